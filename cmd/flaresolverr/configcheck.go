@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+)
+
+// logEntry is a warning or error logged while loading the configuration.
+type logEntry struct {
+	level   string
+	message string
+	fields  string
+}
+
+// logCapture collects the JSON lines zerolog writes, one event per Write.
+type logCapture struct {
+	entries []logEntry
+}
+
+func (c *logCapture) Write(p []byte) (int, error) {
+	var event map[string]any
+	if err := json.Unmarshal(p, &event); err != nil {
+		return len(p), nil //nolint:nilerr // not an event, nothing to report
+	}
+	e := logEntry{}
+	e.level, _ = event[zerolog.LevelFieldName].(string)
+	e.message, _ = event[zerolog.MessageFieldName].(string)
+	delete(event, zerolog.LevelFieldName)
+	delete(event, zerolog.MessageFieldName)
+	delete(event, zerolog.TimestampFieldName)
+
+	keys := make([]string, 0, len(event))
+	for k := range event {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make([]string, 0, len(keys))
+	for _, k := range keys {
+		fields = append(fields, fmt.Sprintf("%s=%v", k, event[k]))
+	}
+	if len(fields) > 0 {
+		e.fields = " (" + strings.Join(fields, " ") + ")"
+	}
+	c.entries = append(c.entries, e)
+	return len(p), nil
+}
+
+// runConfig implements `flaresolverr config <subcommand>`.
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "check" {
+		fmt.Fprintf(os.Stderr, "Usage: %s config check [flags]\n", os.Args[0])
+		return 1
+	}
+	return runConfigCheck(args[1:])
+}
+
+// runConfigCheck implements `flaresolverr config check`. It loads the
+// configuration the way the server does, from the environment, CONFIG_FILE
+// and the setting flags, validates it and prints the effective settings
+// with secrets redacted. Warnings are reported; the exit code is 1 when a
+// value could not be parsed, a secret could not be read or Validate had to
+// correct a setting, which the server only logs before running on with the
+// corrected value.
+func runConfigCheck(args []string) int {
+	fs := flag.NewFlagSet("config check", flag.ContinueOnError)
+	applySettings := settingFlags(fs)
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage: %s config check [flags]\n\n", os.Args[0])
+		fmt.Fprintln(out, "Validates the configuration from the environment, CONFIG_FILE and flags and")
+		fmt.Fprintln(out, "prints the effective settings with secrets redacted. Exits 1 on errors.")
+		fmt.Fprintln(out, "Accepts the same setting flags as the server; see --help.")
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 1
+	}
+	applySettings()
+
+	capture := &logCapture{}
+	log.Logger = zerolog.New(capture)
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+
+	cfg := config.Load()
+	// Every warning while loading is a value that could not be parsed
+	loadProblems := len(capture.entries)
+
+	errorCount := loadProblems
+	secretsCtx, cancelSecrets := context.WithTimeout(context.Background(), 30*time.Second)
+	secretsErr := cfg.ResolveSecrets(secretsCtx)
+	cancelSecrets()
+	if secretsErr != nil {
+		errorCount++
+	}
+
+	before := *cfg
+	validateErr := cfg.Validate()
+	if validateErr != nil {
+		errorCount++
+	}
+	adjustments := config.Adjustments(&before, cfg)
+	errorCount += len(adjustments)
+
+	if err := cfg.Dump(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	for i, e := range capture.entries {
+		level := e.level
+		if i < loadProblems {
+			level = zerolog.LevelErrorValue
+		} else if level == zerolog.LevelErrorValue {
+			errorCount++
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s%s\n", level, e.message, e.fields)
+	}
+	if secretsErr != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to load secrets: %v\n", secretsErr)
+	}
+	if validateErr != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", validateErr)
+	}
+	for _, a := range adjustments {
+		fmt.Fprintf(os.Stderr, "error: %s was corrected from %q to %q\n", a.Field, a.From, a.To)
+	}
+
+	if errorCount > 0 {
+		fmt.Fprintf(os.Stderr, "configuration has %d error(s)\n", errorCount)
+		return 1
+	}
+	fmt.Fprintln(os.Stderr, "configuration OK")
+	return 0
+}
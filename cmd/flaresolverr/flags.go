@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+)
+
+// flagName is the command-line flag for a configuration variable:
+// LOG_LEVEL becomes --log-level.
+func flagName(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+}
+
+// parseFlags parses the command line. Besides --version it accepts a flag
+// for every configuration variable; the flags given override CONFIG_FILE and
+// the environment.
+func parseFlags() (showVersion bool) {
+	fs := flag.CommandLine
+	version := fs.Bool("version", false, "Print version and exit")
+	applySettings := settingFlags(fs)
+
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage: %s [flags]\n", os.Args[0])
+		fmt.Fprintf(out, "       %s healthcheck [-probe shallow|deep] [-url URL] [-timeout 10s]\n", os.Args[0])
+		fmt.Fprintf(out, "       %s config check [flags]\n\n", os.Args[0])
+		fmt.Fprintln(out, "Every setting is read from the environment variable named after it. A")
+		fmt.Fprintln(out, "flag overrides the variable and CONFIG_FILE; a boolean takes --flag=false.")
+		fmt.Fprintln(out, "Prefer the _FILE variants for secrets: flag values show up in process lists.")
+		fmt.Fprintln(out)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(os.Args[1:]) // ExitOnError
+	applySettings()
+	return *version
+}
+
+// settingFlags defines a flag on fs for every configuration variable. Once
+// fs is parsed, the returned function hands the flags given to the config
+// package as overrides.
+func settingFlags(fs *flag.FlagSet) (apply func()) {
+	settings := config.Settings()
+	keys := make(map[string]string, len(settings))
+	for _, s := range settings {
+		name := flagName(s.Key)
+		keys[name] = s.Key
+		usage := settingUsage[s.Key]
+		if usage == "" {
+			usage = s.Key
+		}
+		usage += " (env " + s.Key + ")"
+		if s.Bool {
+			fs.Bool(name, s.Default == "true", usage)
+		} else {
+			fs.String(name, s.Default, usage)
+		}
+	}
+
+	return func() {
+		overrides := make(map[string]string)
+		fs.Visit(func(f *flag.Flag) {
+			if key, ok := keys[f.Name]; ok {
+				overrides[key] = f.Value.String()
+			}
+		})
+		config.SetOverrides(overrides)
+	}
+}
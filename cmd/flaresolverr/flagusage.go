@@ -0,0 +1,191 @@
+package main
+
+// settingUsage is the --help text of the flag for each configuration
+// variable. A variable missing here still gets a flag, described only by
+// its name.
+var settingUsage = map[string]string{
+	"CONFIG_FILE":                  "KEY=VALUE file whose settings override the environment; re-read on reload",
+	"HOST":                         "Server bind address",
+	"PORT":                         "Server port",
+	"RESPONSE_COMPRESSION":         "Compress API responses with brotli or gzip, as negotiated by Accept-Encoding",
+	"BUFFER_POOL_MAX_BUFFER_KB":    "Largest request/response buffer kept for reuse, in KB (4-16384); larger buffers are discarded after use",
+	"MAX_RESPONSE_SIZE_KB":         "Page HTML returned per solve, in KB (up to 102400); longer pages are truncated and flagged with responseTruncated",
+	"MAX_SCREENSHOT_SIZE_KB":       "Largest screenshot returned, in KB (up to 51200); larger ones are left out",
+	"MAX_RESPONSE_COOKIES":         "Cookies returned per solve (up to 1000)",
+	"MAX_STORAGE_ITEMS":            "localStorage and sessionStorage items returned per solve, each (up to 10000)",
+	"MAX_STORAGE_SIZE_KB":          "Largest serialized localStorage or sessionStorage returned, in KB (up to 16384); larger ones are left out",
+	"HEADLESS":                     "Run browser in headless mode",
+	"BROWSER_PATH":                 "Path to Chrome/Chromium executable",
+	"USER_AGENT_POOL_PATH":         "YAML/JSON list of weighted user agents rotated across pool browsers",
+	"USER_AGENT_ROTATION":          "When pool browsers take a new identity from USER_AGENT_POOL_PATH: browser (spawn and recycle), session (also each new session) or request (also each request without a session)",
+	"REMOTE_BROWSER_URLS":          "Comma-separated DevTools endpoints to use instead of launching local Chrome",
+	"GPU_MODE":                     "WebGL/compositing backend for launched browsers: auto, angle, egl or software",
+	"BROWSER_PROFILES_PATH":        "YAML/JSON list of named browser profiles selectable per request with profile",
+	"BROWSER_PROFILE_DATA_DIR":     "Directory holding a Chrome user-data-dir per persistent browser profile",
+	"BROWSER_POOL_SIZE":            "Number of browser instances in pool",
+	"BROWSER_POOL_TIMEOUT":         "Timeout for acquiring a browser",
+	"MAX_MEMORY_MB":                "Browser memory (RSS of all Chrome processes) before recycling browsers",
+	"BROWSER_POOL_MODE":            "browser dedicates a Chrome process to each pool slot; context shares a few processes and hands out incognito contexts",
+	"PAGE_REUSE_ENABLED":           "Keep each pooled browser's tab between requests, reset, instead of creating a new stealth tab per request",
+	"CONTEXT_POOL_HOSTS":           "Chrome processes hosting contexts in context mode (1 to BROWSER_POOL_SIZE)",
+	"PROXY_POOL_SIZE":              "Browsers kept warm per per-request proxy URL (and browser profile) between requests (0 closes them after every request, as before)",
+	"PROXY_POOL_MAX_IDLE":          "Warm per-proxy browsers kept across all proxies; the longest idle is closed first",
+	"PROXY_POOL_IDLE_TIMEOUT":      "Idle time after which a warm per-proxy browser is closed",
+	"SESSION_TTL":                  "Session time-to-live",
+	"SESSION_CLEANUP_INTERVAL":     "Cleanup interval for expired sessions",
+	"MAX_SESSIONS":                 "Maximum concurrent sessions",
+	"SESSION_EVICTION_POLICY":      "What sessions.create does at MAX_SESSIONS: reject refuses the new session, lru destroys the least recently used idle session (its snapshot is kept when persistence is enabled)",
+	"SESSION_PERSIST_DIR":          "Directory for session snapshots; enables restoring sessions after a restart",
+	"SESSION_REDIS_URL":            "Redis URL (redis:// or rediss://) for a session store shared between instances; takes priority over SESSION_PERSIST_DIR",
+	"SESSION_REDIS_PREFIX":         "Key prefix for session snapshots in Redis",
+	"SESSION_PROXY_ROTATION":       "Comma/newline-separated proxy URLs a session moves through after repeated access denials",
+	"SESSION_PROXY_ROTATE_AFTER":   "Consecutive access_denied solves on a session before it rotates (1-100)",
+	"CLEARANCE_CACHE_ENABLED":      "Reuse a minted cf_clearance per domain, egress and User-Agent; sessionless GETs with a still-valid entry skip the challenge wait",
+	"CLEARANCE_TTL":                "Max lifetime of a cached cf_clearance",
+	"CLEARANCE_FAST_PATH":          "On a clearance cache hit, fetch plain GETs over HTTP with the cached cookie and User-Agent instead of a browser, falling back when challenged",
+	"DEFAULT_TIMEOUT":              "Default request timeout",
+	"MAX_TIMEOUT":                  "Maximum allowed timeout",
+	"PROXY_URL":                    "Default proxy URL for all requests",
+	"PROXY_USERNAME":               "Default proxy username",
+	"PROXY_PASSWORD":               "Default proxy password",
+	"PROXY_LIST":                   "Pool of egress proxies (comma/newline-separated, embedded user:pass@ ok). Enables clean-egress routing",
+	"PROXY_STRATEGY":               "Egress selection: sticky-domain (same exit IP per site - keeps cf_clearance valid), round-robin, or per-request",
+	"PROXY_POOLS_PATH":             "YAML/JSON list of named proxy pools selectable per request with proxy: {\"pool\": \"<name>\"}",
+	"PROXY_ROUTES":                 "Per-domain proxy routing for requests without a proxy: comma/newline-separated pattern=proxy-url or pattern=pool:<name> entries",
+	"PROXY_PAC_URL":                "PAC script (http(s):// URL or file path) Chrome evaluates per request in browsers launched without a proxy of their own",
+	"PROXY_FROM_ENV":               "Launch browsers without a proxy of their own behind HTTP_PROXY/HTTPS_PROXY/ALL_PROXY, bypassing NO_PROXY hosts",
+	"PROXY_FAILOVER_ENABLED":       "When a sessionless request through a proxy pool or PROXY_LIST is denied access, retry it once through another proxy of the same pool",
+	"PROXY_STICKY_SESSIONS":        "Give each sessions.create through a Bright Data, Oxylabs or Smartproxy gateway its own sticky-session username, so the session keeps one exit IP",
+	"PROXY_HEALTH_CHECK_ENABLED":   "Probe the configured proxies in the background and take dead ones out of rotation",
+	"PROXY_HEALTH_CHECK_INTERVAL":  "Time between rounds of proxy checks (30s to 24h)",
+	"PROXY_HEALTH_CHECK_TIMEOUT":   "Bound on each probe request (1s to 2m)",
+	"PROXY_HEALTH_IP_URL":          "Returns the caller's IP, as plain text or {\"ip\": \"...\"}; fetched through each proxy for connectivity, latency and egress IP",
+	"PROXY_HEALTH_CANARY_URL":      "Cloudflare-protected page fetched through each proxy to judge the egress IP's reputation; empty skips the check",
+	"PROXY_HEALTH_FAIL_THRESHOLD":  "Consecutive failed checks before a proxy is marked dead",
+	"PROXY_VERIFY_URL":             "IP-echo endpoint loaded by verifyProxy requests, through the browser and directly; returns the caller's IP as plain text or {\"ip\": \"...\"}",
+	"QUIET_HOURS":                  "Per-domain windows during which the domain (and its subdomains) must not be contacted",
+	"DOMAIN_OVERRIDES_PATH":        "YAML/JSON list of per-domain solve settings applied where a request leaves them unset",
+	"TZ":                           "Browser timezone (e.g., America/New_York)",
+	"LANG":                         "Browser language (e.g., en_GB)",
+	"TEST_URL":                     "URL to verify browser works on startup",
+	"DISABLE_MEDIA":                "Block images, stylesheets and fonts unless a request sets disableMedia",
+	"CLIENT_REDIRECT_SETTLE":       "How long to watch for a meta-refresh or JavaScript location redirect after the challenge clears (max 10s, 0 = disabled). Up to 5 hops are followed and each destination is re-validated",
+	"XHR_CHALLENGE_WATCH":          "How long to watch in-page XHR/fetch calls for a second Cloudflare challenge after clearance (max 30s, 0 = disabled)",
+	"CACHE_FALLBACK_ENABLED":       "Allow allowCacheFallback requests to fetch archived copies from the Wayback Machine (archive.org)",
+	"CACHE_FALLBACK_TIMEOUT":       "Bound on the archive lookup and download (1s-1m). The fetch goes direct, not through the request's proxy",
+	"RESULT_CACHE_ENABLED":         "Serve a sessionless request.get identical to one solved within RESULT_CACHE_TTL from that solve, and let identical concurrent requests share one solve",
+	"RESULT_CACHE_TTL":             "How long a solved result is served again (1s-10m)",
+	"RESULT_CACHE_MAX_ENTRIES":     "Cached results kept at once; the soonest to expire is dropped first",
+	"POLL_STRATEGY":                "How the solve loop paces challenge checks: random (0.8-1.5s), fixed (1s) or event (check when the page finishes loading or receives cf_clearance, at least every 3s)",
+	"POLL_STRATEGY_DOMAINS":        "Per-domain overrides as comma/newline-separated domain=strategy entries (e.g. example.com=event); also applies to subdomains",
+	"POLL_INTERVAL_MIN":            "Shortest pause between challenge checks of the random strategy (50ms-10s)",
+	"POLL_INTERVAL_MAX":            "Longest pause between challenge checks of the random strategy (up to 10s, not below POLL_INTERVAL_MIN)",
+	"POLL_MAX_ATTEMPTS":            "Cap on challenge checks per solve; 0 fits as many as the timeout allows",
+	"SELECTOR_DETECTION_BUDGET":    "Time one check may spend looking for challenge selectors, shared among them (100ms-1m)",
+	"LOG_LEVEL":                    "Log level (trace, debug, info, warn, error). Health-check request logs and periodic Server stats are emitted at debug, so set LOG_LEVEL=debug to see them.",
+	"LOG_HTML":                     "Log HTML responses (verbose)",
+	"LOG_FILE":                     "Path to log file (in addition to stdout), written as one JSON object per line",
+	"LOG_FORMAT":                   "Stdout format: console for human-readable lines, json for one JSON object per line (no banner or TUI dashboard)",
+	"LOG_FILE_MAX_SIZE_MB":         "Size at which LOG_FILE is rotated (0 disables)",
+	"LOG_FILE_MAX_AGE":             "Age at which LOG_FILE is rotated, e.g. 24h",
+	"LOG_FILE_MAX_BACKUPS":         "Rotated log files kept (app.log.1 is the newest)",
+	"LOG_STREAM_ENABLED":           "Serve GET /logs/stream (requires API_KEY_ENABLED)",
+	"LOG_STREAM_MAX_SUBSCRIBERS":   "Concurrent log stream connections (1-100)",
+	"EVENT_STREAM_ENABLED":         "Serve GET /admin/events (requires API_KEY_ENABLED)",
+	"EVENT_STREAM_MAX_SUBSCRIBERS": "Concurrent event stream connections (1-100)",
+	"AUDIT_LOG_FILE":               "Write an audit record of every API request to this file",
+	"AUDIT_LOG_MAX_SIZE_MB":        "Size at which the audit file is rotated",
+	"AUDIT_LOG_MAX_BACKUPS":        "Rotated audit files kept (audit.log.1 is the newest)",
+	"AUDIT_LOG_SYSLOG":             "Also send audit records to syslog: local, udp://host:514 or tcp://host:514",
+	"TRACING_ENABLED":              "Export OpenTelemetry spans of the solve pipeline over OTLP/HTTP",
+	"TRACING_ENDPOINT":             "Full OTLP traces URL, e.g. http://otel-collector:4318/v1/traces; defaults to the standard OTEL_EXPORTER_OTLP_* variables",
+	"TRACING_SAMPLE_RATIO":         "Fraction of new traces recorded (0-1); requests with a traceparent header follow the caller's decision",
+	"METRICS_TAG_KEYS":             "Request tag keys exported as labels on the flaresolverr_tag_* metrics",
+	"METRICS_TAG_MAX_VALUES":       "Distinct values tracked per tag key (1-1000); further values are counted under _other",
+	"METRICS_DOMAIN_TOP_N":         "Busiest target domains with flaresolverr_domain_* series of their own (0-500); the rest are counted under domain=\"other\". 0 exports only the other series",
+	"PPROF_ENABLED":                "Enable pprof profiling",
+	"PPROF_PORT":                   "pprof server port",
+	"PPROF_BIND_ADDR":              "pprof bind address",
+	"ADMIN_ENABLED":                "Serve the admin web dashboard on its own listener (requires ADMIN_PASSWORD)",
+	"ADMIN_PORT":                   "Admin dashboard port",
+	"ADMIN_BIND_ADDR":              "Admin dashboard bind address",
+	"ADMIN_USERNAME":               "Admin dashboard basic auth user",
+	"ADMIN_PASSWORD":               "Admin dashboard basic auth password (also ADMIN_PASSWORD_FILE or a vault: reference)",
+	"API_ALLOWED_CIDRS":            "Comma-separated CIDRs or IPs allowed to reach the API listener, including /metrics; others get 403. /health stays open for probes. Client addresses follow TRUST_PROXY",
+	"METRICS_ALLOWED_CIDRS":        "Replaces API_ALLOWED_CIDRS for /metrics, e.g. only the monitoring network",
+	"PPROF_ALLOWED_CIDRS":          "Comma-separated CIDRs or IPs allowed to reach the pprof listener; others get 403",
+	"ADMIN_ALLOWED_CIDRS":          "Comma-separated CIDRs or IPs allowed to reach the admin listener; others get 403",
+	"TLS_CERT_FILE":                "PEM certificate chain; reloaded when the file changes",
+	"TLS_KEY_FILE":                 "PEM private key, required with TLS_CERT_FILE",
+	"TLS_ACME_DOMAINS":             "Comma-separated hostnames to obtain certificates for from an ACME CA, when no TLS_CERT_FILE is set",
+	"TLS_ACME_EMAIL":               "Contact address for the ACME account",
+	"TLS_ACME_CACHE_DIR":           "Where the ACME account and certificates are kept across restarts",
+	"TLS_ACME_DIRECTORY_URL":       "ACME directory, e.g. Let's Encrypt staging for testing",
+	"TLS_ACME_HTTP_ADDR":           "Plain listener for HTTP-01 challenges, e.g. :80",
+	"TLS_CLIENT_CA_FILE":           "PEM CA bundle; API clients must present a certificate that chains to it (mTLS)",
+	"TLS_CLIENT_AUTH":              "require: every endpoint except /health needs a verified client certificate; optional: certificates are verified and logged when presented",
+	"RATE_LIMIT_ENABLED":           "Enable rate limiting",
+	"RATE_LIMIT_RPM":               "Requests per minute per IP",
+	"TRUST_PROXY":                  "Trust X-Forwarded-For headers",
+	"IGNORE_CERT_ERRORS":           "Ignore TLS certificate errors",
+	"CORS_ALLOWED_ORIGINS":         "Comma-separated allowed origins",
+	"ALLOW_LOCAL_PROXIES":          "Allow localhost/private IP proxies",
+	"SSRF_ALLOWED_CIDRS":           "Comma-separated CIDRs or IPs that target URLs may point to despite being private, loopback or link-local",
+	"SSRF_ALLOWED_HOSTS":           "Comma-separated hosts (qa.corp with its subdomains, *.qa.corp for subdomains only) whose addresses are exempt from the private IP checks",
+	"DNS_REBINDING_PROTECTION":     "Pin response URL to the request-time IP. Set false for sites serving identical content across multiple TLDs/CDN IPs (SSRF protection stays on)",
+	"EVALUATE_JS_ENABLED":          "Allow evaluateJs scripts to run in solved pages and return their results. A script can read anything the page can, including cookies and storage, so enable it only with API_KEY_ENABLED or on a trusted network",
+	"RECORD_SOLVE_DIR":             "Directory for recordSolve recordings. When set, recordings are written there as <time>-<host>-<ok|failed>.gif instead of being returned, which also keeps the recordings of failed solves",
+	"API_KEY_ENABLED":              "Enable API key authentication",
+	"API_KEY":                      "Required API key (use 16+ chars)",
+	"API_KEYS_FILE":                "YAML/JSON list of additional API keys restricted to specific commands",
+	"TARGET_ALLOWLIST":             "Comma-separated domain patterns requests may target; anything else is refused",
+	"TARGET_DENYLIST":              "Comma-separated domain patterns requests may never target",
+	"TARGET_ALLOWLIST_FILE":        "File of allowlist patterns, one per line, added to TARGET_ALLOWLIST",
+	"TARGET_DENYLIST_FILE":         "File of denylist patterns, one per line, added to TARGET_DENYLIST",
+	"CAPTCHA_NATIVE_ATTEMPTS":      "Native solve attempts before external fallback (1-10)",
+	"CAPTCHA_FALLBACK_ENABLED":     "Enable external CAPTCHA solver fallback",
+	"TWOCAPTCHA_API_KEY":           "2Captcha API key",
+	"CAPSOLVER_API_KEY":            "CapSolver API key",
+	"ANTICAPTCHA_API_KEY":          "anti-captcha.com API key",
+	"CAPMONSTER_API_KEY":           "CapMonster Cloud API key",
+	"NINEKW_API_KEY":               "9kw.eu API key (hCaptcha/reCAPTCHA only - does not solve Cloudflare Turnstile)",
+	"CAPTCHA_WEBHOOK_URL":          "Endpoint the custom captcha provider POSTs challenges to",
+	"CAPTCHA_WEBHOOK_TOKEN":        "Bearer token sent to CAPTCHA_WEBHOOK_URL",
+	"CAPTCHA_PRIMARY_PROVIDER":     "Primary provider: 2captcha, capsolver, anticaptcha, capmonster, 9kw, or custom",
+	"CAPTCHA_PROVIDER_ORDER":       "Comma-separated providers to use, in order; overrides CAPTCHA_PRIMARY_PROVIDER",
+	"CAPTCHA_BUDGET_DAILY":         "Spend cap in USD across all captcha providers per UTC day (0 for none)",
+	"CAPTCHA_BUDGET_MONTHLY":       "Spend cap in USD across all captcha providers per UTC month (0 for none)",
+	"CAPTCHA_PROVIDER_BUDGETS":     "Per-provider spend caps, e.g. 2captcha:daily=5,capsolver:monthly=40",
+	"CAPTCHA_LOW_BALANCE":          "Balance below which captcha.status flags a provider",
+	"CAPTCHA_RACE_THRESHOLD":       "Native success rate (0-1) below which external solving races native, 0 disables",
+	"CAPTCHA_BREAKER_ERROR_RATE":   "Provider failure share (0-1) that opens its circuit breaker, 0 disables",
+	"CAPTCHA_BREAKER_SLOW_CALL":    "Solves slower than this count as circuit breaker failures, 0 disables",
+	"CAPTCHA_BREAKER_COOLDOWN":     "How long an open circuit breaker skips its provider",
+	"CAPTCHA_SOLVER_TIMEOUT":       "Timeout for external solver API (30s-300s)",
+	"SELECTORS_PATH":               "Path to external selectors.yaml override file",
+	"SELECTORS_HOT_RELOAD":         "Enable file watching for automatic reload",
+	"SELECTORS_REMOTE_URL":         "HTTP(S) URL to fetch selectors from",
+	"SELECTORS_REMOTE_REFRESH":     "Refresh interval for remote selectors (5m-24h)",
+	"SELECTORS_REMOTE_PUBLIC_KEY":  "Base64 Ed25519 public key remote selectors must be signed with",
+	"DASHBOARD_ENABLED":            "TUI dashboard (auto-disables without TTY)",
+	"API_KEY_FILE":                 "File holding the API key, such as a Docker or Kubernetes secret; API_KEY wins if both are set",
+	"TWOCAPTCHA_API_KEY_FILE":      "File holding the 2Captcha API key, such as a Docker or Kubernetes secret; TWOCAPTCHA_API_KEY wins if both are set",
+	"CAPSOLVER_API_KEY_FILE":       "File holding the CapSolver API key, such as a Docker or Kubernetes secret; CAPSOLVER_API_KEY wins if both are set",
+	"ANTICAPTCHA_API_KEY_FILE":     "File holding the Anti-Captcha API key, such as a Docker or Kubernetes secret; ANTICAPTCHA_API_KEY wins if both are set",
+	"CAPMONSTER_API_KEY_FILE":      "File holding the CapMonster Cloud API key, such as a Docker or Kubernetes secret; CAPMONSTER_API_KEY wins if both are set",
+	"NINEKW_API_KEY_FILE":          "File holding the 9kw API key, such as a Docker or Kubernetes secret; NINEKW_API_KEY wins if both are set",
+	"CAPTCHA_WEBHOOK_TOKEN_FILE":   "File holding the custom captcha provider token, such as a Docker or Kubernetes secret; CAPTCHA_WEBHOOK_TOKEN wins if both are set",
+	"PROXY_URL_FILE":               "File holding the default proxy URL, such as a Docker or Kubernetes secret; PROXY_URL wins if both are set",
+	"PROXY_USERNAME_FILE":          "File holding the default proxy username, such as a Docker or Kubernetes secret; PROXY_USERNAME wins if both are set",
+	"PROXY_PASSWORD_FILE":          "File holding the default proxy password, such as a Docker or Kubernetes secret; PROXY_PASSWORD wins if both are set",
+	"PROXY_LIST_FILE":              "File holding the clean-egress proxy list, such as a Docker or Kubernetes secret; PROXY_LIST wins if both are set",
+	"SESSION_REDIS_URL_FILE":       "File holding the Redis session store URL, such as a Docker or Kubernetes secret; SESSION_REDIS_URL wins if both are set",
+	"ADMIN_PASSWORD_FILE":          "File holding the admin dashboard password, such as a Docker or Kubernetes secret; ADMIN_PASSWORD wins if both are set",
+	"VAULT_ADDR":                   "HashiCorp Vault address for vault:<path>#<field> secret references",
+	"VAULT_TOKEN":                  "Vault token for secret references",
+	"VAULT_TOKEN_FILE":             "File holding the Vault token",
+	"VAULT_NAMESPACE":              "Vault Enterprise namespace for secret references",
+
+	// Longer than any key above; set apart so their alignment is kept
+	"RESPONSE_COMPRESSION_MIN_SIZE": "Responses shorter than this many bytes are sent uncompressed",
+}
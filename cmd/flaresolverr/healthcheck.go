@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+)
+
+// healthResponse is the part of the /health response the healthcheck reads.
+type healthResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Probe   *struct {
+		DurationMs int64  `json:"durationMs"`
+		Error      string `json:"error"`
+	} `json:"probe"`
+}
+
+// runHealthcheck implements `flaresolverr healthcheck`. It asks the running
+// server for /health and returns the process exit code: 0 when healthy, 1
+// otherwise, as Docker HEALTHCHECK and Kubernetes exec probes expect. The
+// server address comes from the same HOST, PORT and TLS variables the server
+// reads, so the image needs no curl or wget.
+func runHealthcheck(args []string) int {
+	fs := flag.NewFlagSet("healthcheck", flag.ContinueOnError)
+	probe := fs.String("probe", "shallow", "shallow: the server answers; deep: also navigate a pool browser to about:blank")
+	baseURL := fs.String("url", "", "Server base URL (default: derived from HOST, PORT and the TLS settings)")
+	timeout := fs.Duration("timeout", 10*time.Second, "Give up after this long")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if *probe != "shallow" && *probe != "deep" {
+		fmt.Fprintf(os.Stderr, "unhealthy: invalid -probe %q, expected shallow or deep\n", *probe)
+		return 1
+	}
+
+	// Configuration warnings belong to the server's log, not the probe output
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	cfg := config.Load()
+
+	target := *baseURL
+	if target == "" {
+		target = localServerURL(cfg)
+	}
+	target = strings.TrimRight(target, "/") + "/health"
+	if *probe == "deep" {
+		target += "?probe=deep"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unhealthy: %v\n", err)
+		return 1
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		// The server's certificate names its public hostname, not the
+		// loopback address probed here
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // liveness probe of the local server
+	}}
+	resp, err := client.Do(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unhealthy: %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	var health healthResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&health); err != nil {
+		fmt.Fprintf(os.Stderr, "unhealthy: HTTP %d with an unreadable body: %v\n", resp.StatusCode, err)
+		return 1
+	}
+	if resp.StatusCode != http.StatusOK || health.Status != "ok" {
+		msg := health.Message
+		if health.Probe != nil && health.Probe.Error != "" {
+			msg += ": " + health.Probe.Error
+		}
+		fmt.Fprintf(os.Stderr, "unhealthy: HTTP %d: %s\n", resp.StatusCode, msg)
+		return 1
+	}
+
+	if health.Probe != nil {
+		fmt.Printf("healthy: %s (deep probe %dms)\n", health.Message, health.Probe.DurationMs)
+	} else {
+		fmt.Printf("healthy: %s\n", health.Message)
+	}
+	return 0
+}
+
+// localServerURL is the base URL of the API listener as seen from the same
+// host: a wildcard bind address is reached over loopback.
+func localServerURL(cfg *config.Config) string {
+	scheme := "http"
+	if cfg.TLSCertFile != "" || len(cfg.TLSACMEDomains) > 0 {
+		scheme = "https"
+	}
+	host := cfg.Host
+	switch host {
+	case "", "0.0.0.0":
+		host = "127.0.0.1"
+	case "::", "[::]":
+		host = "::1"
+	}
+	host = strings.Trim(host, "[]")
+	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(cfg.Port))
+}
@@ -3,7 +3,6 @@ package main
 
 import (
 	"context"
-	"flag"
 	"fmt"
 	"io"
 	"net/http"
@@ -16,22 +15,34 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
+	"github.com/Rorqualx/flaresolverr-go/internal/audit"
 	"github.com/Rorqualx/flaresolverr-go/internal/browser"
 	"github.com/Rorqualx/flaresolverr-go/internal/config"
 	"github.com/Rorqualx/flaresolverr-go/internal/dashboard"
+	"github.com/Rorqualx/flaresolverr-go/internal/events"
 	"github.com/Rorqualx/flaresolverr-go/internal/handlers"
+	"github.com/Rorqualx/flaresolverr-go/internal/logfile"
+	"github.com/Rorqualx/flaresolverr-go/internal/logstream"
 	"github.com/Rorqualx/flaresolverr-go/internal/middleware"
+	"github.com/Rorqualx/flaresolverr-go/internal/security"
 	"github.com/Rorqualx/flaresolverr-go/internal/selectors"
+	"github.com/Rorqualx/flaresolverr-go/internal/servertls"
 	"github.com/Rorqualx/flaresolverr-go/internal/session"
+	"github.com/Rorqualx/flaresolverr-go/internal/tracing"
 	"github.com/Rorqualx/flaresolverr-go/pkg/version"
 )
 
 func main() {
-	// Handle --version flag early, before any initialization
-	showVersion := flag.Bool("version", false, "Print version and exit")
-	flag.Parse()
+	// Container health probes run the binary itself
+	if len(os.Args) > 1 && os.Args[1] == "healthcheck" {
+		os.Exit(runHealthcheck(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfig(os.Args[2:]))
+	}
 
-	if *showVersion {
+	// Handle --version flag early, before any initialization
+	if showVersion := parseFlags(); showVersion {
 		fmt.Printf("FlareSolverr %s\n", version.Full())
 		return
 	}
@@ -40,13 +51,97 @@ func main() {
 	cfg := config.Load()
 
 	// Setup logging first so validation warnings are visible
-	setupLogging(cfg.LogLevel, cfg.LogFile)
+	logOutput := setupLogging(cfg)
+
+	// Fill credentials from *_FILE variables and secret stores before validating them
+	secretsCtx, cancelSecrets := context.WithTimeout(context.Background(), 30*time.Second)
+	if err := cfg.ResolveSecrets(secretsCtx); err != nil {
+		log.Fatal().Err(err).Msg("Failed to load secrets")
+	}
+	cancelSecrets()
 
 	// Validate configuration (Bug 12: config bounds validation)
-	cfg.Validate()
+	if err := cfg.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("Invalid configuration")
+	}
 
-	// Print banner
-	printBanner()
+	// Tee structured logs to the live log stream if enabled
+	var logBroker *logstream.Broker
+	if cfg.LogStreamEnabled {
+		logBroker = logstream.NewBroker(cfg.LogStreamMaxSubscribers)
+		log.Logger = log.Output(io.MultiWriter(logOutput, logBroker))
+	}
+
+	// Print banner, unless stdout must stay one JSON object per line
+	if cfg.LogFormat != "json" {
+		printBanner()
+	}
+
+	// Private targets exempted from SSRF protection
+	ssrfExceptions, err := security.ParseSSRFExceptions(cfg.SSRFAllowedCIDRs, cfg.SSRFAllowedHosts)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid SSRF exceptions")
+	}
+	if ssrfExceptions != nil {
+		security.SetSSRFExceptions(ssrfExceptions)
+		log.Warn().
+			Strs("cidrs", cfg.SSRFAllowedCIDRs).
+			Strs("hosts", cfg.SSRFAllowedHosts).
+			Msg("SSRF protection relaxed for the listed private targets")
+	}
+
+	// Export solve pipeline spans over OTLP
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Enabled:        cfg.TracingEnabled,
+		Endpoint:       cfg.TracingEndpoint,
+		SampleRatio:    cfg.TracingSampleRatio,
+		ServiceName:    "flaresolverr",
+		ServiceVersion: version.Full(),
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up tracing")
+	}
+	if cfg.TracingEnabled {
+		log.Info().
+			Str("endpoint", cfg.TracingEndpoint).
+			Float64("sample_ratio", cfg.TracingSampleRatio).
+			Msg("OpenTelemetry tracing enabled")
+	}
+
+	// Create HTTP server. It starts listening before the browser pool
+	// pre-warms so /livez answers (and /readyz reports 503) meanwhile;
+	// the full handler is swapped in once everything is initialized
+	startup := handlers.NewStartupHandler()
+	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           startup,
+		ReadTimeout:       cfg.MaxTimeout + 10*time.Second,
+		WriteTimeout:      cfg.MaxTimeout + 10*time.Second,
+		IdleTimeout:       120 * time.Second,
+		ReadHeaderTimeout: 10 * time.Second, // Prevent slowloris attacks
+	}
+
+	// HTTPS for the API (including /metrics) and pprof listeners
+	tlsProvider, err := servertls.New(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up TLS")
+	}
+	if tlsProvider != nil {
+		server.TLSConfig = tlsProvider.APITLSConfig()
+	}
+
+	go func() {
+		log.Info().Str("address", addr).Bool("tls", tlsProvider != nil).Msg("API listener started")
+		if err := listenAndServe(server); err != nil && err != http.ErrServerClosed {
+			log.Fatal().Err(err).Msg("Server failed")
+		}
+	}()
+
+	// Catch SIGHUP before its default action can end the process; a reload
+	// asked for while the pool warms up runs once the server is ready
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
 
 	// Initialize browser pool
 	log.Info().Msg("Initializing browser pool...")
@@ -61,12 +156,24 @@ func main() {
 	// Initialize selectors manager with optional hot-reload and remote fetch
 	var selectorsManager *selectors.Manager
 	if cfg.SelectorsPath != "" || cfg.SelectorsHotReload || cfg.SelectorsRemoteURL != "" {
+		remote := selectors.RemoteOptions{
+			URL:             cfg.SelectorsRemoteURL,
+			RefreshInterval: cfg.SelectorsRemoteRefresh,
+		}
+		if cfg.SelectorsRemotePublicKey != "" {
+			key, err := selectors.ParsePublicKey(cfg.SelectorsRemotePublicKey)
+			if err != nil {
+				// Validate vetted the key; refuse remote selectors rather than accept them unsigned
+				log.Error().Err(err).Msg("Invalid SELECTORS_REMOTE_PUBLIC_KEY, remote selectors disabled")
+				remote.URL = ""
+			}
+			remote.PublicKey = key
+		}
 		var err error
-		selectorsManager, err = selectors.NewManagerWithRemote(
+		selectorsManager, err = selectors.NewManagerWithRemoteOptions(
 			cfg.SelectorsPath,
 			cfg.SelectorsHotReload,
-			cfg.SelectorsRemoteURL,
-			cfg.SelectorsRemoteRefresh,
+			remote,
 		)
 		if err != nil {
 			log.Warn().Err(err).Msg("Failed to create selectors manager, using embedded defaults")
@@ -79,6 +186,7 @@ func main() {
 			log.Info().
 				Str("url", cfg.SelectorsRemoteURL).
 				Dur("refresh", cfg.SelectorsRemoteRefresh).
+				Bool("signed", cfg.SelectorsRemotePublicKey != "").
 				Msg("Remote selector fetch enabled")
 		}
 	} else {
@@ -87,13 +195,41 @@ func main() {
 
 	// Create handler
 	handler := handlers.NewWithSelectors(pool, sessionMgr, cfg, selectorsManager)
+	if logBroker != nil {
+		handler.SetLogStream(logBroker)
+		log.Info().Int("max_subscribers", cfg.LogStreamMaxSubscribers).Msg("Log streaming enabled at /logs/stream")
+	}
+	if cfg.EventStreamEnabled {
+		events.Default().SetMaxSubscribers(cfg.EventStreamMaxSubscribers)
+		handler.SetEventStream(events.Default())
+		log.Info().Int("max_subscribers", cfg.EventStreamMaxSubscribers).Msg("Event streaming enabled at /admin/events")
+	}
+
+	// Audit log of every API request, written regardless of LOG_LEVEL
+	auditLog, err := audit.New(audit.Config{
+		File:       cfg.AuditLogFile,
+		MaxSizeMB:  cfg.AuditLogMaxSizeMB,
+		MaxBackups: cfg.AuditLogMaxBackups,
+		Syslog:     cfg.AuditLogSyslog,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to open audit log")
+	}
+	if auditLog != nil {
+		handler.SetAuditLog(auditLog)
+		log.Info().
+			Str("file", cfg.AuditLogFile).
+			Str("syslog", cfg.AuditLogSyslog).
+			Msg("Audit log enabled")
+	}
 
 	// Create dashboard (enabled by default)
 	// TTY: full TUI dashboard | Non-TTY (Docker): periodic log-based stats reporter
 	var dash *dashboard.Dashboard
 	var logReporter *dashboard.LogReporter
 	if cfg.DashboardEnabled {
-		if dashboard.IsTTY() {
+		// The TUI would swallow the JSON log stream, so LOG_FORMAT=json gets the reporter
+		if dashboard.IsTTY() && cfg.LogFormat != "json" {
 			dash = dashboard.New(pool, sessionMgr, handler.DomainStats(), time.Now())
 		} else {
 			logReporter = dashboard.NewLogReporter(pool, sessionMgr, handler.DomainStats(), time.Now(), 30*time.Second)
@@ -105,11 +241,20 @@ func main() {
 
 	// Apply middleware (in reverse order - last applied runs first)
 	// 1. Recovery (outermost - catches panics from everything)
-	// 2. Logging (logs all requests)
-	// 3. Rate limiting (if enabled)
-	// 4. API key authentication (if enabled)
-	// 5. Security headers
-	// 6. CORS (handles preflight)
+	// 2. Request ID (tags the request for log correlation)
+	// 3. Logging (logs all requests)
+	// 4. Source allowlist (if API_ALLOWED_CIDRS/METRICS_ALLOWED_CIDRS are set)
+	// 5. Rate limiting (if enabled)
+	// 6. Client certificate (if TLS_CLIENT_CA_FILE is set)
+	// 7. API key authentication (if enabled)
+	// 8. Security headers
+	// 9. CORS (handles preflight)
+	// 10. Compression (if enabled; innermost, so it sees the handler's own output)
+
+	if cfg.ResponseCompression {
+		log.Info().Int("min_size", cfg.ResponseCompressionMinSize).Msg("Response compression enabled")
+		finalHandler = middleware.Compress(cfg.ResponseCompressionMinSize)(finalHandler)
+	}
 
 	finalHandler = middleware.CORS(middleware.CORSConfig{
 		AllowedOrigins: cfg.CORSAllowedOrigins,
@@ -121,8 +266,16 @@ func main() {
 	// This MUST be applied before rate limiting so unauthenticated requests
 	// are rejected before consuming rate limit tokens
 	if cfg.APIKeyEnabled {
-		log.Info().Msg("API key authentication enabled")
-		finalHandler = middleware.APIKey(cfg)(finalHandler)
+		scopedKeys, err := middleware.LoadScopedAPIKeys(cfg.APIKeysFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load API keys file")
+		}
+		log.Info().Int("scoped_keys", len(scopedKeys)).Msg("API key authentication enabled")
+		finalHandler = middleware.APIKey(cfg, scopedKeys...)(finalHandler)
+	}
+
+	if cfg.TLSClientCAFile != "" {
+		finalHandler = middleware.ClientCert(cfg)(finalHandler)
 	}
 
 	// Create rate limiter middleware with cleanup support
@@ -136,70 +289,122 @@ func main() {
 		finalHandler = rateLimiter.Handler()(finalHandler)
 	}
 
+	// SIGHUP and the admin dashboard reload part of the configuration
+	applied := *cfg
+	reloader := &configReloader{cfg: &applied, handler: handler, rateLimiter: rateLimiter, selectors: selectorsManager}
+	handler.SetConfigReloader(reloader.Reload)
+
+	// Reject sources outside the allowlists before they use rate limit tokens
+	apiSources, err := middleware.NewSourceAllowlist(cfg.APIAllowedCIDRs, cfg.TrustProxy)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid API_ALLOWED_CIDRS")
+	}
+	metricsSources, err := middleware.NewSourceAllowlist(cfg.MetricsAllowedCIDRs, cfg.TrustProxy)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid METRICS_ALLOWED_CIDRS")
+	}
+	if apiSources != nil || metricsSources != nil {
+		log.Info().
+			Strs("api", cfg.APIAllowedCIDRs).
+			Strs("metrics", cfg.MetricsAllowedCIDRs).
+			Msg("Source address allowlist enabled")
+		finalHandler = middleware.RestrictSources(apiSources, map[string]*middleware.SourceAllowlist{
+			"/metrics": metricsSources,
+		})(finalHandler)
+	}
+
 	finalHandler = middleware.Logging(finalHandler)
 	if dash != nil {
 		finalHandler = dashboard.RecordRequests(dash.Events())(finalHandler)
 	} else if logReporter != nil {
 		finalHandler = dashboard.RecordRequests(logReporter.Events())(finalHandler)
 	}
+	finalHandler = middleware.RequestID(finalHandler)
 	finalHandler = middleware.Recovery(finalHandler)
 
-	// Create HTTP server
-	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
-	server := &http.Server{
-		Addr:              addr,
-		Handler:           finalHandler,
-		ReadTimeout:       cfg.MaxTimeout + 10*time.Second,
-		WriteTimeout:      cfg.MaxTimeout + 10*time.Second,
-		IdleTimeout:       120 * time.Second,
-		ReadHeaderTimeout: 10 * time.Second, // Prevent slowloris attacks
-	}
-
 	// Start pprof server if enabled
 	// WARNING: pprof should only be enabled in development/debugging
 	// as it exposes detailed runtime information
 	var pprofServer *http.Server
 	if cfg.PProfEnabled {
 		pprofAddr := fmt.Sprintf("%s:%d", cfg.PProfBindAddr, cfg.PProfPort)
+		// The pprof listener is never behind the API's reverse proxy, so
+		// forwarding headers are not trusted here
+		pprofSources, err := middleware.NewSourceAllowlist(cfg.PProfAllowedCIDRs, false)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid PPROF_ALLOWED_CIDRS")
+		}
 		pprofServer = &http.Server{
 			Addr:         pprofAddr,
-			Handler:      http.DefaultServeMux, // pprof registers to DefaultServeMux
+			Handler:      middleware.RestrictSources(pprofSources, nil)(http.DefaultServeMux), // pprof registers to DefaultServeMux
 			ReadTimeout:  60 * time.Second,
 			WriteTimeout: 60 * time.Second, // Profiles can take time
 		}
+		if tlsProvider != nil {
+			pprofServer.TLSConfig = tlsProvider.TLSConfig()
+		}
 
 		go func() {
 			log.Warn().
 				Str("addr", pprofAddr).
 				Msg("WARNING: pprof profiling server started - exposes runtime internals, use for debugging only")
 
-			if err := pprofServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			if err := listenAndServe(pprofServer); err != nil && err != http.ErrServerClosed {
 				log.Error().Err(err).Msg("pprof server failed")
 			}
 		}()
 	}
 
-	// Start main server in goroutine
-	go func() {
-		log.Info().
-			Str("address", addr).
-			Int("pool_size", cfg.BrowserPoolSize).
-			Bool("rate_limit_enabled", cfg.RateLimitEnabled).
-			Msg("FlareSolverr is ready to accept requests")
+	// Start the admin dashboard if enabled
+	var adminServer *http.Server
+	if cfg.AdminEnabled {
+		adminAddr := fmt.Sprintf("%s:%d", cfg.AdminBindAddr, cfg.AdminPort)
+		adminSources, err := middleware.NewSourceAllowlist(cfg.AdminAllowedCIDRs, false)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid ADMIN_ALLOWED_CIDRS")
+		}
+		var adminHandler http.Handler = handler.AdminHandler()
+		adminHandler = middleware.SecurityHeaders(adminHandler)
+		adminHandler = middleware.BasicAuth("FlareSolverr admin", cfg.AdminUsername, cfg.AdminPassword)(adminHandler)
+		adminHandler = middleware.RestrictSources(adminSources, nil)(adminHandler)
+		adminHandler = middleware.RequestID(adminHandler)
+		adminHandler = middleware.Recovery(adminHandler)
+		adminServer = &http.Server{
+			Addr:              adminAddr,
+			Handler:           adminHandler,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      cfg.MaxTimeout + 10*time.Second, // Growing the pool waits for launches
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		if tlsProvider != nil {
+			adminServer.TLSConfig = tlsProvider.TLSConfig()
+		}
 
-		// Check for updates in background (non-blocking)
 		go func() {
-			if latestVersion, releaseURL := version.CheckForUpdate(); latestVersion != "" {
-				log.Info().
-					Str("current", version.Full()).
-					Str("latest", latestVersion).
-					Str("url", releaseURL).
-					Msg("A newer version of FlareSolverr is available")
+			log.Info().Str("addr", adminAddr).Msg("Admin dashboard started")
+			if err := listenAndServe(adminServer); err != nil && err != http.ErrServerClosed {
+				log.Error().Err(err).Msg("Admin dashboard server failed")
 			}
 		}()
+	}
 
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal().Err(err).Msg("Server failed")
+	// From here on the API listener serves the full handler
+	startup.Ready(finalHandler)
+	log.Info().
+		Str("address", addr).
+		Int("pool_size", cfg.BrowserPoolSize).
+		Bool("rate_limit_enabled", cfg.RateLimitEnabled).
+		Bool("tls", tlsProvider != nil).
+		Msg("FlareSolverr is ready to accept requests")
+
+	// Check for updates in background (non-blocking)
+	go func() {
+		if latestVersion, releaseURL := version.CheckForUpdate(); latestVersion != "" {
+			log.Info().
+				Str("current", version.Full()).
+				Str("latest", latestVersion).
+				Str("url", releaseURL).
+				Msg("A newer version of FlareSolverr is available")
 		}
 	}()
 
@@ -210,6 +415,13 @@ func main() {
 		logReporter.Start()
 	}
 
+	go func() {
+		for range hup {
+			log.Info().Msg("SIGHUP received, reloading configuration")
+			_, _ = reloader.Reload() // Reload logs its own outcome
+		}
+	}()
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -217,6 +429,7 @@ func main() {
 
 	// Stop receiving signals to prevent double-shutdown
 	signal.Stop(quit)
+	signal.Stop(hup)
 
 	log.Info().Msg("Shutting down...")
 
@@ -236,6 +449,28 @@ func main() {
 		}
 	}
 
+	if adminServer != nil {
+		if err := adminServer.Shutdown(ctx); err != nil {
+			log.Error().Err(err).Msg("Admin dashboard shutdown error")
+		}
+	}
+
+	// Stop the certificate watcher / ACME challenge listener
+	tlsProvider.Close()
+
+	// Stop background proxy health checks
+	handler.Close()
+
+	// Flush the audit log once no more requests are answered
+	if err := auditLog.Close(); err != nil {
+		log.Error().Err(err).Msg("Audit log close error")
+	}
+
+	// Export the spans still buffered
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error().Err(err).Msg("Tracing shutdown error")
+	}
+
 	// Close rate limiter to stop cleanup goroutine
 	if rateLimiter != nil {
 		rateLimiter.Close()
@@ -269,26 +504,56 @@ func main() {
 	log.Info().Msg("Shutdown complete")
 }
 
-// setupLogging configures zerolog based on the log level.
-func setupLogging(level, logFile string) {
-	var output io.Writer = zerolog.ConsoleWriter{
-		Out:        os.Stdout,
-		TimeFormat: time.RFC3339,
+// listenAndServe serves HTTPS when the server has a TLS configuration.
+func listenAndServe(srv *http.Server) error {
+	if srv.TLSConfig != nil {
+		return srv.ListenAndServeTLS("", "")
+	}
+	return srv.ListenAndServe()
+}
+
+// setupLogging configures zerolog from the LOG_* settings and returns the
+// writer logs are sent to. Stdout gets human-readable lines unless
+// LOG_FORMAT=json; LOG_FILE always receives one JSON object per line.
+func setupLogging(cfg *config.Config) io.Writer {
+	var output io.Writer = os.Stdout
+	if cfg.LogFormat != "json" {
+		output = zerolog.ConsoleWriter{
+			Out:        os.Stdout,
+			TimeFormat: time.RFC3339,
+		}
 	}
 
 	// Add file logging if LOG_FILE is set
-	if logFile != "" {
-		f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if cfg.LogFile != "" {
+		f, err := logfile.Open(cfg.LogFile, logfile.Options{
+			MaxSize:    int64(cfg.LogFileMaxSizeMB) << 20,
+			MaxAge:     cfg.LogFileMaxAge,
+			MaxBackups: cfg.LogFileMaxBackups,
+			Perm:       0o644,
+		})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "WARNING: Failed to open log file %s: %v\n", logFile, err)
+			fmt.Fprintf(os.Stderr, "WARNING: Failed to open log file %s: %v\n", cfg.LogFile, err)
 		} else {
 			output = io.MultiWriter(output, f)
-			fmt.Printf("Logging to file: %s\n", logFile)
+			fmt.Fprintf(os.Stderr, "Logging to file: %s\n", cfg.LogFile)
 		}
 	}
 
 	log.Logger = log.Output(output)
+	// Code logging through log.Ctx(ctx) outside an API request uses the global logger
+	zerolog.DefaultContextLogger = &log.Logger
+
+	setLogLevel(cfg.LogLevel)
+
+	if cfg.LogFormat != "json" && cfg.LogFormat != "console" {
+		log.Warn().Str("format", cfg.LogFormat).Msg("Unknown LOG_FORMAT, using console")
+	}
+	return output
+}
 
+// setLogLevel applies a LOG_LEVEL value, defaulting to info.
+func setLogLevel(level string) {
 	switch level {
 	case "trace":
 		zerolog.SetGlobalLevel(zerolog.TraceLevel)
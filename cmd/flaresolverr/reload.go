@@ -0,0 +1,95 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+	"github.com/Rorqualx/flaresolverr-go/internal/handlers"
+	"github.com/Rorqualx/flaresolverr-go/internal/middleware"
+	"github.com/Rorqualx/flaresolverr-go/internal/selectors"
+)
+
+// configReloader applies the reloadable settings of CONFIG_FILE and the
+// environment to the running server, keeping the warmed browser pool and
+// the sessions: LOG_LEVEL, RATE_LIMIT_RPM, SELECTORS_PATH, PROXY_POOLS_PATH
+// and the domain rules (TARGET_ALLOWLIST, TARGET_DENYLIST, their _FILE
+// variants, QUIET_HOURS and DOMAIN_OVERRIDES_PATH). Any other setting needs
+// a restart.
+type configReloader struct {
+	mu          sync.Mutex
+	cfg         *config.Config // the settings in effect
+	handler     *handlers.Handler
+	rateLimiter *middleware.RateLimiterMiddleware // nil when RATE_LIMIT_ENABLED=false
+	selectors   *selectors.Manager
+}
+
+// Reload re-reads the configuration and applies it. Files named by the
+// settings are re-read even when their path is unchanged, so they can be
+// edited in place. A setting that fails to apply keeps its previous value
+// and is reported in the result's errors.
+func (r *configReloader) Reload() (handlers.ReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := config.Reload()
+	if err != nil {
+		log.Error().Err(err).Msg("Configuration reload failed")
+		return handlers.ReloadResult{}, err
+	}
+	// A QUIET_HOURS that does not parse is reported below by the domain
+	// rules, which keep the previous schedule
+	_ = next.Validate()
+
+	result := handlers.ReloadResult{Applied: []string{}}
+	applied := func(name string) {
+		result.Applied = append(result.Applied, name)
+	}
+	failed := func(name string, err error) {
+		log.Warn().Err(err).Str("setting", name).Msg("Failed to reload setting, keeping the previous value")
+		result.Errors = append(result.Errors, name+": "+err.Error())
+	}
+
+	if next.LogLevel != r.cfg.LogLevel {
+		setLogLevel(next.LogLevel)
+		r.cfg.LogLevel = next.LogLevel
+		applied("LOG_LEVEL")
+	}
+
+	if next.RateLimitRPM != r.cfg.RateLimitRPM && r.rateLimiter != nil {
+		r.rateLimiter.SetRate(next.RateLimitRPM)
+		r.cfg.RateLimitRPM = next.RateLimitRPM
+		applied("RATE_LIMIT_RPM")
+	}
+
+	if next.SelectorsPath != "" || r.cfg.SelectorsPath != "" {
+		if err := r.selectors.SetPath(next.SelectorsPath); err != nil {
+			failed("SELECTORS_PATH", err)
+		} else {
+			r.cfg.SelectorsPath = next.SelectorsPath
+			applied("SELECTORS_PATH")
+		}
+	}
+
+	if next.ProxyPoolsPath != "" || r.cfg.ProxyPoolsPath != "" {
+		if err := r.handler.ReloadProxyPools(next.ProxyPoolsPath); err != nil {
+			failed("PROXY_POOLS_PATH", err)
+		} else {
+			r.cfg.ProxyPoolsPath = next.ProxyPoolsPath
+			applied("PROXY_POOLS_PATH")
+		}
+	}
+
+	if err := r.handler.ReloadDomainRules(next); err != nil {
+		failed("domain rules", err)
+	} else {
+		applied("domain rules")
+	}
+
+	log.Info().
+		Strs("applied", result.Applied).
+		Int("errors", len(result.Errors)).
+		Msg("Configuration reloaded")
+	return result, nil
+}
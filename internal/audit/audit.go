@@ -0,0 +1,108 @@
+// Package audit writes one JSON record per API request to a dedicated sink,
+// a size-rotated file or syslog, so who solved what through which proxy is
+// kept regardless of LOG_LEVEL.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Record describes one answered API request.
+type Record struct {
+	Time       time.Time `json:"time"`
+	RequestID  string    `json:"request_id,omitempty"`
+	ClientIP   string    `json:"client_ip"`
+	APIKey     string    `json:"api_key,omitempty"`     // Scoped key name, empty for API_KEY or no auth
+	ClientCert string    `json:"client_cert,omitempty"` // Verified client certificate common name
+	Command    string    `json:"cmd"`
+	Domain     string    `json:"domain,omitempty"`
+	Session    string    `json:"session,omitempty"`
+	Proxy      string    `json:"proxy,omitempty"` // Redacted proxy URL
+	Outcome    string    `json:"outcome"`         // "ok" or "error"
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	Cookies    int       `json:"cookies"` // Cookies returned to the client
+}
+
+// Config selects the audit sinks. Records go to every configured sink.
+type Config struct {
+	File       string // Path of the audit file; empty disables it
+	MaxSizeMB  int    // Size at which the file is rotated
+	MaxBackups int    // Rotated files kept next to the current one
+	Syslog     string // "local", or udp://host:port / tcp://host:port; empty disables it
+}
+
+// Logger writes audit records. A nil Logger discards them.
+type Logger struct {
+	mu    sync.Mutex
+	sinks []io.WriteCloser
+}
+
+// New opens the sinks in cfg. Returns nil when no sink is configured.
+func New(cfg Config) (*Logger, error) {
+	l := &Logger{}
+	if cfg.File != "" {
+		f, err := openRotatingFile(cfg.File, int64(cfg.MaxSizeMB)<<20, cfg.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		l.sinks = append(l.sinks, f)
+	}
+	if cfg.Syslog != "" {
+		s, err := dialSyslog(cfg.Syslog)
+		if err != nil {
+			_ = l.Close()
+			return nil, fmt.Errorf("failed to connect to audit syslog: %w", err)
+		}
+		l.sinks = append(l.sinks, s)
+	}
+	if len(l.sinks) == 0 {
+		return nil, nil
+	}
+	return l, nil
+}
+
+// Log writes rec to every sink. Write failures are logged, not returned:
+// an unavailable audit sink must not fail the request being audited.
+func (l *Logger) Log(rec Record) {
+	if l == nil {
+		return
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode audit record")
+		return
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, s := range l.sinks {
+		if _, err := s.Write(line); err != nil {
+			log.Error().Err(err).Msg("Failed to write audit record")
+		}
+	}
+}
+
+// Close flushes and closes the sinks.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var firstErr error
+	for _, s := range l.sinks {
+		if err := s.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	l.sinks = nil
+	return firstErr
+}
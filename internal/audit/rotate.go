@@ -0,0 +1,9 @@
+package audit
+
+import "github.com/Rorqualx/flaresolverr-go/internal/logfile"
+
+// openRotatingFile opens the audit file, rotated at maxSize bytes with
+// maxBackups older copies kept.
+func openRotatingFile(path string, maxSize int64, maxBackups int) (*logfile.File, error) {
+	return logfile.Open(path, logfile.Options{MaxSize: maxSize, MaxBackups: maxBackups})
+}
@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package audit
+
+import (
+	"errors"
+	"io"
+)
+
+// dialSyslog is unavailable where Go has no syslog client.
+func dialSyslog(string) (io.WriteCloser, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
@@ -0,0 +1,26 @@
+//go:build !windows && !plan9
+
+package audit
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"net/url"
+)
+
+// dialSyslog connects to the local syslog daemon ("local") or a remote one
+// (udp://host:port, tcp://host:port). Records are sent at LOG_INFO with
+// facility LOG_LOCAL0, tagged "flaresolverr-audit".
+func dialSyslog(target string) (io.WriteCloser, error) {
+	const priority = syslog.LOG_INFO | syslog.LOG_LOCAL0
+	const tag = "flaresolverr-audit"
+	if target == "local" {
+		return syslog.New(priority, tag)
+	}
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "udp" && u.Scheme != "tcp") || u.Host == "" {
+		return nil, fmt.Errorf("invalid syslog address %q: want local, udp://host:port or tcp://host:port", target)
+	}
+	return syslog.Dial(u.Scheme, u.Host, priority, tag)
+}
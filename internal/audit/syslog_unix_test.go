@@ -0,0 +1,37 @@
+//go:build !windows && !plan9
+
+package audit
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRemoteSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on UDP: %v", err)
+	}
+	defer conn.Close()
+
+	l, err := New(Config{Syslog: "udp://" + conn.LocalAddr().String()})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer l.Close()
+	l.Log(Record{Command: "request.get", Domain: "example.com", Outcome: "ok"})
+
+	buf := make([]byte, 2048)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("no syslog message received: %v", err)
+	}
+	msg := string(buf[:n])
+	// <134> is facility local0, severity info
+	if !strings.HasPrefix(msg, "<134>") || !strings.Contains(msg, "flaresolverr-audit") || !strings.Contains(msg, `"domain":"example.com"`) {
+		t.Errorf("syslog message = %q", msg)
+	}
+}
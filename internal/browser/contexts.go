@@ -0,0 +1,215 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+const (
+	// contextCallTimeout bounds creating and disposing one incognito context.
+	contextCallTimeout = 10 * time.Second
+
+	// contextHostSpawnTimeout bounds replacing a dead host process.
+	contextHostSpawnTimeout = 60 * time.Second
+)
+
+// contextHost is a Chrome process shared by incognito contexts in context
+// mode (BROWSER_POOL_MODE=context).
+type contextHost struct {
+	browser    *rod.Browser
+	active     atomic.Int32
+	restarting atomic.Bool
+}
+
+// ContextMode reports whether the pool hands out incognito browser contexts
+// instead of whole browsers.
+func (p *Pool) ContextMode() bool {
+	return p.config.BrowserPoolMode == config.BrowserPoolModeContext
+}
+
+// initContextHosts launches the shared host processes and fills the slot
+// semaphore. Called from NewPool instead of pre-warming whole browsers.
+func (p *Pool) initContextHosts() error {
+	hosts := p.config.ContextPoolHosts
+	log.Info().
+		Int("hosts", hosts).
+		Int("contexts", p.config.BrowserPoolSize).
+		Msg("Pre-warming context pool hosts")
+
+	p.contextSlots = make(chan struct{}, p.config.BrowserPoolSize)
+	for i := 0; i < hosts; i++ {
+		browser, err := p.spawnBrowser(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to spawn context host %d: %w", i, err)
+		}
+		p.mu.Lock()
+		p.contextHosts = append(p.contextHosts, &contextHost{browser: browser})
+		p.mu.Unlock()
+	}
+	p.availableCount.Store(int32(p.config.BrowserPoolSize))
+	return nil
+}
+
+// acquireContext waits for a free slot and opens a fresh incognito context
+// on the least busy host. Cookies, storage and cache are isolated per
+// context and discarded when it is released.
+func (p *Pool) acquireContext(ctx context.Context) (*rod.Browser, error) {
+	select {
+	case p.contextSlots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %v", types.ErrContextCanceled, ctx.Err())
+	case <-time.After(p.config.BrowserPoolTimeout):
+		p.stats.Errors.Add(1)
+		return nil, types.ErrBrowserPoolTimeout
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < p.config.ContextPoolHosts+1; attempt++ {
+		if p.closed.Load() {
+			<-p.contextSlots
+			return nil, types.ErrBrowserPoolClosed
+		}
+		host := p.pickContextHost()
+		if host == nil {
+			break
+		}
+		inc, err := host.browser.Timeout(contextCallTimeout).Incognito()
+		if err != nil {
+			lastErr = err
+			log.Warn().Err(err).Int("attempt", attempt).Msg("Context host unresponsive, restarting it")
+			p.stats.Errors.Add(1)
+			p.restartContextHost(host)
+			continue
+		}
+		inc = inc.CancelTimeout()
+
+		host.active.Add(1)
+		p.contexts.Store(inc, host)
+		p.availableCount.Add(-1)
+		p.stats.Acquired.Add(1)
+
+		// Each context gets its own identity rather than its host's
+		if p.userAgents != nil {
+			if identity, ok := p.userAgents.Pick(); ok {
+				p.identities.Store(inc, identity)
+			}
+		}
+
+		log.Debug().
+			Str("context_id", string(inc.BrowserContextID)).
+			Int32("host_active", host.active.Load()).
+			Msg("Browser context acquired from pool")
+		return inc, nil
+	}
+
+	<-p.contextSlots
+	p.stats.Errors.Add(1)
+	return nil, fmt.Errorf("%w: no context host available: %v", types.ErrBrowserUnhealthy, lastErr)
+}
+
+// pickContextHost returns the host with the fewest open contexts, preferring
+// hosts that are not being restarted.
+func (p *Pool) pickContextHost() *contextHost {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var best *contextHost
+	for _, h := range p.contextHosts {
+		if best == nil ||
+			(best.restarting.Load() && !h.restarting.Load()) ||
+			(best.restarting.Load() == h.restarting.Load() && h.active.Load() < best.active.Load()) {
+			best = h
+		}
+	}
+	return best
+}
+
+// releaseContext disposes an incognito context handed out by acquireContext,
+// closing its pages and dropping its cookies and storage, and frees its slot.
+// Reports false if browser is not a pooled context.
+func (p *Pool) releaseContext(browser *rod.Browser) bool {
+	v, ok := p.contexts.LoadAndDelete(browser)
+	if !ok {
+		return false
+	}
+	host, _ := v.(*contextHost)
+	p.identities.Delete(browser)
+
+	if err := browser.Timeout(contextCallTimeout).Close(); err != nil {
+		// The host may have died with the context; it is restarted on next use
+		log.Debug().Err(err).Msg("Failed to dispose browser context")
+	}
+	if host != nil {
+		host.active.Add(-1)
+	}
+
+	p.stats.Released.Add(1)
+	p.availableCount.Add(1)
+	<-p.contextSlots
+	log.Debug().Int64("total_released", p.stats.Released.Load()).Msg("Browser context released to pool")
+	return true
+}
+
+// restartContextHost replaces a dead host process in the background. Its
+// contexts are already gone; callers release them as usual.
+func (p *Pool) restartContextHost(host *contextHost) {
+	if p.closed.Load() || !host.restarting.CompareAndSwap(false, true) {
+		return
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), contextHostSpawnTimeout)
+		defer cancel()
+		browser, err := p.spawnBrowser(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to restart context host")
+			host.restarting.Store(false)
+			return
+		}
+
+		p.mu.Lock()
+		replaced := false
+		if !p.closed.Load() {
+			for i, h := range p.contextHosts {
+				if h == host {
+					p.contextHosts[i] = &contextHost{browser: browser}
+					replaced = true
+					break
+				}
+			}
+		}
+		p.mu.Unlock()
+
+		if !replaced {
+			p.CleanupBrowser(browser)
+			return
+		}
+		p.stats.Recycled.Add(1)
+		p.publishRecycled(host.browser, "host_crashed")
+		p.CleanupBrowser(host.browser)
+		log.Info().Msg("Context host restarted")
+	}()
+}
+
+// closeContextHosts shuts down every host process. Contexts still held by
+// callers die with their host; releasing them later only frees the slot.
+func (p *Pool) closeContextHosts() {
+	p.mu.Lock()
+	hosts := p.contextHosts
+	p.contextHosts = nil
+	p.mu.Unlock()
+
+	for _, h := range hosts {
+		p.CleanupBrowser(h.browser)
+	}
+}
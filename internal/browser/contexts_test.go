@@ -0,0 +1,96 @@
+package browser
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+)
+
+// TestPoolContextMode verifies that context mode hands out isolated incognito
+// contexts and returns their slots on release.
+func TestPoolContextMode(t *testing.T) {
+	skipCI(t)
+
+	cfg := testConfig()
+	cfg.BrowserPoolMode = config.BrowserPoolModeContext
+	cfg.ContextPoolHosts = 1
+	pool, err := NewPool(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	a, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Failed to acquire context: %v", err)
+	}
+	b, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Failed to acquire second context: %v", err)
+	}
+	if a.BrowserContextID == "" || a.BrowserContextID == b.BrowserContextID {
+		t.Fatalf("Expected distinct incognito contexts, got %q and %q", a.BrowserContextID, b.BrowserContextID)
+	}
+	if pool.Available() != 0 {
+		t.Errorf("Expected 0 available with all slots taken, got %d", pool.Available())
+	}
+
+	// Slots are exhausted until one is released
+	short, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Acquire(short); err == nil {
+		t.Error("Expected Acquire to block when all contexts are in use")
+	}
+
+	pool.Release(a)
+	pool.Release(a) // repeat release is a no-op
+	if pool.Available() != 1 {
+		t.Errorf("Expected 1 available after release, got %d", pool.Available())
+	}
+	pool.Release(b)
+	if pool.Available() != cfg.BrowserPoolSize {
+		t.Errorf("Expected %d available after releasing all, got %d", cfg.BrowserPoolSize, pool.Available())
+	}
+}
+
+// TestPickContextHost verifies that the least busy healthy host is chosen.
+func TestPickContextHost(t *testing.T) {
+	busy, idle, restarting := &contextHost{}, &contextHost{}, &contextHost{}
+	busy.active.Store(3)
+	idle.active.Store(1)
+	restarting.restarting.Store(true)
+
+	p := &Pool{contextHosts: []*contextHost{restarting, busy, idle}}
+	if got := p.pickContextHost(); got != idle {
+		t.Error("Expected the idle host to be picked")
+	}
+
+	idle.restarting.Store(true)
+	if got := p.pickContextHost(); got != busy {
+		t.Error("Expected a host that is not restarting to be preferred")
+	}
+
+	empty := &Pool{}
+	if empty.pickContextHost() != nil {
+		t.Error("Expected nil with no hosts")
+	}
+}
+
+// TestReleaseUnknownContext verifies that releasing a context the pool no
+// longer tracks does not return it to the browser channel.
+func TestReleaseUnknownContext(t *testing.T) {
+	p := &Pool{
+		config:    testConfig(),
+		available: make(chan *rod.Browser, 1),
+	}
+	p.Release(&rod.Browser{BrowserContextID: "stale"})
+	p.CleanupBrowser(&rod.Browser{BrowserContextID: "stale"})
+	if len(p.available) != 0 {
+		t.Error("Expected stale context not to be pooled")
+	}
+}
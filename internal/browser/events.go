@@ -0,0 +1,32 @@
+package browser
+
+import (
+	"context"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/events"
+)
+
+// browserPID returns the process ID of a browser this pool launched, or 0
+// for remote browsers.
+func (p *Pool) browserPID(browser *rod.Browser) int {
+	if v, ok := p.launchers.Load(browser); ok {
+		if l, ok := v.(*launcher.Launcher); ok {
+			return l.PID()
+		}
+	}
+	return 0
+}
+
+// publishSpawned announces a newly spawned or connected browser.
+func (p *Pool) publishSpawned(ctx context.Context, browser *rod.Browser) {
+	events.Publish(ctx, events.Event{Type: events.BrowserSpawned, PID: p.browserPID(browser)})
+}
+
+// publishRecycled announces that browser is being replaced. It must be
+// called before the browser is closed, while its PID is still known.
+func (p *Pool) publishRecycled(browser *rod.Browser, reason string) {
+	events.Publish(context.Background(), events.Event{Type: events.BrowserRecycled, PID: p.browserPID(browser), Reason: reason})
+}
@@ -175,43 +175,27 @@ func BuildStealthScriptWithProfile(profile *FingerprintProfile) string {
 }
 
 // ApplyStealthToPageWithProfile applies anti-detection measures with a custom fingerprint profile.
-// This should be called after page creation but BEFORE navigation.
+// This should be called once, after page creation but BEFORE navigation.
+//
+// The profile's User-Agent and Client Hints override is set first, then the
+// fingerprint overrides and stealth script are registered as one document_start
+// script, so every document the page loads sees the overrides before the
+// patches that read them, without re-evaluation after navigations.
 func ApplyStealthToPageWithProfile(page *rod.Page, profile *FingerprintProfile) error {
 	if profile == nil {
 		return ApplyStealthToPage(page)
 	}
 
-	log.Debug().Str("profile", profile.Name).Msg("Applying stealth patches with custom fingerprint")
+	log.Debug().Str("profile", profile.Name).Msg("Registering stealth patches with custom fingerprint")
 
-	// Inject fingerprint overrides before the stealth script
-	overrides := BuildFingerprintOverrides(profile)
-	if overrides != "" {
-		_, err := page.Evaluate(rod.Eval(overrides))
-		if err != nil {
-			log.Warn().Err(err).Msg("Failed to inject fingerprint overrides")
+	if profile.UserAgent != "" {
+		if err := SetUserAgent(page, profile.UserAgent); err != nil {
+			log.Warn().Err(err).Msg("Failed to set user agent from fingerprint profile")
 		}
 	}
 
-	// Register stealth for future navigations (document_start)
-	_, regErr := proto.PageAddScriptToEvaluateOnNewDocument{
-		Source: stealthScript,
-	}.Call(page)
-	if regErr != nil {
-		log.Warn().Err(regErr).Msg("Failed to register profiled stealth via EvalOnNewDocument")
-	}
-
-	// Also evaluate immediately for current context
-	_, err := page.Evaluate(rod.Eval(stealthScript))
-	if err != nil {
-		errStr := err.Error()
-		if strings.Contains(errStr, "SyntaxError") {
-			return fmt.Errorf("stealth script syntax error: %w", err)
-		}
-		if strings.Contains(errStr, "ReferenceError") {
-			return fmt.Errorf("stealth script reference error: %w", err)
-		}
-		log.Warn().Err(err).Msg("Stealth script had non-fatal errors, continuing")
-		return nil
+	if err := registerStealthScript(page, BuildStealthScriptWithProfile(profile)); err != nil {
+		return err
 	}
 
 	// Apply viewport override if profile specifies screen size
@@ -226,13 +210,6 @@ func ApplyStealthToPageWithProfile(page *rod.Page, profile *FingerprintProfile)
 		}
 	}
 
-	// Apply user agent override if specified
-	if profile.UserAgent != "" {
-		if err := SetUserAgent(page, profile.UserAgent); err != nil {
-			log.Warn().Err(err).Msg("Failed to set user agent from fingerprint profile")
-		}
-	}
-
 	return nil
 }
 
@@ -130,6 +130,23 @@ func TestBuildFingerprintOverrides(t *testing.T) {
 	}
 }
 
+// TestBuildStealthScriptWithProfile verifies the overrides are registered in
+// the same document_start script as the patches, ahead of them, so every
+// document sets them before the patches read them.
+func TestBuildStealthScriptWithProfile(t *testing.T) {
+	profile := &FingerprintProfile{WebGLVendor: "Google Inc. (NVIDIA)", DisabledPatches: []string{"canvas"}}
+	got := BuildStealthScriptWithProfile(profile)
+	if !strings.HasSuffix(got, stealthScript) {
+		t.Fatal("Expected the stealth script at the end")
+	}
+	overrides := strings.TrimSuffix(got, stealthScript)
+	for _, w := range []string{`window.__stealthWebGLVendor = "Google Inc. (NVIDIA)"`, `window.__stealthDisable_canvas = true`} {
+		if !strings.Contains(overrides, w) {
+			t.Errorf("Expected %q ahead of the stealth script", w)
+		}
+	}
+}
+
 func TestValidProfileName(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -0,0 +1,71 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ApplyLocaleOverride makes the page present locale, a language tag such as
+// "fr-FR", everywhere a site can read it: Intl formatting through
+// Emulation.setLocaleOverride, the Accept-Language header and
+// navigator.language(s). The header travels with the user agent override, so
+// userAgent is re-sent with it; empty keeps the browser's own. Call it before
+// navigation. An empty locale is a no-op.
+func ApplyLocaleOverride(page *rod.Page, locale, userAgent string) error {
+	if locale == "" {
+		return nil
+	}
+	if err := (proto.EmulationSetLocaleOverride{Locale: locale}).Call(page); err != nil {
+		return fmt.Errorf("set locale override %q: %w", locale, err)
+	}
+
+	if userAgent == "" {
+		version, err := proto.BrowserGetVersion{}.Call(page)
+		if err != nil {
+			return fmt.Errorf("get browser user agent: %w", err)
+		}
+		userAgent = strings.Replace(version.UserAgent, "HeadlessChrome/", "Chrome/", 1)
+	}
+	override := userAgentOverride(userAgent)
+	override.AcceptLanguage = acceptLanguage(locale)
+	if err := override.Call(page); err != nil {
+		return fmt.Errorf("set accept-language %q: %w", locale, err)
+	}
+
+	// The stealth scripts pin navigator.languages to en-US; registered after
+	// them, this definition wins.
+	languages, _ := json.Marshal(localeLanguages(locale))
+	script := fmt.Sprintf(`(() => {
+  const languages = %s;
+  Object.defineProperty(navigator, 'languages', { get: () => languages.slice(), configurable: true });
+  Object.defineProperty(navigator, 'language', { get: () => languages[0], configurable: true });
+})();`, languages)
+	if _, err := page.EvalOnNewDocument(script); err != nil {
+		return fmt.Errorf("set navigator.languages for %q: %w", locale, err)
+	}
+	return nil
+}
+
+// localeLanguages returns the languages a browser set to locale reports: the
+// tag, then its bare language ("fr-FR" gives fr-FR, fr).
+func localeLanguages(locale string) []string {
+	languages := []string{locale}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		languages = append(languages, base)
+	}
+	return languages
+}
+
+// acceptLanguage returns the Accept-Language header Chrome sends for locale.
+func acceptLanguage(locale string) string {
+	languages := localeLanguages(locale)
+	header := languages[0]
+	if len(languages) > 1 {
+		header += "," + languages[1] + ";q=0.9"
+	}
+	return header
+}
@@ -0,0 +1,27 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod"
+)
+
+func TestApplyLocaleOverrideEmpty(t *testing.T) {
+	if err := ApplyLocaleOverride((*rod.Page)(nil), "", ""); err != nil {
+		t.Errorf("expected nil error for empty locale, got %v", err)
+	}
+}
+
+func TestAcceptLanguage(t *testing.T) {
+	tests := map[string]string{
+		"fr-FR":      "fr-FR,fr;q=0.9",
+		"en-US":      "en-US,en;q=0.9",
+		"de":         "de",
+		"zh-Hant-TW": "zh-Hant-TW,zh;q=0.9",
+	}
+	for locale, want := range tests {
+		if got := acceptLanguage(locale); got != want {
+			t.Errorf("acceptLanguage(%q) = %q, want %q", locale, got, want)
+		}
+	}
+}
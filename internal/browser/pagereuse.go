@@ -0,0 +1,178 @@
+package browser
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rs/zerolog/log"
+)
+
+// pageResetTimeout bounds resetting a tab for reuse and checking a parked
+// tab is still alive. A tab that takes longer is closed instead.
+const pageResetTimeout = 5 * time.Second
+
+// PageReuse reports whether pooled browsers keep their tab between requests
+// (PAGE_REUSE_ENABLED). Context mode discards the whole context instead.
+func (p *Pool) PageReuse() bool {
+	return p.config.PageReuseEnabled && !p.ContextMode()
+}
+
+// TakePage returns the tab the previous request parked on browser, or nil
+// when there is none or it no longer responds. The tab keeps the stealth
+// scripts it was created with; per-request settings must be applied again.
+func (p *Pool) TakePage(browser *rod.Browser) *rod.Page {
+	v, ok := p.idlePages.LoadAndDelete(browser)
+	if !ok {
+		return nil
+	}
+	page := v.(*rod.Page)
+	if _, err := page.Timeout(pageResetTimeout).Eval(`() => document.readyState`); err != nil {
+		log.Debug().Err(err).Msg("Parked tab is unresponsive, creating a new one")
+		_ = page.Close()
+		return nil
+	}
+	return page
+}
+
+// WatchPage records every origin page sends a request to until it is
+// parked: redirect hops, iframes and subresource hosts as well as the page's
+// own, so ParkPage can clear the storage each of them left. Call it before
+// the request's first navigation on a tab that will be parked.
+func (p *Pool) WatchPage(page *rod.Page) {
+	if page == nil || !p.PageReuse() {
+		return
+	}
+	l := &originLog{origins: make(map[string]struct{})}
+	listen, cancel := page.WithCancel()
+	l.stop = cancel
+	if old, loaded := p.pageOrigins.Swap(page.TargetID, l); loaded {
+		old.(*originLog).stop()
+	}
+	wait := listen.EachEvent(func(e *proto.NetworkRequestWillBeSent) {
+		if e.Request != nil {
+			l.add(e.Request.URL)
+		}
+	})
+	go wait()
+}
+
+// ParkPage resets page and keeps it on browser for the browser's next
+// request, saving the cost of creating and patching a new stealth tab.
+// Cookies and the storage of every origin seen by WatchPage are cleared, so
+// the next request starts as clean as on a new tab. The page is closed
+// instead when reuse is off, the browser is a context, or the reset fails.
+func (p *Pool) ParkPage(browser *rod.Browser, page *rod.Page) {
+	if page == nil {
+		return
+	}
+	var origins []string
+	if v, ok := p.pageOrigins.LoadAndDelete(page.TargetID); ok {
+		l := v.(*originLog)
+		l.stop()
+		origins = l.list()
+	}
+	if !p.PageReuse() || browser == nil || browser.BrowserContextID != "" || p.closed.Load() {
+		_ = page.Close()
+		return
+	}
+	if err := resetPage(page.Timeout(pageResetTimeout), origins); err != nil {
+		log.Debug().Err(err).Msg("Failed to reset tab for reuse, closing it")
+		_ = page.Close()
+		return
+	}
+	if old, loaded := p.idlePages.Swap(browser, page); loaded && old.(*rod.Page) != page {
+		_ = old.(*rod.Page).Close()
+	}
+}
+
+// parkedPage returns the tab parked on browser, or nil.
+func (p *Pool) parkedPage(browser *rod.Browser) *rod.Page {
+	if v, ok := p.idlePages.Load(browser); ok {
+		return v.(*rod.Page)
+	}
+	return nil
+}
+
+// originLog is the set of origins a tab has sent requests to.
+type originLog struct {
+	stop func()
+
+	mu      sync.Mutex
+	origins map[string]struct{}
+}
+
+// add records the origin of rawURL, if it has one storage can be kept for.
+func (l *originLog) add(rawURL string) {
+	origin := urlOrigin(rawURL)
+	if origin == "" {
+		return
+	}
+	l.mu.Lock()
+	l.origins[origin] = struct{}{}
+	l.mu.Unlock()
+}
+
+// list returns the recorded origins, sorted.
+func (l *originLog) list() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	origins := make([]string, 0, len(l.origins))
+	for origin := range l.origins {
+		origins = append(origins, origin)
+	}
+	sort.Strings(origins)
+	return origins
+}
+
+// urlOrigin returns the scheme://host origin of an http(s) URL, or "".
+func urlOrigin(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// resetPage returns a used tab to a blank state: off the site it was on,
+// with the browser's cookies cleared, the storage, caches and service
+// workers of every origin in origins and of the site it ended on cleared,
+// and without the per-request overrides that would otherwise carry over.
+func resetPage(page *rod.Page, origins []string) error {
+	if info, err := page.Info(); err == nil {
+		if origin := urlOrigin(info.URL); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	// Interception left on by a solve would stall the next navigation
+	_ = proto.FetchDisable{}.Call(page)
+	if err := page.Navigate("about:blank"); err != nil {
+		return fmt.Errorf("navigate to about:blank: %w", err)
+	}
+	cleared := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		if cleared[origin] {
+			continue
+		}
+		cleared[origin] = true
+		if err := (proto.StorageClearDataForOrigin{Origin: origin, StorageTypes: "all"}).Call(page); err != nil {
+			return fmt.Errorf("clear storage for %s: %w", origin, err)
+		}
+	}
+	if err := (proto.NetworkClearBrowserCookies{}).Call(page); err != nil {
+		return fmt.Errorf("clear cookies: %w", err)
+	}
+	if err := (proto.NetworkSetExtraHTTPHeaders{Headers: proto.NetworkHeaders{}}).Call(page); err != nil {
+		return fmt.Errorf("clear extra headers: %w", err)
+	}
+	// An empty timezone restores the host's
+	if err := (proto.EmulationSetTimezoneOverride{TimezoneID: ""}).Call(page); err != nil {
+		return fmt.Errorf("clear timezone override: %w", err)
+	}
+	return nil
+}
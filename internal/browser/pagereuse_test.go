@@ -0,0 +1,116 @@
+package browser
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+)
+
+// TestPageReuseModes verifies reuse follows PAGE_REUSE_ENABLED and is never
+// offered in context mode.
+func TestPageReuseModes(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		mode    string
+		want    bool
+	}{
+		{"disabled", false, config.BrowserPoolModeBrowser, false},
+		{"browser mode", true, config.BrowserPoolModeBrowser, true},
+		{"context mode", true, config.BrowserPoolModeContext, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Pool{config: &config.Config{PageReuseEnabled: tt.enabled, BrowserPoolMode: tt.mode}}
+			if got := p.PageReuse(); got != tt.want {
+				t.Errorf("PageReuse() = %v, want %v", got, tt.want)
+			}
+			if page := p.TakePage(nil); page != nil {
+				t.Error("TakePage() returned a tab nothing was parked as")
+			}
+		})
+	}
+}
+
+// TestPoolPageReuse verifies a parked tab survives Release, comes back to the
+// browser's next request, and has lost the previous request's cookies.
+func TestPoolPageReuse(t *testing.T) {
+	skipCI(t)
+
+	cfg := testConfig()
+	cfg.BrowserPoolSize = 1
+	cfg.PageReuseEnabled = true
+	pool, err := NewPool(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	b, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Failed to acquire browser: %v", err)
+	}
+	page, err := b.Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		t.Fatalf("Failed to create page: %v", err)
+	}
+	if err := page.SetCookies([]*proto.NetworkCookieParam{{Name: "cf_clearance", Value: "minted", Domain: "example.com", Path: "/"}}); err != nil {
+		t.Fatalf("Failed to set cookie: %v", err)
+	}
+	pool.ParkPage(b, page)
+	pool.Release(b)
+
+	b, err = pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Failed to reacquire browser: %v", err)
+	}
+	defer pool.Release(b)
+	reused := pool.TakePage(b)
+	if reused == nil || reused.TargetID != page.TargetID {
+		t.Fatal("Expected the parked tab back")
+	}
+	cookies, err := proto.StorageGetCookies{}.Call(reused)
+	if err != nil {
+		t.Fatalf("Failed to read cookies: %v", err)
+	}
+	if len(cookies.Cookies) != 0 {
+		t.Errorf("Expected the parked tab's cookies cleared, got %d", len(cookies.Cookies))
+	}
+	if pool.TakePage(b) != nil {
+		t.Error("Expected the parked tab to be handed out once")
+	}
+	_ = reused.Close()
+}
+
+// TestOriginLog verifies a tab's log keeps one entry per http(s) origin it
+// sent requests to, redirect hops and iframes included.
+func TestOriginLog(t *testing.T) {
+	l := &originLog{origins: make(map[string]struct{})}
+	for _, u := range []string{
+		"https://example.com/",
+		"https://example.com/cdn-cgi/challenge-platform/h/g/orchestrate",
+		"http://www.example.com/redirect",
+		"https://challenges.cloudflare.com/cdn-cgi/challenge-platform/turnstile",
+		"https://example.com:8443/api",
+		"about:blank",
+		"data:text/html,hello",
+		"blob:https://example.com/3f2a",
+		"://bad",
+	} {
+		l.add(u)
+	}
+	want := []string{
+		"http://www.example.com",
+		"https://challenges.cloudflare.com",
+		"https://example.com",
+		"https://example.com:8443",
+	}
+	if got := l.list(); !slices.Equal(got, want) {
+		t.Errorf("list() = %v, want %v", got, want)
+	}
+}
@@ -5,7 +5,9 @@ package browser
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"runtime"
 	"strings"
 	"sync"
@@ -71,6 +73,72 @@ type Pool struct {
 	// fill the container's writable layer over time (GitHub issue #6).
 	launchers sync.Map // map[*rod.Browser]*launcher.Launcher
 
+	// Resident memory of the launched browsers from the last monitorMemory
+	// pass; nil until the first sample or where it cannot be read.
+	memory atomic.Pointer[MemorySample]
+
+	// Local forwarders for authenticated SOCKS5 proxies, closed with their
+	// browser (see socks.go).
+	forwarders sync.Map // map[*rod.Browser]*socksForwarder
+
+	// Optional weighted user agent pool. When set, each pooled browser is
+	// assigned its own identity at spawn/recycle time instead of the whole
+	// fleet sharing one UA, reducing cross-request correlation.
+	userAgents *UserAgentPool
+	identities sync.Map // map[*rod.Browser]UserAgentEntry
+
+	// chromeVersion is the installed browser's version, read from the first
+	// browser that reports it; nil until then.
+	chromeVersion atomic.Pointer[string]
+
+	// Proxy for launches without one of their own (PROXY_PAC_URL, PROXY_FROM_ENV)
+	systemProxy systemProxy
+
+	// Remote DevTools endpoints (REMOTE_BROWSER_URLS). When set, pooled
+	// browsers are connections to these instead of local Chrome processes.
+	remoteURLs []string
+	remoteNext atomic.Int64 // round-robin cursor into remoteURLs
+	remotes    sync.Map     // map[*rod.Browser]*cdp.WebSocket
+
+	// Context mode (BROWSER_POOL_MODE=context): Acquire hands out incognito
+	// contexts on a few shared hosts, one per slot in contextSlots.
+	contextHosts []*contextHost // guarded by mu
+	contextSlots chan struct{}
+	contexts     sync.Map // map[*rod.Browser]*contextHost
+
+	// Named browser profiles (BROWSER_PROFILES_PATH), selected per request.
+	// Persistent profiles keep their user-data-dir, one browser at a time.
+	profiles       map[string]*BrowserProfile
+	dataDirLocks   sync.Map // map[string]chan struct{}, one slot per user-data-dir
+	persistentDirs sync.Map // map[*rod.Browser]string, browsers holding a user-data-dir
+
+	// Dedicated sub-pools (PROXY_POOL_SIZE): warm browsers parked between
+	// requests with the same per-request proxy and profile, oldest first.
+	proxyIdleMu   sync.Mutex
+	proxyIdle     []*idleProxyBrowser
+	proxyBrowsers sync.Map // map[*rod.Browser]dedicatedKey, browsers out via AcquireDedicated
+
+	// Standby replacements for stale browsers, swapped in when the stale
+	// browser next passes through Acquire or Release (see prepareStandby).
+	standby sync.Map // map[*rod.Browser]*rod.Browser, stale -> replacement
+
+	// Tabs kept between requests (PAGE_REUSE_ENABLED), one per pooled
+	// browser, reset and ready for its next request (see pagereuse.go).
+	idlePages sync.Map // map[*rod.Browser]*rod.Page
+
+	// Origins each tab in use for reuse has sent requests to, cleared when
+	// the tab is parked (see WatchPage).
+	pageOrigins sync.Map // map[proto.TargetTargetID]*originLog
+
+	// Target number of pooled browsers, changed at runtime by Resize
+	targetSize atomic.Int32
+	resizeMu   sync.Mutex
+
+	// Acquire calls queued on a saturated pool (see offerBrowser)
+	waitMu  sync.Mutex
+	waiters waiterQueue
+	waitSeq uint64
+
 	// Statistics for monitoring
 	stats PoolStats
 }
@@ -94,6 +162,92 @@ func (p *Pool) GetControlURL(browser *rod.Browser) (string, bool) {
 	return s, ok
 }
 
+// IdentityFor returns the user agent identity assigned to a pooled browser.
+// Returns false when no user agent pool is configured or the browser was not
+// spawned by the pool (e.g. SpawnWithProxy browsers).
+func (p *Pool) IdentityFor(browser *rod.Browser) (UserAgentEntry, bool) {
+	val, ok := p.identities.Load(browser)
+	if !ok {
+		return UserAgentEntry{}, false
+	}
+	e, ok := val.(UserAgentEntry)
+	return e, ok
+}
+
+// RotateRequestIdentity gives a pooled browser a freshly picked identity for
+// a request when USER_AGENT_ROTATION=request.
+func (p *Pool) RotateRequestIdentity(browser *rod.Browser) {
+	if p.config.UserAgentRotation == config.UserAgentRotationRequest {
+		p.rotateIdentity(browser)
+	}
+}
+
+// RotateSessionIdentity gives a pooled browser a freshly picked identity for
+// a new session when USER_AGENT_ROTATION is session or request.
+func (p *Pool) RotateSessionIdentity(browser *rod.Browser) {
+	switch p.config.UserAgentRotation {
+	case config.UserAgentRotationSession, config.UserAgentRotationRequest:
+		p.rotateIdentity(browser)
+	}
+}
+
+// rotateIdentity replaces the identity of a browser that has one.
+func (p *Pool) rotateIdentity(browser *rod.Browser) {
+	if p.userAgents == nil || browser == nil {
+		return
+	}
+	if _, ok := p.identities.Load(browser); !ok {
+		return // not a pooled browser
+	}
+	if identity, ok := p.userAgents.Pick(); ok {
+		p.identities.Store(browser, identity)
+		log.Debug().
+			Str("user_agent", identity.UserAgent).
+			Str("profile", identity.Profile).
+			Msg("Rotated browser user agent identity")
+	}
+}
+
+// ChromeVersion returns the installed browser's version (e.g.
+// "136.0.7103.92"), or "" until a browser has reported it.
+func (p *Pool) ChromeVersion() string {
+	if v := p.chromeVersion.Load(); v != nil {
+		return *v
+	}
+	return ""
+}
+
+// detectChromeVersion reads the browser's version once per pool, so rotated
+// user agents and their Client Hints can match it.
+func (p *Pool) detectChromeVersion(browser *rod.Browser) {
+	if p.chromeVersion.Load() != nil {
+		return
+	}
+	result, err := proto.BrowserGetVersion{}.Call(browser.Timeout(5 * time.Second))
+	if err != nil {
+		log.Debug().Err(err).Msg("Could not read browser version")
+		return
+	}
+	_, version, ok := strings.Cut(result.Product, "/")
+	if !ok || version == "" {
+		return
+	}
+	if !p.chromeVersion.CompareAndSwap(nil, &version) {
+		return
+	}
+	setInstalledChromeVersion(version)
+	if p.userAgents != nil {
+		p.userAgents.SetChromeVersion(version)
+	}
+	log.Debug().Str("version", version).Msg("Detected browser version")
+}
+
+// UserAgentPool returns the configured user agent pool, or nil if none.
+// Callers may Swap its entries; new identities apply as browsers are recycled.
+func (p *Pool) UserAgentPool() *UserAgentPool {
+	return p.userAgents
+}
+
 // GetBrowserPath returns the configured browser path.
 // Used by the solver's two-phase bypass to launch a clean Chrome process.
 func (p *Pool) GetBrowserPath() string {
@@ -104,6 +258,10 @@ func (p *Pool) GetBrowserPath() string {
 // instead of being returned to the pool. Call this when a browser is known
 // to be in a bad state after a long operation.
 func (p *Pool) RecycleBrowser(browser *rod.Browser) {
+	// A released context is never reused, so disposing it is enough
+	if p.releaseContext(browser) || browser.BrowserContextID != "" {
+		return
+	}
 	go p.recycleBrowser(browser)
 }
 
@@ -129,11 +287,86 @@ func NewPool(cfg *config.Config) (*Pool, error) {
 
 	pool := &Pool{
 		config:     cfg,
-		available:  make(chan *rod.Browser, cfg.BrowserPoolSize),
+		available:  make(chan *rod.Browser, config.MaxBrowserPoolSize), // room to grow via Resize
 		browsers:   make([]*browserEntry, 0, cfg.BrowserPoolSize),
 		stopCh:     make(chan struct{}),
 		recycleSem: make(chan struct{}, 4), // Issue #11: Limit concurrent recycles to 4
 	}
+	pool.targetSize.Store(int32(cfg.BrowserPoolSize))
+
+	if cfg.UserAgentPoolPath != "" {
+		uaPool, err := NewUserAgentPool(cfg.UserAgentPoolPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load user agent pool: %w", err)
+		}
+		pool.userAgents = uaPool
+		log.Info().
+			Str("path", cfg.UserAgentPoolPath).
+			Int("entries", len(uaPool.Entries())).
+			Msg("User agent rotation enabled")
+	}
+
+	if cfg.BrowserProfilesPath != "" {
+		profiles, err := LoadBrowserProfiles(cfg.BrowserProfilesPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load browser profiles: %w", err)
+		}
+		if err := assignProfileDataDirs(profiles, cfg.BrowserProfileDataDir); err != nil {
+			return nil, err
+		}
+		pool.profiles = profiles
+		log.Info().
+			Str("path", cfg.BrowserProfilesPath).
+			Int("profiles", len(profiles)).
+			Msg("Browser profiles loaded")
+	}
+
+	sysProxy, err := loadSystemProxy(cfg.ProxyPACURL, cfg.ProxyFromEnv, os.Getenv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROXY_PAC_URL: %w", err)
+	}
+	if sysProxy.configured() {
+		pool.systemProxy = sysProxy
+		log.Info().Str("proxy", sysProxy.describe()).Msg("System proxy applies to browsers launched without a proxy")
+		if sysProxy.droppedCredentials {
+			log.Warn().Msg("Credentials in the proxy environment variables are not passed to Chrome; use PROXY_URL with PROXY_USERNAME/PROXY_PASSWORD for an authenticated proxy")
+		}
+		if cfg.ProxyURL != "" {
+			log.Warn().Msg("PROXY_URL is set, so pooled browsers ignore the system proxy")
+		}
+	}
+
+	remoteURLs, err := ParseRemoteBrowserURLs(cfg.RemoteBrowserURLs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REMOTE_BROWSER_URLS: %w", err)
+	}
+	if len(remoteURLs) > 0 {
+		pool.remoteURLs = remoteURLs
+		logRemoteEndpoints(remoteURLs)
+	}
+
+	if cfg.ProxyPoolSize > 0 {
+		pool.wg.Add(1)
+		go func() {
+			defer pool.wg.Done()
+			pool.proxyPoolSweeper()
+		}()
+	}
+
+	if pool.ContextMode() {
+		if err := pool.initContextHosts(); err != nil {
+			log.Error().Err(err).Msg("Failed to spawn browser during pool initialization")
+			if closeErr := pool.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("Failed to close pool during cleanup")
+			}
+			return nil, err
+		}
+		log.Info().
+			Int("pool_size", cfg.BrowserPoolSize).
+			Int("hosts", cfg.ContextPoolHosts).
+			Msg("Browser pool initialized in context mode")
+		return pool, nil
+	}
 
 	// Pre-warm the pool by launching all browsers
 	log.Info().Int("count", cfg.BrowserPoolSize).Msg("Pre-warming browser pool")
@@ -188,7 +421,7 @@ func NewPool(cfg *config.Config) (*Pool, error) {
 // 1. Use Xvfb virtual display (HEADLESS=false) - real headed browser
 // 2. Disable automation-controlled blink features
 // 3. Use consistent, realistic user agent
-// 4. Proper WebGL rendering with SwiftShader
+// 4. Realistic WebGL rendering (see GPU_MODE)
 // 5. No flags that reveal automation
 //
 // The proxyURL parameter sets the --proxy-server flag for Chrome.
@@ -247,6 +480,8 @@ func (p *Pool) createLauncher(proxyURL string) *launcher.Launcher {
 		l = l.Set("proxy-server", proxyURL)
 		// Use redacted proxy URL in logs to prevent credential exposure
 		log.Debug().Str("proxy", security.RedactProxyURL(proxyURL)).Msg("Browser proxy configured")
+	} else {
+		l = p.systemProxy.apply(l)
 	}
 
 	// ========================================
@@ -288,17 +523,8 @@ func (p *Pool) createLauncher(proxyURL string) *launcher.Launcher {
 	// 4. Enable network service features (normal browser behavior)
 	l = l.Set("enable-features", "NetworkService,NetworkServiceInProcess")
 
-	// 5. WebGL — prefer system GPU via ANGLE/Vulkan, fallback to SwiftShader
-	// SwiftShader reports "SwiftShader Device (0x0000C0DE)" which is an instant
-	// bot detection signal for Cloudflare Enterprise. Using the system's Vulkan
-	// driver (via ANGLE) reports the real GPU if available.
-	// --ignore-gpu-blocklist allows Chrome to use GPUs it would normally blacklist.
-	// --disable-gpu-sandbox is required for GPU access in Docker containers.
-	l = l.Set("use-gl", "angle").
-		Set("use-angle", "vulkan").
-		Set("ignore-gpu-blocklist").
-		Set("enable-webgl"). // Explicitly enable WebGL
-		Set("enable-webgl2") // Enable WebGL 2.0
+	// 5. WebGL and compositing — see applyGPUMode
+	l = applyGPUMode(l, p.config.GPUMode, isARM())
 
 	// 6. Ignore certificate errors (like original FlareSolverr)
 	// Required for some proxies and helps avoid SSL-related detection
@@ -352,16 +578,44 @@ func (p *Pool) createLauncher(proxyURL string) *launcher.Launcher {
 	// GPU sandbox - required for container environments
 	l = l.Set("disable-gpu-sandbox")
 
-	// ARM-specific: Use software compositing since hardware GPU compositing
-	// may not be available on ARM devices (Raspberry Pi, etc.)
-	if isARM() {
-		l = l.Set("disable-gpu-compositing")
-		log.Debug().Msg("ARM detected: using software compositing")
-	}
-
 	return l
 }
 
+// applyGPUMode sets the GL backend and compositing flags for a GPU_MODE.
+//
+// SwiftShader reports "SwiftShader Device (0x0000C0DE)" as the WebGL
+// renderer, an instant bot detection signal for Cloudflare Enterprise, so
+// every mode but "software" goes through a real driver: ANGLE over Vulkan
+// reports the actual GPU when one is available. "auto" keeps the historical
+// platform defaults — ANGLE everywhere, with software compositing on ARM
+// where hardware compositing is often missing (Raspberry Pi, etc.). ARM hosts
+// with working GPUs should pick "angle" or "egl" explicitly.
+// --ignore-gpu-blocklist allows Chrome to use GPUs it would normally blacklist.
+func applyGPUMode(l *launcher.Launcher, mode string, arm bool) *launcher.Launcher {
+	switch mode {
+	case config.GPUModeEGL:
+		l = l.Set("use-gl", "egl").
+			Set("ignore-gpu-blocklist")
+	case config.GPUModeSoftware:
+		// Chrome 137+ only allows WebGL on SwiftShader behind this flag
+		l = l.Set("use-gl", "angle").
+			Set("use-angle", "swiftshader").
+			Set("enable-unsafe-swiftshader").
+			Set("disable-gpu-compositing")
+		log.Warn().Msg("GPU_MODE=software: WebGL reports the SwiftShader renderer, which bot detection flags")
+	default:
+		l = l.Set("use-gl", "angle").
+			Set("use-angle", "vulkan").
+			Set("ignore-gpu-blocklist")
+		if mode != config.GPUModeANGLE && arm {
+			l = l.Set("disable-gpu-compositing")
+			log.Debug().Msg("ARM detected: using software compositing")
+		}
+	}
+	// Explicitly enable WebGL and WebGL 2.0
+	return l.Set("enable-webgl").Set("enable-webgl2")
+}
+
 // spawnBrowser launches a new browser instance.
 // This is an internal method - external code should use Acquire/Release.
 // Each call creates a fresh launcher since launchers can only be used once.
@@ -380,10 +634,32 @@ func (p *Pool) spawnBrowser(ctx context.Context) (*rod.Browser, error) {
 	}
 	log.Debug().Msg("Spawning new browser instance")
 
+	if p.IsRemote() {
+		browser, err := p.connectRemote(ctx)
+		if err != nil {
+			return nil, err
+		}
+		p.prepareBrowser(browser)
+		p.publishSpawned(ctx, browser)
+		return browser, nil
+	}
+
+	// An authenticated SOCKS5 default proxy is reached through a forwarder
+	chromeProxy, fwd, err := launchProxy(ProxyLaunchURL(p.config.ProxyURL, p.config.ProxyUsername, p.config.ProxyPassword))
+	if err != nil {
+		return nil, err
+	}
+	spawned := false
+	defer func() {
+		if !spawned && fwd != nil {
+			fwd.Close()
+		}
+	}()
+
 	// Create a fresh launcher for this browser instance
 	// (launchers can only launch once, so we need a new one each time)
 	// Pass the default proxy from config (may be empty)
-	l := p.createLauncher(p.config.ProxyURL)
+	l := p.createLauncher(chromeProxy)
 
 	// Fix HIGH: Add timeout to Launch() to prevent indefinite blocking
 	const launchTimeout = 60 * time.Second
@@ -427,7 +703,25 @@ func (p *Pool) spawnBrowser(ctx context.Context) (*rod.Browser, error) {
 		return nil, fmt.Errorf("failed to connect to browser: %w", err)
 	}
 
-	// Configure browser-level settings
+	log.Debug().Str("url", url).Msg("Browser spawned successfully")
+
+	// Store control URL for reconnection support
+	p.controlURLs.Store(browser, url)
+	// Retain the launcher so we can clean its user-data dir on close
+	p.launchers.Store(browser, l)
+	if fwd != nil {
+		p.forwarders.Store(browser, fwd)
+	}
+	spawned = true
+
+	p.prepareBrowser(browser)
+	p.publishSpawned(ctx, browser)
+	return browser, nil
+}
+
+// prepareBrowser applies browser-level settings to a newly spawned or
+// connected pool browser and assigns its identity.
+func (p *Pool) prepareBrowser(browser *rod.Browser) {
 	// Only ignore certificate errors if explicitly configured (security risk)
 	if p.config.IgnoreCertErrors {
 		log.Warn().Msg("Certificate validation disabled - MITM attacks possible")
@@ -436,25 +730,31 @@ func (p *Pool) spawnBrowser(ctx context.Context) (*rod.Browser, error) {
 		}
 	}
 
-	log.Debug().Str("url", url).Msg("Browser spawned successfully")
+	p.detectChromeVersion(browser)
 
-	// Store control URL for reconnection support
-	p.controlURLs.Store(browser, url)
-	// Retain the launcher so we can clean its user-data dir on close
-	p.launchers.Store(browser, l)
-
-	return browser, nil
+	// Assign a rotated identity for this browser's lifetime
+	if p.userAgents != nil {
+		if identity, ok := p.userAgents.Pick(); ok {
+			p.identities.Store(browser, identity)
+			log.Debug().
+				Str("user_agent", identity.UserAgent).
+				Str("profile", identity.Profile).
+				Msg("Assigned user agent identity to browser")
+		}
+	}
 }
 
 // LaunchOptions configures a custom browser spawn with per-session overrides.
 type LaunchOptions struct {
-	ProxyURL   string   // Proxy URL (replaces pool default)
-	WindowSize string   // "width,height" e.g. "1280,720"
-	Language   string   // Accept-Language e.g. "fr-FR"
-	Timezone   string   // Timezone for stealth patches (applied at JS level)
-	Headless   *bool    // Override global headless setting
-	DisableGPU *bool    // Force software rendering
-	ExtraArgs  []string // Pre-validated extra Chrome flags
+	ProxyURL    string   // Proxy URL (replaces pool default)
+	BrowserPath string   // Chrome binary (replaces BROWSER_PATH)
+	UserDataDir string   // Persistent profile dir, used exclusively and kept on cleanup
+	WindowSize  string   // "width,height" e.g. "1280,720"
+	Language    string   // Accept-Language e.g. "fr-FR"
+	Timezone    string   // Timezone for stealth patches (applied at JS level)
+	Headless    *bool    // Override global headless setting
+	DisableGPU  *bool    // Force software rendering
+	ExtraArgs   []string // Pre-validated extra Chrome flags
 }
 
 // SpawnWithOptions creates a new browser with custom launch options.
@@ -485,10 +785,41 @@ func (p *Pool) SpawnWithOptions(ctx context.Context, opts LaunchOptions) (*rod.B
 		Str("language", opts.Language).
 		Msg("Spawning browser with custom options")
 
+	// An authenticated SOCKS5 proxy is reached through a forwarder
+	chromeProxy, fwd, err := launchProxy(opts.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+	spawned := false
+	defer func() {
+		if !spawned && fwd != nil {
+			fwd.Close()
+		}
+	}()
+
 	// Create launcher with proxy
-	l := p.createLauncher(opts.ProxyURL)
+	l := p.createLauncher(chromeProxy)
+
+	// A persistent profile dir is held until CleanupBrowser
+	if dir := opts.UserDataDir; dir != "" {
+		if err := p.lockUserDataDir(ctx, dir); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if !spawned {
+				p.unlockUserDataDir(dir)
+			}
+		}()
+		if err := prepareUserDataDir(dir); err != nil {
+			return nil, err
+		}
+		l = l.UserDataDir(dir)
+	}
 
 	// Apply per-session overrides
+	if opts.BrowserPath != "" {
+		l = l.Bin(opts.BrowserPath)
+	}
 	if opts.WindowSize != "" {
 		l = l.Set("window-size", opts.WindowSize)
 	}
@@ -537,6 +868,10 @@ func (p *Pool) SpawnWithOptions(ctx context.Context, opts LaunchOptions) (*rod.B
 		if launchErr != nil {
 			return nil, fmt.Errorf("failed to launch browser with custom options: %w", launchErr)
 		}
+		if opts.UserDataDir != "" {
+			// Launched; clearing the flag keeps l.Cleanup() from deleting the dir
+			l.Delete(flags.UserDataDir)
+		}
 	case <-launchCtx.Done():
 		l.Kill()
 		return nil, fmt.Errorf("browser launch timed out after %v", launchTimeout)
@@ -563,8 +898,16 @@ func (p *Pool) SpawnWithOptions(ctx context.Context, opts LaunchOptions) (*rod.B
 
 	p.controlURLs.Store(browser, url)
 	p.launchers.Store(browser, l)
+	if opts.UserDataDir != "" {
+		p.persistentDirs.Store(browser, opts.UserDataDir)
+	}
+	if fwd != nil {
+		p.forwarders.Store(browser, fwd)
+	}
+	spawned = true
 
 	log.Debug().Str("url", url).Msg("Browser with custom options spawned successfully")
+	p.publishSpawned(ctx, browser)
 	return browser, nil
 }
 
@@ -597,8 +940,20 @@ func (p *Pool) SpawnWithProxy(ctx context.Context, proxyURL string) (*rod.Browse
 	// Use redacted proxy URL in logs to prevent credential exposure
 	log.Debug().Str("proxy", security.RedactProxyURL(proxyURL)).Msg("Spawning browser with custom proxy")
 
+	// An authenticated SOCKS5 proxy is reached through a forwarder
+	chromeProxy, fwd, err := launchProxy(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	spawned := false
+	defer func() {
+		if !spawned && fwd != nil {
+			fwd.Close()
+		}
+	}()
+
 	// Create launcher with the specified proxy
-	l := p.createLauncher(proxyURL)
+	l := p.createLauncher(chromeProxy)
 
 	// Fix HIGH: Add timeout to Launch() to prevent indefinite blocking
 	const launchTimeout = 60 * time.Second
@@ -651,9 +1006,14 @@ func (p *Pool) SpawnWithProxy(ctx context.Context, proxyURL string) (*rod.Browse
 
 	p.controlURLs.Store(browser, url)
 	p.launchers.Store(browser, l)
+	if fwd != nil {
+		p.forwarders.Store(browser, fwd)
+	}
+	spawned = true
 
 	// Use redacted proxy URL in logs to prevent credential exposure
 	log.Debug().Str("url", url).Str("proxy", security.RedactProxyURL(proxyURL)).Msg("Browser with proxy spawned successfully")
+	p.publishSpawned(ctx, browser)
 	return browser, nil
 }
 
@@ -670,9 +1030,22 @@ func (p *Pool) SpawnWithProxy(ctx context.Context, proxyURL string) (*rod.Browse
 //	}
 //	defer pool.Release(browser)
 func (p *Pool) Acquire(ctx context.Context) (*rod.Browser, error) {
+	return p.AcquirePriority(ctx, PriorityNormal)
+}
+
+// AcquirePriority is Acquire with a queueing priority (PriorityLow,
+// PriorityNormal or PriorityHigh) that decides who is served first while the
+// pool is saturated. Context mode ignores it.
+func (p *Pool) AcquirePriority(ctx context.Context, priority int) (*rod.Browser, error) {
 	if p.closed.Load() {
 		return nil, types.ErrBrowserPoolClosed
 	}
+	if p.ContextMode() {
+		return p.acquireContext(ctx)
+	}
+	if p.Draining() {
+		return nil, types.ErrBrowserPoolDrained
+	}
 
 	const maxRetries = 5 // Prevent infinite retry if all browsers are unhealthy
 
@@ -682,54 +1055,53 @@ func (p *Pool) Acquire(ctx context.Context) (*rod.Browser, error) {
 			Int("retry", retry).
 			Msg("Acquiring browser from pool")
 
-		select {
-		case browser, ok := <-p.available:
-			// Fix #3: Handle closed channel - ok is false when channel is closed
-			if !ok || p.closed.Load() {
-				// Channel was closed or pool is closing
-				p.CleanupBrowser(browser) // safe on nil; also removes user-data dir
-				return nil, types.ErrBrowserPoolClosed
+		browser, ok, err := p.waitForBrowser(ctx, priority)
+		if err != nil {
+			return nil, err
+		}
+		// Fix #3: Handle closed channel - ok is false when channel is closed
+		if !ok || p.closed.Load() {
+			// Channel was closed or pool is closing
+			p.CleanupBrowser(browser) // safe on nil; also removes user-data dir
+			if !p.closed.Load() && p.Draining() {
+				return nil, types.ErrBrowserPoolDrained
 			}
+			return nil, types.ErrBrowserPoolClosed
+		}
+		browser = p.swapStandby(browser)
 
-			// Got a browser from the pool
-			p.stats.Acquired.Add(1)
-
-			// Verify browser is healthy before returning
-			// Fix: Only decrement availableCount AFTER health check succeeds
-			// to prevent TOCTOU race where count shows available but all are unhealthy
-			if !p.isHealthy(browser) {
-				log.Warn().Int("retry", retry).Msg("Acquired unhealthy browser, recycling")
-				p.stats.Errors.Add(1)
-				go p.recycleBrowser(browser) // Recycle in background
-				continue                     // Iterate instead of recurse
-			}
+		// Got a browser from the pool
+		p.stats.Acquired.Add(1)
 
-			// Health check passed - now decrement available count
-			p.availableCount.Add(-1)
+		// Verify browser is healthy before returning
+		// Fix: Only decrement availableCount AFTER health check succeeds
+		// to prevent TOCTOU race where count shows available but all are unhealthy
+		if !p.isHealthy(browser) {
+			log.Warn().Int("retry", retry).Msg("Acquired unhealthy browser, recycling")
+			p.stats.Errors.Add(1)
+			go p.recycleBrowser(browser) // Recycle in background
+			continue                     // Iterate instead of recurse
+		}
 
-			// Update use count (requires lock to safely access p.browsers)
-			p.mu.Lock()
-			for _, entry := range p.browsers {
-				if entry.browser == browser {
-					entry.useCount.Add(1)
-					break
-				}
-			}
-			p.mu.Unlock()
+		// Health check passed - now decrement available count
+		p.availableCount.Add(-1)
 
-			log.Debug().
-				Int64("total_acquired", p.stats.Acquired.Load()).
-				Msg("Browser acquired from pool")
+		// Update use count (requires lock to safely access p.browsers)
+		p.mu.Lock()
+		for _, entry := range p.browsers {
+			if entry.browser == browser {
+				entry.useCount.Add(1)
+				break
+			}
+		}
+		p.mu.Unlock()
 
-			return browser, nil
+		log.Debug().
+			Int64("total_acquired", p.stats.Acquired.Load()).
+			Msg("Browser acquired from pool")
 
-		case <-ctx.Done():
-			return nil, fmt.Errorf("%w: %v", types.ErrContextCanceled, ctx.Err())
+		return browser, nil
 
-		case <-time.After(p.config.BrowserPoolTimeout):
-			p.stats.Errors.Add(1)
-			return nil, types.ErrBrowserPoolTimeout
-		}
 	}
 
 	// All retries exhausted
@@ -747,6 +1119,10 @@ func (p *Pool) Release(browser *rod.Browser) {
 	if browser == nil {
 		return
 	}
+	// Contexts are disposed, not reused; a repeat release is a no-op
+	if p.releaseContext(browser) || browser.BrowserContextID != "" {
+		return
+	}
 
 	// Acquire lock early to prevent race with Close()
 	// This ensures atomicity of closed check + channel send
@@ -763,26 +1139,12 @@ func (p *Pool) Release(browser *rod.Browser) {
 	p.stats.Released.Add(1)
 	p.mu.Unlock() // Release lock during page cleanup (slow I/O)
 
-	// Clean up all pages before returning to pool
-	// This prevents memory accumulation across requests
-	// Fix #21: Track cleanup failures and mark browser unhealthy if needed
-	cleanupFailed := false
-	pages, err := browser.Pages()
-	if err != nil {
-		log.Warn().Err(err).Msg("Failed to get pages for cleanup, browser may be unhealthy")
-		cleanupFailed = true
-	} else {
-		for _, page := range pages {
-			if err := page.Navigate("about:blank"); err != nil {
-				log.Warn().Err(err).Msg("Failed to navigate page to blank during cleanup")
-				cleanupFailed = true
-			}
-			if err := page.Close(); err != nil {
-				log.Warn().Err(err).Msg("Failed to close page during cleanup")
-				cleanupFailed = true
-			}
-		}
-	}
+	// A stale browser goes back as its standby replacement
+	browser = p.swapStandby(browser)
+
+	// Clean up all pages before returning to pool, except a tab parked for
+	// reuse. This prevents memory accumulation across requests
+	cleanupFailed := !closePages(browser, p.parkedPage(browser))
 
 	// If cleanup failed, recycle the browser instead of returning to pool
 	if cleanupFailed {
@@ -801,20 +1163,52 @@ func (p *Pool) Release(browser *rod.Browser) {
 		return
 	}
 
+	// The pool was shrunk while this browser was out
+	if p.retireIfExcessLocked(browser) {
+		p.CleanupBrowser(browser)
+		return
+	}
+
 	// Safe to send - we hold the lock and confirmed not closed
-	select {
-	case p.available <- browser:
-		p.availableCount.Add(1)
+	if p.offerBrowser(browser) {
 		log.Debug().
 			Int64("total_released", p.stats.Released.Load()).
 			Msg("Browser released to pool")
-	default:
+	} else {
 		// Pool is full (shouldn't happen with correct usage)
 		log.Warn().Msg("Pool is full, closing excess browser")
 		p.CleanupBrowser(browser)
 	}
 }
 
+// closePages navigates every page of a browser to about:blank and closes it
+// so the browser can be reused, leaving keep (a tab parked for reuse, or
+// nil) open. Reports false if any step failed, in which case the browser
+// should not be reused.
+// Fix #21: Track cleanup failures and mark browser unhealthy if needed
+func closePages(browser *rod.Browser, keep *rod.Page) bool {
+	pages, err := browser.Pages()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to get pages for cleanup, browser may be unhealthy")
+		return false
+	}
+	ok := true
+	for _, page := range pages {
+		if keep != nil && page.TargetID == keep.TargetID {
+			continue
+		}
+		if err := page.Navigate("about:blank"); err != nil {
+			log.Warn().Err(err).Msg("Failed to navigate page to blank during cleanup")
+			ok = false
+		}
+		if err := page.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close page during cleanup")
+			ok = false
+		}
+	}
+	return ok
+}
+
 // isHealthy checks if a browser is responsive and usable.
 // Fix #5: Uses context properly with Rod operations for proper timeout propagation.
 func (p *Pool) isHealthy(browser *rod.Browser) bool {
@@ -853,17 +1247,31 @@ func (p *Pool) recycleBrowser(oldBrowser *rod.Browser) {
 		return
 	}
 
+	// The pool was shrunk, so this browser is retired rather than replaced
+	p.mu.Lock()
+	retire := p.retireIfExcessLocked(oldBrowser)
+	p.mu.Unlock()
+	if retire {
+		p.discardStandby(oldBrowser)
+		p.closeBrowserWithTimeout(oldBrowser, 10*time.Second)
+		return
+	}
+
 	p.stats.Recycled.Add(1)
+	p.publishRecycled(oldBrowser, "")
 
 	log.Info().
 		Int64("total_recycled", p.stats.Recycled.Load()).
 		Msg("Recycling browser")
 
-	// Close old browser OUTSIDE lock with timeout
-	// Use closeBrowserWithTimeout helper to properly handle goroutine lifecycle
-	p.closeBrowserWithTimeout(oldBrowser, 10*time.Second)
+	// A standby prepared by the health check is already warm
+	if standby := p.takeStandby(oldBrowser); standby != nil {
+		p.replaceBrowser(oldBrowser, standby)
+		return
+	}
 
-	// Spawn new browser OUTSIDE lock with timeout
+	// Spawn the replacement OUTSIDE lock with timeout, before retiring the
+	// old browser, so the pool is back at capacity as soon as possible
 	var newBrowser *rod.Browser
 	var spawnErr error
 
@@ -886,6 +1294,7 @@ func (p *Pool) recycleBrowser(oldBrowser *rod.Browser) {
 	case <-p.stopCh:
 		// spawnCancel is deferred, no need to call explicitly
 		log.Warn().Msg("Browser spawn abandoned during pool shutdown")
+		p.closeBrowserWithTimeout(oldBrowser, 10*time.Second)
 		p.removeBrowserEntry(oldBrowser)
 		// Wait briefly for spawning goroutine to notice context cancellation
 		select {
@@ -897,16 +1306,26 @@ func (p *Pool) recycleBrowser(oldBrowser *rod.Browser) {
 	case <-time.After(30 * time.Second):
 		// spawnCancel is deferred, no need to call explicitly
 		log.Error().Msg("Browser spawn timed out during recycle")
+		p.closeBrowserWithTimeout(oldBrowser, 10*time.Second)
 		p.removeBrowserEntry(oldBrowser)
+		p.scheduleRemoteReconnect()
 		return
 	}
 
 	if spawnErr != nil {
 		log.Error().Err(spawnErr).Msg("Failed to spawn replacement browser")
+		p.closeBrowserWithTimeout(oldBrowser, 10*time.Second)
 		p.removeBrowserEntry(oldBrowser)
+		p.scheduleRemoteReconnect()
 		return
 	}
 
+	p.replaceBrowser(oldBrowser, newBrowser)
+}
+
+// replaceBrowser puts newBrowser into circulation in place of oldBrowser,
+// then closes oldBrowser.
+func (p *Pool) replaceBrowser(oldBrowser, newBrowser *rod.Browser) {
 	// Update browser entry
 	newEntry := &browserEntry{
 		browser:   newBrowser,
@@ -916,6 +1335,10 @@ func (p *Pool) recycleBrowser(oldBrowser *rod.Browser) {
 
 	// Add new browser to pool with proper synchronization
 	p.addBrowserToPool(newBrowser)
+
+	// Close old browser OUTSIDE lock with timeout
+	// Use closeBrowserWithTimeout helper to properly handle goroutine lifecycle
+	p.closeBrowserWithTimeout(oldBrowser, 10*time.Second)
 }
 
 // CleanupBrowser closes a browser and removes its on-disk user-data dir.
@@ -934,6 +1357,20 @@ func (p *Pool) CleanupBrowser(browser *rod.Browser) {
 	if browser == nil {
 		return
 	}
+	if p.releaseContext(browser) || browser.BrowserContextID != "" {
+		return
+	}
+	// A remote browser outlives us, so its parked tab is closed explicitly
+	if v, ok := p.idlePages.LoadAndDelete(browser); ok {
+		if _, remote := p.remotes.Load(browser); remote {
+			_ = v.(*rod.Page).Timeout(pageResetTimeout).Close()
+		}
+	}
+	// Remote browsers are disconnected, never closed (see disconnectRemote)
+	if p.disconnectRemote(browser) {
+		p.identities.Delete(browser)
+		return
+	}
 	if err := browser.Close(); err != nil {
 		log.Warn().Err(err).Msg("Error closing browser")
 	}
@@ -942,7 +1379,15 @@ func (p *Pool) CleanupBrowser(browser *rod.Browser) {
 			l.Cleanup() // waits for process exit, then os.RemoveAll(user-data dir)
 		}
 	}
+	// The process has exited, so the next browser may open the dir
+	if v, ok := p.persistentDirs.LoadAndDelete(browser); ok {
+		p.unlockUserDataDir(v.(string))
+	}
+	if v, ok := p.forwarders.LoadAndDelete(browser); ok {
+		v.(*socksForwarder).Close()
+	}
 	p.controlURLs.Delete(browser)
+	p.identities.Delete(browser)
 }
 
 // closeBrowserWithTimeout closes a browser with a timeout and proper goroutine handling.
@@ -1004,11 +1449,9 @@ func (p *Pool) addBrowserToPool(browser *rod.Browser) {
 		return
 	}
 
-	select {
-	case p.available <- browser:
-		p.availableCount.Add(1)
+	if p.offerBrowser(browser) {
 		log.Info().Msg("Browser added to pool")
-	default:
+	} else {
 		log.Warn().Msg("Pool is full, closing browser")
 		p.CleanupBrowser(browser)
 	}
@@ -1020,6 +1463,7 @@ func (p *Pool) monitorMemory() {
 	defer ticker.Stop()
 
 	maxBytes := uint64(p.config.MaxMemoryMB) * 1024 * 1024
+	p.checkMemory(maxBytes)
 
 	for {
 		select {
@@ -1030,25 +1474,54 @@ func (p *Pool) monitorMemory() {
 			if p.closed.Load() {
 				return
 			}
+			p.checkMemory(maxBytes)
+		}
+	}
+}
 
-			var m runtime.MemStats
-			runtime.ReadMemStats(&m)
+// checkMemory samples the resident memory of the launched browsers and
+// recycles them all when it exceeds maxBytes. Where process memory cannot be
+// read, the Go heap is the only measure left and is used instead.
+func (p *Pool) checkMemory(maxBytes uint64) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
 
-			log.Debug().
-				Uint64("alloc_mb", m.Alloc/1024/1024).
-				Uint64("sys_mb", m.Sys/1024/1024).
+	sample, err := p.sampleBrowserMemory()
+	if err != nil {
+		log.Debug().
+			Err(err).
+			Uint64("alloc_mb", m.Alloc/1024/1024).
+			Uint64("sys_mb", m.Sys/1024/1024).
+			Int("max_mb", p.config.MaxMemoryMB).
+			Msg("Memory stats (browser memory unavailable)")
+
+		if m.Alloc > maxBytes {
+			log.Warn().
+				Uint64("current_mb", m.Alloc/1024/1024).
 				Int("max_mb", p.config.MaxMemoryMB).
-				Msg("Memory stats")
-
-			if m.Alloc > maxBytes {
-				log.Warn().
-					Uint64("current_mb", m.Alloc/1024/1024).
-					Int("max_mb", p.config.MaxMemoryMB).
-					Msg("Memory threshold exceeded, recycling browsers")
+				Msg("Memory threshold exceeded, recycling browsers")
 
-				p.recycleAll()
-			}
+			p.recycleAll()
 		}
+		return
+	}
+	p.memory.Store(sample)
+
+	log.Debug().
+		Uint64("browser_rss_mb", sample.TotalBytes/1024/1024).
+		Int("browsers", len(sample.Browsers)).
+		Uint64("alloc_mb", m.Alloc/1024/1024).
+		Int("max_mb", p.config.MaxMemoryMB).
+		Msg("Memory stats")
+
+	if sample.TotalBytes > maxBytes {
+		log.Warn().
+			Uint64("current_mb", sample.TotalBytes/1024/1024).
+			Int("browsers", len(sample.Browsers)).
+			Int("max_mb", p.config.MaxMemoryMB).
+			Msg("Browser memory threshold exceeded, recycling browsers")
+
+		p.recycleAll()
 	}
 }
 
@@ -1081,10 +1554,11 @@ func (p *Pool) healthCheckRoutine() {
 			}
 			p.mu.Unlock()
 
-			// Recycle outside of lock
+			// Stale browsers may be in use, so each gets a standby that
+			// replaces it when it is next acquired or released
 			for _, browser := range toRecycle {
-				log.Info().Msg("Recycling stale browser")
-				p.recycleBrowser(browser)
+				log.Info().Msg("Preparing standby for stale browser")
+				p.prepareStandby(browser)
 			}
 		}
 	}
@@ -1133,7 +1607,7 @@ func (p *Pool) recycleAll() {
 
 // Size returns the configured pool size.
 func (p *Pool) Size() int {
-	return p.config.BrowserPoolSize
+	return int(p.targetSize.Load())
 }
 
 // Available returns the number of browsers currently available in the pool.
@@ -1145,6 +1619,33 @@ func (p *Pool) Available() int {
 	return int(p.availableCount.Load())
 }
 
+// Ready returns nil when the pool can take requests: it is open, not drained
+// and holds at least one browser, or in context mode a running host. Busy
+// browsers still count; readiness is about capacity existing, not being idle.
+func (p *Pool) Ready() error {
+	if p.closed.Load() {
+		return types.ErrBrowserPoolClosed
+	}
+	if p.Draining() {
+		return types.ErrBrowserPoolDrained
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ContextMode() {
+		for _, h := range p.contextHosts {
+			if !h.restarting.Load() {
+				return nil
+			}
+		}
+		return errors.New("no context host is running")
+	}
+	if len(p.browsers) == 0 {
+		return errors.New("no browsers in the pool")
+	}
+	return nil
+}
+
 // PoolStatsSnapshot holds a point-in-time snapshot of pool statistics.
 type PoolStatsSnapshot struct {
 	Acquired         int64
@@ -1191,6 +1692,7 @@ func (p *Pool) Close() error {
 	// Close channel while holding lock to prevent send-on-closed-channel panic
 	close(p.available)
 	p.mu.Unlock()
+	p.wakeWaiters()
 
 	log.Info().Msg("Closing browser pool")
 
@@ -1248,6 +1750,10 @@ func (p *Pool) Close() error {
 	// Wait for all browsers to close
 	closeErr := eg.Wait()
 
+	p.closeContextHosts()
+	p.expireIdleProxyBrowsers(0)
+	p.closeStandbys()
+
 	// Drain any remaining items from channel (safe after close)
 	for b := range p.available {
 		p.CleanupBrowser(b) // safe on nil
@@ -1281,6 +1787,11 @@ func (p *Pool) Close() error {
 func (p *Pool) removeBrowserEntry(oldBrowser *rod.Browser) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	p.removeBrowserEntryLocked(oldBrowser)
+}
+
+// removeBrowserEntryLocked is removeBrowserEntry with p.mu already held.
+func (p *Pool) removeBrowserEntryLocked(oldBrowser *rod.Browser) {
 	for i, entry := range p.browsers {
 		if entry.browser == oldBrowser {
 			// Swap with last element and truncate (O(1) removal)
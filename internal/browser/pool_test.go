@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
 
 	"github.com/Rorqualx/flaresolverr-go/internal/config"
 	"github.com/Rorqualx/flaresolverr-go/internal/types"
@@ -488,3 +489,36 @@ func TestSpawnWithProxyEmptyURL(t *testing.T) {
 		t.Error("Expected non-nil browser")
 	}
 }
+
+// TestApplyGPUMode verifies the GL backend and compositing flags chosen for
+// each GPU_MODE, including the ARM default.
+func TestApplyGPUMode(t *testing.T) {
+	tests := []struct {
+		mode          string
+		arm           bool
+		useGL         string
+		useANGLE      string
+		swCompositing bool
+	}{
+		{config.GPUModeAuto, false, "angle", "vulkan", false},
+		{config.GPUModeAuto, true, "angle", "vulkan", true},
+		{config.GPUModeANGLE, true, "angle", "vulkan", false},
+		{config.GPUModeEGL, true, "egl", "", false},
+		{config.GPUModeSoftware, false, "angle", "swiftshader", true},
+	}
+	for _, tt := range tests {
+		l := applyGPUMode(launcher.New(), tt.mode, tt.arm)
+		if got := l.Get("use-gl"); got != tt.useGL {
+			t.Errorf("%s (arm=%v): use-gl = %q, want %q", tt.mode, tt.arm, got, tt.useGL)
+		}
+		if got := l.Get("use-angle"); got != tt.useANGLE {
+			t.Errorf("%s (arm=%v): use-angle = %q, want %q", tt.mode, tt.arm, got, tt.useANGLE)
+		}
+		if got := l.Has("disable-gpu-compositing"); got != tt.swCompositing {
+			t.Errorf("%s (arm=%v): disable-gpu-compositing = %v, want %v", tt.mode, tt.arm, got, tt.swCompositing)
+		}
+		if !l.Has("enable-webgl") {
+			t.Errorf("%s (arm=%v): WebGL not enabled", tt.mode, tt.arm)
+		}
+	}
+}
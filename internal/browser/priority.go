@@ -0,0 +1,165 @@
+package browser
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// Acquire priorities. When the pool is saturated, a released browser goes to
+// the highest-priority waiting Acquire, and to the longest-waiting among
+// equals, so interactive traffic is not starved behind bulk jobs.
+const (
+	PriorityLow    = -1
+	PriorityNormal = 0
+	PriorityHigh   = 1
+)
+
+// acquireWaiter is an Acquire call blocked on a saturated pool.
+type acquireWaiter struct {
+	priority int
+	seq      uint64
+	ch       chan *rod.Browser // buffered; receives the handed-off browser
+	index    int               // position in waiterQueue, -1 once popped
+}
+
+// waiterQueue is a container/heap of waiters, highest priority first.
+type waiterQueue []*acquireWaiter
+
+func (q waiterQueue) Len() int { return len(q) }
+
+func (q waiterQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waiterQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waiterQueue) Push(x any) {
+	w, _ := x.(*acquireWaiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waiterQueue) Pop() any {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*q = old[:n-1]
+	return w
+}
+
+// offerBrowser hands an idle browser to the highest-priority waiter, or parks
+// it in the available channel when nobody waits. Reports false if the
+// channel is full. Callers hold p.mu and have checked p.closed.
+func (p *Pool) offerBrowser(browser *rod.Browser) bool {
+	p.waitMu.Lock()
+	defer p.waitMu.Unlock()
+
+	if p.waiters.Len() > 0 {
+		w, _ := heap.Pop(&p.waiters).(*acquireWaiter)
+		w.ch <- browser
+		p.availableCount.Add(1)
+		return true
+	}
+	select {
+	case p.available <- browser:
+		p.availableCount.Add(1)
+		return true
+	default:
+		return false
+	}
+}
+
+// waitForBrowser takes an idle browser, queueing at priority while the pool
+// is saturated. ok is false once the pool has been closed.
+func (p *Pool) waitForBrowser(ctx context.Context, priority int) (browser *rod.Browser, ok bool, err error) {
+	// Checking the channel and queueing under waitMu means a browser offered
+	// in between cannot be missed: offerBrowser prefers waiters
+	p.waitMu.Lock()
+	select {
+	case browser, ok = <-p.available:
+		p.waitMu.Unlock()
+		return browser, ok, nil
+	default:
+	}
+	if p.closed.Load() || p.Draining() {
+		p.waitMu.Unlock()
+		return nil, false, nil
+	}
+	w := &acquireWaiter{priority: priority, seq: p.waitSeq, ch: make(chan *rod.Browser, 1)}
+	p.waitSeq++
+	heap.Push(&p.waiters, w)
+	p.waitMu.Unlock()
+
+	timer := time.NewTimer(p.config.BrowserPoolTimeout)
+	defer timer.Stop()
+
+	select {
+	case browser, ok = <-w.ch:
+		return browser, ok, nil
+	case <-ctx.Done():
+		p.abandonWait(w)
+		return nil, false, fmt.Errorf("%w: %v", types.ErrContextCanceled, ctx.Err())
+	case <-timer.C:
+		p.abandonWait(w)
+		p.stats.Errors.Add(1)
+		return nil, false, types.ErrBrowserPoolTimeout
+	}
+}
+
+// abandonWait dequeues a waiter that gave up. A browser handed to it in the
+// meantime is passed on to the next waiter.
+func (p *Pool) abandonWait(w *acquireWaiter) {
+	p.waitMu.Lock()
+	if w.index >= 0 {
+		heap.Remove(&p.waiters, w.index)
+		p.waitMu.Unlock()
+		return
+	}
+	p.waitMu.Unlock()
+
+	// Popped: the browser is already in w.ch, or w.ch was closed by Close
+	browser, ok := <-w.ch
+	if !ok || browser == nil {
+		return
+	}
+	p.availableCount.Add(-1)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed.Load() || !p.offerBrowser(browser) {
+		p.CleanupBrowser(browser)
+	}
+}
+
+// wakeWaiters fails every queued Acquire once the pool is closed.
+func (p *Pool) wakeWaiters() {
+	p.waitMu.Lock()
+	defer p.waitMu.Unlock()
+	for _, w := range p.waiters {
+		w.index = -1
+		close(w.ch)
+	}
+	p.waiters = nil
+}
+
+// Waiting returns the number of Acquire calls queued on a saturated pool.
+func (p *Pool) Waiting() int {
+	p.waitMu.Lock()
+	defer p.waitMu.Unlock()
+	return p.waiters.Len()
+}
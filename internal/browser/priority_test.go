@@ -0,0 +1,111 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func newWaitTestPool(timeout time.Duration) *Pool {
+	p := &Pool{
+		config:    &config.Config{BrowserPoolSize: 1, BrowserPoolTimeout: timeout},
+		available: make(chan *rod.Browser, 1),
+	}
+	p.targetSize.Store(1)
+	return p
+}
+
+// waitUntilQueued blocks until n Acquire calls are queued.
+func waitUntilQueued(t *testing.T, p *Pool, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for p.Waiting() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d queued waiters, got %d", n, p.Waiting())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestWaitForBrowserPriority verifies that a released browser goes to the
+// highest-priority waiter, even one that started waiting later.
+func TestWaitForBrowserPriority(t *testing.T) {
+	p := newWaitTestPool(5 * time.Second)
+	ctx := context.Background()
+
+	got := make(chan int, 3)
+	wait := func(priority int) {
+		if _, ok, err := p.waitForBrowser(ctx, priority); err == nil && ok {
+			got <- priority
+		}
+	}
+	go wait(PriorityLow)
+	waitUntilQueued(t, p, 1)
+	go wait(PriorityNormal)
+	waitUntilQueued(t, p, 2)
+	go wait(PriorityHigh)
+	waitUntilQueued(t, p, 3)
+
+	for _, want := range []int{PriorityHigh, PriorityNormal, PriorityLow} {
+		if !p.offerBrowser(&rod.Browser{}) {
+			t.Fatal("offerBrowser() = false with waiters queued")
+		}
+		if priority := <-got; priority != want {
+			t.Errorf("Expected priority %d to be served, got %d", want, priority)
+		}
+	}
+	if len(p.available) != 0 {
+		t.Error("Expected handed-off browsers to bypass the channel")
+	}
+}
+
+// TestWaitForBrowserTimeout verifies that a waiter that gives up leaves the
+// queue, and that a browser offered afterwards is parked in the channel.
+func TestWaitForBrowserTimeout(t *testing.T) {
+	p := newWaitTestPool(20 * time.Millisecond)
+
+	_, _, err := p.waitForBrowser(context.Background(), PriorityNormal)
+	if !errors.Is(err, types.ErrBrowserPoolTimeout) {
+		t.Fatalf("Expected pool timeout, got %v", err)
+	}
+	if p.Waiting() != 0 {
+		t.Errorf("Expected the timed-out waiter to leave the queue, got %d waiting", p.Waiting())
+	}
+
+	b := &rod.Browser{}
+	if !p.offerBrowser(b) || len(p.available) != 1 {
+		t.Fatal("Expected the browser to be parked in the channel")
+	}
+	if got, ok, err := p.waitForBrowser(context.Background(), PriorityLow); err != nil || !ok || got != b {
+		t.Errorf("waitForBrowser() = %v, %v, %v; want the parked browser", got, ok, err)
+	}
+}
+
+// TestWakeWaiters verifies that closing the pool fails queued Acquire calls.
+func TestWakeWaiters(t *testing.T) {
+	p := newWaitTestPool(5 * time.Second)
+
+	done := make(chan bool, 1)
+	go func() {
+		_, ok, err := p.waitForBrowser(context.Background(), PriorityHigh)
+		done <- ok || err != nil
+	}()
+	waitUntilQueued(t, p, 1)
+
+	p.closed.Store(true)
+	p.wakeWaiters()
+	select {
+	case failed := <-done:
+		if failed {
+			t.Error("Expected ok=false and no error for a closed pool")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Queued waiter was not woken")
+	}
+}
@@ -0,0 +1,60 @@
+package browser
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/go-rod/rod/lib/launcher"
+)
+
+// BrowserMemory is the resident memory of one launched browser, counting its
+// renderer, GPU and utility processes.
+type BrowserMemory struct {
+	PID      int    `json:"pid"`
+	RSSBytes uint64 `json:"rssBytes"`
+}
+
+// MemorySample is the browser memory measured by one monitor pass.
+type MemorySample struct {
+	Browsers   []BrowserMemory `json:"browsers"`
+	TotalBytes uint64          `json:"totalBytes"`
+	SampledAt  time.Time       `json:"sampledAt"`
+}
+
+// MemoryUsage returns the last browser memory sample, or nil before the
+// first one and on platforms where process memory cannot be read.
+func (p *Pool) MemoryUsage() *MemorySample {
+	return p.memory.Load()
+}
+
+// sampleBrowserMemory measures the browsers this pool launched. Remote
+// browsers (REMOTE_BROWSER_URLS) run elsewhere and cannot be measured.
+func (p *Pool) sampleBrowserMemory() (*MemorySample, error) {
+	if len(p.remoteURLs) > 0 {
+		return nil, errors.New("remote browsers have no local processes")
+	}
+
+	var pids []int
+	p.launchers.Range(func(_, value any) bool {
+		if l, ok := value.(*launcher.Launcher); ok {
+			if pid := l.PID(); pid > 0 {
+				pids = append(pids, pid)
+			}
+		}
+		return true
+	})
+
+	rss, err := processTreeRSS(pids)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := &MemorySample{Browsers: make([]BrowserMemory, 0, len(rss)), SampledAt: time.Now()}
+	for pid, bytes := range rss {
+		sample.Browsers = append(sample.Browsers, BrowserMemory{PID: pid, RSSBytes: bytes})
+		sample.TotalBytes += bytes
+	}
+	sort.Slice(sample.Browsers, func(i, j int) bool { return sample.Browsers[i].PID < sample.Browsers[j].PID })
+	return sample, nil
+}
@@ -0,0 +1,95 @@
+//go:build linux
+
+package browser
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// processTreeRSS returns the resident memory of each root process together
+// with all of its descendants. Chrome keeps most of its memory in child
+// processes, so the browser process alone would undercount it badly.
+func processTreeRSS(roots []int) (map[int]uint64, error) {
+	return procTreeRSS("/proc", roots)
+}
+
+// procTreeRSS reads process memory from a proc filesystem mounted at procDir.
+func procTreeRSS(procDir string, roots []int) (map[int]uint64, error) {
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", procDir, err)
+	}
+
+	children := make(map[int][]int)
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		stat, err := os.ReadFile(filepath.Join(procDir, e.Name(), "stat"))
+		if err != nil {
+			continue // exited since the directory was listed
+		}
+		if ppid, ok := parseStatPPID(stat); ok {
+			children[ppid] = append(children[ppid], pid)
+		}
+	}
+
+	pageSize := uint64(os.Getpagesize())
+	out := make(map[int]uint64, len(roots))
+	for _, root := range roots {
+		var total uint64
+		seen := make(map[int]bool)
+		stack := []int{root}
+		for len(stack) > 0 {
+			pid := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if seen[pid] {
+				continue
+			}
+			seen[pid] = true
+			total += residentPages(procDir, pid) * pageSize
+			stack = append(stack, children[pid]...)
+		}
+		out[root] = total
+	}
+	return out, nil
+}
+
+// parseStatPPID returns the parent PID from the contents of /proc/<pid>/stat.
+// The command name may itself contain spaces and parentheses, so fields are
+// counted from the last closing parenthesis.
+func parseStatPPID(stat []byte) (int, bool) {
+	i := bytes.LastIndexByte(stat, ')')
+	if i < 0 {
+		return 0, false
+	}
+	fields := bytes.Fields(stat[i+1:])
+	if len(fields) < 2 {
+		return 0, false
+	}
+	ppid, err := strconv.Atoi(string(fields[1]))
+	return ppid, err == nil
+}
+
+// residentPages returns the resident set of pid in pages, or 0 when the
+// process has exited.
+func residentPages(procDir string, pid int) uint64 {
+	statm, err := os.ReadFile(filepath.Join(procDir, strconv.Itoa(pid), "statm"))
+	if err != nil {
+		return 0
+	}
+	fields := bytes.Fields(statm)
+	if len(fields) < 2 {
+		return 0
+	}
+	pages, err := strconv.ParseUint(string(fields[1]), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return pages
+}
@@ -0,0 +1,97 @@
+//go:build linux
+
+package browser
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseStatPPID(t *testing.T) {
+	tests := []struct {
+		stat string
+		want int
+		ok   bool
+	}{
+		{"1234 (chrome) S 1000 1234 1234 0", 1000, true},
+		{"1235 (Web Content (x)) R 1234 1234", 1234, true},
+		{"1236 (a) b) S 77 1", 77, true},
+		{"garbage", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseStatPPID([]byte(tt.stat))
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseStatPPID(%q) = %d, %v; want %d, %v", tt.stat, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestProcTreeRSS(t *testing.T) {
+	dir := t.TempDir()
+	proc := func(pid, ppid, pages string) {
+		t.Helper()
+		d := filepath.Join(dir, pid)
+		if err := os.Mkdir(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(d, "stat"), []byte(pid+" (chrome) S "+ppid+" 0 0"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(d, "statm"), []byte("9999 "+pages+" 10 0 0 0 0"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	proc("100", "1", "10") // browser
+	proc("101", "100", "20")
+	proc("102", "101", "30") // grandchild
+	proc("200", "1", "5")    // another browser
+	proc("300", "1", "1000") // unrelated
+
+	got, err := procTreeRSS(dir, []int{100, 200, 999})
+	if err != nil {
+		t.Fatalf("procTreeRSS() error = %v", err)
+	}
+	page := uint64(os.Getpagesize())
+	if got[100] != 60*page || got[200] != 5*page || got[999] != 0 {
+		t.Errorf("procTreeRSS() = %v, want 100: %d, 200: %d, 999: 0", got, 60*page, 5*page)
+	}
+}
+
+func TestProcessTreeRSSIncludesChildren(t *testing.T) {
+	cmd := exec.Command("sleep", "5")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start child process: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	// Our own RSS moves with the Go runtime, so compare against the child,
+	// whose RSS is stable once it has exec'd
+	child := cmd.Process.Pid
+	comm := filepath.Join("/proc", strconv.Itoa(child), "comm")
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if b, _ := os.ReadFile(comm); strings.TrimSpace(string(b)) == "sleep" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Skip("child process did not exec in time")
+		}
+	}
+	childRSS := residentPages("/proc", child) * uint64(os.Getpagesize())
+
+	self := os.Getpid()
+	tree, err := processTreeRSS([]int{self})
+	if err != nil {
+		t.Fatalf("processTreeRSS() error = %v", err)
+	}
+	if childRSS == 0 || tree[self] <= childRSS {
+		t.Errorf("tree RSS = %d, child RSS = %d; want the child counted on top of our own", tree[self], childRSS)
+	}
+}
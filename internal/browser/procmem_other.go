@@ -0,0 +1,11 @@
+//go:build !linux
+
+package browser
+
+import "errors"
+
+// processTreeRSS is unavailable without /proc; the memory monitor then falls
+// back to the Go heap.
+func processTreeRSS([]int) (map[int]uint64, error) {
+	return nil, errors.New("browser process memory is only available on Linux")
+}
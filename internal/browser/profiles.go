@@ -0,0 +1,142 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// BrowserProfile is a named launch configuration from BROWSER_PROFILES_PATH,
+// selected per request with the "profile" field. Empty fields fall back to
+// the server defaults.
+type BrowserProfile struct {
+	Name        string   `yaml:"name" json:"name"`
+	BrowserPath string   `yaml:"browserPath" json:"browserPath"` // Chrome/Chromium binary
+	Args        []string `yaml:"args" json:"args"`               // Extra Chrome flags, e.g. "--force-device-scale-factor=2"
+	UserAgent   string   `yaml:"userAgent" json:"userAgent"`
+	Language    string   `yaml:"language" json:"language"`       // Accept-Language, e.g. "de-DE"
+	Timezone    string   `yaml:"timezone" json:"timezone"`       // IANA timezone, e.g. "Europe/Berlin"
+	WindowSize  string   `yaml:"windowSize" json:"windowSize"`   // Viewport as "width,height"
+	Headless    *bool    `yaml:"headless" json:"headless"`       // Overrides HEADLESS
+	Fingerprint string   `yaml:"fingerprint" json:"fingerprint"` // Builtin fingerprint profile, e.g. "desktop-chrome-mac"
+	Persistent  bool     `yaml:"persistent" json:"persistent"`   // Keep history, cache and cookies across browsers and restarts
+
+	userDataDir string // set by assignProfileDataDirs for persistent profiles
+}
+
+// LaunchOptions returns the options to spawn a browser for this profile
+// behind proxyURL (empty for a direct connection).
+func (bp *BrowserProfile) LaunchOptions(proxyURL string) LaunchOptions {
+	return LaunchOptions{
+		ProxyURL:    proxyURL,
+		BrowserPath: bp.BrowserPath,
+		UserDataDir: bp.userDataDir,
+		WindowSize:  bp.WindowSize,
+		Language:    bp.Language,
+		Timezone:    bp.Timezone,
+		Headless:    bp.Headless,
+		ExtraArgs:   bp.Args,
+	}
+}
+
+// LoadBrowserProfiles reads a BROWSER_PROFILES_PATH file. Returns nil for an
+// empty path.
+func LoadBrowserProfiles(path string) (map[string]*BrowserProfile, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from operator configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read browser profiles: %w", err)
+	}
+	return ParseBrowserProfiles(data)
+}
+
+// ParseBrowserProfiles parses and validates a browser profiles document, a
+// YAML (or JSON) list of entries:
+//
+//   - name: mobile-eu
+//     userAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8) ..."
+//     language: de-DE
+//     timezone: Europe/Berlin
+//     windowSize: "412,915"
+//     args: ["--force-device-scale-factor=2.625"]
+func ParseBrowserProfiles(data []byte) (map[string]*BrowserProfile, error) {
+	var list []*BrowserProfile
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse browser profiles: %w", err)
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("browser profiles file has no entries")
+	}
+
+	profiles := make(map[string]*BrowserProfile, len(list))
+	for i, bp := range list {
+		if bp == nil || !types.ValidBrowserProfileName(bp.Name) {
+			return nil, fmt.Errorf("browser profile %d: name must be 1-%d letters, digits, '.', '_' or '-'", i, types.MaxProfileNameLength)
+		}
+		if profiles[bp.Name] != nil {
+			return nil, fmt.Errorf("browser profile %q defined twice", bp.Name)
+		}
+		if bp.WindowSize != "" && !validWindowSize(bp.WindowSize) {
+			return nil, fmt.Errorf("browser profile %q: windowSize must be 'width,height'", bp.Name)
+		}
+		for _, arg := range bp.Args {
+			if !strings.HasPrefix(arg, "--") {
+				return nil, fmt.Errorf("browser profile %q: args must start with '--': %s", bp.Name, arg)
+			}
+			if IsBlockedExtraArg(arg) {
+				return nil, fmt.Errorf("browser profile %q: blocked flag %q", bp.Name, arg)
+			}
+		}
+		if bp.Fingerprint != "" && !ValidProfileName(bp.Fingerprint) {
+			return nil, fmt.Errorf("browser profile %q: unknown fingerprint profile %q", bp.Name, bp.Fingerprint)
+		}
+		profiles[bp.Name] = bp
+	}
+	return profiles, nil
+}
+
+// assignProfileDataDirs gives each persistent profile its own user-data-dir
+// under baseDir (BROWSER_PROFILE_DATA_DIR).
+func assignProfileDataDirs(profiles map[string]*BrowserProfile, baseDir string) error {
+	for name, bp := range profiles {
+		if !bp.Persistent {
+			continue
+		}
+		if baseDir == "" {
+			return fmt.Errorf("browser profile %q is persistent but BROWSER_PROFILE_DATA_DIR is not set", name)
+		}
+		bp.userDataDir = filepath.Join(baseDir, name)
+	}
+	return nil
+}
+
+// validWindowSize checks a "width,height" pair of positive integers.
+func validWindowSize(s string) bool {
+	w, h, ok := strings.Cut(s, ",")
+	return ok && isDigits(strings.TrimSpace(w)) && isDigits(strings.TrimSpace(h))
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Profile returns the named browser profile.
+func (p *Pool) Profile(name string) (*BrowserProfile, bool) {
+	bp, ok := p.profiles[name]
+	return bp, ok
+}
@@ -0,0 +1,66 @@
+package browser
+
+import (
+	"testing"
+)
+
+func TestParseBrowserProfiles(t *testing.T) {
+	data := []byte(`
+- name: desktop-us
+  language: en-US
+  timezone: America/New_York
+  fingerprint: desktop-chrome-windows
+- name: mobile-eu
+  userAgent: "UA-mobile"
+  windowSize: "412,915"
+  headless: true
+  args: ["--force-device-scale-factor=2.625"]
+`)
+	profiles, err := ParseBrowserProfiles(data)
+	if err != nil {
+		t.Fatalf("ParseBrowserProfiles() error = %v", err)
+	}
+	if len(profiles) != 2 {
+		t.Fatalf("Expected 2 profiles, got %d", len(profiles))
+	}
+
+	opts := profiles["mobile-eu"].LaunchOptions("http://proxy:8080")
+	if opts.ProxyURL != "http://proxy:8080" || opts.WindowSize != "412,915" || len(opts.ExtraArgs) != 1 {
+		t.Errorf("Unexpected launch options: %+v", opts)
+	}
+	if opts.Headless == nil || !*opts.Headless {
+		t.Error("Expected headless override to carry into launch options")
+	}
+	if profiles["desktop-us"].Timezone != "America/New_York" {
+		t.Errorf("Unexpected desktop profile: %+v", profiles["desktop-us"])
+	}
+}
+
+func TestParseBrowserProfiles_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "empty", data: `[]`},
+		{name: "missing name", data: `[{"language": "en-US"}]`},
+		{name: "bad name", data: `[{"name": "desktop us"}]`},
+		{name: "duplicate", data: `[{"name": "a"}, {"name": "a"}]`},
+		{name: "bad window size", data: `[{"name": "a", "windowSize": "wide"}]`},
+		{name: "arg without dashes", data: `[{"name": "a", "args": ["lang=de"]}]`},
+		{name: "unknown fingerprint", data: `[{"name": "a", "fingerprint": "no-such-profile"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseBrowserProfiles([]byte(tt.data)); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestLoadBrowserProfiles_EmptyPath(t *testing.T) {
+	profiles, err := LoadBrowserProfiles("")
+	if err != nil || profiles != nil {
+		t.Errorf("LoadBrowserProfiles(\"\") = %v, %v; want nil, nil", profiles, err)
+	}
+}
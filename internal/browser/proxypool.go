@@ -0,0 +1,183 @@
+package browser
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/security"
+)
+
+// proxyPoolSweepInterval is how often idle proxy browsers are checked for expiry.
+const proxyPoolSweepInterval = 15 * time.Second
+
+// dedicatedKey identifies a sub-pool: browsers are only shared between
+// requests with the same proxy and browser profile.
+type dedicatedKey struct {
+	proxyURL string
+	profile  string
+}
+
+// idleProxyBrowser is a browser parked in a dedicated sub-pool.
+type idleProxyBrowser struct {
+	browser *rod.Browser
+	key     dedicatedKey
+	since   time.Time
+}
+
+// AcquireDedicated returns a browser outside the shared pool, routed through
+// proxyURL (empty for a direct connection) and launched with profile (nil
+// for the server defaults). A warm one from the matching sub-pool is reused
+// when available; otherwise a new browser is launched.
+//
+// The caller MUST hand the browser back with ReleaseDedicated, which parks
+// it for the next matching request or tears it down.
+func (p *Pool) AcquireDedicated(ctx context.Context, profile *BrowserProfile, proxyURL string) (*rod.Browser, error) {
+	key := dedicatedKey{proxyURL: proxyURL}
+	if profile != nil {
+		key.profile = profile.Name
+	}
+
+	for {
+		idle := p.takeIdleProxyBrowser(key)
+		if idle == nil {
+			break
+		}
+		if p.isHealthy(idle.browser) {
+			p.proxyBrowsers.Store(idle.browser, key)
+			log.Debug().
+				Str("proxy", security.RedactProxyURL(proxyURL)).
+				Str("profile", key.profile).
+				Dur("idle", time.Since(idle.since)).
+				Msg("Reusing warm browser from dedicated sub-pool")
+			return idle.browser, nil
+		}
+		log.Debug().Str("proxy", security.RedactProxyURL(proxyURL)).Msg("Discarding unhealthy browser from dedicated sub-pool")
+		p.CleanupBrowser(idle.browser)
+	}
+
+	var browser *rod.Browser
+	var err error
+	if profile != nil {
+		browser, err = p.SpawnWithOptions(ctx, profile.LaunchOptions(proxyURL))
+	} else {
+		browser, err = p.SpawnWithProxy(ctx, proxyURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.proxyBrowsers.Store(browser, key)
+	return browser, nil
+}
+
+// ReleaseDedicated returns a browser obtained from AcquireDedicated. It is
+// parked in its sub-pool if there is room, otherwise closed.
+// Safe to call with a nil browser.
+func (p *Pool) ReleaseDedicated(browser *rod.Browser) {
+	if browser == nil {
+		return
+	}
+	v, ok := p.proxyBrowsers.LoadAndDelete(browser)
+	key, _ := v.(dedicatedKey)
+	if !ok || p.config.ProxyPoolSize <= 0 || p.closed.Load() || !closePages(browser, nil) {
+		p.CleanupBrowser(browser)
+		return
+	}
+
+	p.proxyIdleMu.Lock()
+	if p.closed.Load() || p.proxyIdleCountLocked(key) >= p.config.ProxyPoolSize {
+		p.proxyIdleMu.Unlock()
+		p.CleanupBrowser(browser)
+		return
+	}
+	p.proxyIdle = append(p.proxyIdle, &idleProxyBrowser{browser: browser, key: key, since: time.Now()})
+	// Over the global cap, the longest-idle browser goes first
+	var evicted *idleProxyBrowser
+	if len(p.proxyIdle) > p.config.ProxyPoolMaxIdle {
+		evicted = p.proxyIdle[0]
+		p.proxyIdle = p.proxyIdle[1:]
+	}
+	p.proxyIdleMu.Unlock()
+
+	if evicted != nil {
+		p.CleanupBrowser(evicted.browser)
+	}
+	log.Debug().
+		Str("proxy", security.RedactProxyURL(key.proxyURL)).
+		Str("profile", key.profile).
+		Msg("Parked browser in dedicated sub-pool")
+}
+
+// takeIdleProxyBrowser removes and returns the most recently parked browser
+// for key, or nil.
+func (p *Pool) takeIdleProxyBrowser(key dedicatedKey) *idleProxyBrowser {
+	p.proxyIdleMu.Lock()
+	defer p.proxyIdleMu.Unlock()
+	for i := len(p.proxyIdle) - 1; i >= 0; i-- {
+		if idle := p.proxyIdle[i]; idle.key == key {
+			p.proxyIdle = append(p.proxyIdle[:i], p.proxyIdle[i+1:]...)
+			return idle
+		}
+	}
+	return nil
+}
+
+// proxyIdleCountLocked counts parked browsers for key. proxyIdleMu must be held.
+func (p *Pool) proxyIdleCountLocked(key dedicatedKey) int {
+	n := 0
+	for _, idle := range p.proxyIdle {
+		if idle.key == key {
+			n++
+		}
+	}
+	return n
+}
+
+// ProxyIdleCount returns the number of browsers parked across all dedicated sub-pools.
+func (p *Pool) ProxyIdleCount() int {
+	p.proxyIdleMu.Lock()
+	defer p.proxyIdleMu.Unlock()
+	return len(p.proxyIdle)
+}
+
+// expireIdleProxyBrowsers closes parked browsers idle longer than maxIdle,
+// or all of them when maxIdle is 0.
+func (p *Pool) expireIdleProxyBrowsers(maxIdle time.Duration) {
+	now := time.Now()
+	p.proxyIdleMu.Lock()
+	var expired []*idleProxyBrowser
+	kept := p.proxyIdle[:0]
+	for _, idle := range p.proxyIdle {
+		if maxIdle == 0 || now.Sub(idle.since) >= maxIdle {
+			expired = append(expired, idle)
+		} else {
+			kept = append(kept, idle)
+		}
+	}
+	clear(p.proxyIdle[len(kept):])
+	p.proxyIdle = kept
+	p.proxyIdleMu.Unlock()
+
+	for _, idle := range expired {
+		p.CleanupBrowser(idle.browser)
+	}
+	if len(expired) > 0 {
+		log.Debug().Int("count", len(expired)).Msg("Closed idle proxy browsers")
+	}
+}
+
+// proxyPoolSweeper closes expired idle proxy browsers until the pool stops.
+func (p *Pool) proxyPoolSweeper() {
+	ticker := time.NewTicker(proxyPoolSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.expireIdleProxyBrowsers(p.config.ProxyPoolIdleTimeout)
+		}
+	}
+}
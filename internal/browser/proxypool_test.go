@@ -0,0 +1,97 @@
+package browser
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// TestPoolProxySubPoolReuse verifies that a browser released for a proxy is
+// handed out again for the same proxy and not for another.
+func TestPoolProxySubPoolReuse(t *testing.T) {
+	skipCI(t)
+
+	cfg := testConfig()
+	cfg.BrowserPoolSize = 1
+	cfg.ProxyPoolSize = 1
+	cfg.ProxyPoolMaxIdle = 4
+	cfg.ProxyPoolIdleTimeout = time.Minute
+	pool, err := NewPool(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	const proxyA, proxyB = "http://127.0.0.1:18080", "http://127.0.0.1:18081"
+
+	first, err := pool.AcquireDedicated(ctx, nil, proxyA)
+	if err != nil {
+		t.Fatalf("Failed to acquire proxy browser: %v", err)
+	}
+	pool.ReleaseDedicated(first)
+	if pool.ProxyIdleCount() != 1 {
+		t.Fatalf("Expected 1 idle proxy browser, got %d", pool.ProxyIdleCount())
+	}
+
+	other, err := pool.AcquireDedicated(ctx, nil, proxyB)
+	if err != nil {
+		t.Fatalf("Failed to acquire browser for second proxy: %v", err)
+	}
+	if other == first {
+		t.Error("Browser for one proxy was handed out for another")
+	}
+	pool.ReleaseDedicated(other)
+
+	again, err := pool.AcquireDedicated(ctx, nil, proxyA)
+	if err != nil {
+		t.Fatalf("Failed to reacquire proxy browser: %v", err)
+	}
+	if again != first {
+		t.Error("Expected the warm browser to be reused")
+	}
+	pool.ReleaseDedicated(again)
+
+	pool.expireIdleProxyBrowsers(0)
+	if pool.ProxyIdleCount() != 0 {
+		t.Errorf("Expected no idle proxy browsers after expiry, got %d", pool.ProxyIdleCount())
+	}
+}
+
+// TestTakeIdleProxyBrowser verifies that parked browsers are matched by proxy
+// URL, most recently parked first.
+func TestTakeIdleProxyBrowser(t *testing.T) {
+	older, newer, other, mobile := &rod.Browser{}, &rod.Browser{}, &rod.Browser{}, &rod.Browser{}
+	now := time.Now()
+	keyA := dedicatedKey{proxyURL: "http://a:1"}
+	p := &Pool{proxyIdle: []*idleProxyBrowser{
+		{browser: older, key: keyA, since: now.Add(-time.Minute)},
+		{browser: other, key: dedicatedKey{proxyURL: "http://b:1"}, since: now.Add(-30 * time.Second)},
+		{browser: mobile, key: dedicatedKey{proxyURL: "http://a:1", profile: "mobile"}, since: now},
+		{browser: newer, key: keyA, since: now},
+	}}
+
+	if n := p.proxyIdleCountLocked(keyA); n != 2 {
+		t.Errorf("Expected 2 idle browsers for proxy a, got %d", n)
+	}
+	if got := p.takeIdleProxyBrowser(keyA); got == nil || got.browser != newer {
+		t.Error("Expected the most recently parked browser first")
+	}
+	if got := p.takeIdleProxyBrowser(keyA); got == nil || got.browser != older {
+		t.Error("Expected the remaining browser for proxy a")
+	}
+	if got := p.takeIdleProxyBrowser(keyA); got != nil {
+		t.Error("Expected no browser left for proxy a without a profile")
+	}
+	if p.ProxyIdleCount() != 2 {
+		t.Errorf("Expected proxy b's and the profile's browsers to stay parked, got %d idle", p.ProxyIdleCount())
+	}
+
+	// Nothing has been idle for an hour, so nothing is closed
+	p.expireIdleProxyBrowsers(time.Hour)
+	if p.ProxyIdleCount() != 2 {
+		t.Errorf("Expected unexpired browser to stay parked, got %d idle", p.ProxyIdleCount())
+	}
+}
@@ -0,0 +1,238 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// remoteConnectTimeout bounds endpoint discovery and the WebSocket
+	// handshake for one remote browser connection.
+	remoteConnectTimeout = 15 * time.Second
+
+	// remoteReconnectMin and remoteReconnectMax bound the backoff between
+	// attempts to restore a pool slot whose remote browser was lost.
+	remoteReconnectMin = time.Second
+	remoteReconnectMax = time.Minute
+)
+
+// ParseRemoteBrowserURLs parses a REMOTE_BROWSER_URLS value: comma- and/or
+// newline-separated DevTools endpoints. ws:// and wss:// URLs are used as-is
+// (e.g. a browserless endpoint with its token); http:// and https:// URLs are
+// resolved through /json/version. Blank entries and #-comments are skipped.
+func ParseRemoteBrowserURLs(raw string) ([]string, error) {
+	entries := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	})
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" || strings.HasPrefix(e, "#") {
+			continue
+		}
+		u, err := url.Parse(e)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid remote browser URL %q", redactEndpoint(e))
+		}
+		switch u.Scheme {
+		case "ws", "wss", "http", "https":
+		default:
+			return nil, fmt.Errorf("remote browser URL %q: scheme must be ws, wss, http or https", redactEndpoint(e))
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// redactEndpoint strips credentials and the query string (browserless puts
+// its token there) from an endpoint before it is logged.
+func redactEndpoint(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "[invalid url]"
+	}
+	u.User = nil
+	u.RawQuery = ""
+	return u.String()
+}
+
+// IsRemote reports whether the pool connects to remote browsers instead of
+// launching local ones.
+func (p *Pool) IsRemote() bool {
+	return len(p.remoteURLs) > 0
+}
+
+// connectRemote connects a pooled browser to a remote endpoint. Endpoints are
+// used round-robin; if one is unreachable the others are tried in turn.
+func (p *Pool) connectRemote(ctx context.Context) (*rod.Browser, error) {
+	var lastErr error
+	for range p.remoteURLs {
+		endpoint := p.remoteURLs[int(p.remoteNext.Add(1)-1)%len(p.remoteURLs)]
+		browser, ws, err := dialRemote(ctx, endpoint)
+		if err == nil {
+			p.remotes.Store(browser, ws)
+			log.Debug().Str("endpoint", redactEndpoint(endpoint)).Msg("Connected to remote browser")
+			return browser, nil
+		}
+		lastErr = err
+		log.Warn().Err(err).Str("endpoint", redactEndpoint(endpoint)).Msg("Remote browser endpoint unavailable")
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return nil, fmt.Errorf("no remote browser endpoint reachable: %w", lastErr)
+}
+
+// dialRemote opens a CDP connection to endpoint. The WebSocket is returned so
+// the connection can be closed without closing the remote browser itself.
+func dialRemote(ctx context.Context, endpoint string) (*rod.Browser, *cdp.WebSocket, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, remoteConnectTimeout)
+	defer cancel()
+
+	wsURL, err := resolveWebSocketURL(dialCtx, endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	ws := &cdp.WebSocket{}
+	if err := ws.Connect(dialCtx, wsURL, nil); err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to remote browser: %w", err)
+	}
+	browser := rod.New().Client(cdp.New().Start(ws))
+	if err := browser.Connect(); err != nil {
+		_ = ws.Close() // Best-effort: the connection is unusable either way
+		return nil, nil, fmt.Errorf("failed to attach to remote browser: %w", err)
+	}
+	return browser, ws, nil
+}
+
+// resolveWebSocketURL returns endpoint's DevTools WebSocket URL, looking it
+// up through /json/version for http(s) endpoints.
+func resolveWebSocketURL(ctx context.Context, endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "ws" || u.Scheme == "wss" {
+		return endpoint, nil
+	}
+
+	u.Path = "/json/version"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to query remote browser version: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("remote browser version endpoint returned status %d", resp.StatusCode)
+	}
+
+	var version struct {
+		WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&version); err != nil {
+		return "", fmt.Errorf("failed to parse remote browser version: %w", err)
+	}
+	if version.WebSocketDebuggerURL == "" {
+		return "", fmt.Errorf("remote browser did not report a webSocketDebuggerUrl")
+	}
+
+	// Chrome reports its own bind address (often 127.0.0.1 or 0.0.0.0); reach
+	// it through the host we were configured with instead
+	ws, err := url.Parse(version.WebSocketDebuggerURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid webSocketDebuggerUrl: %w", err)
+	}
+	ws.Host = u.Host
+	ws.Scheme = "ws"
+	if u.Scheme == "https" {
+		ws.Scheme = "wss"
+	}
+	return ws.String(), nil
+}
+
+// disconnectRemote closes the connection to a remote browser. The browser is
+// not sent Browser.close: endpoints like browserless end the session when the
+// connection drops, and a shared Chrome must keep running for other clients.
+// Reports false if browser is not a remote connection.
+func (p *Pool) disconnectRemote(browser *rod.Browser) bool {
+	v, ok := p.remotes.LoadAndDelete(browser)
+	if !ok {
+		return false
+	}
+	if ws, ok := v.(*cdp.WebSocket); ok {
+		if err := ws.Close(); err != nil {
+			log.Debug().Err(err).Msg("Error closing remote browser connection")
+		}
+	}
+	return true
+}
+
+// restoreRemoteSlot keeps reconnecting, with backoff, until a lost remote
+// browser is replaced or the pool shuts down, so an endpoint outage shrinks
+// the pool only temporarily.
+func (p *Pool) restoreRemoteSlot() {
+	backoff := remoteReconnectMin
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-time.After(backoff):
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), remoteConnectTimeout*time.Duration(len(p.remoteURLs)))
+		browser, err := p.spawnBrowser(ctx)
+		cancel()
+		if err == nil && p.closed.Load() {
+			p.CleanupBrowser(browser)
+			return
+		}
+		if err == nil {
+			p.mu.Lock()
+			p.browsers = append(p.browsers, &browserEntry{browser: browser, createdAt: time.Now()})
+			p.mu.Unlock()
+			p.addBrowserToPool(browser)
+			log.Info().Msg("Remote browser slot restored")
+			return
+		}
+
+		backoff = min(backoff*2, remoteReconnectMax)
+		log.Warn().Err(err).Dur("retry_in", backoff).Msg("Remote browser reconnect failed")
+	}
+}
+
+// scheduleRemoteReconnect starts restoreRemoteSlot for a slot whose
+// replacement could not be spawned. Local pools keep their existing behavior
+// of running one browser short.
+func (p *Pool) scheduleRemoteReconnect() {
+	if !p.IsRemote() || p.closed.Load() {
+		return
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.restoreRemoteSlot()
+	}()
+}
+
+// logRemoteEndpoints logs the configured endpoints with secrets redacted.
+func logRemoteEndpoints(endpoints []string) {
+	redacted := make([]string, len(endpoints))
+	for i, e := range endpoints {
+		redacted[i] = redactEndpoint(e)
+	}
+	log.Info().Strs("endpoints", redacted).Msg("Using remote browsers")
+}
@@ -0,0 +1,149 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// Resize changes the number of pooled browsers at runtime (pool.resize).
+// Growing launches the missing browsers before returning. Shrinking closes
+// idle browsers at once; browsers in use, including those pinned by
+// sessions, are closed when they are released. Returns the previous size.
+// A size of 0 drains the pool: Acquire fails fast until it is resized.
+func (p *Pool) Resize(ctx context.Context, size int) (int, error) {
+	if p.ContextMode() {
+		return 0, fmt.Errorf("resizing is not supported in context pool mode")
+	}
+	if size < 0 || size > config.MaxBrowserPoolSize {
+		return 0, fmt.Errorf("pool size must be between 0 and %d", config.MaxBrowserPoolSize)
+	}
+	if p.closed.Load() {
+		return 0, types.ErrBrowserPoolClosed
+	}
+
+	p.resizeMu.Lock()
+	defer p.resizeMu.Unlock()
+
+	previous := int(p.targetSize.Swap(int32(size)))
+	log.Info().Int("from", previous).Int("to", size).Msg("Resizing browser pool")
+
+	if size == 0 {
+		// Nobody queued can be served any more
+		p.wakeWaiters()
+	}
+	p.trimIdle()
+
+	for p.entryCount() < size {
+		browser, err := p.spawnBrowser(ctx)
+		if err != nil {
+			return previous, fmt.Errorf("failed to grow pool to %d browsers: %w", size, err)
+		}
+		p.mu.Lock()
+		if p.closed.Load() {
+			p.mu.Unlock()
+			p.CleanupBrowser(browser)
+			return previous, types.ErrBrowserPoolClosed
+		}
+		p.browsers = append(p.browsers, &browserEntry{browser: browser, createdAt: time.Now()})
+		if !p.offerBrowser(browser) {
+			p.removeBrowserEntryLocked(browser)
+			p.CleanupBrowser(browser)
+		}
+		p.mu.Unlock()
+	}
+	return previous, nil
+}
+
+// Drain shrinks the pool to zero (pool.drain). See Resize.
+func (p *Pool) Drain() (int, error) {
+	return p.Resize(context.Background(), 0)
+}
+
+// Draining reports whether the pool has been drained to zero browsers.
+func (p *Pool) Draining() bool {
+	return !p.ContextMode() && p.targetSize.Load() == 0
+}
+
+// ReplaceAll schedules a fresh replacement for every pooled browser
+// (pool.recycleAll) and returns how many. Replacements are launched in the
+// background and swapped in as each old browser is next acquired or
+// released, so requests and sessions in flight are not interrupted.
+func (p *Pool) ReplaceAll() (int, error) {
+	if p.ContextMode() {
+		return 0, fmt.Errorf("recycling is not supported in context pool mode")
+	}
+	if p.closed.Load() {
+		return 0, types.ErrBrowserPoolClosed
+	}
+
+	p.mu.Lock()
+	browsers := make([]*rod.Browser, 0, len(p.browsers))
+	for _, entry := range p.browsers {
+		browsers = append(browsers, entry.browser)
+	}
+	p.mu.Unlock()
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for _, b := range browsers {
+			if p.closed.Load() {
+				return
+			}
+			p.prepareStandby(b)
+		}
+	}()
+	return len(browsers), nil
+}
+
+// trimIdle closes idle browsers while the pool is above its target size.
+func (p *Pool) trimIdle() {
+	for {
+		p.mu.Lock()
+		if p.closed.Load() || len(p.browsers) <= int(p.targetSize.Load()) {
+			p.mu.Unlock()
+			return
+		}
+		var browser *rod.Browser
+		select {
+		case browser = <-p.available:
+		default:
+		}
+		if browser == nil {
+			p.mu.Unlock()
+			return
+		}
+		p.availableCount.Add(-1)
+		p.removeBrowserEntryLocked(browser)
+		p.mu.Unlock()
+
+		p.discardStandby(browser)
+		p.closeBrowserWithTimeout(browser, 10*time.Second)
+	}
+}
+
+// retireIfExcessLocked removes browser from the pool when the pool is above
+// its target size, reporting whether the caller must now close it.
+// p.mu must be held.
+func (p *Pool) retireIfExcessLocked(browser *rod.Browser) bool {
+	if len(p.browsers) <= int(p.targetSize.Load()) {
+		return false
+	}
+	p.removeBrowserEntryLocked(browser)
+	log.Info().Int("size", len(p.browsers)).Msg("Retiring browser above target pool size")
+	return true
+}
+
+// entryCount returns the number of pooled browsers.
+func (p *Pool) entryCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.browsers)
+}
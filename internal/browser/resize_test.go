@@ -0,0 +1,122 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// TestResizeValidation verifies sizes outside the allowed range and context
+// mode are rejected before the pool is touched.
+func TestResizeValidation(t *testing.T) {
+	p := &Pool{config: testConfig()}
+	for _, size := range []int{-1, config.MaxBrowserPoolSize + 1} {
+		if _, err := p.Resize(context.Background(), size); err == nil {
+			t.Errorf("Resize(%d): expected error", size)
+		}
+	}
+
+	cfg := testConfig()
+	cfg.BrowserPoolMode = config.BrowserPoolModeContext
+	p = &Pool{config: cfg}
+	if _, err := p.Resize(context.Background(), 2); err == nil {
+		t.Error("Expected resize to be rejected in context mode")
+	}
+	if _, err := p.ReplaceAll(); err == nil {
+		t.Error("Expected recycleAll to be rejected in context mode")
+	}
+	if p.Draining() {
+		t.Error("Context mode pool should never report draining")
+	}
+}
+
+// TestPoolResize verifies growing, shrinking and draining at runtime.
+func TestPoolResize(t *testing.T) {
+	skipCI(t)
+
+	cfg := testConfig()
+	cfg.BrowserPoolSize = 1
+	pool, err := NewPool(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	previous, err := pool.Resize(ctx, 2)
+	if err != nil {
+		t.Fatalf("Resize(2) failed: %v", err)
+	}
+	if previous != 1 || pool.Size() != 2 || pool.Available() != 2 {
+		t.Errorf("After grow: previous=%d size=%d available=%d", previous, pool.Size(), pool.Available())
+	}
+
+	// A browser in use survives the drain until it is released
+	held, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Failed to acquire browser: %v", err)
+	}
+	if _, err := pool.Drain(); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	if !pool.isHealthy(held) {
+		t.Error("Browser in use was closed by the drain")
+	}
+	if _, err := pool.Acquire(ctx); !errors.Is(err, types.ErrBrowserPoolDrained) {
+		t.Errorf("Acquire on drained pool: got %v, want ErrBrowserPoolDrained", err)
+	}
+	pool.Release(held)
+	if n := pool.entryCount(); n != 0 {
+		t.Errorf("Expected no browsers after drain, got %d", n)
+	}
+
+	if _, err := pool.Resize(ctx, 1); err != nil {
+		t.Fatalf("Resize(1) failed: %v", err)
+	}
+	b, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire after resize failed: %v", err)
+	}
+	pool.Release(b)
+}
+
+// TestPoolReady verifies readiness follows the pool's browsers, draining and
+// closing.
+func TestPoolReady(t *testing.T) {
+	p := &Pool{config: testConfig()}
+	p.targetSize.Store(2)
+	if err := p.Ready(); err == nil {
+		t.Error("Expected a pool without browsers not to be ready")
+	}
+
+	p.browsers = []*browserEntry{{}}
+	if err := p.Ready(); err != nil {
+		t.Errorf("Ready() = %v, want nil with a browser", err)
+	}
+
+	p.targetSize.Store(0)
+	if err := p.Ready(); !errors.Is(err, types.ErrBrowserPoolDrained) {
+		t.Errorf("Ready() = %v, want ErrBrowserPoolDrained", err)
+	}
+
+	p.closed.Store(true)
+	if err := p.Ready(); !errors.Is(err, types.ErrBrowserPoolClosed) {
+		t.Errorf("Ready() = %v, want ErrBrowserPoolClosed", err)
+	}
+
+	cfg := testConfig()
+	cfg.BrowserPoolMode = config.BrowserPoolModeContext
+	restarting := &contextHost{}
+	restarting.restarting.Store(true)
+	p = &Pool{config: cfg, contextHosts: []*contextHost{restarting}}
+	if err := p.Ready(); err == nil {
+		t.Error("Expected context mode without a running host not to be ready")
+	}
+	p.contextHosts = append(p.contextHosts, &contextHost{})
+	if err := p.Ready(); err != nil {
+		t.Errorf("Ready() = %v, want nil with a running host", err)
+	}
+}
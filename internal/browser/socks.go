@@ -0,0 +1,317 @@
+package browser
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/proxy"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/security"
+)
+
+// socksDialTimeout bounds connecting to the upstream SOCKS5 proxy.
+const socksDialTimeout = 30 * time.Second
+
+// socksHandshakeTimeout bounds Chrome's side of the local SOCKS5 handshake.
+const socksHandshakeTimeout = 10 * time.Second
+
+// SOCKS5 protocol values (RFC 1928).
+const (
+	socksVersion       = 0x05
+	socksNoAuth        = 0x00
+	socksNoAcceptable  = 0xff
+	socksCmdConnect    = 0x01
+	socksAtypIPv4      = 0x01
+	socksAtypDomain    = 0x03
+	socksAtypIPv6      = 0x04
+	socksReplySuccess  = 0x00
+	socksReplyFailure  = 0x01
+	socksReplyCmdUnsup = 0x07
+	socksReplyAddrType = 0x08
+)
+
+// NeedsSOCKSForwarder reports whether a proxy URL is SOCKS5 with credentials.
+// Chrome ignores credentials in --proxy-server and never asks for SOCKS
+// authentication, so such proxies are reached through a local forwarder.
+func NeedsSOCKSForwarder(proxyURL string) bool {
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.User == nil || u.User.Username() == "" {
+		return false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	return scheme == "socks5" || scheme == "socks5h"
+}
+
+// ProxyLaunchURL returns the proxy URL a browser is launched with. SOCKS5
+// credentials given in the request's username and password fields are moved
+// into the URL, where the SOCKS5 forwarder reads them; other proxies
+// authenticate per page and are returned unchanged.
+func ProxyLaunchURL(rawURL, username, password string) string {
+	if username == "" {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User != nil {
+		return rawURL
+	}
+	if scheme := strings.ToLower(u.Scheme); scheme != "socks5" && scheme != "socks5h" {
+		return rawURL
+	}
+	u.User = url.UserPassword(username, password)
+	return u.String()
+}
+
+// socksForwarder is an unauthenticated SOCKS5 listener on loopback that
+// relays each CONNECT through an authenticated upstream SOCKS5 proxy. Chrome
+// is pointed at the listener; the forwarder lives as long as its browser.
+type socksForwarder struct {
+	listener net.Listener
+	dialer   proxy.Dialer
+	upstream string // redacted, for logs
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// startSOCKSForwarder listens on a free loopback port and relays to the
+// authenticated SOCKS5 proxy in proxyURL.
+func startSOCKSForwarder(proxyURL string) (*socksForwarder, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOCKS5 proxy URL: %w", err)
+	}
+	var auth *proxy.Auth
+	if u.User != nil {
+		password, _ := u.User.Password()
+		auth = &proxy.Auth{User: u.User.Username(), Password: password}
+	}
+	dialer, err := proxy.SOCKS5("tcp", u.Host, auth, &net.Dialer{Timeout: socksDialTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure SOCKS5 upstream: %w", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for SOCKS5 forwarder: %w", err)
+	}
+	f := &socksForwarder{
+		listener: ln,
+		dialer:   dialer,
+		upstream: security.RedactProxyURL(proxyURL),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	f.wg.Add(1)
+	go f.serve()
+	log.Debug().Str("listen", ln.Addr().String()).Str("upstream", f.upstream).Msg("SOCKS5 forwarder started")
+	return f, nil
+}
+
+// launchProxy returns the --proxy-server value for proxyURL, starting a
+// SOCKS5 forwarder when the proxy needs credentials Chrome cannot send. The
+// caller owns the forwarder, which is nil otherwise.
+func launchProxy(proxyURL string) (string, *socksForwarder, error) {
+	if !NeedsSOCKSForwarder(proxyURL) {
+		return proxyURL, nil, nil
+	}
+	fwd, err := startSOCKSForwarder(proxyURL)
+	if err != nil {
+		return "", nil, err
+	}
+	return fwd.ProxyURL(), fwd, nil
+}
+
+// ProxyURL is the unauthenticated proxy URL Chrome is launched with.
+func (f *socksForwarder) ProxyURL() string {
+	return "socks5://" + f.listener.Addr().String()
+}
+
+// Close stops the listener and drops open connections.
+func (f *socksForwarder) Close() {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return
+	}
+	f.closed = true
+	_ = f.listener.Close()
+	for c := range f.conns {
+		_ = c.Close()
+	}
+	f.mu.Unlock()
+	f.wg.Wait()
+	log.Debug().Str("upstream", f.upstream).Msg("SOCKS5 forwarder stopped")
+}
+
+func (f *socksForwarder) serve() {
+	defer f.wg.Done()
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return // listener closed
+		}
+		if !f.track(conn) {
+			_ = conn.Close()
+			return
+		}
+		f.wg.Add(1)
+		go func() {
+			defer f.wg.Done()
+			defer f.untrack(conn)
+			if err := f.handle(conn); err != nil {
+				log.Debug().Err(err).Str("upstream", f.upstream).Msg("SOCKS5 forward failed")
+			}
+		}()
+	}
+}
+
+func (f *socksForwarder) track(c net.Conn) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return false
+	}
+	f.conns[c] = struct{}{}
+	return true
+}
+
+func (f *socksForwarder) untrack(c net.Conn) {
+	f.mu.Lock()
+	delete(f.conns, c)
+	f.mu.Unlock()
+	_ = c.Close()
+}
+
+// handle answers Chrome's handshake, connects upstream and relays.
+func (f *socksForwarder) handle(client net.Conn) error {
+	_ = client.SetDeadline(time.Now().Add(socksHandshakeTimeout))
+	target, err := readSOCKSConnect(client)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), socksDialTimeout)
+	defer cancel()
+	upstream, err := dialContext(ctx, f.dialer, target)
+	if err != nil {
+		_ = writeSOCKSReply(client, socksReplyFailure)
+		return fmt.Errorf("upstream connect to %s: %w", target, err)
+	}
+	if !f.track(upstream) {
+		_ = upstream.Close()
+		return nil
+	}
+	defer f.untrack(upstream)
+
+	if err := writeSOCKSReply(client, socksReplySuccess); err != nil {
+		return err
+	}
+	_ = client.SetDeadline(time.Time{})
+
+	done := make(chan struct{}, 2)
+	relay := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		// Unblock the other direction
+		_ = dst.Close()
+		_ = src.Close()
+		done <- struct{}{}
+	}
+	go relay(upstream, client)
+	go relay(client, upstream)
+	<-done
+	<-done
+	return nil
+}
+
+// dialContext dials through d, honoring ctx when the dialer supports it.
+func dialContext(ctx context.Context, d proxy.Dialer, addr string) (net.Conn, error) {
+	if cd, ok := d.(proxy.ContextDialer); ok {
+		return cd.DialContext(ctx, "tcp", addr)
+	}
+	return d.Dial("tcp", addr)
+}
+
+// readSOCKSConnect reads a no-auth SOCKS5 greeting and CONNECT request and
+// returns the requested host:port. Unsupported requests get an error reply.
+func readSOCKSConnect(c net.Conn) (string, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c, head[:]); err != nil {
+		return "", err
+	}
+	if head[0] != socksVersion {
+		return "", fmt.Errorf("unsupported SOCKS version %d", head[0])
+	}
+	methods := make([]byte, head[1])
+	if _, err := io.ReadFull(c, methods); err != nil {
+		return "", err
+	}
+	if !slices.Contains(methods, socksNoAuth) {
+		_, _ = c.Write([]byte{socksVersion, socksNoAcceptable})
+		return "", errors.New("client offered no unauthenticated method")
+	}
+	if _, err := c.Write([]byte{socksVersion, socksNoAuth}); err != nil {
+		return "", err
+	}
+
+	var req [4]byte
+	if _, err := io.ReadFull(c, req[:]); err != nil {
+		return "", err
+	}
+	if req[0] != socksVersion {
+		return "", fmt.Errorf("unsupported SOCKS version %d", req[0])
+	}
+	if req[1] != socksCmdConnect {
+		_ = writeSOCKSReply(c, socksReplyCmdUnsup)
+		return "", fmt.Errorf("unsupported SOCKS command %d", req[1])
+	}
+
+	var host string
+	switch req[3] {
+	case socksAtypIPv4, socksAtypIPv6:
+		ip := make(net.IP, net.IPv4len)
+		if req[3] == socksAtypIPv6 {
+			ip = make(net.IP, net.IPv6len)
+		}
+		if _, err := io.ReadFull(c, ip); err != nil {
+			return "", err
+		}
+		host = ip.String()
+	case socksAtypDomain:
+		var n [1]byte
+		if _, err := io.ReadFull(c, n[:]); err != nil {
+			return "", err
+		}
+		name := make([]byte, n[0])
+		if _, err := io.ReadFull(c, name); err != nil {
+			return "", err
+		}
+		host = string(name)
+	default:
+		_ = writeSOCKSReply(c, socksReplyAddrType)
+		return "", fmt.Errorf("unsupported SOCKS address type %d", req[3])
+	}
+
+	var port [2]byte
+	if _, err := io.ReadFull(c, port[:]); err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port[:])))), nil
+}
+
+// writeSOCKSReply sends a reply with an unspecified bound address.
+func writeSOCKSReply(c net.Conn, code byte) error {
+	_, err := c.Write([]byte{socksVersion, code, 0x00, socksAtypIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
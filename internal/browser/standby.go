@@ -0,0 +1,95 @@
+package browser
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/rs/zerolog/log"
+)
+
+// standbySpawnTimeout bounds launching a standby replacement.
+const standbySpawnTimeout = 30 * time.Second
+
+// prepareStandby launches a replacement for a stale pooled browser and parks
+// it until the old browser next passes through Acquire or Release, where
+// swapStandby hands it over. The old browser keeps serving in the meantime,
+// so periodic recycling never leaves the pool short of a browser, and a
+// browser held by a request or session is never closed under it.
+func (p *Pool) prepareStandby(old *rod.Browser) {
+	if _, pending := p.standby.Load(old); pending {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), standbySpawnTimeout)
+	defer cancel()
+	replacement, err := p.spawnBrowser(ctx)
+	if err != nil {
+		// The old browser still works; the next health check tries again
+		log.Warn().Err(err).Msg("Failed to spawn standby browser, keeping stale browser for now")
+		return
+	}
+
+	p.mu.Lock()
+	closed := p.closed.Load()
+	if !closed {
+		p.standby.Store(old, replacement)
+	}
+	p.mu.Unlock()
+	if closed {
+		p.CleanupBrowser(replacement)
+		return
+	}
+	log.Info().Msg("Standby browser ready to replace stale browser")
+}
+
+// swapStandby returns the standby prepared for browser, retiring browser in
+// the background, or browser itself when no standby is pending.
+func (p *Pool) swapStandby(browser *rod.Browser) *rod.Browser {
+	v, ok := p.standby.LoadAndDelete(browser)
+	if !ok {
+		return browser
+	}
+	replacement, _ := v.(*rod.Browser)
+
+	p.updateBrowserEntry(browser, &browserEntry{browser: replacement, createdAt: time.Now()})
+	p.stats.Recycled.Add(1)
+	p.publishRecycled(browser, "stale")
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.closeBrowserWithTimeout(browser, 10*time.Second)
+	}()
+
+	log.Info().
+		Int64("total_recycled", p.stats.Recycled.Load()).
+		Msg("Swapped stale browser for its standby")
+	return replacement
+}
+
+// takeStandby removes and returns the standby prepared for browser, or nil.
+func (p *Pool) takeStandby(browser *rod.Browser) *rod.Browser {
+	if v, ok := p.standby.LoadAndDelete(browser); ok {
+		replacement, _ := v.(*rod.Browser)
+		return replacement
+	}
+	return nil
+}
+
+// closeStandbys shuts down standbys whose stale browser was never handed back.
+func (p *Pool) closeStandbys() {
+	p.standby.Range(func(k, v any) bool {
+		p.standby.Delete(k)
+		if b, ok := v.(*rod.Browser); ok {
+			p.CleanupBrowser(b)
+		}
+		return true
+	})
+}
+
+// discardStandby closes the standby prepared for a browser leaving the pool.
+func (p *Pool) discardStandby(browser *rod.Browser) {
+	if standby := p.takeStandby(browser); standby != nil {
+		p.CleanupBrowser(standby)
+	}
+}
@@ -0,0 +1,62 @@
+package browser
+
+import (
+	"context"
+	"testing"
+)
+
+// TestPoolStandbyReplacesStaleBrowser verifies that a stale browser keeps
+// serving until it is released, and is then swapped for its standby.
+func TestPoolStandbyReplacesStaleBrowser(t *testing.T) {
+	skipCI(t)
+
+	cfg := testConfig()
+	cfg.BrowserPoolSize = 1
+	pool, err := NewPool(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	stale, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Failed to acquire browser: %v", err)
+	}
+
+	pool.prepareStandby(stale)
+	if _, ok := pool.standby.Load(stale); !ok {
+		t.Fatal("Expected a standby to be prepared")
+	}
+	// Still usable while the standby waits
+	if !pool.isHealthy(stale) {
+		t.Error("Stale browser was closed before it was released")
+	}
+
+	pool.Release(stale)
+	fresh, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Failed to reacquire browser: %v", err)
+	}
+	if fresh == stale {
+		t.Error("Expected the standby to replace the stale browser")
+	}
+	pool.Release(fresh)
+
+	if pool.Available() != 1 {
+		t.Errorf("Expected pool to stay at capacity, got %d available", pool.Available())
+	}
+}
+
+// TestSwapStandbyWithoutStandby verifies browsers without a pending standby
+// pass through unchanged.
+func TestSwapStandbyWithoutStandby(t *testing.T) {
+	p := &Pool{}
+	if got := p.swapStandby(nil); got != nil {
+		t.Errorf("Expected the same browser back, got %v", got)
+	}
+	if got := p.takeStandby(nil); got != nil {
+		t.Errorf("Expected no standby, got %v", got)
+	}
+	p.closeStandbys()
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-rod/rod"
@@ -13,54 +14,24 @@ import (
 )
 
 // ApplyStealthToPage applies anti-detection measures to a page.
-// This should be called after page creation but BEFORE navigation.
+// This should be called once, after page creation but BEFORE navigation.
 //
-// Uses two injection methods for defense-in-depth:
-//  1. PageAddScriptToEvaluateOnNewDocument: Runs at document_start BEFORE any page scripts.
-//     This closes the race window where Cloudflare's detection scripts could run before stealth.
-//  2. page.Evaluate: Immediate execution for the current context (about:blank).
-//
-// Returns an error for critical failures (e.g., syntax errors in stealth script),
-// but logs and continues for non-critical issues (e.g., APIs not available on about:blank).
+// The stealth script is registered with Page.addScriptToEvaluateOnNewDocument,
+// so Chrome runs it at document_start in every document the page loads, before
+// any page script including Cloudflare's detection. The registration lasts for
+// the page's lifetime: nothing has to be reapplied after a navigation, and the
+// blank document the page starts on is never evaluated.
 func ApplyStealthToPage(page *rod.Page) error {
-	log.Debug().Msg("Applying stealth patches to page")
-
-	// Method 1: Register stealth for ALL future navigations (runs at document_start)
-	// This ensures patches are active before any inline scripts including Cloudflare's detection
-	_, err := proto.PageAddScriptToEvaluateOnNewDocument{
-		Source: stealthScript,
-	}.Call(page)
-	if err != nil {
-		log.Warn().Err(err).Msg("Failed to register stealth via EvalOnNewDocument, falling back to Evaluate")
-	} else {
-		log.Debug().Msg("Stealth registered via EvalOnNewDocument (will run at document_start)")
-	}
-
-	// Method 2: Also evaluate immediately for the current context (about:blank).
-	//
-	// go-rod wraps every Eval argument as `function() { return (JS).apply(this, arguments) }`
-	// (see page_eval.go formatToJSFunc). stealthScript is a self-invoking IIFE, so the
-	// raw form evaluates to `((()=>{...})()).apply(...)` — the IIFE returns undefined and
-	// reading `.apply` on it throws `TypeError: Cannot read properties of undefined
-	// (reading 'apply')` at the script's final line (issue #13). Wrapping in an arrow gives
-	// rod's wrapper a callable target; the trailing `;` rod trims, so the IIFE still runs once.
-	_, evalErr := page.Evaluate(rod.Eval("() => " + stealthScript))
-	if evalErr != nil {
-		errStr := evalErr.Error()
-
-		// Critical errors that indicate broken stealth script - return error
-		if strings.Contains(errStr, "SyntaxError") {
-			return fmt.Errorf("stealth script syntax error: %w", evalErr)
-		}
-		if strings.Contains(errStr, "ReferenceError") {
-			return fmt.Errorf("stealth script reference error: %w", evalErr)
-		}
+	log.Debug().Msg("Registering stealth patches for new documents")
+	return registerStealthScript(page, stealthScript)
+}
 
-		// Non-critical errors - log and continue
-		log.Warn().Err(evalErr).Msg("Stealth script had non-fatal errors, continuing")
-		return nil
+// registerStealthScript registers source to run at document_start in every
+// document page loads from now on.
+func registerStealthScript(page *rod.Page, source string) error {
+	if _, err := (proto.PageAddScriptToEvaluateOnNewDocument{Source: source}).Call(page); err != nil {
+		return fmt.Errorf("failed to register stealth script: %w", err)
 	}
-
 	return nil
 }
 
@@ -1143,9 +1114,34 @@ func buildBlockPatterns(blockImages, blockCSS, blockFonts, blockMedia bool) []*p
 	return patterns
 }
 
+// installedChromeVersion is the full version of the installed browser, set
+// once a pool has read it; nil until then.
+var installedChromeVersion atomic.Pointer[string]
+
+// setInstalledChromeVersion records the installed browser's full version.
+func setInstalledChromeVersion(version string) {
+	installedChromeVersion.Store(&version)
+}
+
+// chromeFullVersion returns the full version Client Hints report for a UA
+// claiming major: the installed browser's when the majors match, since
+// Sec-CH-UA-Full-Version-List is not reduced like the UA string.
+func chromeFullVersion(major string) string {
+	if v := installedChromeVersion.Load(); v != nil && strings.HasPrefix(*v, major+".") {
+		return *v
+	}
+	return major + ".0.0.0"
+}
+
 // SetUserAgent sets a custom user agent on the page with proper Client Hints.
 // This is critical for bypassing Cloudflare detection which checks Sec-CH-UA headers.
 func SetUserAgent(page *rod.Page, userAgent string) error {
+	return userAgentOverride(userAgent).Call(page)
+}
+
+// userAgentOverride builds the user agent override for userAgent, with
+// Client Hints describing the same browser, platform and form factor.
+func userAgentOverride(userAgent string) proto.NetworkSetUserAgentOverride {
 	// Extract Chrome version from user agent for Client Hints
 	// User agent format: ...Chrome/124.0.0.0...
 	chromeVersion := "124"
@@ -1164,6 +1160,9 @@ func SetUserAgent(page *rod.Page, userAgent string) error {
 	platform := "Linux"
 	platformVersion := "6.5.0"
 	architecture := "x86_64"
+	bitness := "64"
+	model := ""
+	mobile := false
 	if strings.Contains(userAgent, "Windows") {
 		platform = "Windows"
 		platformVersion = "15.0.0"
@@ -1172,7 +1171,17 @@ func SetUserAgent(page *rod.Page, userAgent string) error {
 		platform = "macOS"
 		platformVersion = "14.0.0"
 		architecture = "arm"
+	} else if strings.Contains(userAgent, "Android") {
+		platform = "Android"
+		platformVersion = "14.0.0"
+		architecture = ""
+		bitness = ""
+		mobile = strings.Contains(userAgent, "Mobile")
+		if mobile {
+			model = "K" // what Chrome's reduced Android UA reports
+		}
 	}
+	fullVersion := chromeFullVersion(chromeVersion)
 
 	// Include "Google Chrome" brand to match real Chrome browsers
 	// Real Chrome includes: "Not_A Brand", "Google Chrome", "Chromium"
@@ -1188,17 +1197,17 @@ func SetUserAgent(page *rod.Page, userAgent string) error {
 			},
 			FullVersionList: []*proto.EmulationUserAgentBrandVersion{
 				{Brand: "Not_A Brand", Version: "8.0.0.0"},
-				{Brand: "Chromium", Version: chromeVersion + ".0.0.0"},
-				{Brand: "Google Chrome", Version: chromeVersion + ".0.0.0"},
+				{Brand: "Chromium", Version: fullVersion},
+				{Brand: "Google Chrome", Version: fullVersion},
 			},
 			Platform:        platform,
 			PlatformVersion: platformVersion,
 			Architecture:    architecture,
-			Model:           "",
-			Mobile:          false,
-			Bitness:         "64",
+			Model:           model,
+			Mobile:          mobile,
+			Bitness:         bitness,
 		},
-	}.Call(page)
+	}
 }
 
 // SetViewport sets the page viewport size.
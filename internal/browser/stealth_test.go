@@ -53,3 +53,28 @@ func TestGate2CorrectionsScriptBalanced(t *testing.T) {
 		}
 	}
 }
+
+func TestUserAgentOverrideClientHints(t *testing.T) {
+	setInstalledChromeVersion("136.0.7103.92")
+	defer installedChromeVersion.Store(nil)
+
+	o := userAgentOverride("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36")
+	m := o.UserAgentMetadata
+	if o.Platform != "Windows" || m.Platform != "Windows" || m.Mobile || m.Bitness != "64" {
+		t.Errorf("desktop hints = %+v", m)
+	}
+	if m.Brands[1].Version != "136" || m.FullVersionList[2].Version != "136.0.7103.92" {
+		t.Errorf("versions = %s, %s; want 136 and the installed full version", m.Brands[1].Version, m.FullVersionList[2].Version)
+	}
+
+	// A UA claiming another major version cannot borrow the installed full version
+	m = userAgentOverride("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36").UserAgentMetadata
+	if m.Platform != "macOS" || m.FullVersionList[2].Version != "131.0.0.0" {
+		t.Errorf("macOS hints = %s, %s", m.Platform, m.FullVersionList[2].Version)
+	}
+
+	m = userAgentOverride("Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Mobile Safari/537.36").UserAgentMetadata
+	if m.Platform != "Android" || !m.Mobile || m.Model != "K" || m.Bitness != "" {
+		t.Errorf("Android hints = %+v", m)
+	}
+}
@@ -0,0 +1,152 @@
+package browser
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-rod/rod/lib/launcher"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/security"
+)
+
+// System proxy settings for browsers launched without a proxy of their own:
+// a PAC script (PROXY_PAC_URL) or the HTTP_PROXY/HTTPS_PROXY/NO_PROXY
+// environment (PROXY_FROM_ENV). Chrome evaluates them per request, so one
+// pooled browser can reach different targets through different egress paths.
+
+// maxPACSize bounds a PAC script read from disk.
+const maxPACSize = 1 << 20
+
+// systemProxy holds the Chrome flags derived from the system proxy settings.
+// The zero value configures nothing.
+type systemProxy struct {
+	pacURL string // --proxy-pac-url
+	server string // --proxy-server
+	bypass string // --proxy-bypass-list
+
+	// droppedCredentials is set when an environment proxy URL carried
+	// credentials, which Chrome cannot use from --proxy-server
+	droppedCredentials bool
+}
+
+// loadSystemProxy resolves PROXY_PAC_URL or, with fromEnv, the proxy
+// environment variables. A PAC file on disk is inlined as a data: URL since
+// Chrome does not load PAC scripts from file:// URLs.
+func loadSystemProxy(pacURL string, fromEnv bool, getenv func(string) string) (systemProxy, error) {
+	if pacURL != "" {
+		if u, err := url.Parse(pacURL); err == nil && (u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "data") {
+			return systemProxy{pacURL: pacURL}, nil
+		}
+		path := strings.TrimPrefix(pacURL, "file://")
+		info, err := os.Stat(path)
+		if err != nil {
+			return systemProxy{}, fmt.Errorf("failed to read PAC script: %w", err)
+		}
+		if info.Size() > maxPACSize {
+			return systemProxy{}, fmt.Errorf("PAC script %s is larger than %d bytes", path, maxPACSize)
+		}
+		script, err := os.ReadFile(path) //nolint:gosec // path comes from operator configuration
+		if err != nil {
+			return systemProxy{}, fmt.Errorf("failed to read PAC script: %w", err)
+		}
+		return systemProxy{pacURL: "data:application/x-ns-proxy-autoconfig;base64," + base64.StdEncoding.EncodeToString(script)}, nil
+	}
+	if !fromEnv {
+		return systemProxy{}, nil
+	}
+	return envProxy(getenv), nil
+}
+
+// envProxy translates the proxy environment variables, upper or lower case,
+// into Chrome's per-scheme --proxy-server and --proxy-bypass-list syntax.
+func envProxy(getenv func(string) string) systemProxy {
+	lookup := func(name string) string {
+		if v := getenv(name); v != "" {
+			return strings.TrimSpace(v)
+		}
+		return strings.TrimSpace(getenv(strings.ToLower(name)))
+	}
+	httpProxy, httpsProxy, allProxy := lookup("HTTP_PROXY"), lookup("HTTPS_PROXY"), lookup("ALL_PROXY")
+	if httpProxy == "" {
+		httpProxy = allProxy
+	}
+	if httpsProxy == "" {
+		httpsProxy = allProxy
+	}
+
+	var sp systemProxy
+	var rules []string
+	if httpProxy != "" {
+		rules = append(rules, "http="+sp.withoutCredentials(httpProxy))
+	}
+	if httpsProxy != "" {
+		rules = append(rules, "https="+sp.withoutCredentials(httpsProxy))
+	}
+	if len(rules) == 0 {
+		return systemProxy{}
+	}
+
+	var bypass []string
+	for _, entry := range strings.Split(lookup("NO_PROXY"), ",") {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+		case entry == "*":
+			// Everything bypasses the proxy
+			return systemProxy{}
+		case strings.HasPrefix(entry, "."):
+			bypass = append(bypass, "*"+entry)
+		case strings.Contains(entry, "/") || strings.HasPrefix(entry, "*"):
+			bypass = append(bypass, entry)
+		default:
+			// NO_PROXY covers subdomains, a Chrome bypass rule only the host
+			bypass = append(bypass, entry, "*."+entry)
+		}
+	}
+	sp.server, sp.bypass = strings.Join(rules, ";"), strings.Join(bypass, ";")
+	return sp
+}
+
+// withoutCredentials removes the user info from a proxy URL.
+func (sp *systemProxy) withoutCredentials(proxyURL string) string {
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.User == nil || u.Host == "" {
+		return proxyURL
+	}
+	sp.droppedCredentials = true
+	u.User = nil
+	return u.String()
+}
+
+// configured reports whether any system proxy setting applies.
+func (sp systemProxy) configured() bool {
+	return sp.pacURL != "" || sp.server != ""
+}
+
+// apply sets the system proxy flags on l.
+func (sp systemProxy) apply(l *launcher.Launcher) *launcher.Launcher {
+	if sp.pacURL != "" {
+		return l.Set("proxy-pac-url", sp.pacURL)
+	}
+	if sp.server != "" {
+		l = l.Set("proxy-server", sp.server)
+		if sp.bypass != "" {
+			l = l.Set("proxy-bypass-list", sp.bypass)
+		}
+	}
+	return l
+}
+
+// describe summarizes the settings for the startup log, without credentials.
+func (sp systemProxy) describe() string {
+	switch {
+	case strings.HasPrefix(sp.pacURL, "data:"):
+		return "PAC script (inline)"
+	case sp.pacURL != "":
+		return "PAC script " + security.RedactProxyURL(sp.pacURL)
+	}
+	return "environment proxy"
+}
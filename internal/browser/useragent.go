@@ -0,0 +1,266 @@
+package browser
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// maxUserAgentLength caps a single UA string from the pool file.
+const maxUserAgentLength = 512
+
+// chromeVersionToken matches the Chrome version in a user agent.
+var chromeVersionToken = regexp.MustCompile(`Chrome/(\d+)(\.\d+)*`)
+
+// UserAgentEntry is one candidate identity in a user agent pool.
+// Profile optionally names a builtin fingerprint profile (see BuiltinProfiles)
+// so the UA is paired with a coherent set of fingerprint dimensions.
+type UserAgentEntry struct {
+	UserAgent string `yaml:"userAgent" json:"userAgent"`
+	Weight    int    `yaml:"weight" json:"weight"`   // 0 = default weight of 1
+	Profile   string `yaml:"profile" json:"profile"` // empty = no profile
+}
+
+// UserAgentPool selects user agents by weight for pool browsers.
+// Entries can be replaced at any time with Swap; when backed by a file the
+// pool reloads it whenever its modification time changes, so edits take
+// effect at the next browser spawn or recycle without a restart.
+//
+// Safe for concurrent use.
+type UserAgentPool struct {
+	mu          sync.Mutex
+	entries     []UserAgentEntry
+	totalWeight int
+	path        string
+	modTime     time.Time
+	chromeMajor string // installed browser's major version, "" until known
+}
+
+// NewUserAgentPool creates a pool backed by the YAML or JSON file at path.
+// Returns an error if the file cannot be read or contains no valid entries.
+func NewUserAgentPool(path string) (*UserAgentPool, error) {
+	p := &UserAgentPool{path: path}
+	if err := p.reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewStaticUserAgentPool creates a pool from in-memory entries with no file backing.
+func NewStaticUserAgentPool(entries []UserAgentEntry) (*UserAgentPool, error) {
+	p := &UserAgentPool{}
+	if err := p.Swap(entries); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ParseUserAgentEntries parses and validates a user agent pool document.
+// The document is a YAML (or JSON) list of entries:
+//
+//   - userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) ..."
+//     weight: 3
+//     profile: desktop-chrome-windows
+func ParseUserAgentEntries(data []byte) ([]UserAgentEntry, error) {
+	var entries []UserAgentEntry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse user agent pool: %w", err)
+	}
+	if err := validateUserAgentEntries(entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// validateUserAgentEntries checks every entry and fills in default weights.
+func validateUserAgentEntries(entries []UserAgentEntry) error {
+	if len(entries) == 0 {
+		return fmt.Errorf("user agent pool has no entries")
+	}
+	for i := range entries {
+		e := &entries[i]
+		e.UserAgent = strings.TrimSpace(e.UserAgent)
+		if e.UserAgent == "" {
+			return fmt.Errorf("user agent pool entry %d: userAgent is required", i)
+		}
+		if len(e.UserAgent) > maxUserAgentLength {
+			return fmt.Errorf("user agent pool entry %d: userAgent exceeds %d characters", i, maxUserAgentLength)
+		}
+		if strings.ContainsAny(e.UserAgent, "\r\n") {
+			return fmt.Errorf("user agent pool entry %d: userAgent contains newline", i)
+		}
+		if e.Weight < 0 {
+			return fmt.Errorf("user agent pool entry %d: weight must not be negative", i)
+		}
+		if e.Weight == 0 {
+			e.Weight = 1
+		}
+		if e.Profile != "" {
+			if _, ok := BuiltinProfiles[e.Profile]; !ok {
+				return fmt.Errorf("user agent pool entry %d: unknown fingerprint profile %q", i, e.Profile)
+			}
+		}
+	}
+	return nil
+}
+
+// Swap atomically replaces the pool's entries.
+// Invalid entries leave the current set untouched.
+func (p *UserAgentPool) Swap(entries []UserAgentEntry) error {
+	cp := make([]UserAgentEntry, len(entries))
+	copy(cp, entries)
+	if err := validateUserAgentEntries(cp); err != nil {
+		return err
+	}
+
+	total := 0
+	for _, e := range cp {
+		total += e.Weight
+	}
+
+	p.mu.Lock()
+	p.entries = cp
+	p.totalWeight = total
+	p.mu.Unlock()
+
+	log.Info().Int("entries", len(cp)).Msg("User agent pool updated")
+	return nil
+}
+
+// Pick returns a weighted-random entry, reloading the backing file first if
+// it has changed. Once the installed browser's version is known, the
+// entry's Chrome version is replaced with its major version. Returns false
+// if the pool is empty.
+func (p *UserAgentPool) Pick() (UserAgentEntry, bool) {
+	p.reloadIfChanged()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.totalWeight <= 0 {
+		return UserAgentEntry{}, false
+	}
+
+	n := rand.Intn(p.totalWeight)
+	for _, e := range p.entries {
+		if n < e.Weight {
+			e.UserAgent = alignChromeVersion(e.UserAgent, p.chromeMajor)
+			return e, true
+		}
+		n -= e.Weight
+	}
+	e := p.entries[len(p.entries)-1]
+	e.UserAgent = alignChromeVersion(e.UserAgent, p.chromeMajor)
+	return e, true
+}
+
+// SetChromeVersion records the installed browser's version (e.g.
+// "136.0.7103.92"). Picked user agents then claim its major version, since
+// a UA claiming another version than the browser's JavaScript APIs reveal
+// is detectable.
+func (p *UserAgentPool) SetChromeVersion(version string) {
+	major, _, _ := strings.Cut(version, ".")
+	p.mu.Lock()
+	p.chromeMajor = major
+	mismatched := p.mismatchedLocked()
+	p.mu.Unlock()
+	if mismatched > 0 {
+		log.Info().
+			Int("entries", mismatched).
+			Str("chrome_major", major).
+			Msg("User agent pool entries claim another Chrome version than the installed browser, using the installed major version")
+	}
+}
+
+// mismatchedLocked counts the entries whose Chrome major version differs
+// from the installed browser's. Must be called with p.mu held.
+func (p *UserAgentPool) mismatchedLocked() int {
+	if p.chromeMajor == "" {
+		return 0
+	}
+	n := 0
+	for _, e := range p.entries {
+		if m := chromeVersionToken.FindStringSubmatch(e.UserAgent); m != nil && m[1] != p.chromeMajor {
+			n++
+		}
+	}
+	return n
+}
+
+// alignChromeVersion replaces the Chrome version in ua with major, written
+// the way Chrome's reduced user agent does ("Chrome/136.0.0.0"). An empty
+// major leaves ua unchanged.
+func alignChromeVersion(ua, major string) string {
+	if major == "" {
+		return ua
+	}
+	return chromeVersionToken.ReplaceAllLiteralString(ua, "Chrome/"+major+".0.0.0")
+}
+
+// Entries returns a copy of the current entries.
+func (p *UserAgentPool) Entries() []UserAgentEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]UserAgentEntry, len(p.entries))
+	copy(out, p.entries)
+	return out
+}
+
+// reload reads and applies the backing file unconditionally.
+func (p *UserAgentPool) reload() error {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat user agent pool file: %w", err)
+	}
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return fmt.Errorf("failed to read user agent pool file: %w", err)
+	}
+	entries, err := ParseUserAgentEntries(data)
+	if err != nil {
+		return err
+	}
+	if err := p.Swap(entries); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.modTime = info.ModTime()
+	p.mu.Unlock()
+	return nil
+}
+
+// reloadIfChanged reloads the backing file when its modification time differs
+// from the last successful load. Failures keep the previous entries.
+func (p *UserAgentPool) reloadIfChanged() {
+	if p.path == "" {
+		return
+	}
+	info, err := os.Stat(p.path)
+	if err != nil {
+		log.Debug().Err(err).Str("path", p.path).Msg("User agent pool file unavailable, keeping current entries")
+		return
+	}
+
+	p.mu.Lock()
+	unchanged := info.ModTime().Equal(p.modTime)
+	p.mu.Unlock()
+	if unchanged {
+		return
+	}
+
+	if err := p.reload(); err != nil {
+		log.Warn().Err(err).Str("path", p.path).Msg("Failed to reload user agent pool, keeping current entries")
+		// Remember the bad mtime so we don't re-parse the same file on every spawn.
+		p.mu.Lock()
+		p.modTime = info.ModTime()
+		p.mu.Unlock()
+	}
+}
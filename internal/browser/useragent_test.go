@@ -0,0 +1,208 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+)
+
+func TestParseUserAgentEntries(t *testing.T) {
+	data := []byte(`
+- userAgent: "UA-one"
+  weight: 3
+  profile: desktop-chrome-windows
+- userAgent: "UA-two"
+`)
+	entries, err := ParseUserAgentEntries(data)
+	if err != nil {
+		t.Fatalf("ParseUserAgentEntries() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Weight != 3 || entries[0].Profile != "desktop-chrome-windows" {
+		t.Errorf("Unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].Weight != 1 {
+		t.Errorf("Expected default weight 1, got %d", entries[1].Weight)
+	}
+}
+
+func TestParseUserAgentEntries_JSON(t *testing.T) {
+	entries, err := ParseUserAgentEntries([]byte(`[{"userAgent": "UA-json", "weight": 2}]`))
+	if err != nil {
+		t.Fatalf("ParseUserAgentEntries() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].UserAgent != "UA-json" {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+}
+
+func TestParseUserAgentEntries_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", `[]`},
+		{"missing userAgent", `[{"weight": 1}]`},
+		{"negative weight", `[{"userAgent": "UA", "weight": -1}]`},
+		{"unknown profile", `[{"userAgent": "UA", "profile": "nope"}]`},
+		{"newline", `[{"userAgent": "UA\r\nX-Injected: 1"}]`},
+		{"not a list", `userAgent: UA`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseUserAgentEntries([]byte(tt.data)); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestUserAgentPool_PickWeighted(t *testing.T) {
+	pool, err := NewStaticUserAgentPool([]UserAgentEntry{
+		{UserAgent: "heavy", Weight: 9},
+		{UserAgent: "light", Weight: 1},
+	})
+	if err != nil {
+		t.Fatalf("NewStaticUserAgentPool() error = %v", err)
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 5000; i++ {
+		e, ok := pool.Pick()
+		if !ok {
+			t.Fatal("Pick() returned false on non-empty pool")
+		}
+		counts[e.UserAgent]++
+	}
+	if counts["light"] == 0 {
+		t.Error("Expected light entry to be picked at least once")
+	}
+	if counts["heavy"] < counts["light"]*4 {
+		t.Errorf("Expected heavy entry to dominate, got %v", counts)
+	}
+}
+
+func TestUserAgentPool_SwapInvalidKeepsEntries(t *testing.T) {
+	pool, err := NewStaticUserAgentPool([]UserAgentEntry{{UserAgent: "original"}})
+	if err != nil {
+		t.Fatalf("NewStaticUserAgentPool() error = %v", err)
+	}
+	if err := pool.Swap(nil); err == nil {
+		t.Error("Expected error swapping in empty entries")
+	}
+	if e, _ := pool.Pick(); e.UserAgent != "original" {
+		t.Errorf("Expected original entry to survive failed swap, got %q", e.UserAgent)
+	}
+}
+
+func TestUserAgentPool_HotReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ua.yaml")
+	if err := os.WriteFile(path, []byte(`[{"userAgent": "first"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	pool, err := NewUserAgentPool(path)
+	if err != nil {
+		t.Fatalf("NewUserAgentPool() error = %v", err)
+	}
+	if e, _ := pool.Pick(); e.UserAgent != "first" {
+		t.Fatalf("Expected first, got %q", e.UserAgent)
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"userAgent": "second"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// Force a distinct mtime regardless of filesystem timestamp granularity
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := pool.Pick(); e.UserAgent != "second" {
+		t.Errorf("Expected reloaded entry second, got %q", e.UserAgent)
+	}
+
+	// A broken file keeps the last good entries
+	if err := os.WriteFile(path, []byte(`not: [valid`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	later := future.Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := pool.Pick(); e.UserAgent != "second" {
+		t.Errorf("Expected last good entry second, got %q", e.UserAgent)
+	}
+}
+
+func TestNewUserAgentPool_MissingFile(t *testing.T) {
+	if _, err := NewUserAgentPool(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestUserAgentPool_SetChromeVersion(t *testing.T) {
+	pool, err := NewStaticUserAgentPool([]UserAgentEntry{
+		{UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.6778.85 Safari/537.36"},
+	})
+	if err != nil {
+		t.Fatalf("NewStaticUserAgentPool() error = %v", err)
+	}
+	if e, _ := pool.Pick(); !strings.Contains(e.UserAgent, "Chrome/131.0.6778.85 ") {
+		t.Errorf("UA changed before the browser version is known: %q", e.UserAgent)
+	}
+
+	pool.SetChromeVersion("136.0.7103.92")
+	e, _ := pool.Pick()
+	if want := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36"; e.UserAgent != want {
+		t.Errorf("Pick() UA = %q, want %q", e.UserAgent, want)
+	}
+	if got := pool.Entries()[0].UserAgent; !strings.Contains(got, "Chrome/131.") {
+		t.Errorf("Entries() should keep the configured UA, got %q", got)
+	}
+}
+
+func TestPoolRotateIdentity(t *testing.T) {
+	uaPool, err := NewStaticUserAgentPool([]UserAgentEntry{{UserAgent: "a"}, {UserAgent: "b"}})
+	if err != nil {
+		t.Fatalf("NewStaticUserAgentPool() error = %v", err)
+	}
+	pooled, dedicated := &rod.Browser{}, &rod.Browser{}
+
+	for _, tt := range []struct {
+		rotation         string
+		request, session bool // whether each rotates
+	}{
+		{config.UserAgentRotationBrowser, false, false},
+		{config.UserAgentRotationSession, false, true},
+		{config.UserAgentRotationRequest, true, true},
+	} {
+		cfg := testConfig()
+		cfg.UserAgentRotation = tt.rotation
+		p := &Pool{config: cfg, userAgents: uaPool}
+		p.identities.Store(pooled, UserAgentEntry{UserAgent: "initial"})
+
+		p.RotateRequestIdentity(pooled)
+		if got, _ := p.IdentityFor(pooled); (got.UserAgent != "initial") != tt.request {
+			t.Errorf("%s: after RotateRequestIdentity identity = %q", tt.rotation, got.UserAgent)
+		}
+		p.identities.Store(pooled, UserAgentEntry{UserAgent: "initial"})
+		p.RotateSessionIdentity(pooled)
+		if got, _ := p.IdentityFor(pooled); (got.UserAgent != "initial") != tt.session {
+			t.Errorf("%s: after RotateSessionIdentity identity = %q", tt.rotation, got.UserAgent)
+		}
+
+		p.RotateRequestIdentity(dedicated)
+		p.RotateSessionIdentity(dedicated)
+		if _, ok := p.IdentityFor(dedicated); ok {
+			t.Errorf("%s: a browser without an identity got one", tt.rotation)
+		}
+	}
+}
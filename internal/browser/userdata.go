@@ -0,0 +1,87 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// chromeSingletonFiles are the profile lock files Chrome leaves in a
+// user-data-dir. A process killed with the container leaves them behind, and
+// Chrome refuses a dir locked by another hostname, so they are cleared before
+// each launch; lockUserDataDir already guarantees exclusive use.
+var chromeSingletonFiles = []string{"SingletonLock", "SingletonSocket", "SingletonCookie"}
+
+// lockUserDataDir waits for exclusive use of a persistent user-data-dir.
+// Chrome allows one process per dir, so browsers for the same persistent
+// profile take turns. Idle browsers parked on the dir are closed first.
+func (p *Pool) lockUserDataDir(ctx context.Context, dir string) error {
+	v, _ := p.dataDirLocks.LoadOrStore(dir, make(chan struct{}, 1))
+	lock, _ := v.(chan struct{})
+
+	select {
+	case lock <- struct{}{}:
+		return nil
+	default:
+	}
+	p.expireIdleForDataDir(dir)
+
+	select {
+	case lock <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %v", types.ErrContextCanceled, ctx.Err())
+	case <-time.After(p.config.BrowserPoolTimeout):
+		return fmt.Errorf("%w: persistent profile is in use", types.ErrBrowserPoolTimeout)
+	}
+}
+
+// unlockUserDataDir hands a persistent user-data-dir to the next waiter.
+func (p *Pool) unlockUserDataDir(dir string) {
+	if v, ok := p.dataDirLocks.Load(dir); ok {
+		<-v.(chan struct{})
+	}
+}
+
+// prepareUserDataDir creates a persistent user-data-dir and clears stale
+// Chrome locks from a previous run.
+func prepareUserDataDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create user data dir: %w", err)
+	}
+	for _, name := range chromeSingletonFiles {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			log.Warn().Err(err).Str("file", name).Msg("Failed to remove stale Chrome profile lock")
+		}
+	}
+	return nil
+}
+
+// expireIdleForDataDir closes parked browsers that hold dir, so a request
+// for the same persistent profile through another proxy can take it over.
+func (p *Pool) expireIdleForDataDir(dir string) {
+	p.proxyIdleMu.Lock()
+	var expired []*rod.Browser
+	kept := p.proxyIdle[:0]
+	for _, idle := range p.proxyIdle {
+		if d, ok := p.persistentDirs.Load(idle.browser); ok && d == dir {
+			expired = append(expired, idle.browser)
+		} else {
+			kept = append(kept, idle)
+		}
+	}
+	clear(p.proxyIdle[len(kept):])
+	p.proxyIdle = kept
+	p.proxyIdleMu.Unlock()
+
+	for _, b := range expired {
+		p.CleanupBrowser(b)
+	}
+}
@@ -0,0 +1,77 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func TestAssignProfileDataDirs(t *testing.T) {
+	profiles := map[string]*BrowserProfile{
+		"returning": {Name: "returning", Persistent: true},
+		"pristine":  {Name: "pristine"},
+	}
+	if err := assignProfileDataDirs(profiles, ""); err == nil {
+		t.Error("Expected error for a persistent profile without a data dir")
+	}
+
+	base := t.TempDir()
+	if err := assignProfileDataDirs(profiles, base); err != nil {
+		t.Fatalf("assignProfileDataDirs() error = %v", err)
+	}
+	if got := profiles["returning"].LaunchOptions("").UserDataDir; got != filepath.Join(base, "returning") {
+		t.Errorf("Expected user data dir under %s, got %q", base, got)
+	}
+	if got := profiles["pristine"].LaunchOptions("").UserDataDir; got != "" {
+		t.Errorf("Expected no user data dir for a non-persistent profile, got %q", got)
+	}
+}
+
+func TestPrepareUserDataDirClearsStaleLocks(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profile")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("other-host-1234", filepath.Join(dir, "SingletonLock")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Local State"), []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := prepareUserDataDir(dir); err != nil {
+		t.Fatalf("prepareUserDataDir() error = %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "SingletonLock")); !os.IsNotExist(err) {
+		t.Error("Expected stale SingletonLock to be removed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Local State")); err != nil {
+		t.Error("Expected profile data to be kept")
+	}
+}
+
+func TestLockUserDataDir(t *testing.T) {
+	p := &Pool{config: &config.Config{BrowserPoolTimeout: 50 * time.Millisecond}}
+	ctx := context.Background()
+
+	if err := p.lockUserDataDir(ctx, "/data/a"); err != nil {
+		t.Fatalf("First lock failed: %v", err)
+	}
+	if err := p.lockUserDataDir(ctx, "/data/b"); err != nil {
+		t.Errorf("Lock on another dir should not wait: %v", err)
+	}
+	if err := p.lockUserDataDir(ctx, "/data/a"); !errors.Is(err, types.ErrBrowserPoolTimeout) {
+		t.Errorf("Expected pool timeout on a held dir, got %v", err)
+	}
+
+	p.unlockUserDataDir("/data/a")
+	if err := p.lockUserDataDir(ctx, "/data/a"); err != nil {
+		t.Errorf("Lock after unlock failed: %v", err)
+	}
+}
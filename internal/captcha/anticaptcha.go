@@ -2,19 +2,32 @@
 package captcha
 
 import (
+	"context"
 	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
 )
 
 const (
-	// anti-captcha.com API endpoints (compatible with 2Captcha API format)
 	antiCaptchaBaseURL = "https://api.anti-captcha.com"
+
+	// anti-captcha.com asks clients to poll no more often than every 3 seconds
+	antiCaptchaPollInterval = 3 * time.Second
+
+	antiCaptchaDefaultTimeout = 120 * time.Second
+
+	// antiCaptchaMinScore is the reCAPTCHA v3 score requested; the API
+	// requires one of 0.3, 0.7 or 0.9.
+	antiCaptchaMinScore = 0.7
 )
 
-// AntiCaptchaSolver implements CaptchaSolver for anti-captcha.com API.
-// anti-captcha.com uses the same API format as 2Captcha, so we reuse
-// TwoCaptchaSolver with a different base URL.
+// AntiCaptchaSolver implements CaptchaSolver for the anti-captcha.com task
+// API. Turnstile tasks run through the request's proxy when it has one, so
+// the token is minted from the browser's IP.
 type AntiCaptchaSolver struct {
-	*TwoCaptchaSolver
+	client *taskClient
 }
 
 func init() {
@@ -27,20 +40,151 @@ func init() {
 type AntiCaptchaConfig struct {
 	APIKey  string
 	Timeout time.Duration
+	BaseURL string // Override for testing
 }
 
 // NewAntiCaptchaSolver creates a new anti-captcha.com solver instance.
 func NewAntiCaptchaSolver(cfg AntiCaptchaConfig) *AntiCaptchaSolver {
-	return &AntiCaptchaSolver{
-		TwoCaptchaSolver: NewTwoCaptchaSolver(TwoCaptchaConfig{
-			APIKey:  cfg.APIKey,
-			Timeout: cfg.Timeout,
-			BaseURL: antiCaptchaBaseURL,
-		}),
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = antiCaptchaDefaultTimeout
 	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = antiCaptchaBaseURL
+	}
+
+	return &AntiCaptchaSolver{client: newTaskClient(taskClient{
+		provider:     "anticaptcha",
+		label:        "anti-captcha.com",
+		apiKey:       cfg.APIKey,
+		baseURL:      baseURL,
+		timeout:      timeout,
+		pollInterval: antiCaptchaPollInterval,
+		rejection:    antiCaptchaRejection,
+	})}
 }
 
 // Name returns the provider name.
 func (s *AntiCaptchaSolver) Name() string {
 	return "anticaptcha"
 }
+
+// IsConfigured returns true if API key is set.
+func (s *AntiCaptchaSolver) IsConfigured() bool {
+	return s.client.configured()
+}
+
+// SolveTurnstile solves a Turnstile challenge using the anti-captcha.com API.
+// With a request proxy the task is a TurnstileTask solved through it;
+// otherwise, or when the proxy cannot be expressed as task fields, a
+// TurnstileTaskProxyless.
+func (s *AntiCaptchaSolver) SolveTurnstile(ctx context.Context, req *TurnstileRequest) (*TurnstileResult, error) {
+	task := apiTask{
+		Type:           "TurnstileTaskProxyless",
+		WebsiteURL:     req.PageURL,
+		WebsiteKey:     req.SiteKey,
+		Action:         req.Action,
+		TurnstileCData: req.CData,
+	}
+	// Managed challenge pages need the render() parameters and the UA the
+	// token will be presented with
+	if req.PageData != "" {
+		task.CloudflareTaskType = "token"
+		task.PageData = req.PageData
+		task.UserAgent = req.UserAgent
+	}
+	if req.Proxy != nil && req.Proxy.URL != "" {
+		proxy, err := parseTaskProxy(req.Proxy)
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Proxy unusable for anti-captcha.com, solving proxyless")
+		} else {
+			task.Type = "TurnstileTask"
+			task.ProxyType = antiCaptchaProxyType(proxy.Type)
+			task.ProxyAddress = proxy.Address
+			task.ProxyPort = proxy.Port
+			task.ProxyLogin = proxy.Login
+			task.ProxyPassword = proxy.Password
+		}
+	}
+	return s.client.solveTask(ctx, "Turnstile", task, 0.002) // anti-captcha.com Turnstile pricing ~$2.00 per 1000
+}
+
+// antiCaptchaProxyType maps a proxy URL scheme to the API's proxyType.
+func antiCaptchaProxyType(scheme string) string {
+	switch scheme {
+	case "socks4", "socks5":
+		return scheme
+	case "socks5h":
+		return "socks5"
+	}
+	return "http"
+}
+
+// SolveHCaptcha solves an hCaptcha challenge using the anti-captcha.com API.
+func (s *AntiCaptchaSolver) SolveHCaptcha(ctx context.Context, req *HCaptchaRequest) (*CaptchaResult, error) {
+	return s.client.solveTask(ctx, "hCaptcha", apiTask{
+		Type:       "HCaptchaTaskProxyless",
+		WebsiteURL: req.PageURL,
+		WebsiteKey: req.SiteKey,
+	}, 0.002) // anti-captcha.com hCaptcha pricing ~$2.00 per 1000
+}
+
+// SolveRecaptcha solves a reCAPTCHA v2 or v3 challenge using the
+// anti-captcha.com API.
+func (s *AntiCaptchaSolver) SolveRecaptcha(ctx context.Context, req *RecaptchaRequest) (*CaptchaResult, error) {
+	task := apiTask{
+		Type:        "RecaptchaV2TaskProxyless",
+		WebsiteURL:  req.PageURL,
+		WebsiteKey:  req.SiteKey,
+		IsInvisible: req.Invisible,
+	}
+	if req.V3 {
+		task = apiTask{
+			Type:       "RecaptchaV3TaskProxyless",
+			WebsiteURL: req.PageURL,
+			WebsiteKey: req.SiteKey,
+			MinScore:   antiCaptchaMinScore,
+			PageAction: req.Action,
+		}
+	}
+	return s.client.solveTask(ctx, "reCAPTCHA", task, 0.002) // anti-captcha.com reCAPTCHA pricing ~$2.00 per 1000
+}
+
+// SolveDataDome is not supported by anti-captcha.com. Returning a rejected
+// error lets SolverChain fall through to the next provider.
+func (s *AntiCaptchaSolver) SolveDataDome(_ context.Context, _ *DataDomeRequest) (*CaptchaResult, error) {
+	return nil, types.NewCaptchaRejectedError(s.Name(), "UNSUPPORTED", "anti-captcha.com does not support DataDome")
+}
+
+// SolveImage reads an image captcha using the anti-captcha.com API.
+func (s *AntiCaptchaSolver) SolveImage(ctx context.Context, req *ImageRequest) (*CaptchaResult, error) {
+	return s.client.solveTask(ctx, "image", apiTask{
+		Type:       "ImageToTextTask",
+		WebsiteURL: req.PageURL,
+		Body:       req.body(),
+	}, 0.0007) // anti-captcha.com image pricing ~$0.70 per 1000
+}
+
+// Balance retrieves the current account balance.
+func (s *AntiCaptchaSolver) Balance(ctx context.Context) (float64, error) {
+	return s.client.balance(ctx)
+}
+
+// antiCaptchaRejection explains the anti-captcha.com error codes that reject a task.
+func antiCaptchaRejection(code string) string {
+	switch code {
+	case "ERROR_RECAPTCHA_INVALID_SITEKEY", "ERROR_WRONG_CAPTCHA_ID", "ERROR_TASK_NOT_SUPPORTED":
+		return "invalid sitekey or task data"
+	case "ERROR_CAPTCHA_UNSOLVABLE":
+		return "captcha could not be solved"
+	case "ERROR_KEY_DOES_NOT_EXIST", "ERROR_IP_NOT_ALLOWED", "ERROR_IP_BLOCKED":
+		return "invalid API key or client IP not allowed"
+	case "ERROR_NO_SUCH_CAPCHA_ID", "ERROR_TASK_ABSENT":
+		return "task not found or expired"
+	case "ERROR_PROXY_CONNECT_REFUSED", "ERROR_PROXY_CONNECT_TIMEOUT", "ERROR_PROXY_READ_TIMEOUT", "ERROR_PROXY_BANNED", "ERROR_PROXY_TRANSPARENT":
+		return "anti-captcha.com could not use the proxy"
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package captcha
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// newTestAntiCaptcha returns a solver against the fake task API.
+func newTestAntiCaptcha(t *testing.T, created *taskCreateRequest, result taskResultResponse) *AntiCaptchaSolver {
+	t.Helper()
+	solver := NewAntiCaptchaSolver(AntiCaptchaConfig{APIKey: "test-key", BaseURL: newTestTaskAPI(t, created, result)})
+	solver.client.pollInterval = testPollInterval
+	return solver
+}
+
+func TestAntiCaptchaSolver_SolveTurnstile_Success(t *testing.T) {
+	var created taskCreateRequest
+	solver := newTestAntiCaptcha(t, &created, taskResultResponse{
+		Status:   "ready",
+		Solution: &taskSolution{Token: "anticaptcha-token"},
+		Cost:     "0.00150",
+	})
+
+	result, err := solver.SolveTurnstile(context.Background(), &TurnstileRequest{
+		SiteKey:  "0x4AAAAAAA",
+		PageURL:  "https://example.com",
+		Action:   "login",
+		PageData: "chl-page-data",
+	})
+	if err != nil {
+		t.Fatalf("SolveTurnstile() error = %v", err)
+	}
+	if result.Token != "anticaptcha-token" {
+		t.Errorf("Token = %q, want %q", result.Token, "anticaptcha-token")
+	}
+	if result.Cost != 0.0015 {
+		t.Errorf("Cost = %v, want the reported 0.0015", result.Cost)
+	}
+	if result.Provider != "anticaptcha" {
+		t.Errorf("Provider = %q, want anticaptcha", result.Provider)
+	}
+
+	task := created.Task
+	if created.ClientKey != "test-key" || task.Type != "TurnstileTaskProxyless" {
+		t.Errorf("createTask = %+v, want a TurnstileTaskProxyless with the client key", created)
+	}
+	if task.Action != "login" || task.CloudflareTaskType != "token" || task.PageData != "chl-page-data" {
+		t.Errorf("task = %+v, want the action and managed challenge page data", task)
+	}
+}
+
+func TestAntiCaptchaSolver_SolveTurnstile_Proxy(t *testing.T) {
+	var created taskCreateRequest
+	solver := newTestAntiCaptcha(t, &created, taskResultResponse{
+		Status:   "ready",
+		Solution: &taskSolution{Token: "proxied-token"},
+	})
+
+	result, err := solver.SolveTurnstile(context.Background(), &TurnstileRequest{
+		SiteKey: "0x4AAAAAAA",
+		PageURL: "https://example.com",
+		Proxy:   &types.Proxy{URL: "socks5://10.0.0.1:1080", Username: "user", Password: "pass"},
+	})
+	if err != nil {
+		t.Fatalf("SolveTurnstile() error = %v", err)
+	}
+	if result.Cost != 0.002 {
+		t.Errorf("Cost = %v, want the 0.002 estimate when none is reported", result.Cost)
+	}
+
+	task := created.Task
+	if task.Type != "TurnstileTask" {
+		t.Errorf("Type = %q, want TurnstileTask", task.Type)
+	}
+	if task.ProxyType != "socks5" || task.ProxyAddress != "10.0.0.1" || task.ProxyPort != 1080 {
+		t.Errorf("proxy = %s %s:%d, want socks5 10.0.0.1:1080", task.ProxyType, task.ProxyAddress, task.ProxyPort)
+	}
+	if task.ProxyLogin != "user" || task.ProxyPassword != "pass" {
+		t.Errorf("proxy credentials = %q/%q, want user/pass", task.ProxyLogin, task.ProxyPassword)
+	}
+}
+
+func TestAntiCaptchaSolver_SolveRecaptchaV3(t *testing.T) {
+	var created taskCreateRequest
+	solver := newTestAntiCaptcha(t, &created, taskResultResponse{
+		Status:   "ready",
+		Solution: &taskSolution{GRecaptchaResponse: "recaptcha-token"},
+	})
+
+	result, err := solver.SolveRecaptcha(context.Background(), &RecaptchaRequest{
+		SiteKey: "6Lc-key",
+		PageURL: "https://example.com",
+		V3:      true,
+		Action:  "submit",
+	})
+	if err != nil {
+		t.Fatalf("SolveRecaptcha() error = %v", err)
+	}
+	if result.Token != "recaptcha-token" {
+		t.Errorf("Token = %q, want recaptcha-token", result.Token)
+	}
+	if task := created.Task; task.Type != "RecaptchaV3TaskProxyless" || task.PageAction != "submit" || task.MinScore != antiCaptchaMinScore {
+		t.Errorf("task = %+v, want a RecaptchaV3TaskProxyless for action submit", task)
+	}
+}
+
+func TestAntiCaptchaRejection(t *testing.T) {
+	for _, code := range []string{"ERROR_CAPTCHA_UNSOLVABLE", "ERROR_KEY_DOES_NOT_EXIST", "ERROR_TASK_ABSENT", "ERROR_PROXY_CONNECT_REFUSED"} {
+		if antiCaptchaRejection(code) == "" {
+			t.Errorf("antiCaptchaRejection(%q) is empty, want a reason", code)
+		}
+	}
+	if reason := antiCaptchaRejection("ERROR_SOMETHING_NEW"); reason != "" {
+		t.Errorf("antiCaptchaRejection() = %q for an unknown code, want empty", reason)
+	}
+}
+
+func TestExplicitOrder(t *testing.T) {
+	available := []string{"2captcha", "9kw", "anticaptcha", "capsolver"}
+	got := ExplicitOrder([]string{"anticaptcha", "unknown", "capsolver", "anticaptcha"}, available)
+	want := []string{"anticaptcha", "capsolver"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("ExplicitOrder() = %v, want %v", got, want)
+	}
+}
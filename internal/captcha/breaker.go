@@ -0,0 +1,185 @@
+// Package captcha provides external CAPTCHA solver integration.
+package captcha
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// Circuit breaker states.
+const (
+	BreakerClosed   = "closed"    // Calls go through
+	BreakerOpen     = "open"      // Calls are skipped until the cooldown ends
+	BreakerHalfOpen = "half-open" // One probe call decides between closed and open
+)
+
+const (
+	breakerWindow   = 20 // Recent calls the failure rate is taken over
+	breakerMinCalls = 5  // Calls needed before a breaker may open
+)
+
+// BreakerConfig sets when a provider's circuit breaker opens. A failure is an
+// error from the provider or a successful call slower than SlowCall.
+type BreakerConfig struct {
+	ErrorRate float64       // Failure share (0-1) of the recent calls that opens the breaker; 0 disables breakers
+	SlowCall  time.Duration // Successful calls slower than this count as failures; 0 disables
+	Cooldown  time.Duration // How long an open breaker skips the provider before a probe (default: 60s)
+}
+
+// breakerOutcome is how a provider call counts toward its breaker.
+type breakerOutcome int
+
+const (
+	breakerSuccess breakerOutcome = iota
+	breakerFailure
+	breakerIgnored // Says nothing about the provider's health
+)
+
+// breaker is the circuit breaker of one provider. A nil *breaker lets every
+// call through. It is safe for concurrent use.
+type breaker struct {
+	mu       sync.Mutex
+	cfg      BreakerConfig
+	window   []bool // Recent outcomes, true for a failure; a ring once full
+	next     int    // Ring position of the next outcome
+	state    string
+	openedAt time.Time
+	probing  bool // A half-open probe is in flight
+	now      func() time.Time
+}
+
+// newBreaker returns a closed breaker, or nil when cfg disables breakers.
+func newBreaker(cfg BreakerConfig) *breaker {
+	if cfg.ErrorRate <= 0 {
+		return nil
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 60 * time.Second
+	}
+	return &breaker{cfg: cfg, state: BreakerClosed, now: time.Now}
+}
+
+// allow reports whether a call may go to the provider. Once the cooldown of
+// an open breaker has passed, it lets a single probe through.
+func (b *breaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.Cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+// record counts the outcome of a call allow let through.
+func (b *breaker) record(outcome breakerOutcome) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerHalfOpen {
+		b.probing = false
+		switch outcome {
+		case breakerSuccess:
+			b.state = BreakerClosed
+			b.window = b.window[:0]
+			b.next = 0
+		case breakerFailure:
+			b.state = BreakerOpen
+			b.openedAt = b.now()
+		}
+		return
+	}
+	if outcome == breakerIgnored {
+		return
+	}
+
+	failed := outcome == breakerFailure
+	if len(b.window) < breakerWindow {
+		b.window = append(b.window, failed)
+	} else {
+		b.window[b.next] = failed
+	}
+	b.next = (b.next + 1) % breakerWindow
+
+	if b.state == BreakerClosed && len(b.window) >= breakerMinCalls && b.failureRate() >= b.cfg.ErrorRate {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// failureRate is the failure share of the window. Must be called with lock
+// held.
+func (b *breaker) failureRate() float64 {
+	if len(b.window) == 0 {
+		return 0
+	}
+	failures := 0
+	for _, failed := range b.window {
+		if failed {
+			failures++
+		}
+	}
+	return float64(failures) / float64(len(b.window))
+}
+
+// outcome classifies a provider call that took d and returned err. Calls the
+// caller cancelled and task types the provider doesn't support say nothing
+// about its health.
+func (b *breaker) outcome(ctx context.Context, err error, d time.Duration) breakerOutcome {
+	if err == nil {
+		if b != nil && b.cfg.SlowCall > 0 && d > b.cfg.SlowCall {
+			return breakerFailure
+		}
+		return breakerSuccess
+	}
+	if ctx.Err() != nil {
+		return breakerIgnored
+	}
+	var captchaErr *types.CaptchaError
+	if errors.As(err, &captchaErr) && captchaErr.Code == "UNSUPPORTED" {
+		return breakerIgnored
+	}
+	return breakerFailure
+}
+
+// BreakerStatus is the state of a provider's circuit breaker.
+type BreakerStatus struct {
+	State       string    // BreakerClosed, BreakerOpen or BreakerHalfOpen
+	FailureRate float64   // Failure share of the recent calls (0-1)
+	Calls       int       // Recent calls the rate is taken over
+	RetryAt     time.Time // When an open breaker lets a probe through; zero otherwise
+}
+
+// status returns the breaker's state.
+func (b *breaker) status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	st := BreakerStatus{State: b.state, FailureRate: b.failureRate(), Calls: len(b.window)}
+	if b.state == BreakerOpen {
+		st.RetryAt = b.openedAt.Add(b.cfg.Cooldown)
+	}
+	return st
+}
@@ -0,0 +1,151 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func TestBreaker_Transitions(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	b := newBreaker(BreakerConfig{ErrorRate: 0.5, Cooldown: time.Minute})
+	b.now = func() time.Time { return now }
+
+	// Too few calls to judge, even though all failed
+	for i := 0; i < breakerMinCalls-1; i++ {
+		b.record(breakerFailure)
+	}
+	if !b.allow() || b.status().State != BreakerClosed {
+		t.Fatalf("breaker opened before %d calls", breakerMinCalls)
+	}
+	b.record(breakerFailure)
+	if b.allow() {
+		t.Fatal("allow() after the failure rate crossed the threshold, want the breaker open")
+	}
+	if st := b.status(); st.State != BreakerOpen || !st.RetryAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("status = %+v, want open until the cooldown ends", st)
+	}
+
+	// After the cooldown a single probe goes through
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Fatal("allow() after the cooldown, want a probe")
+	}
+	if b.allow() {
+		t.Error("allow() let a second probe through")
+	}
+	b.record(breakerFailure)
+	if b.status().State != BreakerOpen || b.allow() {
+		t.Fatal("a failed probe should reopen the breaker")
+	}
+
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Fatal("allow() after the second cooldown, want a probe")
+	}
+	b.record(breakerIgnored) // a cancelled probe decides nothing
+	if b.status().State != BreakerHalfOpen || !b.allow() {
+		t.Fatal("an ignored probe should leave the breaker half-open for the next one")
+	}
+	b.record(breakerSuccess)
+	if st := b.status(); st.State != BreakerClosed || st.Calls != 0 {
+		t.Errorf("status = %+v, want closed with a fresh window", st)
+	}
+}
+
+func TestBreaker_Outcome(t *testing.T) {
+	b := newBreaker(BreakerConfig{ErrorRate: 0.5, SlowCall: time.Second})
+	ctx := context.Background()
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		d    time.Duration
+		want breakerOutcome
+	}{
+		{"fast success", ctx, nil, 500 * time.Millisecond, breakerSuccess},
+		{"slow success", ctx, nil, 2 * time.Second, breakerFailure},
+		{"rejected", ctx, types.NewCaptchaRejectedError("p", "ERROR", "no"), 0, breakerFailure},
+		{"unsupported", ctx, types.NewCaptchaRejectedError("p", "UNSUPPORTED", "no"), 0, breakerIgnored},
+		{"cancelled", cancelled, context.Canceled, 0, breakerIgnored},
+	}
+	for _, tt := range tests {
+		if got := b.outcome(tt.ctx, tt.err, tt.d); got != tt.want {
+			t.Errorf("%s: outcome = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if newBreaker(BreakerConfig{}) != nil {
+		t.Error("newBreaker() with no error rate should return nil")
+	}
+	var off *breaker
+	if !off.allow() {
+		t.Error("a nil breaker should allow every call")
+	}
+}
+
+func TestSolverChain_Breaker(t *testing.T) {
+	capsolverCalls := 0
+	capsolver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		capsolverCalls++
+		json.NewEncoder(w).Encode(capSolverCreateTaskResponse{ErrorID: 1, ErrorCode: "ERROR_SERVICE_UNAVALIABLE"})
+	}))
+	defer capsolver.Close()
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(webhookResponse{Token: "webhook-token"})
+	}))
+	defer webhook.Close()
+
+	chain := NewSolverChain(SolverChainConfig{
+		FallbackEnabled: true,
+		Metrics:         NewMetrics(),
+		Breaker:         BreakerConfig{ErrorRate: 0.5, Cooldown: time.Hour},
+		Providers: []CaptchaSolver{
+			NewCapSolverSolver(CapSolverConfig{APIKey: "test-key", BaseURL: capsolver.URL}),
+			NewWebhookSolver(WebhookConfig{URL: webhook.URL}),
+		},
+	})
+	req := &TurnstileRequest{SiteKey: "0x4AAAAAAA", PageURL: "https://example.com"}
+
+	for i := 0; i < breakerMinCalls+3; i++ {
+		result, err := chain.SolveTurnstileToken(context.Background(), req)
+		if err != nil || result.Provider != "custom" {
+			t.Fatalf("solve %d = %+v, %v, want custom", i+1, result, err)
+		}
+	}
+	if capsolverCalls != breakerMinCalls {
+		t.Errorf("capsolver called %d times, want %d: its breaker should open", capsolverCalls, breakerMinCalls)
+	}
+
+	breakers := chain.BreakerStatus()
+	if breakers["capsolver"].State != BreakerOpen || breakers["custom"].State != BreakerClosed {
+		t.Errorf("BreakerStatus() = %+v, want capsolver open and custom closed", breakers)
+	}
+	metrics := chain.GetMetrics()
+	capsolverData, _ := metrics["capsolver"].(map[string]interface{})
+	if breaker, _ := capsolverData["breaker"].(map[string]interface{}); breaker["state"] != BreakerOpen || breaker["retry_at"] == nil {
+		t.Errorf("GetMetrics()[capsolver] = %v, want an open breaker", capsolverData)
+	}
+
+	// With every provider's breaker open, the error says so
+	only := NewSolverChain(SolverChainConfig{
+		FallbackEnabled: true,
+		Breaker:         BreakerConfig{ErrorRate: 0.5, Cooldown: time.Hour},
+		Providers:       []CaptchaSolver{NewCapSolverSolver(CapSolverConfig{APIKey: "test-key", BaseURL: capsolver.URL})},
+	})
+	for i := 0; i < breakerMinCalls; i++ {
+		only.SolveTurnstileToken(context.Background(), req)
+	}
+	if _, err := only.SolveTurnstileToken(context.Background(), req); !errors.Is(err, types.ErrCaptchaCircuitOpen) {
+		t.Errorf("error = %v, want ErrCaptchaCircuitOpen", err)
+	}
+}
@@ -0,0 +1,229 @@
+// Package captcha provides external CAPTCHA solver integration.
+package captcha
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// BudgetLimits caps external solver spend in USD per UTC day and calendar
+// month. Zero leaves a period uncapped.
+type BudgetLimits struct {
+	Daily   float64
+	Monthly float64
+}
+
+// isZero reports whether neither period is capped.
+func (l BudgetLimits) isZero() bool {
+	return l.Daily <= 0 && l.Monthly <= 0
+}
+
+// budgetSpend is the spend in the current day and month.
+type budgetSpend struct {
+	daily   float64
+	monthly float64
+}
+
+// Budget enforces spend caps, deployment-wide and per provider. Spend is kept
+// in memory, so a restart starts the current periods over. A nil *Budget caps
+// nothing. It is safe for concurrent use.
+type Budget struct {
+	mu        sync.Mutex
+	global    BudgetLimits
+	providers map[string]BudgetLimits
+	spent     map[string]*budgetSpend // provider -> spend; "" is the total
+	day       string                  // UTC day of spent, "2006-01-02"
+	month     string                  // UTC month of spent, "2006-01"
+	now       func() time.Time
+}
+
+// NewBudget returns a Budget with the global and per-provider caps, or nil
+// when nothing is capped.
+func NewBudget(global BudgetLimits, providers map[string]BudgetLimits) *Budget {
+	capped := make(map[string]BudgetLimits, len(providers))
+	for name, limits := range providers {
+		if !limits.isZero() {
+			capped[name] = limits
+		}
+	}
+	if global.isZero() && len(capped) == 0 {
+		return nil
+	}
+	return &Budget{
+		global:    global,
+		providers: capped,
+		spent:     make(map[string]*budgetSpend),
+		now:       time.Now,
+	}
+}
+
+// ParseProviderBudgets parses a CAPTCHA_PROVIDER_BUDGETS value:
+// comma-separated "provider:daily=USD" or "provider:monthly=USD" entries.
+func ParseProviderBudgets(raw string) (map[string]BudgetLimits, error) {
+	budgets := make(map[string]BudgetLimits)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		name, period, hasPeriod := strings.Cut(strings.TrimSpace(key), ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || !hasPeriod || name == "" {
+			return nil, fmt.Errorf("invalid entry %q: want provider:daily=USD or provider:monthly=USD", entry)
+		}
+		amount, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || amount < 0 {
+			return nil, fmt.Errorf("invalid entry %q: amount must be a non-negative number", entry)
+		}
+		limits := budgets[name]
+		switch strings.ToLower(strings.TrimSpace(period)) {
+		case "daily":
+			limits.Daily = amount
+		case "monthly":
+			limits.Monthly = amount
+		default:
+			return nil, fmt.Errorf("invalid entry %q: period must be daily or monthly", entry)
+		}
+		budgets[name] = limits
+	}
+	return budgets, nil
+}
+
+// rollover starts new periods once the UTC day or month has changed.
+// Must be called with lock held.
+func (b *Budget) rollover() {
+	now := b.now().UTC()
+	day, month := now.Format("2006-01-02"), now.Format("2006-01")
+	if month != b.month {
+		b.spent = make(map[string]*budgetSpend)
+	} else if day != b.day {
+		for _, s := range b.spent {
+			s.daily = 0
+		}
+	}
+	b.day, b.month = day, month
+}
+
+// exceeded returns the budget error when spend has reached limits, or nil.
+func exceeded(provider string, limits BudgetLimits, spend *budgetSpend) error {
+	if spend == nil {
+		return nil
+	}
+	if limits.Daily > 0 && spend.daily >= limits.Daily {
+		return types.NewCaptchaBudgetError(provider, "daily")
+	}
+	if limits.Monthly > 0 && spend.monthly >= limits.Monthly {
+		return types.NewCaptchaBudgetError(provider, "monthly")
+	}
+	return nil
+}
+
+// Exhausted returns the budget error when the global budget is used up, or
+// nil while external solves may still be paid for.
+func (b *Budget) Exhausted() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollover()
+	return exceeded("", b.global, b.spent[""])
+}
+
+// Allow returns the budget error when provider may not be used because its
+// budget or the global one is used up, or nil.
+func (b *Budget) Allow(provider string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollover()
+	if err := exceeded("", b.global, b.spent[""]); err != nil {
+		return err
+	}
+	return exceeded(provider, b.providers[provider], b.spent[provider])
+}
+
+// Record adds cost to the spend of provider and the total.
+func (b *Budget) Record(provider string, cost float64) {
+	if b == nil || cost <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollover()
+	for _, name := range []string{"", provider} {
+		s := b.spent[name]
+		if s == nil {
+			s = &budgetSpend{}
+			b.spent[name] = s
+		}
+		s.daily += cost
+		s.monthly += cost
+	}
+}
+
+// BudgetStatus is one capped period of a budget, as reported by /health and
+// the metrics endpoint.
+type BudgetStatus struct {
+	Provider  string  `json:"provider,omitempty"` // Empty for the global budget
+	Period    string  `json:"period"`             // "daily" or "monthly"
+	Limit     float64 `json:"limitUsd"`
+	Spent     float64 `json:"spentUsd"`
+	Remaining float64 `json:"remainingUsd"`
+	ResetsAt  string  `json:"resetsAt"` // RFC 3339, start of the next period
+}
+
+// Status returns every capped period, the global budget first, then the
+// providers by name.
+func (b *Budget) Status() []BudgetStatus {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollover()
+
+	now := b.now().UTC()
+	nextDay := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	nextMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+
+	var statuses []BudgetStatus
+	add := func(provider string, limits BudgetLimits) {
+		spend := budgetSpend{}
+		if s := b.spent[provider]; s != nil {
+			spend = *s
+		}
+		if limits.Daily > 0 {
+			statuses = append(statuses, BudgetStatus{
+				Provider: provider, Period: "daily", Limit: limits.Daily, Spent: spend.daily,
+				Remaining: max(0, limits.Daily-spend.daily), ResetsAt: nextDay,
+			})
+		}
+		if limits.Monthly > 0 {
+			statuses = append(statuses, BudgetStatus{
+				Provider: provider, Period: "monthly", Limit: limits.Monthly, Spent: spend.monthly,
+				Remaining: max(0, limits.Monthly-spend.monthly), ResetsAt: nextMonth,
+			})
+		}
+	}
+
+	add("", b.global)
+	names := make([]string, 0, len(b.providers))
+	for name := range b.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add(name, b.providers[name])
+	}
+	return statuses
+}
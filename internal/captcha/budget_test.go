@@ -0,0 +1,125 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func TestParseProviderBudgets(t *testing.T) {
+	budgets, err := ParseProviderBudgets(" 2captcha:daily=5, 2captcha:monthly=100,CapSolver:monthly=40 ")
+	if err != nil {
+		t.Fatalf("ParseProviderBudgets() error = %v", err)
+	}
+	if got := budgets["2captcha"]; got.Daily != 5 || got.Monthly != 100 {
+		t.Errorf("2captcha = %+v, want daily 5, monthly 100", got)
+	}
+	if got := budgets["capsolver"]; got.Daily != 0 || got.Monthly != 40 {
+		t.Errorf("capsolver = %+v, want monthly 40", got)
+	}
+
+	for _, raw := range []string{"2captcha=5", "2captcha:weekly=5", "2captcha:daily=-1", "2captcha:daily=lots", ":daily=5"} {
+		if _, err := ParseProviderBudgets(raw); err == nil {
+			t.Errorf("ParseProviderBudgets(%q) succeeded, want an error", raw)
+		}
+	}
+}
+
+func TestNewBudget_Uncapped(t *testing.T) {
+	b := NewBudget(BudgetLimits{}, map[string]BudgetLimits{"2captcha": {}})
+	if b != nil {
+		t.Fatal("NewBudget() without caps should return nil")
+	}
+	// A nil budget allows everything
+	b.Record("2captcha", 100)
+	if err := b.Allow("2captcha"); err != nil {
+		t.Errorf("Allow() = %v, want nil", err)
+	}
+	if b.Status() != nil {
+		t.Error("Status() of a nil budget should be nil")
+	}
+}
+
+func TestBudget_Enforcement(t *testing.T) {
+	now := time.Date(2026, 3, 30, 23, 0, 0, 0, time.UTC)
+	b := NewBudget(BudgetLimits{Monthly: 10}, map[string]BudgetLimits{"2captcha": {Daily: 1}})
+	b.now = func() time.Time { return now }
+
+	b.Record("2captcha", 0.6)
+	if err := b.Allow("2captcha"); err != nil {
+		t.Fatalf("Allow() under budget = %v", err)
+	}
+	b.Record("2captcha", 0.6)
+	err := b.Allow("2captcha")
+	if !errors.Is(err, types.ErrCaptchaBudgetExceeded) {
+		t.Fatalf("Allow() over the daily budget = %v, want ErrCaptchaBudgetExceeded", err)
+	}
+	var captchaErr *types.CaptchaError
+	if !errors.As(err, &captchaErr) || captchaErr.Provider != "2captcha" || captchaErr.Code != "budget_exceeded" {
+		t.Errorf("error = %+v, want a 2captcha budget_exceeded error", err)
+	}
+	if err := b.Allow("capsolver"); err != nil {
+		t.Errorf("Allow(capsolver) = %v, want nil: only 2captcha is over budget", err)
+	}
+
+	// The next day resets the daily spend but not the monthly one
+	now = now.Add(2 * time.Hour)
+	b.Record("capsolver", 0) // rolls the period
+	if err := b.Allow("2captcha"); err != nil {
+		t.Errorf("Allow() after the day rolled = %v, want nil", err)
+	}
+
+	b.Record("capsolver", 9)
+	if err := b.Exhausted(); !errors.Is(err, types.ErrCaptchaBudgetExceeded) {
+		t.Errorf("Exhausted() = %v, want the global monthly budget exceeded", err)
+	}
+
+	statuses := b.Status()
+	if len(statuses) != 2 || statuses[0].Provider != "" || statuses[0].Period != "monthly" {
+		t.Fatalf("Status() = %+v, want global monthly then 2captcha daily", statuses)
+	}
+	if statuses[0].Remaining != 0 || statuses[0].ResetsAt != "2026-04-01T00:00:00Z" {
+		t.Errorf("global status = %+v, want nothing remaining until April", statuses[0])
+	}
+	if statuses[1].Provider != "2captcha" || statuses[1].Remaining != 1 {
+		t.Errorf("2captcha status = %+v, want the full daily budget remaining", statuses[1])
+	}
+}
+
+func TestSolverChain_Budget(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		json.NewEncoder(w).Encode(webhookResponse{Token: "token", Cost: 1})
+	}))
+	defer server.Close()
+
+	chain := NewSolverChain(SolverChainConfig{
+		FallbackEnabled: true,
+		Providers:       []CaptchaSolver{NewWebhookSolver(WebhookConfig{URL: server.URL})},
+		Budget:          NewBudget(BudgetLimits{}, map[string]BudgetLimits{"custom": {Daily: 1.5}}),
+	})
+	req := &TurnstileRequest{SiteKey: "0x4AAAAAAA", PageURL: "https://example.com"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := chain.SolveTurnstileToken(context.Background(), req); err != nil {
+			t.Fatalf("solve %d: %v", i+1, err)
+		}
+	}
+	_, err := chain.SolveTurnstileToken(context.Background(), req)
+	if !errors.Is(err, types.ErrCaptchaBudgetExceeded) {
+		t.Errorf("error = %v, want ErrCaptchaBudgetExceeded", err)
+	}
+	if calls != 2 {
+		t.Errorf("provider called %d times, want 2: the third solve is over budget", calls)
+	}
+	if status := chain.BudgetStatus(); len(status) != 1 || status[0].Spent != 2 {
+		t.Errorf("BudgetStatus() = %+v, want 2 spent", status)
+	}
+}
@@ -0,0 +1,161 @@
+// Package captcha provides external CAPTCHA solver integration.
+package captcha
+
+import (
+	"context"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+const (
+	capMonsterBaseURL = "https://api.capmonster.cloud"
+
+	// CapMonster Cloud solves most tasks within seconds; poll every 2s
+	capMonsterPollInterval = 2 * time.Second
+
+	capMonsterDefaultTimeout = 120 * time.Second
+
+	// capMonsterMinScore is the reCAPTCHA v3 score requested (0.1-0.9).
+	capMonsterMinScore = 0.7
+)
+
+// CapMonsterSolver implements CaptchaSolver for the CapMonster Cloud task API.
+// Cloudflare managed challenges are sent as Turnstile tasks of the "token"
+// Cloudflare task type, with the render() parameters and user agent the token
+// must be presented with.
+type CapMonsterSolver struct {
+	client *taskClient
+}
+
+func init() {
+	Register("capmonster", func(apiKey string, timeout time.Duration) CaptchaSolver {
+		return NewCapMonsterSolver(CapMonsterConfig{APIKey: apiKey, Timeout: timeout})
+	})
+}
+
+// CapMonsterConfig contains configuration for CapMonster Cloud solver.
+type CapMonsterConfig struct {
+	APIKey  string
+	Timeout time.Duration
+	BaseURL string // Override for testing
+}
+
+// NewCapMonsterSolver creates a new CapMonster Cloud solver instance.
+func NewCapMonsterSolver(cfg CapMonsterConfig) *CapMonsterSolver {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = capMonsterDefaultTimeout
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = capMonsterBaseURL
+	}
+
+	return &CapMonsterSolver{client: newTaskClient(taskClient{
+		provider:     "capmonster",
+		label:        "CapMonster",
+		apiKey:       cfg.APIKey,
+		baseURL:      baseURL,
+		timeout:      timeout,
+		pollInterval: capMonsterPollInterval,
+		rejection:    capMonsterRejection,
+	})}
+}
+
+// Name returns the provider name.
+func (s *CapMonsterSolver) Name() string {
+	return "capmonster"
+}
+
+// IsConfigured returns true if API key is set.
+func (s *CapMonsterSolver) IsConfigured() bool {
+	return s.client.configured()
+}
+
+// SolveTurnstile solves a Turnstile challenge using the CapMonster Cloud API.
+// With chlPageData captured from a managed challenge the task is of the
+// "token" Cloudflare type; otherwise a plain widget task.
+func (s *CapMonsterSolver) SolveTurnstile(ctx context.Context, req *TurnstileRequest) (*TurnstileResult, error) {
+	task := apiTask{
+		Type:       "TurnstileTask",
+		WebsiteURL: req.PageURL,
+		WebsiteKey: req.SiteKey,
+		PageAction: req.Action,
+		Data:       req.CData,
+	}
+	if req.PageData != "" {
+		task.CloudflareTaskType = "token"
+		task.PageData = req.PageData
+		task.UserAgent = req.UserAgent
+	}
+	return s.client.solveTask(ctx, "Turnstile", task, 0.0013) // CapMonster Turnstile pricing ~$1.30 per 1000
+}
+
+// SolveHCaptcha is not supported: CapMonster Cloud no longer solves hCaptcha.
+// Returning a rejected error lets SolverChain fall through to the next
+// provider.
+func (s *CapMonsterSolver) SolveHCaptcha(_ context.Context, _ *HCaptchaRequest) (*CaptchaResult, error) {
+	return nil, types.NewCaptchaRejectedError(s.Name(), "UNSUPPORTED", "CapMonster Cloud does not support hCaptcha")
+}
+
+// SolveRecaptcha solves a reCAPTCHA v2 or v3 challenge using the CapMonster
+// Cloud API.
+func (s *CapMonsterSolver) SolveRecaptcha(ctx context.Context, req *RecaptchaRequest) (*CaptchaResult, error) {
+	if req.V3 {
+		return s.client.solveTask(ctx, "reCAPTCHA", apiTask{
+			Type:       "RecaptchaV3TaskProxyless",
+			WebsiteURL: req.PageURL,
+			WebsiteKey: req.SiteKey,
+			MinScore:   capMonsterMinScore,
+			PageAction: req.Action,
+		}, 0.0009) // CapMonster reCAPTCHA v3 pricing ~$0.90 per 1000
+	}
+	return s.client.solveTask(ctx, "reCAPTCHA", apiTask{
+		Type:        "RecaptchaV2Task",
+		WebsiteURL:  req.PageURL,
+		WebsiteKey:  req.SiteKey,
+		IsInvisible: req.Invisible,
+		UserAgent:   req.UserAgent,
+	}, 0.0006) // CapMonster reCAPTCHA v2 pricing ~$0.60 per 1000
+}
+
+// SolveDataDome is not supported: CapMonster's DataDome task needs the
+// blocked response's datadome cookie, which is not captured. Returning a
+// rejected error lets SolverChain fall through to the next provider.
+func (s *CapMonsterSolver) SolveDataDome(_ context.Context, _ *DataDomeRequest) (*CaptchaResult, error) {
+	return nil, types.NewCaptchaRejectedError(s.Name(), "UNSUPPORTED", "CapMonster Cloud DataDome solving is not supported")
+}
+
+// SolveImage reads an image captcha using the CapMonster Cloud API.
+func (s *CapMonsterSolver) SolveImage(ctx context.Context, req *ImageRequest) (*CaptchaResult, error) {
+	return s.client.solveTask(ctx, "image", apiTask{
+		Type:       "ImageToTextTask",
+		WebsiteURL: req.PageURL,
+		Body:       req.body(),
+	}, 0.0003) // CapMonster image pricing ~$0.30 per 1000
+}
+
+// Balance retrieves the current account balance.
+func (s *CapMonsterSolver) Balance(ctx context.Context) (float64, error) {
+	return s.client.balance(ctx)
+}
+
+// capMonsterRejection explains the CapMonster Cloud error codes that reject a
+// task.
+func capMonsterRejection(code string) string {
+	switch code {
+	case "ERROR_KEY_DOES_NOT_EXIST", "ERROR_IP_NOT_ALLOWED", "ERROR_IP_BANNED":
+		return "invalid API key or client IP not allowed"
+	case "ERROR_CAPTCHA_UNSOLVABLE", "ERROR_MAXIMUM_TIME_EXCEED":
+		return "captcha could not be solved"
+	case "ERROR_RECAPTCHA_INVALID_SITEKEY", "ERROR_RECAPTCHA_INVALID_DOMAIN", "ERROR_DOMAIN_NOT_ALLOWED", "ERROR_TASK_NOT_SUPPORTED", "ERROR_INCORRECT_SESSION_DATA":
+		return "invalid sitekey, domain or task data"
+	case "ERROR_NO_SUCH_CAPCHA_ID", "WRONG_CAPTCHA_ID", "ERROR_TOKEN_EXPIRED":
+		return "task not found or expired"
+	case "ERROR_PROXY_CONNECT_REFUSED", "ERROR_PROXY_BANNED", "ERROR_PROXY_TIMEOUT":
+		return "CapMonster could not use the proxy"
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// newTestCapMonster returns a solver against the fake task API.
+func newTestCapMonster(t *testing.T, created *taskCreateRequest, result taskResultResponse) *CapMonsterSolver {
+	t.Helper()
+	solver := NewCapMonsterSolver(CapMonsterConfig{APIKey: "test-key", BaseURL: newTestTaskAPI(t, created, result)})
+	solver.client.pollInterval = testPollInterval
+	return solver
+}
+
+func TestCapMonsterSolver_Registered(t *testing.T) {
+	factory := GetFactory("capmonster")
+	if factory == nil {
+		t.Fatal("capmonster is not registered")
+	}
+	if got := factory("key", time.Minute).Name(); got != "capmonster" {
+		t.Errorf("Name() = %q, want capmonster", got)
+	}
+}
+
+func TestCapMonsterSolver_SolveTurnstile(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          TurnstileRequest
+		wantTaskType string
+	}{
+		{
+			name:         "widget",
+			req:          TurnstileRequest{SiteKey: "0x4AAAAAAA", PageURL: "https://example.com", Action: "login"},
+			wantTaskType: "",
+		},
+		{
+			name: "managed challenge",
+			req: TurnstileRequest{
+				SiteKey: "0x4AAAAAAA", PageURL: "https://example.com", UserAgent: "Mozilla/5.0",
+				Action: "managed", CData: "cdata", PageData: "chl-page-data",
+			},
+			wantTaskType: "token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created taskCreateRequest
+			solver := newTestCapMonster(t, &created, taskResultResponse{
+				Status:   "ready",
+				Solution: &taskSolution{Token: "capmonster-token"},
+			})
+
+			result, err := solver.SolveTurnstile(context.Background(), &tt.req)
+			if err != nil {
+				t.Fatalf("SolveTurnstile() error = %v", err)
+			}
+			if result.Token != "capmonster-token" || result.Provider != "capmonster" {
+				t.Errorf("result = %+v, want capmonster-token from capmonster", result)
+			}
+			if result.Cost != 0.0013 {
+				t.Errorf("Cost = %v, want 0.0013", result.Cost)
+			}
+
+			task := created.Task
+			if task.Type != "TurnstileTask" || task.CloudflareTaskType != tt.wantTaskType {
+				t.Errorf("task type = %s/%q, want TurnstileTask/%q", task.Type, task.CloudflareTaskType, tt.wantTaskType)
+			}
+			if task.PageAction != tt.req.Action || task.Data != tt.req.CData || task.PageData != tt.req.PageData {
+				t.Errorf("task = %+v, want the request's render() parameters", task)
+			}
+			if tt.wantTaskType == "token" && task.UserAgent != tt.req.UserAgent {
+				t.Errorf("UserAgent = %q, want %q", task.UserAgent, tt.req.UserAgent)
+			}
+		})
+	}
+}
+
+func TestCapMonsterSolver_SolveRecaptchaV2(t *testing.T) {
+	var created taskCreateRequest
+	solver := newTestCapMonster(t, &created, taskResultResponse{
+		Status:   "ready",
+		Solution: &taskSolution{GRecaptchaResponse: "recaptcha-token"},
+	})
+
+	result, err := solver.SolveRecaptcha(context.Background(), &RecaptchaRequest{
+		SiteKey:   "6Lc-key",
+		PageURL:   "https://example.com",
+		Invisible: true,
+	})
+	if err != nil {
+		t.Fatalf("SolveRecaptcha() error = %v", err)
+	}
+	if result.Token != "recaptcha-token" {
+		t.Errorf("Token = %q, want recaptcha-token", result.Token)
+	}
+	if task := created.Task; task.Type != "RecaptchaV2Task" || !task.IsInvisible {
+		t.Errorf("task = %+v, want an invisible RecaptchaV2Task", task)
+	}
+}
+
+func TestCapMonsterSolver_Unsupported(t *testing.T) {
+	solver := NewCapMonsterSolver(CapMonsterConfig{APIKey: "test-key"})
+
+	if _, err := solver.SolveHCaptcha(context.Background(), &HCaptchaRequest{}); !errors.Is(err, types.ErrCaptchaSolverRejected) {
+		t.Errorf("SolveHCaptcha() error = %v, want rejected", err)
+	}
+	if _, err := solver.SolveDataDome(context.Background(), &DataDomeRequest{}); !errors.Is(err, types.ErrCaptchaSolverRejected) {
+		t.Errorf("SolveDataDome() error = %v, want rejected", err)
+	}
+}
+
+func TestCapMonsterRejection(t *testing.T) {
+	for _, code := range []string{"ERROR_KEY_DOES_NOT_EXIST", "ERROR_CAPTCHA_UNSOLVABLE", "ERROR_DOMAIN_NOT_ALLOWED", "ERROR_TOKEN_EXPIRED", "ERROR_PROXY_BANNED"} {
+		if capMonsterRejection(code) == "" {
+			t.Errorf("capMonsterRejection(%q) is empty, want a reason", code)
+		}
+	}
+	if reason := capMonsterRejection("ERROR_SOMETHING_NEW"); reason != "" {
+		t.Errorf("capMonsterRejection() = %q for an unknown code, want empty", reason)
+	}
+}
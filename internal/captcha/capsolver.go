@@ -94,6 +94,18 @@ type capSolverTurnstileTask struct {
 	WebsiteURL string             `json:"websiteURL"`
 	WebsiteKey string             `json:"websiteKey"`
 	Metadata   *capSolverMetadata `json:"metadata,omitempty"`
+
+	// reCAPTCHA only
+	IsInvisible bool   `json:"isInvisible,omitempty"`
+	PageAction  string `json:"pageAction,omitempty"`
+
+	// DataDome only
+	CaptchaURL string `json:"captchaUrl,omitempty"`
+	UserAgent  string `json:"userAgent,omitempty"`
+	Proxy      string `json:"proxy,omitempty"`
+
+	// ImageToText only
+	Body string `json:"body,omitempty"`
 }
 
 // capSolverMetadata contains optional metadata for Turnstile.
@@ -108,6 +120,10 @@ type capSolverCreateTaskResponse struct {
 	ErrorCode        string `json:"errorCode,omitempty"`
 	ErrorDescription string `json:"errorDescription,omitempty"`
 	TaskID           string `json:"taskId,omitempty"`
+
+	// Set when the task finishes synchronously, as ImageToText tasks do
+	Status   string                      `json:"status,omitempty"`
+	Solution *capSolverTurnstileSolution `json:"solution,omitempty"`
 }
 
 // capSolverGetResultRequest is the request body for getTaskResult.
@@ -125,9 +141,28 @@ type capSolverGetResultResponse struct {
 	Solution         *capSolverTurnstileSolution `json:"solution,omitempty"`
 }
 
-// capSolverTurnstileSolution contains the Turnstile solution.
+// capSolverTurnstileSolution contains the Turnstile solution. reCAPTCHA and
+// hCaptcha solutions carry gRecaptchaResponse instead of token, DataDome
+// solutions carry cookie, and image solutions carry text.
 type capSolverTurnstileSolution struct {
-	Token string `json:"token"`
+	Token              string `json:"token"`
+	GRecaptchaResponse string `json:"gRecaptchaResponse,omitempty"`
+	Cookie             string `json:"cookie,omitempty"`
+	Text               string `json:"text,omitempty"`
+}
+
+// token returns the solution token from whichever field carries it.
+func (sol *capSolverTurnstileSolution) token() string {
+	if sol.Token != "" {
+		return sol.Token
+	}
+	if sol.GRecaptchaResponse != "" {
+		return sol.GRecaptchaResponse
+	}
+	if sol.Cookie != "" {
+		return sol.Cookie
+	}
+	return sol.Text
 }
 
 // capSolverBalanceResponse is the response from getBalance.
@@ -152,7 +187,7 @@ func (s *CapSolverSolver) SolveTurnstile(ctx context.Context, req *TurnstileRequ
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("task_id", taskID).
 		Str("sitekey", req.SiteKey[:min(10, len(req.SiteKey))]+"...").
 		Msg("CapSolver task created")
@@ -169,7 +204,7 @@ func (s *CapSolverSolver) SolveTurnstile(ctx context.Context, req *TurnstileRequ
 	estimatedCost := 0.0025
 
 	return &TurnstileResult{
-		Token:     result.Solution.Token,
+		Token:     result.Solution.token(),
 		SolveTime: solveTime,
 		Cost:      estimatedCost,
 		Provider:  s.Name(),
@@ -178,20 +213,70 @@ func (s *CapSolverSolver) SolveTurnstile(ctx context.Context, req *TurnstileRequ
 
 // SolveHCaptcha solves an hCaptcha challenge using the CapSolver API.
 func (s *CapSolverSolver) SolveHCaptcha(ctx context.Context, req *HCaptchaRequest) (*CaptchaResult, error) {
+	return s.solveTask(ctx, "hCaptcha", capSolverTurnstileTask{
+		Type:       "HCaptchaTaskProxyLess",
+		WebsiteURL: req.PageURL,
+		WebsiteKey: req.SiteKey,
+	}, 0.003) // CapSolver hCaptcha pricing ~$3.00 per 1000
+}
+
+// SolveRecaptcha solves a reCAPTCHA v2 or v3 challenge using the CapSolver API.
+func (s *CapSolverSolver) SolveRecaptcha(ctx context.Context, req *RecaptchaRequest) (*CaptchaResult, error) {
+	task := capSolverTurnstileTask{
+		Type:        "ReCaptchaV2TaskProxyLess",
+		WebsiteURL:  req.PageURL,
+		WebsiteKey:  req.SiteKey,
+		IsInvisible: req.Invisible,
+	}
+	if req.V3 {
+		task = capSolverTurnstileTask{
+			Type:       "ReCaptchaV3TaskProxyLess",
+			WebsiteURL: req.PageURL,
+			WebsiteKey: req.SiteKey,
+			PageAction: req.Action,
+		}
+	}
+	return s.solveTask(ctx, "reCAPTCHA", task, 0.001) // CapSolver reCAPTCHA pricing ~$1.00 per 1000
+}
+
+// SolveDataDome solves a DataDome slider captcha using the CapSolver API. The
+// task runs through the browser's proxy, since the cookie is IP-bound.
+func (s *CapSolverSolver) SolveDataDome(ctx context.Context, req *DataDomeRequest) (*CaptchaResult, error) {
+	proxy, err := parseTaskProxy(req.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	return s.solveTask(ctx, "DataDome", capSolverTurnstileTask{
+		Type:       "DatadomeSliderTask",
+		WebsiteURL: req.PageURL,
+		CaptchaURL: req.CaptchaURL,
+		UserAgent:  req.UserAgent,
+		Proxy:      proxy.String(),
+	}, 0.0025) // CapSolver DataDome pricing ~$2.50 per 1000
+}
+
+// SolveImage reads an image captcha using the CapSolver API. ImageToText
+// tasks are answered in the createTask response, without polling.
+func (s *CapSolverSolver) SolveImage(ctx context.Context, req *ImageRequest) (*CaptchaResult, error) {
+	return s.solveTask(ctx, "image", capSolverTurnstileTask{
+		Type:       "ImageToTextTask",
+		WebsiteURL: req.PageURL,
+		Body:       req.body(),
+	}, 0.0004) // CapSolver ImageToText pricing ~$0.40 per 1000
+}
+
+// solveTask creates a task of any type and polls it to completion.
+// estimatedCost is reported as the cost, since CapSolver does not return one.
+func (s *CapSolverSolver) solveTask(ctx context.Context, kind string, task capSolverTurnstileTask, estimatedCost float64) (*CaptchaResult, error) {
 	if !s.IsConfigured() {
 		return nil, fmt.Errorf("capsolver API key not configured")
 	}
 
 	startTime := time.Now()
 
-	// Create hCaptcha task
 	taskReq := capSolverCreateTaskRequest{
 		ClientKey: s.apiKey,
-		Task: capSolverTurnstileTask{
-			Type:       "HCaptchaTaskProxyLess",
-			WebsiteURL: req.PageURL,
-			WebsiteKey: req.SiteKey,
-		},
+		Task:      task,
 	}
 
 	body, err := json.Marshal(taskReq)
@@ -225,10 +310,19 @@ func (s *CapSolverSolver) SolveHCaptcha(ctx context.Context, req *HCaptchaReques
 		return nil, s.handleError(taskResp.ErrorCode, taskResp.ErrorDescription, "")
 	}
 
-	log.Debug().
+	if taskResp.Status == "ready" && taskResp.Solution != nil && taskResp.Solution.token() != "" {
+		return &CaptchaResult{
+			Token:     taskResp.Solution.token(),
+			SolveTime: time.Since(startTime),
+			Cost:      estimatedCost,
+			Provider:  s.Name(),
+		}, nil
+	}
+
+	log.Ctx(ctx).Debug().
 		Str("task_id", taskResp.TaskID).
-		Str("sitekey", req.SiteKey[:min(10, len(req.SiteKey))]+"...").
-		Msg("CapSolver hCaptcha task created")
+		Str("sitekey", task.WebsiteKey[:min(10, len(task.WebsiteKey))]+"...").
+		Msg("CapSolver " + kind + " task created")
 
 	// Poll for result (reuses same polling infrastructure)
 	result, err := s.pollResult(ctx, taskResp.TaskID)
@@ -236,12 +330,9 @@ func (s *CapSolverSolver) SolveHCaptcha(ctx context.Context, req *HCaptchaReques
 		return nil, err
 	}
 
-	solveTime := time.Since(startTime)
-	estimatedCost := 0.003 // CapSolver hCaptcha pricing ~$3.00 per 1000
-
 	return &CaptchaResult{
-		Token:     result.Solution.Token,
-		SolveTime: solveTime,
+		Token:     result.Solution.token(),
+		SolveTime: time.Since(startTime),
 		Cost:      estimatedCost,
 		Provider:  s.Name(),
 	}, nil
@@ -330,14 +421,14 @@ func (s *CapSolverSolver) pollResult(ctx context.Context, taskID string) (*capSo
 
 			switch result.Status {
 			case "ready":
-				if result.Solution == nil || result.Solution.Token == "" {
+				if result.Solution == nil || result.Solution.token() == "" {
 					return nil, fmt.Errorf("received ready status but no token")
 				}
 				return result, nil
 			case "failed":
 				return nil, types.NewCaptchaRejectedError(s.Name(), "failed", "task failed")
 			default:
-				log.Debug().
+				log.Ctx(ctx).Debug().
 					Str("task_id", taskID).
 					Str("status", result.Status).
 					Msg("CapSolver task still processing")
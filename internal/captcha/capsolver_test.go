@@ -106,6 +106,57 @@ func TestCapSolverSolver_SolveTurnstile_Success(t *testing.T) {
 	}
 }
 
+// TestCapSolverSolver_SolveDataDome verifies the DataDome task carries the
+// proxy and that the cookie is returned as the token
+func TestCapSolverSolver_SolveDataDome(t *testing.T) {
+	var task map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/createTask":
+			var body struct {
+				Task map[string]interface{} `json:"task"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			task = body.Task
+			json.NewEncoder(w).Encode(capSolverCreateTaskResponse{TaskID: "dd-1"})
+		case "/getTaskResult":
+			json.NewEncoder(w).Encode(capSolverGetResultResponse{
+				Status:   "ready",
+				Solution: &capSolverTurnstileSolution{Cookie: "datadome=abc; Path=/"},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	solver := NewCapSolverSolver(CapSolverConfig{
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+		Timeout: 10 * time.Second,
+	})
+
+	result, err := solver.SolveDataDome(context.Background(), &DataDomeRequest{
+		CaptchaURL: "https://geo.captcha-delivery.com/captcha/?initialCid=x&t=fe",
+		PageURL:    "https://example.com/",
+		UserAgent:  "TestAgent/1.0",
+		Proxy:      &types.Proxy{URL: "http://10.0.0.1:8080", Username: "u", Password: "p"},
+	})
+	if err != nil {
+		t.Fatalf("SolveDataDome() error = %v", err)
+	}
+	if result.Token != "datadome=abc; Path=/" {
+		t.Errorf("Token = %q", result.Token)
+	}
+	if task["type"] != "DatadomeSliderTask" || task["proxy"] != "http:10.0.0.1:8080:u:p" || task["userAgent"] != "TestAgent/1.0" {
+		t.Errorf("unexpected task: %v", task)
+	}
+
+	if _, err := solver.SolveDataDome(context.Background(), &DataDomeRequest{PageURL: "https://example.com/"}); err == nil {
+		t.Error("Expected an error without a proxy")
+	}
+}
+
 func TestCapSolverSolver_SolveTurnstile_WithMetadata(t *testing.T) {
 	var receivedTask capSolverTurnstileTask
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,177 @@
+// Package captcha provides external CAPTCHA solver integration.
+package captcha
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// DataDomeCookie is the clearance cookie DataDome sets once a visitor passes.
+const DataDomeCookie = "datadome"
+
+// DataDomeRequest contains the parameters needed to solve a DataDome slider.
+// DataDome binds its cookie to the visitor's IP and user agent, so providers
+// must solve through the same proxy the browser uses.
+type DataDomeRequest struct {
+	CaptchaURL string       // src of the geo.captcha-delivery.com/captcha/ iframe
+	PageURL    string       // The URL of the page showing the captcha
+	UserAgent  string       // The browser's user agent
+	Proxy      *types.Proxy // The proxy the browser is behind
+}
+
+// ExtractDataDomeCaptchaURL returns the src of the DataDome captcha iframe.
+func ExtractDataDomeCaptchaURL(page *rod.Page) (string, error) {
+	js := `
+	(function() {
+		var iframes = document.querySelectorAll('iframe[src*="captcha-delivery.com"]');
+		for (var i = 0; i < iframes.length; i++) {
+			var src = iframes[i].src || '';
+			if (src.indexOf('/captcha/') !== -1) {
+				return src;
+			}
+		}
+		return '';
+	})()
+	`
+
+	result, err := proto.RuntimeEvaluate{
+		Expression:    js,
+		ReturnByValue: true,
+	}.Call(page)
+	if err != nil {
+		return "", fmt.Errorf("DataDome js evaluation failed: %w", err)
+	}
+	if result == nil || result.Result == nil {
+		return "", fmt.Errorf("empty result from DataDome js evaluation")
+	}
+
+	captchaURL := result.Result.Value.Str()
+	if captchaURL == "" {
+		return "", fmt.Errorf("no DataDome captcha iframe found")
+	}
+	return captchaURL, nil
+}
+
+// IsDataDomeBanned reports whether a DataDome captcha URL marks the visitor
+// as blocked (t=bv). No solve can pass it; only a different IP can.
+func IsDataDomeBanned(captchaURL string) bool {
+	u, err := url.Parse(captchaURL)
+	if err != nil {
+		return false
+	}
+	return u.Query().Get("t") == "bv"
+}
+
+// InjectDataDomeCookie sets the datadome cookie from a provider's Set-Cookie
+// style solution ("datadome=...; Domain=...; Path=/; ...") on the page.
+func InjectDataDomeCookie(ctx context.Context, page *rod.Page, setCookie, pageURL string) error {
+	cookie, err := http.ParseSetCookie(strings.TrimSpace(setCookie))
+	if err != nil {
+		return fmt.Errorf("invalid DataDome cookie: %w", err)
+	}
+	if cookie.Name != DataDomeCookie || cookie.Value == "" {
+		return fmt.Errorf("unexpected DataDome cookie %q", cookie.Name)
+	}
+
+	param := &proto.NetworkCookieParam{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Domain:   cookie.Domain,
+		Path:     cookie.Path,
+		Secure:   cookie.Secure,
+		HTTPOnly: cookie.HttpOnly,
+	}
+	if param.Domain == "" {
+		param.URL = pageURL
+	}
+	if param.Path == "" {
+		param.Path = "/"
+	}
+	switch cookie.SameSite {
+	case http.SameSiteLaxMode:
+		param.SameSite = proto.NetworkCookieSameSiteLax
+	case http.SameSiteStrictMode:
+		param.SameSite = proto.NetworkCookieSameSiteStrict
+	case http.SameSiteNoneMode:
+		param.SameSite = proto.NetworkCookieSameSiteNone
+	}
+	if cookie.MaxAge > 0 {
+		param.Expires = proto.TimeSinceEpoch(time.Now().Add(time.Duration(cookie.MaxAge) * time.Second).Unix())
+	} else if !cookie.Expires.IsZero() {
+		param.Expires = proto.TimeSinceEpoch(cookie.Expires.Unix())
+	}
+
+	if err := page.Context(ctx).SetCookies([]*proto.NetworkCookieParam{param}); err != nil {
+		return fmt.Errorf("failed to set DataDome cookie: %w", err)
+	}
+	return nil
+}
+
+// SolveDataDome solves a DataDome slider captcha using external providers
+// and sets the resulting datadome cookie on the page. The caller reloads the
+// page to present it. It follows the same fallback pattern as Solve.
+func (c *SolverChain) SolveDataDome(ctx context.Context, page *rod.Page, pageURL, userAgent string, proxy *types.Proxy) (*SolveResult, error) {
+	if !c.enabled {
+		return nil, fmt.Errorf("external CAPTCHA solving is not enabled")
+	}
+	if err := c.budget.Exhausted(); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	captchaURL, err := ExtractDataDomeCaptchaURL(page)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("Failed to extract DataDome captcha URL")
+		return nil, fmt.Errorf("failed to extract DataDome captcha URL: %w", err)
+	}
+	if IsDataDomeBanned(captchaURL) {
+		return nil, fmt.Errorf("DataDome has blocked this IP; retry through another proxy")
+	}
+	if proxy == nil || proxy.URL == "" {
+		return nil, fmt.Errorf("DataDome solving requires a per-request proxy")
+	}
+
+	req := &DataDomeRequest{
+		CaptchaURL: captchaURL,
+		PageURL:    pageURL,
+		UserAgent:  userAgent,
+		Proxy:      proxy,
+	}
+
+	log.Ctx(ctx).Info().
+		Str("url", pageURL).
+		Msg("Attempting external DataDome solve")
+
+	result, provider, err := c.tryProviders(ctx, "datadome", func(ctx context.Context, provider CaptchaSolver) (*CaptchaResult, error) {
+		return provider.SolveDataDome(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	injected := false
+	if err := InjectDataDomeCookie(ctx, page, result.Token, pageURL); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to set DataDome cookie")
+	} else {
+		injected = true
+	}
+
+	return &SolveResult{
+		Token:     result.Token,
+		Provider:  provider.Name(),
+		SolveTime: time.Since(startTime),
+		Cost:      result.Cost,
+		Injected:  injected,
+	}, nil
+}
@@ -0,0 +1,12 @@
+package captcha
+
+import "testing"
+
+func TestIsDataDomeBanned(t *testing.T) {
+	if IsDataDomeBanned("https://geo.captcha-delivery.com/captcha/?initialCid=x&cid=y&t=fe") {
+		t.Error("t=fe is a solvable captcha")
+	}
+	if !IsDataDomeBanned("https://geo.captcha-delivery.com/captcha/?initialCid=x&cid=y&t=bv") {
+		t.Error("t=bv is a ban")
+	}
+}
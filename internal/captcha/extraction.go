@@ -2,7 +2,11 @@
 package captcha
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
@@ -479,6 +483,16 @@ func ExtractHCaptchaSitekey(page *rod.Page) (string, error) {
 			}
 		}
 
+		// Any other data-sitekey holding an hCaptcha (UUID) key. Turnstile
+		// keys start with "0x" and reCAPTCHA keys are not UUIDs.
+		var keyed = document.querySelectorAll('[data-sitekey]');
+		for (var i = 0; i < keyed.length; i++) {
+			var key = keyed[i].getAttribute('data-sitekey') || '';
+			if (/^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$/i.test(key)) {
+				return key;
+			}
+		}
+
 		return '';
 	})()
 	`
@@ -507,3 +521,125 @@ func ExtractHCaptchaSitekey(page *rod.Page) (string, error) {
 
 	return sitekey, nil
 }
+
+// recaptchaSitekeyPattern matches reCAPTCHA site keys, which are 40
+// characters starting with "6L" (hCaptcha keys are UUIDs, Turnstile keys
+// start with "0x").
+var recaptchaSitekeyPattern = regexp.MustCompile(`^6L[0-9A-Za-z_-]{38}$`)
+
+// recaptchaPageScan is what the page-side scan reports; resolveRecaptchaParams
+// turns it into a solve request.
+type recaptchaPageScan struct {
+	WidgetKey    string   `json:"widgetKey"`    // data-sitekey of a v2 widget
+	WidgetSize   string   `json:"widgetSize"`   // its data-size ("invisible" for invisible v2)
+	WidgetAction string   `json:"widgetAction"` // its data-action
+	AnchorSrcs   []string `json:"anchorSrcs"`   // recaptcha anchor iframe URLs
+	ScriptSrcs   []string `json:"scriptSrcs"`   // api.js / enterprise.js URLs
+	ExecAction   string   `json:"execAction"`   // action passed to grecaptcha.execute in inline scripts
+}
+
+// ExtractRecaptchaParams reads the reCAPTCHA sitekey, version, invisibility
+// and action from a page. PageURL and UserAgent are left for the caller.
+func ExtractRecaptchaParams(page *rod.Page) (*RecaptchaRequest, error) {
+	js := `
+	(function() {
+		var out = {widgetKey: '', widgetSize: '', widgetAction: '', anchorSrcs: [], scriptSrcs: [], execAction: ''};
+
+		var widgets = document.querySelectorAll('.g-recaptcha[data-sitekey], [data-sitekey]');
+		for (var i = 0; i < widgets.length; i++) {
+			var key = widgets[i].getAttribute('data-sitekey') || '';
+			if (/^6L[0-9A-Za-z_-]{38}$/.test(key)) {
+				out.widgetKey = key;
+				out.widgetSize = widgets[i].getAttribute('data-size') || '';
+				out.widgetAction = widgets[i].getAttribute('data-action') || '';
+				break;
+			}
+		}
+
+		var iframes = document.querySelectorAll('iframe[src*="/recaptcha/"]');
+		for (var i = 0; i < iframes.length; i++) {
+			if (iframes[i].src.indexOf('/anchor') !== -1) {
+				out.anchorSrcs.push(iframes[i].src);
+			}
+		}
+
+		var scripts = document.querySelectorAll('script');
+		for (var i = 0; i < scripts.length; i++) {
+			var src = scripts[i].src || '';
+			if (src.indexOf('/recaptcha/') !== -1) {
+				out.scriptSrcs.push(src);
+				continue;
+			}
+			var m = (scripts[i].textContent || '').match(/grecaptcha(?:\.enterprise)?\.execute\([^)]*action['":\s]+['"]([A-Za-z0-9_/]+)['"]/);
+			if (m && !out.execAction) {
+				out.execAction = m[1];
+			}
+		}
+
+		return JSON.stringify(out);
+	})()
+	`
+
+	result, err := proto.RuntimeEvaluate{
+		Expression:    js,
+		ReturnByValue: true,
+	}.Call(page)
+
+	if err != nil {
+		return nil, fmt.Errorf("reCAPTCHA js evaluation failed: %w", err)
+	}
+
+	if result == nil || result.Result == nil {
+		return nil, fmt.Errorf("empty result from reCAPTCHA js evaluation")
+	}
+
+	if result.ExceptionDetails != nil {
+		return nil, fmt.Errorf("js exception: %s", result.ExceptionDetails.Text)
+	}
+
+	var scan recaptchaPageScan
+	if err := json.Unmarshal([]byte(result.Result.Value.Str()), &scan); err != nil {
+		return nil, fmt.Errorf("failed to parse reCAPTCHA scan: %w", err)
+	}
+	return resolveRecaptchaParams(&scan)
+}
+
+// resolveRecaptchaParams picks the sitekey and version from a page scan. A
+// v2 widget wins; otherwise api.js?render=<sitekey> marks v3, and an anchor
+// iframe alone is treated as v2 (invisible when size=invisible).
+func resolveRecaptchaParams(scan *recaptchaPageScan) (*RecaptchaRequest, error) {
+	if scan.WidgetKey != "" {
+		return &RecaptchaRequest{
+			SiteKey:   scan.WidgetKey,
+			Invisible: scan.WidgetSize == "invisible",
+			Action:    scan.WidgetAction,
+		}, nil
+	}
+
+	for _, src := range scan.ScriptSrcs {
+		if key := recaptchaURLParam(src, "render"); recaptchaSitekeyPattern.MatchString(key) {
+			return &RecaptchaRequest{SiteKey: key, V3: true, Action: scan.ExecAction}, nil
+		}
+	}
+
+	for _, src := range scan.AnchorSrcs {
+		if key := recaptchaURLParam(src, "k"); recaptchaSitekeyPattern.MatchString(key) {
+			return &RecaptchaRequest{
+				SiteKey:   key,
+				Invisible: recaptchaURLParam(src, "size") == "invisible",
+			}, nil
+		}
+	}
+
+	return nil, types.ErrCaptchaSitekeyNotFound
+}
+
+// recaptchaURLParam returns a query parameter of a reCAPTCHA script or
+// iframe URL, or "".
+func recaptchaURLParam(rawURL, name string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(u.Query().Get(name))
+}
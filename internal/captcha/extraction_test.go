@@ -89,6 +89,57 @@ func TestExtractSitekeyFromURL(t *testing.T) {
 	}
 }
 
+func TestResolveRecaptchaParams(t *testing.T) {
+	const key = "6LeIxAcTAAAAAJcZVRqyHh71UMIEGNQ_MXjiZKhI"
+	tests := []struct {
+		name    string
+		scan    recaptchaPageScan
+		want    RecaptchaRequest
+		wantErr bool
+	}{
+		{
+			name: "v2 checkbox widget",
+			scan: recaptchaPageScan{WidgetKey: key},
+			want: RecaptchaRequest{SiteKey: key},
+		},
+		{
+			name: "v2 invisible widget",
+			scan: recaptchaPageScan{WidgetKey: key, WidgetSize: "invisible", WidgetAction: "submit"},
+			want: RecaptchaRequest{SiteKey: key, Invisible: true, Action: "submit"},
+		},
+		{
+			name: "v3 render key",
+			scan: recaptchaPageScan{ScriptSrcs: []string{"https://www.google.com/recaptcha/api.js?render=" + key}, ExecAction: "login"},
+			want: RecaptchaRequest{SiteKey: key, V3: true, Action: "login"},
+		},
+		{
+			name: "explicit render is not v3",
+			scan: recaptchaPageScan{
+				ScriptSrcs: []string{"https://www.google.com/recaptcha/api.js?render=explicit"},
+				AnchorSrcs: []string{"https://www.google.com/recaptcha/api2/anchor?ar=1&k=" + key + "&size=invisible"},
+			},
+			want: RecaptchaRequest{SiteKey: key, Invisible: true},
+		},
+		{
+			name:    "no sitekey",
+			scan:    recaptchaPageScan{ScriptSrcs: []string{"https://www.google.com/recaptcha/api.js"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveRecaptchaParams(&tt.scan)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveRecaptchaParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && *got != tt.want {
+				t.Errorf("resolveRecaptchaParams() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
 func TestContainsSubstring(t *testing.T) {
 	tests := []struct {
 		s      string
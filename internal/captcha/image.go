@@ -0,0 +1,73 @@
+// Package captcha provides external CAPTCHA solver integration.
+package captcha
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// MaxImageBytes bounds the captcha image sent to providers. Every provider
+// rejects uploads much larger than this, and a plain text captcha is a few
+// kilobytes.
+const MaxImageBytes = 600 * 1024
+
+// ImageRequest contains the parameters needed to solve a plain image captcha:
+// distorted text the user reads off a picture and types into a field.
+type ImageRequest struct {
+	Image   []byte // The captcha image, PNG or JPEG
+	PageURL string // The URL of the page showing the captcha
+}
+
+// body returns the image as the base64 string provider task APIs expect.
+func (r *ImageRequest) body() string {
+	return base64.StdEncoding.EncodeToString(r.Image)
+}
+
+// SolveImage reads an image captcha's text using external providers. Unlike
+// the token captchas there is nothing to inject; the caller types the
+// returned text. It follows the same fallback pattern as Solve.
+func (c *SolverChain) SolveImage(ctx context.Context, req *ImageRequest) (*SolveResult, error) {
+	if !c.enabled {
+		return nil, fmt.Errorf("external CAPTCHA solving is not enabled")
+	}
+	if err := c.budget.Exhausted(); err != nil {
+		return nil, err
+	}
+	if len(req.Image) == 0 {
+		return nil, fmt.Errorf("captcha image is empty")
+	}
+	if len(req.Image) > MaxImageBytes {
+		return nil, fmt.Errorf("captcha image is %d bytes, maximum is %d", len(req.Image), MaxImageBytes)
+	}
+
+	startTime := time.Now()
+
+	log.Ctx(ctx).Info().
+		Str("url", req.PageURL).
+		Int("bytes", len(req.Image)).
+		Msg("Attempting external image captcha solve")
+
+	result, provider, err := c.tryProviders(ctx, "image", func(ctx context.Context, provider CaptchaSolver) (*CaptchaResult, error) {
+		result, err := provider.SolveImage(ctx, req)
+		if err == nil && result.Token == "" {
+			err = types.NewCaptchaRejectedError(provider.Name(), "EMPTY_ANSWER", "provider returned an empty answer")
+		}
+		return result, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SolveResult{
+		Token:     result.Token,
+		Provider:  provider.Name(),
+		SolveTime: time.Since(startTime),
+		Cost:      result.Cost,
+	}, nil
+}
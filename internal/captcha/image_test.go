@@ -0,0 +1,80 @@
+package captcha
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSolverChain_SolveImage(t *testing.T) {
+	image := []byte("\x89PNG\r\n\x1a\nfake")
+	var task capSolverTurnstileTask
+	capsolver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Task capSolverTurnstileTask `json:"task"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		task = req.Task
+		// ImageToText tasks are answered synchronously, with no task id to poll
+		json.NewEncoder(w).Encode(capSolverCreateTaskResponse{
+			Status:   "ready",
+			Solution: &capSolverTurnstileSolution{Text: "x7k2p"},
+		})
+	}))
+	defer capsolver.Close()
+
+	metrics := NewMetrics()
+	chain := NewSolverChain(SolverChainConfig{
+		FallbackEnabled: true,
+		Metrics:         metrics,
+		Providers: []CaptchaSolver{
+			NewNineKwSolver(NineKwConfig{APIKey: "test-key"}), // Unsupported, falls through
+			NewCapSolverSolver(CapSolverConfig{APIKey: "test-key", BaseURL: capsolver.URL}),
+		},
+	})
+
+	result, err := chain.SolveImage(context.Background(), &ImageRequest{Image: image, PageURL: "https://example.com/login"})
+	if err != nil {
+		t.Fatalf("SolveImage() error = %v", err)
+	}
+	if result.Token != "x7k2p" || result.Provider != "capsolver" {
+		t.Errorf("SolveImage() = %+v, want x7k2p from capsolver", result)
+	}
+	if task.Type != "ImageToTextTask" || task.Body != base64.StdEncoding.EncodeToString(image) {
+		t.Errorf("task = %+v, want an ImageToTextTask carrying the base64 image", task)
+	}
+	if stats := metrics.GetStats("capsolver"); stats == nil || stats.Successes != 1 {
+		t.Errorf("capsolver stats = %+v, want one success", stats)
+	}
+
+	if _, err := chain.SolveImage(context.Background(), &ImageRequest{Image: make([]byte, MaxImageBytes+1)}); err == nil {
+		t.Error("SolveImage() should reject an oversized image")
+	}
+	if _, err := chain.SolveImage(context.Background(), &ImageRequest{}); err == nil {
+		t.Error("SolveImage() should reject an empty image")
+	}
+}
+
+func TestWebhookSolver_SolveImage(t *testing.T) {
+	var body webhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		json.NewEncoder(w).Encode(webhookResponse{Token: "abcd"})
+	}))
+	defer server.Close()
+
+	solver := NewWebhookSolver(WebhookConfig{URL: server.URL})
+	result, err := solver.SolveImage(context.Background(), &ImageRequest{Image: []byte("img"), PageURL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("SolveImage() error = %v", err)
+	}
+	if result.Token != "abcd" {
+		t.Errorf("Token = %q, want %q", result.Token, "abcd")
+	}
+	if body.Type != "image" || body.Image != base64.StdEncoding.EncodeToString([]byte("img")) {
+		t.Errorf("webhook body = %+v, want type image with the base64 image", body)
+	}
+}
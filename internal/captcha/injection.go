@@ -4,6 +4,7 @@ package captcha
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -32,7 +33,7 @@ func InjectTurnstileToken(ctx context.Context, page *rod.Page, token string) err
 		return fmt.Errorf("failed to encode token: %w", err)
 	}
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("token_prefix", token[:min(20, len(token))]+"...").
 		Msg("Injecting Turnstile token")
 
@@ -55,11 +56,11 @@ func InjectTurnstileToken(ctx context.Context, page *rod.Page, token string) err
 
 		err := method.fn(ctx, page, string(tokenJSON))
 		if err == nil {
-			log.Info().Str("method", method.name).Msg("Token injection succeeded")
+			log.Ctx(ctx).Info().Str("method", method.name).Msg("Token injection succeeded")
 			return nil
 		}
 		lastErr = err
-		log.Debug().
+		log.Ctx(ctx).Debug().
 			Err(err).
 			Str("method", method.name).
 			Msg("Token injection method failed, trying next")
@@ -335,9 +336,10 @@ func InjectHCaptchaToken(ctx context.Context, page *rod.Page, token string) erro
 		return fmt.Errorf("failed to encode token: %w", err)
 	}
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("token_prefix", token[:min(20, len(token))]+"...").
 		Msg("Injecting hCaptcha token")
+	armTokenProbe(ctx, page)
 
 	// Try multiple injection methods for hCaptcha
 	methods := []struct {
@@ -357,11 +359,11 @@ func InjectHCaptchaToken(ctx context.Context, page *rod.Page, token string) erro
 
 		err := method.fn(ctx, page, string(tokenJSON))
 		if err == nil {
-			log.Info().Str("method", method.name).Msg("hCaptcha token injection succeeded")
+			log.Ctx(ctx).Info().Str("method", method.name).Msg("hCaptcha token injection succeeded")
 			return nil
 		}
 		lastErr = err
-		log.Debug().
+		log.Ctx(ctx).Debug().
 			Err(err).
 			Str("method", method.name).
 			Msg("hCaptcha injection method failed, trying next")
@@ -374,26 +376,22 @@ func InjectHCaptchaToken(ctx context.Context, page *rod.Page, token string) erro
 	return types.ErrCaptchaTokenInjection
 }
 
-// injectHCaptchaViaTextarea sets the token on the hCaptcha response textarea.
+// injectHCaptchaViaTextarea sets the token on the hCaptcha response fields.
+// The widget renders both h-captcha-response and a g-recaptcha-response
+// mirror, and forms may post either, so every one of them is filled.
 func injectHCaptchaViaTextarea(ctx context.Context, page *rod.Page, tokenJSON string) error {
 	js := fmt.Sprintf(`
 	(function(token) {
-		var selectors = [
-			'textarea[name="h-captcha-response"]',
-			'textarea[name="g-recaptcha-response"]',
-			'input[name="h-captcha-response"]'
-		];
-
-		for (var i = 0; i < selectors.length; i++) {
-			var input = document.querySelector(selectors[i]);
-			if (input) {
-				input.value = token;
-				input.dispatchEvent(new Event('input', { bubbles: true }));
-				input.dispatchEvent(new Event('change', { bubbles: true }));
-				return true;
-			}
+		var fields = document.querySelectorAll(
+			'textarea[name="h-captcha-response"], input[name="h-captcha-response"], ' +
+			'#h-captcha-response, textarea[name="g-recaptcha-response"]'
+		);
+		for (var i = 0; i < fields.length; i++) {
+			fields[i].value = token;
+			fields[i].dispatchEvent(new Event('input', { bubbles: true }));
+			fields[i].dispatchEvent(new Event('change', { bubbles: true }));
 		}
-		return false;
+		return fields.length > 0;
 	})(%s)
 	`, tokenJSON)
 
@@ -487,34 +485,281 @@ func injectHCaptchaViaAPI(ctx context.Context, page *rod.Page, tokenJSON string)
 	return nil
 }
 
-// WaitForTokenInjectionEffect waits for the page to process the injected token.
-// Some sites need time to validate the token before proceeding.
-func WaitForTokenInjectionEffect(ctx context.Context, page *rod.Page, timeout time.Duration) error {
-	// Wait for potential redirects or form submissions
-	waitCtx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+// InjectRecaptchaToken injects a solved reCAPTCHA token into the page. Unlike
+// the Turnstile and hCaptcha paths every method runs: the response field
+// must be filled and the widget callback fired for the page to proceed.
+func InjectRecaptchaToken(ctx context.Context, page *rod.Page, token string) error {
+	if token == "" {
+		return fmt.Errorf("empty token provided")
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	tokenJSON, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("token_prefix", token[:min(20, len(token))]+"...").
+		Msg("Injecting reCAPTCHA token")
+	armTokenProbe(ctx, page)
+
+	methods := []struct {
+		name string
+		fn   func(context.Context, *rod.Page, string) error
+	}{
+		{"recaptcha_textarea", injectRecaptchaViaTextarea},
+		{"recaptcha_api", injectRecaptchaViaAPI},
+		{"recaptcha_callback", injectRecaptchaViaCallback},
+	}
+
+	injected := false
+	var lastErr error
+	for _, method := range methods {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err := method.fn(ctx, page, string(tokenJSON)); err != nil {
+			lastErr = err
+			log.Ctx(ctx).Debug().
+				Err(err).
+				Str("method", method.name).
+				Msg("reCAPTCHA injection method failed")
+			continue
+		}
+		injected = true
+		log.Ctx(ctx).Debug().Str("method", method.name).Msg("reCAPTCHA injection method succeeded")
+	}
 
-	// Check for common success indicators
-	js := `
-	(function() {
-		// Check if challenge is still visible
-		var challenge = document.querySelector('#challenge-running, .cf-challenge-running, #turnstile-wrapper');
-		if (challenge) {
-			var style = window.getComputedStyle(challenge);
-			if (style.display === 'none' || style.visibility === 'hidden') {
-				return true; // Challenge hidden, likely succeeded
+	if injected {
+		return nil
+	}
+	if lastErr != nil {
+		return fmt.Errorf("all reCAPTCHA injection methods failed, last error: %w", lastErr)
+	}
+
+	return types.ErrCaptchaTokenInjection
+}
+
+// injectRecaptchaViaTextarea sets the token on every g-recaptcha-response
+// field; pages with several widgets number them g-recaptcha-response-1, ...
+func injectRecaptchaViaTextarea(ctx context.Context, page *rod.Page, tokenJSON string) error {
+	js := fmt.Sprintf(`
+	(function(token) {
+		var fields = document.querySelectorAll('[name^="g-recaptcha-response"], #g-recaptcha-response');
+		for (var i = 0; i < fields.length; i++) {
+			fields[i].value = token;
+			fields[i].innerHTML = token;
+			fields[i].dispatchEvent(new Event('input', { bubbles: true }));
+			fields[i].dispatchEvent(new Event('change', { bubbles: true }));
+		}
+		return fields.length > 0;
+	})(%s)
+	`, tokenJSON)
+
+	result, err := evalWithContext(ctx, page, js)
+	if err != nil {
+		return err
+	}
+	if !result {
+		return fmt.Errorf("no g-recaptcha-response field found")
+	}
+	return nil
+}
+
+// injectRecaptchaViaAPI makes grecaptcha.execute and getResponse return the
+// token, so v3 pages and invisible v2 widgets that fetch a token on submit
+// receive the solved one.
+func injectRecaptchaViaAPI(ctx context.Context, page *rod.Page, tokenJSON string) error {
+	js := fmt.Sprintf(`
+	(function(token) {
+		var patched = false;
+		var targets = [window.grecaptcha, window.grecaptcha && window.grecaptcha.enterprise];
+		for (var i = 0; i < targets.length; i++) {
+			var g = targets[i];
+			if (!g) {
+				continue;
 			}
+			g.execute = function() { return Promise.resolve(token); };
+			g.getResponse = function() { return token; };
+			patched = true;
 		}
+		return patched;
+	})(%s)
+	`, tokenJSON)
 
-		// Check for success message
-		var success = document.querySelector('.cf-challenge-success, .turnstile-success');
-		if (success) {
-			return true;
+	result, err := evalWithContext(ctx, page, js)
+	if err != nil {
+		return err
+	}
+	if !result {
+		return fmt.Errorf("grecaptcha API not available")
+	}
+	return nil
+}
+
+// injectRecaptchaViaCallback invokes the widget's success callback, named in
+// data-callback or registered through grecaptcha.render (found by walking
+// ___grecaptcha_cfg.clients).
+func injectRecaptchaViaCallback(ctx context.Context, page *rod.Page, tokenJSON string) error {
+	js := fmt.Sprintf(`
+	(function(token) {
+		var resolve = function(cb) {
+			if (typeof cb === 'function') {
+				return cb;
+			}
+			if (typeof cb === 'string' && typeof window[cb] === 'function') {
+				return window[cb];
+			}
+			return null;
+		};
+
+		var widgets = document.querySelectorAll('.g-recaptcha[data-callback], [data-sitekey][data-callback]');
+		for (var i = 0; i < widgets.length; i++) {
+			var fn = resolve(widgets[i].getAttribute('data-callback'));
+			if (fn) {
+				try { fn(token); return true; } catch(e) {}
+			}
 		}
 
+		var cfg = window.___grecaptcha_cfg;
+		if (!cfg || !cfg.clients) {
+			return false;
+		}
+		var seen = [];
+		var find = function(obj, depth) {
+			if (!obj || typeof obj !== 'object' || depth > 4 || seen.indexOf(obj) !== -1) {
+				return null;
+			}
+			seen.push(obj);
+			for (var key in obj) {
+				var val;
+				try { val = obj[key]; } catch(e) { continue; }
+				if (key === 'callback') {
+					var fn = resolve(val);
+					if (fn) {
+						return fn;
+					}
+				}
+				if (val && typeof val === 'object' && !(val instanceof Node)) {
+					var found = find(val, depth + 1);
+					if (found) {
+						return found;
+					}
+				}
+			}
+			return null;
+		};
+		for (var id in cfg.clients) {
+			var fn = find(cfg.clients[id], 0);
+			if (fn) {
+				try { fn(token); return true; } catch(e) {}
+			}
+		}
 		return false;
-	})()
-	`
+	})(%s)
+	`, tokenJSON)
+
+	result, err := evalWithContext(ctx, page, js)
+	if err != nil {
+		return err
+	}
+	if !result {
+		return fmt.Errorf("no reCAPTCHA callback found")
+	}
+	return nil
+}
+
+// Token kinds VerifyInjectedToken can re-deliver.
+const (
+	TokenTurnstile = "turnstile"
+	TokenHCaptcha  = "hcaptcha"
+	TokenRecaptcha = "recaptcha"
+)
+
+// tokenProbeJS marks the current document before a token is injected, so
+// WaitForTokenInjectionEffect can tell a page that navigated or submitted a
+// form from one that ignored the token. clearance is the cf_clearance value
+// before injection.
+const tokenProbeJS = `
+(function(clearance) {
+	var probe = { clearance: clearance, submitted: false };
+	window.__fsTokenProbe = probe;
+	document.addEventListener('submit', function() { probe.submitted = true; }, true);
+	return true;
+})(%s)
+`
+
+// tokenEffectJS reports how the page reacted to the token: "navigated" once
+// the probed document is gone, "submitted" after a form submit, "success"
+// when the challenge is hidden or shows its success state, or "" with the
+// pre-injection clearance to compare the cookie against.
+const tokenEffectJS = `
+(function() {
+	var probe = window.__fsTokenProbe;
+	if (!probe) {
+		return { state: 'navigated' };
+	}
+	if (probe.submitted) {
+		return { state: 'submitted' };
+	}
+
+	// Check if challenge is still visible
+	var challenge = document.querySelector('#challenge-running, .cf-challenge-running, #turnstile-wrapper');
+	if (challenge) {
+		var style = window.getComputedStyle(challenge);
+		if (style.display === 'none' || style.visibility === 'hidden') {
+			return { state: 'success' }; // Challenge hidden, likely succeeded
+		}
+	}
+
+	// Check for success message
+	if (document.querySelector('.cf-challenge-success, .turnstile-success')) {
+		return { state: 'success' };
+	}
+
+	return { state: '', clearance: probe.clearance };
+})()
+`
+
+// armTokenProbe marks the page ahead of an injection. Failure only weakens
+// the later check, so it is logged rather than returned.
+func armTokenProbe(ctx context.Context, page *rod.Page) {
+	clearanceJSON, err := json.Marshal(clearanceCookie(page))
+	if err != nil {
+		return
+	}
+	if _, err := evalWithContext(ctx, page, fmt.Sprintf(tokenProbeJS, clearanceJSON)); err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("Failed to arm token acceptance probe")
+	}
+}
+
+// clearanceCookie returns the page's cf_clearance value, or "" if it has none.
+func clearanceCookie(page *rod.Page) string {
+	res, err := proto.NetworkGetCookies{}.Call(page)
+	if err != nil {
+		return ""
+	}
+	for _, c := range res.Cookies {
+		if c.Name == "cf_clearance" {
+			return c.Value
+		}
+	}
+	return ""
+}
+
+// WaitForTokenInjectionEffect waits for the page to accept an injected token:
+// it navigates, submits a form, hides its challenge or is issued a new
+// cf_clearance. Returns types.ErrCaptchaTokenRejected if none of that happens
+// within timeout. The Inject functions arm the check; without them any page
+// counts as navigated.
+func WaitForTokenInjectionEffect(ctx context.Context, page *rod.Page, timeout time.Duration) error {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
 	ticker := time.NewTicker(500 * time.Millisecond)
 	defer ticker.Stop()
@@ -522,21 +767,131 @@ func WaitForTokenInjectionEffect(ctx context.Context, page *rod.Page, timeout ti
 	for {
 		select {
 		case <-waitCtx.Done():
-			// Timeout is not necessarily an error - the injection might have worked
-			// but we just can't detect the success indicator
-			return nil
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return types.ErrCaptchaTokenRejected
 		case <-ticker.C:
+			// Evaluation fails while a new document loads; the next tick sees it
 			result, err := proto.RuntimeEvaluate{
-				Expression:    js,
+				Expression:    tokenEffectJS,
 				ReturnByValue: true,
-			}.Call(page)
+			}.Call(page.Context(waitCtx))
+			if err != nil || result == nil || result.Result == nil || result.ExceptionDetails != nil {
+				continue
+			}
 
-			if err == nil && result != nil && result.Result != nil {
-				if result.Result.Value.Bool() {
-					log.Debug().Msg("Detected token injection success indicator")
-					return nil
+			state := result.Result.Value.Get("state").Str()
+			if state == "" {
+				if current := clearanceCookie(page.Context(waitCtx)); current != "" && current != result.Result.Value.Get("clearance").Str() {
+					state = "clearance"
 				}
 			}
+			if state != "" {
+				log.Ctx(ctx).Debug().Str("signal", state).Msg("Page accepted the injected token")
+				return nil
+			}
 		}
 	}
 }
+
+// VerifyInjectedToken waits for the page to accept an injected token. If it
+// does not within timeout, the token is delivered again by invoking the
+// widget callbacks directly, which the first injection skips once a response
+// field takes the token, and the wait repeats. Returns
+// types.ErrCaptchaTokenRejected when the page ignored both.
+func VerifyInjectedToken(ctx context.Context, page *rod.Page, kind, token string, timeout time.Duration) error {
+	err := WaitForTokenInjectionEffect(ctx, page, timeout)
+	if !errors.Is(err, types.ErrCaptchaTokenRejected) {
+		return err
+	}
+
+	log.Ctx(ctx).Info().Str("captcha", kind).Msg("Page did not react to the injected token, re-injecting via callbacks")
+	if err := ReinjectViaCallbacks(ctx, page, kind, token); err != nil {
+		return fmt.Errorf("%w: re-injection failed: %w", types.ErrCaptchaTokenRejected, err)
+	}
+	return WaitForTokenInjectionEffect(ctx, page, timeout)
+}
+
+// ReinjectViaCallbacks delivers a token of the given kind by invoking every
+// widget callback the page exposes. If none does, it submits the form that
+// holds the response field instead.
+func ReinjectViaCallbacks(ctx context.Context, page *rod.Page, kind, token string) error {
+	if token == "" {
+		return fmt.Errorf("empty token provided")
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	var field string
+	var methods []func(context.Context, *rod.Page, string) error
+	switch kind {
+	case TokenTurnstile:
+		field = `[name="cf-turnstile-response"]`
+		methods = []func(context.Context, *rod.Page, string) error{injectViaCallback, injectViaTurnstileAPI, injectViaWindowCallback}
+		if InjectCapturedCallback(page, token) {
+			return nil
+		}
+	case TokenHCaptcha:
+		field = `[name="h-captcha-response"]`
+		methods = []func(context.Context, *rod.Page, string) error{injectHCaptchaViaCallback, injectHCaptchaViaAPI}
+	case TokenRecaptcha:
+		field = `[name^="g-recaptcha-response"]`
+		methods = []func(context.Context, *rod.Page, string) error{injectRecaptchaViaCallback, injectRecaptchaViaAPI}
+	default:
+		return fmt.Errorf("unknown token kind %q", kind)
+	}
+
+	tokenJSON, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	invoked := false
+	for _, fn := range methods {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := fn(ctx, page, string(tokenJSON)); err == nil {
+			invoked = true
+		}
+	}
+	if invoked {
+		return nil
+	}
+	return submitResponseForm(ctx, page, field)
+}
+
+// submitResponseForm submits the form containing the response field matching
+// selector, as the widget would once solved.
+func submitResponseForm(ctx context.Context, page *rod.Page, selector string) error {
+	selectorJSON, err := json.Marshal(selector)
+	if err != nil {
+		return fmt.Errorf("failed to encode selector: %w", err)
+	}
+	js := fmt.Sprintf(`
+	(function(sel) {
+		var field = document.querySelector(sel);
+		var form = field && field.form;
+		if (!form) {
+			return false;
+		}
+		if (typeof form.requestSubmit === 'function') {
+			form.requestSubmit();
+		} else {
+			form.submit();
+		}
+		return true;
+	})(%s)
+	`, selectorJSON)
+
+	submitted, err := evalWithContext(ctx, page, js)
+	if err != nil {
+		return err
+	}
+	if !submitted {
+		return fmt.Errorf("no callback or form to deliver the token to")
+	}
+	return nil
+}
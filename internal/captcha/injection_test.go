@@ -2,7 +2,9 @@ package captcha
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 )
 
 func TestInjectTurnstileToken_EmptyToken(t *testing.T) {
@@ -22,6 +24,26 @@ func TestInjectTurnstileToken_CanceledContext(t *testing.T) {
 	}
 }
 
+func TestReinjectViaCallbacks_Rejects(t *testing.T) {
+	if err := ReinjectViaCallbacks(context.Background(), nil, TokenTurnstile, ""); err == nil {
+		t.Error("expected error for empty token")
+	}
+	if err := ReinjectViaCallbacks(context.Background(), nil, "funcaptcha", "test-token"); err == nil {
+		t.Error("expected error for unknown token kind")
+	}
+}
+
+func TestVerifyInjectedToken_CanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// A canceled solve is not a rejected token, and never re-injects
+	err := VerifyInjectedToken(ctx, nil, TokenTurnstile, "test-token", time.Second)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("VerifyInjectedToken() error = %v, want context.Canceled", err)
+	}
+}
+
 // Note: Full injection tests require a browser page mock
 // which would need a test browser setup. These are covered
 // by integration tests.
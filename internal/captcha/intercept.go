@@ -100,9 +100,17 @@ const turnstileInterceptorJS = `
 `
 
 // interceptors tracks installed pages so Read/Inject can find them, keyed by
-// TargetID. The value is the page's *oopifState (auto-attach capture for its
-// out-of-process iframe children); see oopif.go.
-var interceptors sync.Map // map[proto.TargetTargetID]*oopifState
+// TargetID. The value is the page's *interceptor.
+var interceptors sync.Map // map[proto.TargetTargetID]*interceptor
+
+// interceptor is the state installed on one page: the auto-attach capture for
+// its out-of-process iframe children (see oopif.go), and the removal of the
+// render() script, which would otherwise stay registered on a tab parked for
+// reuse and stack up with every request.
+type interceptor struct {
+	oopif  *oopifState
+	remove func() error
+}
 
 // InstallTurnstileInterceptor registers the render() interceptor on the page. It
 // must be called BEFORE navigation so the script is present at document_start.
@@ -112,11 +120,12 @@ var interceptors sync.Map // map[proto.TargetTargetID]*oopifState
 // Best-effort: failures are logged but never fatal — DOM/iframe/pierce extraction
 // remains as a fallback (see extraction.go).
 func InstallTurnstileInterceptor(page *rod.Page) {
-	if _, err := page.EvalOnNewDocument(turnstileInterceptorJS); err != nil {
+	remove, err := page.EvalOnNewDocument(turnstileInterceptorJS)
+	if err != nil {
 		log.Debug().Err(err).Msg("Failed to register turnstile interceptor")
 		return
 	}
-	interceptors.Store(page.TargetID, installOOPIFCapture(page))
+	interceptors.Store(page.TargetID, &interceptor{oopif: installOOPIFCapture(page), remove: remove})
 	log.Debug().Msg("Turnstile render interceptor installed (main frame + OOPIF auto-attach)")
 }
 
@@ -126,8 +135,7 @@ func loadState(page *rod.Page) (*oopifState, bool) {
 	if !ok {
 		return nil, false
 	}
-	st, _ := v.(*oopifState)
-	return st, true
+	return v.(*interceptor).oopif, true
 }
 
 // ReadCapturedChallengeParams returns the parameters captured from
@@ -190,12 +198,18 @@ func InjectCapturedCallback(page *rod.Page, token string) bool {
 	return false
 }
 
-// RemoveTurnstileInterceptor clears interception state for a page and stops its
-// OOPIF event listener. Safe to call on a page that was never instrumented.
+// RemoveTurnstileInterceptor clears interception state for a page, stops its
+// OOPIF event listener and unregisters the render() script, so a tab reused
+// by the next request starts without it. Safe to call on a page that was
+// never instrumented.
 func RemoveTurnstileInterceptor(page *rod.Page) {
-	if v, ok := interceptors.LoadAndDelete(page.TargetID); ok {
-		if st, _ := v.(*oopifState); st != nil {
-			st.stop()
-		}
+	v, ok := interceptors.LoadAndDelete(page.TargetID)
+	if !ok {
+		return
+	}
+	in := v.(*interceptor)
+	in.oopif.stop()
+	if err := in.remove(); err != nil {
+		log.Debug().Err(err).Msg("Failed to unregister turnstile interceptor")
 	}
 }
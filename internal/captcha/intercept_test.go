@@ -0,0 +1,100 @@
+package captcha
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/browser"
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+)
+
+// interceptorProbeJS counts the copies of turnstileInterceptorJS that run on
+// a document: each reads window.__cfInterceptorInstalled once. Registered
+// before the interceptor, so it runs first.
+const interceptorProbeJS = `
+(() => {
+  window.__interceptorRuns = 0;
+  Object.defineProperty(window, '__cfInterceptorInstalled', {
+    configurable: true,
+    get() { window.__interceptorRuns++; return false; },
+    set(v) {}
+  });
+})();
+`
+
+// TestTurnstileInterceptorOnReusedTab parks and retakes a tab twice and
+// verifies the render() script registered by each request is gone by the
+// next, so copies never stack and a request without the interceptor runs
+// none.
+func TestTurnstileInterceptorOnReusedTab(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping browser test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body>ok</body></html>"))
+	}))
+	defer server.Close()
+
+	pool, err := browser.NewPool(&config.Config{
+		Headless:           true,
+		BrowserPoolSize:    1,
+		BrowserPoolTimeout: 10 * time.Second,
+		MaxMemoryMB:        1024,
+		PageReuseEnabled:   true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	b, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Failed to acquire browser: %v", err)
+	}
+	defer pool.Release(b)
+	page, err := b.Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		t.Fatalf("Failed to create page: %v", err)
+	}
+	if _, err := page.EvalOnNewDocument(interceptorProbeJS); err != nil {
+		t.Fatalf("Failed to register probe: %v", err)
+	}
+
+	runs := func() int {
+		t.Helper()
+		if err := page.Navigate(server.URL); err != nil {
+			t.Fatalf("Failed to navigate: %v", err)
+		}
+		res, err := page.Eval(`() => window.__interceptorRuns`)
+		if err != nil {
+			t.Fatalf("Failed to read probe: %v", err)
+		}
+		return res.Value.Int()
+	}
+
+	// Two requests with the interceptor, then one on a domain without it
+	for i, install := range []bool{true, true, false} {
+		want := 0
+		if install {
+			InstallTurnstileInterceptor(page)
+			want = 1
+		}
+		if got := runs(); got != want {
+			t.Errorf("request %d: interceptor ran %d times, want %d", i+1, got, want)
+		}
+		RemoveTurnstileInterceptor(page)
+
+		pool.ParkPage(b, page)
+		if page = pool.TakePage(b); page == nil {
+			t.Fatalf("request %d: expected the parked tab back", i+1)
+		}
+	}
+	_ = page.Close()
+}
@@ -93,6 +93,18 @@ func (m *Metrics) GetStats(provider string) *ProviderStats {
 	}
 }
 
+// Snapshot returns a copy of the stats of every provider, by name.
+func (m *Metrics) Snapshot() map[string]ProviderStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make(map[string]ProviderStats, len(m.providers))
+	for name, stats := range m.providers {
+		out[name] = *stats
+	}
+	return out
+}
+
 // ToJSON returns all metrics as a map for JSON serialization.
 func (m *Metrics) ToJSON() map[string]interface{} {
 	m.mu.RLock()
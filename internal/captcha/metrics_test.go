@@ -231,6 +231,32 @@ func TestMetrics_Reset(t *testing.T) {
 	}
 }
 
+func TestMetrics_Snapshot(t *testing.T) {
+	m := NewMetrics()
+	m.RecordAttempt("2captcha", true, 0.002, time.Second)
+	m.RecordAttempt("2captcha", false, 0, time.Second)
+	m.RecordAttempt("capsolver", true, 0.003, 2*time.Second)
+
+	snap := m.Snapshot()
+	if len(snap) != 2 {
+		t.Fatalf("Snapshot() has %d providers, want 2", len(snap))
+	}
+	if s := snap["2captcha"]; s.Attempts != 2 || s.Failures != 1 || s.TotalCost != 0.002 {
+		t.Errorf("2captcha = %+v", s)
+	}
+
+	// The snapshot is a copy
+	m.RecordAttempt("capsolver", true, 0.003, time.Second)
+	if snap["capsolver"].Attempts != 1 {
+		t.Error("Snapshot() changed after a later attempt")
+	}
+
+	var chain *SolverChain
+	if chain.ProviderStats() != nil {
+		t.Error("nil chain should report no provider stats")
+	}
+}
+
 func TestMetrics_Concurrent(t *testing.T) {
 	m := NewMetrics()
 
@@ -12,9 +12,10 @@
 // OpenBullet CaptchaSharp NineKwService reference implementation — neither has a
 // Turnstile path). So SolveTurnstile returns a typed "unsupported" error, which
 // makes SolverChain fall through to a Turnstile-capable provider. hCaptcha is
-// fully supported via oldsource=hcaptcha. This means 9kw does NOT help the
-// Cloudflare managed-challenge / Turnstile path that drives issues #11/#13; it
-// adds hCaptcha (and, in future, reCAPTCHA) solving capability.
+// fully supported via oldsource=hcaptcha, and reCAPTCHA v2 via
+// oldsource=recaptchav2. This means 9kw does NOT help the Cloudflare
+// managed-challenge / Turnstile path that drives issues #11/#13; it adds
+// hCaptcha and reCAPTCHA v2 solving capability.
 package captcha
 
 import (
@@ -43,7 +44,8 @@ const (
 	nineKwActionBalance = "usercaptchaguthaben"
 
 	// oldsource identifiers for interactive (token) captchas.
-	nineKwSourceHCaptcha = "hcaptcha"
+	nineKwSourceHCaptcha    = "hcaptcha"
+	nineKwSourceRecaptchaV2 = "recaptchav2"
 
 	// Human solving is slow; poll less aggressively than the automated providers.
 	nineKwPollInterval = 10 * time.Second
@@ -145,6 +147,18 @@ func (s *NineKwSolver) SolveTurnstile(_ context.Context, _ *TurnstileRequest) (*
 	return nil, types.NewCaptchaRejectedError(s.Name(), "UNSUPPORTED", "9kw does not support Cloudflare Turnstile")
 }
 
+// SolveDataDome is not supported by 9kw.
+func (s *NineKwSolver) SolveDataDome(_ context.Context, _ *DataDomeRequest) (*CaptchaResult, error) {
+	return nil, types.NewCaptchaRejectedError(s.Name(), "UNSUPPORTED", "9kw does not support DataDome")
+}
+
+// SolveImage is not supported: 9kw takes image captchas as multipart uploads,
+// which this GET-only client does not send. Returning a rejected error lets
+// SolverChain fall through to the next provider.
+func (s *NineKwSolver) SolveImage(_ context.Context, _ *ImageRequest) (*CaptchaResult, error) {
+	return nil, types.NewCaptchaRejectedError(s.Name(), "UNSUPPORTED", "9kw image captchas are not supported")
+}
+
 // SolveHCaptcha solves an hCaptcha challenge using the 9kw human solving pool.
 func (s *NineKwSolver) SolveHCaptcha(ctx context.Context, req *HCaptchaRequest) (*CaptchaResult, error) {
 	if !s.IsConfigured() {
@@ -158,7 +172,7 @@ func (s *NineKwSolver) SolveHCaptcha(ctx context.Context, req *HCaptchaRequest)
 		return nil, fmt.Errorf("failed to submit hCaptcha: %w", err)
 	}
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("captcha_id", captchaID).
 		Str("sitekey", req.SiteKey[:min(10, len(req.SiteKey))]+"...").
 		Msg("9kw hCaptcha task created")
@@ -176,6 +190,42 @@ func (s *NineKwSolver) SolveHCaptcha(ctx context.Context, req *HCaptchaRequest)
 	}, nil
 }
 
+// SolveRecaptcha solves a reCAPTCHA v2 challenge using the 9kw human solving
+// pool. v3 is score-based and cannot be solved by a human worker, so it is
+// rejected and SolverChain falls through to the next provider.
+func (s *NineKwSolver) SolveRecaptcha(ctx context.Context, req *RecaptchaRequest) (*CaptchaResult, error) {
+	if req.V3 {
+		return nil, types.NewCaptchaRejectedError(s.Name(), "UNSUPPORTED", "9kw does not support reCAPTCHA v3")
+	}
+	if !s.IsConfigured() {
+		return nil, fmt.Errorf("9kw API key not configured")
+	}
+
+	startTime := time.Now()
+
+	captchaID, err := s.submit(ctx, nineKwSourceRecaptchaV2, req.SiteKey, req.PageURL, req.UserAgent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit reCAPTCHA: %w", err)
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("captcha_id", captchaID).
+		Str("sitekey", req.SiteKey[:min(10, len(req.SiteKey))]+"...").
+		Msg("9kw reCAPTCHA task created")
+
+	token, err := s.poll(ctx, captchaID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CaptchaResult{
+		Token:     token,
+		SolveTime: time.Since(startTime),
+		Cost:      0, // 9kw bills in credits, not USD
+		Provider:  s.Name(),
+	}, nil
+}
+
 // submit uploads an interactive (token) captcha and returns the 9kw captcha id.
 func (s *NineKwSolver) submit(ctx context.Context, oldsource, sitekey, pageURL, userAgent string) (string, error) {
 	params := s.authParams()
@@ -236,7 +286,7 @@ func (s *NineKwSolver) poll(ctx context.Context, captchaID string) (string, erro
 			if ready {
 				return token, nil
 			}
-			log.Debug().Str("captcha_id", captchaID).Msg("9kw task still processing")
+			log.Ctx(ctx).Debug().Str("captcha_id", captchaID).Msg("9kw task still processing")
 		}
 	}
 }
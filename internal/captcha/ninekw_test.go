@@ -66,6 +66,24 @@ func TestNineKwSolver_SolveTurnstile_Unsupported(t *testing.T) {
 	}
 }
 
+func TestNineKwSolver_SolveRecaptcha_V3Unsupported(t *testing.T) {
+	solver := NewNineKwSolver(NineKwConfig{APIKey: "test-key"})
+
+	_, err := solver.SolveRecaptcha(context.Background(), &RecaptchaRequest{
+		SiteKey: "6LeIxAcTAAAAAJcZVRqyHh71UMIEGNQ_MXjiZKhI",
+		PageURL: "https://example.com",
+		V3:      true,
+	})
+
+	var captchaErr *types.CaptchaError
+	if !containsCaptchaError(err, &captchaErr) {
+		t.Fatalf("expected CaptchaError, got %v", err)
+	}
+	if captchaErr.Code != "UNSUPPORTED" {
+		t.Errorf("Code = %q, want %q", captchaErr.Code, "UNSUPPORTED")
+	}
+}
+
 func TestNineKwSolver_SolveHCaptcha_Success(t *testing.T) {
 	const wantToken = "P0_eyJ0eXAfor-hcaptcha" //nolint:gosec // test fixture, not a real credential
 	polls := 0
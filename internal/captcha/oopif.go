@@ -174,9 +174,19 @@ func (st *oopifState) injectCallback(expr string) bool {
 	return false
 }
 
+// stop ends the listener and turns auto-attach off again: a tab parked for
+// reuse keeps its session, and its next request's iframes must not wait for a
+// listener that is gone.
 func (st *oopifState) stop() {
-	if st != nil && st.cancel != nil {
-		st.cancel()
+	if st == nil || st.cancel == nil {
+		return
+	}
+	st.cancel()
+	if _, err := rawCDP(st.browser, st.pageSession, "Target.setAutoAttach", map[string]any{
+		"autoAttach":             false,
+		"waitForDebuggerOnStart": false,
+	}); err != nil {
+		log.Debug().Err(err).Msg("OOPIF capture: disabling auto-attach failed")
 	}
 }
 
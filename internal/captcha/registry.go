@@ -1,6 +1,7 @@
 package captcha
 
 import (
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -75,3 +76,16 @@ func BuildPriorityOrder(primary string, available []string) []string {
 	}
 	return order
 }
+
+// ExplicitOrder returns the names in order that are available, first
+// occurrence only. Providers not named are left out, so an operator can both
+// select and rank providers.
+func ExplicitOrder(order, available []string) []string {
+	result := make([]string, 0, len(order))
+	for _, name := range order {
+		if slices.Contains(available, name) && !slices.Contains(result, name) {
+			result = append(result, name)
+		}
+	}
+	return result
+}
@@ -5,11 +5,16 @@ package captcha
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/Rorqualx/flaresolverr-go/internal/tracing"
 	"github.com/Rorqualx/flaresolverr-go/internal/types"
 )
 
@@ -26,6 +31,18 @@ type CaptchaSolver interface {
 	// Returns the solution token or an error.
 	SolveHCaptcha(ctx context.Context, req *HCaptchaRequest) (*CaptchaResult, error)
 
+	// SolveRecaptcha attempts to solve a reCAPTCHA v2 or v3 challenge.
+	// Returns the g-recaptcha-response token or an error.
+	SolveRecaptcha(ctx context.Context, req *RecaptchaRequest) (*CaptchaResult, error)
+
+	// SolveDataDome attempts to solve a DataDome slider captcha.
+	// Returns the datadome Set-Cookie string as the token, or an error.
+	SolveDataDome(ctx context.Context, req *DataDomeRequest) (*CaptchaResult, error)
+
+	// SolveImage attempts to read the text of a plain image captcha.
+	// Returns the answer as the token, or an error.
+	SolveImage(ctx context.Context, req *ImageRequest) (*CaptchaResult, error)
+
 	// Balance retrieves the current account balance from the provider.
 	Balance(ctx context.Context) (float64, error)
 
@@ -35,12 +52,13 @@ type CaptchaSolver interface {
 
 // TurnstileRequest contains the parameters needed to solve a Turnstile challenge.
 type TurnstileRequest struct {
-	SiteKey   string // The Turnstile sitekey (data-sitekey attribute)
-	PageURL   string // The URL of the page containing the Turnstile
-	UserAgent string // The user agent to use for solving
-	Action    string // Optional action parameter
-	CData     string // Optional cData parameter
-	PageData  string // Optional chlPageData — required for Cloudflare managed challenges
+	SiteKey   string       // The Turnstile sitekey (data-sitekey attribute)
+	PageURL   string       // The URL of the page containing the Turnstile
+	UserAgent string       // The user agent to use for solving
+	Action    string       // Optional action parameter
+	CData     string       // Optional cData parameter
+	PageData  string       // Optional chlPageData — required for Cloudflare managed challenges
+	Proxy     *types.Proxy // Optional proxy for providers that solve through the caller's IP
 }
 
 // HCaptchaRequest contains the parameters needed to solve an hCaptcha challenge.
@@ -50,6 +68,16 @@ type HCaptchaRequest struct {
 	UserAgent string // The user agent to use for solving
 }
 
+// RecaptchaRequest contains the parameters needed to solve a reCAPTCHA challenge.
+type RecaptchaRequest struct {
+	SiteKey   string // The reCAPTCHA sitekey (data-sitekey or api.js?render=)
+	PageURL   string // The URL of the page containing the reCAPTCHA
+	UserAgent string // The user agent to use for solving
+	V3        bool   // Score-based reCAPTCHA v3 rather than the v2 checkbox
+	Invisible bool   // v2 invisible widget (data-size="invisible")
+	Action    string // v3 action name, e.g. "login"
+}
+
 // CaptchaResult contains the solution from a CAPTCHA solver (generic).
 type CaptchaResult = TurnstileResult
 
@@ -67,7 +95,10 @@ type SolverChain struct {
 	nativeAttempts int             // Number of native attempts before fallback
 	providers      []CaptchaSolver // External solver providers in order of preference
 	metrics        *Metrics        // Usage metrics tracking
+	budget         *Budget         // Spend caps (nil caps nothing)
 	enabled        bool            // Whether external fallback is enabled
+	raceThreshold  float64         // Native success rate below which external solving races native
+	breakers       map[string]*breaker
 }
 
 // SolverChainConfig contains configuration for the SolverChain.
@@ -75,7 +106,10 @@ type SolverChainConfig struct {
 	NativeAttempts  int             // Native attempts before fallback (default: 3)
 	Providers       []CaptchaSolver // External providers in priority order
 	Metrics         *Metrics        // Metrics tracker (optional)
+	Budget          *Budget         // Spend caps (optional)
 	FallbackEnabled bool            // Whether external fallback is enabled
+	RaceThreshold   float64         // Native success rate (0-1) below which external solving races native; 0 disables
+	Breaker         BreakerConfig   // Per-provider circuit breakers (zero ErrorRate disables)
 }
 
 // NewSolverChain creates a new SolverChain with the given configuration.
@@ -88,11 +122,21 @@ func NewSolverChain(cfg SolverChainConfig) *SolverChain {
 		nativeAttempts = 10
 	}
 
+	breakers := make(map[string]*breaker, len(cfg.Providers))
+	for _, p := range cfg.Providers {
+		if b := newBreaker(cfg.Breaker); b != nil {
+			breakers[p.Name()] = b
+		}
+	}
+
 	return &SolverChain{
+		breakers:       breakers,
 		nativeAttempts: nativeAttempts,
 		providers:      cfg.Providers,
 		metrics:        cfg.Metrics,
+		budget:         cfg.Budget,
 		enabled:        cfg.FallbackEnabled,
+		raceThreshold:  cfg.RaceThreshold,
 	}
 }
 
@@ -110,6 +154,16 @@ func (c *SolverChain) IsEnabled() bool {
 	return c.enabled
 }
 
+// RaceThreshold returns the native success rate below which the external
+// solve is started alongside the native methods instead of after them, or 0
+// when racing is off.
+func (c *SolverChain) RaceThreshold() float64 {
+	if !c.enabled {
+		return 0
+	}
+	return c.raceThreshold
+}
+
 // NativeAttempts returns the configured number of native attempts.
 func (c *SolverChain) NativeAttempts() int {
 	return c.nativeAttempts
@@ -120,6 +174,33 @@ func (c *SolverChain) GetProviders() []CaptchaSolver {
 	return c.providers
 }
 
+// preferredProviderKey carries the provider a domain's solver preferences
+// put first.
+type preferredProviderKey struct{}
+
+// WithPreferredProvider returns ctx asking the chain to try the provider
+// named name before the others. An empty name keeps the configured order.
+func WithPreferredProvider(ctx context.Context, name string) context.Context {
+	if name == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, preferredProviderKey{}, name)
+}
+
+// providersFor returns the providers in the order to try them for ctx: the
+// one preferred on ctx first, then the rest in the configured order.
+func (c *SolverChain) providersFor(ctx context.Context) []CaptchaSolver {
+	preferred, _ := ctx.Value(preferredProviderKey{}).(string)
+	i := slices.IndexFunc(c.providers, func(p CaptchaSolver) bool { return p.Name() == preferred })
+	if i <= 0 {
+		return c.providers
+	}
+	ordered := make([]CaptchaSolver, 0, len(c.providers))
+	ordered = append(ordered, c.providers[i])
+	ordered = append(ordered, c.providers[:i]...)
+	return append(ordered, c.providers[i+1:]...)
+}
+
 // HasProviders returns true if at least one provider is configured.
 func (c *SolverChain) HasProviders() bool {
 	for _, p := range c.providers {
@@ -147,27 +228,63 @@ type SolveResult struct {
 // 2. Tries each provider in order until one succeeds
 // 3. Injects the token into the page
 // 4. Records metrics
-func (c *SolverChain) Solve(ctx context.Context, page *rod.Page, pageURL, userAgent string) (*SolveResult, error) {
+//
+// proxy is the request's proxy, handed to providers that can solve through
+// it; nil solves proxyless.
+func (c *SolverChain) Solve(ctx context.Context, page *rod.Page, pageURL, userAgent string, proxy *types.Proxy) (*SolveResult, error) {
 	if !c.enabled {
 		return nil, fmt.Errorf("external CAPTCHA solving is not enabled")
 	}
+	if err := c.budget.Exhausted(); err != nil {
+		return nil, err
+	}
 
 	startTime := time.Now()
 
-	// Prefer parameters captured live from the turnstile.render() call (managed
-	// challenges expose sitekey/action/cData/chlPageData only there, never as DOM
-	// attributes). Fall back to DOM/iframe extraction for embedded widgets.
+	req, err := TurnstileRequestFromPage(ctx, page, pageURL, userAgent, proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Info().
+		Str("sitekey", req.SiteKey[:min(10, len(req.SiteKey))]+"...").
+		Str("url", pageURL).
+		Bool("managed", req.PageData != "").
+		Msg("Attempting external CAPTCHA solve")
+
+	result, provider, err := c.tryProviders(ctx, "turnstile", func(ctx context.Context, provider CaptchaSolver) (*CaptchaResult, error) {
+		return provider.SolveTurnstile(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SolveResult{
+		Token:     result.Token,
+		Provider:  provider.Name(),
+		SolveTime: time.Since(startTime),
+		Cost:      result.Cost,
+		Injected:  InjectSolvedTurnstile(ctx, page, result.Token),
+	}, nil
+}
+
+// TurnstileRequestFromPage describes the Turnstile widget on page for an
+// external provider. Parameters captured live from the turnstile.render() call
+// are preferred (managed challenges expose sitekey/action/cData/chlPageData
+// only there, never as DOM attributes), with DOM/iframe extraction as the
+// fallback for embedded widgets.
+func TurnstileRequestFromPage(ctx context.Context, page *rod.Page, pageURL, userAgent string, proxy *types.Proxy) (*TurnstileRequest, error) {
 	captured, hasCaptured := ReadCapturedChallengeParams(page)
 
 	var sitekey string
 	if hasCaptured && captured.SiteKey != "" {
 		sitekey = captured.SiteKey
-		log.Debug().Str("source", "render_intercept").Msg("Using intercepted Turnstile params")
+		log.Ctx(ctx).Debug().Str("source", "render_intercept").Msg("Using intercepted Turnstile params")
 	} else {
 		var err error
 		sitekey, err = ExtractTurnstileSitekey(page)
 		if err != nil {
-			log.Debug().Err(err).Msg("Failed to extract Turnstile sitekey")
+			log.Ctx(ctx).Debug().Err(err).Msg("Failed to extract Turnstile sitekey")
 			return nil, fmt.Errorf("failed to extract sitekey: %w", err)
 		}
 	}
@@ -178,6 +295,7 @@ func (c *SolverChain) Solve(ctx context.Context, page *rod.Page, pageURL, userAg
 		UserAgent: userAgent,
 		Action:    ExtractTurnstileAction(page),
 		CData:     ExtractTurnstileCData(page),
+		Proxy:     proxy,
 	}
 	// Captured render() params win over DOM scraping when present.
 	if hasCaptured {
@@ -189,78 +307,60 @@ func (c *SolverChain) Solve(ctx context.Context, page *rod.Page, pageURL, userAg
 		}
 		req.PageData = captured.PageData
 	}
+	return req, nil
+}
 
-	log.Info().
-		Str("sitekey", sitekey[:min(10, len(sitekey))]+"...").
-		Str("url", pageURL).
-		Bool("managed", req.PageData != "").
-		Bool("intercepted", hasCaptured).
-		Msg("Attempting external CAPTCHA solve")
-
-	// Try each provider in order
-	var lastErr error
-	for _, provider := range c.providers {
-		if !provider.IsConfigured() {
-			continue
-		}
-
-		providerStart := time.Now()
-		result, err := provider.SolveTurnstile(ctx, req)
-		providerDuration := time.Since(providerStart)
-
-		if err != nil {
-			log.Warn().
-				Err(err).
-				Str("provider", provider.Name()).
-				Dur("duration", providerDuration).
-				Msg("External solver failed, trying next provider")
-			lastErr = err
-
-			// Record failed attempt
-			if c.metrics != nil {
-				c.metrics.RecordAttempt(provider.Name(), false, 0, providerDuration)
-			}
-			continue
-		}
-
-		// Success - inject the token
-		log.Info().
-			Str("provider", provider.Name()).
-			Dur("solve_time", result.SolveTime).
-			Float64("cost", result.Cost).
-			Msg("External solver succeeded")
+// InjectSolvedTurnstile hands an externally solved token to the page and
+// reports whether it was injected. Managed challenges complete through the
+// captured render() callback; that is tried first, then the generic
+// textarea/callback methods.
+func InjectSolvedTurnstile(ctx context.Context, page *rod.Page, token string) bool {
+	armTokenProbe(ctx, page)
+	if InjectCapturedCallback(page, token) {
+		return true
+	}
+	if err := InjectTurnstileToken(ctx, page, token); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to inject token, returning token anyway")
+		return false
+	}
+	log.Ctx(ctx).Debug().Msg("Token injected successfully")
+	return true
+}
 
-		injected := false
-		// Managed challenges complete through the captured render() callback;
-		// try that first, then fall back to the generic textarea/callback methods.
-		if InjectCapturedCallback(page, result.Token) {
-			injected = true
-		} else if err := InjectTurnstileToken(ctx, page, result.Token); err != nil {
-			log.Warn().Err(err).Msg("Failed to inject token, returning token anyway")
-		} else {
-			injected = true
-			log.Debug().Msg("Token injected successfully")
-		}
+// SolveTurnstileToken solves a Turnstile widget described entirely by req,
+// without a page to read from or inject into. It backs the turnstile.solve
+// command, where the caller only wants the token.
+func (c *SolverChain) SolveTurnstileToken(ctx context.Context, req *TurnstileRequest) (*SolveResult, error) {
+	if !c.enabled {
+		return nil, fmt.Errorf("external CAPTCHA solving is not enabled")
+	}
+	if err := c.budget.Exhausted(); err != nil {
+		return nil, err
+	}
+	if req.SiteKey == "" {
+		return nil, fmt.Errorf("sitekey is required")
+	}
 
-		// Record successful attempt
-		if c.metrics != nil {
-			c.metrics.RecordAttempt(provider.Name(), true, result.Cost, result.SolveTime)
-		}
+	startTime := time.Now()
 
-		return &SolveResult{
-			Token:     result.Token,
-			Provider:  provider.Name(),
-			SolveTime: time.Since(startTime),
-			Cost:      result.Cost,
-			Injected:  injected,
-		}, nil
-	}
+	log.Ctx(ctx).Info().
+		Str("sitekey", req.SiteKey[:min(10, len(req.SiteKey))]+"...").
+		Str("url", req.PageURL).
+		Msg("Attempting external Turnstile token solve")
 
-	if lastErr != nil {
-		return nil, fmt.Errorf("all providers failed, last error: %w", lastErr)
+	result, provider, err := c.tryProviders(ctx, "turnstile", func(ctx context.Context, provider CaptchaSolver) (*CaptchaResult, error) {
+		return provider.SolveTurnstile(ctx, req)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, types.ErrCaptchaNoProviders
+	return &SolveResult{
+		Token:     result.Token,
+		Provider:  provider.Name(),
+		SolveTime: time.Since(startTime),
+		Cost:      result.Cost,
+	}, nil
 }
 
 // SolveHCaptcha attempts to solve an hCaptcha challenge using external providers.
@@ -269,17 +369,20 @@ func (c *SolverChain) SolveHCaptcha(ctx context.Context, page *rod.Page, pageURL
 	if !c.enabled {
 		return nil, fmt.Errorf("external CAPTCHA solving is not enabled")
 	}
+	if err := c.budget.Exhausted(); err != nil {
+		return nil, err
+	}
 
 	startTime := time.Now()
 
 	// Extract hCaptcha sitekey from page
 	sitekey, err := ExtractHCaptchaSitekey(page)
 	if err != nil {
-		log.Debug().Err(err).Msg("Failed to extract hCaptcha sitekey")
+		log.Ctx(ctx).Debug().Err(err).Msg("Failed to extract hCaptcha sitekey")
 		return nil, fmt.Errorf("failed to extract hCaptcha sitekey: %w", err)
 	}
 
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("sitekey", sitekey[:min(10, len(sitekey))]+"...").
 		Str("url", pageURL).
 		Msg("Attempting external hCaptcha solve")
@@ -290,69 +393,270 @@ func (c *SolverChain) SolveHCaptcha(ctx context.Context, page *rod.Page, pageURL
 		UserAgent: userAgent,
 	}
 
-	// Try each provider in order
+	result, provider, err := c.tryProviders(ctx, "hcaptcha", func(ctx context.Context, provider CaptchaSolver) (*CaptchaResult, error) {
+		return provider.SolveHCaptcha(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	injected := false
+	if err := InjectHCaptchaToken(ctx, page, result.Token); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to inject hCaptcha token, returning token anyway")
+	} else {
+		injected = true
+		log.Ctx(ctx).Debug().Msg("hCaptcha token injected successfully")
+	}
+
+	return &SolveResult{
+		Token:     result.Token,
+		Provider:  provider.Name(),
+		SolveTime: time.Since(startTime),
+		Cost:      result.Cost,
+		Injected:  injected,
+	}, nil
+}
+
+// SolveRecaptcha attempts to solve a reCAPTCHA v2 or v3 challenge using
+// external providers, injecting the token into g-recaptcha-response and
+// firing the widget callback. It follows the same fallback pattern as Solve.
+func (c *SolverChain) SolveRecaptcha(ctx context.Context, page *rod.Page, pageURL, userAgent string) (*SolveResult, error) {
+	if !c.enabled {
+		return nil, fmt.Errorf("external CAPTCHA solving is not enabled")
+	}
+	if err := c.budget.Exhausted(); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	req, err := ExtractRecaptchaParams(page)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("Failed to extract reCAPTCHA parameters")
+		return nil, fmt.Errorf("failed to extract reCAPTCHA sitekey: %w", err)
+	}
+	req.PageURL = pageURL
+	req.UserAgent = userAgent
+
+	log.Ctx(ctx).Info().
+		Str("sitekey", req.SiteKey[:min(10, len(req.SiteKey))]+"...").
+		Str("url", pageURL).
+		Bool("v3", req.V3).
+		Bool("invisible", req.Invisible).
+		Msg("Attempting external reCAPTCHA solve")
+
+	result, provider, err := c.tryProviders(ctx, "recaptcha", func(ctx context.Context, provider CaptchaSolver) (*CaptchaResult, error) {
+		return provider.SolveRecaptcha(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	injected := false
+	if err := InjectRecaptchaToken(ctx, page, result.Token); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to inject reCAPTCHA token, returning token anyway")
+	} else {
+		injected = true
+		log.Ctx(ctx).Debug().Msg("reCAPTCHA token injected successfully")
+	}
+
+	return &SolveResult{
+		Token:     result.Token,
+		Provider:  provider.Name(),
+		SolveTime: time.Since(startTime),
+		Cost:      result.Cost,
+		Injected:  injected,
+	}, nil
+}
+
+// tryProviders calls solve with each configured provider in preference order
+// until one returns a token, and reports which did. Providers over their spend
+// budget or with an open circuit breaker are skipped. Each call is traced,
+// fed to the provider's breaker and counted in the metrics, and the winning
+// solve's cost is charged to the budget. kind names the captcha type in
+// spans, logs and the error returned when every provider fails.
+func (c *SolverChain) tryProviders(ctx context.Context, kind string, solve func(context.Context, CaptchaSolver) (*CaptchaResult, error)) (*CaptchaResult, CaptchaSolver, error) {
 	var lastErr error
-	for _, provider := range c.providers {
+	for _, provider := range c.providersFor(ctx) {
 		if !provider.IsConfigured() {
 			continue
 		}
+		if err := c.budget.Allow(provider.Name()); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Str("provider", provider.Name()).Msg("Provider over spend budget, skipping")
+			lastErr = err
+			continue
+		}
+		circuit := c.breakers[provider.Name()]
+		if !circuit.allow() {
+			log.Ctx(ctx).Debug().Str("provider", provider.Name()).Msg("Provider circuit breaker open, skipping")
+			lastErr = types.NewCaptchaCircuitOpenError(provider.Name())
+			continue
+		}
 
 		providerStart := time.Now()
-		result, err := provider.SolveHCaptcha(ctx, req)
+		providerCtx, span := startProviderSpan(ctx, provider, kind)
+		result, err := solve(providerCtx, provider)
+		tracing.End(span, err)
 		providerDuration := time.Since(providerStart)
+		circuit.record(circuit.outcome(ctx, err, providerDuration))
 
 		if err != nil {
-			log.Warn().
+			log.Ctx(ctx).Warn().
 				Err(err).
 				Str("provider", provider.Name()).
+				Str("captcha_type", kind).
 				Dur("duration", providerDuration).
-				Msg("External hCaptcha solver failed, trying next provider")
+				Msg("External solver failed, trying next provider")
 			lastErr = err
 
 			if c.metrics != nil {
 				c.metrics.RecordAttempt(provider.Name(), false, 0, providerDuration)
+				c.metrics.RecordError(provider.Name(), err.Error())
 			}
 			continue
 		}
 
-		log.Info().
+		log.Ctx(ctx).Info().
 			Str("provider", provider.Name()).
+			Str("captcha_type", kind).
 			Dur("solve_time", result.SolveTime).
 			Float64("cost", result.Cost).
-			Msg("External hCaptcha solver succeeded")
-
-		injected := false
-		if err := InjectHCaptchaToken(ctx, page, result.Token); err != nil {
-			log.Warn().Err(err).Msg("Failed to inject hCaptcha token, returning token anyway")
-		} else {
-			injected = true
-			log.Debug().Msg("hCaptcha token injected successfully")
-		}
+			Msg("External solver succeeded")
 
 		if c.metrics != nil {
 			c.metrics.RecordAttempt(provider.Name(), true, result.Cost, result.SolveTime)
 		}
-
-		return &SolveResult{
-			Token:     result.Token,
-			Provider:  provider.Name(),
-			SolveTime: time.Since(startTime),
-			Cost:      result.Cost,
-			Injected:  injected,
-		}, nil
+		c.budget.Record(provider.Name(), result.Cost)
+		return result, provider, nil
 	}
 
 	if lastErr != nil {
-		return nil, fmt.Errorf("all providers failed for hCaptcha, last error: %w", lastErr)
+		return nil, nil, fmt.Errorf("all providers failed for %s, last error: %w", kind, lastErr)
 	}
+	return nil, nil, types.ErrCaptchaNoProviders
+}
 
-	return nil, types.ErrCaptchaNoProviders
+// startProviderSpan starts the span of one external provider call.
+func startProviderSpan(ctx context.Context, provider CaptchaSolver, captchaType string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "captcha."+provider.Name(),
+		attribute.String("captcha.provider", provider.Name()),
+		attribute.String("captcha.type", captchaType))
 }
 
-// GetMetrics returns the current metrics for all providers.
+// GetMetrics returns the current metrics for all providers, with the state
+// of their circuit breakers.
 func (c *SolverChain) GetMetrics() map[string]interface{} {
 	if c.metrics == nil {
 		return nil
 	}
-	return c.metrics.ToJSON()
+	result := c.metrics.ToJSON()
+	for name, st := range c.BreakerStatus() {
+		providerData, ok := result[name].(map[string]interface{})
+		if !ok {
+			providerData = make(map[string]interface{})
+			result[name] = providerData
+		}
+		breakerData := map[string]interface{}{
+			"state":        st.State,
+			"failure_rate": st.FailureRate,
+			"calls":        st.Calls,
+		}
+		if !st.RetryAt.IsZero() {
+			breakerData["retry_at"] = st.RetryAt.Format(time.RFC3339)
+		}
+		providerData["breaker"] = breakerData
+	}
+	return result
+}
+
+// BreakerStatus returns the circuit breaker state of each provider, by name,
+// nil when breakers are disabled.
+func (c *SolverChain) BreakerStatus() map[string]BreakerStatus {
+	if c == nil || len(c.breakers) == 0 {
+		return nil
+	}
+	out := make(map[string]BreakerStatus, len(c.breakers))
+	for name, b := range c.breakers {
+		out[name] = b.status()
+	}
+	return out
+}
+
+// ProviderStats returns a copy of the per-provider stats, nil when the
+// chain keeps no metrics.
+func (c *SolverChain) ProviderStats() map[string]ProviderStats {
+	if c == nil || c.metrics == nil {
+		return nil
+	}
+	return c.metrics.Snapshot()
+}
+
+// BudgetStatus returns the spend budgets with what remains of them, nil when
+// nothing is capped.
+func (c *SolverChain) BudgetStatus() []BudgetStatus {
+	if c == nil {
+		return nil
+	}
+	return c.budget.Status()
+}
+
+// balanceQueryTimeout bounds each provider's balance query in Status.
+const balanceQueryTimeout = 15 * time.Second
+
+// Status queries every configured provider's balance concurrently and
+// reports it with the provider's solve stats. A balance below lowBalance is
+// flagged; 0 disables the flag. Returns nil without a chain.
+func (c *SolverChain) Status(ctx context.Context, lowBalance float64) []types.CaptchaProviderStatus {
+	if c == nil {
+		return nil
+	}
+
+	var configured []CaptchaSolver
+	for _, p := range c.providers {
+		if p.IsConfigured() {
+			configured = append(configured, p)
+		}
+	}
+
+	statuses := make([]types.CaptchaProviderStatus, len(configured))
+	var wg sync.WaitGroup
+	for i, provider := range configured {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			st := types.CaptchaProviderStatus{Provider: provider.Name()}
+
+			balanceCtx, cancel := context.WithTimeout(ctx, balanceQueryTimeout)
+			start := time.Now()
+			balance, err := provider.Balance(balanceCtx)
+			st.LatencyMs = time.Since(start).Milliseconds()
+			cancel()
+			if err != nil {
+				st.Error = err.Error()
+			} else {
+				st.Balance = &balance
+				st.LowBalance = lowBalance > 0 && balance < lowBalance
+			}
+
+			if c.metrics != nil {
+				if err == nil {
+					c.metrics.UpdateBalance(provider.Name(), balance)
+				}
+				if stats := c.metrics.GetStats(provider.Name()); stats != nil {
+					st.Attempts = stats.Attempts
+					st.LastError = stats.LastError
+					if stats.Attempts > 0 {
+						st.AvgSolveMs = stats.TotalTimeMs / stats.Attempts
+						st.SuccessRate = float64(stats.Successes) / float64(stats.Attempts) * 100
+					}
+				}
+			}
+			if b := c.breakers[provider.Name()]; b != nil {
+				st.Breaker = b.status().State
+			}
+			statuses[i] = st
+		}()
+	}
+	wg.Wait()
+	return statuses
 }
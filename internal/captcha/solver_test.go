@@ -1,8 +1,20 @@
 package captcha
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
 )
 
 func TestSolverChain_ShouldFallback(t *testing.T) {
@@ -149,6 +161,70 @@ func TestSolverChain_HasProviders(t *testing.T) {
 	})
 }
 
+func TestSolverChain_SolveTurnstileToken(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	var task capSolverTurnstileTask
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/createTask":
+			var req capSolverCreateTaskRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			task = req.Task
+			json.NewEncoder(w).Encode(capSolverCreateTaskResponse{TaskID: "ts-1"})
+		case "/getTaskResult":
+			json.NewEncoder(w).Encode(capSolverGetResultResponse{
+				Status:   "ready",
+				Solution: &capSolverTurnstileSolution{Token: "0.token"},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	chain := NewSolverChain(SolverChainConfig{
+		FallbackEnabled: true,
+		Providers: []CaptchaSolver{
+			NewTwoCaptchaSolver(TwoCaptchaConfig{}), // unconfigured, skipped
+			NewCapSolverSolver(CapSolverConfig{APIKey: "test-key", BaseURL: server.URL, Timeout: 10 * time.Second}),
+		},
+	})
+
+	result, err := chain.SolveTurnstileToken(context.Background(), &TurnstileRequest{
+		SiteKey: "0x4AAAAAAAtest",
+		PageURL: "https://example.com/login",
+	})
+	if err != nil {
+		t.Fatalf("SolveTurnstileToken() error = %v", err)
+	}
+	if result.Token != "0.token" || result.Provider != "capsolver" || result.Injected {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if task.WebsiteKey != "0x4AAAAAAAtest" || task.WebsiteURL != "https://example.com/login" {
+		t.Errorf("unexpected task: %+v", task)
+	}
+	spans := rec.Ended()
+	if len(spans) != 1 || spans[0].Name() != "captcha.capsolver" {
+		t.Fatalf("provider spans = %d, want one captcha.capsolver span", len(spans))
+	}
+	if attrs := attribute.NewSet(spans[0].Attributes()...); !attrs.HasValue("captcha.type") {
+		t.Errorf("provider span attributes = %v, want captcha.type", spans[0].Attributes())
+	}
+
+	if _, err := chain.SolveTurnstileToken(context.Background(), &TurnstileRequest{PageURL: "https://example.com/"}); err == nil {
+		t.Error("Expected an error without a sitekey")
+	}
+
+	empty := NewSolverChain(SolverChainConfig{FallbackEnabled: true})
+	if _, err := empty.SolveTurnstileToken(context.Background(), &TurnstileRequest{SiteKey: "k"}); !errors.Is(err, types.ErrCaptchaNoProviders) {
+		t.Errorf("error = %v, want ErrCaptchaNoProviders", err)
+	}
+}
+
 func TestTurnstileRequest_Fields(t *testing.T) {
 	req := &TurnstileRequest{
 		SiteKey:   "0x4AAAAAAA",
@@ -215,3 +291,159 @@ func TestMin(t *testing.T) {
 		}
 	}
 }
+
+func TestSolverChain_Status(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/getBalance" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(capSolverBalanceResponse{Balance: 0.4})
+	}))
+	defer server.Close()
+
+	metrics := NewMetrics()
+	metrics.RecordAttempt("capsolver", true, 0.001, 4*time.Second)
+	metrics.RecordAttempt("capsolver", false, 0, 2*time.Second)
+	metrics.RecordError("capsolver", "ERROR_CAPTCHA_UNSOLVABLE")
+
+	chain := NewSolverChain(SolverChainConfig{
+		FallbackEnabled: true,
+		Metrics:         metrics,
+		Providers: []CaptchaSolver{
+			NewTwoCaptchaSolver(TwoCaptchaConfig{}), // unconfigured, not reported
+			NewCapSolverSolver(CapSolverConfig{APIKey: "test-key", BaseURL: server.URL}),
+			NewWebhookSolver(WebhookConfig{URL: server.URL + "/solve"}),
+		},
+	})
+
+	statuses := chain.Status(context.Background(), 1.0)
+	if len(statuses) != 2 {
+		t.Fatalf("Status() returned %d providers, want 2", len(statuses))
+	}
+	cs := statuses[0]
+	if cs.Provider != "capsolver" || cs.Balance == nil || *cs.Balance != 0.4 || !cs.LowBalance {
+		t.Errorf("capsolver status = %+v, want a low 0.4 balance", cs)
+	}
+	if cs.Attempts != 2 || cs.SuccessRate != 50 || cs.AvgSolveMs != 3000 || cs.LastError != "ERROR_CAPTCHA_UNSOLVABLE" {
+		t.Errorf("capsolver stats = %+v, want 2 attempts, 50%% success, 3000ms average and the last error", cs)
+	}
+	if stats := metrics.GetStats("capsolver"); stats.LastBalance != 0.4 {
+		t.Errorf("LastBalance = %v, want the queried 0.4", stats.LastBalance)
+	}
+	if custom := statuses[1]; custom.Provider != "custom" || custom.Balance != nil || custom.Error == "" {
+		t.Errorf("custom status = %+v, want a balance error", custom)
+	}
+
+	if got := (*SolverChain)(nil).Status(context.Background(), 1); got != nil {
+		t.Errorf("nil chain Status() = %v, want nil", got)
+	}
+}
+
+func TestSolverChain_PreferredProvider(t *testing.T) {
+	capsolverCalls := 0
+	capsolver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		capsolverCalls++
+		json.NewEncoder(w).Encode(capSolverCreateTaskResponse{ErrorID: 1, ErrorCode: "ERROR_KEY_DENIED_ACCESS"})
+	}))
+	defer capsolver.Close()
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(webhookResponse{Token: "webhook-token"})
+	}))
+	defer webhook.Close()
+
+	chain := NewSolverChain(SolverChainConfig{
+		FallbackEnabled: true,
+		Providers: []CaptchaSolver{
+			NewCapSolverSolver(CapSolverConfig{APIKey: "test-key", BaseURL: capsolver.URL}),
+			NewWebhookSolver(WebhookConfig{URL: webhook.URL}),
+		},
+	})
+	req := &TurnstileRequest{SiteKey: "0x4AAAAAAA", PageURL: "https://example.com"}
+
+	result, err := chain.SolveTurnstileToken(WithPreferredProvider(context.Background(), "custom"), req)
+	if err != nil || result.Provider != "custom" {
+		t.Fatalf("preferred solve = %+v, %v, want custom", result, err)
+	}
+	if capsolverCalls != 0 {
+		t.Errorf("capsolver called %d times, want 0: custom is preferred", capsolverCalls)
+	}
+
+	// Without a preference the configured order applies; unknown names are ignored
+	if result, err := chain.SolveTurnstileToken(WithPreferredProvider(context.Background(), "nope"), req); err != nil || result.Provider != "custom" {
+		t.Fatalf("solve = %+v, %v, want custom after capsolver fails", result, err)
+	}
+	if capsolverCalls != 1 {
+		t.Errorf("capsolver called %d times, want 1", capsolverCalls)
+	}
+}
+
+// stubProvider is a configured provider whose every solve returns result and err.
+type stubProvider struct {
+	name   string
+	result *CaptchaResult
+	err    error
+	calls  int
+}
+
+func (p *stubProvider) solve() (*CaptchaResult, error) {
+	p.calls++
+	return p.result, p.err
+}
+
+func (p *stubProvider) Name() string { return p.name }
+func (p *stubProvider) SolveTurnstile(context.Context, *TurnstileRequest) (*TurnstileResult, error) {
+	return p.solve()
+}
+func (p *stubProvider) SolveHCaptcha(context.Context, *HCaptchaRequest) (*CaptchaResult, error) {
+	return p.solve()
+}
+func (p *stubProvider) SolveRecaptcha(context.Context, *RecaptchaRequest) (*CaptchaResult, error) {
+	return p.solve()
+}
+func (p *stubProvider) SolveDataDome(context.Context, *DataDomeRequest) (*CaptchaResult, error) {
+	return p.solve()
+}
+func (p *stubProvider) SolveImage(context.Context, *ImageRequest) (*CaptchaResult, error) {
+	return p.solve()
+}
+func (p *stubProvider) Balance(context.Context) (float64, error) { return 0, nil }
+func (p *stubProvider) IsConfigured() bool                       { return true }
+
+func TestSolverChain_TryProviders(t *testing.T) {
+	overBudget := &stubProvider{name: "over", result: &CaptchaResult{Token: "unused"}}
+	failing := &stubProvider{name: "failing", err: errors.New("ERROR_CAPTCHA_UNSOLVABLE")}
+	working := &stubProvider{name: "working", result: &CaptchaResult{Token: "tok", Cost: 0.002}}
+
+	budget := NewBudget(BudgetLimits{}, map[string]BudgetLimits{"over": {Daily: 0.5}})
+	budget.Record("over", 1)
+	metrics := NewMetrics()
+	chain := NewSolverChain(SolverChainConfig{
+		FallbackEnabled: true,
+		Providers:       []CaptchaSolver{overBudget, failing, working},
+		Metrics:         metrics,
+		Budget:          budget,
+	})
+
+	solve := func(ctx context.Context, p CaptchaSolver) (*CaptchaResult, error) {
+		return p.SolveHCaptcha(ctx, &HCaptchaRequest{})
+	}
+	result, provider, err := chain.tryProviders(context.Background(), "hcaptcha", solve)
+	if err != nil || result.Token != "tok" || provider != working {
+		t.Fatalf("tryProviders() = %+v, %v, %v; want the working provider's token", result, provider, err)
+	}
+	if overBudget.calls != 0 || failing.calls != 1 {
+		t.Errorf("calls: over budget %d, failing %d; want 0 and 1", overBudget.calls, failing.calls)
+	}
+	if st := metrics.GetStats("failing"); st == nil || st.Failures != 1 {
+		t.Errorf("failing provider stats = %+v, want one failure", st)
+	}
+	if st := metrics.GetStats("working"); st == nil || st.Successes != 1 || st.TotalCost != 0.002 {
+		t.Errorf("working provider stats = %+v, want one success costing 0.002", st)
+	}
+
+	only := NewSolverChain(SolverChainConfig{FallbackEnabled: true, Providers: []CaptchaSolver{failing}})
+	if _, _, err := only.tryProviders(context.Background(), "hcaptcha", solve); !errors.Is(err, failing.err) {
+		t.Errorf("tryProviders() error = %v, want the last provider error", err)
+	}
+}
@@ -0,0 +1,300 @@
+// Package captcha provides external CAPTCHA solver integration.
+package captcha
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// Methods of the task API shared by anti-captcha.com and CapMonster Cloud
+const (
+	taskAPICreateTask = "/createTask"
+	taskAPIGetResult  = "/getTaskResult"
+	taskAPIGetBalance = "/getBalance"
+)
+
+// taskClient speaks the task API anti-captcha.com and CapMonster Cloud share:
+// createTask with the client key, then getTaskResult until the task is ready.
+// Providers build the tasks and name their own error codes; the client
+// handles the transport, polling, cost and the codes common to both.
+type taskClient struct {
+	provider     string // Provider name reported in results and errors
+	label        string // Service name in logs and error messages
+	apiKey       string
+	baseURL      string
+	timeout      time.Duration
+	pollInterval time.Duration
+	httpClient   *http.Client
+
+	// rejection returns why a provider-specific error code rejected the
+	// task, or "" for a code it does not know.
+	rejection func(code string) string
+}
+
+// newTaskClient completes c with its HTTP client.
+func newTaskClient(c taskClient) *taskClient {
+	c.httpClient = &http.Client{
+		Timeout: c.timeout + 10*time.Second, // HTTP timeout slightly longer than solve timeout
+	}
+	return &c
+}
+
+// apiTask is the task specification of createTask. Each provider fills the
+// fields its task types take; the rest are omitted.
+type apiTask struct {
+	Type       string `json:"type"`
+	WebsiteURL string `json:"websiteURL"`
+	WebsiteKey string `json:"websiteKey"`
+
+	// Turnstile
+	Action             string `json:"action,omitempty"`             // anti-captcha.com
+	TurnstileCData     string `json:"turnstileCData,omitempty"`     // anti-captcha.com
+	Data               string `json:"data,omitempty"`               // CapMonster's cData
+	CloudflareTaskType string `json:"cloudflareTaskType,omitempty"` // "token" for managed challenge pages
+	PageData           string `json:"pageData,omitempty"`           // chlPageData
+	UserAgent          string `json:"userAgent,omitempty"`
+
+	// reCAPTCHA
+	IsInvisible bool    `json:"isInvisible,omitempty"`
+	MinScore    float64 `json:"minScore,omitempty"`
+	PageAction  string  `json:"pageAction,omitempty"` // v3 action, and CapMonster's Turnstile action
+
+	// Proxy tasks
+	ProxyType     string `json:"proxyType,omitempty"`
+	ProxyAddress  string `json:"proxyAddress,omitempty"`
+	ProxyPort     int    `json:"proxyPort,omitempty"`
+	ProxyLogin    string `json:"proxyLogin,omitempty"`
+	ProxyPassword string `json:"proxyPassword,omitempty"`
+
+	// ImageToText
+	Body string `json:"body,omitempty"`
+}
+
+// taskCreateRequest is the request body for createTask.
+type taskCreateRequest struct {
+	ClientKey string  `json:"clientKey"`
+	Task      apiTask `json:"task"`
+}
+
+// taskCreateResponse is the response from createTask.
+type taskCreateResponse struct {
+	ErrorID          int    `json:"errorId"`
+	ErrorCode        string `json:"errorCode,omitempty"`
+	ErrorDescription string `json:"errorDescription,omitempty"`
+	TaskID           int64  `json:"taskId,omitempty"`
+}
+
+// taskResultRequest is the request body for getTaskResult.
+type taskResultRequest struct {
+	ClientKey string `json:"clientKey"`
+	TaskID    int64  `json:"taskId"`
+}
+
+// taskResultResponse is the response from getTaskResult.
+type taskResultResponse struct {
+	ErrorID          int           `json:"errorId"`
+	ErrorCode        string        `json:"errorCode,omitempty"`
+	ErrorDescription string        `json:"errorDescription,omitempty"`
+	Status           string        `json:"status"` // "processing" or "ready"
+	Solution         *taskSolution `json:"solution,omitempty"`
+	Cost             string        `json:"cost,omitempty"` // Charged in USD, e.g. "0.00200"; anti-captcha.com only
+}
+
+// taskSolution contains the task solution. Turnstile solutions carry token;
+// reCAPTCHA and hCaptcha solutions carry gRecaptchaResponse, and image
+// solutions carry text.
+type taskSolution struct {
+	Token              string `json:"token,omitempty"`
+	GRecaptchaResponse string `json:"gRecaptchaResponse,omitempty"`
+	Text               string `json:"text,omitempty"`
+}
+
+// token returns the solution token from whichever field carries it.
+func (sol *taskSolution) token() string {
+	if sol.Token != "" {
+		return sol.Token
+	}
+	if sol.GRecaptchaResponse != "" {
+		return sol.GRecaptchaResponse
+	}
+	return sol.Text
+}
+
+// taskBalanceResponse is the response from getBalance.
+type taskBalanceResponse struct {
+	ErrorID          int     `json:"errorId"`
+	ErrorCode        string  `json:"errorCode,omitempty"`
+	ErrorDescription string  `json:"errorDescription,omitempty"`
+	Balance          float64 `json:"balance"`
+}
+
+// configured reports whether the client has an API key.
+func (c *taskClient) configured() bool {
+	return c.apiKey != ""
+}
+
+// solveTask creates a task of any type and polls it to completion. The cost
+// is the one the provider reports, or estimatedCost if it reports none.
+func (c *taskClient) solveTask(ctx context.Context, kind string, task apiTask, estimatedCost float64) (*CaptchaResult, error) {
+	if !c.configured() {
+		return nil, fmt.Errorf("%s API key not configured", c.provider)
+	}
+
+	startTime := time.Now()
+
+	var taskResp taskCreateResponse
+	if err := c.call(ctx, taskAPICreateTask, taskCreateRequest{ClientKey: c.apiKey, Task: task}, &taskResp); err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+	if taskResp.ErrorID != 0 {
+		return nil, c.handleError(taskResp.ErrorCode, taskResp.ErrorDescription, "")
+	}
+
+	log.Ctx(ctx).Debug().
+		Int64("task_id", taskResp.TaskID).
+		Str("type", task.Type).
+		Str("cloudflare_task_type", task.CloudflareTaskType).
+		Str("sitekey", task.WebsiteKey[:min(10, len(task.WebsiteKey))]+"...").
+		Msg(c.label + " " + kind + " task created")
+
+	result, err := c.pollResult(ctx, taskResp.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	cost := estimatedCost
+	if reported, err := strconv.ParseFloat(result.Cost, 64); err == nil && reported > 0 {
+		cost = reported
+	}
+
+	return &CaptchaResult{
+		Token:     result.Solution.token(),
+		SolveTime: time.Since(startTime),
+		Cost:      cost,
+		Provider:  c.provider,
+	}, nil
+}
+
+// pollResult polls for the task result until complete or timeout.
+func (c *taskClient) pollResult(ctx context.Context, taskID int64) (*taskResultResponse, error) {
+	pollCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	id := strconv.FormatInt(taskID, 10)
+	for {
+		select {
+		case <-pollCtx.Done():
+			return nil, types.NewCaptchaTimeoutError(c.provider, id)
+		case <-ticker.C:
+			var result taskResultResponse
+			if err := c.call(pollCtx, taskAPIGetResult, taskResultRequest{ClientKey: c.apiKey, TaskID: taskID}, &result); err != nil {
+				// The solve timeout can expire mid-request
+				if ctx.Err() == nil && pollCtx.Err() != nil {
+					return nil, types.NewCaptchaTimeoutError(c.provider, id)
+				}
+				return nil, err
+			}
+			if result.ErrorID != 0 {
+				return nil, c.handleError(result.ErrorCode, result.ErrorDescription, id)
+			}
+
+			if result.Status == "ready" {
+				if result.Solution == nil || result.Solution.token() == "" {
+					return nil, fmt.Errorf("received ready status but no token")
+				}
+				return &result, nil
+			}
+			log.Ctx(ctx).Debug().
+				Int64("task_id", taskID).
+				Str("status", result.Status).
+				Msg(c.label + " task still processing")
+		}
+	}
+}
+
+// balance retrieves the current account balance.
+func (c *taskClient) balance(ctx context.Context) (float64, error) {
+	if !c.configured() {
+		return 0, fmt.Errorf("%s API key not configured", c.provider)
+	}
+
+	var balanceResp taskBalanceResponse
+	if err := c.call(ctx, taskAPIGetBalance, map[string]string{"clientKey": c.apiKey}, &balanceResp); err != nil {
+		return 0, err
+	}
+	if balanceResp.ErrorID != 0 {
+		return 0, c.handleError(balanceResp.ErrorCode, balanceResp.ErrorDescription, "")
+	}
+
+	return balanceResp.Balance, nil
+}
+
+// call posts body as JSON to an API method and decodes the response into out.
+func (c *taskClient) call(ctx context.Context, method string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+method, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// handleError converts an error code to the matching error type: the codes
+// both services use here, the provider's own through rejection, and any other
+// as a generic rejection carrying the service's description.
+func (c *taskClient) handleError(code, description, taskID string) error {
+	switch code {
+	case "ERROR_ZERO_BALANCE":
+		return types.NewCaptchaBalanceError(c.provider)
+	case "ERROR_NO_SLOT_AVAILABLE":
+		return types.NewCaptchaRejectedError(c.provider, code, "no workers available, try again later")
+	}
+	if reason := c.rejection(code); reason != "" {
+		return types.NewCaptchaRejectedError(c.provider, code, reason)
+	}
+
+	msg := description
+	if msg == "" {
+		msg = code
+	}
+	return &types.CaptchaError{
+		Provider: c.provider,
+		TaskID:   taskID,
+		Code:     code,
+		Message:  fmt.Sprintf("%s error: %s", c.label, msg),
+		Err:      types.ErrCaptchaSolverRejected,
+	}
+}
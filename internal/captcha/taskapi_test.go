@@ -0,0 +1,199 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// testPollInterval keeps tests against the fake task API fast.
+const testPollInterval = 10 * time.Millisecond
+
+// newTestTaskAPI starts a fake createTask/getTaskResult API and returns its
+// URL. createTask requests are decoded into created; getTaskResult answers
+// with results in turn, repeating the last.
+func newTestTaskAPI(t *testing.T, created *taskCreateRequest, results ...taskResultResponse) string {
+	t.Helper()
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/createTask":
+			if err := json.NewDecoder(r.Body).Decode(created); err != nil {
+				t.Errorf("decode createTask: %v", err)
+			}
+			json.NewEncoder(w).Encode(taskCreateResponse{TaskID: 4242})
+		case "/getTaskResult":
+			var req taskResultRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TaskID != 4242 {
+				t.Errorf("getTaskResult = %+v (%v), want task 4242", req, err)
+			}
+			mu.Lock()
+			result := results[0]
+			if len(results) > 1 {
+				results = results[1:]
+			}
+			mu.Unlock()
+			json.NewEncoder(w).Encode(result)
+		case "/getBalance":
+			json.NewEncoder(w).Encode(taskBalanceResponse{Balance: 12.5})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// newTestTaskClient returns a client against baseURL that knows one
+// provider-specific code, ERROR_TEST_REJECTED.
+func newTestTaskClient(baseURL string) *taskClient {
+	return newTaskClient(taskClient{
+		provider:     "test",
+		label:        "Test",
+		apiKey:       "test-key",
+		baseURL:      baseURL,
+		timeout:      5 * time.Second,
+		pollInterval: testPollInterval,
+		rejection: func(code string) string {
+			if code == "ERROR_TEST_REJECTED" {
+				return "rejected by test"
+			}
+			return ""
+		},
+	})
+}
+
+func TestTaskClient_SolveTask(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   taskResultResponse
+		wantCost float64
+	}{
+		{
+			name:     "reported cost",
+			result:   taskResultResponse{Status: "ready", Solution: &taskSolution{Token: "token"}, Cost: "0.00150"},
+			wantCost: 0.0015,
+		},
+		{
+			name:     "estimated cost",
+			result:   taskResultResponse{Status: "ready", Solution: &taskSolution{GRecaptchaResponse: "token"}},
+			wantCost: 0.002,
+		},
+		{
+			name:     "image text",
+			result:   taskResultResponse{Status: "ready", Solution: &taskSolution{Text: "token"}, Cost: "0"},
+			wantCost: 0.002,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created taskCreateRequest
+			client := newTestTaskClient(newTestTaskAPI(t, &created, taskResultResponse{Status: "processing"}, tt.result))
+
+			result, err := client.solveTask(context.Background(), "Turnstile", apiTask{
+				Type:       "TurnstileTaskProxyless",
+				WebsiteURL: "https://example.com",
+				WebsiteKey: "0x4AAAAAAA",
+			}, 0.002)
+			if err != nil {
+				t.Fatalf("solveTask() error = %v", err)
+			}
+			if result.Token != "token" || result.Provider != "test" {
+				t.Errorf("result = %+v, want token from test", result)
+			}
+			if result.Cost != tt.wantCost {
+				t.Errorf("Cost = %v, want %v", result.Cost, tt.wantCost)
+			}
+			if created.ClientKey != "test-key" || created.Task.Type != "TurnstileTaskProxyless" {
+				t.Errorf("createTask = %+v, want the task with the client key", created)
+			}
+		})
+	}
+}
+
+func TestTaskClient_ReadyWithoutToken(t *testing.T) {
+	var created taskCreateRequest
+	client := newTestTaskClient(newTestTaskAPI(t, &created, taskResultResponse{Status: "ready", Solution: &taskSolution{}}))
+
+	if _, err := client.solveTask(context.Background(), "image", apiTask{Type: "ImageToTextTask"}, 0); err == nil {
+		t.Error("solveTask() should fail on a ready task without a token")
+	}
+}
+
+func TestTaskClient_Timeout(t *testing.T) {
+	var created taskCreateRequest
+	client := newTestTaskClient(newTestTaskAPI(t, &created, taskResultResponse{Status: "processing"}))
+	client.timeout = 50 * time.Millisecond
+
+	_, err := client.solveTask(context.Background(), "Turnstile", apiTask{Type: "TurnstileTaskProxyless"}, 0)
+	var captchaErr *types.CaptchaError
+	if !errors.Is(err, types.ErrCaptchaSolverTimeout) || !errors.As(err, &captchaErr) || captchaErr.TaskID != "4242" {
+		t.Errorf("error = %v, want a timeout for task 4242", err)
+	}
+}
+
+func TestTaskClient_Errors(t *testing.T) {
+	tests := []struct {
+		code        string
+		wantErr     error
+		wantMessage string
+	}{
+		{code: "ERROR_ZERO_BALANCE", wantErr: types.ErrCaptchaSolverBalance},
+		{code: "ERROR_NO_SLOT_AVAILABLE", wantErr: types.ErrCaptchaSolverRejected, wantMessage: "no workers available"},
+		{code: "ERROR_TEST_REJECTED", wantErr: types.ErrCaptchaSolverRejected, wantMessage: "rejected by test"},
+		{code: "ERROR_SOMETHING_NEW", wantErr: types.ErrCaptchaSolverRejected, wantMessage: "Test error: something new"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			var created taskCreateRequest
+			client := newTestTaskClient(newTestTaskAPI(t, &created, taskResultResponse{
+				ErrorID:          1,
+				ErrorCode:        tt.code,
+				ErrorDescription: "something new",
+			}))
+
+			_, err := client.solveTask(context.Background(), "Turnstile", apiTask{Type: "TurnstileTaskProxyless"}, 0)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantMessage) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestTaskClient_Balance(t *testing.T) {
+	var created taskCreateRequest
+	client := newTestTaskClient(newTestTaskAPI(t, &created, taskResultResponse{}))
+
+	balance, err := client.balance(context.Background())
+	if err != nil {
+		t.Fatalf("balance() error = %v", err)
+	}
+	if balance != 12.5 {
+		t.Errorf("balance() = %v, want 12.5", balance)
+	}
+}
+
+func TestTaskClient_NotConfigured(t *testing.T) {
+	client := newTestTaskClient("http://127.0.0.1:0")
+	client.apiKey = ""
+
+	if _, err := client.solveTask(context.Background(), "Turnstile", apiTask{}, 0); err == nil {
+		t.Error("solveTask() without an API key should fail")
+	}
+	if _, err := client.balance(context.Background()); err == nil {
+		t.Error("balance() without an API key should fail")
+	}
+}
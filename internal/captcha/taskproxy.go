@@ -0,0 +1,65 @@
+package captcha
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// taskProxy is a proxy split into the fields provider task APIs expect.
+type taskProxy struct {
+	Type     string // "http", "https", "socks4" or "socks5"
+	Address  string
+	Port     int
+	Login    string
+	Password string
+}
+
+// parseTaskProxy splits a request proxy into provider task fields, for the
+// proxy-bound DataDome and Turnstile tasks of every provider.
+// Credentials in the proxy object take precedence over ones in the URL.
+func parseTaskProxy(p *types.Proxy) (*taskProxy, error) {
+	if p == nil || p.URL == "" {
+		return nil, fmt.Errorf("solving through the browser's proxy requires a proxy")
+	}
+	raw := p.URL
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	host, portStr, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return nil, fmt.Errorf("proxy URL must include a port: %w", err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		return nil, fmt.Errorf("invalid proxy port %q", portStr)
+	}
+
+	proxy := &taskProxy{Type: strings.ToLower(u.Scheme), Address: host, Port: port}
+	if u.User != nil {
+		proxy.Login = u.User.Username()
+		proxy.Password, _ = u.User.Password()
+	}
+	if p.Username != "" {
+		proxy.Login = p.Username
+		proxy.Password = p.Password
+	}
+	return proxy, nil
+}
+
+// String formats the proxy as CapSolver's "type:host:port[:login:password]".
+func (p *taskProxy) String() string {
+	s := p.Type + ":" + net.JoinHostPort(p.Address, strconv.Itoa(p.Port))
+	if p.Login != "" {
+		s += ":" + p.Login + ":" + p.Password
+	}
+	return s
+}
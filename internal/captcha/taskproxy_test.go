@@ -0,0 +1,37 @@
+package captcha
+
+import (
+	"testing"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func TestParseTaskProxy(t *testing.T) {
+	tests := []struct {
+		name  string
+		proxy *types.Proxy
+		want  string
+	}{
+		{"no credentials", &types.Proxy{URL: "http://10.0.0.1:8080"}, "http:10.0.0.1:8080"},
+		{"credentials in url", &types.Proxy{URL: "socks5://a:b@proxy.example.com:1080"}, "socks5:proxy.example.com:1080:a:b"},
+		{"credentials fields win", &types.Proxy{URL: "http://a:b@10.0.0.1:8080", Username: "c", Password: "d"}, "http:10.0.0.1:8080:c:d"},
+		{"no scheme", &types.Proxy{URL: "10.0.0.1:3128"}, "http:10.0.0.1:3128"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTaskProxy(tt.proxy)
+			if err != nil {
+				t.Fatalf("parseTaskProxy() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("parseTaskProxy() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+
+	for _, p := range []*types.Proxy{nil, {URL: "http://10.0.0.1"}, {URL: "http://10.0.0.1:99999"}} {
+		if _, err := parseTaskProxy(p); err == nil {
+			t.Errorf("parseTaskProxy(%v): expected error", p)
+		}
+	}
+}
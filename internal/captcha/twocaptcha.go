@@ -99,6 +99,22 @@ type twoCaptchaTurnstileTask struct {
 	Data       string `json:"data,omitempty"`
 	PageData   string `json:"pagedata,omitempty"`
 	UserAgent  string `json:"userAgent,omitempty"`
+
+	// reCAPTCHA only
+	IsInvisible bool    `json:"isInvisible,omitempty"`
+	PageAction  string  `json:"pageAction,omitempty"`
+	MinScore    float64 `json:"minScore,omitempty"`
+
+	// DataDome only
+	CaptchaURL    string `json:"captchaUrl,omitempty"`
+	ProxyType     string `json:"proxyType,omitempty"`
+	ProxyAddress  string `json:"proxyAddress,omitempty"`
+	ProxyPort     int    `json:"proxyPort,omitempty"`
+	ProxyLogin    string `json:"proxyLogin,omitempty"`
+	ProxyPassword string `json:"proxyPassword,omitempty"`
+
+	// ImageToText only
+	Body string `json:"body,omitempty"`
 }
 
 // twoCaptchaCreateTaskResponse is the response from createTask.
@@ -125,9 +141,29 @@ type twoCaptchaGetResultResponse struct {
 	Cost             string                       `json:"cost,omitempty"`
 }
 
-// twoCaptchaTurnstileSolution contains the Turnstile solution.
+// twoCaptchaTurnstileSolution contains the Turnstile solution. reCAPTCHA
+// (and, on anti-captcha.com, hCaptcha) solutions carry gRecaptchaResponse
+// instead of token, DataDome solutions carry cookie, and image solutions
+// carry text.
 type twoCaptchaTurnstileSolution struct {
-	Token string `json:"token"`
+	Token              string `json:"token"`
+	GRecaptchaResponse string `json:"gRecaptchaResponse,omitempty"`
+	Cookie             string `json:"cookie,omitempty"`
+	Text               string `json:"text,omitempty"`
+}
+
+// token returns the solution token from whichever field carries it.
+func (sol *twoCaptchaTurnstileSolution) token() string {
+	if sol.Token != "" {
+		return sol.Token
+	}
+	if sol.GRecaptchaResponse != "" {
+		return sol.GRecaptchaResponse
+	}
+	if sol.Cookie != "" {
+		return sol.Cookie
+	}
+	return sol.Text
 }
 
 // twoCaptchaBalanceResponse is the response from getBalance.
@@ -152,7 +188,7 @@ func (s *TwoCaptchaSolver) SolveTurnstile(ctx context.Context, req *TurnstileReq
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Int64("task_id", taskID).
 		Str("sitekey", req.SiteKey[:min(10, len(req.SiteKey))]+"...").
 		Msg("2Captcha task created")
@@ -172,7 +208,7 @@ func (s *TwoCaptchaSolver) SolveTurnstile(ctx context.Context, req *TurnstileReq
 	}
 
 	return &TurnstileResult{
-		Token:     result.Solution.Token,
+		Token:     result.Solution.token(),
 		SolveTime: solveTime,
 		Cost:      cost,
 		Provider:  s.Name(),
@@ -255,13 +291,13 @@ func (s *TwoCaptchaSolver) pollResult(ctx context.Context, taskID int64) (*twoCa
 			}
 
 			if result.Status == "ready" {
-				if result.Solution == nil || result.Solution.Token == "" {
+				if result.Solution == nil || result.Solution.token() == "" {
 					return nil, fmt.Errorf("received ready status but no token")
 				}
 				return result, nil
 			}
 
-			log.Debug().
+			log.Ctx(ctx).Debug().
 				Int64("task_id", taskID).
 				Str("status", result.Status).
 				Msg("2Captcha task still processing")
@@ -354,20 +390,79 @@ func (s *TwoCaptchaSolver) Balance(ctx context.Context) (float64, error) {
 // SolveHCaptcha solves an hCaptcha challenge using the 2Captcha-compatible API.
 // The task type is "HCaptchaTaskProxyless" which all 3 providers support.
 func (s *TwoCaptchaSolver) SolveHCaptcha(ctx context.Context, req *HCaptchaRequest) (*CaptchaResult, error) {
+	return s.solveTask(ctx, "hCaptcha", twoCaptchaTurnstileTask{
+		Type:       "HCaptchaTaskProxyless",
+		WebsiteURL: req.PageURL,
+		WebsiteKey: req.SiteKey,
+	})
+}
+
+// twoCaptchaRecaptchaV3MinScore is the score requested for v3 tokens. Higher
+// scores cost more and fail more often; 0.3 passes most default thresholds.
+const twoCaptchaRecaptchaV3MinScore = 0.3
+
+// SolveRecaptcha solves a reCAPTCHA v2 or v3 challenge using the
+// 2Captcha-compatible API.
+func (s *TwoCaptchaSolver) SolveRecaptcha(ctx context.Context, req *RecaptchaRequest) (*CaptchaResult, error) {
+	task := twoCaptchaTurnstileTask{
+		Type:        "RecaptchaV2TaskProxyless",
+		WebsiteURL:  req.PageURL,
+		WebsiteKey:  req.SiteKey,
+		UserAgent:   req.UserAgent,
+		IsInvisible: req.Invisible,
+	}
+	if req.V3 {
+		task = twoCaptchaTurnstileTask{
+			Type:       "RecaptchaV3TaskProxyless",
+			WebsiteURL: req.PageURL,
+			WebsiteKey: req.SiteKey,
+			PageAction: req.Action,
+			MinScore:   twoCaptchaRecaptchaV3MinScore,
+		}
+	}
+	return s.solveTask(ctx, "reCAPTCHA", task)
+}
+
+// SolveDataDome solves a DataDome slider captcha using the 2Captcha API. The
+// task runs through the browser's proxy, since the cookie is IP-bound.
+func (s *TwoCaptchaSolver) SolveDataDome(ctx context.Context, req *DataDomeRequest) (*CaptchaResult, error) {
+	proxy, err := parseTaskProxy(req.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	return s.solveTask(ctx, "DataDome", twoCaptchaTurnstileTask{
+		Type:          "DataDomeSliderTask",
+		WebsiteURL:    req.PageURL,
+		CaptchaURL:    req.CaptchaURL,
+		UserAgent:     req.UserAgent,
+		ProxyType:     proxy.Type,
+		ProxyAddress:  proxy.Address,
+		ProxyPort:     proxy.Port,
+		ProxyLogin:    proxy.Login,
+		ProxyPassword: proxy.Password,
+	})
+}
+
+// SolveImage reads an image captcha using the 2Captcha API.
+func (s *TwoCaptchaSolver) SolveImage(ctx context.Context, req *ImageRequest) (*CaptchaResult, error) {
+	return s.solveTask(ctx, "image", twoCaptchaTurnstileTask{
+		Type:       "ImageToTextTask",
+		WebsiteURL: req.PageURL,
+		Body:       req.body(),
+	})
+}
+
+// solveTask creates a task of any type and polls it to completion.
+func (s *TwoCaptchaSolver) solveTask(ctx context.Context, kind string, task twoCaptchaTurnstileTask) (*CaptchaResult, error) {
 	if !s.IsConfigured() {
 		return nil, fmt.Errorf("%s API key not configured", s.Name())
 	}
 
 	startTime := time.Now()
 
-	// Create hCaptcha task (same API structure, different task type)
 	taskReq := twoCaptchaCreateTaskRequest{
 		ClientKey: s.apiKey,
-		Task: twoCaptchaTurnstileTask{
-			Type:       "HCaptchaTaskProxyless",
-			WebsiteURL: req.PageURL,
-			WebsiteKey: req.SiteKey,
-		},
+		Task:      task,
 	}
 
 	body, err := json.Marshal(taskReq)
@@ -401,9 +496,9 @@ func (s *TwoCaptchaSolver) SolveHCaptcha(ctx context.Context, req *HCaptchaReque
 		return nil, s.handleError(taskResp.ErrorCode, taskResp.ErrorDescription, "")
 	}
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Int64("task_id", taskResp.TaskID).
-		Msg("hCaptcha task created via " + s.Name())
+		Msg(kind + " task created via " + s.Name())
 
 	// Poll for result (reuse existing poll method)
 	result, err := s.pollResult(ctx, taskResp.TaskID)
@@ -418,7 +513,7 @@ func (s *TwoCaptchaSolver) SolveHCaptcha(ctx context.Context, req *HCaptchaReque
 	}
 
 	return &CaptchaResult{
-		Token:     result.Solution.Token,
+		Token:     result.Solution.token(),
 		SolveTime: solveTime,
 		Cost:      cost,
 		Provider:  s.Name(),
@@ -115,6 +115,53 @@ func TestTwoCaptchaSolver_SolveTurnstile_Success(t *testing.T) {
 	}
 }
 
+// TestTwoCaptchaSolver_SolveRecaptcha_V3 verifies the v3 task shape and that
+// the token is read from gRecaptchaResponse
+func TestTwoCaptchaSolver_SolveRecaptcha_V3(t *testing.T) {
+	var task map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/createTask":
+			var body struct {
+				Task map[string]interface{} `json:"task"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			task = body.Task
+			json.NewEncoder(w).Encode(twoCaptchaCreateTaskResponse{TaskID: 1})
+		case "/getTaskResult":
+			json.NewEncoder(w).Encode(twoCaptchaGetResultResponse{
+				Status:   "ready",
+				Solution: &twoCaptchaTurnstileSolution{GRecaptchaResponse: "03AGdBq2"},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	solver := NewTwoCaptchaSolver(TwoCaptchaConfig{
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+		Timeout: 30 * time.Second,
+	})
+
+	result, err := solver.SolveRecaptcha(context.Background(), &RecaptchaRequest{
+		SiteKey: "6LeIxAcTAAAAAJcZVRqyHh71UMIEGNQ_MXjiZKhI",
+		PageURL: "https://example.com/login",
+		V3:      true,
+		Action:  "login",
+	})
+	if err != nil {
+		t.Fatalf("SolveRecaptcha() error = %v", err)
+	}
+	if result.Token != "03AGdBq2" {
+		t.Errorf("Token = %q, want %q", result.Token, "03AGdBq2")
+	}
+	if task["type"] != "RecaptchaV3TaskProxyless" || task["pageAction"] != "login" || task["minScore"] != twoCaptchaRecaptchaV3MinScore {
+		t.Errorf("unexpected task: %v", task)
+	}
+}
+
 func TestTwoCaptchaSolver_SolveTurnstile_CreateTaskError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(twoCaptchaCreateTaskResponse{
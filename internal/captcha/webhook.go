@@ -0,0 +1,286 @@
+// Package captcha provides external CAPTCHA solver integration.
+package captcha
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+const (
+	webhookDefaultTimeout = 120 * time.Second
+
+	// webhookMaxResponseSize caps the endpoint's response body.
+	webhookMaxResponseSize = 1 << 20
+)
+
+// WebhookSolver implements CaptchaSolver by POSTing the challenge parameters
+// to an operator-run endpoint (CAPTCHA_WEBHOOK_URL) and reading the token
+// from its response, so self-hosted or niche solving services can be plugged
+// in without code changes. The call is synchronous: the endpoint answers once
+// it has a token, within the solver timeout.
+//
+// Request body, one of type "turnstile", "hcaptcha", "recaptcha", "datadome" or "image":
+//
+//	{"type": "turnstile", "websiteURL": "...", "websiteKey": "...",
+//	 "userAgent": "...", "action": "...", "cData": "...", "pageData": "...",
+//	 "proxy": {"type": "http", "address": "...", "port": 8080, "login": "...", "password": "..."}}
+//
+// Response: {"token": "...", "cost": 0.001} on success, or {"error": "...",
+// "code": "..."}. HTTP 402 reports an exhausted balance.
+type WebhookSolver struct {
+	url        string
+	token      string
+	httpClient *http.Client
+}
+
+func init() {
+	Register("custom", func(url string, timeout time.Duration) CaptchaSolver {
+		return NewWebhookSolver(WebhookConfig{URL: url, Timeout: timeout})
+	})
+}
+
+// WebhookConfig contains configuration for the webhook solver.
+type WebhookConfig struct {
+	URL     string // Endpoint the challenge is POSTed to
+	Token   string // Optional bearer token sent in the Authorization header
+	Timeout time.Duration
+}
+
+// NewWebhookSolver creates a new webhook solver instance.
+func NewWebhookSolver(cfg WebhookConfig) *WebhookSolver {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = webhookDefaultTimeout
+	}
+	return &WebhookSolver{
+		url:        cfg.URL,
+		token:      cfg.Token,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the provider name.
+func (s *WebhookSolver) Name() string {
+	return "custom"
+}
+
+// IsConfigured returns true if the endpoint URL is set.
+func (s *WebhookSolver) IsConfigured() bool {
+	return s.url != ""
+}
+
+// webhookProxy is the proxy the challenge must be solved through.
+type webhookProxy struct {
+	Type     string `json:"type"` // "http", "https", "socks4" or "socks5"
+	Address  string `json:"address"`
+	Port     int    `json:"port"`
+	Login    string `json:"login,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// webhookRequest is the body POSTed to the endpoint. Fields unused by a
+// challenge type are omitted.
+type webhookRequest struct {
+	Type       string        `json:"type"`
+	WebsiteURL string        `json:"websiteURL"`
+	WebsiteKey string        `json:"websiteKey,omitempty"`
+	UserAgent  string        `json:"userAgent,omitempty"`
+	Action     string        `json:"action,omitempty"`
+	CData      string        `json:"cData,omitempty"`
+	PageData   string        `json:"pageData,omitempty"`
+	Invisible  bool          `json:"invisible,omitempty"`
+	V3         bool          `json:"v3,omitempty"`
+	CaptchaURL string        `json:"captchaURL,omitempty"`
+	Image      string        `json:"image,omitempty"` // Base64 image (image)
+	Proxy      *webhookProxy `json:"proxy,omitempty"`
+}
+
+// webhookResponse is the endpoint's answer.
+type webhookResponse struct {
+	Token string  `json:"token"`
+	Cost  float64 `json:"cost,omitempty"` // USD, reported to the provider cost metric
+	Error string  `json:"error,omitempty"`
+	Code  string  `json:"code,omitempty"`
+}
+
+// SolveTurnstile posts a Turnstile challenge to the endpoint.
+func (s *WebhookSolver) SolveTurnstile(ctx context.Context, req *TurnstileRequest) (*TurnstileResult, error) {
+	return s.solve(ctx, webhookRequest{
+		Type:       "turnstile",
+		WebsiteURL: req.PageURL,
+		WebsiteKey: req.SiteKey,
+		UserAgent:  req.UserAgent,
+		Action:     req.Action,
+		CData:      req.CData,
+		PageData:   req.PageData,
+		Proxy:      webhookProxyFor(req.Proxy),
+	})
+}
+
+// SolveHCaptcha posts an hCaptcha challenge to the endpoint.
+func (s *WebhookSolver) SolveHCaptcha(ctx context.Context, req *HCaptchaRequest) (*CaptchaResult, error) {
+	return s.solve(ctx, webhookRequest{
+		Type:       "hcaptcha",
+		WebsiteURL: req.PageURL,
+		WebsiteKey: req.SiteKey,
+		UserAgent:  req.UserAgent,
+	})
+}
+
+// SolveRecaptcha posts a reCAPTCHA v2 or v3 challenge to the endpoint.
+func (s *WebhookSolver) SolveRecaptcha(ctx context.Context, req *RecaptchaRequest) (*CaptchaResult, error) {
+	return s.solve(ctx, webhookRequest{
+		Type:       "recaptcha",
+		WebsiteURL: req.PageURL,
+		WebsiteKey: req.SiteKey,
+		UserAgent:  req.UserAgent,
+		Action:     req.Action,
+		Invisible:  req.Invisible,
+		V3:         req.V3,
+	})
+}
+
+// SolveDataDome posts a DataDome slider to the endpoint. The token returned
+// must be the datadome Set-Cookie string.
+func (s *WebhookSolver) SolveDataDome(ctx context.Context, req *DataDomeRequest) (*CaptchaResult, error) {
+	proxy := webhookProxyFor(req.Proxy)
+	if proxy == nil {
+		return nil, fmt.Errorf("DataDome solving requires a proxy")
+	}
+	return s.solve(ctx, webhookRequest{
+		Type:       "datadome",
+		WebsiteURL: req.PageURL,
+		CaptchaURL: req.CaptchaURL,
+		UserAgent:  req.UserAgent,
+		Proxy:      proxy,
+	})
+}
+
+// SolveImage posts a base64 image captcha to the endpoint. The token
+// returned must be the captcha's text.
+func (s *WebhookSolver) SolveImage(ctx context.Context, req *ImageRequest) (*CaptchaResult, error) {
+	return s.solve(ctx, webhookRequest{
+		Type:       "image",
+		WebsiteURL: req.PageURL,
+		Image:      req.body(),
+	})
+}
+
+// Balance is not reported by webhook endpoints.
+func (s *WebhookSolver) Balance(_ context.Context) (float64, error) {
+	return 0, fmt.Errorf("custom captcha provider does not report a balance")
+}
+
+// webhookProxyFor splits a request proxy into the fields sent to the
+// endpoint, or returns nil when there is none or it cannot be split.
+func webhookProxyFor(p *types.Proxy) *webhookProxy {
+	if p == nil || p.URL == "" {
+		return nil
+	}
+	proxy, err := parseTaskProxy(p)
+	if err != nil {
+		return nil
+	}
+	return &webhookProxy{
+		Type:     proxy.Type,
+		Address:  proxy.Address,
+		Port:     proxy.Port,
+		Login:    proxy.Login,
+		Password: proxy.Password,
+	}
+}
+
+// solve posts body to the endpoint and waits for the token.
+func (s *WebhookSolver) solve(ctx context.Context, body webhookRequest) (*CaptchaResult, error) {
+	if !s.IsConfigured() {
+		return nil, fmt.Errorf("custom captcha provider URL not configured")
+	}
+
+	startTime := time.Now()
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("type", body.Type).
+		Bool("proxy", body.Proxy != nil).
+		Msg("Posting challenge to custom captcha provider")
+
+	resp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || isTimeout(err) {
+			return nil, types.NewCaptchaTimeoutError(s.Name(), "")
+		}
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, webhookMaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var result webhookResponse
+	parseErr := json.Unmarshal(respBody, &result)
+
+	switch {
+	case resp.StatusCode == http.StatusPaymentRequired:
+		return nil, types.NewCaptchaBalanceError(s.Name())
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		msg := result.Error
+		if msg == "" {
+			msg = http.StatusText(resp.StatusCode)
+		}
+		return nil, s.rejected(result.Code, fmt.Sprintf("HTTP %d: %s", resp.StatusCode, msg))
+	case parseErr != nil:
+		return nil, fmt.Errorf("failed to parse response: %w", parseErr)
+	case result.Error != "":
+		return nil, s.rejected(result.Code, result.Error)
+	case result.Token == "":
+		return nil, fmt.Errorf("custom captcha provider returned no token")
+	}
+
+	return &CaptchaResult{
+		Token:     result.Token,
+		SolveTime: time.Since(startTime),
+		Cost:      result.Cost,
+		Provider:  s.Name(),
+	}, nil
+}
+
+// rejected returns the error for a failure the endpoint reported.
+func (s *WebhookSolver) rejected(code, msg string) error {
+	return &types.CaptchaError{
+		Provider: s.Name(),
+		Code:     code,
+		Message:  fmt.Sprintf("custom captcha provider error: %s", msg),
+		Err:      types.ErrCaptchaSolverRejected,
+	}
+}
+
+// isTimeout reports whether err is a network timeout.
+func isTimeout(err error) bool {
+	var t interface{ Timeout() bool }
+	return errors.As(err, &t) && t.Timeout()
+}
@@ -0,0 +1,101 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func TestWebhookSolver_SolveTurnstile(t *testing.T) {
+	var got webhookRequest
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		json.NewEncoder(w).Encode(webhookResponse{Token: "webhook-token", Cost: 0.001})
+	}))
+	defer server.Close()
+
+	solver := NewWebhookSolver(WebhookConfig{URL: server.URL, Token: "secret", Timeout: 5 * time.Second})
+	result, err := solver.SolveTurnstile(context.Background(), &TurnstileRequest{
+		SiteKey:   "0x4AAAAAAA",
+		PageURL:   "https://example.com",
+		UserAgent: "Mozilla/5.0",
+		PageData:  "chl-page-data",
+		Proxy:     &types.Proxy{URL: "http://proxy.example:8080", Username: "user", Password: "pass"},
+	})
+	if err != nil {
+		t.Fatalf("SolveTurnstile() error = %v", err)
+	}
+	if result.Token != "webhook-token" || result.Cost != 0.001 || result.Provider != "custom" {
+		t.Errorf("result = %+v, want webhook-token costing 0.001 from custom", result)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want Bearer secret", auth)
+	}
+	if got.Type != "turnstile" || got.WebsiteKey != "0x4AAAAAAA" || got.UserAgent != "Mozilla/5.0" || got.PageData != "chl-page-data" {
+		t.Errorf("request = %+v, want the Turnstile parameters", got)
+	}
+	if got.Proxy == nil || got.Proxy.Address != "proxy.example" || got.Proxy.Port != 8080 || got.Proxy.Login != "user" {
+		t.Errorf("proxy = %+v, want proxy.example:8080 as user", got.Proxy)
+	}
+}
+
+func TestWebhookSolver_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr error
+	}{
+		{name: "reported error", status: http.StatusOK, body: `{"error":"unsolvable","code":"UNSOLVABLE"}`, wantErr: types.ErrCaptchaSolverRejected},
+		{name: "server error", status: http.StatusBadGateway, body: `oops`, wantErr: types.ErrCaptchaSolverRejected},
+		{name: "payment required", status: http.StatusPaymentRequired, body: `{}`, wantErr: types.ErrCaptchaSolverBalance},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			solver := NewWebhookSolver(WebhookConfig{URL: server.URL})
+			_, err := solver.SolveHCaptcha(context.Background(), &HCaptchaRequest{SiteKey: "key", PageURL: "https://example.com"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookSolver_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	solver := NewWebhookSolver(WebhookConfig{URL: server.URL, Timeout: 50 * time.Millisecond})
+	_, err := solver.SolveTurnstile(context.Background(), &TurnstileRequest{SiteKey: "key", PageURL: "https://example.com"})
+	if !errors.Is(err, types.ErrCaptchaSolverTimeout) {
+		t.Errorf("error = %v, want timeout", err)
+	}
+}
+
+func TestWebhookSolver_DataDomeRequiresProxy(t *testing.T) {
+	solver := NewWebhookSolver(WebhookConfig{URL: "http://127.0.0.1:1"})
+	if _, err := solver.SolveDataDome(context.Background(), &DataDomeRequest{PageURL: "https://example.com"}); err == nil {
+		t.Error("expected an error without a proxy")
+	}
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces secret values in Dump output.
+const redacted = "[REDACTED]"
+
+// urlPassword matches the password of credentials embedded in a URL.
+var urlPassword = regexp.MustCompile(`(://[^/@\s:]+:)[^/@\s]+@`)
+
+// Adjustment is a setting Validate had to correct.
+type Adjustment struct {
+	Field string
+	From  string
+	To    string
+}
+
+// Adjustments compares a configuration before and after Validate and
+// returns the settings it corrected: clamped bounds, invalid values replaced
+// by defaults and features it turned off. Paths made absolute and values
+// that only changed case or surrounding spaces are not corrections.
+func Adjustments(before, after *Config) []Adjustment {
+	var list []Adjustment
+	b, a := reflect.ValueOf(before).Elem(), reflect.ValueOf(after).Elem()
+	for i := 0; i < b.NumField(); i++ {
+		if !b.Type().Field(i).IsExported() {
+			continue
+		}
+		from, to := b.Field(i).Interface(), a.Field(i).Interface()
+		if reflect.DeepEqual(from, to) {
+			continue
+		}
+		if f, ok := from.(string); ok {
+			t := to.(string)
+			if strings.EqualFold(strings.TrimSpace(f), t) {
+				continue
+			}
+			if abs, err := filepath.Abs(filepath.Clean(f)); f != "" && err == nil && abs == t {
+				continue
+			}
+		}
+		list = append(list, Adjustment{
+			Field: b.Type().Field(i).Name,
+			From:  formatDefault(b.Field(i).Interface()),
+			To:    formatDefault(a.Field(i).Interface()),
+		})
+	}
+	return list
+}
+
+// Dump writes the configuration, one "Field = value" line per setting, with
+// credentials and the passwords of URLs redacted.
+func (c *Config) Dump(w io.Writer) error {
+	clean := *c
+	for _, s := range clean.secretSettings() {
+		if *s.value != "" {
+			*s.value = redacted
+		}
+	}
+
+	v := reflect.ValueOf(clean)
+	width := 0
+	for i := 0; i < v.NumField(); i++ {
+		width = max(width, len(v.Type().Field(i).Name))
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		value := urlPassword.ReplaceAllString(formatDefault(v.Field(i).Interface()), "${1}"+redacted+"@")
+		if _, err := fmt.Fprintf(w, "%-*s = %s\n", width, v.Type().Field(i).Name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
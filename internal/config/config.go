@@ -2,15 +2,27 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/quiethours"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
 )
 
+// MaxBrowserPoolSize is the largest pool, at startup or via pool.resize.
+const MaxBrowserPoolSize = maxBrowserPoolSize
+
 // Configuration upper bounds to prevent resource exhaustion.
 const (
 	maxBrowserPoolSize = 20
@@ -21,6 +33,34 @@ const (
 	minAPIKeyLength    = 16    // Minimum API key length for security
 )
 
+// Session eviction policies applied when MaxSessions is reached.
+const (
+	SessionEvictionReject = "reject" // refuse new sessions (default)
+	SessionEvictionLRU    = "lru"    // destroy the least recently used idle session
+)
+
+// Browser pool modes (BROWSER_POOL_MODE).
+const (
+	BrowserPoolModeBrowser = "browser" // one Chrome process per pool slot (default)
+	BrowserPoolModeContext = "context" // incognito contexts on a few shared Chrome processes
+)
+
+// GPU rendering modes for launched browsers (GPU_MODE).
+const (
+	GPUModeAuto     = "auto"     // platform default: ANGLE, plus software compositing on ARM
+	GPUModeANGLE    = "angle"    // ANGLE over Vulkan with GPU compositing
+	GPUModeEGL      = "egl"      // native EGL (e.g. Mesa on ARM boards) with GPU compositing
+	GPUModeSoftware = "software" // SwiftShader WebGL and software compositing
+)
+
+// When pool browsers take a new identity from USER_AGENT_POOL_PATH
+// (USER_AGENT_ROTATION).
+const (
+	UserAgentRotationBrowser = "browser" // when a browser is spawned or recycled
+	UserAgentRotationSession = "session" // also when a session is created
+	UserAgentRotationRequest = "request" // also for every request without a session
+)
+
 // Config holds all application configuration.
 // Configuration is loaded from environment variables at startup.
 type Config struct {
@@ -28,23 +68,61 @@ type Config struct {
 	Host string
 	Port int
 
+	// ResponseCompression brotli- or gzip-encodes API responses for clients
+	// that accept it (RESPONSE_COMPRESSION); responses shorter than
+	// ResponseCompressionMinSize bytes are sent as is
+	// (RESPONSE_COMPRESSION_MIN_SIZE)
+	ResponseCompression        bool
+	ResponseCompressionMinSize int
+
+	// BufferPoolMaxBufferKB is the largest request/response buffer, in KB,
+	// kept for reuse; larger ones are discarded (BUFFER_POOL_MAX_BUFFER_KB)
+	BufferPoolMaxBufferKB int
+
 	// Browser settings
-	Headless    bool
-	BrowserPath string
+	Headless            bool
+	BrowserPath         string
+	UserAgentPoolPath   string // USER_AGENT_POOL_PATH — YAML/JSON list of weighted UAs rotated across pool browsers
+	UserAgentRotation   string // USER_AGENT_ROTATION — browser, session or request
+	RemoteBrowserURLs   string // REMOTE_BROWSER_URLS — DevTools endpoints the pool connects to instead of launching Chrome
+	GPUMode             string // GPU_MODE — auto, angle, egl or software
+	BrowserProfilesPath string // BROWSER_PROFILES_PATH — YAML/JSON list of named launch profiles selectable per request
+	// BrowserProfileDataDir holds a user-data-dir per persistent browser
+	// profile (BROWSER_PROFILE_DATA_DIR); mount it on a volume to keep them
+	BrowserProfileDataDir string
 
 	// Pool settings - CRITICAL for memory efficiency
 	BrowserPoolSize    int
 	BrowserPoolTimeout time.Duration
 	MaxMemoryMB        int
+	BrowserPoolMode    string // BROWSER_POOL_MODE — browser or context
+	ContextPoolHosts   int    // CONTEXT_POOL_HOSTS — Chrome processes hosting contexts in context mode
+	PageReuseEnabled   bool   // PAGE_REUSE_ENABLED — keep a reset tab per pooled browser for the next request
+
+	// Per-proxy sub-pools keep browsers launched for a per-request proxy warm
+	// for the next request through the same proxy
+	ProxyPoolSize        int           // PROXY_POOL_SIZE — idle browsers kept per proxy URL (0 disables)
+	ProxyPoolMaxIdle     int           // PROXY_POOL_MAX_IDLE — idle browsers kept across all proxies
+	ProxyPoolIdleTimeout time.Duration // PROXY_POOL_IDLE_TIMEOUT — idle time before a proxy browser is closed
 
 	// Session settings
 	SessionTTL             time.Duration
 	SessionCleanupInterval time.Duration
 	MaxSessions            int
+	SessionEvictionPolicy  string // SESSION_EVICTION_POLICY — reject or lru when MaxSessions is reached
+	SessionPersistDir      string // SESSION_PERSIST_DIR — directory for session snapshots restored across restarts
+	SessionRedisURL        string // SESSION_REDIS_URL — shared Redis session store (takes priority over SESSION_PERSIST_DIR)
+	SessionRedisPrefix     string // SESSION_REDIS_PREFIX — key prefix for session snapshots in Redis
+
+	// Session proxy rotation: after SessionProxyRotateAfter consecutive
+	// access_denied solves, a session moves to the next proxy in the list
+	SessionProxyRotation    string // SESSION_PROXY_ROTATION — comma/newline-separated proxy URLs
+	SessionProxyRotateAfter int    // SESSION_PROXY_ROTATE_AFTER — consecutive denials before rotating
 
 	// Clearance cache (Layer-2 of the clean-egress path)
 	ClearanceCacheEnabled bool          // Reuse minted cf_clearance across requests (CLEARANCE_CACHE_ENABLED)
 	ClearanceTTL          time.Duration // Max lifetime of a cached cf_clearance (CLEARANCE_TTL)
+	ClearanceFastPath     bool          // Fetch plain GETs over HTTP on clearance cache hits (CLEARANCE_FAST_PATH)
 
 	// Timeouts
 	DefaultTimeout time.Duration
@@ -54,11 +132,44 @@ type Config struct {
 	// Fix #32: Note - Proxy credentials are stored in plaintext in memory
 	// for compatibility with proxy libraries. Consider using environment
 	// variables that are cleared after reading if security is critical.
-	ProxyURL      string
-	ProxyUsername string
-	ProxyPassword string
-	ProxyList     string // Pool of egress proxies, comma/newline-separated (PROXY_LIST)
-	ProxyStrategy string // Egress selection: sticky-domain|round-robin|per-request (PROXY_STRATEGY)
+	ProxyURL       string
+	ProxyUsername  string
+	ProxyPassword  string
+	ProxyList      string // Pool of egress proxies, comma/newline-separated (PROXY_LIST)
+	ProxyStrategy  string // Egress selection: sticky-domain|round-robin|per-request (PROXY_STRATEGY)
+	ProxyPoolsPath string // PROXY_POOLS_PATH — YAML/JSON list of named proxy pools selectable per request
+	ProxyRoutes    string // PROXY_ROUTES — per-domain "pattern=proxy" or "pattern=pool:name" rules, comma/newline-separated
+
+	// System proxy for browsers launched without a proxy of their own
+	ProxyPACURL  string // PROXY_PAC_URL — PAC script URL or file path, evaluated by Chrome per request
+	ProxyFromEnv bool   // PROXY_FROM_ENV — honor HTTP_PROXY/HTTPS_PROXY/ALL_PROXY/NO_PROXY
+
+	// ProxyFailoverEnabled retries a sessionless request once through another
+	// proxy of its pool (or PROXY_LIST) when the target denies access (PROXY_FAILOVER_ENABLED)
+	ProxyFailoverEnabled bool
+
+	// ProxyStickySessions adds a provider session parameter to the proxy
+	// username of each sessions.create so the session keeps one exit IP (PROXY_STICKY_SESSIONS)
+	ProxyStickySessions bool
+
+	// Background proxy health checks: dead proxies leave rotation
+	ProxyHealthCheckEnabled bool          // PROXY_HEALTH_CHECK_ENABLED
+	ProxyHealthInterval     time.Duration // PROXY_HEALTH_CHECK_INTERVAL — time between rounds
+	ProxyHealthTimeout      time.Duration // PROXY_HEALTH_CHECK_TIMEOUT — bound on each probe request
+	ProxyHealthIPURL        string        // PROXY_HEALTH_IP_URL — returns the caller's IP
+	ProxyHealthCanaryURL    string        // PROXY_HEALTH_CANARY_URL — Cloudflare-protected page for the reputation check
+	ProxyHealthFailures     int           // PROXY_HEALTH_FAIL_THRESHOLD — consecutive failures before a proxy is dead
+
+	// ProxyVerifyURL is the IP-echo endpoint loaded by verifyProxy requests (PROXY_VERIFY_URL)
+	ProxyVerifyURL string
+
+	// QuietHours lists per-domain windows during which targets must not be
+	// contacted (QUIET_HOURS, e.g. "example.com=sat-sun 00:00-06:00 Europe/London")
+	QuietHours string
+
+	// DomainOverridesPath is a YAML/JSON list of per-domain solve settings
+	// applied where a request leaves them unset (DOMAIN_OVERRIDES_PATH)
+	DomainOverridesPath string
 
 	// Browser locale/timezone
 	BrowserTimezone string // TZ env var — sets browser timezone (e.g., "America/New_York")
@@ -66,16 +177,111 @@ type Config struct {
 	TestURL         string // TEST_URL — URL to verify browser works on startup (default: https://www.google.com)
 	DisableMedia    bool   // DISABLE_MEDIA — global default for blocking images/CSS/fonts
 
+	// ClientRedirectSettle is how long to watch for meta-refresh/JS redirects
+	// after clearance (CLIENT_REDIRECT_SETTLE, 0 = don't follow)
+	ClientRedirectSettle time.Duration
+
+	// XHRChallengeWatch is how long to watch the cleared page's XHR/fetch
+	// responses for a second-order challenge (XHR_CHALLENGE_WATCH, 0 = off)
+	XHRChallengeWatch time.Duration
+
+	// Stale fallback for hard-blocked request.get calls that set allowCacheFallback
+	CacheFallbackEnabled bool          // CACHE_FALLBACK_ENABLED — allow fetching archived copies
+	CacheFallbackTimeout time.Duration // CACHE_FALLBACK_TIMEOUT — bound on the archive lookup and download
+
+	// Short-lived cache of solved plain GETs, so bursts of identical requests share one solve
+	ResultCacheEnabled    bool          // RESULT_CACHE_ENABLED
+	ResultCacheTTL        time.Duration // RESULT_CACHE_TTL — how long a solved result is served again
+	ResultCacheMaxEntries int           // RESULT_CACHE_MAX_ENTRIES — bound on cached results
+
+	// Limits on what one solve returns; requests may override them up to the
+	// hard ceilings in types
+	MaxResponseSizeKB   int // MAX_RESPONSE_SIZE_KB — page HTML beyond this is truncated
+	MaxScreenshotSizeKB int // MAX_SCREENSHOT_SIZE_KB — larger screenshots are dropped
+	MaxResponseCookies  int // MAX_RESPONSE_COOKIES — cookies returned per solve
+	MaxStorageItems     int // MAX_STORAGE_ITEMS — localStorage/sessionStorage items returned, each
+	MaxStorageSizeKB    int // MAX_STORAGE_SIZE_KB — larger localStorage/sessionStorage is dropped
+
+	// Challenge poll strategy: how the solve loop paces detection passes
+	PollStrategy        string // POLL_STRATEGY — random (default), fixed or event
+	PollStrategyDomains string // POLL_STRATEGY_DOMAINS — per-domain overrides, "domain=strategy" comma/newline-separated
+
+	PollIntervalMin         time.Duration // POLL_INTERVAL_MIN — shortest pause of the random strategy
+	PollIntervalMax         time.Duration // POLL_INTERVAL_MAX — longest pause of the random strategy
+	PollMaxAttempts         int           // POLL_MAX_ATTEMPTS — cap on detection passes per solve, 0 sizes them to the timeout
+	SelectorDetectionBudget time.Duration // SELECTOR_DETECTION_BUDGET — time one pass may spend checking challenge selectors
+
+	// FeatureFlags switches experimental behaviors deployment-wide or per
+	// domain (FEATURE_FLAGS, e.g. "turnstile.positional=off,turnstile.shadow@example.com=on")
+	FeatureFlags string
+
 	// Logging
 	LogLevel string
 	LogHTML  bool
 	LogFile  string // LOG_FILE — path to log file (in addition to stdout)
 
+	LogFormat         string        // LOG_FORMAT — "console" (human-readable) or "json" on stdout
+	LogFileMaxSizeMB  int           // LOG_FILE_MAX_SIZE_MB — size at which LOG_FILE is rotated, 0 disables
+	LogFileMaxAge     time.Duration // LOG_FILE_MAX_AGE — age at which LOG_FILE is rotated, 0 disables
+	LogFileMaxBackups int           // LOG_FILE_MAX_BACKUPS — rotated files kept
+
+	// Live log streaming (GET /logs/stream); requires API key authentication
+	LogStreamEnabled        bool // LOG_STREAM_ENABLED
+	LogStreamMaxSubscribers int  // LOG_STREAM_MAX_SUBSCRIBERS — concurrent stream connections
+
+	// Live operational event stream (GET /admin/events); requires API key authentication
+	EventStreamEnabled        bool // EVENT_STREAM_ENABLED
+	EventStreamMaxSubscribers int  // EVENT_STREAM_MAX_SUBSCRIBERS — concurrent stream connections
+
+	// Audit log: one JSON record per API request, independent of LOG_LEVEL
+	AuditLogFile       string // AUDIT_LOG_FILE — path of the audit file
+	AuditLogMaxSizeMB  int    // AUDIT_LOG_MAX_SIZE_MB — size at which the file is rotated
+	AuditLogMaxBackups int    // AUDIT_LOG_MAX_BACKUPS — rotated files kept
+	AuditLogSyslog     string // AUDIT_LOG_SYSLOG — "local", udp://host:port or tcp://host:port
+
+	// OpenTelemetry tracing, exported over OTLP/HTTP
+	TracingEnabled     bool    // TRACING_ENABLED
+	TracingEndpoint    string  // TRACING_ENDPOINT — full traces URL; defaults to the OTEL_EXPORTER_OTLP_* variables
+	TracingSampleRatio float64 // TRACING_SAMPLE_RATIO — fraction of new traces recorded (0-1)
+
+	// Request tags: keys exported as metric labels, with a per-key cap on
+	// distinct values to keep label cardinality bounded
+	MetricsTagKeys      []string // METRICS_TAG_KEYS — comma-separated tag keys
+	MetricsTagMaxValues int      // METRICS_TAG_MAX_VALUES — distinct values per key before folding into "_other"
+
+	// Per-domain metrics for the busiest domains, the rest labelled "other"
+	MetricsDomainTopN int // METRICS_DOMAIN_TOP_N — 0 disables per-domain series
+
 	// Profiling
 	PProfEnabled  bool
 	PProfPort     int
 	PProfBindAddr string // Bind address for pprof server (default: localhost only)
 
+	// Admin web dashboard on its own listener, behind HTTP basic auth
+	AdminEnabled  bool   // ADMIN_ENABLED
+	AdminPort     int    // ADMIN_PORT
+	AdminBindAddr string // ADMIN_BIND_ADDR — localhost only by default
+	AdminUsername string // ADMIN_USERNAME
+	AdminPassword string // ADMIN_PASSWORD — required, the dashboard stays off without it
+
+	// Source address allowlists (CIDRs or IPs); empty admits every source
+	APIAllowedCIDRs     []string // API_ALLOWED_CIDRS — main listener, /health exempt
+	MetricsAllowedCIDRs []string // METRICS_ALLOWED_CIDRS — replaces API_ALLOWED_CIDRS for /metrics
+	PProfAllowedCIDRs   []string // PPROF_ALLOWED_CIDRS — pprof listener
+	AdminAllowedCIDRs   []string // ADMIN_ALLOWED_CIDRS — admin dashboard listener
+
+	// Native HTTPS for the API and pprof listeners: a certificate reloaded
+	// when its files change, or one obtained from an ACME CA
+	TLSCertFile         string   // TLS_CERT_FILE — PEM certificate chain
+	TLSKeyFile          string   // TLS_KEY_FILE — PEM private key
+	TLSACMEDomains      []string // TLS_ACME_DOMAINS — hostnames to obtain certificates for
+	TLSACMEEmail        string   // TLS_ACME_EMAIL — contact address for the ACME account
+	TLSACMECacheDir     string   // TLS_ACME_CACHE_DIR — where ACME accounts and certificates are kept
+	TLSACMEDirectoryURL string   // TLS_ACME_DIRECTORY_URL — ACME directory, Let's Encrypt by default
+	TLSACMEHTTPAddr     string   // TLS_ACME_HTTP_ADDR — listener for HTTP-01 challenges, e.g. ":80"
+	TLSClientCAFile     string   // TLS_CLIENT_CA_FILE — PEM CA bundle API clients' certificates must chain to
+	TLSClientAuth       string   // TLS_CLIENT_AUTH — require (every endpoint but /health) or optional
+
 	// Security
 	RateLimitEnabled   bool
 	RateLimitRPM       int      // Requests per minute per IP
@@ -84,15 +290,37 @@ type Config struct {
 	CORSAllowedOrigins []string // Allowed CORS origins (empty = allow all with warning)
 	AllowLocalProxies  bool     // Allow localhost/private IP proxies (default: true for backward compatibility)
 
+	// SSRF exceptions: private targets the operator trusts, e.g. QA
+	// environments on RFC 1918 addresses. Cloud metadata stays blocked.
+	SSRFAllowedCIDRs []string // SSRF_ALLOWED_CIDRS — CIDRs or IPs target URLs may resolve to
+	SSRFAllowedHosts []string // SSRF_ALLOWED_HOSTS — hosts ("qa.corp", "*.qa.corp") exempt from the private IP checks
+
 	// DNSRebindingProtection pins the response URL to the IP resolved at request
 	// time. Disable (DNS_REBINDING_PROTECTION=false) for sites that legitimately
 	// serve identical content across multiple TLDs/CDN IPs. SSRF protection
 	// (blocking private/internal/metadata IPs) stays active either way.
 	DNSRebindingProtection bool
 
+	// EvaluateJsEnabled allows requests to run evaluateJs scripts in the
+	// solved page and return their result (EVALUATE_JS_ENABLED, default off)
+	EvaluateJsEnabled bool
+
+	// RecordSolveDir is where recordSolve screencasts are written, including
+	// those of failed solves (RECORD_SOLVE_DIR). Empty returns them in the
+	// response instead.
+	RecordSolveDir string
+
 	// API Key Authentication
 	APIKeyEnabled bool   // Enable API key authentication
 	APIKey        string // Required API key for requests (only used if APIKeyEnabled is true)
+	APIKeysFile   string // API_KEYS_FILE — YAML/JSON list of additional keys scoped to commands
+
+	// Target domain policy: domain patterns requests may (allowlist) or may
+	// not (denylist) target, from the variable and/or a file of one pattern per line
+	TargetAllowlist     string // TARGET_ALLOWLIST
+	TargetDenylist      string // TARGET_DENYLIST
+	TargetAllowlistFile string // TARGET_ALLOWLIST_FILE
+	TargetDenylistFile  string // TARGET_DENYLIST_FILE
 
 	// CAPTCHA Solver settings
 	CaptchaNativeAttempts    int           // Native solve attempts before external fallback (default: 3)
@@ -100,56 +328,133 @@ type Config struct {
 	Captcha2CaptchaAPIKey    string        // 2Captcha API key (TWOCAPTCHA_API_KEY)
 	CaptchaCapSolverAPIKey   string        // CapSolver API key (CAPSOLVER_API_KEY)
 	CaptchaAntiCaptchaAPIKey string        // anti-captcha.com API key (ANTICAPTCHA_API_KEY)
+	CaptchaCapMonsterAPIKey  string        // CapMonster Cloud API key (CAPMONSTER_API_KEY)
+	CaptchaWebhookURL        string        // Endpoint of the "custom" webhook provider (CAPTCHA_WEBHOOK_URL)
+	CaptchaWebhookToken      string        // Bearer token for the webhook provider (CAPTCHA_WEBHOOK_TOKEN)
 	Captcha9kwAPIKey         string        // 9kw.eu API key (NINEKW_API_KEY) — hCaptcha/reCAPTCHA only, no Turnstile
-	CaptchaPrimaryProvider   string        // Primary provider: "2captcha", "capsolver", "anticaptcha", or "9kw" (default: "2captcha")
+	CaptchaPrimaryProvider   string        // Primary provider: "2captcha", "capsolver", "anticaptcha", "capmonster", "9kw", or "custom" (default: "2captcha")
+	CaptchaProviderOrder     []string      // Providers to use, in order (CAPTCHA_PROVIDER_ORDER); overrides CaptchaPrimaryProvider when set
 	CaptchaSolverTimeout     time.Duration // Timeout for external solver API (default: 120s)
+	CaptchaLowBalance        float64       // Balance below which captcha.status flags a provider (CAPTCHA_LOW_BALANCE, default: 1.0)
+	CaptchaRaceThreshold     float64       // Native success rate below which external solving races native (CAPTCHA_RACE_THRESHOLD, 0 disables)
+	CaptchaBreakerErrorRate  float64       // Provider failure share that opens its circuit breaker (CAPTCHA_BREAKER_ERROR_RATE, default: 0.5, 0 disables)
+	CaptchaBreakerSlowCall   time.Duration // Solves slower than this count as breaker failures (CAPTCHA_BREAKER_SLOW_CALL, default: 90s, 0 disables)
+	CaptchaBreakerCooldown   time.Duration // How long an open breaker skips its provider (CAPTCHA_BREAKER_COOLDOWN, default: 60s)
+	CaptchaBudgetDaily       float64       // Global spend cap in USD per UTC day, 0 for none (CAPTCHA_BUDGET_DAILY)
+	CaptchaBudgetMonthly     float64       // Global spend cap in USD per UTC month, 0 for none (CAPTCHA_BUDGET_MONTHLY)
+	CaptchaProviderBudgets   string        // Per-provider caps, "provider:daily=USD,..." (CAPTCHA_PROVIDER_BUDGETS)
 
 	// Selectors settings
 	SelectorsPath          string        // Path to external selectors.yaml override file
 	SelectorsHotReload     bool          // Enable file watching for hot-reload of selectors
 	SelectorsRemoteURL     string        // HTTP(S) URL to fetch selectors from
 	SelectorsRemoteRefresh time.Duration // Refresh interval for remote selectors (default: 1h)
+	// SelectorsRemotePublicKey is the base64 Ed25519 public key remote
+	// selectors must be signed with (SELECTORS_REMOTE_PUBLIC_KEY)
+	SelectorsRemotePublicKey string
 
 	// Dashboard
 	DashboardEnabled bool // TUI dashboard enabled by default; disable with DASHBOARD_ENABLED=false
 }
 
-// Load loads configuration from environment variables.
+// Load loads configuration from environment variables and CONFIG_FILE.
 // Returns a Config with values from environment or sensible defaults.
 func Load() *Config {
+	if err := loadConfigFile(); err != nil {
+		log.Warn().Err(err).Msg("Ignoring CONFIG_FILE, using the environment only")
+	}
+	return load()
+}
+
+// Reload re-reads CONFIG_FILE and the environment. Unlike Load it fails when
+// CONFIG_FILE cannot be read, so a broken edit never replaces a working
+// configuration.
+func Reload() (*Config, error) {
+	if err := loadConfigFile(); err != nil {
+		return nil, err
+	}
+	return load(), nil
+}
+
+func load() *Config {
 	return &Config{
 		// Server - default to localhost for security (prevents accidental exposure)
 		// Set HOST=0.0.0.0 explicitly to bind to all interfaces
 		Host: getEnvString("HOST", "127.0.0.1"),
 		Port: getEnvInt("PORT", 8191),
 
+		ResponseCompression:        getEnvBool("RESPONSE_COMPRESSION", false),
+		ResponseCompressionMinSize: getEnvInt("RESPONSE_COMPRESSION_MIN_SIZE", 1024),
+		BufferPoolMaxBufferKB:      getEnvInt("BUFFER_POOL_MAX_BUFFER_KB", 64),
+
 		// Browser
-		Headless:    getEnvBool("HEADLESS", true),
-		BrowserPath: getEnvString("BROWSER_PATH", ""),
+		Headless:            getEnvBool("HEADLESS", true),
+		BrowserPath:         getEnvString("BROWSER_PATH", ""),
+		UserAgentPoolPath:   getEnvString("USER_AGENT_POOL_PATH", ""),
+		UserAgentRotation:   getEnvString("USER_AGENT_ROTATION", UserAgentRotationBrowser),
+		RemoteBrowserURLs:   getEnvString("REMOTE_BROWSER_URLS", ""),
+		GPUMode:             getEnvString("GPU_MODE", GPUModeAuto),
+		BrowserProfilesPath: getEnvString("BROWSER_PROFILES_PATH", ""),
+
+		BrowserProfileDataDir: getEnvString("BROWSER_PROFILE_DATA_DIR", ""),
 
 		// Pool - These defaults are tuned for memory efficiency
 		BrowserPoolSize:    getEnvInt("BROWSER_POOL_SIZE", 3),
 		BrowserPoolTimeout: getEnvDuration("BROWSER_POOL_TIMEOUT", 30*time.Second),
 		MaxMemoryMB:        getEnvInt("MAX_MEMORY_MB", 2048),
+		BrowserPoolMode:    getEnvString("BROWSER_POOL_MODE", BrowserPoolModeBrowser),
+		PageReuseEnabled:   getEnvBool("PAGE_REUSE_ENABLED", false),
+		ContextPoolHosts:   getEnvInt("CONTEXT_POOL_HOSTS", 2),
+
+		ProxyPoolSize:        getEnvInt("PROXY_POOL_SIZE", 1),
+		ProxyPoolMaxIdle:     getEnvInt("PROXY_POOL_MAX_IDLE", 4),
+		ProxyPoolIdleTimeout: getEnvDuration("PROXY_POOL_IDLE_TIMEOUT", 2*time.Minute),
 
 		// Sessions
 		SessionTTL:             getEnvDuration("SESSION_TTL", 30*time.Minute),
 		SessionCleanupInterval: getEnvDuration("SESSION_CLEANUP_INTERVAL", 1*time.Minute),
 		MaxSessions:            getEnvInt("MAX_SESSIONS", 100),
+		SessionEvictionPolicy:  getEnvString("SESSION_EVICTION_POLICY", SessionEvictionReject),
+		SessionPersistDir:      getEnvString("SESSION_PERSIST_DIR", ""),
+		SessionRedisURL:        getEnvString("SESSION_REDIS_URL", ""),
+		SessionRedisPrefix:     getEnvString("SESSION_REDIS_PREFIX", "flaresolverr:session:"),
+
+		SessionProxyRotation:    getEnvString("SESSION_PROXY_ROTATION", ""),
+		SessionProxyRotateAfter: getEnvInt("SESSION_PROXY_ROTATE_AFTER", 3),
 
 		ClearanceCacheEnabled: getEnvBool("CLEARANCE_CACHE_ENABLED", true),
 		ClearanceTTL:          getEnvDuration("CLEARANCE_TTL", 25*time.Minute),
+		ClearanceFastPath:     getEnvBool("CLEARANCE_FAST_PATH", false),
 
 		// Timeouts
 		DefaultTimeout: getEnvDuration("DEFAULT_TIMEOUT", 60*time.Second),
 		MaxTimeout:     getEnvDuration("MAX_TIMEOUT", 300*time.Second),
 
 		// Proxy
-		ProxyURL:      getEnvString("PROXY_URL", ""),
-		ProxyUsername: getEnvString("PROXY_USERNAME", ""),
-		ProxyPassword: getEnvString("PROXY_PASSWORD", ""),
-		ProxyList:     getEnvString("PROXY_LIST", ""),
-		ProxyStrategy: getEnvString("PROXY_STRATEGY", "sticky-domain"),
+		ProxyURL:       getEnvString("PROXY_URL", ""),
+		ProxyUsername:  getEnvString("PROXY_USERNAME", ""),
+		ProxyPassword:  getEnvString("PROXY_PASSWORD", ""),
+		ProxyList:      getEnvString("PROXY_LIST", ""),
+		ProxyStrategy:  getEnvString("PROXY_STRATEGY", "sticky-domain"),
+		ProxyPoolsPath: getEnvString("PROXY_POOLS_PATH", ""),
+		ProxyRoutes:    getEnvString("PROXY_ROUTES", ""),
+		ProxyPACURL:    getEnvString("PROXY_PAC_URL", ""),
+		ProxyFromEnv:   getEnvBool("PROXY_FROM_ENV", false),
+
+		ProxyFailoverEnabled: getEnvBool("PROXY_FAILOVER_ENABLED", true),
+		ProxyStickySessions:  getEnvBool("PROXY_STICKY_SESSIONS", true),
+
+		ProxyHealthCheckEnabled: getEnvBool("PROXY_HEALTH_CHECK_ENABLED", false),
+		ProxyHealthInterval:     getEnvDuration("PROXY_HEALTH_CHECK_INTERVAL", 5*time.Minute),
+		ProxyHealthTimeout:      getEnvDuration("PROXY_HEALTH_CHECK_TIMEOUT", 15*time.Second),
+		ProxyHealthIPURL:        getEnvString("PROXY_HEALTH_IP_URL", "https://api.ipify.org"),
+		ProxyHealthCanaryURL:    getEnvString("PROXY_HEALTH_CANARY_URL", ""),
+		ProxyHealthFailures:     getEnvInt("PROXY_HEALTH_FAIL_THRESHOLD", 2),
+		ProxyVerifyURL:          getEnvString("PROXY_VERIFY_URL", "https://api.ipify.org"),
+
+		QuietHours: getEnvString("QUIET_HOURS", ""),
+
+		DomainOverridesPath: getEnvString("DOMAIN_OVERRIDES_PATH", ""),
 
 		// Browser locale/timezone
 		BrowserTimezone: getEnvTimezone("TZ", ""),
@@ -157,16 +462,87 @@ func Load() *Config {
 		TestURL:         getEnvString("TEST_URL", "https://www.google.com"),
 		DisableMedia:    getEnvBool("DISABLE_MEDIA", false),
 
+		ClientRedirectSettle: getEnvDuration("CLIENT_REDIRECT_SETTLE", 0),
+		XHRChallengeWatch:    getEnvDuration("XHR_CHALLENGE_WATCH", 0),
+
+		CacheFallbackEnabled: getEnvBool("CACHE_FALLBACK_ENABLED", true),
+		CacheFallbackTimeout: getEnvDuration("CACHE_FALLBACK_TIMEOUT", 20*time.Second),
+
+		ResultCacheEnabled:    getEnvBool("RESULT_CACHE_ENABLED", false),
+		ResultCacheTTL:        getEnvDuration("RESULT_CACHE_TTL", 30*time.Second),
+		ResultCacheMaxEntries: getEnvInt("RESULT_CACHE_MAX_ENTRIES", 256),
+
+		MaxResponseSizeKB:   getEnvInt("MAX_RESPONSE_SIZE_KB", 10*1024),
+		MaxScreenshotSizeKB: getEnvInt("MAX_SCREENSHOT_SIZE_KB", 5*1024),
+		MaxResponseCookies:  getEnvInt("MAX_RESPONSE_COOKIES", 100),
+		MaxStorageItems:     getEnvInt("MAX_STORAGE_ITEMS", 100),
+		MaxStorageSizeKB:    getEnvInt("MAX_STORAGE_SIZE_KB", 1024),
+
+		PollStrategy:        getEnvString("POLL_STRATEGY", "random"),
+		PollStrategyDomains: getEnvString("POLL_STRATEGY_DOMAINS", ""),
+
+		PollIntervalMin:         getEnvDuration("POLL_INTERVAL_MIN", 800*time.Millisecond),
+		PollIntervalMax:         getEnvDuration("POLL_INTERVAL_MAX", 1500*time.Millisecond),
+		PollMaxAttempts:         getEnvInt("POLL_MAX_ATTEMPTS", 0),
+		SelectorDetectionBudget: getEnvDuration("SELECTOR_DETECTION_BUDGET", 5*time.Second),
+
+		FeatureFlags: getEnvString("FEATURE_FLAGS", ""),
+
 		// Logging
 		LogLevel: getEnvString("LOG_LEVEL", "info"),
 		LogHTML:  getEnvBool("LOG_HTML", false),
 		LogFile:  getEnvString("LOG_FILE", ""),
 
+		LogFormat:         strings.ToLower(getEnvString("LOG_FORMAT", "console")),
+		LogFileMaxSizeMB:  getEnvInt("LOG_FILE_MAX_SIZE_MB", 100),
+		LogFileMaxAge:     getEnvDuration("LOG_FILE_MAX_AGE", 0),
+		LogFileMaxBackups: getEnvInt("LOG_FILE_MAX_BACKUPS", 5),
+
+		LogStreamEnabled:        getEnvBool("LOG_STREAM_ENABLED", false),
+		LogStreamMaxSubscribers: getEnvInt("LOG_STREAM_MAX_SUBSCRIBERS", 5),
+
+		EventStreamEnabled:        getEnvBool("EVENT_STREAM_ENABLED", false),
+		EventStreamMaxSubscribers: getEnvInt("EVENT_STREAM_MAX_SUBSCRIBERS", 5),
+
+		AuditLogFile:       getEnvString("AUDIT_LOG_FILE", ""),
+		AuditLogMaxSizeMB:  getEnvInt("AUDIT_LOG_MAX_SIZE_MB", 100),
+		AuditLogMaxBackups: getEnvInt("AUDIT_LOG_MAX_BACKUPS", 5),
+		AuditLogSyslog:     getEnvString("AUDIT_LOG_SYSLOG", ""),
+
+		TracingEnabled:     getEnvBool("TRACING_ENABLED", false),
+		TracingEndpoint:    getEnvString("TRACING_ENDPOINT", ""),
+		TracingSampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", 1.0),
+
+		MetricsTagKeys:      getEnvStringSlice("METRICS_TAG_KEYS", []string{"app", "team"}),
+		MetricsTagMaxValues: getEnvInt("METRICS_TAG_MAX_VALUES", 50),
+		MetricsDomainTopN:   getEnvInt("METRICS_DOMAIN_TOP_N", 20),
+
 		// Profiling - disabled by default for security
 		PProfEnabled:  getEnvBool("PPROF_ENABLED", false),
 		PProfPort:     getEnvInt("PPROF_PORT", 6060),
 		PProfBindAddr: getEnvString("PPROF_BIND_ADDR", "127.0.0.1"), // Localhost only by default
 
+		AdminEnabled:  getEnvBool("ADMIN_ENABLED", false),
+		AdminPort:     getEnvInt("ADMIN_PORT", 8192),
+		AdminBindAddr: getEnvString("ADMIN_BIND_ADDR", "127.0.0.1"),
+		AdminUsername: getEnvString("ADMIN_USERNAME", "admin"),
+		AdminPassword: getEnvString("ADMIN_PASSWORD", ""),
+
+		APIAllowedCIDRs:     getEnvStringSlice("API_ALLOWED_CIDRS", nil),
+		MetricsAllowedCIDRs: getEnvStringSlice("METRICS_ALLOWED_CIDRS", nil),
+		PProfAllowedCIDRs:   getEnvStringSlice("PPROF_ALLOWED_CIDRS", nil),
+		AdminAllowedCIDRs:   getEnvStringSlice("ADMIN_ALLOWED_CIDRS", nil),
+
+		TLSCertFile:         getEnvString("TLS_CERT_FILE", ""),
+		TLSKeyFile:          getEnvString("TLS_KEY_FILE", ""),
+		TLSACMEDomains:      getEnvStringSlice("TLS_ACME_DOMAINS", nil),
+		TLSACMEEmail:        getEnvString("TLS_ACME_EMAIL", ""),
+		TLSACMECacheDir:     getEnvString("TLS_ACME_CACHE_DIR", "acme-cache"),
+		TLSACMEDirectoryURL: getEnvString("TLS_ACME_DIRECTORY_URL", ""),
+		TLSACMEHTTPAddr:     getEnvString("TLS_ACME_HTTP_ADDR", ""),
+		TLSClientCAFile:     getEnvString("TLS_CLIENT_CA_FILE", ""),
+		TLSClientAuth:       getEnvString("TLS_CLIENT_AUTH", "require"),
+
 		// Security
 		RateLimitEnabled:   getEnvBool("RATE_LIMIT_ENABLED", true),
 		RateLimitRPM:       getEnvInt("RATE_LIMIT_RPM", 60), // 60 requests per minute per IP
@@ -174,12 +550,23 @@ func Load() *Config {
 		IgnoreCertErrors:   getEnvBool("IGNORE_CERT_ERRORS", false),
 		CORSAllowedOrigins: getEnvStringSlice("CORS_ALLOWED_ORIGINS", nil),
 		AllowLocalProxies:  getEnvBool("ALLOW_LOCAL_PROXIES", false), // Default false for security
+		SSRFAllowedCIDRs:   getEnvStringSlice("SSRF_ALLOWED_CIDRS", nil),
+		SSRFAllowedHosts:   getEnvStringSlice("SSRF_ALLOWED_HOSTS", nil),
 
 		DNSRebindingProtection: getEnvBool("DNS_REBINDING_PROTECTION", true), // Default true for security
+		EvaluateJsEnabled:      getEnvBool("EVALUATE_JS_ENABLED", false),
+		RecordSolveDir:         getEnvString("RECORD_SOLVE_DIR", ""),
 
 		// API Key Authentication
 		APIKeyEnabled: getEnvBool("API_KEY_ENABLED", false),
 		APIKey:        getEnvString("API_KEY", ""),
+		APIKeysFile:   getEnvString("API_KEYS_FILE", ""),
+
+		// Target domain policy
+		TargetAllowlist:     getEnvString("TARGET_ALLOWLIST", ""),
+		TargetDenylist:      getEnvString("TARGET_DENYLIST", ""),
+		TargetAllowlistFile: getEnvString("TARGET_ALLOWLIST_FILE", ""),
+		TargetDenylistFile:  getEnvString("TARGET_DENYLIST_FILE", ""),
 
 		// CAPTCHA Solver settings
 		CaptchaNativeAttempts:    getEnvInt("CAPTCHA_NATIVE_ATTEMPTS", 3),
@@ -187,9 +574,21 @@ func Load() *Config {
 		Captcha2CaptchaAPIKey:    getEnvString("TWOCAPTCHA_API_KEY", ""),
 		CaptchaCapSolverAPIKey:   getEnvString("CAPSOLVER_API_KEY", ""),
 		CaptchaAntiCaptchaAPIKey: getEnvString("ANTICAPTCHA_API_KEY", ""),
+		CaptchaCapMonsterAPIKey:  getEnvString("CAPMONSTER_API_KEY", ""),
+		CaptchaWebhookURL:        getEnvString("CAPTCHA_WEBHOOK_URL", ""),
+		CaptchaWebhookToken:      getEnvString("CAPTCHA_WEBHOOK_TOKEN", ""),
 		Captcha9kwAPIKey:         getEnvString("NINEKW_API_KEY", ""),
 		CaptchaPrimaryProvider:   getEnvString("CAPTCHA_PRIMARY_PROVIDER", "2captcha"),
+		CaptchaProviderOrder:     getEnvStringSlice("CAPTCHA_PROVIDER_ORDER", nil),
 		CaptchaSolverTimeout:     getEnvDuration("CAPTCHA_SOLVER_TIMEOUT", 120*time.Second),
+		CaptchaLowBalance:        getEnvFloat("CAPTCHA_LOW_BALANCE", 1.0),
+		CaptchaRaceThreshold:     getEnvFloat("CAPTCHA_RACE_THRESHOLD", 0),
+		CaptchaBreakerErrorRate:  getEnvFloat("CAPTCHA_BREAKER_ERROR_RATE", 0.5),
+		CaptchaBreakerSlowCall:   getEnvDuration("CAPTCHA_BREAKER_SLOW_CALL", 90*time.Second),
+		CaptchaBreakerCooldown:   getEnvDuration("CAPTCHA_BREAKER_COOLDOWN", 60*time.Second),
+		CaptchaBudgetDaily:       getEnvFloat("CAPTCHA_BUDGET_DAILY", 0),
+		CaptchaBudgetMonthly:     getEnvFloat("CAPTCHA_BUDGET_MONTHLY", 0),
+		CaptchaProviderBudgets:   getEnvString("CAPTCHA_PROVIDER_BUDGETS", ""),
 
 		// Selectors settings
 		SelectorsPath:          getEnvString("SELECTORS_PATH", ""),
@@ -197,6 +596,8 @@ func Load() *Config {
 		SelectorsRemoteURL:     getEnvString("SELECTORS_REMOTE_URL", ""),
 		SelectorsRemoteRefresh: getEnvDuration("SELECTORS_REMOTE_REFRESH", 1*time.Hour),
 
+		SelectorsRemotePublicKey: getEnvString("SELECTORS_REMOTE_PUBLIC_KEY", ""),
+
 		// Dashboard
 		DashboardEnabled: getEnvBool("DASHBOARD_ENABLED", true),
 	}
@@ -209,7 +610,9 @@ func (c *Config) HasDefaultProxy() bool {
 
 // Validate checks configuration values and logs warnings for invalid values.
 // Invalid values are corrected to sensible defaults. (Bug 12: config bounds validation)
-func (c *Config) Validate() {
+// It returns an error for a value that cannot be corrected without failing
+// open, such as a QUIET_HOURS schedule that does not parse.
+func (c *Config) Validate() error {
 	// Port validation - allow 0 for system-assigned ports
 	if c.Port < 0 || c.Port > 65535 {
 		log.Warn().Int("port", c.Port).Msg("Invalid port, using default 8191")
@@ -239,6 +642,39 @@ func (c *Config) Validate() {
 		}
 	}
 
+	c.GPUMode = strings.ToLower(strings.TrimSpace(c.GPUMode))
+	switch c.GPUMode {
+	case GPUModeAuto, GPUModeANGLE, GPUModeEGL, GPUModeSoftware:
+	default:
+		if c.GPUMode != "" {
+			log.Warn().
+				Str("mode", c.GPUMode).
+				Msg("Invalid GPU_MODE (want auto, angle, egl or software), using auto")
+		}
+		c.GPUMode = GPUModeAuto
+	}
+
+	c.UserAgentRotation = strings.ToLower(strings.TrimSpace(c.UserAgentRotation))
+	switch c.UserAgentRotation {
+	case UserAgentRotationBrowser, UserAgentRotationSession, UserAgentRotationRequest:
+	default:
+		log.Warn().
+			Str("rotation", c.UserAgentRotation).
+			Msg("Invalid USER_AGENT_ROTATION (want browser, session or request), using browser")
+		c.UserAgentRotation = UserAgentRotationBrowser
+	}
+
+	if c.ResponseCompressionMinSize < 0 {
+		log.Warn().
+			Int("size", c.ResponseCompressionMinSize).
+			Msg("Invalid RESPONSE_COMPRESSION_MIN_SIZE, using 1024")
+		c.ResponseCompressionMinSize = 1024
+	}
+
+	if c.ClearanceFastPath && !c.ClearanceCacheEnabled {
+		log.Warn().Msg("CLEARANCE_FAST_PATH has no effect without CLEARANCE_CACHE_ENABLED")
+	}
+
 	// Pool size validation with upper bound
 	if c.BrowserPoolSize < 1 {
 		log.Warn().Int("size", c.BrowserPoolSize).Msg("Invalid pool size, using default 3")
@@ -251,6 +687,52 @@ func (c *Config) Validate() {
 		c.BrowserPoolSize = maxBrowserPoolSize
 	}
 
+	c.BrowserPoolMode = strings.ToLower(strings.TrimSpace(c.BrowserPoolMode))
+	if c.BrowserPoolMode != BrowserPoolModeBrowser && c.BrowserPoolMode != BrowserPoolModeContext {
+		if c.BrowserPoolMode != "" {
+			log.Warn().
+				Str("mode", c.BrowserPoolMode).
+				Msg("Invalid BROWSER_POOL_MODE (want browser or context), using browser")
+		}
+		c.BrowserPoolMode = BrowserPoolModeBrowser
+	}
+	if c.PageReuseEnabled && c.BrowserPoolMode == BrowserPoolModeContext {
+		log.Warn().Msg("PAGE_REUSE_ENABLED has no effect with BROWSER_POOL_MODE=context, whose contexts are discarded after each request")
+	}
+	// More hosts than slots would leave processes that never get a context
+	if c.ContextPoolHosts < 1 {
+		log.Warn().Int("hosts", c.ContextPoolHosts).Msg("CONTEXT_POOL_HOSTS too low, using 1")
+		c.ContextPoolHosts = 1
+	} else if c.ContextPoolHosts > c.BrowserPoolSize {
+		log.Warn().
+			Int("hosts", c.ContextPoolHosts).
+			Int("pool_size", c.BrowserPoolSize).
+			Msg("CONTEXT_POOL_HOSTS exceeds BROWSER_POOL_SIZE, capping to pool size")
+		c.ContextPoolHosts = c.BrowserPoolSize
+	}
+
+	if c.ProxyPoolSize < 0 {
+		log.Warn().Int("size", c.ProxyPoolSize).Msg("PROXY_POOL_SIZE negative, disabling proxy sub-pools")
+		c.ProxyPoolSize = 0
+	} else if c.ProxyPoolSize > 10 {
+		log.Warn().Int("size", c.ProxyPoolSize).Msg("PROXY_POOL_SIZE too high, using 10")
+		c.ProxyPoolSize = 10
+	}
+	if c.ProxyPoolMaxIdle < 1 {
+		log.Warn().Int("max", c.ProxyPoolMaxIdle).Msg("PROXY_POOL_MAX_IDLE too low, using 1")
+		c.ProxyPoolMaxIdle = 1
+	} else if c.ProxyPoolMaxIdle > 100 {
+		log.Warn().Int("max", c.ProxyPoolMaxIdle).Msg("PROXY_POOL_MAX_IDLE too high, using 100")
+		c.ProxyPoolMaxIdle = 100
+	}
+	if c.ProxyPoolIdleTimeout < 10*time.Second {
+		log.Warn().Dur("timeout", c.ProxyPoolIdleTimeout).Msg("PROXY_POOL_IDLE_TIMEOUT too short, using 10s")
+		c.ProxyPoolIdleTimeout = 10 * time.Second
+	} else if c.ProxyPoolIdleTimeout > time.Hour {
+		log.Warn().Dur("timeout", c.ProxyPoolIdleTimeout).Msg("PROXY_POOL_IDLE_TIMEOUT too long, using 1h")
+		c.ProxyPoolIdleTimeout = time.Hour
+	}
+
 	// Memory validation with upper bound
 	if c.MaxMemoryMB < 256 {
 		log.Warn().Int("mb", c.MaxMemoryMB).Msg("Memory limit too low, using default 2048")
@@ -288,6 +770,19 @@ func (c *Config) Validate() {
 		c.DefaultTimeout = c.MaxTimeout
 	}
 
+	// BufferPoolMaxBufferKB validation (4KB to 16MB)
+	const maxBufferPoolMaxBufferKB = 16 * 1024
+	if c.BufferPoolMaxBufferKB < 4 {
+		log.Warn().Int("kb", c.BufferPoolMaxBufferKB).Msg("BUFFER_POOL_MAX_BUFFER_KB too small, using 4")
+		c.BufferPoolMaxBufferKB = 4
+	} else if c.BufferPoolMaxBufferKB > maxBufferPoolMaxBufferKB {
+		log.Warn().
+			Int("kb", c.BufferPoolMaxBufferKB).
+			Int("max", maxBufferPoolMaxBufferKB).
+			Msg("BUFFER_POOL_MAX_BUFFER_KB too large, capping to maximum")
+		c.BufferPoolMaxBufferKB = maxBufferPoolMaxBufferKB
+	}
+
 	// Session validation with upper bound
 	if c.MaxSessions < 1 {
 		log.Warn().Int("max", c.MaxSessions).Msg("Invalid max sessions, using 100")
@@ -299,6 +794,37 @@ func (c *Config) Validate() {
 			Msg("Max sessions too high, capping to maximum")
 		c.MaxSessions = maxMaxSessions
 	}
+	c.SessionEvictionPolicy = strings.ToLower(strings.TrimSpace(c.SessionEvictionPolicy))
+	if c.SessionEvictionPolicy != SessionEvictionReject && c.SessionEvictionPolicy != SessionEvictionLRU {
+		if c.SessionEvictionPolicy != "" {
+			log.Warn().
+				Str("policy", c.SessionEvictionPolicy).
+				Msg("Invalid SESSION_EVICTION_POLICY (want reject or lru), using reject")
+		}
+		c.SessionEvictionPolicy = SessionEvictionReject
+	}
+
+	if c.SessionProxyRotateAfter < 1 {
+		log.Warn().
+			Int("value", c.SessionProxyRotateAfter).
+			Msg("SESSION_PROXY_ROTATE_AFTER too low, using 1")
+		c.SessionProxyRotateAfter = 1
+	} else if c.SessionProxyRotateAfter > 100 {
+		log.Warn().
+			Int("value", c.SessionProxyRotateAfter).
+			Msg("SESSION_PROXY_ROTATE_AFTER too high, using 100")
+		c.SessionProxyRotateAfter = 100
+	}
+
+	if c.ProxyHealthCheckEnabled {
+		c.validateProxyHealth()
+	}
+	if c.ProxyVerifyURL != "" {
+		if u, err := url.Parse(c.ProxyVerifyURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			log.Warn().Msg("PROXY_VERIFY_URL must be an absolute http(s) URL, verifyProxy requests will fail")
+			c.ProxyVerifyURL = ""
+		}
+	}
 
 	// SessionTTL validation (minimum 1 minute, maximum 24 hours)
 	const minSessionTTL = 1 * time.Minute
@@ -342,6 +868,194 @@ func (c *Config) Validate() {
 			Msg("SESSION_CLEANUP_INTERVAL should be less than SESSION_TTL for timely cleanup")
 	}
 
+	// Session persistence directory - normalize to an absolute path
+	if c.SessionPersistDir != "" {
+		absPath, err := filepath.Abs(filepath.Clean(c.SessionPersistDir))
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("path", c.SessionPersistDir).
+				Msg("SESSION_PERSIST_DIR could not be resolved, session persistence disabled")
+			c.SessionPersistDir = ""
+		} else {
+			c.SessionPersistDir = absPath
+		}
+	}
+
+	// Recording directory - normalize to an absolute path
+	if c.RecordSolveDir != "" {
+		absPath, err := filepath.Abs(filepath.Clean(c.RecordSolveDir))
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("path", c.RecordSolveDir).
+				Msg("RECORD_SOLVE_DIR could not be resolved, recordings will be returned in responses")
+			c.RecordSolveDir = ""
+		} else {
+			c.RecordSolveDir = absPath
+		}
+	}
+
+	// Session Redis URL must use a Redis scheme
+	if c.SessionRedisURL != "" {
+		if !strings.HasPrefix(c.SessionRedisURL, "redis://") && !strings.HasPrefix(c.SessionRedisURL, "rediss://") {
+			log.Warn().Msg("SESSION_REDIS_URL must use redis:// or rediss:// scheme, ignoring")
+			c.SessionRedisURL = ""
+		} else if c.SessionPersistDir != "" {
+			log.Warn().Msg("Both SESSION_REDIS_URL and SESSION_PERSIST_DIR are set - Redis takes priority")
+		}
+	}
+
+	// ClientRedirectSettle validation (0 disables, maximum 10 seconds)
+	const maxClientRedirectSettle = 10 * time.Second
+	if c.ClientRedirectSettle < 0 {
+		log.Warn().
+			Dur("settle", c.ClientRedirectSettle).
+			Msg("CLIENT_REDIRECT_SETTLE cannot be negative, disabling")
+		c.ClientRedirectSettle = 0
+	} else if c.ClientRedirectSettle > maxClientRedirectSettle {
+		log.Warn().
+			Dur("settle", c.ClientRedirectSettle).
+			Dur("max", maxClientRedirectSettle).
+			Msg("CLIENT_REDIRECT_SETTLE too long, using maximum")
+		c.ClientRedirectSettle = maxClientRedirectSettle
+	}
+
+	// XHRChallengeWatch validation (0 disables, maximum 30 seconds)
+	const maxXHRChallengeWatch = 30 * time.Second
+	if c.XHRChallengeWatch < 0 {
+		log.Warn().
+			Dur("watch", c.XHRChallengeWatch).
+			Msg("XHR_CHALLENGE_WATCH cannot be negative, disabling")
+		c.XHRChallengeWatch = 0
+	} else if c.XHRChallengeWatch > maxXHRChallengeWatch {
+		log.Warn().
+			Dur("watch", c.XHRChallengeWatch).
+			Dur("max", maxXHRChallengeWatch).
+			Msg("XHR_CHALLENGE_WATCH too long, using maximum")
+		c.XHRChallengeWatch = maxXHRChallengeWatch
+	}
+
+	// CacheFallbackTimeout validation (1 second to 1 minute)
+	const minCacheFallbackTimeout = 1 * time.Second
+	const maxCacheFallbackTimeout = time.Minute
+	if c.CacheFallbackTimeout < minCacheFallbackTimeout {
+		log.Warn().
+			Dur("timeout", c.CacheFallbackTimeout).
+			Msg("CACHE_FALLBACK_TIMEOUT too short, using 1s")
+		c.CacheFallbackTimeout = minCacheFallbackTimeout
+	} else if c.CacheFallbackTimeout > maxCacheFallbackTimeout {
+		log.Warn().
+			Dur("timeout", c.CacheFallbackTimeout).
+			Dur("max", maxCacheFallbackTimeout).
+			Msg("CACHE_FALLBACK_TIMEOUT too long, using maximum")
+		c.CacheFallbackTimeout = maxCacheFallbackTimeout
+	}
+
+	// ResultCacheTTL validation (1 second to 10 minutes)
+	const minResultCacheTTL = 1 * time.Second
+	const maxResultCacheTTL = 10 * time.Minute
+	if c.ResultCacheTTL < minResultCacheTTL {
+		log.Warn().
+			Dur("ttl", c.ResultCacheTTL).
+			Msg("RESULT_CACHE_TTL too short, using 1s")
+		c.ResultCacheTTL = minResultCacheTTL
+	} else if c.ResultCacheTTL > maxResultCacheTTL {
+		log.Warn().
+			Dur("ttl", c.ResultCacheTTL).
+			Dur("max", maxResultCacheTTL).
+			Msg("RESULT_CACHE_TTL too long, using maximum")
+		c.ResultCacheTTL = maxResultCacheTTL
+	}
+	if c.ResultCacheMaxEntries < 1 {
+		log.Warn().
+			Int("entries", c.ResultCacheMaxEntries).
+			Msg("Invalid RESULT_CACHE_MAX_ENTRIES, using 256")
+		c.ResultCacheMaxEntries = 256
+	}
+
+	// Response limits validation (1 up to the hard ceiling each)
+	for _, l := range []struct {
+		env   string
+		value *int
+		def   int
+		max   int
+	}{
+		{"MAX_RESPONSE_SIZE_KB", &c.MaxResponseSizeKB, 10 * 1024, types.MaxResponseSizeCeiling / 1024},
+		{"MAX_SCREENSHOT_SIZE_KB", &c.MaxScreenshotSizeKB, 5 * 1024, types.MaxScreenshotSizeCeiling / 1024},
+		{"MAX_RESPONSE_COOKIES", &c.MaxResponseCookies, 100, types.MaxCookiesCeiling},
+		{"MAX_STORAGE_ITEMS", &c.MaxStorageItems, 100, types.MaxStorageItemsCeiling},
+		{"MAX_STORAGE_SIZE_KB", &c.MaxStorageSizeKB, 1024, types.MaxStorageSizeCeiling / 1024},
+	} {
+		if *l.value < 1 {
+			log.Warn().
+				Str("setting", l.env).
+				Int("value", *l.value).
+				Int("default", l.def).
+				Msg("Invalid response limit, using default")
+			*l.value = l.def
+		} else if *l.value > l.max {
+			log.Warn().
+				Str("setting", l.env).
+				Int("value", *l.value).
+				Int("max", l.max).
+				Msg("Response limit too large, capping to maximum")
+			*l.value = l.max
+		}
+	}
+
+	// Poll interval validation (50ms to 10s, max not below min)
+	const minPollInterval = 50 * time.Millisecond
+	const maxPollInterval = 10 * time.Second
+	if c.PollIntervalMin < minPollInterval {
+		log.Warn().
+			Dur("interval", c.PollIntervalMin).
+			Msg("POLL_INTERVAL_MIN too short, using 50ms")
+		c.PollIntervalMin = minPollInterval
+	} else if c.PollIntervalMin > maxPollInterval {
+		log.Warn().
+			Dur("interval", c.PollIntervalMin).
+			Dur("max", maxPollInterval).
+			Msg("POLL_INTERVAL_MIN too long, using maximum")
+		c.PollIntervalMin = maxPollInterval
+	}
+	if c.PollIntervalMax < c.PollIntervalMin {
+		log.Warn().
+			Dur("interval", c.PollIntervalMax).
+			Dur("min", c.PollIntervalMin).
+			Msg("POLL_INTERVAL_MAX below POLL_INTERVAL_MIN, using POLL_INTERVAL_MIN")
+		c.PollIntervalMax = c.PollIntervalMin
+	} else if c.PollIntervalMax > maxPollInterval {
+		log.Warn().
+			Dur("interval", c.PollIntervalMax).
+			Dur("max", maxPollInterval).
+			Msg("POLL_INTERVAL_MAX too long, using maximum")
+		c.PollIntervalMax = maxPollInterval
+	}
+
+	if c.PollMaxAttempts < 0 {
+		log.Warn().
+			Int("attempts", c.PollMaxAttempts).
+			Msg("POLL_MAX_ATTEMPTS cannot be negative, sizing attempts to the timeout")
+		c.PollMaxAttempts = 0
+	}
+
+	// SelectorDetectionBudget validation (100ms to 1 minute)
+	const minSelectorBudget = 100 * time.Millisecond
+	const maxSelectorBudget = time.Minute
+	if c.SelectorDetectionBudget < minSelectorBudget {
+		log.Warn().
+			Dur("budget", c.SelectorDetectionBudget).
+			Msg("SELECTOR_DETECTION_BUDGET too short, using 100ms")
+		c.SelectorDetectionBudget = minSelectorBudget
+	} else if c.SelectorDetectionBudget > maxSelectorBudget {
+		log.Warn().
+			Dur("budget", c.SelectorDetectionBudget).
+			Dur("max", maxSelectorBudget).
+			Msg("SELECTOR_DETECTION_BUDGET too long, using maximum")
+		c.SelectorDetectionBudget = maxSelectorBudget
+	}
+
 	// BrowserPoolTimeout validation (minimum 1 second, maximum 5 minutes)
 	const minPoolTimeout = 1 * time.Second
 	const maxPoolTimeout = 5 * time.Minute
@@ -384,10 +1098,19 @@ func (c *Config) Validate() {
 	}
 
 	// PProf security warning
-	if c.PProfEnabled && c.PProfBindAddr != "127.0.0.1" && c.PProfBindAddr != "localhost" {
+	if c.PProfEnabled && c.PProfBindAddr != "127.0.0.1" && c.PProfBindAddr != "localhost" && len(c.PProfAllowedCIDRs) == 0 {
 		log.Warn().
 			Str("addr", c.PProfBindAddr).
-			Msg("WARNING: pprof exposed on non-localhost address - this is a security risk")
+			Msg("WARNING: pprof exposed on non-localhost address - this is a security risk; restrict it with PPROF_ALLOWED_CIDRS")
+	}
+
+	// The admin dashboard can recycle and drain the pool, so it never runs unauthenticated
+	if c.AdminEnabled && c.AdminPassword == "" {
+		log.Warn().Msg("ADMIN_ENABLED requires ADMIN_PASSWORD, admin dashboard disabled")
+		c.AdminEnabled = false
+	}
+	if c.AdminEnabled && c.AdminUsername == "" {
+		c.AdminUsername = "admin"
 	}
 
 	// CORS security warning
@@ -409,6 +1132,10 @@ func (c *Config) Validate() {
 		log.Warn().Msg("DNS_REBINDING_PROTECTION disabled - response URL IP pinning is off (SSRF protection against private/internal IPs remains active)")
 	}
 
+	if c.EvaluateJsEnabled && !c.APIKeyEnabled {
+		log.Warn().Msg("EVALUATE_JS_ENABLED without API_KEY_ENABLED - any client can run scripts in solved pages")
+	}
+
 	// Fix #17: Proxy URL and credential validation
 	if c.ProxyURL != "" {
 		// Basic URL format validation
@@ -474,8 +1201,28 @@ func (c *Config) Validate() {
 				}
 			}
 		}
+		usedPorts[c.PProfPort] = "PPROF_PORT"
+	}
+	if c.AdminEnabled {
+		if existingName, exists := usedPorts[c.AdminPort]; exists {
+			log.Error().
+				Int("port", c.AdminPort).
+				Str("conflicts_with", existingName).
+				Msg("ADMIN_PORT conflicts with another port, adjusting")
+			c.AdminPort = 8192
+			for usedPorts[c.AdminPort] != "" {
+				c.AdminPort++
+				if c.AdminPort > 65535 {
+					log.Warn().Msg("Could not find available admin port, disabling")
+					c.AdminEnabled = false
+					break
+				}
+			}
+		}
 	}
 
+	c.validateTLS()
+
 	// CAPTCHA solver validation
 	c.validateCaptchaConfig()
 
@@ -510,6 +1257,70 @@ func (c *Config) Validate() {
 		}
 	}
 
+	// User agent pool path - same normalization as SelectorsPath
+	if c.UserAgentPoolPath != "" {
+		absPath, err := filepath.Abs(filepath.Clean(c.UserAgentPoolPath))
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("path", c.UserAgentPoolPath).
+				Msg("UserAgentPoolPath could not be resolved, ignoring")
+			c.UserAgentPoolPath = ""
+		} else {
+			c.UserAgentPoolPath = absPath
+		}
+	}
+
+	// Browser profiles path - same normalization as SelectorsPath
+	if c.BrowserProfilesPath != "" {
+		absPath, err := filepath.Abs(filepath.Clean(c.BrowserProfilesPath))
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("path", c.BrowserProfilesPath).
+				Msg("BrowserProfilesPath could not be resolved, ignoring")
+			c.BrowserProfilesPath = ""
+		} else {
+			c.BrowserProfilesPath = absPath
+		}
+	}
+	if c.ProxyPoolsPath != "" {
+		absPath, err := filepath.Abs(filepath.Clean(c.ProxyPoolsPath))
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("path", c.ProxyPoolsPath).
+				Msg("ProxyPoolsPath could not be resolved, ignoring")
+			c.ProxyPoolsPath = ""
+		} else {
+			c.ProxyPoolsPath = absPath
+		}
+	}
+	if c.DomainOverridesPath != "" {
+		absPath, err := filepath.Abs(filepath.Clean(c.DomainOverridesPath))
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("path", c.DomainOverridesPath).
+				Msg("DomainOverridesPath could not be resolved, ignoring")
+			c.DomainOverridesPath = ""
+		} else {
+			c.DomainOverridesPath = absPath
+		}
+	}
+	if c.BrowserProfileDataDir != "" {
+		absPath, err := filepath.Abs(filepath.Clean(c.BrowserProfileDataDir))
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("path", c.BrowserProfileDataDir).
+				Msg("BrowserProfileDataDir could not be resolved, ignoring")
+			c.BrowserProfileDataDir = ""
+		} else {
+			c.BrowserProfileDataDir = absPath
+		}
+	}
+
 	// Warn if hot-reload is enabled but no path is set
 	if c.SelectorsHotReload && c.SelectorsPath == "" {
 		log.Warn().Msg("SELECTORS_HOT_RELOAD enabled but SELECTORS_PATH not set - hot-reload disabled")
@@ -535,6 +1346,19 @@ func (c *Config) Validate() {
 		}
 	}
 
+	// An unusable signing key must not silently accept unsigned selectors
+	if c.SelectorsRemotePublicKey != "" {
+		key, err := base64.StdEncoding.DecodeString(c.SelectorsRemotePublicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			log.Error().
+				Msg("SELECTORS_REMOTE_PUBLIC_KEY must be a base64 Ed25519 public key, remote selectors disabled")
+			c.SelectorsRemoteURL = ""
+			c.SelectorsRemotePublicKey = ""
+		} else if c.SelectorsRemoteURL == "" {
+			log.Warn().Msg("SELECTORS_REMOTE_PUBLIC_KEY set but SELECTORS_REMOTE_URL not set, ignoring")
+		}
+	}
+
 	// Remote selectors refresh interval validation (min 5m, max 24h)
 	if c.SelectorsRemoteURL != "" {
 		const minRemoteRefresh = 5 * time.Minute
@@ -554,10 +1378,100 @@ func (c *Config) Validate() {
 		}
 	}
 
+	// The log stream exposes target URLs and session IDs, so never serve it unauthenticated
+	if c.LogStreamEnabled {
+		if !c.APIKeyEnabled {
+			log.Warn().Msg("LOG_STREAM_ENABLED requires API_KEY_ENABLED, log streaming disabled")
+			c.LogStreamEnabled = false
+		}
+		if c.LogStreamMaxSubscribers < 1 {
+			log.Warn().
+				Int("value", c.LogStreamMaxSubscribers).
+				Msg("LOG_STREAM_MAX_SUBSCRIBERS too low, using 1")
+			c.LogStreamMaxSubscribers = 1
+		} else if c.LogStreamMaxSubscribers > 100 {
+			log.Warn().
+				Int("value", c.LogStreamMaxSubscribers).
+				Msg("LOG_STREAM_MAX_SUBSCRIBERS too high, using 100")
+			c.LogStreamMaxSubscribers = 100
+		}
+	}
+
+	// Events carry session IDs and target domains, so they need authentication too
+	if c.EventStreamEnabled {
+		if !c.APIKeyEnabled {
+			log.Warn().Msg("EVENT_STREAM_ENABLED requires API_KEY_ENABLED, event streaming disabled")
+			c.EventStreamEnabled = false
+		}
+		if c.EventStreamMaxSubscribers < 1 {
+			log.Warn().
+				Int("value", c.EventStreamMaxSubscribers).
+				Msg("EVENT_STREAM_MAX_SUBSCRIBERS too low, using 1")
+			c.EventStreamMaxSubscribers = 1
+		} else if c.EventStreamMaxSubscribers > 100 {
+			log.Warn().
+				Int("value", c.EventStreamMaxSubscribers).
+				Msg("EVENT_STREAM_MAX_SUBSCRIBERS too high, using 100")
+			c.EventStreamMaxSubscribers = 100
+		}
+	}
+
+	if c.AuditLogFile != "" {
+		if c.AuditLogMaxSizeMB < 1 {
+			log.Warn().
+				Int("value", c.AuditLogMaxSizeMB).
+				Msg("AUDIT_LOG_MAX_SIZE_MB too low, using 1")
+			c.AuditLogMaxSizeMB = 1
+		}
+		if c.AuditLogMaxBackups < 0 {
+			log.Warn().
+				Int("value", c.AuditLogMaxBackups).
+				Msg("AUDIT_LOG_MAX_BACKUPS cannot be negative, using 0")
+			c.AuditLogMaxBackups = 0
+		}
+	}
+
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		log.Warn().
+			Float64("value", c.TracingSampleRatio).
+			Msg("TRACING_SAMPLE_RATIO must be between 0 and 1, using 1")
+		c.TracingSampleRatio = 1
+	}
+
+	if c.MetricsTagMaxValues < 1 {
+		log.Warn().
+			Int("value", c.MetricsTagMaxValues).
+			Msg("METRICS_TAG_MAX_VALUES too low, using 1")
+		c.MetricsTagMaxValues = 1
+	} else if c.MetricsTagMaxValues > 1000 {
+		log.Warn().
+			Int("value", c.MetricsTagMaxValues).
+			Msg("METRICS_TAG_MAX_VALUES too high, using 1000")
+		c.MetricsTagMaxValues = 1000
+	}
+
+	if c.MetricsDomainTopN < 0 {
+		log.Warn().
+			Int("value", c.MetricsDomainTopN).
+			Msg("METRICS_DOMAIN_TOP_N cannot be negative, using 0")
+		c.MetricsDomainTopN = 0
+	} else if c.MetricsDomainTopN > 500 {
+		log.Warn().
+			Int("value", c.MetricsDomainTopN).
+			Msg("METRICS_DOMAIN_TOP_N too high, using 500")
+		c.MetricsDomainTopN = 500
+	}
+
+	if c.APIKeysFile != "" && !c.APIKeyEnabled {
+		log.Warn().Msg("API_KEYS_FILE is set but API_KEY_ENABLED is false, scoped keys are ignored")
+	}
+
 	// API key validation with minimum length enforcement
 	if c.APIKeyEnabled {
 		const maxAPIKeyLength = 256
 		switch {
+		case c.APIKey == "" && c.APIKeysFile != "":
+			log.Info().Msg("API_KEY is empty - only the scoped keys in API_KEYS_FILE are accepted")
 		case c.APIKey == "":
 			log.Error().Msg("API_KEY_ENABLED is true but API_KEY is empty - authentication will always fail")
 		case len(c.APIKey) < minAPIKeyLength:
@@ -584,19 +1498,25 @@ func (c *Config) Validate() {
 			}
 		}
 	}
+
+	// An unparseable schedule must not silently lift every quiet window
+	if _, err := quiethours.Parse(c.QuietHours); err != nil {
+		return fmt.Errorf("invalid QUIET_HOURS: %w", err)
+	}
+	return nil
 }
 
 // Helper functions for environment variable parsing
 
 func getEnvString(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+	if value := setting(key, defaultValue); value != "" {
 		return value
 	}
 	return defaultValue
 }
 
 func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
+	if value := setting(key, defaultValue); value != "" {
 		// Use ParseInt with explicit bounds to catch overflow
 		intValue, err := strconv.ParseInt(value, 10, 32)
 		if err == nil {
@@ -622,8 +1542,23 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := setting(key, defaultValue); value != "" {
+		floatValue, err := strconv.ParseFloat(value, 64)
+		if err == nil && !math.IsNaN(floatValue) && !math.IsInf(floatValue, 0) {
+			return floatValue
+		}
+		log.Warn().
+			Str("key", key).
+			Str("value", value).
+			Float64("default", defaultValue).
+			Msg("Invalid number in environment variable, using default")
+	}
+	return defaultValue
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
+	if value := setting(key, defaultValue); value != "" {
 		boolValue, err := strconv.ParseBool(value)
 		if err == nil {
 			return boolValue
@@ -640,7 +1575,7 @@ func getEnvBool(key string, defaultValue bool) bool {
 }
 
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
+	if value := setting(key, defaultValue); value != "" {
 		duration, err := time.ParseDuration(value)
 		if err == nil {
 			// Reject negative or zero durations
@@ -666,7 +1601,7 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 }
 
 func getEnvTimezone(key, defaultValue string) string {
-	value := os.Getenv(key)
+	value := setting(key, defaultValue)
 	if value == "" {
 		return defaultValue
 	}
@@ -683,7 +1618,7 @@ func getEnvTimezone(key, defaultValue string) string {
 }
 
 func getEnvStringSlice(key string, defaultValue []string) []string {
-	if value := os.Getenv(key); value != "" {
+	if value := setting(key, defaultValue); value != "" {
 		// Parse comma-separated values, trimming whitespace
 		parts := strings.Split(value, ",")
 		result := make([]string, 0, len(parts))
@@ -733,7 +1668,7 @@ func (c *Config) validateCaptchaConfig() {
 	}
 
 	// Validate primary provider
-	validProviders := map[string]bool{"2captcha": true, "capsolver": true, "anticaptcha": true, "9kw": true}
+	validProviders := map[string]bool{"2captcha": true, "capsolver": true, "anticaptcha": true, "capmonster": true, "9kw": true, "custom": true}
 	if c.CaptchaPrimaryProvider != "" && !validProviders[strings.ToLower(c.CaptchaPrimaryProvider)] {
 		log.Warn().
 			Str("provider", c.CaptchaPrimaryProvider).
@@ -742,10 +1677,88 @@ func (c *Config) validateCaptchaConfig() {
 	}
 	c.CaptchaPrimaryProvider = strings.ToLower(c.CaptchaPrimaryProvider)
 
+	if c.CaptchaLowBalance < 0 {
+		log.Warn().
+			Float64("balance", c.CaptchaLowBalance).
+			Msg("CAPTCHA_LOW_BALANCE must not be negative, disabling the low balance flag")
+		c.CaptchaLowBalance = 0
+	}
+
+	if c.CaptchaRaceThreshold < 0 || c.CaptchaRaceThreshold > 1 {
+		log.Warn().
+			Float64("threshold", c.CaptchaRaceThreshold).
+			Msg("CAPTCHA_RACE_THRESHOLD must be between 0 and 1, disabling racing")
+		c.CaptchaRaceThreshold = 0
+	}
+
+	if c.CaptchaBreakerErrorRate < 0 || c.CaptchaBreakerErrorRate > 1 {
+		log.Warn().
+			Float64("rate", c.CaptchaBreakerErrorRate).
+			Msg("CAPTCHA_BREAKER_ERROR_RATE must be between 0 and 1, using 0.5")
+		c.CaptchaBreakerErrorRate = 0.5
+	}
+	if c.CaptchaBreakerSlowCall < 0 {
+		log.Warn().
+			Dur("slow_call", c.CaptchaBreakerSlowCall).
+			Msg("CAPTCHA_BREAKER_SLOW_CALL must not be negative, disabling the latency threshold")
+		c.CaptchaBreakerSlowCall = 0
+	}
+	if c.CaptchaBreakerCooldown < time.Second {
+		log.Warn().
+			Dur("cooldown", c.CaptchaBreakerCooldown).
+			Msg("CAPTCHA_BREAKER_COOLDOWN too short, using 1s")
+		c.CaptchaBreakerCooldown = time.Second
+	}
+
+	// Validate spend budgets
+	if c.CaptchaBudgetDaily < 0 {
+		log.Warn().
+			Float64("budget", c.CaptchaBudgetDaily).
+			Msg("CAPTCHA_BUDGET_DAILY must not be negative, disabling the daily budget")
+		c.CaptchaBudgetDaily = 0
+	}
+	if c.CaptchaBudgetMonthly < 0 {
+		log.Warn().
+			Float64("budget", c.CaptchaBudgetMonthly).
+			Msg("CAPTCHA_BUDGET_MONTHLY must not be negative, disabling the monthly budget")
+		c.CaptchaBudgetMonthly = 0
+	}
+
+	// Validate webhook provider URL
+	if c.CaptchaWebhookURL != "" {
+		if u, err := url.Parse(c.CaptchaWebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			log.Warn().
+				Str("url", c.CaptchaWebhookURL).
+				Msg("Invalid CAPTCHA_WEBHOOK_URL, custom captcha provider disabled")
+			c.CaptchaWebhookURL = ""
+		}
+	}
+
+	// Validate provider order, dropping unknown and repeated names
+	if len(c.CaptchaProviderOrder) > 0 {
+		order := make([]string, 0, len(c.CaptchaProviderOrder))
+		for _, name := range c.CaptchaProviderOrder {
+			name = strings.ToLower(name)
+			if !validProviders[name] {
+				log.Warn().
+					Str("provider", name).
+					Msg("Unknown provider in CAPTCHA_PROVIDER_ORDER, ignoring")
+				continue
+			}
+			if !slices.Contains(order, name) {
+				order = append(order, name)
+			}
+		}
+		if len(order) == 0 {
+			order = nil
+		}
+		c.CaptchaProviderOrder = order
+	}
+
 	// Warn if fallback enabled but no API keys configured
 	if c.CaptchaFallbackEnabled {
-		if c.Captcha2CaptchaAPIKey == "" && c.CaptchaCapSolverAPIKey == "" && c.CaptchaAntiCaptchaAPIKey == "" && c.Captcha9kwAPIKey == "" {
-			log.Warn().Msg("CAPTCHA_FALLBACK_ENABLED is true but no API keys configured (TWOCAPTCHA_API_KEY, CAPSOLVER_API_KEY, ANTICAPTCHA_API_KEY, or NINEKW_API_KEY)")
+		if c.Captcha2CaptchaAPIKey == "" && c.CaptchaCapSolverAPIKey == "" && c.CaptchaAntiCaptchaAPIKey == "" && c.CaptchaCapMonsterAPIKey == "" && c.Captcha9kwAPIKey == "" && c.CaptchaWebhookURL == "" {
+			log.Warn().Msg("CAPTCHA_FALLBACK_ENABLED is true but no API keys configured (TWOCAPTCHA_API_KEY, CAPSOLVER_API_KEY, ANTICAPTCHA_API_KEY, CAPMONSTER_API_KEY, NINEKW_API_KEY, or CAPTCHA_WEBHOOK_URL)")
 		} else {
 			// Log which providers are configured
 			var configured []string
@@ -758,12 +1771,19 @@ func (c *Config) validateCaptchaConfig() {
 			if c.CaptchaAntiCaptchaAPIKey != "" {
 				configured = append(configured, "anticaptcha")
 			}
+			if c.CaptchaCapMonsterAPIKey != "" {
+				configured = append(configured, "capmonster")
+			}
 			if c.Captcha9kwAPIKey != "" {
 				configured = append(configured, "9kw")
 			}
+			if c.CaptchaWebhookURL != "" {
+				configured = append(configured, "custom")
+			}
 			log.Info().
 				Strs("providers", configured).
 				Str("primary", c.CaptchaPrimaryProvider).
+				Strs("order", c.CaptchaProviderOrder).
 				Int("native_attempts", c.CaptchaNativeAttempts).
 				Msg("External CAPTCHA solver fallback enabled")
 		}
@@ -772,5 +1792,117 @@ func (c *Config) validateCaptchaConfig() {
 
 // HasCaptchaFallback returns true if external CAPTCHA fallback is configured.
 func (c *Config) HasCaptchaFallback() bool {
-	return c.CaptchaFallbackEnabled && (c.Captcha2CaptchaAPIKey != "" || c.CaptchaCapSolverAPIKey != "" || c.CaptchaAntiCaptchaAPIKey != "" || c.Captcha9kwAPIKey != "")
+	return c.CaptchaFallbackEnabled && (c.Captcha2CaptchaAPIKey != "" || c.CaptchaCapSolverAPIKey != "" || c.CaptchaAntiCaptchaAPIKey != "" || c.CaptchaCapMonsterAPIKey != "" || c.Captcha9kwAPIKey != "" || c.CaptchaWebhookURL != "")
+}
+
+// validateProxyHealth clamps the proxy health check settings.
+func (c *Config) validateProxyHealth() {
+	const minInterval = 30 * time.Second
+	const maxInterval = 24 * time.Hour
+	if c.ProxyHealthInterval < minInterval {
+		log.Warn().
+			Dur("interval", c.ProxyHealthInterval).
+			Dur("min", minInterval).
+			Msg("PROXY_HEALTH_CHECK_INTERVAL too short, using minimum")
+		c.ProxyHealthInterval = minInterval
+	} else if c.ProxyHealthInterval > maxInterval {
+		log.Warn().
+			Dur("interval", c.ProxyHealthInterval).
+			Dur("max", maxInterval).
+			Msg("PROXY_HEALTH_CHECK_INTERVAL too long, using maximum")
+		c.ProxyHealthInterval = maxInterval
+	}
+
+	const minTimeout = time.Second
+	const maxTimeout = 2 * time.Minute
+	if c.ProxyHealthTimeout < minTimeout {
+		log.Warn().
+			Dur("timeout", c.ProxyHealthTimeout).
+			Dur("min", minTimeout).
+			Msg("PROXY_HEALTH_CHECK_TIMEOUT too short, using minimum")
+		c.ProxyHealthTimeout = minTimeout
+	} else if c.ProxyHealthTimeout > maxTimeout {
+		log.Warn().
+			Dur("timeout", c.ProxyHealthTimeout).
+			Dur("max", maxTimeout).
+			Msg("PROXY_HEALTH_CHECK_TIMEOUT too long, using maximum")
+		c.ProxyHealthTimeout = maxTimeout
+	}
+
+	if c.ProxyHealthFailures < 1 {
+		log.Warn().
+			Int("value", c.ProxyHealthFailures).
+			Msg("PROXY_HEALTH_FAIL_THRESHOLD too low, using 1")
+		c.ProxyHealthFailures = 1
+	} else if c.ProxyHealthFailures > 100 {
+		log.Warn().
+			Int("value", c.ProxyHealthFailures).
+			Msg("PROXY_HEALTH_FAIL_THRESHOLD too high, using 100")
+		c.ProxyHealthFailures = 100
+	}
+
+	for name, target := range map[string]*string{
+		"PROXY_HEALTH_IP_URL":     &c.ProxyHealthIPURL,
+		"PROXY_HEALTH_CANARY_URL": &c.ProxyHealthCanaryURL,
+	} {
+		if *target == "" {
+			continue
+		}
+		if u, err := url.Parse(*target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			log.Warn().Str("setting", name).Msg("Proxy health check URL must be an absolute http(s) URL, ignoring")
+			*target = ""
+		}
+	}
+	if c.ProxyHealthIPURL == "" {
+		log.Warn().Msg("PROXY_HEALTH_IP_URL is empty, proxy health checks disabled")
+		c.ProxyHealthCheckEnabled = false
+	}
+}
+
+// TLSEnabled reports whether the listeners serve HTTPS.
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" || len(c.TLSACMEDomains) > 0
+}
+
+// validateTLS checks the HTTPS settings: a certificate needs its key, and a
+// static certificate takes precedence over ACME.
+func (c *Config) validateTLS() {
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		log.Warn().Msg("TLS_CERT_FILE and TLS_KEY_FILE must be set together, ignoring both")
+		c.TLSCertFile, c.TLSKeyFile = "", ""
+	}
+	if c.TLSCertFile != "" && len(c.TLSACMEDomains) > 0 {
+		log.Warn().Msg("TLS_CERT_FILE is set, ignoring TLS_ACME_DOMAINS")
+		c.TLSACMEDomains = nil
+	}
+
+	switch c.TLSClientAuth = strings.ToLower(strings.TrimSpace(c.TLSClientAuth)); c.TLSClientAuth {
+	case "require", "optional":
+	default:
+		log.Warn().Str("value", c.TLSClientAuth).Msg("TLS_CLIENT_AUTH must be require or optional, using require")
+		c.TLSClientAuth = "require"
+	}
+	if c.TLSClientCAFile != "" && !c.TLSEnabled() {
+		log.Warn().Msg("TLS_CLIENT_CA_FILE needs TLS_CERT_FILE or TLS_ACME_DOMAINS, client certificates are not checked")
+		c.TLSClientCAFile = ""
+	}
+
+	if len(c.TLSACMEDomains) == 0 {
+		return
+	}
+	if c.TLSACMECacheDir == "" {
+		log.Warn().Msg("TLS_ACME_CACHE_DIR is empty, ACME certificates would be requested again on every restart; using acme-cache")
+		c.TLSACMECacheDir = "acme-cache"
+	}
+	if c.TLSACMEDirectoryURL != "" {
+		if u, err := url.Parse(c.TLSACMEDirectoryURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			log.Warn().Msg("TLS_ACME_DIRECTORY_URL must be an absolute https URL, using Let's Encrypt")
+			c.TLSACMEDirectoryURL = ""
+		}
+	}
+	if c.TLSACMEHTTPAddr == "" && c.Port != 443 {
+		log.Warn().
+			Int("port", c.Port).
+			Msg("ACME TLS-ALPN challenges only reach port 443; set PORT=443 or TLS_ACME_HTTP_ADDR=:80 for HTTP challenges")
+	}
 }
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -11,10 +12,10 @@ func TestLoadDefaults(t *testing.T) {
 	envVars := []string{
 		"HOST", "PORT", "HEADLESS", "BROWSER_PATH",
 		"BROWSER_POOL_SIZE", "BROWSER_POOL_TIMEOUT", "MAX_MEMORY_MB",
-		"SESSION_TTL", "SESSION_CLEANUP_INTERVAL", "MAX_SESSIONS",
+		"SESSION_TTL", "SESSION_CLEANUP_INTERVAL", "MAX_SESSIONS", "SESSION_EVICTION_POLICY",
 		"DEFAULT_TIMEOUT", "MAX_TIMEOUT",
 		"PROXY_URL", "PROXY_USERNAME", "PROXY_PASSWORD",
-		"LOG_LEVEL", "LOG_HTML",
+		"LOG_LEVEL", "LOG_HTML", "EVALUATE_JS_ENABLED",
 	}
 	for _, env := range envVars {
 		os.Unsetenv(env)
@@ -56,6 +57,28 @@ func TestLoadDefaults(t *testing.T) {
 	if cfg.MaxSessions != 100 {
 		t.Errorf("Expected default max sessions 100, got %d", cfg.MaxSessions)
 	}
+	if cfg.BrowserPoolMode != BrowserPoolModeBrowser {
+		t.Errorf("Expected default pool mode %q, got %q", BrowserPoolModeBrowser, cfg.BrowserPoolMode)
+	}
+	if cfg.ContextPoolHosts != 2 {
+		t.Errorf("Expected default context pool hosts 2, got %d", cfg.ContextPoolHosts)
+	}
+	if cfg.ProxyPoolSize != 1 || cfg.ProxyPoolMaxIdle != 4 || cfg.ProxyPoolIdleTimeout != 2*time.Minute {
+		t.Errorf("Unexpected proxy pool defaults: size=%d max_idle=%d idle_timeout=%v",
+			cfg.ProxyPoolSize, cfg.ProxyPoolMaxIdle, cfg.ProxyPoolIdleTimeout)
+	}
+	if cfg.GPUMode != GPUModeAuto {
+		t.Errorf("Expected default GPU mode %q, got %q", GPUModeAuto, cfg.GPUMode)
+	}
+	if cfg.BrowserProfilesPath != "" {
+		t.Errorf("Expected no browser profiles by default, got %q", cfg.BrowserProfilesPath)
+	}
+	if cfg.BrowserProfileDataDir != "" {
+		t.Errorf("Expected no browser profile data dir by default, got %q", cfg.BrowserProfileDataDir)
+	}
+	if cfg.SessionEvictionPolicy != SessionEvictionReject {
+		t.Errorf("Expected default session eviction policy %q, got %q", SessionEvictionReject, cfg.SessionEvictionPolicy)
+	}
 
 	// Timeout defaults
 	if cfg.DefaultTimeout != 60*time.Second {
@@ -72,6 +95,185 @@ func TestLoadDefaults(t *testing.T) {
 	if cfg.LogHTML {
 		t.Error("Expected LogHTML to be false by default")
 	}
+
+	// Script evaluation is opt-in
+	if cfg.EvaluateJsEnabled {
+		t.Error("Expected EvaluateJsEnabled to be false by default")
+	}
+	if cfg.RecordSolveDir != "" {
+		t.Errorf("Expected no RecordSolveDir by default, got %q", cfg.RecordSolveDir)
+	}
+}
+
+func TestValidateRecordSolveDir(t *testing.T) {
+	cfg := Load()
+	cfg.RecordSolveDir = "recordings/../recordings"
+	cfg.Validate()
+	if !filepath.IsAbs(cfg.RecordSolveDir) || filepath.Base(cfg.RecordSolveDir) != "recordings" {
+		t.Errorf("RecordSolveDir = %q, want an absolute recordings path", cfg.RecordSolveDir)
+	}
+}
+
+func TestValidateQuietHours(t *testing.T) {
+	cfg := Load()
+	cfg.QuietHours = "example.com=sat-sun 00:00-06:00 Europe/London"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with a valid QUIET_HOURS = %v", err)
+	}
+	cfg.QuietHours = "example.com=25:00-26:00"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should refuse a QUIET_HOURS that does not parse")
+	}
+}
+
+func TestValidateProxyPoolsPath(t *testing.T) {
+	cfg := Load()
+	cfg.ProxyPoolsPath = "conf/../proxy-pools.yaml"
+	cfg.Validate()
+	if !filepath.IsAbs(cfg.ProxyPoolsPath) || filepath.Base(cfg.ProxyPoolsPath) != "proxy-pools.yaml" {
+		t.Errorf("ProxyPoolsPath = %q, want an absolute proxy-pools.yaml path", cfg.ProxyPoolsPath)
+	}
+}
+
+func TestValidateSelectorsRemotePublicKey(t *testing.T) {
+	cfg := Load()
+	cfg.SelectorsRemoteURL = "https://example.com/selectors.json"
+	cfg.SelectorsRemotePublicKey = "bm90IGEga2V5" // base64, but not 32 bytes
+	cfg.Validate()
+	if cfg.SelectorsRemoteURL != "" {
+		t.Error("remote selectors should be disabled with an invalid public key")
+	}
+
+	cfg.SelectorsRemoteURL = "https://example.com/selectors.json"
+	cfg.SelectorsRemotePublicKey = "11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo="
+	cfg.Validate()
+	if cfg.SelectorsRemoteURL == "" || cfg.SelectorsRemotePublicKey == "" {
+		t.Error("a valid public key should keep remote selectors enabled")
+	}
+}
+
+func TestValidateUserAgentRotation(t *testing.T) {
+	cfg := Load()
+	cfg.UserAgentRotation = " Request "
+	cfg.Validate()
+	if cfg.UserAgentRotation != UserAgentRotationRequest {
+		t.Errorf("UserAgentRotation = %q, want request", cfg.UserAgentRotation)
+	}
+	cfg.UserAgentRotation = "hourly"
+	cfg.Validate()
+	if cfg.UserAgentRotation != UserAgentRotationBrowser {
+		t.Errorf("UserAgentRotation = %q, want the browser default", cfg.UserAgentRotation)
+	}
+}
+
+func TestValidatePollSettings(t *testing.T) {
+	cfg := Load()
+	if cfg.PollIntervalMin != 800*time.Millisecond || cfg.PollIntervalMax != 1500*time.Millisecond || cfg.SelectorDetectionBudget != 5*time.Second {
+		t.Errorf("defaults = %v-%v, %v", cfg.PollIntervalMin, cfg.PollIntervalMax, cfg.SelectorDetectionBudget)
+	}
+	cfg.PollIntervalMin = 2 * time.Second
+	cfg.PollIntervalMax = time.Second
+	cfg.PollMaxAttempts = -1
+	cfg.SelectorDetectionBudget = time.Hour
+	cfg.Validate()
+	if cfg.PollIntervalMax != 2*time.Second || cfg.PollMaxAttempts != 0 || cfg.SelectorDetectionBudget != time.Minute {
+		t.Errorf("clamped to max %v, attempts %d, budget %v", cfg.PollIntervalMax, cfg.PollMaxAttempts, cfg.SelectorDetectionBudget)
+	}
+	cfg.PollIntervalMin = time.Millisecond
+	cfg.Validate()
+	if cfg.PollIntervalMin != 50*time.Millisecond {
+		t.Errorf("PollIntervalMin = %v, want 50ms", cfg.PollIntervalMin)
+	}
+}
+
+func TestValidateResponseLimits(t *testing.T) {
+	cfg := Load()
+	if cfg.MaxResponseSizeKB != 10*1024 || cfg.MaxScreenshotSizeKB != 5*1024 || cfg.MaxResponseCookies != 100 || cfg.MaxStorageItems != 100 || cfg.MaxStorageSizeKB != 1024 {
+		t.Errorf("defaults = %d, %d, %d, %d, %d", cfg.MaxResponseSizeKB, cfg.MaxScreenshotSizeKB, cfg.MaxResponseCookies, cfg.MaxStorageItems, cfg.MaxStorageSizeKB)
+	}
+	cfg.MaxResponseSizeKB = 1 << 20
+	cfg.MaxResponseCookies = 0
+	cfg.MaxStorageSizeKB = -5
+	cfg.Validate()
+	if cfg.MaxResponseSizeKB != 100*1024 || cfg.MaxResponseCookies != 100 || cfg.MaxStorageSizeKB != 1024 {
+		t.Errorf("clamped to %dKB, %d cookies, %dKB storage", cfg.MaxResponseSizeKB, cfg.MaxResponseCookies, cfg.MaxStorageSizeKB)
+	}
+}
+
+func TestValidateProxyHealth(t *testing.T) {
+	cfg := Load()
+	cfg.ProxyHealthCheckEnabled = true
+	cfg.ProxyHealthInterval = time.Second
+	cfg.ProxyHealthTimeout = time.Hour
+	cfg.ProxyHealthFailures = 0
+	cfg.ProxyHealthCanaryURL = "not a url"
+	cfg.Validate()
+	if cfg.ProxyHealthInterval != 30*time.Second || cfg.ProxyHealthTimeout != 2*time.Minute || cfg.ProxyHealthFailures != 1 {
+		t.Errorf("clamped to %v, %v, %d", cfg.ProxyHealthInterval, cfg.ProxyHealthTimeout, cfg.ProxyHealthFailures)
+	}
+	if cfg.ProxyHealthCanaryURL != "" || !cfg.ProxyHealthCheckEnabled {
+		t.Errorf("invalid canary URL should be dropped, keeping checks enabled: %q, %v", cfg.ProxyHealthCanaryURL, cfg.ProxyHealthCheckEnabled)
+	}
+
+	cfg.ProxyHealthIPURL = "ftp://example.com"
+	cfg.Validate()
+	if cfg.ProxyHealthCheckEnabled {
+		t.Error("proxy health checks should be disabled without a valid IP URL")
+	}
+}
+
+func TestTracingSampleRatio(t *testing.T) {
+	t.Setenv("TRACING_SAMPLE_RATIO", "0.25")
+	if cfg := Load(); cfg.TracingSampleRatio != 0.25 {
+		t.Errorf("TracingSampleRatio = %v, want 0.25", cfg.TracingSampleRatio)
+	}
+	t.Setenv("TRACING_SAMPLE_RATIO", "lots")
+	cfg := Load()
+	if cfg.TracingSampleRatio != 1 {
+		t.Errorf("invalid ratio: TracingSampleRatio = %v, want the default 1", cfg.TracingSampleRatio)
+	}
+	cfg.TracingSampleRatio = 4
+	cfg.Validate()
+	if cfg.TracingSampleRatio != 1 {
+		t.Errorf("out of range ratio clamped to %v, want 1", cfg.TracingSampleRatio)
+	}
+}
+
+func TestValidateTLS(t *testing.T) {
+	cfg := Load()
+	cfg.TLSCertFile = "/certs/tls.crt"
+	cfg.Validate()
+	if cfg.TLSCertFile != "" || cfg.TLSEnabled() {
+		t.Errorf("certificate without key should be ignored, got %q", cfg.TLSCertFile)
+	}
+
+	cfg.TLSCertFile, cfg.TLSKeyFile = "/certs/tls.crt", "/certs/tls.key"
+	cfg.TLSACMEDomains = []string{"solver.example.com"}
+	cfg.Validate()
+	if !cfg.TLSEnabled() || cfg.TLSACMEDomains != nil {
+		t.Errorf("static certificate should win over ACME: %q, %v", cfg.TLSCertFile, cfg.TLSACMEDomains)
+	}
+
+	cfg.TLSCertFile, cfg.TLSKeyFile = "", ""
+	cfg.TLSACMEDomains = []string{"solver.example.com"}
+	cfg.TLSACMECacheDir = ""
+	cfg.TLSACMEDirectoryURL = "http://insecure.example/directory"
+	cfg.Validate()
+	if !cfg.TLSEnabled() || cfg.TLSACMECacheDir == "" || cfg.TLSACMEDirectoryURL != "" {
+		t.Errorf("ACME settings = %q, %q", cfg.TLSACMECacheDir, cfg.TLSACMEDirectoryURL)
+	}
+
+	cfg.TLSClientCAFile = "/certs/clients-ca.pem"
+	cfg.TLSClientAuth = "Sometimes"
+	cfg.Validate()
+	if cfg.TLSClientCAFile == "" || cfg.TLSClientAuth != "require" {
+		t.Errorf("client auth = %q, %q; want the CA kept and mode require", cfg.TLSClientCAFile, cfg.TLSClientAuth)
+	}
+	cfg.TLSACMEDomains = nil
+	cfg.Validate()
+	if cfg.TLSClientCAFile != "" {
+		t.Error("client CA without TLS should be ignored")
+	}
 }
 
 func TestLoadFromEnv(t *testing.T) {
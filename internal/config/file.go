@@ -0,0 +1,87 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// fileValues holds the settings read from CONFIG_FILE, nil when none is set.
+var fileValues atomic.Pointer[map[string]string]
+
+// overrideValues holds the settings given by SetOverrides, nil when none are.
+var overrideValues atomic.Pointer[map[string]string]
+
+// SetOverrides sets values that take precedence over CONFIG_FILE and the
+// environment, such as command-line flags. Keys are variable names.
+func SetOverrides(values map[string]string) {
+	overrideValues.Store(&values)
+}
+
+// getenv returns a setting from the overrides or CONFIG_FILE, falling back
+// to the environment.
+func getenv(key string) string {
+	for _, layer := range []*map[string]string{overrideValues.Load(), fileValues.Load()} {
+		if layer != nil {
+			if value, ok := (*layer)[key]; ok {
+				return value
+			}
+		}
+	}
+	return os.Getenv(key)
+}
+
+// loadConfigFile (re)reads the file named by CONFIG_FILE. On error the
+// settings read last time stay in effect.
+func loadConfigFile() error {
+	path := os.Getenv("CONFIG_FILE")
+	if values := overrideValues.Load(); values != nil && (*values)["CONFIG_FILE"] != "" {
+		path = (*values)["CONFIG_FILE"]
+	}
+	if path == "" {
+		fileValues.Store(nil)
+		return nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from operator configuration
+	if err != nil {
+		return fmt.Errorf("failed to read CONFIG_FILE: %w", err)
+	}
+	values, err := parseConfigFile(data)
+	if err != nil {
+		return fmt.Errorf("CONFIG_FILE %s: %w", path, err)
+	}
+	fileValues.Store(&values)
+	return nil
+}
+
+// parseConfigFile parses KEY=VALUE lines in the style of a Docker env file.
+// Blank lines and lines starting with # are skipped, an "export " prefix is
+// allowed and a value may be wrapped in single or double quotes.
+func parseConfigFile(data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", n)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseConfigFile(t *testing.T) {
+	values, err := parseConfigFile([]byte(`
+# Reloadable settings
+LOG_LEVEL=debug
+export RATE_LIMIT_RPM = 120
+TARGET_DENYLIST="example.com, example.org"
+QUIET_HOURS='example.net 01:00-05:00'
+EMPTY=
+`))
+	if err != nil {
+		t.Fatalf("parseConfigFile() error = %v", err)
+	}
+	want := map[string]string{
+		"LOG_LEVEL":       "debug",
+		"RATE_LIMIT_RPM":  "120",
+		"TARGET_DENYLIST": "example.com, example.org",
+		"QUIET_HOURS":     "example.net 01:00-05:00",
+		"EMPTY":           "",
+	}
+	if len(values) != len(want) {
+		t.Errorf("got %d values, want %d: %v", len(values), len(want), values)
+	}
+	for k, v := range want {
+		if values[k] != v {
+			t.Errorf("%s = %q, want %q", k, values[k], v)
+		}
+	}
+
+	for _, bad := range []string{"LOG_LEVEL", "=debug", "LOG LEVEL=debug"} {
+		if _, err := parseConfigFile([]byte(bad)); err == nil {
+			t.Errorf("parseConfigFile(%q) should fail", bad)
+		}
+	}
+}
+
+func TestConfigFileOverridesEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flaresolverr.env")
+	if err := os.WriteFile(path, []byte("LOG_LEVEL=debug\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("RATE_LIMIT_RPM", "30")
+	t.Cleanup(func() { fileValues.Store(nil) })
+
+	cfg := Load()
+	if cfg.LogLevel != "debug" {
+		t.Errorf("LogLevel = %q, want the file's debug", cfg.LogLevel)
+	}
+	if cfg.RateLimitRPM != 30 {
+		t.Errorf("RateLimitRPM = %d, want the environment's 30", cfg.RateLimitRPM)
+	}
+
+	// Reload picks up edits
+	if err := os.WriteFile(path, []byte("LOG_LEVEL=error\nRATE_LIMIT_RPM=90\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if cfg.LogLevel != "error" || cfg.RateLimitRPM != 90 {
+		t.Errorf("after Reload LogLevel = %q, RateLimitRPM = %d, want error and 90", cfg.LogLevel, cfg.RateLimitRPM)
+	}
+
+	// A broken edit fails the reload and keeps the previous file's values
+	if err := os.WriteFile(path, []byte("not a setting\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Reload(); err == nil {
+		t.Fatal("Reload() of a broken file should fail")
+	}
+	if got := getenv("LOG_LEVEL"); got != "error" {
+		t.Errorf("LOG_LEVEL = %q after failed reload, want the previous error", got)
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/secrets"
+)
+
+// secretSettings maps the variables that may hold credentials to their fields.
+func (c *Config) secretSettings() []struct {
+	name  string
+	value *string
+} {
+	return []struct {
+		name  string
+		value *string
+	}{
+		{"API_KEY", &c.APIKey},
+		{"TWOCAPTCHA_API_KEY", &c.Captcha2CaptchaAPIKey},
+		{"CAPSOLVER_API_KEY", &c.CaptchaCapSolverAPIKey},
+		{"ANTICAPTCHA_API_KEY", &c.CaptchaAntiCaptchaAPIKey},
+		{"CAPMONSTER_API_KEY", &c.CaptchaCapMonsterAPIKey},
+		{"NINEKW_API_KEY", &c.Captcha9kwAPIKey},
+		{"CAPTCHA_WEBHOOK_TOKEN", &c.CaptchaWebhookToken},
+		{"PROXY_URL", &c.ProxyURL},
+		{"PROXY_USERNAME", &c.ProxyUsername},
+		{"PROXY_PASSWORD", &c.ProxyPassword},
+		{"PROXY_LIST", &c.ProxyList},
+		{"SESSION_REDIS_URL", &c.SessionRedisURL},
+		{"ADMIN_PASSWORD", &c.AdminPassword},
+	}
+}
+
+// ResolveSecrets completes the credential settings. A variable left unset
+// is read from the file named by its _FILE variant (Docker and Kubernetes
+// secrets), and a value such as "vault:secret/data/flaresolverr#capsolver"
+// is replaced by the secret it refers to.
+func (c *Config) ResolveSecrets(ctx context.Context) error {
+	return c.resolveSecrets(ctx, getenv)
+}
+
+func (c *Config) resolveSecrets(ctx context.Context, getenv func(string) string) error {
+	resolver := secrets.NewResolver(getenv)
+	for _, s := range c.secretSettings() {
+		if file := getenv(s.name + "_FILE"); file != "" {
+			if *s.value != "" {
+				log.Warn().Str("key", s.name).Msg("Both " + s.name + " and " + s.name + "_FILE are set, using " + s.name)
+			} else {
+				value, err := secrets.ReadFile(file)
+				if err != nil {
+					return fmt.Errorf("%s_FILE: %w", s.name, err)
+				}
+				*s.value = value
+			}
+		}
+		if !secrets.IsReference(*s.value) {
+			continue
+		}
+		value, err := resolver.Resolve(ctx, *s.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.name, err)
+		}
+		*s.value = value
+		log.Debug().Str("key", s.name).Msg("Secret resolved from secret store")
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSecretsFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "capsolver")
+	if err := os.WriteFile(keyFile, []byte("CAP-123\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	passwordFile := filepath.Join(dir, "proxy_password")
+	if err := os.WriteFile(passwordFile, []byte("from-file"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{
+		"CAPSOLVER_API_KEY_FILE": keyFile,
+		"PROXY_PASSWORD_FILE":    passwordFile,
+	}
+
+	c := &Config{ProxyPassword: "from-env"}
+	if err := c.resolveSecrets(context.Background(), func(name string) string { return env[name] }); err != nil {
+		t.Fatalf("resolveSecrets() error = %v", err)
+	}
+	if c.CaptchaCapSolverAPIKey != "CAP-123" {
+		t.Errorf("CaptchaCapSolverAPIKey = %q, want the file contents", c.CaptchaCapSolverAPIKey)
+	}
+	if c.ProxyPassword != "from-env" {
+		t.Errorf("ProxyPassword = %q, the variable should win over its _FILE", c.ProxyPassword)
+	}
+
+	env["API_KEY_FILE"] = filepath.Join(dir, "missing")
+	if err := (&Config{}).resolveSecrets(context.Background(), func(name string) string { return env[name] }); err == nil {
+		t.Error("missing API_KEY_FILE: expected error")
+	}
+}
+
+func TestResolveSecretsReportsUnresolvableReference(t *testing.T) {
+	c := &Config{APIKey: "vault:secret/data/flaresolverr#api_key"}
+	if err := c.resolveSecrets(context.Background(), func(string) string { return "" }); err == nil {
+		t.Error("vault reference without VAULT_ADDR: expected error")
+	}
+}
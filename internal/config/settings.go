@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Setting describes one configuration variable.
+type Setting struct {
+	Key     string // Environment variable name
+	Default string // Default value, empty when unset
+	Bool    bool   // Takes true or false
+}
+
+// settingRecorder, while Settings runs, collects each variable Load reads.
+var (
+	settingRecorder atomic.Pointer[func(key string, defaultValue any)]
+	settingsMu      sync.Mutex
+)
+
+// setting returns the value of a variable Load reads. While Settings runs it
+// records the variable and returns nothing, so every default is kept.
+func setting(key string, defaultValue any) string {
+	if record := settingRecorder.Load(); record != nil {
+		(*record)(key, defaultValue)
+		return ""
+	}
+	return getenv(key)
+}
+
+// Settings lists every configuration variable with its default: CONFIG_FILE,
+// the variables Load reads in the order it reads them, then the _FILE and
+// secret store variables ResolveSecrets reads.
+func Settings() []Setting {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+
+	list := []Setting{{Key: "CONFIG_FILE"}}
+	record := func(key string, defaultValue any) {
+		_, isBool := defaultValue.(bool)
+		list = append(list, Setting{Key: key, Default: formatDefault(defaultValue), Bool: isBool})
+	}
+	settingRecorder.Store(&record)
+	c := load()
+	settingRecorder.Store(nil)
+
+	for _, s := range c.secretSettings() {
+		list = append(list, Setting{Key: s.name + "_FILE"})
+	}
+	for _, key := range []string{"VAULT_ADDR", "VAULT_TOKEN", "VAULT_TOKEN_FILE", "VAULT_NAMESPACE"} {
+		list = append(list, Setting{Key: key})
+	}
+	return list
+}
+
+// formatDefault renders a value the way its variable would be written.
+func formatDefault(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Duration:
+		s := v.String()
+		if strings.HasSuffix(s, "m0s") {
+			s = strings.TrimSuffix(s, "0s")
+		}
+		if strings.HasSuffix(s, "h0m") {
+			s = strings.TrimSuffix(s, "0m")
+		}
+		return s
+	case []string:
+		return strings.Join(v, ",")
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSettings(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "debug") // defaults are listed, not the environment
+
+	settings := Settings()
+	byKey := make(map[string]Setting, len(settings))
+	for _, s := range settings {
+		if _, dup := byKey[s.Key]; dup {
+			t.Errorf("%s listed twice", s.Key)
+		}
+		byKey[s.Key] = s
+	}
+
+	for key, want := range map[string]Setting{
+		"CONFIG_FILE":       {Key: "CONFIG_FILE"},
+		"LOG_LEVEL":         {Key: "LOG_LEVEL", Default: "info"},
+		"HEADLESS":          {Key: "HEADLESS", Default: "true", Bool: true},
+		"SESSION_TTL":       {Key: "SESSION_TTL", Default: "30m"},
+		"API_KEY_FILE":      {Key: "API_KEY_FILE"},
+		"VAULT_ADDR":        {Key: "VAULT_ADDR"},
+		"BROWSER_POOL_SIZE": {Key: "BROWSER_POOL_SIZE", Default: "3"},
+	} {
+		if got := byKey[key]; got != want {
+			t.Errorf("%s = %+v, want %+v", key, got, want)
+		}
+	}
+
+	// Listing the settings leaves Load reading the environment
+	if got := Load().LogLevel; got != "debug" {
+		t.Errorf("LogLevel after Settings = %q, want debug", got)
+	}
+}
+
+func TestFormatDefault(t *testing.T) {
+	for _, tt := range []struct {
+		v    any
+		want string
+	}{
+		{90 * time.Second, "1m30s"},
+		{2 * time.Hour, "2h"},
+		{0 * time.Second, "0s"},
+		{[]string{"a", "b"}, "a,b"},
+		{0.5, "0.5"},
+	} {
+		if got := formatDefault(tt.v); got != tt.want {
+			t.Errorf("formatDefault(%v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestSetOverrides(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("PORT", "9000")
+	t.Cleanup(func() { SetOverrides(nil) })
+
+	SetOverrides(map[string]string{"LOG_LEVEL": "debug"})
+	cfg := Load()
+	if cfg.LogLevel != "debug" {
+		t.Errorf("LogLevel = %q, want the override", cfg.LogLevel)
+	}
+	if cfg.Port != 9000 {
+		t.Errorf("Port = %d, want the environment's 9000", cfg.Port)
+	}
+}
@@ -127,6 +127,11 @@ func (rw *recordingWriter) Flush() {
 	}
 }
 
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // RecordRequests returns HTTP middleware that records each request
 // into the dashboard's event buffer.
 func RecordRequests(buf *EventBuffer) func(http.Handler) http.Handler {
@@ -0,0 +1,163 @@
+// Package events broadcasts operational events (browsers spawned and
+// recycled, challenges met, solves finished, sessions created and destroyed,
+// rate limits hit) to live subscribers for the /admin/events stream.
+//
+// Events are published on a process-wide bus so the pool, session manager
+// and solver need no extra wiring. Publishing costs a single atomic load
+// while nobody is subscribed.
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/middleware"
+)
+
+// Event types.
+const (
+	BrowserSpawned     = "browser.spawned"
+	BrowserRecycled    = "browser.recycled"
+	ChallengeDetected  = "challenge.detected"
+	SolveSucceeded     = "solve.succeeded"
+	SolveFailed        = "solve.failed"
+	SessionCreated     = "session.created"
+	SessionDestroyed   = "session.destroyed"
+	RateLimitTriggered = "rate_limit.triggered"
+)
+
+// subscriberBuffer is how many events a slow subscriber may fall behind
+// before further events are dropped for it.
+const subscriberBuffer = 256
+
+// ErrTooManySubscribers is returned when the subscriber limit is reached.
+var ErrTooManySubscribers = errors.New("too many event stream subscribers")
+
+// Event is one operational event. Fields that do not apply are left empty.
+type Event struct {
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	RequestID  string    `json:"requestId,omitempty"`
+	Session    string    `json:"session,omitempty"`
+	Domain     string    `json:"domain,omitempty"`
+	Challenge  string    `json:"challenge,omitempty"`  // challenge.detected
+	PID        int       `json:"pid,omitempty"`        // Local browser process, browser.*
+	Reason     string    `json:"reason,omitempty"`     // Why a browser was recycled or a session destroyed
+	Status     int       `json:"status,omitempty"`     // Target HTTP status, solve.*
+	Error      string    `json:"error,omitempty"`      // solve.failed
+	ErrorCode  string    `json:"errorCode,omitempty"`  // rate_limit.triggered
+	DurationMs int64     `json:"durationMs,omitempty"` // solve.*
+}
+
+// Subscription receives events of the requested types.
+type Subscription struct {
+	types   map[string]bool // nil receives every type
+	ch      chan Event
+	dropped atomic.Int64
+}
+
+// Events returns the channel events are delivered on. It is closed when the
+// subscription is cancelled.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Dropped returns how many events were discarded because the subscriber fell behind.
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Bus fans events out to subscribers. Publish never blocks on subscribers.
+type Bus struct {
+	mu             sync.RWMutex
+	subs           map[*Subscription]struct{}
+	count          atomic.Int32
+	maxSubscribers atomic.Int32
+}
+
+// NewBus creates a bus allowing up to maxSubscribers concurrent subscriptions.
+func NewBus(maxSubscribers int) *Bus {
+	b := &Bus{subs: make(map[*Subscription]struct{})}
+	b.maxSubscribers.Store(int32(maxSubscribers))
+	return b
+}
+
+// defaultBus receives everything published with Publish.
+var defaultBus = NewBus(5)
+
+// Default returns the process-wide bus.
+func Default() *Bus {
+	return defaultBus
+}
+
+// SetMaxSubscribers changes the subscription limit for new subscriptions.
+func (b *Bus) SetMaxSubscribers(n int) {
+	b.maxSubscribers.Store(int32(n))
+}
+
+// Subscribe registers a subscription for the given event types, or for all
+// types when none are given.
+func (b *Bus) Subscribe(types ...string) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.subs) >= int(b.maxSubscribers.Load()) {
+		return nil, ErrTooManySubscribers
+	}
+	sub := &Subscription{ch: make(chan Event, subscriberBuffer)}
+	if len(types) > 0 {
+		sub.types = make(map[string]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+	b.subs[sub] = struct{}{}
+	b.count.Add(1)
+	return sub, nil
+}
+
+// Unsubscribe removes a subscription and closes its channel.
+func (b *Bus) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+	delete(b.subs, sub)
+	b.count.Add(-1)
+	close(sub.ch)
+}
+
+// Publish delivers e to the subscribers of its type. The time is set when
+// missing, and the request ID is taken from ctx when missing.
+func (b *Bus) Publish(ctx context.Context, e Event) {
+	if b.count.Load() == 0 {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.RequestID == "" && ctx != nil {
+		e.RequestID = middleware.RequestIDFromContext(ctx)
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		if sub.types != nil && !sub.types[e.Type] {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Publish publishes e on the process-wide bus.
+func Publish(ctx context.Context, e Event) {
+	defaultBus.Publish(ctx, e)
+}
@@ -0,0 +1,77 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/middleware"
+)
+
+func TestBus_TypeFilter(t *testing.T) {
+	b := NewBus(5)
+	all, _ := b.Subscribe()
+	sessions, _ := b.Subscribe(SessionCreated, SessionDestroyed)
+
+	b.Publish(context.Background(), Event{Type: BrowserSpawned, PID: 42})
+	b.Publish(context.Background(), Event{Type: SessionCreated, Session: "s1"})
+
+	if n := len(all.Events()); n != 2 {
+		t.Errorf("unfiltered subscriber got %d events, want 2", n)
+	}
+	if n := len(sessions.Events()); n != 1 {
+		t.Fatalf("session subscriber got %d events, want 1", n)
+	}
+	e := <-sessions.Events()
+	if e.Type != SessionCreated || e.Session != "s1" || e.Time.IsZero() {
+		t.Errorf("event = %+v, want session.created for s1 with a time", e)
+	}
+}
+
+func TestBus_RequestIDFromContext(t *testing.T) {
+	b := NewBus(1)
+	sub, _ := b.Subscribe()
+
+	var ctx context.Context
+	middleware.RequestID(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/v1", nil))
+
+	b.Publish(ctx, Event{Type: SolveSucceeded})
+	b.Publish(nil, Event{Type: SolveFailed}) //nolint:staticcheck // background publishers may have no context
+	if e := <-sub.Events(); e.RequestID == "" || e.RequestID != middleware.RequestIDFromContext(ctx) {
+		t.Errorf("RequestID = %q, want the request's ID", e.RequestID)
+	}
+	if e := <-sub.Events(); e.RequestID != "" {
+		t.Errorf("RequestID = %q, want none without a context", e.RequestID)
+	}
+}
+
+func TestBus_DropsAndLimits(t *testing.T) {
+	b := NewBus(1)
+	sub, err := b.Subscribe()
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if _, err := b.Subscribe(); !errors.Is(err, ErrTooManySubscribers) {
+		t.Errorf("second Subscribe() error = %v, want ErrTooManySubscribers", err)
+	}
+
+	for i := 0; i < subscriberBuffer+3; i++ {
+		b.Publish(context.Background(), Event{Type: BrowserRecycled})
+	}
+	if got := sub.Dropped(); got != 3 {
+		t.Errorf("Dropped() = %d, want 3", got)
+	}
+
+	b.Unsubscribe(sub)
+	b.Unsubscribe(sub) // idempotent
+	if _, ok := <-sub.Events(); !ok {
+		t.Error("buffered events should still be readable after Unsubscribe")
+	}
+	if _, err := b.Subscribe(); err != nil {
+		t.Errorf("Subscribe() after Unsubscribe error = %v", err)
+	}
+}
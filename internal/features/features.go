@@ -0,0 +1,221 @@
+// Package features gates experimental solver behaviors behind named flags, so
+// a risky change can be switched on or off per deployment (FEATURE_FLAGS) or
+// per domain without a code edit, and toggled at runtime from the admin
+// dashboard.
+package features
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Flag names.
+const (
+	TurnstileWait        = "turnstile.wait"
+	TurnstileFrames      = "turnstile.frames"
+	TurnstileKeyboard    = "turnstile.keyboard"
+	TurnstileShadow      = "turnstile.shadow"
+	TurnstileWidget      = "turnstile.widget"
+	TurnstileIframe      = "turnstile.iframe"
+	TurnstilePositional  = "turnstile.positional"
+	TurnstileInterceptor = "turnstile.interceptor"
+)
+
+// Flag is a known feature flag.
+type Flag struct {
+	Name        string
+	Description string
+	Default     bool
+}
+
+// Known lists every flag. Names not listed here are rejected, so a typo
+// cannot silently leave a behavior on.
+var Known = []Flag{
+	{Name: TurnstileWait, Description: "Turnstile method: wait for the widget to pass unaided", Default: true},
+	{Name: TurnstileFrames, Description: "Turnstile method: click through the managed challenge's nested iframes", Default: true},
+	{Name: TurnstileKeyboard, Description: "Turnstile method: tab to the checkbox and press space", Default: true},
+	{Name: TurnstileShadow, Description: "Turnstile method: reach the checkbox through closed shadow roots over CDP", Default: true},
+	{Name: TurnstileWidget, Description: "Turnstile method: click the widget container", Default: true},
+	{Name: TurnstileIframe, Description: "Turnstile method: click inside the challenge iframe", Default: true},
+	{Name: TurnstilePositional, Description: "Turnstile method: click where the checkbox is expected to be", Default: true},
+	{Name: TurnstileInterceptor, Description: "Hook turnstile.render to hand the widget parameters to external solvers", Default: true},
+}
+
+// lookup returns the known flag named name.
+func lookup(name string) (Flag, bool) {
+	for _, f := range Known {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Flag{}, false
+}
+
+// Flags holds the flag settings: deployment-wide values and per-domain
+// values, on top of each flag's default. A nil *Flags uses the defaults.
+// It is safe for concurrent use.
+type Flags struct {
+	mu      sync.RWMutex
+	global  map[string]bool            // name -> enabled
+	domains map[string]map[string]bool // domain -> name -> enabled
+}
+
+// New returns Flags with every flag at its default.
+func New() *Flags {
+	return &Flags{global: map[string]bool{}, domains: map[string]map[string]bool{}}
+}
+
+// Parse parses a FEATURE_FLAGS value: comma- and/or newline-separated
+// "name=on|off" entries, or "name@domain=on|off" for a domain and its
+// subdomains. true/false and 1/0 are accepted too. Blank entries and
+// #-comments are skipped.
+func Parse(raw string) (*Flags, error) {
+	f := New()
+	entries := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	})
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" || strings.HasPrefix(e, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(e, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid entry %q: want name=on|off or name@domain=on|off", e)
+		}
+		enabled, err := parseValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry %q: %w", e, err)
+		}
+		name, domain, _ := strings.Cut(strings.TrimSpace(key), "@")
+		if err := f.Set(name, domain, &enabled); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// parseValue parses an on/off value.
+func parseValue(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return false, fmt.Errorf("value must be on or off")
+	}
+	return enabled, nil
+}
+
+// Set sets a flag deployment-wide (empty domain) or for domain and its
+// subdomains. A nil enabled removes the setting, falling back to the
+// deployment-wide value or the default.
+func (f *Flags) Set(name, domain string, enabled *bool) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if _, ok := lookup(name); !ok {
+		return fmt.Errorf("unknown feature flag %q", name)
+	}
+	domain = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+	if domain != "" && (strings.ContainsAny(domain, "/:* ") || !strings.Contains(domain, ".")) {
+		return fmt.Errorf("invalid domain %q for feature flag %s", domain, name)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	values := f.global
+	if domain != "" {
+		if f.domains[domain] == nil {
+			f.domains[domain] = map[string]bool{}
+		}
+		values = f.domains[domain]
+	}
+	if enabled == nil {
+		delete(values, name)
+		if domain != "" && len(values) == 0 {
+			delete(f.domains, domain)
+		}
+		return nil
+	}
+	values[name] = *enabled
+	return nil
+}
+
+// Enabled reports whether a flag is on for host: the setting for host or its
+// nearest parent domain, else the deployment-wide setting, else the default.
+// An empty host uses the deployment-wide setting.
+func (f *Flags) Enabled(name, host string) bool {
+	flag, ok := lookup(name)
+	if !ok {
+		return false
+	}
+	if f == nil {
+		return flag.Default
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for d := strings.ToLower(strings.TrimSuffix(host, ".")); d != ""; {
+		if enabled, ok := f.domains[d][name]; ok {
+			return enabled
+		}
+		_, parent, found := strings.Cut(d, ".")
+		if !found || !strings.Contains(parent, ".") {
+			break
+		}
+		d = parent
+	}
+	if enabled, ok := f.global[name]; ok {
+		return enabled
+	}
+	return flag.Default
+}
+
+// State is a flag's current setting, as listed by the admin endpoint.
+type State struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Default     bool            `json:"default"`
+	Enabled     bool            `json:"enabled"`           // Deployment-wide
+	Domains     map[string]bool `json:"domains,omitempty"` // Per-domain settings
+}
+
+// States returns every known flag with its settings, in the order of Known.
+func (f *Flags) States() []State {
+	states := make([]State, 0, len(Known))
+	for _, flag := range Known {
+		s := State{Name: flag.Name, Description: flag.Description, Default: flag.Default, Enabled: f.Enabled(flag.Name, "")}
+		if f != nil {
+			f.mu.RLock()
+			for domain, values := range f.domains {
+				if enabled, ok := values[flag.Name]; ok {
+					if s.Domains == nil {
+						s.Domains = map[string]bool{}
+					}
+					s.Domains[domain] = enabled
+				}
+			}
+			f.mu.RUnlock()
+		}
+		states = append(states, s)
+	}
+	return states
+}
+
+// Overridden returns the names of the flags set away from their default
+// anywhere, sorted, for the startup log.
+func (f *Flags) Overridden() []string {
+	var names []string
+	for _, s := range f.States() {
+		if s.Enabled != s.Default || len(s.Domains) > 0 {
+			names = append(names, s.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,89 @@
+package features
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	f, err := Parse("turnstile.positional=off, turnstile.shadow@example.com=false\n# comment\nturnstile.wait@shop.example.com=on")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	tests := []struct {
+		name, host string
+		want       bool
+	}{
+		{TurnstilePositional, "", false},
+		{TurnstilePositional, "example.com", false},
+		{TurnstileShadow, "", true},
+		{TurnstileShadow, "example.com", false},
+		{TurnstileShadow, "www.example.com", false}, // inherits from the parent domain
+		{TurnstileShadow, "notexample.com", true},
+		{TurnstileWait, "shop.example.com", true},
+		{TurnstileInterceptor, "example.com", true}, // default
+		{"turnstile.teleport", "", false},           // unknown flags are off
+	}
+	for _, tt := range tests {
+		if got := f.Enabled(tt.name, tt.host); got != tt.want {
+			t.Errorf("Enabled(%q, %q) = %v, want %v", tt.name, tt.host, got, tt.want)
+		}
+	}
+	if got := f.Overridden(); len(got) != 3 {
+		t.Errorf("Overridden() = %v, want 3 flags", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, raw := range []string{
+		"turnstile.positional",
+		"turnstile.teleport=on",
+		"turnstile.shadow=maybe",
+		"turnstile.shadow@localhost=on",
+		"turnstile.shadow@*.example.com=on",
+	} {
+		if _, err := Parse(raw); err == nil {
+			t.Errorf("Parse(%q) should fail", raw)
+		}
+	}
+}
+
+func TestNilFlags(t *testing.T) {
+	var f *Flags
+	if !f.Enabled(TurnstileShadow, "example.com") {
+		t.Error("nil Flags should use the defaults")
+	}
+	if states := f.States(); len(states) != len(Known) || !states[0].Enabled {
+		t.Errorf("nil States() = %+v", states)
+	}
+}
+
+func TestSet(t *testing.T) {
+	f := New()
+	off, on := false, true
+	if err := f.Set(TurnstileFrames, "", &off); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Set(TurnstileFrames, "Example.com.", &on); err != nil {
+		t.Fatal(err)
+	}
+	if f.Enabled(TurnstileFrames, "other.org") || !f.Enabled(TurnstileFrames, "example.com") {
+		t.Error("domain setting should win over the deployment-wide one")
+	}
+
+	// Clearing falls back a level at a time
+	if err := f.Set(TurnstileFrames, "example.com", nil); err != nil {
+		t.Fatal(err)
+	}
+	if f.Enabled(TurnstileFrames, "example.com") {
+		t.Error("cleared domain setting should fall back to the deployment-wide value")
+	}
+	if err := f.Set(TurnstileFrames, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if !f.Enabled(TurnstileFrames, "example.com") {
+		t.Error("cleared setting should fall back to the default")
+	}
+	if states := f.States(); states[1].Domains != nil {
+		t.Errorf("cleared domain still listed: %+v", states[1])
+	}
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"mime"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/features"
+	"github.com/Rorqualx/flaresolverr-go/internal/stats"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+	"github.com/Rorqualx/flaresolverr-go/pkg/version"
+)
+
+//go:embed admin
+var adminAssets embed.FS
+
+// adminCSP confines the dashboard page to its own embedded script and styles.
+const adminCSP = "default-src 'none'; script-src 'self'; style-src 'self'; connect-src 'self'; img-src 'self'; frame-ancestors 'none'; base-uri 'none'; form-action 'none'"
+
+// AdminState is the snapshot shown by the admin dashboard.
+type AdminState struct {
+	Version       string                           `json:"version"`
+	UptimeSeconds int64                            `json:"uptimeSeconds"`
+	Pool          *PoolStats                       `json:"pool,omitempty"`
+	Draining      bool                             `json:"draining"`
+	Sessions      []types.SessionDetail            `json:"sessions"`
+	Inflight      []InflightSolve                  `json:"inflight"`
+	Domains       map[string]stats.DomainStatsJSON `json:"domains"`
+	CaptchaSpend  []CaptchaSpend                   `json:"captchaSpend"`
+	Features      []features.State                 `json:"features"`
+}
+
+// CaptchaSpend is the usage of one external CAPTCHA provider since start.
+type CaptchaSpend struct {
+	Provider     string  `json:"provider"`
+	Attempts     int64   `json:"attempts"`
+	Successes    int64   `json:"successes"`
+	Failures     int64   `json:"failures"`
+	CostUSD      float64 `json:"costUsd"`
+	SolveSeconds float64 `json:"solveSeconds"`
+	LastError    string  `json:"lastError,omitempty"`
+}
+
+// AdminHandler serves the admin dashboard: the embedded page at /, its state
+// at GET /api/state, the pool actions at POST /api/pool/{recycle,drain,resize},
+// the configuration reload at POST /api/config/reload and the feature flags
+// at GET and POST /api/features.
+// It does no authentication of its own; the caller wraps it.
+func (h *Handler) AdminHandler() http.Handler {
+	assets, err := fs.Sub(adminAssets, "admin")
+	if err != nil {
+		panic(err) // the directory is embedded at build time
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServer(http.FS(assets)))
+	mux.HandleFunc("GET /api/state", h.handleAdminState)
+	mux.HandleFunc("POST /api/pool/recycle", h.adminAction(func(w http.ResponseWriter, r *http.Request, startTime time.Time) {
+		h.handlePoolRecycleAll(w, startTime)
+	}))
+	mux.HandleFunc("POST /api/pool/drain", h.adminAction(func(w http.ResponseWriter, r *http.Request, startTime time.Time) {
+		h.handlePoolDrain(w, startTime)
+	}))
+	mux.HandleFunc("POST /api/pool/resize", h.adminAction(func(w http.ResponseWriter, r *http.Request, startTime time.Time) {
+		var body struct {
+			Size int `json:"size"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&body); err != nil {
+			h.writeErrorWithStatus(w, http.StatusBadRequest, "Invalid JSON request", startTime)
+			return
+		}
+		h.handlePoolResize(w, r.Context(), &types.Request{Cmd: types.CmdPoolResize, PoolSize: body.Size}, startTime)
+	}))
+	mux.HandleFunc("POST /api/config/reload", h.adminAction(func(w http.ResponseWriter, r *http.Request, startTime time.Time) {
+		h.handleConfigReload(w, startTime)
+	}))
+	mux.HandleFunc("GET /api/features", h.handleFeatureList)
+	mux.HandleFunc("POST /api/features", h.adminAction(h.handleFeatureToggle))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", adminCSP)
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// adminAction guards a dashboard action against cross-site requests: a
+// browser only sends a JSON body cross-origin after a CORS preflight, which
+// this listener never answers, so a forged form post is refused here.
+func (h *Handler) adminAction(action func(w http.ResponseWriter, r *http.Request, startTime time.Time)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			h.writeErrorWithStatus(w, http.StatusUnsupportedMediaType, "Admin actions require a JSON request", startTime)
+			return
+		}
+		action(w, r, startTime)
+	}
+}
+
+// handleAdminState returns the dashboard snapshot.
+func (h *Handler) handleAdminState(w http.ResponseWriter, r *http.Request) {
+	state := AdminState{
+		Version:       version.Full(),
+		UptimeSeconds: int64(time.Since(serverStartTime) / time.Second),
+		Pool:          h.poolStats(),
+		Sessions:      []types.SessionDetail{},
+		Inflight:      h.inflight.snapshot(),
+		Domains:       map[string]stats.DomainStatsJSON{},
+		CaptchaSpend:  []CaptchaSpend{},
+		Features:      h.features.States(),
+	}
+	if h.pool != nil {
+		state.Draining = h.pool.Draining()
+	}
+	if h.sessions != nil {
+		if details, err := h.sessions.Details(r.Context(), ""); err == nil {
+			sort.Slice(details, func(i, j int) bool { return details[i].CreatedAt < details[j].CreatedAt })
+			state.Sessions = details
+		}
+	}
+	if h.domainStats != nil {
+		state.Domains = h.domainStats.AllStats()
+	}
+	if h.solver != nil {
+		for name, ps := range h.solver.CaptchaProviderStats() {
+			state.CaptchaSpend = append(state.CaptchaSpend, CaptchaSpend{
+				Provider:     name,
+				Attempts:     ps.Attempts,
+				Successes:    ps.Successes,
+				Failures:     ps.Failures,
+				CostUSD:      ps.TotalCost,
+				SolveSeconds: float64(ps.TotalTimeMs) / 1000,
+				LastError:    ps.LastError,
+			})
+		}
+		sort.Slice(state.CaptchaSpend, func(i, j int) bool {
+			return state.CaptchaSpend[i].Provider < state.CaptchaSpend[j].Provider
+		})
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, state)
+}
@@ -0,0 +1,185 @@
+"use strict";
+
+// Admin dashboard: polls /api/state and posts pool actions. Everything is
+// rendered with textContent, so values from targets are never parsed as HTML.
+
+const REFRESH_MS = 5000;
+
+function $(id) {
+  return document.getElementById(id);
+}
+
+function duration(seconds) {
+  seconds = Math.max(0, Math.round(seconds));
+  if (seconds < 60) return seconds + "s";
+  if (seconds < 3600) return Math.floor(seconds / 60) + "m " + (seconds % 60) + "s";
+  return Math.floor(seconds / 3600) + "h " + Math.floor((seconds % 3600) / 60) + "m";
+}
+
+function megabytes(bytes) {
+  return (bytes / (1024 * 1024)).toFixed(0) + " MB";
+}
+
+function setStatus(text, error) {
+  const el = $("status");
+  el.textContent = text;
+  el.className = error ? "error" : "";
+}
+
+function fillTable(id, rows, empty) {
+  const body = $(id);
+  body.replaceChildren();
+  if (rows.length === 0) {
+    const tr = body.insertRow();
+    const td = tr.insertCell();
+    td.colSpan = body.parentElement.tHead.rows[0].cells.length;
+    td.className = "empty";
+    td.textContent = empty;
+    return;
+  }
+  for (const cells of rows) {
+    const tr = body.insertRow();
+    for (const value of cells) {
+      tr.insertCell().textContent = value;
+    }
+  }
+}
+
+function renderPool(state) {
+  const dl = $("pool");
+  dl.replaceChildren();
+  const pool = state.pool;
+  if (!pool) {
+    dl.textContent = "No browser pool";
+    return;
+  }
+  const items = [
+    ["Size", pool.size + (state.draining ? " (draining)" : "")],
+    ["Available", pool.available],
+    ["Waiting", pool.waiting],
+    ["Acquired", pool.acquired],
+    ["Recycled", pool.recycled],
+    ["Errors", pool.errors],
+  ];
+  if (pool.memory) {
+    items.push(["Browser memory", megabytes(pool.memory.totalBytes)]);
+  }
+  for (const [name, value] of items) {
+    const div = document.createElement("div");
+    const dt = document.createElement("dt");
+    const dd = document.createElement("dd");
+    dt.textContent = name;
+    dd.textContent = value;
+    div.append(dt, dd);
+    dl.append(div);
+  }
+  if (document.activeElement !== $("size")) {
+    $("size").value = pool.size;
+  }
+}
+
+function render(state) {
+  $("version").textContent = "v" + state.version;
+  $("uptime").textContent = "up " + duration(state.uptimeSeconds);
+  renderPool(state);
+
+  const now = Date.now();
+  fillTable("inflight", state.inflight.map((s) => [
+    s.cmd, s.domain || "", s.session || "", s.requestId || "", duration((now - s.startedAt) / 1000),
+  ]), "No solves running");
+
+  fillTable("sessions", state.sessions.map((s) => [
+    s.id, s.currentUrl || "", s.proxy || "", s.requestCount, s.solveInProgress ? "yes" : "no",
+    duration(s.remainingTtlSeconds),
+  ]), "No sessions");
+
+  const domains = Object.entries(state.domains).sort((a, b) => b[1].requestCount - a[1].requestCount);
+  fillTable("domains", domains.map(([name, d]) => [
+    name, d.requestCount, d.requestCount ? Math.round((100 * d.successCount) / d.requestCount) + "%" : "",
+    d.errorCount, d.rateLimitCount, d.avgLatencyMs + " ms", d.suggestedDelayMs + " ms",
+  ]), "No domains yet");
+
+  fillTable("captcha", state.captchaSpend.map((c) => [
+    c.provider, c.attempts, c.successes, c.failures, c.costUsd.toFixed(4), duration(c.solveSeconds), c.lastError || "",
+  ]), "No CAPTCHA provider calls");
+
+  renderFeatures(state.features);
+}
+
+function renderFeatures(flags) {
+  fillTable("features", flags.map((f) => [
+    f.name, f.description, (f.enabled ? "on" : "off") + (f.enabled !== f.default ? " (changed)" : ""),
+    Object.entries(f.domains || {}).map(([domain, on]) => domain + "=" + (on ? "on" : "off")).join(", "), "",
+  ]), "No feature flags");
+  // Toggle buttons flip the deployment-wide setting
+  flags.forEach((f, i) => {
+    const button = document.createElement("button");
+    button.type = "button";
+    button.textContent = f.enabled ? "Turn off" : "Turn on";
+    button.addEventListener("click", () =>
+      action("api/features", { name: f.name, enabled: !f.enabled }));
+    $("features").rows[i].cells[4].append(button);
+  });
+}
+
+async function refresh() {
+  try {
+    const resp = await fetch("api/state", { cache: "no-store" });
+    if (!resp.ok) throw new Error("HTTP " + resp.status);
+    render(await resp.json());
+    setStatus("updated " + new Date().toLocaleTimeString(), false);
+  } catch (err) {
+    setStatus("refresh failed: " + err.message, true);
+  }
+}
+
+async function action(path, body, confirmText) {
+  if (confirmText && !window.confirm(confirmText)) return;
+  try {
+    const resp = await fetch(path, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(body || {}),
+    });
+    const result = await resp.json();
+    setStatus(result.message, result.status !== "ok");
+  } catch (err) {
+    setStatus(path + " failed: " + err.message, true);
+  }
+  refresh();
+}
+
+async function reloadConfig() {
+  try {
+    const resp = await fetch("api/config/reload", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: "{}",
+    });
+    const result = await resp.json();
+    if (!resp.ok) {
+      setStatus(result.message, true);
+      return;
+    }
+    const errors = result.errors || [];
+    if (errors.length > 0) {
+      setStatus("reload kept previous values: " + errors.join("; "), true);
+    } else {
+      setStatus("reloaded " + result.applied.join(", "), false);
+    }
+  } catch (err) {
+    setStatus("reload failed: " + err.message, true);
+  }
+}
+
+document.addEventListener("DOMContentLoaded", () => {
+  $("reload").addEventListener("click", reloadConfig);
+  $("recycle").addEventListener("click", () =>
+    action("api/pool/recycle", null, "Replace every pooled browser?"));
+  $("drain").addEventListener("click", () =>
+    action("api/pool/drain", null, "Close idle browsers and refuse new solves until the pool is resized?"));
+  $("resize").addEventListener("click", () =>
+    action("api/pool/resize", { size: Number($("size").value) }));
+  refresh();
+  setInterval(refresh, REFRESH_MS);
+});
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/features"
+	"github.com/Rorqualx/flaresolverr-go/internal/stats"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func TestAdminState(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+	h.domainStats = stats.NewManager()
+	defer h.domainStats.Close()
+	h.domainStats.RecordRequest("example.com", 1200, true, false)
+
+	done := h.trackSolve(httptest.NewRequest("POST", "/v1", nil), &types.Request{Cmd: types.CmdRequestGet, URL: "https://example.com/"}, time.Now())
+	h.trackSolve(httptest.NewRequest("POST", "/v1", nil), &types.Request{Cmd: types.CmdSessionsList}, time.Now())()
+
+	w := httptest.NewRecorder()
+	h.AdminHandler().ServeHTTP(w, httptest.NewRequest("GET", "/api/state", nil))
+	var state AdminState
+	if err := json.NewDecoder(w.Body).Decode(&state); err != nil {
+		t.Fatalf("decode state: %v", err)
+	}
+	if len(state.Inflight) != 1 || state.Inflight[0].Domain != "example.com" {
+		t.Errorf("inflight = %+v, want the running request.get only", state.Inflight)
+	}
+	if state.Domains["example.com"].RequestCount != 1 {
+		t.Errorf("domains = %+v", state.Domains)
+	}
+	if w.Header().Get("Content-Security-Policy") == "" {
+		t.Error("admin response without a Content-Security-Policy")
+	}
+
+	done()
+	if got := h.inflight.snapshot(); len(got) != 0 {
+		t.Errorf("inflight after the solve finished = %+v", got)
+	}
+}
+
+func TestAdminPage(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	for path, want := range map[string]string{"/": "<title>FlareSolverr admin</title>", "/admin.js": "api/state"} {
+		w := httptest.NewRecorder()
+		h.AdminHandler().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+			t.Errorf("GET %s = %d, want a body containing %q", path, w.Code, want)
+		}
+	}
+}
+
+func TestAdminActionRequiresJSON(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	// A cross-site form post cannot set a JSON content type
+	req := httptest.NewRequest("POST", "/api/pool/drain", strings.NewReader("x=1"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.AdminHandler().ServeHTTP(w, req)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("form post status = %d, want %d", w.Code, http.StatusUnsupportedMediaType)
+	}
+
+	req = httptest.NewRequest("POST", "/api/pool/drain", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	h.AdminHandler().ServeHTTP(w, req)
+	var resp types.Response
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != types.StatusError || !strings.Contains(resp.Message, "pool is not available") {
+		t.Errorf("drain without a pool = %+v", resp)
+	}
+}
+
+func TestAdminFeatureToggle(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+	h.features = features.New()
+
+	post := func(body string) (int, types.Response) {
+		req := httptest.NewRequest("POST", "/api/features", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		h.AdminHandler().ServeHTTP(w, req)
+		var resp types.Response
+		_ = json.NewDecoder(w.Body).Decode(&resp)
+		return w.Code, resp
+	}
+
+	if code, resp := post(`{"name":"turnstile.positional","domain":"example.com","enabled":false}`); code != http.StatusOK || resp.Status != types.StatusOK {
+		t.Fatalf("toggle = %d %+v", code, resp)
+	}
+	if h.features.Enabled(features.TurnstilePositional, "www.example.com") || !h.features.Enabled(features.TurnstilePositional, "other.org") {
+		t.Error("flag should be off for example.com only")
+	}
+	if code, _ := post(`{"name":"turnstile.teleport","enabled":true}`); code != http.StatusBadRequest {
+		t.Errorf("unknown flag status = %d, want %d", code, http.StatusBadRequest)
+	}
+
+	w := httptest.NewRecorder()
+	h.AdminHandler().ServeHTTP(w, httptest.NewRequest("GET", "/api/features", nil))
+	var states []features.State
+	if err := json.NewDecoder(w.Body).Decode(&states); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range states {
+		if enabled, ok := s.Domains["example.com"]; s.Name == features.TurnstilePositional && (!ok || enabled) {
+			t.Errorf("listed state = %+v", s)
+		}
+	}
+}
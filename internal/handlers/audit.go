@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/audit"
+	"github.com/Rorqualx/flaresolverr-go/internal/middleware"
+	"github.com/Rorqualx/flaresolverr-go/internal/security"
+	"github.com/Rorqualx/flaresolverr-go/internal/stats"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// SetAuditLog enables the per-request audit log.
+func (h *Handler) SetAuditLog(l *audit.Logger) {
+	h.audit = l
+}
+
+// auditRequest writes the audit record of a finished command.
+func (h *Handler) auditRequest(o *outcomeWriter, r *http.Request, req *types.Request, startTime time.Time) {
+	rec := audit.Record{
+		Time:       startTime.UTC(),
+		RequestID:  middleware.RequestIDFromContext(r.Context()),
+		ClientIP:   middleware.ClientIP(r, h.config.TrustProxy),
+		APIKey:     middleware.APIKeyNameFromContext(r.Context()),
+		ClientCert: middleware.ClientCertName(r),
+		Command:    req.Cmd,
+		Domain:     stats.ExtractDomain(req.URL),
+		Session:    req.Session,
+		Outcome:    o.status,
+		Error:      o.message,
+		DurationMs: time.Since(startTime).Milliseconds(),
+		Cookies:    o.cookies,
+	}
+	if rec.Domain == "" {
+		// domains.getPrefs and domains.setPrefs name a domain, not a URL
+		rec.Domain = req.Domain
+	}
+	if rec.Outcome == "" {
+		// Nothing went through writeJSONResponse, e.g. a binary download
+		rec.Outcome = types.StatusOK
+	}
+
+	// The proxy the solve went through, else the one the request asked for,
+	// else the server default
+	switch {
+	case o.proxy != nil && o.proxy.URL != "":
+		rec.Proxy = security.RedactProxy(o.proxy.URL, o.proxy.Username)
+	case req.Proxy != nil && req.Proxy.URL != "":
+		rec.Proxy = security.RedactProxy(req.Proxy.URL, req.Proxy.Username)
+	case req.Proxy != nil && req.Proxy.Pool != "":
+		rec.Proxy = "pool:" + req.Proxy.Pool
+	case h.config.HasDefaultProxy() && req.URL != "":
+		rec.Proxy = security.RedactProxyURL(h.config.ProxyURL)
+	}
+	h.audit.Log(rec)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/stats"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+	"github.com/Rorqualx/flaresolverr-go/internal/webcache"
+	"github.com/Rorqualx/flaresolverr-go/pkg/version"
+)
+
+// writeCachedCopy answers a hard-blocked allowCacheFallback request with an
+// archived copy of the URL, marked stale with its capture time. It reports
+// false, having written nothing, when the fallback is disabled, does not fit
+// the request or no copy is available; the caller then writes the original
+// access denied error.
+func (h *Handler) writeCachedCopy(w http.ResponseWriter, r *http.Request, req *types.Request, startTime time.Time) bool {
+	// A page copy carries no cookies and is not the requested download
+	if h.webCache == nil || req.ReturnOnlyCookies || req.Download {
+		return false
+	}
+
+	cached, err := h.webCache.Fetch(r.Context(), req.URL)
+	if err != nil {
+		if errors.Is(err, webcache.ErrNoCopy) {
+			log.Ctx(r.Context()).Info().Str("url", sanitizeURLForLogging(req.URL)).Msg("Origin denied access and no cached copy exists")
+		} else {
+			log.Ctx(r.Context()).Warn().Err(err).Str("url", sanitizeURLForLogging(req.URL)).Msg("Cache fallback failed")
+		}
+		return false
+	}
+
+	// The origin still refused us; keep the domain's rate limit hints accurate
+	if domain := stats.ExtractDomain(req.URL); domain != "" && h.domainStats != nil {
+		h.domainStats.RecordRequest(domain, time.Since(startTime).Milliseconds(), false, true)
+		h.addDomainHeaders(w, domain)
+	}
+
+	stale := true
+	cachedAt := cached.CapturedAt.Format(time.RFC3339)
+	solution := &types.Solution{
+		URL:      req.URL,
+		Status:   cached.Status,
+		Cookies:  []types.Cookie{},
+		Stale:    &stale,
+		CachedAt: &cachedAt,
+		CacheURL: &cached.URL,
+	}
+	if cached.Truncated {
+		solution.ResponseTruncated = &cached.Truncated
+	}
+
+	log.Ctx(r.Context()).Info().
+		Str("url", sanitizeURLForLogging(req.URL)).
+		Str("source", cached.Source).
+		Time("cached_at", cached.CapturedAt).
+		Msg("Origin denied access, returning cached copy")
+
+	resp := types.Response{
+		Status:    types.StatusOK,
+		Message:   "Origin denied access; returning a stale cached copy",
+		StartTime: startTime.UnixMilli(),
+		EndTime:   time.Now().UnixMilli(),
+		Version:   version.Full(),
+		Solution:  solution,
+	}
+	if req.StreamResponse {
+		h.writeSolutionStream(w, r, resp, cached.HTML)
+	} else {
+		h.writeSolutionResponse(w, r, resp, cached.HTML)
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+	"github.com/Rorqualx/flaresolverr-go/pkg/version"
+)
+
+// handleCaptchaStatus handles the captcha.status command: the balance, balance
+// query latency and solve stats of every configured external provider.
+func (h *Handler) handleCaptchaStatus(w http.ResponseWriter, ctx context.Context, startTime time.Time) {
+	var statuses []types.CaptchaProviderStatus
+	if h.solver != nil {
+		statuses = h.solver.CaptchaStatus(ctx, h.config.CaptchaLowBalance)
+	}
+	if statuses == nil {
+		h.writeError(w, "External CAPTCHA solving is not enabled (set CAPTCHA_FALLBACK_ENABLED=true and a provider API key)", startTime)
+		return
+	}
+
+	healthy := 0
+	for _, st := range statuses {
+		switch {
+		case st.LowBalance:
+			log.Ctx(ctx).Warn().
+				Str("provider", st.Provider).
+				Float64("balance", *st.Balance).
+				Float64("threshold", h.config.CaptchaLowBalance).
+				Msg("CAPTCHA provider balance is low")
+		case st.Balance != nil:
+			healthy++
+		}
+	}
+
+	resp := types.Response{
+		Status:           types.StatusOK,
+		Message:          fmt.Sprintf("%d of %d providers have balance", healthy, len(statuses)),
+		StartTime:        startTime.UnixMilli(),
+		EndTime:          time.Now().UnixMilli(),
+		Version:          version.Full(),
+		CaptchaProviders: statuses,
+	}
+	h.writeJSONResponse(w, http.StatusOK, resp)
+}
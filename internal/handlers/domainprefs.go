@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/captcha"
+	"github.com/Rorqualx/flaresolverr-go/internal/solver"
+	"github.com/Rorqualx/flaresolverr-go/internal/stats"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+	"github.com/Rorqualx/flaresolverr-go/pkg/version"
+)
+
+// prefsDomain normalizes the domain of a domains.* command: a bare host, or
+// the host of a URL.
+func prefsDomain(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if strings.Contains(raw, "://") {
+		raw = stats.ExtractDomain(raw)
+	}
+	return strings.TrimSuffix(strings.ToLower(raw), ".")
+}
+
+// handleDomainsGetPrefs handles the domains.getPrefs command: the solver
+// preferences set for a domain.
+func (h *Handler) handleDomainsGetPrefs(w http.ResponseWriter, req *types.Request, startTime time.Time) {
+	if h.domainStats == nil {
+		h.writeError(w, "Domain stats are not available", startTime)
+		return
+	}
+	domain := prefsDomain(req.Domain)
+	prefs := h.domainStats.GetDomainSolverPrefs(domain)
+
+	resp := types.Response{
+		Status:    types.StatusOK,
+		Message:   fmt.Sprintf("No solver preferences set for %s", domain),
+		StartTime: startTime.UnixMilli(),
+		EndTime:   time.Now().UnixMilli(),
+		Version:   version.Full(),
+	}
+	if prefs != nil {
+		resp.Message = fmt.Sprintf("Solver preferences for %s", domain)
+		resp.DomainPrefs = &types.DomainPrefs{
+			DisableMethods:    prefs.DisableMethods,
+			PreferredProvider: prefs.PreferredProvider,
+			PollStrategy:      prefs.PollStrategy,
+		}
+		if prefs.TimeoutOverrideMs != nil {
+			resp.DomainPrefs.TimeoutOverrideMs = *prefs.TimeoutOverrideMs
+		}
+	}
+	h.writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// handleDomainsSetPrefs handles the domains.setPrefs command: it replaces the
+// solver preferences of a domain, or clears them when none are given. They
+// take effect on the next request and are kept in memory only.
+func (h *Handler) handleDomainsSetPrefs(w http.ResponseWriter, ctx context.Context, req *types.Request, startTime time.Time) {
+	if h.domainStats == nil {
+		h.writeError(w, "Domain stats are not available", startTime)
+		return
+	}
+	domain := prefsDomain(req.Domain)
+	if domain == "" || strings.ContainsAny(domain, "/ ") {
+		h.writeError(w, fmt.Sprintf("Invalid domain %q", req.Domain), startTime)
+		return
+	}
+
+	p := req.DomainPrefs
+	if p == nil {
+		p = &types.DomainPrefs{}
+	}
+	if err := solver.ValidateTurnstileMethods(p.DisableMethods); err != nil {
+		h.writeError(w, fmt.Sprintf("Invalid disableMethods: %v", err), startTime)
+		return
+	}
+	if p.PreferredProvider != "" && !slices.Contains(captcha.Available(), p.PreferredProvider) {
+		h.writeError(w, fmt.Sprintf("Unknown preferredProvider %q (must be one of %s)", p.PreferredProvider, strings.Join(captcha.Available(), ", ")), startTime)
+		return
+	}
+	if p.PollStrategy != "" {
+		if _, err := solver.NewPollStrategy(p.PollStrategy); err != nil {
+			h.writeError(w, fmt.Sprintf("Invalid pollStrategy: %v", err), startTime)
+			return
+		}
+	}
+
+	var prefs *stats.SolverPreferences
+	message := fmt.Sprintf("Solver preferences cleared for %s", domain)
+	if len(p.DisableMethods) > 0 || p.PreferredProvider != "" || p.TimeoutOverrideMs > 0 || p.PollStrategy != "" {
+		prefs = &stats.SolverPreferences{
+			NativeFirst:       true,
+			DisableMethods:    slices.Clone(p.DisableMethods),
+			PreferredProvider: p.PreferredProvider,
+			PollStrategy:      p.PollStrategy,
+		}
+		if p.TimeoutOverrideMs > 0 {
+			timeout := p.TimeoutOverrideMs
+			prefs.TimeoutOverrideMs = &timeout
+		}
+		// Keep what the API doesn't set
+		if prev := h.domainStats.GetDomainSolverPrefs(domain); prev != nil {
+			prefs.NativeFirst = prev.NativeFirst
+			prefs.NativeAttempts = prev.NativeAttempts
+		}
+		message = fmt.Sprintf("Solver preferences set for %s", domain)
+	}
+	h.domainStats.SetDomainSolverPrefs(domain, prefs)
+
+	log.Ctx(ctx).Info().
+		Str("domain", domain).
+		Strs("disable_methods", p.DisableMethods).
+		Str("preferred_provider", p.PreferredProvider).
+		Int("timeout_override_ms", p.TimeoutOverrideMs).
+		Str("poll_strategy", p.PollStrategy).
+		Msg("Domain solver preferences updated")
+
+	resp := types.Response{
+		Status:    types.StatusOK,
+		Message:   message,
+		StartTime: startTime.UnixMilli(),
+		EndTime:   time.Now().UnixMilli(),
+		Version:   version.Full(),
+	}
+	if prefs != nil {
+		resp.DomainPrefs = p
+	}
+	h.writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// applyDomainPrefs fills the maxTimeout req leaves unset from the solver
+// preferences for its domain. The other preferences are applied by the
+// solver.
+func (h *Handler) applyDomainPrefs(req *types.Request) {
+	if req.MaxTimeout != 0 || h.domainStats == nil {
+		return
+	}
+	prefs := h.domainStats.SolverPrefsFor(stats.ExtractDomain(req.URL))
+	if prefs == nil || prefs.TimeoutOverrideMs == nil || *prefs.TimeoutOverrideMs <= 0 {
+		return
+	}
+	req.MaxTimeout = min(*prefs.TimeoutOverrideMs, int(h.config.MaxTimeout.Milliseconds()))
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/events"
+	"github.com/Rorqualx/flaresolverr-go/internal/solver"
+	"github.com/Rorqualx/flaresolverr-go/internal/stats"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// eventTypes are the types accepted by the ?types= filter of /admin/events.
+var eventTypes = map[string]bool{
+	events.BrowserSpawned:     true,
+	events.BrowserRecycled:    true,
+	events.ChallengeDetected:  true,
+	events.SolveSucceeded:     true,
+	events.SolveFailed:        true,
+	events.SessionCreated:     true,
+	events.SessionDestroyed:   true,
+	events.RateLimitTriggered: true,
+}
+
+// SetEventStream enables GET /admin/events backed by the given bus.
+func (h *Handler) SetEventStream(b *events.Bus) {
+	h.eventStream = b
+}
+
+// publishSolve announces the outcome of a finished solve.
+func publishSolve(ctx context.Context, req *types.Request, result *solver.Result, solveErr error, solved bool, startTime time.Time) {
+	e := events.Event{
+		Type:       events.SolveFailed,
+		Session:    req.Session,
+		Domain:     stats.ExtractDomain(req.URL),
+		DurationMs: time.Since(startTime).Milliseconds(),
+	}
+	if solved {
+		e.Type = events.SolveSucceeded
+	}
+	if result != nil {
+		e.Status = result.StatusCode
+	}
+	if solveErr != nil {
+		e.Error = solveErr.Error()
+	}
+	events.Publish(ctx, e)
+}
+
+// handleEventStream sends operational events as Server-Sent Events, one JSON
+// event per message with the event type as the SSE event name. Query
+// parameter types takes a comma-separated list of event types and domain
+// narrows the stream to one target domain.
+func (h *Handler) handleEventStream(w http.ResponseWriter, r *http.Request, startTime time.Time) {
+	if h.eventStream == nil {
+		h.writeErrorWithStatus(w, http.StatusNotFound, "Event streaming is not enabled", startTime)
+		return
+	}
+	if r.Method != http.MethodGet {
+		h.writeErrorWithStatus(w, http.StatusMethodNotAllowed, "Method not allowed", startTime)
+		return
+	}
+
+	q := r.URL.Query()
+	var filter []string
+	if list := q.Get("types"); list != "" {
+		for _, t := range strings.Split(list, ",") {
+			t = strings.TrimSpace(t)
+			if !eventTypes[t] {
+				h.writeErrorWithStatus(w, http.StatusBadRequest, "Invalid event type: "+t, startTime)
+				return
+			}
+			filter = append(filter, t)
+		}
+	}
+	domain := strings.ToLower(q.Get("domain"))
+
+	sub, err := h.eventStream.Subscribe(filter...)
+	if err != nil {
+		h.writeErrorWithStatus(w, http.StatusServiceUnavailable, "Too many event stream subscribers", startTime)
+		return
+	}
+	defer h.eventStream.Unsubscribe(sub)
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Ctx(r.Context()).Debug().Err(err).Msg("Could not clear write deadline for event stream")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		log.Ctx(r.Context()).Warn().Err(err).Msg("Event stream requires a flushable response writer")
+		return
+	}
+
+	log.Ctx(r.Context()).Info().
+		Strs("types", filter).
+		Str("domain_filter", domain).
+		Msg("Event stream subscriber connected")
+
+	ticker := time.NewTicker(logStreamKeepalive)
+	defer ticker.Stop()
+
+	var reportedDrops int64
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if domain != "" && e.Domain != domain {
+				continue
+			}
+			if dropped := sub.Dropped(); dropped > reportedDrops {
+				// Tell the client it missed events instead of silently skipping them
+				if _, err := fmt.Fprintf(w, "event: dropped\ndata: %d\n\n", dropped-reportedDrops); err != nil {
+					return
+				}
+				reportedDrops = dropped
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
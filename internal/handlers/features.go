@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+	"github.com/Rorqualx/flaresolverr-go/pkg/version"
+)
+
+// featureToggle is the body of POST /api/features.
+type featureToggle struct {
+	Name    string `json:"name"`
+	Domain  string `json:"domain,omitempty"` // Empty toggles the flag deployment-wide
+	Enabled *bool  `json:"enabled"`          // null removes the setting
+}
+
+// handleFeatureList returns every feature flag with its settings; without
+// flags configured, the defaults.
+func (h *Handler) handleFeatureList(w http.ResponseWriter, _ *http.Request) {
+	h.writeJSONResponse(w, http.StatusOK, h.features.States())
+}
+
+// handleFeatureToggle switches a feature flag until the next restart.
+func (h *Handler) handleFeatureToggle(w http.ResponseWriter, r *http.Request, startTime time.Time) {
+	if h.features == nil {
+		h.writeErrorWithStatus(w, http.StatusNotFound, "Feature flags are not available", startTime)
+		return
+	}
+	var body featureToggle
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&body); err != nil {
+		h.writeErrorWithStatus(w, http.StatusBadRequest, "Invalid JSON request", startTime)
+		return
+	}
+	if err := h.features.Set(body.Name, body.Domain, body.Enabled); err != nil {
+		h.writeErrorWithStatus(w, http.StatusBadRequest, err.Error(), startTime)
+		return
+	}
+
+	scope := "deployment-wide"
+	if body.Domain != "" {
+		scope = "for " + body.Domain
+	}
+	state := "reset to the default"
+	switch {
+	case body.Enabled == nil && body.Domain != "":
+		state = "reset to the deployment-wide setting"
+	case body.Enabled != nil && *body.Enabled:
+		state = "turned on"
+	case body.Enabled != nil:
+		state = "turned off"
+	}
+	message := fmt.Sprintf("Feature flag %s %s %s", body.Name, state, scope)
+	log.Info().Str("flag", body.Name).Str("domain", body.Domain).Msg(message)
+
+	h.writeJSONResponse(w, http.StatusOK, types.Response{
+		Status:    types.StatusOK,
+		Message:   message,
+		StartTime: startTime.UnixMilli(),
+		EndTime:   time.Now().UnixMilli(),
+		Version:   version.Full(),
+	})
+}
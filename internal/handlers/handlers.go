@@ -12,22 +12,32 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/rs/zerolog/log"
 
+	"github.com/Rorqualx/flaresolverr-go/internal/audit"
 	"github.com/Rorqualx/flaresolverr-go/internal/browser"
 	"github.com/Rorqualx/flaresolverr-go/internal/captcha"
 	"github.com/Rorqualx/flaresolverr-go/internal/config"
+	"github.com/Rorqualx/flaresolverr-go/internal/events"
+	"github.com/Rorqualx/flaresolverr-go/internal/features"
+	"github.com/Rorqualx/flaresolverr-go/internal/logstream"
+	"github.com/Rorqualx/flaresolverr-go/internal/middleware"
+	"github.com/Rorqualx/flaresolverr-go/internal/proxyhealth"
+	"github.com/Rorqualx/flaresolverr-go/internal/quiethours"
 	"github.com/Rorqualx/flaresolverr-go/internal/ratelimit"
 	"github.com/Rorqualx/flaresolverr-go/internal/security"
 	"github.com/Rorqualx/flaresolverr-go/internal/selectors"
 	"github.com/Rorqualx/flaresolverr-go/internal/session"
 	"github.com/Rorqualx/flaresolverr-go/internal/solver"
 	"github.com/Rorqualx/flaresolverr-go/internal/stats"
+	"github.com/Rorqualx/flaresolverr-go/internal/targetpolicy"
 	"github.com/Rorqualx/flaresolverr-go/internal/types"
+	"github.com/Rorqualx/flaresolverr-go/internal/webcache"
 	"github.com/Rorqualx/flaresolverr-go/pkg/version"
 )
 
@@ -153,6 +163,24 @@ type Handler struct {
 	userAgent        string
 	domainStats      *stats.Manager
 	selectorsManager *selectors.Manager
+	quietHours       *quiethours.Schedule
+	features         *features.Flags
+	targetPolicy     *targetpolicy.Policy // TARGET_ALLOWLIST/TARGET_DENYLIST, nil allows every domain
+	logStream        *logstream.Broker
+	eventStream      *events.Bus   // EVENT_STREAM_ENABLED, nil when disabled
+	audit            *audit.Logger // AUDIT_LOG_FILE/AUDIT_LOG_SYSLOG, nil when disabled
+	tagStats         *stats.TagStats
+	webCache         *webcache.Fetcher            // nil when CACHE_FALLBACK_ENABLED=false
+	proxyPools       map[string]*solver.ProxyPool // PROXY_POOLS_PATH, selected with proxy.pool
+	proxyRoutes      solver.ProxyRoutes           // PROXY_ROUTES, for requests without a proxy
+	domainOverrides  solver.DomainOverrides       // DOMAIN_OVERRIDES_PATH, for settings a request leaves unset
+	proxyHealth      *proxyhealth.Checker         // nil when PROXY_HEALTH_CHECK_ENABLED=false
+	egressPool       *solver.EgressPool           // PROXY_LIST, also used for failover
+	inflight         inflightSolves               // Running solves, for the admin dashboard
+	results          *resultCache                 // nil when RESULT_CACHE_ENABLED=false
+
+	rulesMu      sync.RWMutex                 // Guards quietHours, targetPolicy, proxyPools and domainOverrides, which a config reload replaces
+	configReload func() (ReloadResult, error) // nil disables the admin config reload action
 }
 
 // Fix #11: closeBody closes an io.ReadCloser and logs any error at debug level.
@@ -190,6 +218,7 @@ func NewWithSelectors(pool *browser.Pool, sessions *session.Manager, cfg *config
 
 	// Create stats manager for domain tracking
 	domainStats := stats.NewManager()
+	domainStats.EnableDomainMetrics(cfg.MetricsDomainTopN)
 
 	// Create solver with selectors manager
 	solverInstance := solver.NewWithSelectors(pool, userAgent, selectorsManager)
@@ -198,6 +227,46 @@ func NewWithSelectors(pool *browser.Pool, sessions *session.Manager, cfg *config
 	// This enables per-domain learning of which solving methods work best
 	solverInstance.SetStatsManager(domainStats)
 
+	// Challenge poll strategy: server default plus per-domain preferences
+	if pollStrategy, err := solver.NewPollStrategy(cfg.PollStrategy); err != nil {
+		log.Warn().Err(err).Msg("Invalid POLL_STRATEGY, using random polling")
+	} else {
+		solverInstance.SetPollStrategy(pollStrategy)
+	}
+	solverInstance.SetPollSettings(solver.PollSettings{
+		IntervalMin:    cfg.PollIntervalMin,
+		IntervalMax:    cfg.PollIntervalMax,
+		MaxAttempts:    cfg.PollMaxAttempts,
+		SelectorBudget: cfg.SelectorDetectionBudget,
+	})
+	solverInstance.SetResponseLimits(solver.ResponseLimits{
+		MaxResponseSize:   cfg.MaxResponseSizeKB * 1024,
+		MaxScreenshotSize: cfg.MaxScreenshotSizeKB * 1024,
+		MaxCookies:        cfg.MaxResponseCookies,
+		MaxStorageItems:   cfg.MaxStorageItems,
+		MaxStorageSize:    cfg.MaxStorageSizeKB * 1024,
+	})
+	if domainStrategies, err := solver.ParsePollStrategyDomains(cfg.PollStrategyDomains); err != nil {
+		log.Warn().Err(err).Msg("Invalid POLL_STRATEGY_DOMAINS, per-domain poll strategies disabled")
+	} else {
+		for domain, name := range domainStrategies {
+			domainStats.SetDomainPollStrategy(domain, name)
+		}
+		if len(domainStrategies) > 0 {
+			log.Info().Int("domains", len(domainStrategies)).Msg("Per-domain poll strategies configured")
+		}
+	}
+
+	// Feature flags: experimental behaviors, toggled at runtime from the admin dashboard
+	featureFlags, err := features.Parse(cfg.FeatureFlags)
+	if err != nil {
+		log.Warn().Err(err).Msg("Invalid FEATURE_FLAGS, using flag defaults")
+		featureFlags = features.New()
+	} else if overridden := featureFlags.Overridden(); len(overridden) > 0 {
+		log.Info().Strs("flags", overridden).Msg("Feature flags configured")
+	}
+	solverInstance.SetFeatures(featureFlags)
+
 	// Set up external CAPTCHA solver chain if configured
 	if cfg.HasCaptchaFallback() {
 		// Map provider names to their configured API keys
@@ -205,12 +274,18 @@ func NewWithSelectors(pool *browser.Pool, sessions *session.Manager, cfg *config
 			"2captcha":    cfg.Captcha2CaptchaAPIKey,
 			"capsolver":   cfg.CaptchaCapSolverAPIKey,
 			"anticaptcha": cfg.CaptchaAntiCaptchaAPIKey,
+			"capmonster":  cfg.CaptchaCapMonsterAPIKey,
 			"9kw":         cfg.Captcha9kwAPIKey,
+			"custom":      cfg.CaptchaWebhookURL, // The webhook provider's "key" is its endpoint
 		}
 
-		// Build providers in priority order using the registry
+		// Build providers in priority order using the registry:
+		// CAPTCHA_PROVIDER_ORDER when set, else the primary then the rest
 		var providers []captcha.CaptchaSolver
 		order := captcha.BuildPriorityOrder(cfg.CaptchaPrimaryProvider, captcha.Available())
+		if len(cfg.CaptchaProviderOrder) > 0 {
+			order = captcha.ExplicitOrder(cfg.CaptchaProviderOrder, captcha.Available())
+		}
 		for _, name := range order {
 			apiKey := providerKeys[name]
 			if apiKey == "" {
@@ -221,6 +296,14 @@ func NewWithSelectors(pool *browser.Pool, sessions *session.Manager, cfg *config
 				continue
 			}
 			provider := factory(apiKey, cfg.CaptchaSolverTimeout)
+			if name == "custom" {
+				// The registry factory has no room for the bearer token
+				provider = captcha.NewWebhookSolver(captcha.WebhookConfig{
+					URL:     cfg.CaptchaWebhookURL,
+					Token:   cfg.CaptchaWebhookToken,
+					Timeout: cfg.CaptchaSolverTimeout,
+				})
+			}
 			if provider.IsConfigured() {
 				providers = append(providers, provider)
 				log.Debug().Str("provider", name).Msg("CAPTCHA provider registered")
@@ -228,26 +311,138 @@ func NewWithSelectors(pool *browser.Pool, sessions *session.Manager, cfg *config
 		}
 
 		if len(providers) > 0 {
+			providerBudgets, err := captcha.ParseProviderBudgets(cfg.CaptchaProviderBudgets)
+			if err != nil {
+				log.Warn().Err(err).Msg("Invalid CAPTCHA_PROVIDER_BUDGETS, ignoring per-provider budgets")
+				providerBudgets = nil
+			}
+			budget := captcha.NewBudget(captcha.BudgetLimits{
+				Daily:   cfg.CaptchaBudgetDaily,
+				Monthly: cfg.CaptchaBudgetMonthly,
+			}, providerBudgets)
 			chain := captcha.NewSolverChain(captcha.SolverChainConfig{
 				NativeAttempts:  cfg.CaptchaNativeAttempts,
 				Providers:       providers,
+				Metrics:         captcha.NewMetrics(),
+				Budget:          budget,
 				FallbackEnabled: true,
+				RaceThreshold:   cfg.CaptchaRaceThreshold,
+				Breaker: captcha.BreakerConfig{
+					ErrorRate: cfg.CaptchaBreakerErrorRate,
+					SlowCall:  cfg.CaptchaBreakerSlowCall,
+					Cooldown:  cfg.CaptchaBreakerCooldown,
+				},
 			})
 			solverInstance.SetSolverChain(chain)
 		}
 	}
 
 	// Layer-1 clean-egress path: sticky proxy pool (same exit IP per domain).
+	var egressPool *solver.EgressPool
 	if proxies := solver.ParseProxyList(cfg.ProxyList); len(proxies) > 0 {
 		strategy := solver.ParseEgressStrategy(cfg.ProxyStrategy)
-		solverInstance.SetEgressPool(solver.NewEgressPool(proxies, strategy))
+		egressPool = solver.NewEgressPool(proxies, strategy)
+		solverInstance.SetEgressPool(egressPool)
 		log.Info().Int("proxies", len(proxies)).Str("strategy", string(strategy)).Msg("Clean-egress proxy pool enabled")
 	}
 
+	// Named proxy pools, selected per request with proxy.pool
+	proxyPools, err := solver.LoadProxyPools(cfg.ProxyPoolsPath)
+	if err != nil {
+		log.Warn().Err(err).Str("path", cfg.ProxyPoolsPath).Msg("Invalid PROXY_POOLS_PATH, proxy pools disabled")
+		proxyPools = nil
+	} else if len(proxyPools) > 0 {
+		log.Info().Strs("pools", solver.ProxyPoolNames(proxyPools)).Msg("Proxy pools loaded")
+	}
+
+	// Per-domain proxy routes for requests that name no proxy
+	proxyRoutes, err := solver.ParseProxyRoutes(cfg.ProxyRoutes)
+	if err != nil {
+		log.Warn().Err(err).Msg("Invalid PROXY_ROUTES, per-domain proxy routing disabled")
+		proxyRoutes = nil
+	} else if len(proxyRoutes) > 0 {
+		for _, name := range proxyRoutes.Pools() {
+			if proxyPools[name] == nil {
+				log.Warn().Str("pool", name).Msg("PROXY_ROUTES refers to an unknown proxy pool; matching requests will fail")
+			}
+		}
+		log.Info().Int("routes", len(proxyRoutes)).Msg("Per-domain proxy routing enabled")
+	}
+
+	// Per-domain solve settings for requests that leave them unset
+	domainOverrides, err := solver.LoadDomainOverrides(cfg.DomainOverridesPath)
+	if err != nil {
+		log.Warn().Err(err).Str("path", cfg.DomainOverridesPath).Msg("Invalid DOMAIN_OVERRIDES_PATH, domain overrides disabled")
+		domainOverrides = nil
+	} else if len(domainOverrides) > 0 {
+		warnUnknownOverridePools(domainOverrides, proxyPools)
+		log.Info().Int("patterns", len(domainOverrides)).Msg("Domain overrides loaded")
+	}
+
+	// Background health checks take dead proxies out of rotation
+	var proxyHealth *proxyhealth.Checker
+	if cfg.ProxyHealthCheckEnabled {
+		proxyHealth = newProxyHealthChecker(cfg, egressPool, proxyPools, proxyRoutes)
+	}
+
+	// Sessions that keep getting denied move to the next proxy in the rotation.
+	if proxies := solver.ParseProxyList(cfg.SessionProxyRotation); len(proxies) > 0 && sessions != nil {
+		sessions.SetProxyRotation(proxies, cfg.SessionProxyRotateAfter)
+		log.Info().
+			Int("proxies", len(proxies)).
+			Int("rotate_after", cfg.SessionProxyRotateAfter).
+			Msg("Session proxy rotation enabled")
+	}
+
+	solverInstance.SetProxyVerifyURL(cfg.ProxyVerifyURL)
+
+	if cfg.RecordSolveDir != "" {
+		solverInstance.SetRecordingDir(cfg.RecordSolveDir)
+		log.Info().Str("dir", cfg.RecordSolveDir).Msg("recordSolve recordings are written to disk")
+	}
+
 	// Layer-2 clean-egress path: reuse minted cf_clearance across requests.
 	if cfg.ClearanceCacheEnabled {
 		solverInstance.SetClearanceCache(solver.NewClearanceCache(cfg.ClearanceTTL, 0))
 		log.Info().Dur("ttl", cfg.ClearanceTTL).Msg("cf_clearance cache enabled")
+		if cfg.ClearanceFastPath {
+			solverInstance.SetFastPath(solver.NewFastPath())
+			log.Info().Msg("Clearance fast path enabled: cache hits are fetched without a browser")
+		}
+	}
+
+	// Per-domain quiet hours: requests inside a window are refused up front.
+	// config.Validate refuses to start on a schedule that does not parse.
+	quietHours, err := quiethours.Parse(cfg.QuietHours)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid QUIET_HOURS")
+	} else if quietHours != nil {
+		log.Info().Msg("Per-domain quiet hours enabled")
+	}
+
+	// Target domain allow/deny lists. A list that fails to load refuses
+	// every target rather than leaving the instance open.
+	targetPolicy, err := targetpolicy.Load(cfg.TargetAllowlist, cfg.TargetDenylist, cfg.TargetAllowlistFile, cfg.TargetDenylistFile)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid target domain policy, refusing all targets")
+		targetPolicy = targetpolicy.DenyAll()
+	} else if targetPolicy != nil {
+		allowed, blocked := targetPolicy.Summary()
+		log.Info().Int("allowed", allowed).Int("blocked", blocked).Msg("Target domain policy enabled")
+	}
+
+	// Request/response buffers larger than this are not kept for reuse
+	setMaxRetainedBufferCap(cfg.BufferPoolMaxBufferKB * 1024)
+
+	// Archived copies served to allowCacheFallback requests when the origin hard-blocks
+	var webCache *webcache.Fetcher
+	if cfg.CacheFallbackEnabled {
+		webCache = webcache.New(webcache.Config{Timeout: cfg.CacheFallbackTimeout})
+	}
+
+	var results *resultCache
+	if cfg.ResultCacheEnabled {
+		results = newResultCache(cfg.ResultCacheTTL, cfg.ResultCacheMaxEntries)
 	}
 
 	return &Handler{
@@ -258,9 +453,25 @@ func NewWithSelectors(pool *browser.Pool, sessions *session.Manager, cfg *config
 		userAgent:        userAgent,
 		domainStats:      domainStats,
 		selectorsManager: selectorsManager,
+		quietHours:       quietHours,
+		features:         featureFlags,
+		targetPolicy:     targetPolicy,
+		tagStats:         stats.NewTagStats(cfg.MetricsTagKeys, cfg.MetricsTagMaxValues),
+		webCache:         webCache,
+		proxyPools:       proxyPools,
+		proxyRoutes:      proxyRoutes,
+		domainOverrides:  domainOverrides,
+		proxyHealth:      proxyHealth,
+		egressPool:       egressPool,
+		results:          results,
 	}
 }
 
+// Close stops the handler's background work.
+func (h *Handler) Close() {
+	h.proxyHealth.Close()
+}
+
 // testBrowserStartup navigates to the TEST_URL to verify the browser works.
 // This is a non-blocking verification — failure is logged as a warning.
 func testBrowserStartup(pool *browser.Pool, testURL string) {
@@ -269,30 +480,30 @@ func testBrowserStartup(pool *browser.Pool, testURL string) {
 
 	b, err := pool.Acquire(ctx)
 	if err != nil {
-		log.Warn().Err(err).Msg("Startup test: could not acquire browser")
+		log.Ctx(ctx).Warn().Err(err).Msg("Startup test: could not acquire browser")
 		return
 	}
 	defer pool.Release(b)
 
 	page, err := b.Page(proto.TargetCreateTarget{URL: testURL})
 	if err != nil {
-		log.Warn().Err(err).Str("url", testURL).Msg("Startup test: could not create page")
+		log.Ctx(ctx).Warn().Err(err).Str("url", testURL).Msg("Startup test: could not create page")
 		return
 	}
 	defer page.Close()
 
 	if err := page.Context(ctx).WaitLoad(); err != nil {
-		log.Warn().Err(err).Str("url", testURL).Msg("Startup test: page load failed")
+		log.Ctx(ctx).Warn().Err(err).Str("url", testURL).Msg("Startup test: page load failed")
 		return
 	}
 
 	info, err := page.Info()
 	if err != nil {
-		log.Warn().Err(err).Msg("Startup test: could not get page info")
+		log.Ctx(ctx).Warn().Err(err).Msg("Startup test: could not get page info")
 		return
 	}
 
-	log.Info().Str("url", testURL).Str("title", info.Title).Msg("Startup browser test passed")
+	log.Ctx(ctx).Info().Str("url", testURL).Str("title", info.Title).Msg("Startup browser test passed")
 }
 
 // getActualUserAgent retrieves the real user agent from the browser via CDP.
@@ -308,7 +519,7 @@ func getActualUserAgent(pool *browser.Pool) string {
 
 	b, err := pool.Acquire(ctx)
 	if err != nil {
-		log.Warn().Err(err).Msg("Could not acquire browser to get user agent, using fallback")
+		log.Ctx(ctx).Warn().Err(err).Msg("Could not acquire browser to get user agent, using fallback")
 		return fallbackUA
 	}
 	defer pool.Release(b)
@@ -317,7 +528,7 @@ func getActualUserAgent(pool *browser.Pool) string {
 	// This is more reliable than navigator.userAgent on about:blank
 	result, err := proto.BrowserGetVersion{}.Call(b)
 	if err != nil {
-		log.Warn().Err(err).Msg("Could not get browser version via CDP, using fallback")
+		log.Ctx(ctx).Warn().Err(err).Msg("Could not get browser version via CDP, using fallback")
 		return fallbackUA
 	}
 
@@ -332,7 +543,7 @@ func getActualUserAgent(pool *browser.Pool) string {
 	//
 	// Previously this code forced Chrome 142, but if the actual browser is Chrome 124,
 	// this mismatch is detected and triggers Turnstile challenges.
-	log.Debug().Str("browser_ua", ua).Msg("Using browser's actual user agent")
+	log.Ctx(ctx).Debug().Str("browser_ua", ua).Msg("Using browser's actual user agent")
 
 	return ua
 }
@@ -361,7 +572,17 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Handle health check
 	if r.URL.Path == "/health" {
-		h.handleHealth(w, startTime)
+		h.handleHealth(w, r, startTime)
+		return
+	}
+
+	// Kubernetes-style liveness and readiness probes
+	if r.URL.Path == "/livez" {
+		h.handleLivez(w)
+		return
+	}
+	if r.URL.Path == "/readyz" {
+		h.handleReadyz(w, r)
 		return
 	}
 
@@ -378,6 +599,18 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Tail structured logs (SSE)
+	if r.URL.Path == "/logs/stream" {
+		h.handleLogStream(w, r, startTime)
+		return
+	}
+
+	// Operational events (SSE)
+	if r.URL.Path == "/admin/events" {
+		h.handleEventStream(w, r, startTime)
+		return
+	}
+
 	// Only POST is allowed for the main endpoint
 	if r.Method != http.MethodPost {
 		h.writeError(w, "Method not allowed", startTime)
@@ -394,14 +627,14 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer putBuffer(buf)
 
 	if _, err := io.Copy(buf, r.Body); err != nil {
-		log.Warn().Err(err).Msg("Failed to read request body")
+		log.Ctx(r.Context()).Warn().Err(err).Msg("Failed to read request body")
 		h.writeError(w, "Failed to read request", startTime)
 		return
 	}
 
 	var req types.Request
 	if err := json.Unmarshal(buf.Bytes(), &req); err != nil {
-		log.Warn().Err(err).Msg("Failed to decode request")
+		log.Ctx(r.Context()).Warn().Err(err).Msg("Failed to decode request")
 		h.writeError(w, "Invalid JSON request", startTime)
 		return
 	}
@@ -409,12 +642,12 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Fix HIGH: Call centralized validation instead of duplicating checks
 	// This validates cmd, url, session, cookies, proxy, headers, etc.
 	if err := req.Validate(); err != nil {
-		log.Warn().Err(err).Msg("Request validation failed")
+		log.Ctx(r.Context()).Warn().Err(err).Msg("Request validation failed")
 		h.writeError(w, err.Error(), startTime)
 		return
 	}
 
-	log.Info().
+	withTags(log.Ctx(r.Context()).Info(), req.Tags).
 		Str("cmd", req.Cmd).
 		Str("url", sanitizeURLForLogging(req.URL)).
 		Str("session", req.Session).
@@ -425,8 +658,8 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 // HandleHealth handles the /health and /v1 endpoints.
-func (h *Handler) HandleHealth(w http.ResponseWriter, _ *http.Request) {
-	h.handleHealth(w, time.Now())
+func (h *Handler) HandleHealth(w http.ResponseWriter, r *http.Request) {
+	h.handleHealth(w, r, time.Now())
 }
 
 // HandleAPI handles the main API endpoint.
@@ -443,14 +676,14 @@ func (h *Handler) HandleAPI(w http.ResponseWriter, r *http.Request) {
 	defer putBuffer(buf)
 
 	if _, err := io.Copy(buf, r.Body); err != nil {
-		log.Warn().Err(err).Msg("Failed to read request body")
+		log.Ctx(r.Context()).Warn().Err(err).Msg("Failed to read request body")
 		h.writeError(w, "Failed to read request", startTime)
 		return
 	}
 
 	var req types.Request
 	if err := json.Unmarshal(buf.Bytes(), &req); err != nil {
-		log.Warn().Err(err).Msg("Failed to decode request")
+		log.Ctx(r.Context()).Warn().Err(err).Msg("Failed to decode request")
 		h.writeError(w, "Invalid JSON request", startTime)
 		return
 	}
@@ -458,12 +691,12 @@ func (h *Handler) HandleAPI(w http.ResponseWriter, r *http.Request) {
 	// Fix HIGH: Call centralized validation instead of duplicating checks
 	// This validates cmd, url, session, cookies, proxy, headers, etc.
 	if err := req.Validate(); err != nil {
-		log.Warn().Err(err).Msg("Request validation failed")
+		log.Ctx(r.Context()).Warn().Err(err).Msg("Request validation failed")
 		h.writeError(w, err.Error(), startTime)
 		return
 	}
 
-	log.Info().
+	withTags(log.Ctx(r.Context()).Info(), req.Tags).
 		Str("cmd", req.Cmd).
 		Str("url", sanitizeURLForLogging(req.URL)).
 		Str("session", req.Session).
@@ -490,6 +723,10 @@ type PoolStats struct {
 	Released  int64 `json:"released"`
 	Recycled  int64 `json:"recycled"`
 	Errors    int64 `json:"errors"`
+	Waiting   int   `json:"waiting"` // Requests queued for a browser
+
+	// Resident memory of the launched browsers, absent where it cannot be read
+	Memory *browser.MemorySample `json:"memory,omitempty"`
 }
 
 // SelectorsStats contains statistics about selector hot-reloading.
@@ -511,6 +748,9 @@ type HealthResponse struct {
 	DomainStats    map[string]stats.DomainStatsJSON `json:"domainStats,omitempty"`
 	Defaults       *DelayDefaults                   `json:"defaults,omitempty"`
 	SelectorsStats *SelectorsStats                  `json:"selectorsStats,omitempty"`
+	ProxyPools     map[string]solver.ProxyPoolStats `json:"proxyPools,omitempty"`
+	CaptchaBudgets []captcha.BudgetStatus           `json:"captchaBudgets,omitempty"`
+	Probe          *ProbeResult                     `json:"probe,omitempty"`
 }
 
 // DelayDefaults contains default delay configuration.
@@ -519,8 +759,45 @@ type DelayDefaults struct {
 	MaxDelayMs int `json:"maxDelayMs"`
 }
 
-// handleHealth returns service health information.
-func (h *Handler) handleHealth(w http.ResponseWriter, startTime time.Time) {
+// poolStats returns the browser pool counters, or nil without a pool.
+func (h *Handler) poolStats() *PoolStats {
+	if h.pool == nil {
+		return nil
+	}
+	poolStats := h.pool.Stats()
+	return &PoolStats{
+		Size:      h.pool.Size(),
+		Available: h.pool.Available(),
+		Acquired:  poolStats.Acquired,
+		Released:  poolStats.Released,
+		Recycled:  poolStats.Recycled,
+		Errors:    poolStats.Errors,
+		Waiting:   h.pool.Waiting(),
+		Memory:    h.pool.MemoryUsage(),
+	}
+}
+
+// handleHealth returns service health information. With ?probe=deep it also
+// runs a navigation through the browser pool and answers 503 if that fails.
+func (h *Handler) handleHealth(w http.ResponseWriter, r *http.Request, startTime time.Time) {
+	status := http.StatusOK
+	var probe *ProbeResult
+	switch mode := r.URL.Query().Get("probe"); mode {
+	case "", "shallow":
+	case "deep":
+		probeStart := time.Now()
+		err := h.deepProbe(r.Context())
+		probe = &ProbeResult{Mode: mode, DurationMs: time.Since(probeStart).Milliseconds()}
+		if err != nil {
+			log.Ctx(r.Context()).Warn().Err(err).Msg("Deep health probe failed")
+			probe.Error = err.Error()
+			status = http.StatusServiceUnavailable
+		}
+	default:
+		h.writeErrorWithStatus(w, http.StatusBadRequest, "Invalid probe, expected shallow or deep", startTime)
+		return
+	}
+
 	resp := HealthResponse{
 		Status:    types.StatusOK,
 		Message:   "FlareSolverr is ready",
@@ -531,17 +808,7 @@ func (h *Handler) handleHealth(w http.ResponseWriter, startTime time.Time) {
 	}
 
 	// Include pool stats if pool is available
-	if h.pool != nil {
-		poolStats := h.pool.Stats()
-		resp.Pool = &PoolStats{
-			Size:      h.pool.Size(),
-			Available: h.pool.Available(),
-			Acquired:  poolStats.Acquired,
-			Released:  poolStats.Released,
-			Recycled:  poolStats.Recycled,
-			Errors:    poolStats.Errors,
-		}
-	}
+	resp.Pool = h.poolStats()
 
 	// Include domain stats if any domains have been tracked
 	if h.domainStats != nil && h.domainStats.DomainCount() > 0 {
@@ -569,16 +836,71 @@ func (h *Handler) handleHealth(w http.ResponseWriter, startTime time.Time) {
 		}
 	}
 
-	h.writeJSONResponse(w, http.StatusOK, resp)
+	// Include per-proxy outcome stats of the named proxy pools
+	if proxyPools := h.currentProxyPools(); len(proxyPools) > 0 {
+		resp.ProxyPools = make(map[string]solver.ProxyPoolStats, len(proxyPools))
+		for name, pool := range proxyPools {
+			resp.ProxyPools[name] = pool.Stats()
+		}
+	}
+
+	// Include what remains of the external solver spend budgets
+	if h.solver != nil {
+		resp.CaptchaBudgets = h.solver.CaptchaBudgetStatus()
+	}
+
+	resp.Probe = probe
+	if status != http.StatusOK {
+		resp.Status = types.StatusError
+		resp.Message = "Deep probe failed"
+	}
+	h.writeJSONResponse(w, status, resp)
+}
+
+// screenshotOptions maps the request's screenshot fields to the solver's.
+func screenshotOptions(req *types.Request) solver.ScreenshotOptions {
+	return solver.ScreenshotOptions{
+		Format:   req.ScreenshotFormat,
+		Quality:  req.ScreenshotQuality,
+		Viewport: req.ScreenshotFullPage != nil && !*req.ScreenshotFullPage,
+		Selector: req.ScreenshotSelector,
+	}
 }
 
-// handleRequest handles both GET and POST requests with challenge solving.
-func (h *Handler) handleRequest(w http.ResponseWriter, ctx context.Context, req *types.Request, isPost bool, startTime time.Time) {
+// handleRequest handles GET, POST, PUT, PATCH and DELETE requests with challenge solving.
+// GET and POST navigate the page; PUT, PATCH and DELETE are issued through the
+// in-page Fetch API once the target origin is loaded.
+func (h *Handler) handleRequest(w http.ResponseWriter, r *http.Request, req *types.Request, method string, startTime time.Time) {
+	ctx := r.Context()
+	isPost := method == http.MethodPost
+	// Every method except GET may carry a request body.
+	hasBody := method != http.MethodGet
+
 	if req.URL == "" {
 		h.writeError(w, "url is required", startTime)
 		return
 	}
 
+	// evaluateJs hands the caller the page's script results; operators opt in
+	if req.EvaluateJs != "" && !h.config.EvaluateJsEnabled {
+		h.writeError(w, "evaluateJs is disabled on this server (EVALUATE_JS_ENABLED=false)", startTime)
+		return
+	}
+
+	// Refuse domains outside the target policy or in a configured quiet
+	// window before any network activity
+	if err := h.currentTargetPolicy().Check(stats.ExtractDomain(req.URL)); err != nil {
+		h.writeTargetNotAllowed(w, req.URL, err, startTime)
+		return
+	}
+	if err := h.currentQuietHours().Check(stats.ExtractDomain(req.URL), time.Now()); err != nil {
+		var qhErr *types.QuietHoursError
+		if errors.As(err, &qhErr) {
+			h.writeQuietHoursError(w, req.URL, qhErr, startTime)
+			return
+		}
+	}
+
 	// Validate URL for SSRF protection with DNS resolution and pinning
 	// DNS Pinning: The resolved IP is captured here and passed to the solver.
 	// After browser navigation, the response URL's IP is compared against this
@@ -590,18 +912,33 @@ func (h *Handler) handleRequest(w http.ResponseWriter, ctx context.Context, req
 	// Use request context to respect client-side timeouts for DNS resolution.
 	validatedURL, resolvedIP, err := security.ValidateAndResolveURLWithContext(ctx, req.URL)
 	if err != nil {
-		log.Warn().Err(err).Str("url", sanitizeURLForLogging(req.URL)).Msg("URL validation failed")
+		log.Ctx(ctx).Warn().Err(err).Str("url", sanitizeURLForLogging(req.URL)).Msg("URL validation failed")
 		h.writeError(w, fmt.Sprintf("Invalid URL: %v", err), startTime)
 		return
 	}
 	// Log resolved IP for DNS pinning
 	if resolvedIP != nil {
-		log.Debug().
+		log.Ctx(ctx).Debug().
 			Str("url", sanitizeURLForLogging(validatedURL)).
 			Str("resolved_ip", resolvedIP.String()).
 			Msg("URL validated with DNS resolution (IP pinned for rebinding protection)")
 	}
 
+	h.applyDomainPrefs(req)
+	turnstileMethods := h.applyDomainOverride(ctx, req)
+
+	profile, err := h.browserProfile(req.Profile)
+	if err != nil {
+		h.writeError(w, err.Error(), startTime)
+		return
+	}
+
+	proxyPool, err := h.resolveRequestProxy(req)
+	if err != nil {
+		h.writeError(w, err.Error(), startTime)
+		return
+	}
+
 	// Validate proxy URL if provided
 	var proxyURL string
 	if req.Proxy != nil && req.Proxy.URL != "" {
@@ -611,7 +948,7 @@ func (h *Handler) handleRequest(w http.ResponseWriter, ctx context.Context, req
 	}
 	if proxyURL != "" {
 		if err := security.ValidateProxyURL(proxyURL, h.config.AllowLocalProxies); err != nil {
-			log.Warn().Err(err).Msg("Proxy URL validation failed")
+			log.Ctx(ctx).Warn().Err(err).Msg("Proxy URL validation failed")
 			h.writeError(w, fmt.Sprintf("Invalid proxy URL: %v", err), startTime)
 			return
 		}
@@ -624,12 +961,12 @@ func (h *Handler) handleRequest(w http.ResponseWriter, ctx context.Context, req
 	)
 	if req.Proxy != nil {
 		if len(req.Proxy.Username) > maxProxyUsernameLength {
-			log.Warn().Int("len", len(req.Proxy.Username)).Msg("Proxy username too long")
+			log.Ctx(ctx).Warn().Int("len", len(req.Proxy.Username)).Msg("Proxy username too long")
 			h.writeError(w, "Proxy username exceeds maximum length of 256 characters", startTime)
 			return
 		}
 		if len(req.Proxy.Password) > maxProxyPasswordLength {
-			log.Warn().Msg("Proxy password too long") // Don't log password length
+			log.Ctx(ctx).Warn().Msg("Proxy password too long") // Don't log password length
 			h.writeError(w, "Proxy password exceeds maximum length of 256 characters", startTime)
 			return
 		}
@@ -650,14 +987,14 @@ func (h *Handler) handleRequest(w http.ResponseWriter, ctx context.Context, req
 		maxCookiePathLength   = 2048
 	)
 	if len(req.Cookies) > maxCookieCount {
-		log.Warn().Int("count", len(req.Cookies)).Msg("Too many cookies in request")
+		log.Ctx(ctx).Warn().Int("count", len(req.Cookies)).Msg("Too many cookies in request")
 		h.writeError(w, "Too many cookies (maximum 100)", startTime)
 		return
 	}
 	for _, cookie := range req.Cookies {
 		// Fix #40: Validate cookie name is not empty
 		if len(cookie.Name) == 0 {
-			log.Warn().Msg("Empty cookie name")
+			log.Ctx(ctx).Warn().Msg("Empty cookie name")
 			h.writeError(w, "Cookie name cannot be empty", startTime)
 			return
 		}
@@ -666,28 +1003,28 @@ func (h *Handler) handleRequest(w http.ResponseWriter, ctx context.Context, req
 			if len(truncName) > 50 {
 				truncName = truncName[:50]
 			}
-			log.Warn().Str("name", truncName).Msg("Cookie name too long")
+			log.Ctx(ctx).Warn().Str("name", truncName).Msg("Cookie name too long")
 			h.writeError(w, "Cookie name exceeds maximum length of 256 characters", startTime)
 			return
 		}
 		if len(cookie.Value) > maxCookieValueLength {
-			log.Warn().Str("name", cookie.Name).Msg("Cookie value too long")
+			log.Ctx(ctx).Warn().Str("name", cookie.Name).Msg("Cookie value too long")
 			h.writeError(w, "Cookie value exceeds maximum length of 4096 characters", startTime)
 			return
 		}
 		if len(cookie.Domain) > maxCookieDomainLength {
-			log.Warn().Str("name", cookie.Name).Int("len", len(cookie.Domain)).Msg("Cookie domain too long")
+			log.Ctx(ctx).Warn().Str("name", cookie.Name).Int("len", len(cookie.Domain)).Msg("Cookie domain too long")
 			h.writeError(w, "Cookie domain exceeds maximum length of 256 characters", startTime)
 			return
 		}
 		if len(cookie.Path) > maxCookiePathLength {
-			log.Warn().Str("name", cookie.Name).Int("len", len(cookie.Path)).Msg("Cookie path too long")
+			log.Ctx(ctx).Warn().Str("name", cookie.Name).Int("len", len(cookie.Path)).Msg("Cookie path too long")
 			h.writeError(w, "Cookie path exceeds maximum length of 2048 characters", startTime)
 			return
 		}
 		// Fix #41: Validate cookie path doesn't contain traversal sequences
 		if strings.Contains(cookie.Path, "..") {
-			log.Warn().Str("name", cookie.Name).Str("path", cookie.Path).Msg("Cookie path contains traversal sequence")
+			log.Ctx(ctx).Warn().Str("name", cookie.Name).Str("path", cookie.Path).Msg("Cookie path contains traversal sequence")
 			h.writeError(w, "Cookie path cannot contain '..'", startTime)
 			return
 		}
@@ -702,7 +1039,7 @@ func (h *Handler) handleRequest(w http.ResponseWriter, ctx context.Context, req
 	// Validate postData size to prevent memory exhaustion
 	const maxPostDataSize = 256 * 1024 // 256KB
 	if len(req.PostData) > maxPostDataSize {
-		log.Warn().
+		log.Ctx(ctx).Warn().
 			Int("size", len(req.PostData)).
 			Int("max_size", maxPostDataSize).
 			Msg("postData exceeds maximum size")
@@ -710,22 +1047,22 @@ func (h *Handler) handleRequest(w http.ResponseWriter, ctx context.Context, req
 		return
 	}
 
-	// Validate contentType (only for POST requests)
+	// Validate contentType (only for methods that carry a body)
 	contentType := req.ContentType
-	if isPost && contentType != "" {
+	if hasBody && contentType != "" {
 		switch contentType {
 		case types.ContentTypeFormURLEncoded, types.ContentTypeJSON:
 			// Valid content types
 		default:
-			log.Warn().Str("contentType", contentType).Msg("Invalid content type")
+			log.Ctx(ctx).Warn().Str("contentType", contentType).Msg("Invalid content type")
 			h.writeError(w, "contentType must be 'application/json' or 'application/x-www-form-urlencoded'", startTime)
 			return
 		}
 
 		// Validate JSON syntax if contentType is application/json
-		if contentType == types.ContentTypeJSON {
+		if contentType == types.ContentTypeJSON && req.PostData != "" {
 			if !json.Valid([]byte(req.PostData)) {
-				log.Warn().Msg("Invalid JSON in postData")
+				log.Ctx(ctx).Warn().Msg("Invalid JSON in postData")
 				h.writeError(w, "postData must be valid JSON when contentType is 'application/json'", startTime)
 				return
 			}
@@ -734,7 +1071,7 @@ func (h *Handler) handleRequest(w http.ResponseWriter, ctx context.Context, req
 		// Validate form-urlencoded syntax if contentType is application/x-www-form-urlencoded
 		if contentType == types.ContentTypeFormURLEncoded && req.PostData != "" {
 			if _, err := url.ParseQuery(req.PostData); err != nil {
-				log.Warn().Err(err).Msg("Invalid form-urlencoded postData")
+				log.Ctx(ctx).Warn().Err(err).Msg("Invalid form-urlencoded postData")
 				h.writeError(w, "postData must be valid form-urlencoded format", startTime)
 				return
 			}
@@ -744,7 +1081,7 @@ func (h *Handler) handleRequest(w http.ResponseWriter, ctx context.Context, req
 	// Validate custom headers
 	if len(req.Headers) > 0 {
 		if err := security.ValidateHeaders(req.Headers); err != nil {
-			log.Warn().Err(err).Msg("Header validation failed")
+			log.Ctx(ctx).Warn().Err(err).Msg("Header validation failed")
 			h.writeError(w, fmt.Sprintf("Invalid headers: %v", err), startTime)
 			return
 		}
@@ -755,20 +1092,7 @@ func (h *Handler) handleRequest(w http.ResponseWriter, ctx context.Context, req
 		h.writeError(w, "maxTimeout cannot be negative", startTime)
 		return
 	}
-	timeout := h.config.DefaultTimeout
-	if req.MaxTimeout > 0 {
-		// Fix 1.8: Cap maxTimeout to prevent integer overflow when converting to Duration
-		// Maximum safe value: 10 minutes (600,000 ms) - prevents overflow and abuse
-		const maxTimeoutMs = 10 * 60 * 1000 // 10 minutes in milliseconds
-		maxTimeoutValue := req.MaxTimeout
-		if maxTimeoutValue > maxTimeoutMs {
-			maxTimeoutValue = maxTimeoutMs
-		}
-		timeout = time.Duration(maxTimeoutValue) * time.Millisecond
-		if timeout > h.config.MaxTimeout {
-			timeout = h.config.MaxTimeout
-		}
-	}
+	timeout := h.requestTimeout(req.MaxTimeout)
 
 	// Validate and cap WaitInSeconds to prevent abuse
 	// Maximum wait is 60 seconds or remaining timeout, whichever is smaller
@@ -777,7 +1101,7 @@ func (h *Handler) handleRequest(w http.ResponseWriter, ctx context.Context, req
 	if waitInSeconds < 0 {
 		waitInSeconds = 0
 	} else if waitInSeconds > maxWaitSeconds {
-		log.Warn().
+		log.Ctx(ctx).Warn().
 			Int("requested", req.WaitInSeconds).
 			Int("capped_to", maxWaitSeconds).
 			Msg("WaitInSeconds exceeds maximum, capping")
@@ -790,7 +1114,7 @@ func (h *Handler) handleRequest(w http.ResponseWriter, ctx context.Context, req
 	if tabsTillVerify < 0 {
 		tabsTillVerify = 0
 	} else if tabsTillVerify > maxTabsTillVerify {
-		log.Warn().
+		log.Ctx(ctx).Warn().
 			Int("requested", req.TabsTillVerify).
 			Int("capped_to", maxTabsTillVerify).
 			Msg("TabsTillVerify exceeds maximum, capping")
@@ -817,11 +1141,14 @@ func (h *Handler) handleRequest(w http.ResponseWriter, ctx context.Context, req
 		ContentType:        contentType, // Content type for POST (json or form-urlencoded)
 		Headers:            req.Headers, // Custom HTTP headers
 		IsPost:             isPost,
+		Method:             fetchMethod(method),
 		Screenshot:         req.ReturnScreenshot,
+		ScreenshotOptions:  screenshotOptions(req),
 		DisableMedia:       req.DisableMedia || h.config.DisableMedia, // Per-request or global DISABLE_MEDIA env
 		WaitInSeconds:      waitInSeconds,
 		ExpectedIP:         expectedIP,     // DNS pinning: verify response URL resolves to same IP (nil = pinning off)
 		TabsTillVerify:     tabsTillVerify, // Number of Tab presses for Turnstile keyboard navigation
+		TurnstileMethods:   turnstileMethods,
 		Download:           req.Download,
 		FollowRedirects:    req.FollowRedirects,
 		CaptchaSolver:      req.CaptchaSolver,
@@ -829,19 +1156,68 @@ func (h *Handler) handleRequest(w http.ResponseWriter, ctx context.Context, req
 		UserAgent:          req.UserAgent,
 		ReturnRawHtml:      req.ReturnRawHtml,
 		ExecuteJs:          req.ExecuteJs,
+		EvaluateJs:         req.EvaluateJs,
+		CaptureRequests:    req.CaptureRequests,
+		BlockURLs:          req.BlockURLs,
+		AllowURLs:          req.AllowURLs,
+		ReturnHar:          req.ReturnHar,
+		ReturnPdf:          req.ReturnPdf,
+		PdfOptions:         req.PdfOptions,
+		ReturnSnapshot:     req.ReturnSnapshot,
+		RecordSolve:        req.RecordSolve,
+		VerifyProxy:        req.VerifyProxy,
+		SolveRecaptcha:     req.SolveRecaptcha,
+		WaitForSelector:    req.WaitForSelector,
+		WaitForText:        req.WaitForText,
+		Actions:            req.Actions,
 		CookieExtractDelay: req.CookieExtractDelay,
 		Fingerprint:        req.Fingerprint,
 		DefaultTimezone:    h.config.BrowserTimezone, // TZ env fallback; per-request fingerprint override wins
+		Locale:             req.Locale,
+		Timezone:           req.Timezone,
+		Viewport:           req.Viewport,
+		Poll:               req.Poll,
+		Limits:             req.Limits,
+		RedirectSettle:     redirectSettle(req, h.config),
+		XHRWatch:           xhrWatch(req, h.config),
+		XHRChallengeReport: req.XHRChallengeAction == types.XHRChallengeReport,
+		Profile:            profile,
+		Priority:           acquirePriority(req),
 	}
 
 	var result *solver.Result
 	var solveErr error
 
+	// An identical request solved moments ago (or solving now) answers this one
+	finishResult := func(*solver.Result, error) {}
+	if h.results != nil && resultCacheable(req, method) {
+		if key := resultCacheKey(req); key != "" {
+			cached, finish := h.results.acquire(ctx, key)
+			if cached != nil {
+				log.Ctx(ctx).Info().Str("url", sanitizeURLForLogging(req.URL)).Msg("Serving cached result of an identical request")
+				w.Header().Set("X-Result-Cache", "hit")
+				h.writeSuccess(w, r, cached, req.ReturnOnlyCookies, req.StreamResponse, startTime)
+				return
+			}
+			finishResult = finish
+			// Waiters must not hang on a solve that panicked
+			defer func() { finishResult(result, solveErr) }()
+		}
+	}
+
 	// Use session if provided
 	if req.Session != "" {
 		sess, sessErr := h.sessions.Get(req.Session)
+		if errors.Is(sessErr, types.ErrSessionNotFound) {
+			// The session may live in a shared store (another instance, or before a restart)
+			sess, sessErr = h.restoreStoredSession(ctx, req)
+		}
+		if errors.Is(sessErr, types.ErrSessionProxyRequired) || errors.Is(sessErr, types.ErrSessionProxyMismatch) {
+			h.writeError(w, fmt.Sprintf("Failed to restore session: %v", sessErr), startTime)
+			return
+		}
 		if sessErr != nil {
-			log.Warn().Err(sessErr).Str("session", req.Session).Msg("Session lookup failed")
+			log.Ctx(ctx).Warn().Err(sessErr).Str("session", req.Session).Msg("Session lookup failed")
 			h.writeError(w, "Session not found or expired", startTime)
 			return
 		}
@@ -851,42 +1227,159 @@ func (h *Handler) handleRequest(w http.ResponseWriter, ctx context.Context, req
 			opts.DefaultTimezone = sess.Timezone
 		}
 
+		// Keep the UA stable for the session's lifetime (including across a
+		// restore) unless the request overrides it.
+		if opts.UserAgent == "" && sess.UserAgent != "" {
+			opts.UserAgent = sess.UserAgent
+		}
+
 		// Acquire operation lock to prevent concurrent operations on the same session
 		// This prevents page state corruption from concurrent navigation/actions
 		sess.LockOperation()
 		defer sess.UnlockOperation()
 
+		// The session's browser exits through the session's proxy whatever
+		// the request names, so its credentials answer the proxy
+		if sess.Proxy != nil {
+			opts.Proxy = sess.Proxy
+		}
+
 		// Use AcquirePageWithRelease for reference counting to prevent
 		// race condition where page is closed during solve operation.
 		// The release function uses sync.Once to ensure exactly one release.
 		page, releasePage := sess.AcquirePageWithRelease()
 		if page == nil {
-			log.Error().Str("session", req.Session).Msg("Session page is nil or session is closing")
+			log.Ctx(ctx).Error().Str("session", req.Session).Msg("Session page is nil or session is closing")
 			h.writeError(w, "Session page is no longer available", startTime)
 			return
 		}
 		defer releasePage()
+		endSolve := sess.BeginSolve()
 		result, solveErr = h.solver.SolveWithPage(ctx, page, opts)
+		endSolve()
+
+		if h.sessions.RecordSolveOutcome(sess, isAccessDenied(solveErr)) {
+			// Give up on this egress and retry once through the next proxy
+			releasePage()
+			if err := h.sessions.RotateProxy(ctx, sess); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Str("session", req.Session).Msg("Session proxy rotation failed")
+			} else if page, releasePage = sess.AcquirePageWithRelease(); page != nil {
+				defer releasePage()
+				endSolve = sess.BeginSolve()
+				result, solveErr = h.solver.SolveWithPage(ctx, page, opts)
+				endSolve()
+				h.sessions.RecordSolveOutcome(sess, isAccessDenied(solveErr))
+			}
+		}
+		noteProxy(w, sess.Proxy)
+		if solveErr == nil && result != nil {
+			sess.RecordUserAgent(result.UserAgent)
+			if err := h.sessions.Persist(sess); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Str("session", req.Session).Msg("Failed to persist session snapshot")
+			}
+		}
 	} else {
+		// The solver adjusts opts (egress, cached clearance); a retry starts over
+		retryOpts := *opts
 		result, solveErr = h.solver.Solve(ctx, opts)
+		succeeded := solveErr == nil && result != nil && result.StatusCode >= 200 && result.StatusCode < 400
+		recordPoolProxy(proxyPool, opts.Proxy, succeeded, startTime)
+		noteProxy(w, opts.Proxy)
+
+		if deniedEgress(result, solveErr) {
+			if next := h.failoverProxy(req, proxyPool, opts.Proxy); next != nil {
+				log.Ctx(ctx).Info().
+					Str("url", sanitizeURLForLogging(req.URL)).
+					Str("refused", security.RedactProxy(opts.Proxy.URL, opts.Proxy.Username)).
+					Str("proxy", security.RedactProxy(next.URL, next.Username)).
+					Msg("Access denied, retrying through another proxy")
+				retryOpts.Proxy = next
+				retryStart := time.Now()
+				result, solveErr = h.solver.Solve(ctx, &retryOpts)
+				succeeded = solveErr == nil && result != nil && result.StatusCode >= 200 && result.StatusCode < 400
+				recordPoolProxy(proxyPool, next, succeeded, retryStart)
+				noteProxy(w, next)
+				if deniedEgress(result, solveErr) {
+					h.recordProxyBan(req.URL, next)
+				}
+			}
+		}
 	}
 
+	finishResult(result, solveErr)
+
+	solved := solveErr == nil && result != nil && result.StatusCode >= 200 && result.StatusCode < 400
+	h.domainStats.DomainMetrics().RecordSolve(stats.ExtractDomain(req.URL), solved, time.Since(startTime))
+	publishSolve(ctx, req, result, solveErr, solved, startTime)
+
 	if solveErr != nil {
-		log.Error().Err(solveErr).Str("url", sanitizeURLForLogging(req.URL)).Msg("Solve failed")
+		log.Ctx(ctx).Error().Err(solveErr).Str("url", sanitizeURLForLogging(req.URL)).Msg("Solve failed")
 
 		// Check if this is a ChallengeError (access_denied, timeout, etc.)
 		// and include rate limit hints in the response
 		var challengeErr *types.ChallengeError
 		if errors.As(solveErr, &challengeErr) && challengeErr.Type == "access_denied" {
+			events.Publish(ctx, events.Event{
+				Type:      events.RateLimitTriggered,
+				Session:   req.Session,
+				Domain:    stats.ExtractDomain(req.URL),
+				ErrorCode: "ACCESS_DENIED",
+			})
+			if req.AllowCacheFallback && h.writeCachedCopy(w, r, req, startTime) {
+				return
+			}
 			h.writeAccessDeniedError(w, req.URL, challengeErr.Message, startTime)
 			return
 		}
+		if errors.As(solveErr, &challengeErr) && challengeErr.Type == "xhr_challenged" {
+			h.writeXHRChallengedError(w, req.URL, challengeErr.Message, startTime)
+			return
+		}
+		var captchaErr *types.CaptchaError
+		if errors.As(solveErr, &captchaErr) && errors.Is(captchaErr, types.ErrCaptchaBudgetExceeded) {
+			h.writeCaptchaBudgetError(w, req.URL, captchaErr.Message, startTime)
+			return
+		}
 
 		h.writeError(w, solveErr.Error(), startTime)
 		return
 	}
 
-	h.writeSuccess(w, result, req.ReturnOnlyCookies, startTime)
+	h.writeSuccess(w, r, result, req.ReturnOnlyCookies, req.StreamResponse, startTime)
+}
+
+// redirectSettle resolves the post-clearance client redirect window: the
+// per-request redirectSettleMs wins over the CLIENT_REDIRECT_SETTLE default.
+func redirectSettle(req *types.Request, cfg *config.Config) time.Duration {
+	if req.RedirectSettleMs != nil {
+		return time.Duration(*req.RedirectSettleMs) * time.Millisecond
+	}
+	return cfg.ClientRedirectSettle
+}
+
+// xhrWatch resolves the second-order XHR challenge watch window: the
+// per-request xhrWatchMs wins over the XHR_CHALLENGE_WATCH default.
+func xhrWatch(req *types.Request, cfg *config.Config) time.Duration {
+	if req.XHRWatchMs != nil {
+		return time.Duration(*req.XHRWatchMs) * time.Millisecond
+	}
+	return cfg.XHRChallengeWatch
+}
+
+// fetchMethod returns the HTTP method to issue through the in-page Fetch API,
+// or "" for GET/POST which use regular page navigation.
+func fetchMethod(method string) string {
+	switch method {
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return method
+	}
+	return ""
+}
+
+// isAccessDenied reports whether a solve failed because the target denied access.
+func isAccessDenied(err error) bool {
+	var challengeErr *types.ChallengeError
+	return errors.As(err, &challengeErr) && challengeErr.Type == "access_denied"
 }
 
 // handleSessionCreate creates a new session.
@@ -899,41 +1392,140 @@ func (h *Handler) handleSessionCreate(w http.ResponseWriter, ctx context.Context
 		return
 	}
 
+	profile, err := h.browserProfile(req.Profile)
+	if err != nil {
+		h.writeError(w, err.Error(), startTime)
+		return
+	}
+	if _, err := h.resolveRequestProxy(req); err != nil {
+		h.writeError(w, err.Error(), startTime)
+		return
+	}
+
+	// Check the session limit before taking a browser: sessions pin pooled
+	// browsers, so at the limit Acquire would otherwise block request traffic.
+	// Re-creating an existing session stays idempotent and needs no room.
+	if _, err := h.sessions.Get(sessionID); err != nil {
+		if err := h.sessions.MakeRoom(); err != nil {
+			h.writeError(w, fmt.Sprintf("Failed to create session: %v", err), startTime)
+			return
+		}
+	}
+
+	// Look for state persisted before a restart (SESSION_PERSIST_DIR)
+	snapshot, snapErr := h.sessions.LoadSnapshot(sessionID)
+	if snapErr != nil {
+		log.Ctx(ctx).Warn().Err(snapErr).Str("session_id", sessionID).Msg("Failed to load session snapshot, creating fresh session")
+	}
+	// Snapshots hold no proxy credentials, so a session saved behind a proxy
+	// is only restored when the client sends that proxy again
+	if snapshot != nil {
+		if err := snapshot.CheckProxy(req.Proxy); err != nil {
+			h.writeError(w, fmt.Sprintf("Failed to restore session: %v", err), startTime)
+			return
+		}
+	}
+
 	// Resolve effective per-session timezone: per-session browserFlags overrides
-	// the global TZ default. Either may be empty (no override).
+	// a restored snapshot, which overrides the browser profile, which overrides
+	// the global TZ default. Any may be empty.
 	sessionTimezone := h.config.BrowserTimezone
+	if profile != nil && profile.Timezone != "" {
+		sessionTimezone = profile.Timezone
+	}
+	if snapshot != nil && snapshot.Timezone != "" {
+		sessionTimezone = snapshot.Timezone
+	}
 	if req.BrowserFlags != nil && req.BrowserFlags.Timezone != "" {
 		sessionTimezone = req.BrowserFlags.Timezone
 	}
 
+	// Convert per-request TTL from minutes to duration (0 = use server default
+	// or the TTL recorded in a restored snapshot)
+	var sessionTTL time.Duration
+	if req.SessionTTL > 0 {
+		sessionTTL = time.Duration(req.SessionTTL) * time.Minute
+	} else if snapshot != nil {
+		sessionTTL = snapshot.TTL
+	}
+
+	// Residential gateways change the exit IP per connection unless the
+	// username names a session; a restored session reuses its token.
+	proxySession := ""
+	if req.Proxy != nil && h.config.ProxyStickySessions {
+		proxySession = solver.NewProxySessionToken()
+		if snapshot != nil && snapshot.ProxySession != "" {
+			proxySession = snapshot.ProxySession
+		}
+		lifetime := sessionTTL
+		if lifetime == 0 {
+			lifetime = h.config.SessionTTL
+		}
+		if sticky := solver.StickyProxy(req.Proxy, solver.ProxyProvider(req.Proxy.Provider), proxySession, lifetime); sticky != req.Proxy {
+			req.Proxy = sticky
+			log.Ctx(ctx).Info().
+				Str("session_id", sessionID).
+				Str("proxy", security.RedactProxy(req.Proxy.URL, req.Proxy.Username)).
+				Msg("Pinned session to a sticky proxy exit IP")
+		} else {
+			proxySession = ""
+		}
+	}
+
+	sessionProxyURL := ""
+	if req.Proxy != nil {
+		sessionProxyURL = browser.ProxyLaunchURL(req.Proxy.URL, req.Proxy.Username, req.Proxy.Password)
+	}
+
 	// Acquire browser — from pool or custom-spawned with flags
 	var browserInstance *rod.Browser
 	ownsBrowser := false
 
-	if req.BrowserFlags != nil {
-		// Validate extra args against whitelist
-		for _, arg := range req.BrowserFlags.ExtraArgs {
-			if browser.IsBlockedExtraArg(arg) {
-				h.writeError(w, fmt.Sprintf("browserFlags.extraArgs: blocked flag %q", arg), startTime)
-				return
-			}
-			if !browser.IsAllowedExtraArg(arg) {
-				h.writeError(w, fmt.Sprintf("browserFlags.extraArgs: unknown flag %q", arg), startTime)
-				return
-			}
+	if req.BrowserFlags == nil && profile == nil && sessionProxyURL != "" {
+		// The proxy is applied at launch, so a pooled browser cannot serve it
+		var err error
+		browserInstance, err = h.pool.SpawnWithOptions(ctx, browser.LaunchOptions{
+			ProxyURL: sessionProxyURL,
+			Timezone: sessionTimezone,
+		})
+		if err != nil {
+			h.writeError(w, fmt.Sprintf("Failed to spawn browser for session proxy: %v", err), startTime)
+			return
 		}
-
-		opts := browser.LaunchOptions{
-			WindowSize: req.BrowserFlags.WindowSize,
-			Language:   req.BrowserFlags.Language,
-			Timezone:   sessionTimezone,
-			Headless:   req.BrowserFlags.Headless,
-			DisableGPU: req.BrowserFlags.DisableGPU,
-			ExtraArgs:  req.BrowserFlags.ExtraArgs,
+		ownsBrowser = true
+	} else if req.BrowserFlags != nil || profile != nil {
+		// Start from the profile's launch options; browserFlags override them
+		var opts browser.LaunchOptions
+		if profile != nil {
+			opts = profile.LaunchOptions(sessionProxyURL)
 		}
-		if req.Proxy != nil {
-			opts.ProxyURL = req.Proxy.URL
+		if flags := req.BrowserFlags; flags != nil {
+			// Validate extra args against whitelist
+			for _, arg := range flags.ExtraArgs {
+				if browser.IsBlockedExtraArg(arg) {
+					h.writeError(w, fmt.Sprintf("browserFlags.extraArgs: blocked flag %q", arg), startTime)
+					return
+				}
+				if !browser.IsAllowedExtraArg(arg) {
+					h.writeError(w, fmt.Sprintf("browserFlags.extraArgs: unknown flag %q", arg), startTime)
+					return
+				}
+			}
+
+			if flags.WindowSize != "" {
+				opts.WindowSize = flags.WindowSize
+			}
+			if flags.Language != "" {
+				opts.Language = flags.Language
+			}
+			if flags.Headless != nil {
+				opts.Headless = flags.Headless
+			}
+			opts.DisableGPU = flags.DisableGPU
+			opts.ExtraArgs = append(append([]string{}, opts.ExtraArgs...), flags.ExtraArgs...)
 		}
+		opts.Timezone = sessionTimezone
+		opts.ProxyURL = sessionProxyURL
 
 		var err error
 		browserInstance, err = h.pool.SpawnWithOptions(ctx, opts)
@@ -944,17 +1536,12 @@ func (h *Handler) handleSessionCreate(w http.ResponseWriter, ctx context.Context
 		ownsBrowser = true
 	} else {
 		var err error
-		browserInstance, err = h.pool.Acquire(ctx)
+		browserInstance, err = h.pool.AcquirePriority(ctx, acquirePriority(req))
 		if err != nil {
 			h.writeError(w, fmt.Sprintf("Failed to acquire browser: %v", err), startTime)
 			return
 		}
-	}
-
-	// Convert per-request TTL from minutes to duration (0 = use server default)
-	var sessionTTL time.Duration
-	if req.SessionTTL > 0 {
-		sessionTTL = time.Duration(req.SessionTTL) * time.Minute
+		h.pool.RotateSessionIdentity(browserInstance)
 	}
 
 	// Create session (note: this transfers browser ownership to session)
@@ -987,6 +1574,19 @@ func (h *Handler) handleSessionCreate(w http.ResponseWriter, ctx context.Context
 	if ownsBrowser {
 		sess.OwnsBrowser = true
 	}
+	if ownsBrowser {
+		sess.ProxyURL = sessionProxyURL
+		sess.Proxy = req.Proxy
+		sess.ProxySession = proxySession
+	}
+	if snapshot != nil {
+		h.sessions.Restore(sess, snapshot)
+	}
+	// The profile's UA applies to every solve on the session unless a
+	// restored snapshot already pinned one
+	if profile != nil && profile.UserAgent != "" && sess.UserAgent == "" {
+		sess.UserAgent = profile.UserAgent
+	}
 
 	// Apply timezone override to the session's page so it persists for the session lifetime,
 	// and record it so subsequent solves on this session reuse the same value.
@@ -994,18 +1594,23 @@ func (h *Handler) handleSessionCreate(w http.ResponseWriter, ctx context.Context
 		sess.Timezone = sessionTimezone
 		if page := sess.SafeGetPage(); page != nil {
 			if err := browser.ApplyTimezoneOverride(page, sessionTimezone); err != nil {
-				log.Warn().Err(err).Str("timezone", sessionTimezone).Str("session_id", sess.ID).Msg("Failed to apply timezone override to session page")
+				log.Ctx(ctx).Warn().Err(err).Str("timezone", sessionTimezone).Str("session_id", sess.ID).Msg("Failed to apply timezone override to session page")
 			}
 		}
 	}
 
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("session_id", sess.ID).
 		Msg("Session created")
 
+	message := "Session created successfully"
+	if snapshot != nil {
+		message = "Session restored successfully"
+	}
+
 	resp := types.Response{
 		Status:    types.StatusOK,
-		Message:   "Session created successfully",
+		Message:   message,
 		StartTime: startTime.UnixMilli(),
 		EndTime:   time.Now().UnixMilli(),
 		Version:   version.Full(),
@@ -1014,17 +1619,293 @@ func (h *Handler) handleSessionCreate(w http.ResponseWriter, ctx context.Context
 	h.writeJSONResponse(w, http.StatusOK, resp)
 }
 
+// acquirePriority maps a request's "priority" field to a pool priority.
+// Session creation is interactive, so it defaults to high.
+func acquirePriority(req *types.Request) int {
+	switch req.Priority {
+	case types.PriorityLow:
+		return browser.PriorityLow
+	case types.PriorityNormal:
+		return browser.PriorityNormal
+	case types.PriorityHigh:
+		return browser.PriorityHigh
+	}
+	if req.Cmd == types.CmdSessionsCreate {
+		return browser.PriorityHigh
+	}
+	return browser.PriorityNormal
+}
+
+// browserProfile resolves a request's "profile" field to a loaded browser
+// profile. Returns nil for an empty name.
+func (h *Handler) browserProfile(name string) (*browser.BrowserProfile, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if h.pool != nil {
+		if bp, ok := h.pool.Profile(name); ok {
+			return bp, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown browser profile %q", name)
+}
+
+// applyDomainOverride fills the settings req leaves unset from the
+// DOMAIN_OVERRIDES_PATH entry for its domain, and returns the entry's
+// preferred Turnstile methods. A profile is only applied to a request.*
+// command without a session, the only requests that take one.
+func (h *Handler) applyDomainOverride(ctx context.Context, req *types.Request) []string {
+	override := h.currentDomainOverrides().Match(stats.ExtractDomain(req.URL))
+	if override == nil {
+		return nil
+	}
+	if req.MaxTimeout == 0 && override.Timeout > 0 {
+		req.MaxTimeout = int(min(override.Timeout, h.config.MaxTimeout).Milliseconds())
+	}
+	if override.DisableMedia {
+		req.DisableMedia = true
+	}
+	if req.TabsTillVerify == 0 {
+		req.TabsTillVerify = override.TabsTillVerify
+	}
+	if req.Proxy == nil || (req.Proxy.URL == "" && req.Proxy.Pool == "") {
+		if proxy := override.ProxyConfig(); proxy != nil {
+			req.Proxy = proxy
+		}
+	}
+	if req.Profile == "" && req.Session == "" && req.Cmd != types.CmdTurnstileSolve {
+		req.Profile = override.Profile
+	}
+	if req.UserAgent == "" {
+		req.UserAgent = override.UserAgent
+	}
+	log.Ctx(ctx).Debug().
+		Str("domain", stats.ExtractDomain(req.URL)).
+		Strs("turnstile_methods", override.TurnstileMethods).
+		Msg("Applied domain override")
+	return override.TurnstileMethods
+}
+
+// warnUnknownOverridePools logs the pools domain overrides refer to that are
+// not among pools.
+func warnUnknownOverridePools(overrides solver.DomainOverrides, pools map[string]*solver.ProxyPool) {
+	for _, name := range overrides.Pools() {
+		if pools[name] == nil {
+			log.Warn().Str("pool", name).Msg("DOMAIN_OVERRIDES_PATH refers to an unknown proxy pool; matching requests will fail")
+		}
+	}
+}
+
+// resolveRequestProxy routes a request that names no proxy by PROXY_ROUTES,
+// then replaces a proxy.pool reference with a proxy picked from that pool
+// for the target domain. Returns the pool the outcome should be recorded
+// with, or nil when no pool is involved.
+func (h *Handler) resolveRequestProxy(req *types.Request) (*solver.ProxyPool, error) {
+	if req.Proxy == nil || (req.Proxy.URL == "" && req.Proxy.Pool == "") {
+		if routed := h.proxyRoutes.Match(stats.ExtractDomain(req.URL)); routed != nil {
+			req.Proxy = routed
+			log.Debug().
+				Str("proxy", security.RedactProxyURL(routed.URL)).
+				Str("pool", routed.Pool).
+				Msg("Applied per-domain proxy route")
+		}
+	}
+	if req.Proxy == nil || req.Proxy.Pool == "" {
+		return nil, nil
+	}
+	pool := h.currentProxyPools()[req.Proxy.Pool]
+	if pool == nil {
+		return nil, fmt.Errorf("unknown proxy pool %q", req.Proxy.Pool)
+	}
+	provider := req.Proxy.Provider
+	req.Proxy = pool.Select(stats.ExtractDomain(req.URL))
+	req.Proxy.Provider = provider
+	log.Debug().
+		Str("pool", pool.Name()).
+		Str("proxy", security.RedactProxyURL(req.Proxy.URL)).
+		Msg("Selected proxy from pool")
+	return pool, nil
+}
+
+// proxyBanMemory is how long a proxy a domain refused is passed over when
+// failing over for that domain.
+const proxyBanMemory = time.Hour
+
+// deniedEgress reports whether the target refused the solve's egress: an
+// access_denied error, or a page Cloudflare blocked with an access error
+// such as 1020.
+func deniedEgress(result *solver.Result, err error) bool {
+	if err != nil {
+		return isAccessDenied(err)
+	}
+	return result != nil && ratelimit.Detect(result.StatusCode, result.HTML).Category == ratelimit.CategoryAccessDenied
+}
+
+// failoverProxy records refused as banned for the request's domain and
+// returns the proxy to retry through: another proxy of the request's pool,
+// or of PROXY_LIST when the solver picked the egress. Proxies the domain
+// refused recently are passed over. Returns nil when there is no pool to
+// fail over within, no other proxy, or failover is disabled.
+func (h *Handler) failoverProxy(req *types.Request, pool *solver.ProxyPool, refused *types.Proxy) *types.Proxy {
+	if refused == nil {
+		return nil
+	}
+	h.recordProxyBan(req.URL, refused)
+	if !h.config.ProxyFailoverEnabled {
+		return nil
+	}
+
+	domain := stats.ExtractDomain(req.URL)
+	avoid := func(p *types.Proxy) bool {
+		if p.URL == refused.URL && p.Username == refused.Username {
+			return true
+		}
+		return h.domainStats != nil && h.domainStats.ProxyBannedWithin(domain, security.RedactProxy(p.URL, p.Username), proxyBanMemory)
+	}
+	switch {
+	case pool != nil:
+		return pool.SelectOther(domain, avoid)
+	case req.Proxy == nil:
+		// No proxy of its own: the solver chose from PROXY_LIST
+		return h.egressPool.SelectOther(domain, avoid)
+	}
+	return nil
+}
+
+// recordProxyBan records in the domain stats that the target refused proxy.
+func (h *Handler) recordProxyBan(targetURL string, proxy *types.Proxy) {
+	if h.domainStats != nil && proxy != nil {
+		h.domainStats.RecordProxyBan(stats.ExtractDomain(targetURL), security.RedactProxy(proxy.URL, proxy.Username))
+	}
+}
+
+// recordPoolProxy counts a finished solve against the pool proxy it used.
+func recordPoolProxy(pool *solver.ProxyPool, proxy *types.Proxy, success bool, startTime time.Time) {
+	if pool != nil {
+		pool.RecordOutcome(proxy, success, time.Since(startTime).Milliseconds())
+	}
+}
+
+// restoreStoredSession recreates the request's session when it is unknown to
+// this instance but has a snapshot in the session store, e.g. one created on
+// another instance behind a load balancer. Returns ErrSessionNotFound if there
+// is none, ErrSessionProxyRequired if it was saved behind a proxy the
+// request does not name, and ErrSessionProxyMismatch if the request names
+// another.
+func (h *Handler) restoreStoredSession(ctx context.Context, req *types.Request) (*session.Session, error) {
+	id := req.Session
+	snapshot, err := h.sessions.LoadSnapshot(id)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil {
+		return nil, types.ErrSessionNotFound
+	}
+
+	// Keep the original egress: the restored cf_clearance is IP-bound, and
+	// the snapshot holds no credentials, so the request supplies the proxy
+	if err := snapshot.CheckProxy(req.Proxy); err != nil {
+		return nil, err
+	}
+	var proxy *types.Proxy
+	proxyURL := ""
+	if snapshot.ProxyURL != "" {
+		proxy = req.Proxy
+		if h.config.ProxyStickySessions && snapshot.ProxySession != "" {
+			lifetime := snapshot.TTL
+			if lifetime == 0 {
+				lifetime = h.config.SessionTTL
+			}
+			proxy = solver.StickyProxy(proxy, solver.ProxyProvider(proxy.Provider), snapshot.ProxySession, lifetime)
+		}
+		proxyURL = browser.ProxyLaunchURL(proxy.URL, proxy.Username, proxy.Password)
+	}
+
+	var browserInstance *rod.Browser
+	ownsBrowser := proxyURL != ""
+	if ownsBrowser {
+		browserInstance, err = h.pool.SpawnWithOptions(ctx, browser.LaunchOptions{
+			ProxyURL: proxyURL,
+			Timezone: snapshot.Timezone,
+		})
+	} else {
+		// A request is waiting on this session, so treat it as interactive
+		browserInstance, err = h.pool.AcquirePriority(ctx, browser.PriorityHigh)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire browser for stored session: %w", err)
+	}
+
+	sess, err := h.sessions.Create(id, browserInstance, snapshot.TTL)
+	if err != nil {
+		if errors.Is(err, types.ErrSessionAlreadyExists) {
+			// A concurrent request restored it first
+			return h.sessions.Get(id)
+		}
+		return nil, err
+	}
+	if ownsBrowser {
+		sess.OwnsBrowser = true
+		sess.ProxyURL = proxyURL
+		sess.Proxy = proxy
+	}
+	h.sessions.Restore(sess, snapshot)
+
+	if sess.Timezone != "" {
+		if page := sess.SafeGetPage(); page != nil {
+			if err := browser.ApplyTimezoneOverride(page, sess.Timezone); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Str("timezone", sess.Timezone).Str("session_id", id).Msg("Failed to apply timezone override to restored session page")
+			}
+			sess.ReleasePage()
+		}
+	}
+
+	return sess, nil
+}
+
 // handleSessionList lists all active sessions.
 func (h *Handler) handleSessionList(w http.ResponseWriter, startTime time.Time) {
-	sessions := h.sessions.List()
+	infos := h.sessions.ListInfo()
+	sessions := make([]string, 0, len(infos))
+	for _, info := range infos {
+		sessions = append(sessions, info.ID)
+	}
 
 	resp := types.Response{
-		Status:    types.StatusOK,
-		Message:   "Session list retrieved",
-		StartTime: startTime.UnixMilli(),
-		EndTime:   time.Now().UnixMilli(),
-		Version:   version.Full(),
-		Sessions:  sessions,
+		Status:       types.StatusOK,
+		Message:      "Session list retrieved",
+		StartTime:    startTime.UnixMilli(),
+		EndTime:      time.Now().UnixMilli(),
+		Version:      version.Full(),
+		Sessions:     sessions,
+		SessionsInfo: infos,
+	}
+	h.writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// handleSessionInfo reports detailed state for one session, or for all
+// sessions when none is given.
+func (h *Handler) handleSessionInfo(w http.ResponseWriter, ctx context.Context, req *types.Request, startTime time.Time) {
+	if req.Session != "" {
+		if errMsg := security.ValidateSessionID(req.Session); errMsg != "" {
+			h.writeError(w, errMsg, startTime)
+			return
+		}
+	}
+
+	details, err := h.sessions.Details(ctx, req.Session)
+	if err != nil {
+		h.writeError(w, "Session not found", startTime)
+		return
+	}
+
+	resp := types.Response{
+		Status:         types.StatusOK,
+		Message:        "Session info retrieved",
+		StartTime:      startTime.UnixMilli(),
+		EndTime:        time.Now().UnixMilli(),
+		Version:        version.Full(),
+		SessionDetails: details,
 	}
 	h.writeJSONResponse(w, http.StatusOK, resp)
 }
@@ -1094,8 +1975,211 @@ func (h *Handler) handleSessionKeepalive(w http.ResponseWriter, req *types.Reque
 	h.writeJSONResponse(w, http.StatusOK, resp)
 }
 
-// writeSuccess writes a successful response.
-func (h *Handler) writeSuccess(w http.ResponseWriter, result *solver.Result, cookiesOnly bool, startTime time.Time) {
+// handleSessionTouch resets a session's idle timer. With a url it also
+// navigates the session's page there, without running a solve, so the site
+// sees the session's cookies and cf_clearance stays warm. The response says
+// whether a challenge came back, in which case a full request is due.
+func (h *Handler) handleSessionTouch(w http.ResponseWriter, ctx context.Context, req *types.Request, startTime time.Time) {
+	if req.Session == "" {
+		h.writeError(w, "session is required", startTime)
+		return
+	}
+	if errMsg := security.ValidateSessionID(req.Session); errMsg != "" {
+		h.writeError(w, errMsg, startTime)
+		return
+	}
+	if req.MaxTimeout < 0 {
+		h.writeError(w, "maxTimeout cannot be negative", startTime)
+		return
+	}
+
+	// Get refreshes the idle timer
+	sess, err := h.sessions.Get(req.Session)
+	if err != nil {
+		h.writeError(w, "Session not found", startTime)
+		return
+	}
+
+	resp := types.Response{
+		Status:  types.StatusOK,
+		Message: "Session touched",
+		Version: version.Full(),
+	}
+	if req.URL != "" {
+		if err := h.currentTargetPolicy().Check(stats.ExtractDomain(req.URL)); err != nil {
+			h.writeTargetNotAllowed(w, req.URL, err, startTime)
+			return
+		}
+		if err := h.currentQuietHours().Check(stats.ExtractDomain(req.URL), time.Now()); err != nil {
+			var qhErr *types.QuietHoursError
+			if errors.As(err, &qhErr) {
+				h.writeQuietHoursError(w, req.URL, qhErr, startTime)
+				return
+			}
+		}
+		validatedURL, _, err := security.ValidateAndResolveURLWithContext(ctx, req.URL)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("url", sanitizeURLForLogging(req.URL)).Msg("URL validation failed")
+			h.writeError(w, fmt.Sprintf("Invalid URL: %v", err), startTime)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, h.requestTimeout(req.MaxTimeout))
+		defer cancel()
+
+		sess.LockOperation()
+		defer sess.UnlockOperation()
+		page, releasePage := sess.AcquirePageWithRelease()
+		if page == nil {
+			h.writeError(w, "Session page is no longer available", startTime)
+			return
+		}
+		defer releasePage()
+
+		warm, err := h.solver.WarmPage(ctx, page, validatedURL)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("session_id", req.Session).Msg("Session warm-up navigation failed")
+			h.writeError(w, fmt.Sprintf("Session touch failed: %v", err), startTime)
+			return
+		}
+		sess.Touch()
+		if err := h.sessions.Persist(sess); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("session_id", req.Session).Msg("Failed to persist session snapshot")
+		}
+
+		resp.SessionTouch = &types.SessionTouch{
+			URL:          warm.URL,
+			Challenged:   warm.Challenged,
+			HasClearance: warm.HasClearance,
+		}
+		if !warm.ClearanceExpires.IsZero() {
+			resp.SessionTouch.ClearanceExpiresAt = warm.ClearanceExpires.UnixMilli()
+		}
+		if warm.Challenged {
+			resp.Message = "Session touched, challenge detected"
+		}
+		log.Ctx(ctx).Info().
+			Str("session_id", req.Session).
+			Str("url", sanitizeURLForLogging(warm.URL)).
+			Bool("challenged", warm.Challenged).
+			Bool("has_clearance", warm.HasClearance).
+			Msg("Session warmed")
+	}
+
+	resp.StartTime = startTime.UnixMilli()
+	resp.EndTime = time.Now().UnixMilli()
+	h.writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// requestTimeout returns the effective timeout for a request's maxTimeout (ms),
+// capped at the configured MaxTimeout. Zero selects the default.
+func (h *Handler) requestTimeout(maxTimeout int) time.Duration {
+	if maxTimeout <= 0 {
+		return h.config.DefaultTimeout
+	}
+	// Fix 1.8: Cap maxTimeout to prevent integer overflow when converting to Duration
+	// Maximum safe value: 10 minutes (600,000 ms) - prevents overflow and abuse
+	const maxTimeoutMs = 10 * 60 * 1000 // 10 minutes in milliseconds
+	timeout := time.Duration(min(maxTimeout, maxTimeoutMs)) * time.Millisecond
+	if timeout > h.config.MaxTimeout {
+		timeout = h.config.MaxTimeout
+	}
+	return timeout
+}
+
+// handleSessionExport returns a session's cookie jar and localStorage so it
+// can be backed up or imported into another instance.
+func (h *Handler) handleSessionExport(w http.ResponseWriter, req *types.Request, startTime time.Time) {
+	if req.Session == "" {
+		h.writeError(w, "session is required", startTime)
+		return
+	}
+	if errMsg := security.ValidateSessionID(req.Session); errMsg != "" {
+		h.writeError(w, errMsg, startTime)
+		return
+	}
+
+	sess, err := h.sessions.Get(req.Session)
+	if err != nil {
+		h.writeError(w, "Session not found", startTime)
+		return
+	}
+
+	// Don't read the page while a solve is navigating it
+	sess.LockOperation()
+	state, err := h.sessions.Export(sess)
+	sess.UnlockOperation()
+	if err != nil {
+		log.Warn().Err(err).Str("session_id", req.Session).Msg("Failed to export session")
+		h.writeError(w, "Failed to export session", startTime)
+		return
+	}
+
+	log.Info().
+		Str("session_id", req.Session).
+		Int("cookies", len(state.Cookies)).
+		Int("local_storage", len(state.LocalStorage)).
+		Msg("Session exported")
+
+	resp := types.Response{
+		Status:       types.StatusOK,
+		Message:      "Session exported successfully",
+		StartTime:    startTime.UnixMilli(),
+		EndTime:      time.Now().UnixMilli(),
+		Version:      version.Full(),
+		SessionState: state,
+	}
+	h.writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// handleSessionImport loads a sessions.export state into an existing session.
+func (h *Handler) handleSessionImport(w http.ResponseWriter, req *types.Request, startTime time.Time) {
+	if req.Session == "" {
+		h.writeError(w, "session is required", startTime)
+		return
+	}
+	if errMsg := security.ValidateSessionID(req.Session); errMsg != "" {
+		h.writeError(w, errMsg, startTime)
+		return
+	}
+	if req.SessionState == nil {
+		h.writeError(w, "sessionState is required", startTime)
+		return
+	}
+
+	sess, err := h.sessions.Get(req.Session)
+	if err != nil {
+		h.writeError(w, "Session not found", startTime)
+		return
+	}
+
+	sess.LockOperation()
+	err = h.sessions.Import(sess, req.SessionState)
+	if err == nil {
+		// Keep the persisted snapshot in line with the imported state
+		if perr := h.sessions.Persist(sess); perr != nil {
+			log.Warn().Err(perr).Str("session_id", req.Session).Msg("Failed to persist imported session")
+		}
+	}
+	sess.UnlockOperation()
+	if err != nil {
+		log.Warn().Err(err).Str("session_id", req.Session).Msg("Failed to import session")
+		h.writeError(w, "Failed to import session", startTime)
+		return
+	}
+
+	resp := types.Response{
+		Status:    types.StatusOK,
+		Message:   "Session imported successfully",
+		StartTime: startTime.UnixMilli(),
+		EndTime:   time.Now().UnixMilli(),
+		Version:   version.Full(),
+	}
+	h.writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// writeSuccess writes a successful response, as NDJSON when stream is set.
+func (h *Handler) writeSuccess(w http.ResponseWriter, r *http.Request, result *solver.Result, cookiesOnly, stream bool, startTime time.Time) {
 	cookies := make([]types.Cookie, 0, len(result.Cookies))
 	for _, c := range result.Cookies {
 		cookie := types.Cookie{
@@ -1113,24 +2197,21 @@ func (h *Handler) writeSuccess(w http.ResponseWriter, result *solver.Result, coo
 		cookies = append(cookies, cookie)
 	}
 
-	response := ""
-	if !cookiesOnly {
-		response = result.HTML
-	}
-
 	solution := &types.Solution{
 		URL:              result.URL,
 		Status:           result.StatusCode,
-		Response:         response,
 		ResponseEncoding: result.ResponseEncoding,
 		Cookies:          cookies,
 		UserAgent:        result.UserAgent,
 		BrowserVersion:   extractChromeVersion(result.UserAgent),
 		Screenshot:       result.Screenshot,
 		TurnstileToken:   result.TurnstileToken,
+		RecaptchaToken:   result.RecaptchaToken,
 		LocalStorage:     result.LocalStorage,
 		SessionStorage:   result.SessionStorage,
 		ResponseHeaders:  result.ResponseHeaders,
+		ClientRedirects:  result.ClientRedirects,
+		EgressIP:         result.EgressIP,
 	}
 
 	// Add response metadata if applicable
@@ -1144,6 +2225,14 @@ func (h *Handler) writeSuccess(w http.ResponseWriter, result *solver.Result, coo
 	if result.ExecuteJsResult != "" {
 		solution.ExecuteJsResult = &result.ExecuteJsResult
 	}
+	solution.JSResult = result.JSResult
+	solution.JSError = result.JSError
+	solution.CapturedRequests = result.CapturedRequests
+	solution.Har = result.HAR
+	solution.Pdf = result.Pdf
+	solution.Snapshot = result.Snapshot
+	solution.Recording = result.Recording
+	solution.RecordingPath = result.RecordingPath
 
 	// Detect rate limiting in the response
 	rateLimitInfo := ratelimit.Detect(result.StatusCode, result.HTML)
@@ -1155,11 +2244,17 @@ func (h *Handler) writeSuccess(w http.ResponseWriter, result *solver.Result, coo
 		category := string(rateLimitInfo.Category)
 		solution.ErrorCategory = &category
 
-		log.Info().
+		log.Ctx(r.Context()).Info().
 			Str("error_code", rateLimitInfo.ErrorCode).
 			Str("category", category).
 			Int("suggested_delay_ms", rateLimitInfo.SuggestedDelay).
 			Msg("Rate limiting detected in response")
+		events.Publish(r.Context(), events.Event{
+			Type:      events.RateLimitTriggered,
+			Domain:    stats.ExtractDomain(result.URL),
+			Status:    result.StatusCode,
+			ErrorCode: rateLimitInfo.ErrorCode,
+		})
 	}
 
 	// Extract domain and record stats
@@ -1181,7 +2276,16 @@ func (h *Handler) writeSuccess(w http.ResponseWriter, result *solver.Result, coo
 		Version:   version.Full(),
 		Solution:  solution,
 	}
-	h.writeJSONResponse(w, http.StatusOK, resp)
+	if cookiesOnly {
+		h.writeJSONResponse(w, http.StatusOK, resp)
+		return
+	}
+	if stream {
+		h.writeSolutionStream(w, r, resp, result.HTML)
+		return
+	}
+	// The HTML is streamed into the encoder rather than copied into the response
+	h.writeSolutionResponse(w, r, resp, result.HTML)
 }
 
 // addDomainHeaders adds X-Domain-* headers to the response.
@@ -1249,6 +2353,103 @@ func (h *Handler) writeAccessDeniedError(w http.ResponseWriter, requestURL strin
 	h.writeJSONResponse(w, http.StatusOK, resp)
 }
 
+// writeXHRChallengedError writes an error for a page whose document was
+// cleared but whose in-page XHR/fetch calls are still being challenged.
+func (h *Handler) writeXHRChallengedError(w http.ResponseWriter, requestURL string, message string, startTime time.Time) {
+	errorCode := "XHR_CHALLENGED"
+	errorCategory := "xhr_challenge"
+
+	resp := types.Response{
+		Status:    types.StatusError,
+		Message:   message,
+		StartTime: startTime.UnixMilli(),
+		EndTime:   time.Now().UnixMilli(),
+		Version:   version.Full(),
+		Solution: &types.Solution{
+			URL:           requestURL,
+			ErrorCode:     &errorCode,
+			ErrorCategory: &errorCategory,
+		},
+	}
+
+	log.Info().
+		Str("error_code", errorCode).
+		Str("url", sanitizeURLForLogging(requestURL)).
+		Msg("In-page request challenged after clearance")
+
+	h.writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// writeCaptchaBudgetError writes a CAPTCHA_BUDGET_EXCEEDED error for a
+// challenge left unsolved because the external solver spend budget is used up.
+func (h *Handler) writeCaptchaBudgetError(w http.ResponseWriter, requestURL string, message string, startTime time.Time) {
+	errorCode := "CAPTCHA_BUDGET_EXCEEDED"
+	errorCategory := "captcha"
+
+	resp := types.Response{
+		Status:    types.StatusError,
+		Message:   message,
+		StartTime: startTime.UnixMilli(),
+		EndTime:   time.Now().UnixMilli(),
+		Version:   version.Full(),
+		Solution: &types.Solution{
+			URL:           requestURL,
+			ErrorCode:     &errorCode,
+			ErrorCategory: &errorCategory,
+		},
+	}
+
+	log.Warn().
+		Str("error_code", errorCode).
+		Str("url", sanitizeURLForLogging(requestURL)).
+		Msg("Challenge unsolved, captcha spend budget exceeded")
+
+	h.writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// writeTargetNotAllowed refuses a request whose target the domain policy
+// does not permit.
+func (h *Handler) writeTargetNotAllowed(w http.ResponseWriter, requestURL string, err error, startTime time.Time) {
+	log.Warn().Str("url", sanitizeURLForLogging(requestURL)).Msg("Target refused by domain policy")
+	h.writeError(w, err.Error(), startTime)
+}
+
+// writeQuietHoursError writes a QUIET_HOURS error telling the client when
+// the domain may be contacted again. Retry-After carries the same hint.
+func (h *Handler) writeQuietHoursError(w http.ResponseWriter, requestURL string, qhErr *types.QuietHoursError, startTime time.Time) {
+	errorCode := "QUIET_HOURS"
+	errorCategory := "quiet_hours"
+	nextAllowedAt := qhErr.NextAllowedAt.UTC().Format(time.RFC3339)
+	suggestedDelay := int(time.Until(qhErr.NextAllowedAt).Milliseconds())
+	if suggestedDelay < 0 {
+		suggestedDelay = 0
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa((suggestedDelay+999)/1000))
+
+	resp := types.Response{
+		Status:    types.StatusError,
+		Message:   qhErr.Message,
+		StartTime: startTime.UnixMilli(),
+		EndTime:   time.Now().UnixMilli(),
+		Version:   version.Full(),
+		Solution: &types.Solution{
+			URL:              requestURL,
+			SuggestedDelayMs: &suggestedDelay,
+			ErrorCode:        &errorCode,
+			ErrorCategory:    &errorCategory,
+			NextAllowedAt:    &nextAllowedAt,
+		},
+	}
+
+	log.Info().
+		Str("domain", qhErr.Domain).
+		Str("next_allowed_at", nextAllowedAt).
+		Msg("Request refused during quiet hours")
+
+	h.writeJSONResponse(w, http.StatusOK, resp)
+}
+
 // sanitizeErrorMessage removes internal details from error messages
 // to prevent information disclosure to clients.
 func sanitizeErrorMessage(message string) string {
@@ -1304,9 +2505,20 @@ func (h *Handler) writeErrorWithStatus(w http.ResponseWriter, statusCode int, me
 	h.writeJSONResponse(w, statusCode, resp)
 }
 
+// setRequestID copies the request ID the RequestID middleware put in the
+// response header into resp.
+func setRequestID(w http.ResponseWriter, resp *types.Response) {
+	resp.RequestID = w.Header().Get(middleware.RequestIDHeader)
+}
+
 // writeJSONResponse buffers JSON before writing to ensure encoding errors are caught
 // before headers are sent. Bug 6: Prevents partial responses on encoding failure.
 func (h *Handler) writeJSONResponse(w http.ResponseWriter, statusCode int, resp interface{}) {
+	if r, ok := resp.(types.Response); ok {
+		setRequestID(w, &r)
+		resp = r
+	}
+
 	buf := getResponseBuffer()
 	defer putResponseBuffer(buf)
 
@@ -1319,6 +2531,9 @@ func (h *Handler) writeJSONResponse(w http.ResponseWriter, statusCode int, resp
 		return
 	}
 
+	if r, ok := resp.(types.Response); ok {
+		noteOutcome(w, &r)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	if statusCode != http.StatusOK {
 		w.WriteHeader(statusCode)
@@ -2,16 +2,37 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/audit"
+	"github.com/Rorqualx/flaresolverr-go/internal/browser"
+	"github.com/Rorqualx/flaresolverr-go/internal/captcha"
 	"github.com/Rorqualx/flaresolverr-go/internal/config"
+	"github.com/Rorqualx/flaresolverr-go/internal/events"
+	"github.com/Rorqualx/flaresolverr-go/internal/logstream"
 	"github.com/Rorqualx/flaresolverr-go/internal/middleware"
+	"github.com/Rorqualx/flaresolverr-go/internal/proxyhealth"
+	"github.com/Rorqualx/flaresolverr-go/internal/quiethours"
 	"github.com/Rorqualx/flaresolverr-go/internal/session"
+	"github.com/Rorqualx/flaresolverr-go/internal/solver"
+	"github.com/Rorqualx/flaresolverr-go/internal/stats"
+	"github.com/Rorqualx/flaresolverr-go/internal/targetpolicy"
 	"github.com/Rorqualx/flaresolverr-go/internal/types"
+	"github.com/Rorqualx/flaresolverr-go/internal/webcache"
 )
 
 // mockHandler creates a handler without a real browser pool for testing
@@ -64,6 +85,84 @@ func TestHealthEndpoint(t *testing.T) {
 	}
 }
 
+func TestHealthDeepProbe(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	// Without a pool the deep probe cannot navigate, so the service is unhealthy
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/health?probe=deep", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+	var resp HealthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if resp.Status != types.StatusError || resp.Probe == nil || resp.Probe.Mode != "deep" || resp.Probe.Error == "" {
+		t.Errorf("Unexpected response: %+v (probe %+v)", resp, resp.Probe)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/health?probe=shallow", nil))
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), `"probe"`) {
+		t.Errorf("Shallow probe: status %d, body %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/health?probe=full", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown probe, got %d", w.Code)
+	}
+}
+
+func TestLivenessAndReadiness(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/livez", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("/livez: expected 200, got %d", w.Code)
+	}
+
+	// Without a pool there is nothing to serve requests with
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz: expected 503, got %d", w.Code)
+	}
+	var status ProbeStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil || status.Status != types.StatusError {
+		t.Errorf("/readyz body = %s (%v)", w.Body.String(), err)
+	}
+}
+
+func TestStartupHandler(t *testing.T) {
+	s := NewStartupHandler()
+
+	serve := func(path string) int {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Code
+	}
+	if code := serve("/livez"); code != http.StatusOK {
+		t.Errorf("/livez while starting: got %d, want 200", code)
+	}
+	for _, path := range []string{"/readyz", "/health", "/v1"} {
+		if code := serve(path); code != http.StatusServiceUnavailable {
+			t.Errorf("%s while starting: got %d, want 503", path, code)
+		}
+	}
+
+	s.Ready(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	if code := serve("/readyz"); code != http.StatusTeapot {
+		t.Errorf("after Ready: got %d, want the real handler's response", code)
+	}
+}
+
 func TestV1Endpoint(t *testing.T) {
 	h := mockHandler()
 	defer h.sessions.Close()
@@ -248,6 +347,48 @@ func TestSessionCreateMissingID(t *testing.T) {
 	}
 }
 
+func TestSessionCreateRestoreRequiresProxy(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxy   *types.Proxy
+		wantErr error
+	}{
+		{"no proxy", nil, types.ErrSessionProxyRequired},
+		{"another proxy", &types.Proxy{URL: "http://other.example.com:8080", Username: "user", Password: "pass"}, types.ErrSessionProxyMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := mockHandler()
+			h.sessions.Close()
+			h.config.SessionPersistDir = t.TempDir()
+			h.sessions = session.NewManager(h.config, nil)
+			defer h.sessions.Close()
+
+			store, err := session.NewFileStore(h.config.SessionPersistDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			id := "proxied-session-0001"
+			snap := &session.Snapshot{ID: id, ProxyURL: "http://proxy.example.com:8080", SavedAt: time.Now()}
+			if err := store.Save(context.Background(), snap, 0); err != nil {
+				t.Fatal(err)
+			}
+
+			bodyBytes, _ := json.Marshal(types.Request{Cmd: types.CmdSessionsCreate, Session: id, Proxy: tt.proxy})
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("POST", "/v1", bytes.NewReader(bodyBytes)))
+
+			var resp types.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if resp.Status != types.StatusError || !strings.Contains(resp.Message, tt.wantErr.Error()) {
+				t.Errorf("Restoring with %v = %q %q, want %v", tt.proxy, resp.Status, resp.Message, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestSessionDestroyMissingID(t *testing.T) {
 	h := mockHandler()
 	defer h.sessions.Close()
@@ -295,6 +436,251 @@ func TestSessionDestroyNotFound(t *testing.T) {
 	}
 }
 
+func TestSessionExportImportErrors(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	tests := []struct {
+		name    string
+		body    types.Request
+		wantMsg string
+	}{
+		{"export missing session", types.Request{Cmd: types.CmdSessionsExport}, "session is required"},
+		{"export not found", types.Request{Cmd: types.CmdSessionsExport, Session: "nonexistent-session-id"}, "Session not found"},
+		{"import missing state", types.Request{Cmd: types.CmdSessionsImport, Session: "nonexistent-session-id"}, "sessionState is required"},
+		{"import not found", types.Request{
+			Cmd:          types.CmdSessionsImport,
+			Session:      "nonexistent-session-id",
+			SessionState: &types.SessionState{Cookies: []types.Cookie{{Name: "cf_clearance", Value: "v", Domain: ".example.com"}}},
+		}, "Session not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodyBytes, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest("POST", "/v1", bytes.NewReader(bodyBytes))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			var resp types.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if resp.Status != types.StatusError {
+				t.Errorf("Expected error status, got %q", resp.Status)
+			}
+			if resp.Message != tt.wantMsg {
+				t.Errorf("Message = %q, want %q", resp.Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestSessionTouchErrors(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	tests := []struct {
+		name    string
+		body    types.Request
+		wantMsg string
+	}{
+		{"missing session", types.Request{Cmd: types.CmdSessionsTouch}, "session is required"},
+		{"not found", types.Request{Cmd: types.CmdSessionsTouch, Session: "nonexistent-session-id"}, "Session not found"},
+		{"not found with url", types.Request{
+			Cmd:     types.CmdSessionsTouch,
+			Session: "nonexistent-session-id",
+			URL:     "https://example.com/",
+		}, "Session not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodyBytes, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest("POST", "/v1", bytes.NewReader(bodyBytes))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			var resp types.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if resp.Status != types.StatusError {
+				t.Errorf("Expected error status, got %q", resp.Status)
+			}
+			if resp.Message != tt.wantMsg {
+				t.Errorf("Message = %q, want %q", resp.Message, tt.wantMsg)
+			}
+			if resp.SessionTouch != nil {
+				t.Errorf("SessionTouch = %+v, want nil on error", resp.SessionTouch)
+			}
+		})
+	}
+}
+
+// TestScopedAPIKeyCommands verifies that a scoped API key can only run the
+// commands it was granted.
+func TestScopedAPIKeyCommands(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	h.config.APIKeyEnabled = true
+	keys, err := middleware.ParseScopedAPIKeys([]byte(`[{name: lister, key: "lister-key-0123456789", commands: [sessions.list]}]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := middleware.APIKey(h.config, keys...)(h)
+
+	call := func(cmd string) (int, types.Response) {
+		bodyBytes, _ := json.Marshal(types.Request{Cmd: cmd, Session: "scoped-session-0123456789"})
+		req := httptest.NewRequest("POST", "/v1", bytes.NewReader(bodyBytes))
+		req.Header.Set("X-API-Key", "lister-key-0123456789")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		var resp types.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return w.Code, resp
+	}
+
+	if code, resp := call(types.CmdSessionsList); code != http.StatusOK || resp.Status != types.StatusOK {
+		t.Errorf("sessions.list: got %d %q", code, resp.Message)
+	}
+	code, resp := call(types.CmdSessionsDestroy)
+	if code != http.StatusForbidden || resp.Status != types.StatusError {
+		t.Errorf("sessions.destroy: got %d %q, want 403", code, resp.Status)
+	}
+	if !strings.Contains(resp.Message, "not permitted") {
+		t.Errorf("sessions.destroy: message = %q", resp.Message)
+	}
+}
+
+func TestSessionInfo(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	post := func(body types.Request) types.Response {
+		bodyBytes, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/v1", bytes.NewReader(bodyBytes)))
+		var resp types.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return resp
+	}
+
+	resp := post(types.Request{Cmd: types.CmdSessionsInfo})
+	if resp.Status != types.StatusOK {
+		t.Errorf("Expected ok status for all sessions, got %q (%s)", resp.Status, resp.Message)
+	}
+	if len(resp.SessionDetails) != 0 {
+		t.Errorf("Expected no session details, got %d", len(resp.SessionDetails))
+	}
+
+	resp = post(types.Request{Cmd: types.CmdSessionsInfo, Session: "nonexistent-session-id"})
+	if resp.Status != types.StatusError || resp.Message != "Session not found" {
+		t.Errorf("Expected session not found error, got %q: %q", resp.Status, resp.Message)
+	}
+}
+
+func TestRequestTagsRecorded(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+	h.tagStats = stats.NewTagStats([]string{"app"}, 10)
+
+	post := func(body types.Request) {
+		bodyBytes, _ := json.Marshal(body)
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/v1", bytes.NewReader(bodyBytes)))
+	}
+	tags := map[string]string{"app": "prowlarr", "team": "media"}
+	post(types.Request{Cmd: types.CmdSessionsList, Tags: tags})
+	post(types.Request{Cmd: types.CmdSessionsInfo, Session: "nonexistent-session-id", Tags: tags})
+	post(types.Request{Cmd: types.CmdSessionsList}) // untagged
+
+	series := h.tagStats.Snapshot()
+	if len(series) != 1 {
+		t.Fatalf("Expected one series for the allowlisted key, got %+v", series)
+	}
+	if got := series[0]; got.Key != "app" || got.Value != "prowlarr" || got.RequestCount != 2 || got.ErrorCount != 1 {
+		t.Errorf("Unexpected tag series %+v", got)
+	}
+}
+
+func TestLogStreamEndpoint(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	// Disabled unless a broker is attached
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/logs/stream", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 when disabled, got %d", w.Code)
+	}
+
+	h.SetLogStream(logstream.NewBroker(1))
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/logs/stream?level=loud", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid level, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/logs/stream", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", w.Code)
+	}
+}
+
+func TestEventStreamEndpoint(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	// Disabled unless a bus is attached
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/admin/events", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 when disabled, got %d", w.Code)
+	}
+
+	bus := events.NewBus(1)
+	h.SetEventStream(bus)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/admin/events?types=solve.succeeded,nope", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown event type, got %d", w.Code)
+	}
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/admin/events?types=session.created&domain=example.com")
+	if err != nil {
+		t.Fatalf("GET /admin/events: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	// The headers are sent once subscribed, so these reach the stream
+	bus.Publish(context.Background(), events.Event{Type: events.SessionDestroyed, Domain: "example.com"})
+	bus.Publish(context.Background(), events.Event{Type: events.SessionCreated, Domain: "other.com"})
+	bus.Publish(context.Background(), events.Event{Type: events.SessionCreated, Domain: "example.com", Session: "s1"})
+
+	buf := make([]byte, 4096)
+	n, err := resp.Body.Read(buf)
+	if err != nil {
+		t.Fatalf("reading stream: %v", err)
+	}
+	got := string(buf[:n])
+	if !strings.HasPrefix(got, "event: session.created\ndata: {") || !strings.Contains(got, `"session":"s1"`) {
+		t.Errorf("stream = %q, want only the session.created event for example.com", got)
+	}
+}
+
 func TestRequestGetMissingURL(t *testing.T) {
 	h := mockHandler()
 	defer h.sessions.Close()
@@ -350,6 +736,200 @@ func TestRequestPostMissingPostData(t *testing.T) {
 	}
 }
 
+func TestRequestDuringQuietHours(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	schedule, err := quiethours.Parse("example.com=* 00:00-24:00")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	h.quietHours = schedule
+
+	body := types.Request{
+		Cmd: types.CmdRequestGet,
+		URL: "https://www.example.com/page",
+	}
+	bodyBytes, _ := json.Marshal(body)
+
+	req := httptest.NewRequest("POST", "/v1", bytes.NewReader(bodyBytes))
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	var resp types.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if resp.Status != types.StatusError {
+		t.Errorf("Expected error status, got %q", resp.Status)
+	}
+	if resp.Solution == nil || resp.Solution.ErrorCode == nil || *resp.Solution.ErrorCode != "QUIET_HOURS" {
+		t.Fatalf("Expected QUIET_HOURS error code, got %+v", resp.Solution)
+	}
+	if resp.Solution.NextAllowedAt == nil {
+		t.Fatal("Expected nextAllowedAt to be set")
+	}
+	if _, err := time.Parse(time.RFC3339, *resp.Solution.NextAllowedAt); err != nil {
+		t.Errorf("nextAllowedAt is not RFC 3339: %v", err)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header")
+	}
+}
+
+func TestRequestOutsideTargetPolicy(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	policy, err := targetpolicy.Load("example.com", "", "", "")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	h.targetPolicy = policy
+
+	body := types.Request{
+		Cmd: types.CmdRequestGet,
+		URL: "https://www.example.org/page",
+	}
+	bodyBytes, _ := json.Marshal(body)
+
+	req := httptest.NewRequest("POST", "/v1", bytes.NewReader(bodyBytes))
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	var resp types.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if resp.Status != types.StatusError {
+		t.Errorf("Expected error status, got %q", resp.Status)
+	}
+	if !strings.Contains(resp.Message, types.ErrTargetNotAllowed.Error()) {
+		t.Errorf("Unexpected error message: %q", resp.Message)
+	}
+}
+
+func TestAuditLogRecordsRequest(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditLog, err := audit.New(audit.Config{File: path, MaxSizeMB: 1})
+	if err != nil {
+		t.Fatalf("audit.New() error = %v", err)
+	}
+	h.SetAuditLog(auditLog)
+	h.targetPolicy = targetpolicy.DenyAll()
+
+	body := types.Request{
+		Cmd:   types.CmdRequestGet,
+		URL:   "https://www.example.com/page",
+		Proxy: &types.Proxy{URL: "http://proxy.example.net:8080", Username: "user", Password: "secret"},
+	}
+	bodyBytes, _ := json.Marshal(body)
+
+	req := httptest.NewRequest("POST", "/v1", bytes.NewReader(bodyBytes))
+	req.RemoteAddr = "203.0.113.7:41000"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if err := auditLog.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec audit.Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatalf("audit log %q is not one JSON record: %v", data, err)
+	}
+	if rec.Command != types.CmdRequestGet || rec.Domain != "www.example.com" || rec.ClientIP != "203.0.113.7" {
+		t.Errorf("record = %+v", rec)
+	}
+	if rec.Outcome != types.StatusError || !strings.Contains(rec.Error, types.ErrTargetNotAllowed.Error()) {
+		t.Errorf("outcome = %q, error = %q", rec.Outcome, rec.Error)
+	}
+	if strings.Contains(rec.Proxy, "secret") || !strings.Contains(rec.Proxy, "proxy.example.net") {
+		t.Errorf("proxy = %q, want the redacted request proxy", rec.Proxy)
+	}
+}
+
+func TestCommandSpan(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	h := mockHandler()
+	defer h.sessions.Close()
+	h.targetPolicy = targetpolicy.DenyAll()
+
+	bodyBytes, _ := json.Marshal(types.Request{Cmd: types.CmdRequestGet, URL: "https://www.example.com/page"})
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/v1", bytes.NewReader(bodyBytes)))
+
+	spans := rec.Ended()
+	if len(spans) != 1 || spans[0].Name() != "flaresolverr."+types.CmdRequestGet {
+		t.Fatalf("spans = %d, want one flaresolverr.%s span", len(spans), types.CmdRequestGet)
+	}
+	span := spans[0]
+	if span.Status().Code != codes.Error || !strings.Contains(span.Status().Description, types.ErrTargetNotAllowed.Error()) {
+		t.Errorf("status = %+v, want the API error", span.Status())
+	}
+	attrs := attribute.NewSet(span.Attributes()...)
+	if v, _ := attrs.Value("flaresolverr.domain"); v.AsString() != "www.example.com" {
+		t.Errorf("domain attribute = %q", v.AsString())
+	}
+}
+
+func TestFetchMethodCommandsMissingURL(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	for _, cmd := range []string{types.CmdRequestPut, types.CmdRequestPatch, types.CmdRequestDelete} {
+		t.Run(cmd, func(t *testing.T) {
+			body := types.Request{Cmd: cmd}
+			bodyBytes, _ := json.Marshal(body)
+
+			req := httptest.NewRequest("POST", "/api", bytes.NewReader(bodyBytes))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			var resp types.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+
+			if resp.Status != types.StatusError {
+				t.Errorf("Expected error status, got %q", resp.Status)
+			}
+
+			if resp.Message != "url is required" {
+				t.Errorf("Unexpected error message: %q", resp.Message)
+			}
+		})
+	}
+}
+
+func TestFetchMethod(t *testing.T) {
+	tests := map[string]string{
+		http.MethodGet:    "",
+		http.MethodPost:   "",
+		http.MethodPut:    http.MethodPut,
+		http.MethodPatch:  http.MethodPatch,
+		http.MethodDelete: http.MethodDelete,
+	}
+	for method, want := range tests {
+		if got := fetchMethod(method); got != want {
+			t.Errorf("fetchMethod(%q) = %q, want %q", method, got, want)
+		}
+	}
+}
+
 func TestContentTypeHeader(t *testing.T) {
 	h := mockHandler()
 	defer h.sessions.Close()
@@ -444,3 +1024,543 @@ func TestExtractChromeVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteCachedCopy(t *testing.T) {
+	archive := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/available" && r.URL.Query().Get("url") == "https://blocked.example.com/":
+			_, _ = w.Write([]byte(`{"archived_snapshots":{"closest":{"available":true,"status":"200","timestamp":"20240102030405"}}}`))
+		case r.URL.Path == "/available":
+			_, _ = w.Write([]byte(`{"archived_snapshots":{}}`))
+		case r.URL.Path == "/web/20240102030405id_/https://blocked.example.com/":
+			_, _ = w.Write([]byte("<html>archived</html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer archive.Close()
+
+	h := mockHandler()
+	defer h.sessions.Close()
+	h.webCache = webcache.New(webcache.Config{
+		Timeout:         5 * time.Second,
+		AvailabilityURL: archive.URL + "/available",
+		SnapshotBaseURL: archive.URL + "/web/",
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/v1", nil)
+	req := &types.Request{Cmd: types.CmdRequestGet, URL: "https://blocked.example.com/", AllowCacheFallback: true}
+	if !h.writeCachedCopy(w, r, req, time.Now()) {
+		t.Fatal("writeCachedCopy() = false, want cached copy written")
+	}
+	var resp types.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if resp.Status != types.StatusOK || resp.Solution == nil {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	sol := resp.Solution
+	if sol.Stale == nil || !*sol.Stale {
+		t.Error("solution not marked stale")
+	}
+	if sol.CachedAt == nil || *sol.CachedAt != "2024-01-02T03:04:05Z" {
+		t.Errorf("cachedAt = %v", sol.CachedAt)
+	}
+	if sol.Response != "<html>archived</html>" || sol.URL != req.URL {
+		t.Errorf("solution url/response = %q/%q", sol.URL, sol.Response)
+	}
+
+	// No archived copy: nothing is written so the access denied error goes out
+	w = httptest.NewRecorder()
+	req.URL = "https://never-archived.example.com/"
+	if h.writeCachedCopy(w, r, req, time.Now()) {
+		t.Error("writeCachedCopy() = true without an archived copy")
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("unexpected body written: %s", w.Body.String())
+	}
+
+	// Disabled
+	h.webCache = nil
+	if h.writeCachedCopy(httptest.NewRecorder(), r, req, time.Now()) {
+		t.Error("writeCachedCopy() = true with the fallback disabled")
+	}
+}
+
+// TestAcquirePriority verifies request priorities and the per-command default
+func TestAcquirePriority(t *testing.T) {
+	tests := []struct {
+		req  types.Request
+		want int
+	}{
+		{types.Request{Cmd: types.CmdRequestGet}, browser.PriorityNormal},
+		{types.Request{Cmd: types.CmdRequestGet, Priority: types.PriorityLow}, browser.PriorityLow},
+		{types.Request{Cmd: types.CmdSessionsCreate}, browser.PriorityHigh},
+		{types.Request{Cmd: types.CmdSessionsCreate, Priority: types.PriorityNormal}, browser.PriorityNormal},
+	}
+	for _, tt := range tests {
+		if got := acquirePriority(&tt.req); got != tt.want {
+			t.Errorf("acquirePriority(%s, %q) = %d, want %d", tt.req.Cmd, tt.req.Priority, got, tt.want)
+		}
+	}
+}
+
+// TestPoolAdminWithoutPool verifies the pool admin commands fail cleanly
+// when no browser pool is configured
+func TestPoolAdminWithoutPool(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	for _, body := range []types.Request{
+		{Cmd: types.CmdPoolDrain},
+		{Cmd: types.CmdPoolResize, PoolSize: 2},
+		{Cmd: types.CmdPoolRecycleAll},
+	} {
+		bodyBytes, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/v1", bytes.NewReader(bodyBytes)))
+		var resp types.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if resp.Status != types.StatusError || !strings.Contains(resp.Message, "not available") {
+			t.Errorf("%s: got %q %q, want pool not available error", body.Cmd, resp.Status, resp.Message)
+		}
+	}
+}
+
+func TestProxiesStatus(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	call := func() types.Response {
+		body, _ := json.Marshal(types.Request{Cmd: types.CmdProxiesStatus})
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/v1", bytes.NewReader(body)))
+		var resp types.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return resp
+	}
+
+	if resp := call(); resp.Status != types.StatusError || !strings.Contains(resp.Message, "PROXY_HEALTH_CHECK_ENABLED") {
+		t.Errorf("without health checks: got %q %q, want a not enabled error", resp.Status, resp.Message)
+	}
+
+	h.proxyHealth = proxyhealth.New(proxyhealth.Config{IPURL: "http://ip.test/"}, []*types.Proxy{
+		{URL: "http://10.0.0.1:8080", Username: "u", Password: "secret"},
+	})
+	resp := call()
+	if resp.Status != types.StatusOK || len(resp.Proxies) != 1 {
+		t.Fatalf("got %q %q with %d proxies, want one status", resp.Status, resp.Message, len(resp.Proxies))
+	}
+	if st := resp.Proxies[0]; !st.Alive || strings.Contains(st.Proxy, "secret") {
+		t.Errorf("status = %+v, want an alive proxy with the password redacted", st)
+	}
+}
+
+func TestDomainPrefs(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+	h.domainStats = stats.NewManager()
+	defer h.domainStats.Close()
+
+	call := func(req types.Request) types.Response {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/v1", bytes.NewReader(body)))
+		var resp types.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return resp
+	}
+
+	if resp := call(types.Request{Cmd: types.CmdDomainsGetPrefs, Domain: "example.com"}); resp.Status != types.StatusOK || resp.DomainPrefs != nil {
+		t.Errorf("get before set = %q %+v, want ok without prefs", resp.Status, resp.DomainPrefs)
+	}
+
+	resp := call(types.Request{Cmd: types.CmdDomainsSetPrefs, Domain: "Example.com", DomainPrefs: &types.DomainPrefs{
+		DisableMethods:    []string{"positional"},
+		PreferredProvider: "capsolver",
+		TimeoutOverrideMs: 90000,
+	}})
+	if resp.Status != types.StatusOK {
+		t.Fatalf("set = %q %q", resp.Status, resp.Message)
+	}
+	resp = call(types.Request{Cmd: types.CmdDomainsGetPrefs, Domain: "https://example.com/login"})
+	if got := resp.DomainPrefs; got == nil || got.PreferredProvider != "capsolver" || got.TimeoutOverrideMs != 90000 || len(got.DisableMethods) != 1 {
+		t.Errorf("get = %+v, want the prefs just set", got)
+	}
+
+	// The timeout applies to requests that set none, on subdomains too
+	req := &types.Request{Cmd: types.CmdRequestGet, URL: "https://www.example.com/"}
+	h.applyDomainPrefs(req)
+	if req.MaxTimeout != 90000 {
+		t.Errorf("MaxTimeout = %d, want 90000", req.MaxTimeout)
+	}
+	req = &types.Request{Cmd: types.CmdRequestGet, URL: "https://example.com/", MaxTimeout: 5000}
+	h.applyDomainPrefs(req)
+	if req.MaxTimeout != 5000 {
+		t.Errorf("MaxTimeout = %d, want the request's 5000", req.MaxTimeout)
+	}
+
+	for _, bad := range []*types.DomainPrefs{
+		{DisableMethods: []string{"teleport"}},
+		{PreferredProvider: "nope"},
+		{PollStrategy: "turbo"},
+	} {
+		if resp := call(types.Request{Cmd: types.CmdDomainsSetPrefs, Domain: "example.com", DomainPrefs: bad}); resp.Status != types.StatusError {
+			t.Errorf("set %+v = %q, want an error", bad, resp.Status)
+		}
+	}
+
+	if resp := call(types.Request{Cmd: types.CmdDomainsSetPrefs, Domain: "example.com"}); resp.Status != types.StatusOK {
+		t.Fatalf("clear = %q %q", resp.Status, resp.Message)
+	}
+	if prefs := h.domainStats.GetDomainSolverPrefs("example.com"); prefs != nil {
+		t.Errorf("prefs after clear = %+v, want nil", prefs)
+	}
+}
+
+func TestCaptchaStatus(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+	h.config.CaptchaLowBalance = 1
+
+	call := func() types.Response {
+		body, _ := json.Marshal(types.Request{Cmd: types.CmdCaptchaStatus})
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/v1", bytes.NewReader(body)))
+		var resp types.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return resp
+	}
+
+	if resp := call(); resp.Status != types.StatusError || !strings.Contains(resp.Message, "CAPTCHA_FALLBACK_ENABLED") {
+		t.Errorf("without providers: got %q %q, want a not enabled error", resp.Status, resp.Message)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"errorId":0,"balance":0.25}`))
+	}))
+	defer server.Close()
+	h.solver = solver.New(nil, "TestAgent/1.0")
+	h.solver.SetSolverChain(captcha.NewSolverChain(captcha.SolverChainConfig{
+		FallbackEnabled: true,
+		Metrics:         captcha.NewMetrics(),
+		Providers:       []captcha.CaptchaSolver{captcha.NewCapSolverSolver(captcha.CapSolverConfig{APIKey: "k", BaseURL: server.URL})},
+	}))
+
+	resp := call()
+	if resp.Status != types.StatusOK || len(resp.CaptchaProviders) != 1 {
+		t.Fatalf("got %q %q with %d providers, want one status", resp.Status, resp.Message, len(resp.CaptchaProviders))
+	}
+	if st := resp.CaptchaProviders[0]; st.Provider != "capsolver" || st.Balance == nil || *st.Balance != 0.25 || !st.LowBalance {
+		t.Errorf("status = %+v, want capsolver with a low 0.25 balance", st)
+	}
+	if resp.Message != "0 of 1 providers have balance" {
+		t.Errorf("Message = %q", resp.Message)
+	}
+}
+
+func TestEvaluateJsDisabled(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	body, _ := json.Marshal(types.Request{Cmd: types.CmdRequestGet, URL: "https://example.com/", EvaluateJs: "return document.title"})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/v1", bytes.NewReader(body)))
+
+	var resp types.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if resp.Status != types.StatusError || !strings.Contains(resp.Message, "EVALUATE_JS_ENABLED") {
+		t.Errorf("Expected evaluateJs to be refused, got status %q message %q", resp.Status, resp.Message)
+	}
+}
+
+func TestTurnstileSolveErrors(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	schedule, err := quiethours.Parse("quiet.example.com=* 00:00-24:00")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	h.quietHours = schedule
+
+	tests := []struct {
+		name    string
+		body    types.Request
+		wantMsg string
+	}{
+		{
+			name:    "missing sitekey",
+			body:    types.Request{Cmd: types.CmdTurnstileSolve, URL: "https://example.com/"},
+			wantMsg: "siteKey are required",
+		},
+		{
+			name:    "quiet hours",
+			body:    types.Request{Cmd: types.CmdTurnstileSolve, URL: "https://quiet.example.com/", SiteKey: "0x4AAAAAAAtest"},
+			wantMsg: "quiet",
+		},
+		{
+			name:    "private url",
+			body:    types.Request{Cmd: types.CmdTurnstileSolve, URL: "http://127.0.0.1/", SiteKey: "0x4AAAAAAAtest"},
+			wantMsg: "Invalid URL",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodyBytes, _ := json.Marshal(tt.body)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("POST", "/v1", bytes.NewReader(bodyBytes)))
+			var resp types.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if resp.Status != types.StatusError || !strings.Contains(strings.ToLower(resp.Message), strings.ToLower(tt.wantMsg)) {
+				t.Errorf("got %q %q, want error containing %q", resp.Status, resp.Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestResolveRequestProxy(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	pool, err := solver.NewProxyPool("residential", solver.PoolRoundRobin, []*types.Proxy{{URL: "http://10.0.0.1:8080", Username: "u", Password: "p"}})
+	if err != nil {
+		t.Fatalf("NewProxyPool() error = %v", err)
+	}
+	h.proxyPools = map[string]*solver.ProxyPool{"residential": pool}
+
+	req := &types.Request{URL: "https://example.com/", Proxy: &types.Proxy{Pool: "residential", Provider: "oxylabs"}}
+	got, err := h.resolveRequestProxy(req)
+	if err != nil || got != pool {
+		t.Fatalf("resolveRequestProxy() = %v, %v; want the residential pool", got, err)
+	}
+	if req.Proxy.URL != "http://10.0.0.1:8080" || req.Proxy.Username != "u" || req.Proxy.Pool != "" || req.Proxy.Provider != "oxylabs" {
+		t.Errorf("req.Proxy = %+v, want the pool's proxy with the requested provider", req.Proxy)
+	}
+
+	req = &types.Request{URL: "https://example.com/", Proxy: &types.Proxy{Pool: "missing"}}
+	if _, err := h.resolveRequestProxy(req); err == nil || !strings.Contains(err.Error(), "unknown proxy pool") {
+		t.Errorf("resolveRequestProxy() error = %v, want unknown proxy pool", err)
+	}
+
+	direct := &types.Request{URL: "https://example.com/", Proxy: &types.Proxy{URL: "http://10.0.0.9:8080"}}
+	if got, err := h.resolveRequestProxy(direct); got != nil || err != nil || direct.Proxy.URL != "http://10.0.0.9:8080" {
+		t.Errorf("resolveRequestProxy() with a raw URL = %v, %v, %+v", got, err, direct.Proxy)
+	}
+
+	// Requests without a proxy follow PROXY_ROUTES
+	h.proxyRoutes, err = solver.ParseProxyRoutes("*.example.com=pool:residential,example.org=http://10.0.0.5:3128")
+	if err != nil {
+		t.Fatalf("ParseProxyRoutes() error = %v", err)
+	}
+	routed := &types.Request{URL: "https://shop.example.com/"}
+	if got, err := h.resolveRequestProxy(routed); got != pool || err != nil || routed.Proxy.URL != "http://10.0.0.1:8080" {
+		t.Errorf("routed to pool = %v, %v, %+v", got, err, routed.Proxy)
+	}
+	routed = &types.Request{URL: "https://example.org/"}
+	if got, err := h.resolveRequestProxy(routed); got != nil || err != nil || routed.Proxy == nil || routed.Proxy.URL != "http://10.0.0.5:3128" {
+		t.Errorf("routed to proxy = %v, %v, %+v", got, err, routed.Proxy)
+	}
+	unrouted := &types.Request{URL: "https://example.net/"}
+	if _, err := h.resolveRequestProxy(unrouted); err != nil || unrouted.Proxy != nil {
+		t.Errorf("unrouted request got proxy %+v, %v", unrouted.Proxy, err)
+	}
+	if _, err := h.resolveRequestProxy(direct); err != nil || direct.Proxy.URL != "http://10.0.0.9:8080" {
+		t.Errorf("explicit proxy overridden by route: %+v", direct.Proxy)
+	}
+}
+
+func TestApplyDomainOverride(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	var err error
+	h.domainOverrides, err = solver.ParseDomainOverrides([]byte(`
+- domains: [example.com]
+  timeout: 90s
+  disableMedia: true
+  turnstileMethods: [keyboard]
+  tabsTillVerify: 14
+  proxy: pool:residential
+  profile: desktop-mac
+  userAgent: Override/1.0
+- domains: [slow.example.org]
+  timeout: 1h
+`))
+	if err != nil {
+		t.Fatalf("ParseDomainOverrides() error = %v", err)
+	}
+
+	req := &types.Request{Cmd: types.CmdRequestGet, URL: "https://www.example.com/"}
+	methods := h.applyDomainOverride(context.Background(), req)
+	if len(methods) != 1 || methods[0] != "keyboard" {
+		t.Errorf("turnstile methods = %v, want [keyboard]", methods)
+	}
+	if req.MaxTimeout != 90000 || !req.DisableMedia || req.TabsTillVerify != 14 || req.Profile != "desktop-mac" || req.UserAgent != "Override/1.0" {
+		t.Errorf("request = %+v, want every override applied", req)
+	}
+	if req.Proxy == nil || req.Proxy.Pool != "residential" {
+		t.Errorf("req.Proxy = %+v, want pool residential", req.Proxy)
+	}
+
+	// Settings the request makes win
+	req = &types.Request{
+		Cmd:            types.CmdRequestGet,
+		URL:            "https://example.com/",
+		Session:        "s1",
+		MaxTimeout:     5000,
+		TabsTillVerify: 3,
+		Proxy:          &types.Proxy{URL: "http://10.0.0.9:8080"},
+		UserAgent:      "Client/1.0",
+	}
+	h.applyDomainOverride(context.Background(), req)
+	if req.MaxTimeout != 5000 || req.TabsTillVerify != 3 || req.Proxy.URL != "http://10.0.0.9:8080" || req.UserAgent != "Client/1.0" {
+		t.Errorf("request settings overridden: %+v", req)
+	}
+	if req.Profile != "" {
+		t.Errorf("profile %q applied to a session request", req.Profile)
+	}
+
+	// The timeout stays within MAX_TIMEOUT
+	req = &types.Request{Cmd: types.CmdRequestGet, URL: "https://slow.example.org/"}
+	h.applyDomainOverride(context.Background(), req)
+	if req.MaxTimeout != int(h.config.MaxTimeout.Milliseconds()) {
+		t.Errorf("MaxTimeout = %d, want MAX_TIMEOUT", req.MaxTimeout)
+	}
+
+	req = &types.Request{Cmd: types.CmdRequestGet, URL: "https://example.net/"}
+	if methods := h.applyDomainOverride(context.Background(), req); methods != nil || req.MaxTimeout != 0 || req.Proxy != nil {
+		t.Errorf("unmatched request changed: %+v, %v", req, methods)
+	}
+}
+
+func TestFailoverProxy(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+	h.config.ProxyFailoverEnabled = true
+	h.domainStats = stats.NewManager()
+	defer h.domainStats.Close()
+
+	proxies := []*types.Proxy{{URL: "http://10.0.0.1:8080"}, {URL: "http://10.0.0.2:8080"}, {URL: "http://10.0.0.3:8080"}}
+	pool, err := solver.NewProxyPool("residential", solver.PoolRoundRobin, proxies)
+	if err != nil {
+		t.Fatalf("NewProxyPool() error = %v", err)
+	}
+
+	req := &types.Request{URL: "https://www.example.com/", Proxy: &types.Proxy{URL: "http://10.0.0.1:8080"}}
+	next := h.failoverProxy(req, pool, req.Proxy)
+	if next == nil || next.URL == "http://10.0.0.1:8080" {
+		t.Fatalf("failoverProxy() = %v, want another pool proxy", next)
+	}
+	if !h.domainStats.ProxyBannedWithin("www.example.com", "http://10.0.0.1:8080", time.Minute) {
+		t.Error("refused proxy not recorded as banned for the domain")
+	}
+
+	// A proxy the domain refused recently is passed over on the next failover
+	again := h.failoverProxy(req, pool, next)
+	if again == nil || again.URL == "http://10.0.0.1:8080" || again.URL == next.URL {
+		t.Errorf("failoverProxy() = %v, want the one proxy not yet refused", again)
+	}
+
+	// PROXY_LIST egress, when the request brought no proxy
+	h.egressPool = solver.NewEgressPool(proxies, solver.EgressStickyDomain)
+	direct := &types.Request{URL: "https://other.example.org/"}
+	refused := h.egressPool.Select("example.org")
+	if got := h.failoverProxy(direct, nil, refused); got == nil || got.URL == refused.URL {
+		t.Errorf("egress failoverProxy() = %v, want another egress", got)
+	}
+
+	// A proxy of the request's own has nothing to fail over to
+	if got := h.failoverProxy(&types.Request{URL: "https://example.net/", Proxy: proxies[0]}, nil, proxies[0]); got != nil {
+		t.Errorf("failoverProxy() = %v for a request's own proxy, want nil", got)
+	}
+
+	h.config.ProxyFailoverEnabled = false
+	if got := h.failoverProxy(req, pool, req.Proxy); got != nil {
+		t.Errorf("failoverProxy() = %v with failover disabled, want nil", got)
+	}
+}
+
+func TestDeniedEgress(t *testing.T) {
+	if !deniedEgress(nil, types.NewAccessDeniedError("https://example.com/")) {
+		t.Error("access_denied error not treated as a refused egress")
+	}
+	if deniedEgress(nil, types.ErrChallengeTimeout) {
+		t.Error("timeout treated as a refused egress")
+	}
+	blocked := &solver.Result{StatusCode: 403, HTML: "<h1>Access denied</h1><span>Error code: 1020</span>"}
+	if !deniedEgress(blocked, nil) {
+		t.Error("1020 block page not treated as a refused egress")
+	}
+	if deniedEgress(&solver.Result{StatusCode: 200, HTML: "<html>ok</html>"}, nil) {
+		t.Error("successful page treated as a refused egress")
+	}
+}
+
+func TestWriteHistogramLabeled(t *testing.T) {
+	var b strings.Builder
+	writeHistogramLabeled(&b, "solve_seconds", "Solve duration", `domain="example.com"`, []float64{1, 5}, []int64{2, 0, 1}, 9.5)
+	want := `# HELP solve_seconds Solve duration
+# TYPE solve_seconds histogram
+solve_seconds_bucket{domain="example.com",le="1"} 2
+solve_seconds_bucket{domain="example.com",le="5"} 2
+solve_seconds_bucket{domain="example.com",le="+Inf"} 3
+solve_seconds_sum{domain="example.com"} 9.5
+solve_seconds_count{domain="example.com"} 3
+`
+	if b.String() != want {
+		t.Errorf("histogram =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestWriteBrowserMemory(t *testing.T) {
+	var b strings.Builder
+	writeBrowserMemory(&b, nil)
+	if b.Len() != 0 {
+		t.Fatalf("output without a sample = %q, want none", b.String())
+	}
+
+	writeBrowserMemory(&b, &browser.MemorySample{
+		Browsers:   []browser.BrowserMemory{{PID: 41, RSSBytes: 300}, {PID: 42, RSSBytes: 200}},
+		TotalBytes: 500,
+	})
+	out := b.String()
+	for _, want := range []string{
+		"flaresolverr_browser_memory_bytes 500\n",
+		`flaresolverr_browser_rss_bytes{pid="41"} 300` + "\n",
+		`flaresolverr_browser_rss_bytes{pid="42"} 200` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestResponseCarriesRequestID(t *testing.T) {
+	h := mockHandler()
+	w := httptest.NewRecorder()
+	w.Header().Set(middleware.RequestIDHeader, "caller-42")
+	h.writeError(w, "Challenge not solved", time.Now())
+
+	var resp types.Response
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.RequestID != "caller-42" {
+		t.Errorf("requestId = %q, want the X-Request-ID header", resp.RequestID)
+	}
+}
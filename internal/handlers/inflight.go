@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/middleware"
+	"github.com/Rorqualx/flaresolverr-go/internal/stats"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// InflightSolve describes a command that is driving a browser right now.
+type InflightSolve struct {
+	RequestID string `json:"requestId,omitempty"`
+	Cmd       string `json:"cmd"`
+	Domain    string `json:"domain,omitempty"`
+	Session   string `json:"session,omitempty"`
+	StartedAt int64  `json:"startedAt"` // Unix milliseconds
+}
+
+// inflightSolves tracks the running solves for the admin dashboard. The
+// zero value is ready to use.
+type inflightSolves struct {
+	mu     sync.Mutex
+	next   uint64
+	solves map[uint64]InflightSolve
+}
+
+// isSolveCommand reports whether cmd navigates a browser to a target.
+func isSolveCommand(cmd string) bool {
+	switch cmd {
+	case types.CmdRequestGet, types.CmdRequestPost, types.CmdRequestPut, types.CmdRequestPatch,
+		types.CmdRequestDelete, types.CmdSessionsTouch, types.CmdTurnstileSolve:
+		return true
+	}
+	return false
+}
+
+// start records a solve and returns the function that removes it.
+func (f *inflightSolves) start(s InflightSolve) func() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.solves == nil {
+		f.solves = make(map[uint64]InflightSolve)
+	}
+	f.next++
+	id := f.next
+	f.solves[id] = s
+	return func() {
+		f.mu.Lock()
+		delete(f.solves, id)
+		f.mu.Unlock()
+	}
+}
+
+// snapshot returns the running solves, oldest first.
+func (f *inflightSolves) snapshot() []InflightSolve {
+	f.mu.Lock()
+	out := make([]InflightSolve, 0, len(f.solves))
+	for _, s := range f.solves {
+		out = append(out, s)
+	}
+	f.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt < out[j].StartedAt })
+	return out
+}
+
+// trackSolve records req as in flight until the returned function is called.
+// Commands that do not solve return a no-op.
+func (h *Handler) trackSolve(r *http.Request, req *types.Request, startTime time.Time) func() {
+	if !isSolveCommand(req.Cmd) {
+		return func() {}
+	}
+	return h.inflight.start(InflightSolve{
+		RequestID: middleware.RequestIDFromContext(r.Context()),
+		Cmd:       req.Cmd,
+		Domain:    stats.ExtractDomain(req.URL),
+		Session:   req.Session,
+		StartedAt: startTime.UnixMilli(),
+	})
+}
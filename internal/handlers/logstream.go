@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/logstream"
+)
+
+// logStreamKeepalive is how often an SSE comment is sent on an idle stream so
+// proxies and clients don't time the connection out.
+const logStreamKeepalive = 15 * time.Second
+
+// SetLogStream enables GET /logs/stream backed by the given broker.
+func (h *Handler) SetLogStream(b *logstream.Broker) {
+	h.logStream = b
+}
+
+// handleLogStream tails structured logs as Server-Sent Events, one JSON log
+// line per event. Query parameters requestId, session and domain narrow the
+// stream; level sets the minimum level (default: everything the server logs).
+func (h *Handler) handleLogStream(w http.ResponseWriter, r *http.Request, startTime time.Time) {
+	if h.logStream == nil {
+		h.writeErrorWithStatus(w, http.StatusNotFound, "Log streaming is not enabled", startTime)
+		return
+	}
+	if r.Method != http.MethodGet {
+		h.writeErrorWithStatus(w, http.StatusMethodNotAllowed, "Method not allowed", startTime)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := logstream.Filter{
+		RequestID: q.Get("requestId"),
+		Session:   q.Get("session"),
+		Domain:    q.Get("domain"),
+		Level:     zerolog.TraceLevel,
+	}
+	if lvl := q.Get("level"); lvl != "" {
+		parsed, err := zerolog.ParseLevel(lvl)
+		if err != nil || parsed == zerolog.NoLevel {
+			h.writeErrorWithStatus(w, http.StatusBadRequest, "Invalid level", startTime)
+			return
+		}
+		filter.Level = parsed
+	}
+
+	sub, err := h.logStream.Subscribe(filter)
+	if err != nil {
+		h.writeErrorWithStatus(w, http.StatusServiceUnavailable, "Too many log stream subscribers", startTime)
+		return
+	}
+	defer h.logStream.Unsubscribe(sub)
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Ctx(r.Context()).Debug().Err(err).Msg("Could not clear write deadline for log stream")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		log.Ctx(r.Context()).Warn().Err(err).Msg("Log stream requires a flushable response writer")
+		return
+	}
+
+	log.Ctx(r.Context()).Info().
+		Str("request_filter", filter.RequestID).
+		Str("session_filter", filter.Session).
+		Str("domain_filter", filter.Domain).
+		Msg("Log stream subscriber connected")
+
+	ticker := time.NewTicker(logStreamKeepalive)
+	defer ticker.Stop()
+
+	var reportedDrops int64
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case line, ok := <-sub.Lines():
+			if !ok {
+				return
+			}
+			if dropped := sub.Dropped(); dropped > reportedDrops {
+				// Tell the client it missed lines instead of silently skipping them
+				if _, err := fmt.Fprintf(w, "event: dropped\ndata: %d\n\n", dropped-reportedDrops); err != nil {
+					return
+				}
+				reportedDrops = dropped
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", line); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
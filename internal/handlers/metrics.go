@@ -4,8 +4,12 @@ import (
 	"fmt"
 	"net/http"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/browser"
+	"github.com/Rorqualx/flaresolverr-go/internal/stats"
 )
 
 // handleMetrics serves Prometheus-compatible metrics at /metrics.
@@ -24,12 +28,23 @@ func (h *Handler) handleMetrics(w http.ResponseWriter) {
 	writeCounter(&b, "flaresolverr_pool_released_total", "Total browsers released to pool", float64(poolStats.Released))
 	writeCounter(&b, "flaresolverr_pool_recycled_total", "Total browsers recycled", float64(poolStats.Recycled))
 	writeCounter(&b, "flaresolverr_pool_errors_total", "Total pool errors", float64(poolStats.Errors))
+	writeBrowserMemory(&b, h.pool.MemoryUsage())
 
 	// Session metrics
 	if h.sessions != nil {
 		writeGauge(&b, "flaresolverr_sessions_active", "Active browser sessions", float64(h.sessions.Count()))
 	}
 
+	// Request/response buffer pools
+	writeGauge(&b, "flaresolverr_buffer_pool_max_buffer_bytes", "Largest buffer capacity kept for reuse", float64(maxRetainedBufferCap.Load()))
+	for _, ps := range bufferPoolStats() {
+		labels := fmt.Sprintf(`pool="%s"`, ps.Name) //nolint:gocritic // Prometheus label format requires literal quotes, not %q
+		writeGaugeLabeled(&b, "flaresolverr_buffer_pool_in_use", "Buffers currently checked out of the pool", labels, float64(ps.InUse))
+		writeCounterLabeled(&b, "flaresolverr_buffer_pool_hits_total", "Buffers reused from the pool", labels, float64(ps.Gets-ps.Misses))
+		writeCounterLabeled(&b, "flaresolverr_buffer_pool_misses_total", "Buffers allocated because the pool was empty", labels, float64(ps.Misses))
+		writeCounterLabeled(&b, "flaresolverr_buffer_pool_discards_total", "Oversized buffers dropped instead of returned", labels, float64(ps.Discarded))
+	}
+
 	// Go runtime metrics
 	var mem runtime.MemStats
 	runtime.ReadMemStats(&mem)
@@ -41,26 +56,126 @@ func (h *Handler) handleMetrics(w http.ResponseWriter) {
 	// Uptime
 	writeGauge(&b, "flaresolverr_uptime_seconds", "Seconds since server start", time.Since(serverStartTime).Seconds())
 
-	// Domain stats
-	if h.domainStats != nil {
-		allStats := h.domainStats.AllStats()
-		for domain, ds := range allStats {
-			labels := fmt.Sprintf(`domain="%s"`, escapeProm(domain)) //nolint:gocritic // Prometheus label format requires literal quotes, not %q
-			writeCounterLabeled(&b, "flaresolverr_domain_requests_total", "Total requests per domain", labels, float64(ds.RequestCount))
-			writeCounterLabeled(&b, "flaresolverr_domain_successes_total", "Successful requests per domain", labels, float64(ds.SuccessCount))
-			writeCounterLabeled(&b, "flaresolverr_domain_errors_total", "Failed requests per domain", labels, float64(ds.ErrorCount))
-			writeCounterLabeled(&b, "flaresolverr_domain_rate_limits_total", "Rate-limited responses per domain", labels, float64(ds.RateLimitCount))
-			writeGaugeLabeled(&b, "flaresolverr_domain_avg_latency_ms", "Average latency per domain", labels, float64(ds.AvgLatencyMs))
-			writeGaugeLabeled(&b, "flaresolverr_domain_suggested_delay_ms", "Suggested delay per domain", labels, float64(ds.SuggestedDelayMs))
+	// Per-domain series for the busiest domains, the rest under "other"
+	for _, ds := range h.domainStats.DomainMetrics().Snapshot() {
+		labels := fmt.Sprintf(`domain="%s"`, escapeProm(ds.Domain)) //nolint:gocritic // Prometheus label format requires literal quotes, not %q
+		writeCounterLabeled(&b, "flaresolverr_domain_requests_total", "Total solves per domain", labels, float64(ds.Solves))
+		writeCounterLabeled(&b, "flaresolverr_domain_successes_total", "Successful solves per domain", labels, float64(ds.Successes))
+		writeCounterLabeled(&b, "flaresolverr_domain_errors_total", "Failed solves per domain", labels, float64(ds.Solves-ds.Successes))
+		writeCounterLabeled(&b, "flaresolverr_domain_rate_limits_total", "Rate-limited responses per domain", labels, float64(ds.RateLimits))
+		if ds.Solves > 0 {
+			writeGaugeLabeled(&b, "flaresolverr_domain_success_ratio", "Share of solves per domain that succeeded", labels, float64(ds.Successes)/float64(ds.Solves))
+		}
+		for _, challenge := range sortedKeys(ds.Challenges) {
+			challengeLabels := fmt.Sprintf(`domain="%s",type="%s"`, escapeProm(ds.Domain), escapeProm(challenge)) //nolint:gocritic // Prometheus label format requires literal quotes, not %q
+			writeCounterLabeled(&b, "flaresolverr_domain_challenges_total", "Solves per domain that met a challenge, by first challenge type", challengeLabels, float64(ds.Challenges[challenge]))
+		}
+		writeHistogramLabeled(&b, "flaresolverr_domain_solve_duration_seconds", "Solve duration per domain", labels, stats.SolveDurationBuckets, ds.Buckets, ds.DurationSum)
+		if ds.Domain == stats.DomainOverflow {
+			continue
+		}
+		if st := h.domainStats.Get(ds.Domain); st != nil {
+			js := st.ToJSON(h.domainStats.DefaultMinDelayMs, h.domainStats.DefaultMaxDelayMs)
+			writeGaugeLabeled(&b, "flaresolverr_domain_avg_latency_ms", "Average latency per domain", labels, float64(js.AvgLatencyMs))
+			writeGaugeLabeled(&b, "flaresolverr_domain_suggested_delay_ms", "Suggested delay per domain", labels, float64(js.SuggestedDelayMs))
+		}
+	}
+
+	// Named proxy pools, per proxy
+	for name, pool := range h.currentProxyPools() {
+		for _, ps := range pool.Stats().Proxies {
+			labels := fmt.Sprintf(`pool="%s",proxy="%s"`, escapeProm(name), escapeProm(ps.Proxy)) //nolint:gocritic // Prometheus label format requires literal quotes, not %q
+			writeCounterLabeled(&b, "flaresolverr_proxy_requests_total", "Total requests per pool proxy", labels, float64(ps.RequestCount))
+			writeCounterLabeled(&b, "flaresolverr_proxy_errors_total", "Failed requests per pool proxy", labels, float64(ps.ErrorCount))
+			writeGaugeLabeled(&b, "flaresolverr_proxy_avg_latency_ms", "Average latency per pool proxy", labels, float64(ps.AvgLatencyMs))
+		}
+	}
+
+	// Proxy health checks, per proxy
+	for _, st := range h.proxyHealth.Statuses() {
+		labels := fmt.Sprintf(`proxy="%s"`, escapeProm(st.Proxy)) //nolint:gocritic // Prometheus label format requires literal quotes, not %q
+		up := 0.0
+		if st.Alive {
+			up = 1
+		}
+		writeGaugeLabeled(&b, "flaresolverr_proxy_up", "Whether the proxy passes health checks (1) or is out of rotation (0)", labels, up)
+		writeGaugeLabeled(&b, "flaresolverr_proxy_probe_latency_ms", "Latency of the last successful proxy health check", labels, float64(st.LatencyMs))
+		writeGaugeLabeled(&b, "flaresolverr_proxy_consecutive_failures", "Consecutive failed proxy health checks", labels, float64(st.ConsecutiveFailures))
+		repLabels := fmt.Sprintf(`proxy="%s",reputation="%s"`, escapeProm(st.Proxy), st.Reputation) //nolint:gocritic // Prometheus label format requires literal quotes, not %q
+		writeGaugeLabeled(&b, "flaresolverr_proxy_reputation", "Cloudflare reputation of the proxy's egress IP from the canary check", repLabels, 1)
+	}
+
+	// Challenges by type
+	if h.solver != nil {
+		for _, cs := range h.solver.ChallengeStats() {
+			labels := fmt.Sprintf(`type="%s"`, cs.Type) //nolint:gocritic // Prometheus label format requires literal quotes, not %q
+			writeCounterLabeled(&b, "flaresolverr_challenges_detected_total", "Solves that met a challenge, by first challenge type", labels, float64(cs.Detected))
+			writeCounterLabeled(&b, "flaresolverr_challenges_solved_total", "Solves that cleared a challenge, by first challenge type", labels, float64(cs.Solved))
+		}
+	}
+
+	// Turnstile bypass methods across all domains
+	for _, mt := range h.domainStats.TurnstileMethodTotals() {
+		labels := fmt.Sprintf(`method="%s"`, escapeProm(mt.Method)) //nolint:gocritic // Prometheus label format requires literal quotes, not %q
+		writeCounterLabeled(&b, "flaresolverr_turnstile_method_attempts_total", "Turnstile solve attempts by method", labels, float64(mt.Attempts))
+		writeCounterLabeled(&b, "flaresolverr_turnstile_method_successes_total", "Turnstile solve attempts by method that cleared the widget", labels, float64(mt.Successes))
+	}
+
+	// External CAPTCHA solver providers
+	if h.solver != nil {
+		providers := h.solver.CaptchaProviderStats()
+		names := make([]string, 0, len(providers))
+		for name := range providers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			ps := providers[name]
+			labels := fmt.Sprintf(`provider="%s"`, escapeProm(name)) //nolint:gocritic // Prometheus label format requires literal quotes, not %q
+			writeCounterLabeled(&b, "flaresolverr_captcha_provider_attempts_total", "External solver attempts by provider", labels, float64(ps.Attempts))
+			writeCounterLabeled(&b, "flaresolverr_captcha_provider_successes_total", "Successful external solves by provider", labels, float64(ps.Successes))
+			writeCounterLabeled(&b, "flaresolverr_captcha_provider_failures_total", "Failed external solves by provider", labels, float64(ps.Failures))
+			writeCounterLabeled(&b, "flaresolverr_captcha_provider_cost_usd_total", "External solver spend in USD by provider", labels, ps.TotalCost)
+			writeCounterLabeled(&b, "flaresolverr_captcha_provider_solve_seconds_total", "Time spent in external solves by provider", labels, float64(ps.TotalTimeMs)/1000)
+		}
+		for _, bs := range h.solver.CaptchaBudgetStatus() {
+			scope := bs.Provider
+			if scope == "" {
+				scope = "global"
+			}
+			labels := fmt.Sprintf(`scope="%s",period="%s"`, escapeProm(scope), bs.Period) //nolint:gocritic // Prometheus label format requires literal quotes, not %q
+			writeGaugeLabeled(&b, "flaresolverr_captcha_budget_remaining_usd", "External solver spend budget left in the current period", labels, bs.Remaining)
+			writeGaugeLabeled(&b, "flaresolverr_captcha_budget_spent_usd", "External solver spend in the current budget period", labels, bs.Spent)
 		}
 	}
 
+	// Request tag attribution (allowlisted keys, bounded values)
+	for _, ts := range h.tagStats.Snapshot() {
+		labels := fmt.Sprintf(`key="%s",value="%s"`, escapeProm(ts.Key), escapeProm(ts.Value)) //nolint:gocritic // Prometheus label format requires literal quotes, not %q
+		writeCounterLabeled(&b, "flaresolverr_tag_requests_total", "Total requests per request tag", labels, float64(ts.RequestCount))
+		writeCounterLabeled(&b, "flaresolverr_tag_errors_total", "Failed requests per request tag", labels, float64(ts.ErrorCount))
+		writeCounterLabeled(&b, "flaresolverr_tag_latency_ms_total", "Cumulative latency per request tag", labels, float64(ts.TotalLatencyMs))
+	}
+
 	w.Write([]byte(b.String()))
 }
 
 // startTime tracks when the server started for uptime calculation
 var serverStartTime = time.Now()
 
+// writeBrowserMemory writes the resident memory of each launched browser
+// process tree and their total. Nothing is written without a sample.
+func writeBrowserMemory(b *strings.Builder, sample *browser.MemorySample) {
+	if sample == nil {
+		return
+	}
+	writeGauge(b, "flaresolverr_browser_memory_bytes", "Resident memory of all launched browsers including child processes", float64(sample.TotalBytes))
+	for _, bm := range sample.Browsers {
+		labels := fmt.Sprintf(`pid="%d"`, bm.PID) //nolint:gocritic // Prometheus label format requires literal quotes, not %q
+		writeGaugeLabeled(b, "flaresolverr_browser_rss_bytes", "Resident memory of one browser including child processes", labels, float64(bm.RSSBytes))
+	}
+}
+
 func writeGauge(b *strings.Builder, name, help string, value float64) {
 	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
 }
@@ -77,6 +192,30 @@ func writeCounterLabeled(b *strings.Builder, name, help, labels string, value fl
 	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n%s{%s} %g\n", name, help, name, name, labels, value)
 }
 
+// writeHistogramLabeled writes a histogram from per-bucket (not cumulative)
+// counts, whose last entry counts the observations above every bound.
+func writeHistogramLabeled(b *strings.Builder, name, help, labels string, bounds []float64, counts []int64, sum float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	var cumulative int64
+	for i, bound := range bounds {
+		cumulative += counts[i]
+		fmt.Fprintf(b, "%s_bucket{%s,le=\"%g\"} %d\n", name, labels, bound, cumulative)
+	}
+	cumulative += counts[len(bounds)]
+	fmt.Fprintf(b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, cumulative)
+	fmt.Fprintf(b, "%s_sum{%s} %g\n%s_count{%s} %d\n", name, labels, sum, name, labels, cumulative)
+}
+
+// sortedKeys returns the keys of m in order, for stable metric output.
+func sortedKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func escapeProm(s string) string {
 	s = strings.ReplaceAll(s, `\`, `\\`)
 	s = strings.ReplaceAll(s, `"`, `\"`)
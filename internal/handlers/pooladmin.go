@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+	"github.com/Rorqualx/flaresolverr-go/pkg/version"
+)
+
+// handlePoolDrain handles the pool.drain command: idle browsers are closed
+// and new acquisitions fail fast until pool.resize is called. Sessions keep
+// their browsers.
+func (h *Handler) handlePoolDrain(w http.ResponseWriter, startTime time.Time) {
+	if h.pool == nil {
+		h.writeError(w, "Browser pool is not available", startTime)
+		return
+	}
+	previous, err := h.pool.Drain()
+	if err != nil {
+		h.writeError(w, fmt.Sprintf("Failed to drain pool: %v", err), startTime)
+		return
+	}
+	log.Info().Int("previous_size", previous).Msg("Browser pool drained")
+	h.writePoolAdminResponse(w, fmt.Sprintf("Pool drained from %d browsers", previous), startTime)
+}
+
+// handlePoolResize handles the pool.resize command.
+func (h *Handler) handlePoolResize(w http.ResponseWriter, ctx context.Context, req *types.Request, startTime time.Time) {
+	if h.pool == nil {
+		h.writeError(w, "Browser pool is not available", startTime)
+		return
+	}
+	previous, err := h.pool.Resize(ctx, req.PoolSize)
+	if err != nil {
+		h.writeError(w, fmt.Sprintf("Failed to resize pool: %v", err), startTime)
+		return
+	}
+	log.Ctx(ctx).Info().Int("previous_size", previous).Int("size", req.PoolSize).Msg("Browser pool resized")
+	h.writePoolAdminResponse(w, fmt.Sprintf("Pool resized from %d to %d browsers", previous, req.PoolSize), startTime)
+}
+
+// handlePoolRecycleAll handles the pool.recycleAll command. Replacements
+// are swapped in as each browser is next acquired or released.
+func (h *Handler) handlePoolRecycleAll(w http.ResponseWriter, startTime time.Time) {
+	if h.pool == nil {
+		h.writeError(w, "Browser pool is not available", startTime)
+		return
+	}
+	n, err := h.pool.ReplaceAll()
+	if err != nil {
+		h.writeError(w, fmt.Sprintf("Failed to recycle pool: %v", err), startTime)
+		return
+	}
+	log.Info().Int("browsers", n).Msg("Browser pool recycle scheduled")
+	h.writePoolAdminResponse(w, fmt.Sprintf("Recycling %d browsers", n), startTime)
+}
+
+func (h *Handler) writePoolAdminResponse(w http.ResponseWriter, message string, startTime time.Time) {
+	resp := types.Response{
+		Status:    types.StatusOK,
+		Message:   message,
+		StartTime: startTime.UnixMilli(),
+		EndTime:   time.Now().UnixMilli(),
+		Version:   version.Full(),
+	}
+	h.writeJSONResponse(w, http.StatusOK, resp)
+}
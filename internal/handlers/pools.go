@@ -4,6 +4,7 @@ package handlers
 import (
 	"bytes"
 	"sync"
+	"sync/atomic"
 
 	"github.com/rs/zerolog/log"
 )
@@ -14,67 +15,126 @@ import (
 // would otherwise waste memory indefinitely.
 const maxPoolBufferCap = 64 * 1024 // 64KB
 
-// jsonBufferPool provides reusable byte buffers for JSON decoding.
-// This reduces GC pressure by avoiding frequent allocation of buffers.
-var jsonBufferPool = sync.Pool{
-	New: func() interface{} {
-		// Pre-allocate 4KB buffer, typical JSON request size
-		return bytes.NewBuffer(make([]byte, 0, 4096))
-	},
+// maxRetainedBufferCap is the capacity limit in force, maxPoolBufferCap
+// unless BUFFER_POOL_MAX_BUFFER_KB overrides it.
+var maxRetainedBufferCap atomic.Int64
+
+func init() {
+	maxRetainedBufferCap.Store(maxPoolBufferCap)
+}
+
+// setMaxRetainedBufferCap sets the largest buffer capacity returned to the pools.
+func setMaxRetainedBufferCap(n int) {
+	if n <= 0 {
+		n = maxPoolBufferCap
+	}
+	maxRetainedBufferCap.Store(int64(n))
+}
+
+// bufferPool is a sync.Pool of byte buffers that counts its traffic for
+// /metrics. sync.Pool may drop idle buffers on any GC, so the counters
+// describe flow through the pool rather than how many buffers it holds.
+type bufferPool struct {
+	name     string
+	initSize int
+	pool     sync.Pool
+
+	gets      atomic.Int64 // buffers handed out
+	misses    atomic.Int64 // gets that had to allocate
+	returned  atomic.Int64 // buffers put back for reuse
+	discarded atomic.Int64 // buffers dropped for exceeding the retained cap
+}
+
+// BufferPoolStats is a snapshot of one buffer pool's counters.
+type BufferPoolStats struct {
+	Name      string
+	Gets      int64
+	Misses    int64
+	Returned  int64
+	Discarded int64
+	InUse     int64 // handed out and not yet returned or discarded
 }
 
-// getBuffer retrieves a buffer from the pool.
+func newBufferPool(name string, initSize int) *bufferPool {
+	p := &bufferPool{name: name, initSize: initSize}
+	p.pool.New = func() interface{} {
+		p.misses.Add(1)
+		return bytes.NewBuffer(make([]byte, 0, initSize))
+	}
+	return p
+}
+
+// get retrieves a buffer from the pool.
 // Bug 7: Use safe type assertion to prevent panics.
-func getBuffer() *bytes.Buffer {
-	v := jsonBufferPool.Get()
+func (p *bufferPool) get() *bytes.Buffer {
+	p.gets.Add(1)
+	v := p.pool.Get()
 	buf, ok := v.(*bytes.Buffer)
 	if !ok {
 		// This should never happen with our New func, but handle defensively
-		log.Warn().Interface("got_type", v).Msg("Unexpected type from json buffer pool")
-		return bytes.NewBuffer(make([]byte, 0, 4096))
+		log.Warn().Interface("got_type", v).Str("pool", p.name).Msg("Unexpected type from buffer pool")
+		p.misses.Add(1)
+		return bytes.NewBuffer(make([]byte, 0, p.initSize))
 	}
 	return buf
 }
 
-// putBuffer returns a buffer to the pool after resetting it.
+// put returns a buffer to the pool after resetting it.
 // Fix #2: Discard oversized buffers to prevent memory bloat.
-func putBuffer(buf *bytes.Buffer) {
-	if buf.Cap() > maxPoolBufferCap {
+func (p *bufferPool) put(buf *bytes.Buffer) {
+	if int64(buf.Cap()) > maxRetainedBufferCap.Load() {
 		// Discard oversized buffer - let GC collect it
+		p.discarded.Add(1)
 		return
 	}
+	p.returned.Add(1)
 	buf.Reset()
-	jsonBufferPool.Put(buf)
+	p.pool.Put(buf)
+}
+
+// stats returns a snapshot of the pool's counters.
+func (p *bufferPool) stats() BufferPoolStats {
+	s := BufferPoolStats{
+		Name:      p.name,
+		Gets:      p.gets.Load(),
+		Misses:    p.misses.Load(),
+		Returned:  p.returned.Load(),
+		Discarded: p.discarded.Load(),
+	}
+	s.InUse = max(s.Gets-s.Returned-s.Discarded, 0)
+	return s
+}
+
+// requestBuffers provides reusable byte buffers for JSON decoding.
+// This reduces GC pressure by avoiding frequent allocation of buffers.
+// Pre-allocates 4KB, the typical JSON request size.
+var requestBuffers = newBufferPool("request", 4096)
+
+// responseBuffers provides reusable byte buffers for JSON encoding.
+// Pre-allocates 8KB (HTML content can be large).
+var responseBuffers = newBufferPool("response", 8192)
+
+// bufferPoolStats returns the counters of every buffer pool.
+func bufferPoolStats() []BufferPoolStats {
+	return []BufferPoolStats{requestBuffers.stats(), responseBuffers.stats()}
+}
+
+// getBuffer retrieves a request buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	return requestBuffers.get()
 }
 
-// responseBufferPool provides reusable byte buffers for JSON encoding.
-var responseBufferPool = sync.Pool{
-	New: func() interface{} {
-		// Pre-allocate 8KB buffer for responses (HTML content can be large)
-		return bytes.NewBuffer(make([]byte, 0, 8192))
-	},
+// putBuffer returns a request buffer to the pool.
+func putBuffer(buf *bytes.Buffer) {
+	requestBuffers.put(buf)
 }
 
 // getResponseBuffer retrieves a response buffer from the pool.
-// Bug 7: Use safe type assertion to prevent panics.
 func getResponseBuffer() *bytes.Buffer {
-	v := responseBufferPool.Get()
-	buf, ok := v.(*bytes.Buffer)
-	if !ok {
-		// This should never happen with our New func, but handle defensively
-		log.Warn().Interface("got_type", v).Msg("Unexpected type from response buffer pool")
-		return bytes.NewBuffer(make([]byte, 0, 8192))
-	}
-	return buf
+	return responseBuffers.get()
 }
 
-// putResponseBuffer returns a response buffer to the pool after resetting it.
-// Fix #2: Discard oversized buffers to prevent memory bloat.
+// putResponseBuffer returns a response buffer to the pool.
 func putResponseBuffer(buf *bytes.Buffer) {
-	if buf.Cap() > maxPoolBufferCap {
-		// Discard oversized buffer - let GC collect it
-		return
-	}
-	buf.Reset()
-	responseBufferPool.Put(buf)
+	responseBuffers.put(buf)
 }
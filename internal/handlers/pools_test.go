@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBufferPoolStats(t *testing.T) {
+	defer setMaxRetainedBufferCap(maxPoolBufferCap)
+	setMaxRetainedBufferCap(1024)
+
+	p := newBufferPool("test", 64)
+
+	small := p.get()
+	small.WriteString("hello")
+	large := p.get()
+	large.Write(bytes.Repeat([]byte("x"), 2048))
+
+	s := p.stats()
+	if s.Gets != 2 || s.InUse != 2 {
+		t.Errorf("after two gets: %+v", s)
+	}
+
+	p.put(small)
+	p.put(large)
+	s = p.stats()
+	if s.Returned != 1 || s.Discarded != 1 || s.InUse != 0 {
+		t.Errorf("after put: Returned=%d Discarded=%d InUse=%d, want 1/1/0", s.Returned, s.Discarded, s.InUse)
+	}
+	if s.Misses < 1 || s.Misses > s.Gets {
+		t.Errorf("Misses = %d, want between 1 and %d", s.Misses, s.Gets)
+	}
+
+	// A returned buffer comes back reset
+	if buf := p.get(); buf.Len() != 0 {
+		t.Errorf("reused buffer has %d bytes, want 0", buf.Len())
+	}
+}
+
+func TestSetMaxRetainedBufferCap(t *testing.T) {
+	defer setMaxRetainedBufferCap(maxPoolBufferCap)
+
+	setMaxRetainedBufferCap(256 * 1024)
+	if got := maxRetainedBufferCap.Load(); got != 256*1024 {
+		t.Errorf("cap = %d, want %d", got, 256*1024)
+	}
+	setMaxRetainedBufferCap(0)
+	if got := maxRetainedBufferCap.Load(); got != maxPoolBufferCap {
+		t.Errorf("cap after 0 = %d, want default %d", got, maxPoolBufferCap)
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/browser"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// deepProbeTimeout bounds a deep health probe, including the wait for a
+// free browser. The caller disconnecting ends it sooner.
+const deepProbeTimeout = 30 * time.Second
+
+// ProbeResult reports a deep health probe.
+type ProbeResult struct {
+	Mode       string `json:"mode"`
+	DurationMs int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+// deepProbe takes a browser from the pool ahead of queued solves, navigates
+// a fresh page to about:blank and gives the browser back, proving the pool
+// can still serve a request end to end.
+func (h *Handler) deepProbe(ctx context.Context) error {
+	if h.pool == nil {
+		return errors.New("browser pool is not available")
+	}
+	ctx, cancel := context.WithTimeout(ctx, deepProbeTimeout)
+	defer cancel()
+
+	b, err := h.pool.AcquirePriority(ctx, browser.PriorityHigh)
+	if err != nil {
+		return fmt.Errorf("failed to acquire browser: %w", err)
+	}
+	defer h.pool.Release(b)
+
+	page, err := b.Context(ctx).Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		return fmt.Errorf("failed to create page: %w", err)
+	}
+	defer page.Close()
+
+	if err := page.Context(ctx).Navigate("about:blank"); err != nil {
+		return fmt.Errorf("failed to navigate: %w", err)
+	}
+	return nil
+}
+
+// ProbeStatus is the body of /livez and /readyz.
+type ProbeStatus struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// writeProbeStatus answers a liveness or readiness probe. Anything but 200
+// carries status "error".
+func writeProbeStatus(w http.ResponseWriter, code int, message string) {
+	status := types.StatusOK
+	if code != http.StatusOK {
+		status = types.StatusError
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(ProbeStatus{Status: status, Message: message}); err != nil {
+		log.Debug().Err(err).Msg("Failed to write probe response")
+	}
+}
+
+// handleLivez answers the liveness probe: the process is up and serving HTTP.
+// It deliberately checks nothing else, so a saturated pool never gets the
+// instance restarted.
+func (h *Handler) handleLivez(w http.ResponseWriter) {
+	writeProbeStatus(w, http.StatusOK, "alive")
+}
+
+// handleReadyz answers the readiness probe: 200 while the browser pool is
+// warmed, not drained and has at least one browser, 503 otherwise.
+func (h *Handler) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if h.pool == nil {
+		writeProbeStatus(w, http.StatusServiceUnavailable, "browser pool is not available")
+		return
+	}
+	if err := h.pool.Ready(); err != nil {
+		log.Ctx(r.Context()).Debug().Err(err).Msg("Readiness probe failed")
+		writeProbeStatus(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	writeProbeStatus(w, http.StatusOK, "ready")
+}
+
+// StartupHandler fronts the API listener while the service starts, so it can
+// listen before the browser pool has pre-warmed: /livez answers 200 and every
+// other path 503 until Ready installs the real handler.
+type StartupHandler struct {
+	next atomic.Pointer[http.Handler]
+}
+
+// NewStartupHandler returns a StartupHandler that is still starting.
+func NewStartupHandler() *StartupHandler {
+	return &StartupHandler{}
+}
+
+// Ready hands every further request to next.
+func (s *StartupHandler) Ready(next http.Handler) {
+	s.next.Store(&next)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *StartupHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if next := s.next.Load(); next != nil {
+		(*next).ServeHTTP(w, r)
+		return
+	}
+	if r.URL.Path == "/livez" {
+		writeProbeStatus(w, http.StatusOK, "alive")
+		return
+	}
+	w.Header().Set("Retry-After", "5")
+	writeProbeStatus(w, http.StatusServiceUnavailable, "starting: browser pool is warming up")
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+	"github.com/Rorqualx/flaresolverr-go/internal/proxyhealth"
+	"github.com/Rorqualx/flaresolverr-go/internal/solver"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+	"github.com/Rorqualx/flaresolverr-go/pkg/version"
+)
+
+// newProxyHealthChecker starts health checks of every configured proxy:
+// PROXY_URL, PROXY_LIST, the proxy pools and the PROXY_ROUTES targets. The
+// pools skip proxies the checker reports dead. Returns nil when no proxy is
+// configured.
+func newProxyHealthChecker(cfg *config.Config, egress *solver.EgressPool, pools map[string]*solver.ProxyPool, routes solver.ProxyRoutes) *proxyhealth.Checker {
+	var proxies []*types.Proxy
+	if cfg.ProxyURL != "" {
+		proxies = append(proxies, &types.Proxy{URL: cfg.ProxyURL, Username: cfg.ProxyUsername, Password: cfg.ProxyPassword})
+	}
+	proxies = append(proxies, egress.Proxies()...)
+	for _, name := range solver.ProxyPoolNames(pools) {
+		proxies = append(proxies, pools[name].Proxies()...)
+	}
+	for _, route := range routes {
+		if route.Proxy.URL != "" {
+			proxies = append(proxies, route.Proxy)
+		}
+	}
+
+	checker := proxyhealth.New(proxyhealth.Config{
+		Interval:      cfg.ProxyHealthInterval,
+		Timeout:       cfg.ProxyHealthTimeout,
+		IPURL:         cfg.ProxyHealthIPURL,
+		CanaryURL:     cfg.ProxyHealthCanaryURL,
+		FailThreshold: cfg.ProxyHealthFailures,
+	}, proxies)
+	if checker == nil {
+		log.Warn().Msg("PROXY_HEALTH_CHECK_ENABLED is set but no proxies are configured")
+		return nil
+	}
+
+	egress.SetHealthCheck(checker.Healthy)
+	for _, pool := range pools {
+		pool.SetHealthCheck(checker.Healthy)
+	}
+	checker.Start()
+	log.Info().
+		Int("proxies", len(checker.Statuses())).
+		Dur("interval", cfg.ProxyHealthInterval).
+		Bool("canary", cfg.ProxyHealthCanaryURL != "").
+		Msg("Proxy health checks enabled")
+	return checker
+}
+
+// handleProxiesStatus handles the proxies.status command.
+func (h *Handler) handleProxiesStatus(w http.ResponseWriter, startTime time.Time) {
+	if h.proxyHealth == nil {
+		h.writeError(w, "Proxy health checks are not enabled (set PROXY_HEALTH_CHECK_ENABLED=true)", startTime)
+		return
+	}
+	statuses := h.proxyHealth.Statuses()
+	alive := 0
+	for _, st := range statuses {
+		if st.Alive {
+			alive++
+		}
+	}
+	resp := types.Response{
+		Status:    types.StatusOK,
+		Message:   fmt.Sprintf("%d of %d proxies alive", alive, len(statuses)),
+		StartTime: startTime.UnixMilli(),
+		EndTime:   time.Now().UnixMilli(),
+		Version:   version.Full(),
+		Proxies:   statuses,
+	}
+	h.writeJSONResponse(w, http.StatusOK, resp)
+}
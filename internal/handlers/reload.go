@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+	"github.com/Rorqualx/flaresolverr-go/internal/quiethours"
+	"github.com/Rorqualx/flaresolverr-go/internal/solver"
+	"github.com/Rorqualx/flaresolverr-go/internal/targetpolicy"
+)
+
+// ReloadResult reports a configuration reload: the settings now in effect
+// and, for each setting that could not be applied, why it kept its
+// previous value.
+type ReloadResult struct {
+	Applied []string `json:"applied"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// SetConfigReloader enables POST /api/config/reload on the admin dashboard.
+func (h *Handler) SetConfigReloader(reload func() (ReloadResult, error)) {
+	h.configReload = reload
+}
+
+func (h *Handler) currentTargetPolicy() *targetpolicy.Policy {
+	h.rulesMu.RLock()
+	defer h.rulesMu.RUnlock()
+	return h.targetPolicy
+}
+
+func (h *Handler) currentQuietHours() *quiethours.Schedule {
+	h.rulesMu.RLock()
+	defer h.rulesMu.RUnlock()
+	return h.quietHours
+}
+
+func (h *Handler) currentProxyPools() map[string]*solver.ProxyPool {
+	h.rulesMu.RLock()
+	defer h.rulesMu.RUnlock()
+	return h.proxyPools
+}
+
+func (h *Handler) currentDomainOverrides() solver.DomainOverrides {
+	h.rulesMu.RLock()
+	defer h.rulesMu.RUnlock()
+	return h.domainOverrides
+}
+
+// ReloadDomainRules replaces the target domain policy, quiet hours and
+// domain overrides with the ones cfg describes, re-reading the allow and
+// deny list files and the domain overrides file. If any fails to load, all
+// keep their previous rules.
+func (h *Handler) ReloadDomainRules(cfg *config.Config) error {
+	policy, err := targetpolicy.Load(cfg.TargetAllowlist, cfg.TargetDenylist, cfg.TargetAllowlistFile, cfg.TargetDenylistFile)
+	if err != nil {
+		return fmt.Errorf("target domain policy: %w", err)
+	}
+	schedule, err := quiethours.Parse(cfg.QuietHours)
+	if err != nil {
+		return fmt.Errorf("QUIET_HOURS: %w", err)
+	}
+	overrides, err := solver.LoadDomainOverrides(cfg.DomainOverridesPath)
+	if err != nil {
+		return fmt.Errorf("DOMAIN_OVERRIDES_PATH: %w", err)
+	}
+	warnUnknownOverridePools(overrides, h.currentProxyPools())
+
+	h.rulesMu.Lock()
+	h.targetPolicy = policy
+	h.quietHours = schedule
+	h.domainOverrides = overrides
+	h.rulesMu.Unlock()
+
+	allowed, blocked := policy.Summary()
+	log.Info().
+		Int("allowed", allowed).
+		Int("blocked", blocked).
+		Bool("quiet_hours", schedule != nil).
+		Int("override_patterns", len(overrides)).
+		Msg("Domain rules reloaded")
+	return nil
+}
+
+// ReloadProxyPools re-reads the named proxy pools from path. Reloaded pools
+// start with fresh stats; on error the previous pools stay in use.
+func (h *Handler) ReloadProxyPools(path string) error {
+	pools, err := solver.LoadProxyPools(path)
+	if err != nil {
+		return err
+	}
+	for _, name := range h.proxyRoutes.Pools() {
+		if pools[name] == nil {
+			log.Warn().Str("pool", name).Msg("PROXY_ROUTES refers to an unknown proxy pool; matching requests will fail")
+		}
+	}
+	warnUnknownOverridePools(h.currentDomainOverrides(), pools)
+	// Health checks keep probing the proxies known at startup; a proxy
+	// added here counts as healthy
+	if h.proxyHealth != nil {
+		for _, pool := range pools {
+			pool.SetHealthCheck(h.proxyHealth.Healthy)
+		}
+	}
+
+	h.rulesMu.Lock()
+	h.proxyPools = pools
+	h.rulesMu.Unlock()
+
+	log.Info().Strs("pools", solver.ProxyPoolNames(pools)).Msg("Proxy pools reloaded")
+	return nil
+}
+
+// handleConfigReload runs the configured reloader for the admin dashboard.
+func (h *Handler) handleConfigReload(w http.ResponseWriter, startTime time.Time) {
+	if h.configReload == nil {
+		h.writeErrorWithStatus(w, http.StatusNotFound, "Configuration reload is not available", startTime)
+		return
+	}
+	result, err := h.configReload()
+	if err != nil {
+		h.writeErrorWithStatus(w, http.StatusInternalServerError, "Configuration reload failed: "+err.Error(), startTime)
+		return
+	}
+	h.writeJSONResponse(w, http.StatusOK, result)
+}
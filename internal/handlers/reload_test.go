@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+)
+
+func TestReloadDomainRules(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	if err := h.ReloadDomainRules(&config.Config{TargetDenylist: "example.com"}); err != nil {
+		t.Fatalf("ReloadDomainRules() error = %v", err)
+	}
+	if err := h.currentTargetPolicy().Check("example.com"); err == nil {
+		t.Error("example.com should be refused after the reload")
+	}
+
+	// A broken quiet hours spec keeps the previous rules, target policy included
+	err := h.ReloadDomainRules(&config.Config{QuietHours: "not a schedule"})
+	if err == nil {
+		t.Fatal("ReloadDomainRules() with invalid QUIET_HOURS should fail")
+	}
+	if err := h.currentTargetPolicy().Check("example.com"); err == nil {
+		t.Error("a failed reload should keep the previous target policy")
+	}
+
+	if err := h.ReloadDomainRules(&config.Config{}); err != nil {
+		t.Fatalf("ReloadDomainRules() error = %v", err)
+	}
+	if err := h.currentTargetPolicy().Check("example.com"); err != nil {
+		t.Errorf("example.com refused after the deny list was removed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "overrides.yaml")
+	if err := os.WriteFile(path, []byte("- domains: [example.com]\n  tabsTillVerify: 7\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.ReloadDomainRules(&config.Config{DomainOverridesPath: path}); err != nil {
+		t.Fatalf("ReloadDomainRules() error = %v", err)
+	}
+	if o := h.currentDomainOverrides().Match("example.com"); o == nil || o.TabsTillVerify != 7 {
+		t.Errorf("domain override after reload = %+v", o)
+	}
+	if err := h.ReloadDomainRules(&config.Config{DomainOverridesPath: path + ".missing"}); err == nil {
+		t.Fatal("ReloadDomainRules() with a missing overrides file should fail")
+	}
+	if h.currentDomainOverrides().Match("example.com") == nil {
+		t.Error("a failed reload should keep the previous domain overrides")
+	}
+}
+
+func TestReloadProxyPools(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	path := filepath.Join(t.TempDir(), "pools.yaml")
+	if err := os.WriteFile(path, []byte(`- name: residential
+  proxies: ["http://10.0.0.1:8080"]
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.ReloadProxyPools(path); err != nil {
+		t.Fatalf("ReloadProxyPools() error = %v", err)
+	}
+	if h.currentProxyPools()["residential"] == nil {
+		t.Fatal("residential pool missing after the reload")
+	}
+
+	if err := os.WriteFile(path, []byte("- name: \"\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.ReloadProxyPools(path); err == nil {
+		t.Fatal("ReloadProxyPools() of an invalid file should fail")
+	}
+	if h.currentProxyPools()["residential"] == nil {
+		t.Error("a failed reload should keep the previous pools")
+	}
+}
+
+func TestAdminConfigReload(t *testing.T) {
+	h := mockHandler()
+	defer h.sessions.Close()
+
+	post := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/config/reload", strings.NewReader("{}"))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		h.AdminHandler().ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post(); w.Code != http.StatusNotFound {
+		t.Errorf("status without a reloader = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	h.SetConfigReloader(func() (ReloadResult, error) {
+		return ReloadResult{Applied: []string{"LOG_LEVEL"}, Errors: []string{"PROXY_POOLS_PATH: bad file"}}, nil
+	})
+	w := post()
+	var result ReloadResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || len(result.Applied) != 1 || len(result.Errors) != 1 {
+		t.Errorf("reload = %d %+v, want 200 with one applied setting and one error", w.Code, result)
+	}
+
+	h.SetConfigReloader(func() (ReloadResult, error) {
+		return ReloadResult{}, errors.New("CONFIG_FILE: line 1: expected KEY=VALUE")
+	})
+	if w := post(); w.Code != http.StatusInternalServerError {
+		t.Errorf("status of a failed reload = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/solver"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// resultCache keeps solved plain GETs for a short TTL, so a burst of
+// identical requests (a misconfigured indexer retrying in a loop, say) costs
+// one browser solve instead of one each. Identical requests that arrive
+// while the first is still solving wait for it rather than starting their own.
+type resultCache struct {
+	mu         sync.Mutex
+	entries    map[string]*resultEntry
+	pending    map[string]chan struct{} // key -> closed when the running solve finishes
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time // injectable for tests
+}
+
+// resultEntry is one cached solve.
+type resultEntry struct {
+	result    *solver.Result
+	expiresAt time.Time
+}
+
+// newResultCache creates a cache serving results for ttl, holding at most
+// maxEntries of them.
+func newResultCache(ttl time.Duration, maxEntries int) *resultCache {
+	return &resultCache{
+		entries:    make(map[string]*resultEntry),
+		pending:    make(map[string]chan struct{}),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+	}
+}
+
+// acquire returns the cached result for key, waiting first for an identical
+// request that is solving right now. On a miss it returns a nil result and
+// the finish function the caller must call with its own solve's outcome;
+// requests waiting on the caller are released then, and later calls are
+// ignored. A caller whose context ends while waiting solves on its own.
+func (c *resultCache) acquire(ctx context.Context, key string) (*solver.Result, func(*solver.Result, error)) {
+	for {
+		c.mu.Lock()
+		if e, ok := c.entries[key]; ok {
+			if c.now().Before(e.expiresAt) {
+				c.mu.Unlock()
+				return e.result, nil
+			}
+			delete(c.entries, key)
+		}
+		done, solving := c.pending[key]
+		if !solving {
+			done = make(chan struct{})
+			c.pending[key] = done
+			c.mu.Unlock()
+			var once sync.Once
+			return nil, func(result *solver.Result, err error) {
+				once.Do(func() { c.finish(key, done, result, err) })
+			}
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-done:
+			// Look again: a failed solve leaves nothing behind, and the next
+			// waiter takes over
+		case <-ctx.Done():
+			return nil, func(*solver.Result, error) {}
+		}
+	}
+}
+
+// finish stores a successful solve and releases the requests waiting on it.
+func (c *resultCache) finish(key string, done chan struct{}, result *solver.Result, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending[key] == done {
+		delete(c.pending, key)
+	}
+	close(done)
+
+	if err != nil || result == nil || result.StatusCode < http.StatusOK || result.StatusCode >= http.StatusBadRequest {
+		return
+	}
+	now := c.now()
+	c.entries[key] = &resultEntry{result: result, expiresAt: now.Add(c.ttl)}
+	if len(c.entries) > c.maxEntries {
+		c.evictLocked(now)
+	}
+}
+
+// evictLocked prunes expired entries, then the soonest-to-expire if still over cap.
+// Caller must hold c.mu.
+func (c *resultCache) evictLocked(now time.Time) {
+	for k, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	for len(c.entries) > c.maxEntries {
+		var oldestKey string
+		var oldest *resultEntry
+		for k, e := range c.entries {
+			if oldest == nil || e.expiresAt.Before(oldest.expiresAt) {
+				oldestKey, oldest = k, e
+			}
+		}
+		delete(c.entries, oldestKey)
+	}
+}
+
+// resultCacheable reports whether a request's solve can be served to an
+// identical request. Only sessionless GETs qualify, and only without the
+// options that act on the page or return per-solve artifacts.
+func resultCacheable(req *types.Request, method string) bool {
+	return method == http.MethodGet && req.Session == "" && req.SessionState == nil &&
+		!req.ReturnScreenshot && !req.Download &&
+		req.ExecuteJs == "" && req.EvaluateJs == "" &&
+		len(req.Actions) == 0 && len(req.CaptureRequests) == 0 &&
+		!req.ReturnHar && !req.ReturnPdf && req.ReturnSnapshot == "" &&
+		!req.RecordSolve && !req.VerifyProxy && !req.SolveRecaptcha
+}
+
+// resultCacheKey hashes the URL, proxy and cookies together with the rest of
+// the request's options. Settings that only shape the response (timeout,
+// returnOnlyCookies, streamResponse) and metrics tags are left out, so a
+// client that varies them still shares the solve.
+func resultCacheKey(req *types.Request) string {
+	norm := *req
+	norm.MaxTimeout = 0
+	norm.ReturnOnlyCookies = false
+	norm.StreamResponse = false
+	norm.Tags = nil
+	norm.Priority = ""
+	b, err := json.Marshal(&norm)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
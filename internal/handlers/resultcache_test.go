@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/solver"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func TestResultCache(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	c := newResultCache(30*time.Second, 2)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if cached, finish := c.acquire(ctx, "a"); cached != nil || finish == nil {
+		t.Fatal("acquire() on an empty cache should hand out the solve")
+	} else {
+		finish(nil, errors.New("timeout"))
+	}
+	_, finish := c.acquire(ctx, "a")
+	if finish == nil {
+		t.Fatal("A failed solve should not be cached")
+	}
+
+	// An identical request arriving mid-solve waits and gets the result
+	solved := &solver.Result{StatusCode: http.StatusOK, HTML: "<html></html>"}
+	got := make(chan *solver.Result)
+	go func() {
+		cached, _ := c.acquire(ctx, "a")
+		got <- cached
+	}()
+	time.Sleep(20 * time.Millisecond)
+	finish(solved, nil)
+	finish(nil, errors.New("ignored"))
+	if r := <-got; r != solved {
+		t.Errorf("Waiting request got %v, want the shared result", r)
+	}
+
+	now = now.Add(31 * time.Second)
+	if cached, finish := c.acquire(ctx, "a"); cached != nil {
+		t.Error("acquire() returned an expired result")
+	} else {
+		finish(&solver.Result{StatusCode: http.StatusForbidden}, nil)
+	}
+	if _, finish := c.acquire(ctx, "a"); finish == nil {
+		t.Error("A blocked response should not be cached")
+	} else {
+		finish(nil, errors.New("timeout"))
+	}
+
+	// Over the cap, the soonest to expire goes
+	for i, key := range []string{"x", "y", "z"} {
+		now = now.Add(time.Duration(i) * time.Second)
+		_, finish := c.acquire(ctx, key)
+		finish(solved, nil)
+	}
+	if len(c.entries) != 2 || c.entries["x"] != nil {
+		t.Errorf("entries = %v, want y and z", c.entries)
+	}
+}
+
+func TestResultCacheWaiterCanceled(t *testing.T) {
+	c := newResultCache(time.Minute, 10)
+	_, finish := c.acquire(context.Background(), "a")
+	defer finish(nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if cached, _ := c.acquire(ctx, "a"); cached != nil {
+		t.Error("A canceled waiter should get no result")
+	}
+}
+
+func TestResultCacheKey(t *testing.T) {
+	base := func() *types.Request {
+		return &types.Request{
+			Cmd:     types.CmdRequestGet,
+			URL:     "https://example.com/",
+			Cookies: []types.RequestCookie{{Name: "a", Value: "1"}},
+			Proxy:   &types.Proxy{URL: "http://proxy:8080"},
+		}
+	}
+	key := resultCacheKey(base())
+
+	shaping := base()
+	shaping.MaxTimeout = 120000
+	shaping.ReturnOnlyCookies = true
+	shaping.StreamResponse = true
+	shaping.Tags = map[string]string{"client": "sonarr"}
+	if resultCacheKey(shaping) != key {
+		t.Error("Response-only settings should not change the key")
+	}
+
+	for name, change := range map[string]func(*types.Request){
+		"url":     func(r *types.Request) { r.URL = "https://example.com/other" },
+		"proxy":   func(r *types.Request) { r.Proxy = &types.Proxy{URL: "http://other:8080"} },
+		"cookies": func(r *types.Request) { r.Cookies[0].Value = "2" },
+		"headers": func(r *types.Request) { r.Headers = map[string]string{"Referer": "x"} },
+	} {
+		req := base()
+		change(req)
+		if resultCacheKey(req) == key {
+			t.Errorf("Changing %s should change the key", name)
+		}
+	}
+}
+
+func TestResultCacheable(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    types.Request
+		method string
+		want   bool
+	}{
+		{"plain get", types.Request{}, http.MethodGet, true},
+		{"post", types.Request{}, http.MethodPost, false},
+		{"session", types.Request{Session: "s"}, http.MethodGet, false},
+		{"screenshot", types.Request{ReturnScreenshot: true}, http.MethodGet, false},
+		{"actions", types.Request{Actions: []types.Action{{Type: types.ActionClick, Selector: "a"}}}, http.MethodGet, false},
+		{"evaluateJs", types.Request{EvaluateJs: "1"}, http.MethodGet, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resultCacheable(&tt.req, tt.method); got != tt.want {
+				t.Errorf("resultCacheable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/middleware"
 	"github.com/Rorqualx/flaresolverr-go/internal/types"
 )
 
@@ -13,26 +16,77 @@ import (
 var validCommands = map[string]bool{
 	types.CmdRequestGet:        true,
 	types.CmdRequestPost:       true,
+	types.CmdRequestPut:        true,
+	types.CmdRequestPatch:      true,
+	types.CmdRequestDelete:     true,
 	types.CmdSessionsCreate:    true,
 	types.CmdSessionsList:      true,
 	types.CmdSessionsDestroy:   true,
 	types.CmdSessionsKeepalive: true,
+	types.CmdSessionsExport:    true,
+	types.CmdSessionsImport:    true,
+	types.CmdSessionsInfo:      true,
+	types.CmdSessionsTouch:     true,
+	types.CmdPoolDrain:         true,
+	types.CmdPoolResize:        true,
+	types.CmdPoolRecycleAll:    true,
+	types.CmdTurnstileSolve:    true,
+	types.CmdProxiesStatus:     true,
+	types.CmdCaptchaStatus:     true,
+	types.CmdDomainsGetPrefs:   true,
+	types.CmdDomainsSetPrefs:   true,
 }
 
 // routeCommand routes API commands to their handlers.
 // Commands must be in the validCommands map to be processed.
 func (h *Handler) routeCommand(w http.ResponseWriter, r *http.Request, req *types.Request, startTime time.Time) {
+	r, span := startCommandSpan(r, req)
+	defer span.End()
+
+	// Tagged, audited and traced requests are attributed an outcome once answered
+	if len(req.Tags) > 0 || h.audit != nil || span.IsRecording() {
+		ow := &outcomeWriter{ResponseWriter: w}
+		w = ow
+		if span.IsRecording() {
+			defer traceOutcome(span, ow)
+		}
+		if len(req.Tags) > 0 {
+			defer h.recordTaggedRequest(ow, r, req, startTime)
+		}
+		if h.audit != nil {
+			defer h.auditRequest(ow, r, req, startTime)
+		}
+	}
+
 	// Early validation: check if command is in the valid commands map
 	if !validCommands[req.Cmd] {
 		h.writeError(w, fmt.Sprintf("Unknown command: %q", req.Cmd), startTime)
 		return
 	}
 
+	// Scoped API keys may only call the commands they were granted
+	if !middleware.CommandAllowed(r.Context(), req.Cmd) {
+		log.Ctx(r.Context()).Warn().
+			Str("cmd", req.Cmd).
+			Str("api_key", middleware.APIKeyNameFromContext(r.Context())).
+			Msg("Command denied for API key scope")
+		h.writeErrorWithStatus(w, http.StatusForbidden, fmt.Sprintf("API key is not permitted to call %q", req.Cmd), startTime)
+		return
+	}
+
+	defer h.trackSolve(r, req, startTime)()
+
 	switch req.Cmd {
 	case types.CmdRequestGet:
-		h.handleRequest(w, r.Context(), req, false, startTime)
+		h.handleRequest(w, r, req, http.MethodGet, startTime)
 	case types.CmdRequestPost:
-		h.handleRequest(w, r.Context(), req, true, startTime)
+		h.handleRequest(w, r, req, http.MethodPost, startTime)
+	case types.CmdRequestPut:
+		h.handleRequest(w, r, req, http.MethodPut, startTime)
+	case types.CmdRequestPatch:
+		h.handleRequest(w, r, req, http.MethodPatch, startTime)
+	case types.CmdRequestDelete:
+		h.handleRequest(w, r, req, http.MethodDelete, startTime)
 	case types.CmdSessionsCreate:
 		h.handleSessionCreate(w, r.Context(), req, startTime)
 	case types.CmdSessionsList:
@@ -41,6 +95,30 @@ func (h *Handler) routeCommand(w http.ResponseWriter, r *http.Request, req *type
 		h.handleSessionDestroy(w, req, startTime)
 	case types.CmdSessionsKeepalive:
 		h.handleSessionKeepalive(w, req, startTime)
+	case types.CmdSessionsExport:
+		h.handleSessionExport(w, req, startTime)
+	case types.CmdSessionsImport:
+		h.handleSessionImport(w, req, startTime)
+	case types.CmdSessionsInfo:
+		h.handleSessionInfo(w, r.Context(), req, startTime)
+	case types.CmdSessionsTouch:
+		h.handleSessionTouch(w, r.Context(), req, startTime)
+	case types.CmdPoolDrain:
+		h.handlePoolDrain(w, startTime)
+	case types.CmdPoolResize:
+		h.handlePoolResize(w, r.Context(), req, startTime)
+	case types.CmdPoolRecycleAll:
+		h.handlePoolRecycleAll(w, startTime)
+	case types.CmdTurnstileSolve:
+		h.handleTurnstileSolve(w, r, req, startTime)
+	case types.CmdProxiesStatus:
+		h.handleProxiesStatus(w, startTime)
+	case types.CmdCaptchaStatus:
+		h.handleCaptchaStatus(w, r.Context(), startTime)
+	case types.CmdDomainsGetPrefs:
+		h.handleDomainsGetPrefs(w, req, startTime)
+	case types.CmdDomainsSetPrefs:
+		h.handleDomainsSetPrefs(w, r.Context(), req, startTime)
 	default:
 		// This should never be reached due to validCommands check above,
 		// but kept for safety
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// streamChunkSize is how much of the HTML is escaped per write when streaming
+// a solution response.
+const streamChunkSize = 32 * 1024
+
+// writeSolutionResponse writes a successful solve response with html streamed
+// straight into the JSON output as solution.response. The envelope is encoded
+// with a placeholder, then the HTML is escaped chunk by chunk between its two
+// halves, so the page is never duplicated into a full-size JSON buffer. The
+// bytes produced are identical to encoding/json's; with RESPONSE_COMPRESSION
+// the Compress middleware encodes them as they are written.
+func (h *Handler) writeSolutionResponse(w http.ResponseWriter, r *http.Request, resp types.Response, html string) {
+	placeholder, err := newPlaceholder()
+	if err != nil || resp.Solution == nil {
+		if resp.Solution != nil {
+			resp.Solution.Response = html
+		}
+		h.writeJSONResponse(w, http.StatusOK, resp)
+		return
+	}
+	resp.Solution.Response = placeholder
+	setRequestID(w, &resp)
+
+	buf := getResponseBuffer()
+	defer putResponseBuffer(buf)
+	if err := json.NewEncoder(buf).Encode(resp); err != nil {
+		// Let the buffered path report the failure
+		resp.Solution.Response = html
+		h.writeJSONResponse(w, http.StatusOK, resp)
+		return
+	}
+	envelope := buf.Bytes()
+	idx := bytes.Index(envelope, []byte(`"`+placeholder+`"`))
+	if idx < 0 {
+		// Cannot happen for a hex placeholder, but never emit a broken document
+		resp.Solution.Response = html
+		h.writeJSONResponse(w, http.StatusOK, resp)
+		return
+	}
+
+	noteOutcome(w, &resp)
+	w.Header().Set("Content-Type", "application/json")
+	if err := streamJSONEnvelope(w, envelope[:idx], html, envelope[idx+len(placeholder)+2:]); err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to write JSON response")
+	}
+}
+
+// streamJSONEnvelope writes prefix, html as a JSON string, then suffix.
+func streamJSONEnvelope(w io.Writer, prefix []byte, html string, suffix []byte) error {
+	if _, err := w.Write(prefix); err != nil {
+		return err
+	}
+	chunk := make([]byte, 0, streamChunkSize+streamChunkSize/4)
+	chunk = append(chunk, '"')
+	for len(html) > 0 {
+		n := chunkEnd(html)
+		chunk = appendJSONString(chunk, html[:n])
+		html = html[n:]
+		if _, err := w.Write(chunk); err != nil {
+			return err
+		}
+		chunk = chunk[:0]
+	}
+	chunk = append(chunk, '"')
+	if _, err := w.Write(chunk); err != nil {
+		return err
+	}
+	_, err := w.Write(suffix)
+	return err
+}
+
+// chunkEnd returns the length of the next chunk of html to write, at most
+// streamChunkSize and cut on a rune start so multi-byte characters are
+// escaped whole.
+func chunkEnd(html string) int {
+	n := min(len(html), streamChunkSize)
+	for i := 0; i < utf8.UTFMax-1 && n < len(html) && n > 1 && !utf8.RuneStart(html[n]); i++ {
+		n--
+	}
+	return n
+}
+
+// writeSolutionStream writes a successful solve response as newline-delimited
+// JSON for streamResponse requests. The first line is the usual response with
+// an empty solution.response, so clients can act on the status, headers and
+// cookies before the page arrives. The HTML follows as {"chunk": "..."} lines
+// of up to streamChunkSize bytes, each flushed to the client, and a final
+// {"done": true, "length": n} line carrying the HTML's length in bytes marks
+// the stream complete; a stream without it was cut short.
+func (h *Handler) writeSolutionStream(w http.ResponseWriter, r *http.Request, resp types.Response, html string) {
+	setRequestID(w, &resp)
+
+	buf := getResponseBuffer()
+	defer putResponseBuffer(buf)
+	if err := json.NewEncoder(buf).Encode(resp); err != nil {
+		// Let the buffered path report the failure
+		if resp.Solution != nil {
+			resp.Solution.Response = html
+		}
+		h.writeJSONResponse(w, http.StatusOK, resp)
+		return
+	}
+
+	noteOutcome(w, &resp)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	rc := http.NewResponseController(w)
+	flush := func() error {
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		return nil
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Ctx(r.Context()).Debug().Err(err).Msg("Failed to write streamed response")
+		return
+	}
+	if err := flush(); err != nil {
+		log.Ctx(r.Context()).Debug().Err(err).Msg("Failed to write streamed response")
+		return
+	}
+
+	length := len(html)
+	line := make([]byte, 0, streamChunkSize+streamChunkSize/4)
+	for len(html) > 0 {
+		n := chunkEnd(html)
+		line = append(line, `{"chunk":"`...)
+		line = appendJSONString(line, html[:n])
+		line = append(line, "\"}\n"...)
+		html = html[n:]
+		if _, err := w.Write(line); err != nil {
+			log.Ctx(r.Context()).Debug().Err(err).Msg("Client went away while streaming response")
+			return
+		}
+		if err := flush(); err != nil {
+			log.Ctx(r.Context()).Debug().Err(err).Msg("Client went away while streaming response")
+			return
+		}
+		line = line[:0]
+	}
+	line = append(line, `{"done":true,"length":`...)
+	line = strconv.AppendInt(line, int64(length), 10)
+	line = append(line, "}\n"...)
+	if _, err := w.Write(line); err != nil {
+		log.Ctx(r.Context()).Debug().Err(err).Msg("Failed to write streamed response")
+	}
+}
+
+// appendJSONString appends the body of s as a JSON string (without quotes),
+// escaped exactly as encoding/json does with HTML escaping enabled.
+func appendJSONString(dst []byte, s string) []byte {
+	const hexDigits = "0123456789abcdef"
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				dst = append(dst, '\\', b)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, invalidUTF8...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are valid JSON but break JavaScript parsers
+		if c == '\u2028' || c == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[c&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	return append(dst, s[start:]...)
+}
+
+// newPlaceholder returns a random token marking where the HTML goes in the
+// encoded envelope. Being hex, it encodes verbatim and cannot collide with
+// escaped content.
+func newPlaceholder() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "__flaresolverr_html_" + hex.EncodeToString(b), nil
+}
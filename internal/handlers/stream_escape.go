@@ -0,0 +1,7 @@
+//go:build !goexperiment.jsonv2
+
+package handlers
+
+// invalidUTF8 is what encoding/json writes for a byte that is not valid
+// UTF-8: the six-character \ufffd escape.
+const invalidUTF8 = `\ufffd`
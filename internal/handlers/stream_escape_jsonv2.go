@@ -0,0 +1,8 @@
+//go:build goexperiment.jsonv2
+
+package handlers
+
+// invalidUTF8 is what encoding/json writes for a byte that is not valid
+// UTF-8. The v2-backed encoder writes the replacement character itself
+// rather than the \ufffd escape.
+const invalidUTF8 = "\ufffd"
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/middleware"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func TestAppendJSONStringMatchesEncodingJSON(t *testing.T) {
+	inputs := []string{
+		"",
+		"<html><body>Hello & \"world\"</body></html>",
+		"tabs\tnew\nlines\rback\\slash",
+		"control \x00\x01\x1f\b\f",
+		"unicode é 日本語 🎉",
+		"separators \u2028 and \u2029",
+		"invalid \xff\xfe utf8 \xe2\x82",
+		"\xff",
+		"<>&",
+		"\u2028",
+		"<a href=\"?x=1&y=2\">\u2028\xc3</a>",
+	}
+	for _, in := range inputs {
+		want, err := json.Marshal(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := append(appendJSONString([]byte{'"'}, in), '"')
+		if !bytes.Equal(got, want) {
+			t.Errorf("appendJSONString(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestWriteSolutionResponse(t *testing.T) {
+	// Multi-byte characters straddle chunk boundaries
+	html := "<html>" + strings.Repeat("é日🎉<&>\"", streamChunkSize/5) + "</html>"
+	resp := types.Response{
+		Status:  types.StatusOK,
+		Message: "Challenge solved successfully",
+		Solution: &types.Solution{
+			URL:     "https://example.com",
+			Status:  200,
+			Headers: map[string]string{"response": ""},
+		},
+	}
+
+	var want bytes.Buffer
+	expected := resp
+	sol := *resp.Solution
+	sol.Response = html
+	expected.Solution = &sol
+	if err := json.NewEncoder(&want).Encode(expected); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("plain", func(t *testing.T) {
+		h := mockHandler()
+		defer h.sessions.Close()
+
+		w := httptest.NewRecorder()
+		h.writeSolutionResponse(w, httptest.NewRequest("POST", "/v1", nil), resp, html)
+
+		if got := w.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("Content-Encoding = %q, want none", got)
+		}
+		if !bytes.Equal(w.Body.Bytes(), want.Bytes()) {
+			t.Error("streamed response differs from encoding/json output")
+		}
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		h := mockHandler()
+		defer h.sessions.Close()
+		compressed := middleware.Compress(1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.writeSolutionResponse(w, r, resp, html)
+		}))
+
+		r := httptest.NewRequest("POST", "/v1", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		compressed.ServeHTTP(w, r)
+
+		if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+			t.Fatalf("Content-Encoding = %q, want gzip", got)
+		}
+		gz, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(gz)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(body, want.Bytes()) {
+			t.Error("decompressed response differs from encoding/json output")
+		}
+	})
+}
+
+func TestWriteSolutionStream(t *testing.T) {
+	html := "<html>" + strings.Repeat("é日🎉<&>\"", streamChunkSize/5) + "</html>"
+	resp := types.Response{
+		Status:  types.StatusOK,
+		Message: "Challenge solved successfully",
+		Solution: &types.Solution{
+			URL:     "https://example.com",
+			Status:  200,
+			Cookies: []types.Cookie{{Name: "cf_clearance", Value: "abc"}},
+		},
+	}
+
+	h := mockHandler()
+	defer h.sessions.Close()
+	w := httptest.NewRecorder()
+	h.writeSolutionStream(w, httptest.NewRequest("POST", "/v1", nil), resp, html)
+
+	if got := w.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q, want application/x-ndjson", got)
+	}
+	if !w.Flushed {
+		t.Error("chunks were not flushed to the client")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+	if len(lines) < 4 {
+		t.Fatalf("got %d lines, want the envelope, several chunks and done", len(lines))
+	}
+	var first types.Response
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Solution == nil || first.Solution.Response != "" || len(first.Solution.Cookies) != 1 {
+		t.Errorf("first line = %s, want the solution's cookies without the HTML", lines[0])
+	}
+
+	var body strings.Builder
+	for _, line := range lines[1 : len(lines)-1] {
+		var chunk struct {
+			Chunk string `json:"chunk"`
+		}
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			t.Fatalf("chunk line %q: %v", line, err)
+		}
+		body.WriteString(chunk.Chunk)
+	}
+	if body.String() != html {
+		t.Error("reassembled chunks differ from the HTML")
+	}
+
+	var done struct {
+		Done   bool `json:"done"`
+		Length int  `json:"length"`
+	}
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &done); err != nil {
+		t.Fatal(err)
+	}
+	if !done.Done || done.Length != len(html) {
+		t.Errorf("last line = %s, want done with length %d", lines[len(lines)-1], len(html))
+	}
+}
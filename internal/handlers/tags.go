@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// outcomeWriter remembers the API status ("ok"/"error") of the response
+// written through it, so tagged requests can be attributed a success or
+// failure. API errors are sent with HTTP 200, so the status code alone does
+// not tell. The error message, cookie count and proxy feed the audit log.
+type outcomeWriter struct {
+	http.ResponseWriter
+	status  string
+	message string
+	cookies int
+	proxy   *types.Proxy
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (o *outcomeWriter) Unwrap() http.ResponseWriter {
+	return o.ResponseWriter
+}
+
+// noteOutcome records the API status of a response if w tracks outcomes.
+func noteOutcome(w http.ResponseWriter, resp *types.Response) {
+	o, ok := w.(*outcomeWriter)
+	if !ok {
+		return
+	}
+	o.status = resp.Status
+	if resp.Status != types.StatusOK {
+		o.message = resp.Message
+	}
+	if resp.Solution != nil {
+		o.cookies = len(resp.Solution.Cookies)
+	}
+}
+
+// noteProxy records the proxy a request was finally sent through if w
+// tracks outcomes.
+func noteProxy(w http.ResponseWriter, proxy *types.Proxy) {
+	if o, ok := w.(*outcomeWriter); ok && proxy != nil {
+		o.proxy = proxy
+	}
+}
+
+// recordTaggedRequest attributes a finished request to its tags in the tag
+// metrics and writes the tagged completion log line.
+func (h *Handler) recordTaggedRequest(o *outcomeWriter, r *http.Request, req *types.Request, startTime time.Time) {
+	latency := time.Since(startTime)
+	success := o.status == types.StatusOK
+	h.tagStats.Record(req.Tags, success, latency.Milliseconds())
+
+	withTags(log.Ctx(r.Context()).Info(), req.Tags).
+		Str("cmd", req.Cmd).
+		Str("session", req.Session).
+		Str("status", o.status).
+		Dur("latency", latency).
+		Msg("Tagged request finished")
+}
+
+// withTags adds the request tags to a log event as a "tags" object.
+func withTags(e *zerolog.Event, tags map[string]string) *zerolog.Event {
+	if len(tags) == 0 {
+		return e
+	}
+	d := zerolog.Dict()
+	for k, v := range tags {
+		d.Str(k, v)
+	}
+	return e.Dict("tags", d)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/middleware"
+	"github.com/Rorqualx/flaresolverr-go/internal/stats"
+	"github.com/Rorqualx/flaresolverr-go/internal/tracing"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// startCommandSpan starts the root span of an API command. Unknown commands
+// share one span name to keep span names bounded.
+func startCommandSpan(r *http.Request, req *types.Request) (*http.Request, trace.Span) {
+	name := "flaresolverr.unknown"
+	if validCommands[req.Cmd] {
+		name = "flaresolverr." + req.Cmd
+	}
+	ctx, span := tracing.StartServer(r, name,
+		attribute.String("flaresolverr.cmd", req.Cmd),
+		attribute.String("flaresolverr.domain", stats.ExtractDomain(req.URL)),
+		attribute.String("flaresolverr.session", req.Session),
+		attribute.String("flaresolverr.request_id", middleware.RequestIDFromContext(r.Context())),
+	)
+	return r.WithContext(ctx), span
+}
+
+// traceOutcome marks the command span as failed when an API error was sent.
+func traceOutcome(span trace.Span, o *outcomeWriter) {
+	span.SetAttributes(attribute.Int("flaresolverr.cookies", o.cookies))
+	if o.status != "" && o.status != types.StatusOK {
+		tracing.Fail(span, o.message)
+	}
+}
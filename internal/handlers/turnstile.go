@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/security"
+	"github.com/Rorqualx/flaresolverr-go/internal/solver"
+	"github.com/Rorqualx/flaresolverr-go/internal/stats"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+	"github.com/Rorqualx/flaresolverr-go/pkg/version"
+)
+
+// handleTurnstileSolve handles the turnstile.solve command: it loads the URL,
+// waits for the Turnstile widget with the given sitekey to produce a token
+// and returns only that token, without the page HTML.
+func (h *Handler) handleTurnstileSolve(w http.ResponseWriter, r *http.Request, req *types.Request, startTime time.Time) {
+	ctx := r.Context()
+
+	// Refuse domains outside the target policy or in a configured quiet
+	// window before any network activity
+	if err := h.currentTargetPolicy().Check(stats.ExtractDomain(req.URL)); err != nil {
+		h.writeTargetNotAllowed(w, req.URL, err, startTime)
+		return
+	}
+	if err := h.currentQuietHours().Check(stats.ExtractDomain(req.URL), time.Now()); err != nil {
+		var qhErr *types.QuietHoursError
+		if errors.As(err, &qhErr) {
+			h.writeQuietHoursError(w, req.URL, qhErr, startTime)
+			return
+		}
+	}
+
+	h.applyDomainPrefs(req)
+	h.applyDomainOverride(ctx, req)
+
+	validatedURL, resolvedIP, err := security.ValidateAndResolveURLWithContext(ctx, req.URL)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("url", sanitizeURLForLogging(req.URL)).Msg("URL validation failed")
+		h.writeError(w, fmt.Sprintf("Invalid URL: %v", err), startTime)
+		return
+	}
+
+	proxyPool, err := h.resolveRequestProxy(req)
+	if err != nil {
+		h.writeError(w, err.Error(), startTime)
+		return
+	}
+
+	var proxyURL string
+	if req.Proxy != nil && req.Proxy.URL != "" {
+		proxyURL = req.Proxy.URL
+	} else if h.config.HasDefaultProxy() {
+		proxyURL = h.config.ProxyURL
+	}
+	if proxyURL != "" {
+		if err := security.ValidateProxyURL(proxyURL, h.config.AllowLocalProxies); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("Proxy URL validation failed")
+			h.writeError(w, fmt.Sprintf("Invalid proxy URL: %v", err), startTime)
+			return
+		}
+	}
+
+	if h.solver == nil {
+		h.writeError(w, "Browser pool is not available", startTime)
+		return
+	}
+
+	expectedIP := resolvedIP
+	if !h.config.DNSRebindingProtection {
+		expectedIP = nil
+	}
+
+	opts := &solver.SolveOptions{
+		URL:             validatedURL,
+		Timeout:         h.requestTimeout(req.MaxTimeout),
+		Cookies:         req.Cookies,
+		Proxy:           req.Proxy,
+		ExpectedIP:      expectedIP,
+		TabsTillVerify:  req.TabsTillVerify,
+		UserAgent:       req.UserAgent,
+		Fingerprint:     req.Fingerprint,
+		DefaultTimezone: h.config.BrowserTimezone,
+		Locale:          req.Locale,
+		Timezone:        req.Timezone,
+		Viewport:        req.Viewport,
+		Priority:        acquirePriority(req),
+	}
+
+	token, err := h.solver.SolveTurnstileToken(ctx, opts, req.SiteKey)
+	recordPoolProxy(proxyPool, req.Proxy, err == nil, startTime)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("url", sanitizeURLForLogging(req.URL)).Msg("Turnstile solve failed")
+		h.writeError(w, err.Error(), startTime)
+		return
+	}
+
+	log.Ctx(ctx).Info().
+		Str("url", sanitizeURLForLogging(token.URL)).
+		Str("method", token.Method).
+		Dur("elapsed", time.Since(startTime)).
+		Msg("Turnstile token returned")
+
+	resp := types.Response{
+		Status:    types.StatusOK,
+		Message:   "Turnstile token obtained",
+		StartTime: startTime.UnixMilli(),
+		EndTime:   time.Now().UnixMilli(),
+		Version:   version.Full(),
+		Solution: &types.Solution{
+			URL:            token.URL,
+			Status:         http.StatusOK,
+			Cookies:        []types.Cookie{},
+			UserAgent:      token.UserAgent,
+			BrowserVersion: extractChromeVersion(token.UserAgent),
+			TurnstileToken: token.Token,
+		},
+	}
+	h.writeJSONResponse(w, http.StatusOK, resp)
+}
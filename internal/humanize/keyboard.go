@@ -0,0 +1,61 @@
+package humanize
+
+import (
+	"context"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+)
+
+// Keyboard provides humanized typing for a browser page.
+type Keyboard struct {
+	page   *rod.Page
+	timing *Timing
+}
+
+// NewKeyboard creates a new humanized keyboard for the given page.
+func NewKeyboard(page *rod.Page) *Keyboard {
+	return &Keyboard{
+		page:   page,
+		timing: NewTiming(),
+	}
+}
+
+// NewKeyboardWithConfig creates a new humanized keyboard with custom timing.
+func NewKeyboardWithConfig(page *rod.Page, config TimingConfig) *Keyboard {
+	return &Keyboard{
+		page:   page,
+		timing: NewTimingWithConfig(config),
+	}
+}
+
+// Type types text into the focused element one character at a time, with a
+// random delay between keystrokes. Characters on a US keyboard are sent as
+// key presses; anything else is inserted as text, as an IME would.
+func (k *Keyboard) Type(ctx context.Context, text string) error {
+	for i, r := range text {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if i > 0 && !sleepWithContext(ctx, k.timing.TypingDelay()) {
+			return ctx.Err()
+		}
+
+		var err error
+		if isKeyboardRune(r) {
+			err = k.page.Keyboard.Type(input.Key(r))
+		} else {
+			err = k.page.InsertText(string(r))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isKeyboardRune reports whether r can be typed as a key press: printable
+// ASCII, which rod's US key map covers in full.
+func isKeyboardRune(r rune) bool {
+	return r >= 0x20 && r <= 0x7e
+}
@@ -0,0 +1,37 @@
+package humanize
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod/lib/input"
+)
+
+func TestIsKeyboardRune(t *testing.T) {
+	for _, r := range []rune{'a', 'Z', '0', ' ', '~', '@'} {
+		if !isKeyboardRune(r) {
+			t.Errorf("isKeyboardRune(%q) = false, want true", r)
+		}
+	}
+	for _, r := range []rune{'\n', '\t', 'é', '日', 0x7f} {
+		if isKeyboardRune(r) {
+			t.Errorf("isKeyboardRune(%q) = true, want false", r)
+		}
+	}
+}
+
+// Every rune sent as a key press must be in rod's key map, whose lookup panics
+func TestKeyboardRunesHaveKeys(t *testing.T) {
+	for r := rune(0); r < 0x80; r++ {
+		if !isKeyboardRune(r) {
+			continue
+		}
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					t.Errorf("rune %q has no key: %v", r, p)
+				}
+			}()
+			_ = input.Key(r).Info()
+		}()
+	}
+}
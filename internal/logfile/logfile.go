@@ -0,0 +1,110 @@
+// Package logfile appends to a file that is rotated by size and age, for the
+// application log (LOG_FILE) and the audit log (AUDIT_LOG_FILE).
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Options controls when a File is rotated. Zero values disable the limit.
+type Options struct {
+	MaxSize    int64         // Bytes after which the file is rotated
+	MaxAge     time.Duration // Time after which the file is rotated
+	MaxBackups int           // Rotated files kept as path.1 … path.<MaxBackups>
+	Perm       os.FileMode   // Mode of new files; 0o600 when zero
+}
+
+// File appends to path and, once it reaches MaxSize or has been written to
+// for MaxAge, renames it to path.1 (shifting older copies up to
+// path.<MaxBackups>) and starts afresh. It is safe for concurrent use.
+type File struct {
+	mu       sync.Mutex
+	path     string
+	opts     Options
+	f        *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+}
+
+// Open opens or creates path for appending.
+func Open(path string, opts Options) (*File, error) {
+	if opts.Perm == 0 {
+		opts.Perm = 0o600
+	}
+	r := &File{path: path, opts: opts, now: time.Now}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *File) open() error {
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, r.opts.Perm)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	r.f, r.size, r.openedAt = f, info.Size(), r.now()
+	return nil
+}
+
+// Write appends p, rotating first when p would take the file past MaxSize
+// or the file is older than MaxAge. Records are never split across files.
+func (r *File) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size > 0 && r.due(len(p)) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *File) due(n int) bool {
+	if r.opts.MaxSize > 0 && r.size+int64(n) > r.opts.MaxSize {
+		return true
+	}
+	return r.opts.MaxAge > 0 && r.now().Sub(r.openedAt) >= r.opts.MaxAge
+}
+
+func (r *File) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return fmt.Errorf("failed to close log file for rotation: %w", err)
+	}
+	if r.opts.MaxBackups > 0 {
+		_ = os.Remove(r.backup(r.opts.MaxBackups))
+		for i := r.opts.MaxBackups - 1; i >= 1; i-- {
+			_ = os.Rename(r.backup(i), r.backup(i+1))
+		}
+		if err := os.Rename(r.path, r.backup(1)); err != nil {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	return r.open()
+}
+
+func (r *File) backup(n int) string {
+	return r.path + "." + strconv.Itoa(n)
+}
+
+// Close closes the current file.
+func (r *File) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
@@ -0,0 +1,59 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileRotatesByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := Open(path, Options{MaxAge: time.Hour, MaxBackups: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	now := time.Now()
+	f.now = func() time.Time { return now }
+	f.openedAt = now
+
+	write := func(s string) {
+		t.Helper()
+		if _, err := f.Write([]byte(s)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	write("first\n")
+	now = now.Add(59 * time.Minute)
+	write("second\n")
+	now = now.Add(time.Minute)
+	write("third\n")
+
+	if data, _ := os.ReadFile(path + ".1"); string(data) != "first\nsecond\n" {
+		t.Errorf("backup = %q, want the records from the first hour", data)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "third\n" {
+		t.Errorf("current file = %q, want only the record after rotation", data)
+	}
+}
+
+func TestFileWithoutLimitsNeverRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := Open(path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if _, err := f.Write([]byte("0123456789\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = f.Close()
+	if info, err := os.Stat(path); err != nil || info.Size() != 1100 {
+		t.Errorf("file = %v, %v; want all 1100 bytes in one file", info, err)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Error("file rotated without any limit set")
+	}
+}
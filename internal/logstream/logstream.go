@@ -0,0 +1,162 @@
+// Package logstream fans structured log lines out to live subscribers, each
+// filtered by request ID, session or domain, for the /logs/stream endpoint.
+package logstream
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// subscriberBuffer is how many log lines a slow subscriber may fall behind
+// before further lines are dropped for it.
+const subscriberBuffer = 256
+
+// ErrTooManySubscribers is returned when the subscriber limit is reached.
+var ErrTooManySubscribers = errors.New("too many log stream subscribers")
+
+// Filter selects log lines for a subscription. Empty fields match anything.
+type Filter struct {
+	RequestID string
+	Session   string
+	Domain    string        // Matches the domain and its subdomains
+	Level     zerolog.Level // Minimum level; use zerolog.TraceLevel for everything
+}
+
+// Subscription receives matching log lines as raw JSON.
+type Subscription struct {
+	filter  Filter
+	ch      chan []byte
+	dropped atomic.Int64
+}
+
+// Lines returns the channel matching log lines are delivered on.
+// It is closed when the subscription is cancelled.
+func (s *Subscription) Lines() <-chan []byte {
+	return s.ch
+}
+
+// Dropped returns how many lines were discarded because the subscriber fell behind.
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Broker is an io.Writer for zerolog that copies each log line to matching
+// subscribers. Writes never block on subscribers and never fail, so a stuck
+// client cannot slow down or break logging.
+type Broker struct {
+	mu             sync.RWMutex
+	subs           map[*Subscription]struct{}
+	count          atomic.Int32
+	maxSubscribers int
+}
+
+// NewBroker creates a broker allowing up to maxSubscribers concurrent subscriptions.
+func NewBroker(maxSubscribers int) *Broker {
+	return &Broker{
+		subs:           make(map[*Subscription]struct{}),
+		maxSubscribers: maxSubscribers,
+	}
+}
+
+// Subscribe registers a new subscription for lines matching f.
+func (b *Broker) Subscribe(f Filter) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.subs) >= b.maxSubscribers {
+		return nil, ErrTooManySubscribers
+	}
+	f.Domain = strings.ToLower(strings.TrimPrefix(f.Domain, "."))
+	sub := &Subscription{filter: f, ch: make(chan []byte, subscriberBuffer)}
+	b.subs[sub] = struct{}{}
+	b.count.Add(1)
+	return sub, nil
+}
+
+// Unsubscribe removes a subscription and closes its channel.
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+	delete(b.subs, sub)
+	b.count.Add(-1)
+	close(sub.ch)
+}
+
+// Write implements io.Writer. p is a single zerolog JSON line.
+func (b *Broker) Write(p []byte) (int, error) {
+	if b.count.Load() == 0 {
+		return len(p), nil
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(p, &entry); err != nil {
+		return len(p), nil
+	}
+
+	// zerolog reuses its buffer, so each delivered line needs its own copy
+	var line []byte
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		if !sub.filter.matches(entry) {
+			continue
+		}
+		if line == nil {
+			line = append([]byte(nil), strings.TrimRight(string(p), "\n")...)
+		}
+		select {
+		case sub.ch <- line:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+	return len(p), nil
+}
+
+// matches reports whether a decoded log entry passes the filter.
+func (f Filter) matches(entry map[string]interface{}) bool {
+	if lvl, err := zerolog.ParseLevel(stringField(entry, zerolog.LevelFieldName)); err == nil && lvl < f.Level {
+		return false
+	}
+	if f.RequestID != "" && stringField(entry, "request_id") != f.RequestID {
+		return false
+	}
+	if f.Session != "" && stringField(entry, "session") != f.Session && stringField(entry, "session_id") != f.Session {
+		return false
+	}
+	if f.Domain != "" && !matchesDomain(entryDomain(entry), f.Domain) {
+		return false
+	}
+	return true
+}
+
+// entryDomain extracts the target host from a "domain" or "url" field.
+func entryDomain(entry map[string]interface{}) string {
+	if d := stringField(entry, "domain"); d != "" {
+		return strings.ToLower(d)
+	}
+	if raw := stringField(entry, "url"); raw != "" {
+		if u, err := url.Parse(raw); err == nil {
+			return strings.ToLower(u.Hostname())
+		}
+	}
+	return ""
+}
+
+// matchesDomain reports whether host is domain or one of its subdomains.
+func matchesDomain(host, domain string) bool {
+	return host != "" && (host == domain || strings.HasSuffix(host, "."+domain))
+}
+
+func stringField(entry map[string]interface{}, key string) string {
+	s, _ := entry[key].(string)
+	return s
+}
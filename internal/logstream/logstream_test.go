@@ -0,0 +1,100 @@
+package logstream
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestBroker_Filters(t *testing.T) {
+	b := NewBroker(5)
+	logger := zerolog.New(b)
+
+	byRequest, _ := b.Subscribe(Filter{RequestID: "req-1", Level: zerolog.TraceLevel})
+	byDomain, _ := b.Subscribe(Filter{Domain: "Example.com", Level: zerolog.TraceLevel})
+	bySession, _ := b.Subscribe(Filter{Session: "sess-1", Level: zerolog.TraceLevel})
+	warnOnly, _ := b.Subscribe(Filter{Level: zerolog.WarnLevel})
+
+	logger.Info().Str("request_id", "req-1").Msg("request line")
+	logger.Info().Str("url", "https://www.example.com/page").Msg("domain line")
+	logger.Info().Str("domain", "notexample.com").Msg("other domain")
+	logger.Debug().Str("session_id", "sess-1").Msg("session line")
+	logger.Warn().Msg("warn line")
+
+	tests := []struct {
+		name string
+		sub  *Subscription
+		want []string
+	}{
+		{"request id", byRequest, []string{"request line"}},
+		{"domain", byDomain, []string{"domain line"}},
+		{"session", bySession, []string{"session line"}},
+		{"level", warnOnly, []string{"warn line"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for len(tt.sub.Lines()) > 0 {
+				got = append(got, string(<-tt.sub.Lines()))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Got %d lines, want %d: %v", len(got), len(tt.want), got)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("Line %d = %s, want it to contain %q", i, got[i], want)
+				}
+				if strings.HasSuffix(got[i], "\n") {
+					t.Errorf("Line %d should not end with a newline", i)
+				}
+			}
+		})
+	}
+}
+
+func TestBroker_DropsWhenFull(t *testing.T) {
+	b := NewBroker(1)
+	logger := zerolog.New(b)
+	sub, err := b.Subscribe(Filter{Level: zerolog.TraceLevel})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < subscriberBuffer+10; i++ {
+		logger.Info().Int("i", i).Msg("line")
+	}
+	if sub.Dropped() != 10 {
+		t.Errorf("Dropped() = %d, want 10", sub.Dropped())
+	}
+}
+
+func TestBroker_SubscriberLimitAndUnsubscribe(t *testing.T) {
+	b := NewBroker(1)
+	sub, err := b.Subscribe(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Subscribe(Filter{}); !errors.Is(err, ErrTooManySubscribers) {
+		t.Errorf("Expected ErrTooManySubscribers, got %v", err)
+	}
+
+	b.Unsubscribe(sub)
+	if _, ok := <-sub.Lines(); ok {
+		t.Error("Expected channel to be closed after Unsubscribe")
+	}
+	b.Unsubscribe(sub) // second call is a no-op
+
+	if _, err := b.Subscribe(Filter{}); err != nil {
+		t.Errorf("Expected slot to be free after Unsubscribe, got %v", err)
+	}
+}
+
+func TestBroker_NoSubscribers(t *testing.T) {
+	b := NewBroker(1)
+	n, err := b.Write([]byte("not json\n"))
+	if err != nil || n != 9 {
+		t.Errorf("Write() = %d, %v", n, err)
+	}
+}
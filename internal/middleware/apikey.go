@@ -2,6 +2,7 @@
 package middleware
 
 import (
+	"context"
 	"crypto/sha256"
 	"crypto/subtle"
 	"net/http"
@@ -20,11 +21,20 @@ import (
 // - Browser history
 // - Referrer headers (may leak to third-party sites)
 // - Proxy logs
-func APIKey(cfg *config.Config) func(http.Handler) http.Handler {
+//
+// API_KEY grants every command. Each scoped key grants only its listed
+// commands; the scope is attached to the request context for CommandAllowed,
+// and the non-command endpoints (/metrics, /logs/stream,
+// /admin/events, /docs) are checked
+// here against their admin.* pseudo-commands.
+func APIKey(cfg *config.Config, scoped ...*ScopedAPIKey) func(http.Handler) http.Handler {
 	// Pre-compute the hash of the expected API key for constant-time comparison.
 	// This ensures consistent comparison time regardless of input length,
 	// preventing timing attacks that could leak information about the key length.
 	expectedHash := sha256.Sum256([]byte(cfg.APIKey))
+	// With only scoped keys configured, an empty API_KEY must not let
+	// requests without a key through with full access
+	fullAccessKey := cfg.APIKey != "" || len(scoped) == 0
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -34,8 +44,8 @@ func APIKey(cfg *config.Config) func(http.Handler) http.Handler {
 				return
 			}
 
-			// Skip health endpoint - always accessible for load balancer health checks
-			if r.URL.Path == "/health" {
+			// Skip health endpoints - always accessible for load balancer health checks
+			if IsProbePath(r.URL.Path) {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -50,11 +60,28 @@ func APIKey(cfg *config.Config) func(http.Handler) http.Handler {
 			// 2. Using constant-time comparison for the hash values
 			// Even if the provided key is empty or much longer, comparison time is constant.
 			providedHash := sha256.Sum256([]byte(apiKey))
-			if subtle.ConstantTimeCompare(providedHash[:], expectedHash[:]) != 1 {
+			matched := subtle.ConstantTimeCompare(providedHash[:], expectedHash[:]) == 1 && fullAccessKey
+
+			// Every scoped key is compared, so timing does not reveal which matched
+			var scope *ScopedAPIKey
+			for _, k := range scoped {
+				if subtle.ConstantTimeCompare(providedHash[:], k.hash[:]) == 1 && !matched && scope == nil {
+					scope = k
+				}
+			}
+			if !matched && scope == nil {
 				writeErrorResponse(w, http.StatusUnauthorized, "Invalid or missing API key", time.Now())
 				return
 			}
 
+			if scope != nil {
+				if cmd := adminCommandForPath(r.URL.Path); cmd != "" && !scope.Allows(cmd) {
+					writeErrorResponse(w, http.StatusForbidden, "API key is not permitted to access "+r.URL.Path, time.Now())
+					return
+				}
+				r = r.WithContext(context.WithValue(r.Context(), apiKeyScopeKey{}, scope))
+			}
+
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Pseudo-commands for the non-command endpoints, so API key scopes can grant
+// or withhold them like any command. "admin.*" covers all of them.
+const (
+	CmdAdminMetrics = "admin.metrics" // GET /metrics
+	CmdAdminLogs    = "admin.logs"    // GET /logs/stream
+	CmdAdminEvents  = "admin.events"  // GET /admin/events
+	CmdAdminDocs    = "admin.docs"    // GET /docs
+)
+
+// ScopedAPIKey is one entry of an API_KEYS_FILE: an additional API key that
+// may only call the listed commands.
+type ScopedAPIKey struct {
+	Name string `yaml:"name" json:"name"`
+	Key  string `yaml:"key" json:"key"`
+	// Commands lists allowed commands. An entry ending in ".*" allows every
+	// command with that prefix (e.g. "sessions.*"); "*" allows everything.
+	Commands []string `yaml:"commands" json:"commands"`
+
+	hash [sha256.Size]byte
+}
+
+// Allows reports whether the key may call cmd.
+func (k *ScopedAPIKey) Allows(cmd string) bool {
+	for _, c := range k.Commands {
+		if c == "*" || c == cmd {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(c, "*"); ok && strings.HasSuffix(prefix, ".") && strings.HasPrefix(cmd, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadScopedAPIKeys reads an API_KEYS_FILE. Returns nil for an empty path.
+func LoadScopedAPIKeys(path string) ([]*ScopedAPIKey, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from operator configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys file: %w", err)
+	}
+	return ParseScopedAPIKeys(data)
+}
+
+// ParseScopedAPIKeys parses and validates an API keys document, a YAML (or
+// JSON) list of entries:
+//
+//   - name: indexer
+//     key: "a-long-random-secret"
+//     commands: [request.get]
+func ParseScopedAPIKeys(data []byte) ([]*ScopedAPIKey, error) {
+	var keys []*ScopedAPIKey
+	if err := yaml.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys file: %w", err)
+	}
+	seen := make(map[string]bool, len(keys))
+	for i, k := range keys {
+		if k == nil {
+			return nil, fmt.Errorf("API key entry %d is empty", i)
+		}
+		label := k.Name
+		if label == "" {
+			label = fmt.Sprintf("entry %d", i)
+		}
+		if len(k.Key) < 16 {
+			return nil, fmt.Errorf("API key %s: key must be at least 16 characters", label)
+		}
+		if seen[k.Key] {
+			return nil, fmt.Errorf("API key %s: duplicate key", label)
+		}
+		seen[k.Key] = true
+		if len(k.Commands) == 0 {
+			return nil, fmt.Errorf("API key %s: commands must not be empty", label)
+		}
+		for _, c := range k.Commands {
+			if c == "" || (strings.Contains(c, "*") && c != "*" && !strings.HasSuffix(c, ".*")) {
+				return nil, fmt.Errorf("API key %s: invalid command pattern %q", label, c)
+			}
+		}
+		k.hash = sha256.Sum256([]byte(k.Key))
+	}
+	return keys, nil
+}
+
+type apiKeyScopeKey struct{}
+
+// CommandAllowed reports whether the API key that authenticated the request
+// may call cmd. Requests authenticated with API_KEY, or made with
+// authentication disabled, may call everything.
+func CommandAllowed(ctx context.Context, cmd string) bool {
+	k, ok := ctx.Value(apiKeyScopeKey{}).(*ScopedAPIKey)
+	return !ok || k.Allows(cmd)
+}
+
+// APIKeyNameFromContext returns the name of the scoped key that
+// authenticated the request, or "".
+func APIKeyNameFromContext(ctx context.Context) string {
+	if k, ok := ctx.Value(apiKeyScopeKey{}).(*ScopedAPIKey); ok {
+		return k.Name
+	}
+	return ""
+}
+
+// adminCommandForPath maps non-command endpoints to their pseudo-command.
+func adminCommandForPath(path string) string {
+	switch path {
+	case "/metrics":
+		return CmdAdminMetrics
+	case "/logs/stream":
+		return CmdAdminLogs
+	case "/admin/events":
+		return CmdAdminEvents
+	case "/docs":
+		return CmdAdminDocs
+	}
+	return ""
+}
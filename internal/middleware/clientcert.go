@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+)
+
+// ClientCert returns middleware that requires a client certificate verified
+// against TLS_CLIENT_CA_FILE. The TLS handshake verifies certificates that
+// are presented; this middleware rejects requests without one, except for
+// the health endpoints so load balancer health checks keep working. With
+// TLS_CLIENT_AUTH=optional, or without a CA bundle, requests pass through.
+func ClientCert(cfg *config.Config) func(http.Handler) http.Handler {
+	required := cfg.TLSClientCAFile != "" && cfg.TLSClientAuth == "require"
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !required || IsProbePath(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if ClientCertName(r) == "" {
+				writeErrorResponse(w, http.StatusUnauthorized, "Client certificate required", time.Now())
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClientCertName returns the subject common name of the request's verified
+// client certificate, or "" when there is none.
+func ClientCertName(r *http.Request) string {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	leaf := r.TLS.VerifiedChains[0][0]
+	if leaf.Subject.CommonName != "" {
+		return leaf.Subject.CommonName
+	}
+	// Certificates identified only by a SAN still count as verified
+	return leaf.Subject.String() + "(" + leaf.SerialNumber.String() + ")"
+}
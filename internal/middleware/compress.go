@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+
+	// Fast levels: solution pages compress well already and latency matters
+	// more than the last few percent.
+	brotliLevel = 4
+	gzipLevel   = gzip.BestSpeed
+)
+
+// compressor is the part of the gzip and brotli writers the middleware uses.
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var compressorPools = map[string]*sync.Pool{
+	encodingBrotli: {New: func() any { return brotli.NewWriterLevel(io.Discard, brotliLevel) }},
+	encodingGzip: {New: func() any {
+		gz, _ := gzip.NewWriterLevel(io.Discard, gzipLevel)
+		return gz
+	}},
+}
+
+// incompressibleTypes are Content-Type prefixes not worth compressing,
+// either already compressed or streamed to clients that expect each flush
+// to arrive as sent.
+var incompressibleTypes = []string{
+	"image/", "video/", "audio/", "font/woff",
+	"application/gzip", "application/zip", "application/zstd",
+	"application/x-7z-compressed", "application/x-rar",
+	"text/event-stream",
+}
+
+// Compress returns middleware that compresses responses with brotli or gzip,
+// whichever the client's Accept-Encoding prefers (brotli on a tie). Output is
+// encoded as it is written, so a large solution is never buffered whole.
+// Responses shorter than minSize, already encoded, or of an incompressible
+// type are sent as is. A response the handler flushes is compressed whatever
+// its size, as more is on the way.
+func Compress(minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, encoding: encoding, minSize: minSize, status: http.StatusOK}
+			defer cw.close(r)
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding picks the supported coding with the highest quality in
+// an Accept-Encoding header, or "" when the client accepts neither.
+func negotiateEncoding(header string) string {
+	q := map[string]float64{}
+	wildcard := -1.0
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+		quality := 1.0
+		if v, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if coding == "*" {
+			wildcard = quality
+			continue
+		}
+		q[coding] = quality
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range []string{encodingBrotli, encodingGzip} {
+		quality, listed := q[coding]
+		if !listed {
+			quality = wildcard
+		}
+		if quality > bestQ {
+			best, bestQ = coding, quality
+		}
+	}
+	return best
+}
+
+// compressWriter holds back the first minSize bytes of a response to decide
+// whether to compress it, then either streams through an encoder or passes
+// the response through untouched.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+
+	status  int
+	buf     []byte
+	decided bool
+	enc     compressor
+}
+
+// WriteHeader records the status; it is sent once the writer has decided
+// whether to compress, since that changes the headers.
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.decided {
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	cw.status = code
+	if code == http.StatusNoContent || code == http.StatusNotModified || code < http.StatusOK {
+		cw.decide(false)
+	}
+}
+
+// Write implements http.ResponseWriter.
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.decided {
+		if !cw.compressible() {
+			cw.decide(false)
+		} else {
+			cw.buf = append(cw.buf, b...)
+			if len(cw.buf) < cw.minSize {
+				return len(b), nil
+			}
+			if err := cw.decide(true); err != nil {
+				return 0, err
+			}
+			return len(b), nil
+		}
+	}
+	if cw.enc != nil {
+		return cw.enc.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher, pushing what the encoder holds to the
+// client.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if err := cw.decide(cw.compressible()); err != nil {
+			return
+		}
+	}
+	if cw.enc != nil {
+		if err := cw.enc.Flush(); err != nil {
+			return
+		}
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// compressible reports whether the response headers allow compressing it.
+func (cw *compressWriter) compressible() bool {
+	h := cw.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := strings.ToLower(h.Get("Content-Type"))
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// decide sends the headers, starting the encoder when compress is set, and
+// writes out whatever was held back.
+func (cw *compressWriter) decide(compress bool) error {
+	cw.decided = true
+	h := cw.Header()
+	if compress {
+		h.Set("Content-Encoding", cw.encoding)
+		h.Del("Content-Length")
+		cw.enc = compressorPools[cw.encoding].Get().(compressor)
+		cw.enc.Reset(cw.ResponseWriter)
+	}
+	if cw.compressible() || compress {
+		h.Add("Vary", "Accept-Encoding")
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	buf := cw.buf
+	cw.buf = nil
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// close finishes the response: a short body still held back is sent as
+// is, and the encoder's trailer is written before it returns to its pool.
+func (cw *compressWriter) close(r *http.Request) {
+	if !cw.decided {
+		// Nothing written at all leaves the status to the server, as usual
+		if cw.status == http.StatusOK && len(cw.buf) == 0 {
+			return
+		}
+		cw.decide(false)
+		return
+	}
+	if cw.enc == nil {
+		return
+	}
+	if err := cw.enc.Close(); err != nil {
+		log.Ctx(r.Context()).Debug().Err(err).Str("encoding", cw.encoding).Msg("Failed to finish compressed response")
+	}
+	cw.enc.Reset(io.Discard)
+	compressorPools[cw.encoding].Put(cw.enc)
+	cw.enc = nil
+}
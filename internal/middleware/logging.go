@@ -13,7 +13,7 @@ import (
 
 // infraLogPaths holds monitoring endpoints whose request logs are demoted to
 // debug, so health polling doesn't spam the default info log (#14).
-var infraLogPaths = map[string]bool{"/health": true}
+var infraLogPaths = probePaths
 
 // requestLogLevel returns the log level for a completed request. Monitoring
 // endpoints (see infraLogPaths) are demoted to debug; everything else stays at
@@ -112,6 +112,11 @@ func (rw *responseWriter) Flush() {
 	}
 }
 
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Logging returns middleware that logs request details.
 // Fix #15, #16: Masks IP addresses and sanitizes URLs in logs for privacy.
 func Logging(next http.Handler) http.Handler {
@@ -127,12 +132,16 @@ func Logging(next http.Handler) http.Handler {
 		// Log after completion
 		duration := time.Since(start)
 
-		log.WithLevel(requestLogLevel(r.URL.Path)).
+		event := log.WithLevel(requestLogLevel(r.URL.Path)).
 			Str("method", r.Method).
 			Str("path", sanitizeURLForLogging(r.URL.String())).
 			Str("remote_addr", maskIP(r.RemoteAddr)).
 			Int("status", wrapped.statusCode).
 			Dur("duration", duration).
-			Msg("Request completed")
+			Str("request_id", RequestIDFromContext(r.Context()))
+		if name := ClientCertName(r); name != "" {
+			event = event.Str("client_cert", name)
+		}
+		event.Msg("Request completed")
 	})
 }
@@ -1,12 +1,23 @@
 package middleware
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/andybalholm/brotli"
 	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 
 	"github.com/Rorqualx/flaresolverr-go/internal/config"
 )
@@ -100,6 +111,7 @@ func TestRequestLogLevel(t *testing.T) {
 		want zerolog.Level
 	}{
 		{"health is demoted to debug", "/health", zerolog.DebugLevel},
+		{"readyz is demoted to debug", "/readyz", zerolog.DebugLevel},
 		{"v1 stays info", "/v1", zerolog.InfoLevel},
 		{"index stays info", "/", zerolog.InfoLevel},
 		{"unknown path stays info", "/whatever", zerolog.InfoLevel},
@@ -374,6 +386,29 @@ func TestRateLimiterDifferentIPs(t *testing.T) {
 	}
 }
 
+func TestRateLimiterSetRate(t *testing.T) {
+	rl := NewRateLimiter(10, time.Second, false)
+	defer rl.Close()
+
+	rl.Allow("127.0.0.1")
+	rl.SetRate(2)
+
+	// The client that already had 9 tokens left is cut down to the new rate
+	if !rl.Allow("127.0.0.1") {
+		t.Error("request within the new rate should be allowed")
+	}
+	if rl.Allow("127.0.0.1") {
+		t.Error("request above the new rate should be blocked")
+	}
+
+	// New clients get the new rate
+	rl.Allow("192.168.1.1")
+	rl.Allow("192.168.1.1")
+	if rl.Allow("192.168.1.1") {
+		t.Error("new client should be limited to the new rate")
+	}
+}
+
 // ==================== APIKey Middleware Tests ====================
 
 func TestAPIKeyMiddlewareDisabled(t *testing.T) {
@@ -542,6 +577,14 @@ func TestAPIKeyMiddlewareHealthEndpointBypass(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status 200, got %d", w.Code)
 	}
+
+	for _, path := range []string{"/livez", "/readyz"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("%s should bypass API key authentication, got %d", path, w.Code)
+		}
+	}
 }
 
 func TestAPIKeyMiddlewareHeaderPrefersOverQuery(t *testing.T) {
@@ -638,3 +681,448 @@ func TestAPIKeyMiddlewareConstantTimeComparison(t *testing.T) {
 		}
 	}
 }
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var seen string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name     string
+		header   string
+		wantEcho bool
+	}{
+		{"generated when missing", "", false},
+		{"client id honored", "client-req_1.2", true},
+		{"invalid id replaced", "bad id\r\nX-Injected: 1", false},
+		{"overlong id replaced", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			got := w.Header().Get(RequestIDHeader)
+			if got == "" || got != seen {
+				t.Fatalf("Response ID %q does not match context ID %q", got, seen)
+			}
+			if tt.wantEcho && got != tt.header {
+				t.Errorf("Expected client ID %q to be echoed, got %q", tt.header, got)
+			}
+			if !tt.wantEcho && got == tt.header {
+				t.Errorf("Expected generated ID, got client value %q", got)
+			}
+		})
+	}
+}
+
+func TestRequestIDContextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	prev := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { log.Logger = prev })
+
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Ctx(r.Context()).Info().Msg("solving")
+	}))
+	req := httptest.NewRequest("POST", "/v1", nil)
+	req.Header.Set(RequestIDHeader, "caller-42")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got := buf.String(); !strings.Contains(got, `"request_id":"caller-42"`) {
+		t.Errorf("log line = %q, want the request ID", got)
+	}
+}
+
+func TestParseScopedAPIKeys(t *testing.T) {
+	keys, err := ParseScopedAPIKeys([]byte(`
+- name: indexer
+  key: "indexer-key-0123456789"
+  commands: [request.get]
+- name: ops
+  key: "ops-key-0123456789abc"
+  commands: ["sessions.*", "admin.*"]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 2 || keys[0].Name != "indexer" || keys[1].Commands[0] != "sessions.*" {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+
+	bad := map[string]string{
+		"short key":     `[{name: a, key: short, commands: [request.get]}]`,
+		"no commands":   `[{name: a, key: "0123456789abcdef"}]`,
+		"bad wildcard":  `[{name: a, key: "0123456789abcdef", commands: ["sessions*"]}]`,
+		"duplicate key": `[{key: "0123456789abcdef", commands: ["*"]}, {key: "0123456789abcdef", commands: ["*"]}]`,
+		"not a list":    `name: a`,
+	}
+	for name, doc := range bad {
+		if _, err := ParseScopedAPIKeys([]byte(doc)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestScopedAPIKeyAllows(t *testing.T) {
+	k := &ScopedAPIKey{Commands: []string{"request.get", "sessions.*"}}
+	for cmd, want := range map[string]bool{
+		"request.get":     true,
+		"request.post":    false,
+		"sessions.create": true,
+		"sessions":        false,
+		"admin.metrics":   false,
+	} {
+		if got := k.Allows(cmd); got != want {
+			t.Errorf("Allows(%q) = %v, want %v", cmd, got, want)
+		}
+	}
+	if !(&ScopedAPIKey{Commands: []string{"*"}}).Allows("admin.logs") {
+		t.Error("Expected * to allow everything")
+	}
+}
+
+func TestAPIKeyMiddlewareScopedKey(t *testing.T) {
+	cfg := &config.Config{
+		APIKeyEnabled: true,
+		APIKey:        "full-access-key-12345",
+	}
+	keys, err := ParseScopedAPIKeys([]byte(`[{name: indexer, key: "indexer-key-0123456789", commands: [request.get]}]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var getAllowed, createAllowed bool
+	var name string
+	handler := APIKey(cfg, keys...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		getAllowed = CommandAllowed(r.Context(), "request.get")
+		createAllowed = CommandAllowed(r.Context(), "sessions.create")
+		name = APIKeyNameFromContext(r.Context())
+	}))
+
+	serve := func(path, key string) int {
+		req := httptest.NewRequest("POST", path, nil)
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := serve("/v1", "indexer-key-0123456789"); code != http.StatusOK {
+		t.Fatalf("scoped key: expected 200, got %d", code)
+	}
+	if !getAllowed || createAllowed || name != "indexer" {
+		t.Errorf("scoped key: get=%v create=%v name=%q", getAllowed, createAllowed, name)
+	}
+
+	if code := serve("/v1", "full-access-key-12345"); code != http.StatusOK {
+		t.Fatalf("full key: expected 200, got %d", code)
+	}
+	if !getAllowed || !createAllowed || name != "" {
+		t.Errorf("full key: get=%v create=%v name=%q", getAllowed, createAllowed, name)
+	}
+
+	if code := serve("/metrics", "indexer-key-0123456789"); code != http.StatusForbidden {
+		t.Errorf("scoped key on /metrics: expected 403, got %d", code)
+	}
+	if code := serve("/v1", "unknown-key-0123456789"); code != http.StatusUnauthorized {
+		t.Errorf("unknown key: expected 401, got %d", code)
+	}
+}
+
+// TestAPIKeyMiddlewareScopedOnly verifies that an empty API_KEY does not admit
+// keyless requests once scoped keys are configured.
+func TestAPIKeyMiddlewareScopedOnly(t *testing.T) {
+	cfg := &config.Config{APIKeyEnabled: true}
+	keys, err := ParseScopedAPIKeys([]byte(`[{key: "indexer-key-0123456789", commands: [request.get]}]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := APIKey(cfg, keys...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest("POST", "/v1", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a request without a key, got %d", w.Code)
+	}
+}
+
+func TestClientCertMiddleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	verified := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{
+		{Subject: pkix.Name{CommonName: "worker-1"}, SerialNumber: big.NewInt(7)},
+	}}}
+	serve := func(cfg *config.Config, path string, state *tls.ConnectionState) int {
+		req := httptest.NewRequest("POST", path, nil)
+		req.TLS = state
+		w := httptest.NewRecorder()
+		ClientCert(cfg)(ok).ServeHTTP(w, req)
+		return w.Code
+	}
+
+	required := &config.Config{TLSClientCAFile: "/certs/ca.pem", TLSClientAuth: "require"}
+	if code := serve(required, "/v1", nil); code != http.StatusUnauthorized {
+		t.Errorf("without certificate: status %d, want 401", code)
+	}
+	if code := serve(required, "/v1", &tls.ConnectionState{}); code != http.StatusUnauthorized {
+		t.Errorf("TLS without certificate: status %d, want 401", code)
+	}
+	if code := serve(required, "/v1", verified); code != http.StatusOK {
+		t.Errorf("verified certificate: status %d, want 200", code)
+	}
+	if code := serve(required, "/health", nil); code != http.StatusOK {
+		t.Errorf("/health without certificate: status %d, want 200", code)
+	}
+
+	optional := &config.Config{TLSClientCAFile: "/certs/ca.pem", TLSClientAuth: "optional"}
+	if code := serve(optional, "/v1", nil); code != http.StatusOK {
+		t.Errorf("optional mode without certificate: status %d, want 200", code)
+	}
+
+	req := httptest.NewRequest("POST", "/v1", nil)
+	req.TLS = verified
+	if name := ClientCertName(req); name != "worker-1" {
+		t.Errorf("ClientCertName() = %q, want worker-1", name)
+	}
+}
+
+func TestRestrictSources(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	api, err := NewSourceAllowlist([]string{"10.0.0.0/8", "192.168.1.5", "fd00::/8"}, false)
+	if err != nil {
+		t.Fatalf("NewSourceAllowlist() error = %v", err)
+	}
+	metrics, err := NewSourceAllowlist([]string{"172.16.0.0/12"}, false)
+	if err != nil {
+		t.Fatalf("NewSourceAllowlist() error = %v", err)
+	}
+	handler := RestrictSources(api, map[string]*SourceAllowlist{"/metrics": metrics})(ok)
+	serve := func(path, remoteAddr, xff string) int {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = remoteAddr
+		if xff != "" {
+			req.Header.Set("X-Forwarded-For", xff)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	tests := []struct {
+		path, remoteAddr, xff string
+		want                  int
+	}{
+		{"/v1", "10.1.2.3:5000", "", http.StatusOK},
+		{"/v1", "192.168.1.5:5000", "", http.StatusOK},
+		{"/v1", "[fd00::1]:5000", "", http.StatusOK},
+		{"/v1", "192.168.1.6:5000", "", http.StatusForbidden},
+		{"/v1", "203.0.113.9:5000", "10.1.2.3", http.StatusForbidden}, // forwarding headers not trusted
+		{"/metrics", "172.20.0.4:5000", "", http.StatusOK},
+		{"/metrics", "10.1.2.3:5000", "", http.StatusForbidden}, // the metrics list replaces the API list
+		{"/health", "203.0.113.9:5000", "", http.StatusOK},
+		{"/livez", "203.0.113.9:5000", "", http.StatusOK},
+		{"/readyz", "203.0.113.9:5000", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if code := serve(tt.path, tt.remoteAddr, tt.xff); code != tt.want {
+			t.Errorf("%s from %s (XFF %q): status %d, want %d", tt.path, tt.remoteAddr, tt.xff, code, tt.want)
+		}
+	}
+
+	trusted, _ := NewSourceAllowlist([]string{"10.0.0.0/8"}, true)
+	req := httptest.NewRequest("GET", "/v1", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	req.Header.Set("X-Forwarded-For", "10.9.8.7, 127.0.0.1")
+	if !trusted.Allows(req) {
+		t.Error("with TRUST_PROXY the forwarded client address should be checked")
+	}
+
+	if a, err := NewSourceAllowlist(nil, false); a != nil || err != nil {
+		t.Errorf("empty allowlist = %v, %v; want nil, nil", a, err)
+	}
+	if _, err := NewSourceAllowlist([]string{"10.0.0.0/40"}, false); err == nil {
+		t.Error("invalid CIDR: expected error")
+	}
+
+	w := httptest.NewRecorder()
+	RestrictSources(nil, nil)(ok).ServeHTTP(w, httptest.NewRequest("GET", "/v1", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("nil allowlist: status %d, want 200", w.Code)
+	}
+}
+
+func TestBasicAuth(t *testing.T) {
+	handler := BasicAuth("admin", "ops", "s3cret")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name       string
+		user, pass string
+		set        bool
+		want       int
+	}{
+		{"valid credentials", "ops", "s3cret", true, http.StatusNoContent},
+		{"wrong password", "ops", "guess", true, http.StatusUnauthorized},
+		{"wrong user", "root", "s3cret", true, http.StatusUnauthorized},
+		{"no credentials", "", "", false, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.set {
+				req.SetBasicAuth(tt.user, tt.pass)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && !strings.HasPrefix(w.Header().Get("WWW-Authenticate"), "Basic ") {
+				t.Error("401 without a basic auth challenge")
+			}
+		})
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate, GZIP", "gzip"},
+		{"br, gzip", "br"},
+		{"gzip, br;q=0.5", "gzip"},
+		{"gzip;q=0", ""},
+		{"br;q=0, gzip;q=0.1", "gzip"},
+		{"*", "br"},
+		{"*;q=0.2, br;q=0", "gzip"},
+		{"identity", ""},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.header); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat(`{"status":"ok","html":"<div>content</div>"}`, 200)
+	serve := func(acceptEncoding, contentType, body string) *httptest.ResponseRecorder {
+		handler := Compress(1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			// Written in pieces, as the streamed solution response is
+			for i := 0; i < len(body); i += 100 {
+				w.Write([]byte(body[i:min(i+100, len(body))]))
+			}
+		}))
+		r := httptest.NewRequest("POST", "/v1", nil)
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) string {
+		t.Helper()
+		var reader io.Reader
+		switch w.Header().Get("Content-Encoding") {
+		case "gzip":
+			gz, err := gzip.NewReader(w.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader = gz
+		case "br":
+			reader = brotli.NewReader(w.Body)
+		default:
+			reader = w.Body
+		}
+		body, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	for _, encoding := range []string{"gzip", "br"} {
+		t.Run(encoding, func(t *testing.T) {
+			w := serve(encoding, "application/json", large)
+			if got := w.Header().Get("Content-Encoding"); got != encoding {
+				t.Fatalf("Content-Encoding = %q, want %q", got, encoding)
+			}
+			if w.Header().Get("Content-Length") != "" || w.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("headers = %v, want no Content-Length and Vary: Accept-Encoding", w.Header())
+			}
+			if w.Body.Len() >= len(large) {
+				t.Errorf("compressed body is %d bytes, want less than %d", w.Body.Len(), len(large))
+			}
+			if got := decode(t, w); got != large {
+				t.Error("decompressed body differs from the original")
+			}
+		})
+	}
+
+	t.Run("small", func(t *testing.T) {
+		w := serve("gzip", "application/json", `{"status":"ok"}`)
+		if got := w.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("Content-Encoding = %q, want none below the minimum size", got)
+		}
+		if w.Body.String() != `{"status":"ok"}` || w.Header().Get("Content-Length") == "" {
+			t.Errorf("small response = %q with headers %v, want it passed through", w.Body.String(), w.Header())
+		}
+	})
+
+	t.Run("incompressible", func(t *testing.T) {
+		w := serve("gzip", "image/png", large)
+		if got := w.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("Content-Encoding = %q, want none for an image", got)
+		}
+		if w.Body.String() != large {
+			t.Error("image body was altered")
+		}
+	})
+
+	t.Run("not accepted", func(t *testing.T) {
+		w := serve("", "application/json", large)
+		if got := w.Header().Get("Content-Encoding"); got != "" || w.Body.String() != large {
+			t.Errorf("Content-Encoding = %q, want the body passed through", got)
+		}
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		handler := Compress(1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"status":"ok"}`))
+			if err := http.NewResponseController(w).Flush(); err != nil {
+				t.Errorf("Flush() error = %v", err)
+			}
+			w.Write([]byte(large))
+		}))
+		r := httptest.NewRequest("POST", "/v1", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if !w.Flushed {
+			t.Error("Flush() did not reach the underlying writer")
+		}
+		if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+			t.Errorf("Content-Encoding = %q, want gzip for a flushed response", got)
+		}
+		if got := decode(t, w); got != `{"status":"ok"}`+large {
+			t.Error("decompressed body differs from the original")
+		}
+	})
+}
@@ -0,0 +1,15 @@
+package middleware
+
+// probePaths are the health endpoints orchestrators poll. They stay open
+// without API keys, client certificates or an allowed source address so
+// load balancers and Kubernetes probes keep working.
+var probePaths = map[string]bool{
+	"/health": true,
+	"/livez":  true,
+	"/readyz": true,
+}
+
+// IsProbePath reports whether path is a health endpoint.
+func IsProbePath(path string) bool {
+	return probePaths[path]
+}
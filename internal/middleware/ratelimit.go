@@ -94,6 +94,20 @@ func (rl *RateLimiter) Allow(ip string) bool {
 	return false
 }
 
+// SetRate changes the requests allowed per window. Clients keep their
+// current window, with any tokens above the new rate taken away.
+func (rl *RateLimiter) SetRate(rate int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.rate = rate
+	for _, c := range rl.clients {
+		if c.tokens > rate-1 {
+			c.tokens = rate - 1
+		}
+	}
+}
+
 // cleanupRoutine removes stale client entries.
 func (rl *RateLimiter) cleanupRoutine() {
 	ticker := time.NewTicker(rl.cleanup)
@@ -198,6 +212,11 @@ func (m *RateLimiterMiddleware) Close() {
 	}
 }
 
+// SetRate changes the requests allowed per minute per IP.
+func (m *RateLimiterMiddleware) SetRate(requestsPerMinute int) {
+	m.limiter.SetRate(requestsPerMinute)
+}
+
 // Handler returns the middleware handler function.
 func (m *RateLimiterMiddleware) Handler() func(http.Handler) http.Handler {
 	return m.handler
@@ -287,6 +306,12 @@ func normalizeIP(ipStr string) string {
 	return ip.String()
 }
 
+// ClientIP returns the client address of r as the rate limiter sees it,
+// honouring X-Forwarded-For and X-Real-IP only when trustProxy is set.
+func ClientIP(r *http.Request, trustProxy bool) string {
+	return getClientIP(r, trustProxy)
+}
+
 // getClientIP extracts the client IP from the request.
 // When trustProxy is false (default), only RemoteAddr is used to prevent IP spoofing.
 // When trustProxy is true, X-Forwarded-For and X-Real-IP headers are checked first.
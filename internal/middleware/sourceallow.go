@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SourceAllowlist is a set of source networks allowed to reach a listener.
+type SourceAllowlist struct {
+	nets       []*net.IPNet
+	trustProxy bool
+}
+
+// NewSourceAllowlist parses CIDRs and bare IP addresses. With trustProxy the
+// client address is taken from X-Forwarded-For/X-Real-IP, as for rate
+// limiting. Returns nil when cidrs is empty.
+func NewSourceAllowlist(cidrs []string, trustProxy bool) (*SourceAllowlist, error) {
+	a := &SourceAllowlist{trustProxy: trustProxy}
+	for _, entry := range cidrs {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid source address %q", entry)
+			}
+			bits := 128
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 32
+			}
+			a.nets = append(a.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source CIDR %q: %w", entry, err)
+		}
+		a.nets = append(a.nets, ipNet)
+	}
+	if len(a.nets) == 0 {
+		return nil, nil
+	}
+	return a, nil
+}
+
+// Allows reports whether r comes from an allowed network.
+func (a *SourceAllowlist) Allows(r *http.Request) bool {
+	ip := net.ParseIP(getClientIP(r, a.trustProxy))
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range a.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// RestrictSources returns middleware that rejects requests from outside
+// allowed with 403. perPath replaces allowed for individual paths, e.g.
+// /metrics scraped from a monitoring network. The health endpoints are always
+// reachable so liveness probes keep working. A nil allowlist admits everyone.
+func RestrictSources(allowed *SourceAllowlist, perPath map[string]*SourceAllowlist) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			list := allowed
+			if l := perPath[r.URL.Path]; l != nil {
+				list = l
+			}
+			if list == nil || IsProbePath(r.URL.Path) || list.Allows(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			log.Warn().
+				Str("remote_addr", maskIP(r.RemoteAddr)).
+				Str("path", r.URL.Path).
+				Msg("Request from a source outside the allowlist rejected")
+			writeErrorResponse(w, http.StatusForbidden, "Forbidden", time.Now())
+		})
+	}
+}
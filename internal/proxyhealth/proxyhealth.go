@@ -0,0 +1,330 @@
+// Package proxyhealth probes the configured proxies in the background:
+// whether they connect, how fast, which IP they exit from and whether
+// Cloudflare challenges that IP. Proxies that keep failing are reported dead
+// so the proxy pools leave them out of rotation until they recover.
+package proxyhealth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/security"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// Cloudflare reputation of a proxy's egress IP, from the canary URL.
+const (
+	ReputationClean      = "clean"      // The canary page loaded
+	ReputationChallenged = "challenged" // Cloudflare served a challenge
+	ReputationBlocked    = "blocked"    // Cloudflare refused the IP outright
+	ReputationUnknown    = "unknown"    // No canary configured, or the check failed
+)
+
+const (
+	// maxProbeBody bounds how much of a probe response is read.
+	maxProbeBody = 64 * 1024
+
+	// probeConcurrency bounds the proxies checked at once.
+	probeConcurrency = 8
+)
+
+// Config contains Checker settings.
+type Config struct {
+	Interval      time.Duration // Time between rounds of checks
+	Timeout       time.Duration // Bound on each probe request
+	IPURL         string        // Returns the caller's IP, as text or {"ip": "..."}
+	CanaryURL     string        // Cloudflare-protected page; empty skips the reputation check
+	FailThreshold int           // Consecutive failures before a proxy is dead
+}
+
+// Checker probes a fixed set of proxies. A nil Checker reports every proxy
+// healthy.
+type Checker struct {
+	cfg     Config
+	proxies []*types.Proxy
+
+	mu    sync.RWMutex
+	state map[string]*types.ProxyStatus
+
+	stop      chan struct{}
+	done      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// New creates a Checker for the given proxies, deduplicated. Returns nil when
+// there are none.
+func New(cfg Config, proxies []*types.Proxy) *Checker {
+	c := &Checker{
+		cfg:   cfg,
+		state: make(map[string]*types.ProxyStatus),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	if c.cfg.FailThreshold < 1 {
+		c.cfg.FailThreshold = 1
+	}
+	for _, p := range proxies {
+		if p == nil || p.URL == "" {
+			continue
+		}
+		k := key(p)
+		if _, dup := c.state[k]; dup {
+			continue
+		}
+		c.proxies = append(c.proxies, p)
+		c.state[k] = &types.ProxyStatus{Proxy: security.RedactProxy(p.URL, p.Username), Alive: true, Reputation: ReputationUnknown}
+	}
+	if len(c.proxies) == 0 {
+		return nil
+	}
+	return c
+}
+
+// Start checks every proxy now and then every Interval until Close.
+func (c *Checker) Start() {
+	if c == nil {
+		return
+	}
+	c.startOnce.Do(func() {
+		go c.run()
+	})
+}
+
+// Close stops the background checks and waits for a running round to end.
+func (c *Checker) Close() {
+	if c == nil {
+		return
+	}
+	c.stopOnce.Do(func() {
+		close(c.stop)
+		// Never started: nothing runs, and Start stays a no-op
+		c.startOnce.Do(func() { close(c.done) })
+		<-c.done
+	})
+}
+
+func (c *Checker) run() {
+	defer close(c.done)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-c.stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		c.CheckAll(ctx)
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll probes every proxy once.
+func (c *Checker) CheckAll(ctx context.Context) {
+	sem := make(chan struct{}, probeConcurrency)
+	var wg sync.WaitGroup
+	for _, p := range c.proxies {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+		wg.Add(1)
+		go func(p *types.Proxy) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			c.record(p, c.probe(ctx, p))
+		}(p)
+	}
+	wg.Wait()
+}
+
+// probeResult is the outcome of checking one proxy.
+type probeResult struct {
+	err        error
+	latency    time.Duration
+	egressIP   string
+	reputation string
+}
+
+// probe fetches the IP URL, then the canary URL, through the proxy.
+func (c *Checker) probe(ctx context.Context, p *types.Proxy) probeResult {
+	client, err := c.client(p)
+	if err != nil {
+		return probeResult{err: err}
+	}
+	defer client.CloseIdleConnections()
+
+	start := time.Now()
+	status, body, _, err := c.get(ctx, client, c.cfg.IPURL)
+	if err != nil {
+		return probeResult{err: err}
+	}
+	res := probeResult{latency: time.Since(start), reputation: ReputationUnknown}
+	if status != http.StatusOK {
+		return probeResult{err: fmt.Errorf("IP check returned HTTP %d", status)}
+	}
+	res.egressIP = ParseIP(body)
+
+	if c.cfg.CanaryURL != "" {
+		status, body, header, err := c.get(ctx, client, c.cfg.CanaryURL)
+		if err != nil {
+			log.Debug().Err(err).Str("proxy", security.RedactProxy(p.URL, p.Username)).Msg("Proxy canary check failed")
+		} else {
+			res.reputation = classify(status, header, body)
+		}
+	}
+	return res
+}
+
+// client returns an HTTP client that sends everything through p.
+func (c *Checker) client(p *types.Proxy) (*http.Client, error) {
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("cannot probe %s proxies", u.Scheme)
+	}
+	if p.Username != "" {
+		u.User = url.UserPassword(p.Username, p.Password)
+	}
+	transport := &http.Transport{
+		Proxy:               http.ProxyURL(u),
+		DialContext:         (&net.Dialer{Timeout: c.cfg.Timeout}).DialContext,
+		TLSHandshakeTimeout: c.cfg.Timeout,
+		DisableKeepAlives:   true,
+	}
+	return &http.Client{Transport: transport, Timeout: c.cfg.Timeout}, nil
+}
+
+func (c *Checker) get(ctx context.Context, client *http.Client, target string) (int, []byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	// The canary should see an ordinary browser, not a Go client
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBody))
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return resp.StatusCode, body, resp.Header, nil
+}
+
+// ParseIP reads an IP from a plain-text or {"ip": "..."} body.
+func ParseIP(body []byte) string {
+	text := strings.TrimSpace(string(body))
+	var js struct {
+		IP string `json:"ip"`
+	}
+	if json.Unmarshal(body, &js) == nil && js.IP != "" {
+		text = js.IP
+	}
+	if ip := net.ParseIP(text); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// classify judges the canary response. Cloudflare marks challenge pages with
+// the cf-mitigated header; block pages only by their content.
+func classify(status int, header http.Header, body []byte) string {
+	if strings.EqualFold(header.Get("cf-mitigated"), "challenge") {
+		return ReputationChallenged
+	}
+	page := string(body)
+	switch {
+	case strings.Contains(page, "cf-error-details") || strings.Contains(page, "Sorry, you have been blocked"):
+		return ReputationBlocked
+	case strings.Contains(page, "challenge-platform") || strings.Contains(page, "Just a moment..."):
+		return ReputationChallenged
+	case status >= 200 && status < 400:
+		return ReputationClean
+	}
+	return ReputationUnknown
+}
+
+// record applies a probe result to the proxy's status.
+func (c *Checker) record(p *types.Proxy, res probeResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st := c.state[key(p)]
+	st.LastChecked = time.Now().UnixMilli()
+	if res.err != nil {
+		st.ConsecutiveFailures++
+		st.LastError = res.err.Error()
+		st.LatencyMs = 0
+		if st.Alive && st.ConsecutiveFailures >= c.cfg.FailThreshold {
+			st.Alive = false
+			log.Warn().Err(res.err).Str("proxy", st.Proxy).Msg("Proxy marked dead, removed from rotation")
+		}
+		return
+	}
+	if !st.Alive {
+		log.Info().Str("proxy", st.Proxy).Msg("Proxy recovered, back in rotation")
+	}
+	st.Alive = true
+	st.ConsecutiveFailures = 0
+	st.LastError = ""
+	st.LatencyMs = res.latency.Milliseconds()
+	st.EgressIP = res.egressIP
+	st.Reputation = res.reputation
+}
+
+// Healthy reports whether p may be used. Proxies the Checker does not know
+// and proxies not yet checked are healthy.
+func (c *Checker) Healthy(p *types.Proxy) bool {
+	if c == nil || p == nil {
+		return true
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	st, ok := c.state[key(p)]
+	return !ok || st.Alive
+}
+
+// Statuses returns the latest status of every proxy, in configuration order.
+func (c *Checker) Statuses() []types.ProxyStatus {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]types.ProxyStatus, 0, len(c.proxies))
+	for _, p := range c.proxies {
+		out = append(out, *c.state[key(p)])
+	}
+	return out
+}
+
+// key identifies a proxy: gateways that pick the exit by username are
+// distinct proxies.
+func key(p *types.Proxy) string {
+	return p.URL + "\x00" + p.Username
+}
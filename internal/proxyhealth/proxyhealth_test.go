@@ -0,0 +1,186 @@
+package proxyhealth
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// newTestProxy starts a forward HTTP proxy that requires user:secret and
+// answers the IP and canary URLs itself.
+func newTestProxy(t *testing.T) *httptest.Server {
+	t.Helper()
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:secret"))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") != want {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		switch r.URL.Host + r.URL.Path {
+		case "ip.test/":
+			_, _ = w.Write([]byte(`{"ip": "203.0.113.7"}`))
+		case "canary.test/":
+			w.Header().Set("cf-mitigated", "challenge")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("<title>Just a moment...</title>"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// closedAddr returns a loopback address nothing listens on.
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+func testConfig() Config {
+	return Config{
+		Interval:      time.Hour,
+		Timeout:       5 * time.Second,
+		IPURL:         "http://ip.test/",
+		CanaryURL:     "http://canary.test/",
+		FailThreshold: 2,
+	}
+}
+
+func TestNewWithoutProxies(t *testing.T) {
+	if c := New(testConfig(), []*types.Proxy{nil, {Pool: "p"}}); c != nil {
+		t.Fatal("New() without proxy URLs should return nil")
+	}
+	var c *Checker
+	if !c.Healthy(&types.Proxy{URL: "http://a:1"}) {
+		t.Error("nil Checker should report proxies healthy")
+	}
+	c.Start()
+	c.Close()
+}
+
+func TestCheckAllReportsProxyDetails(t *testing.T) {
+	proxy := newTestProxy(t)
+	p := &types.Proxy{URL: proxy.URL, Username: "user", Password: "secret"}
+	c := New(testConfig(), []*types.Proxy{p, {URL: proxy.URL, Username: "user", Password: "secret"}})
+	c.CheckAll(context.Background())
+
+	statuses := c.Statuses()
+	if len(statuses) != 1 {
+		t.Fatalf("Statuses() has %d entries, want duplicates merged into 1", len(statuses))
+	}
+	st := statuses[0]
+	if !st.Alive || st.EgressIP != "203.0.113.7" || st.Reputation != ReputationChallenged || st.LastChecked == 0 {
+		t.Errorf("status = %+v, want alive, egress 203.0.113.7, challenged", st)
+	}
+	if st.Proxy == "" || st.Proxy == proxy.URL {
+		t.Errorf("status proxy = %q, want the URL with the username", st.Proxy)
+	}
+}
+
+func TestDeadProxyLeavesAndRejoinsRotation(t *testing.T) {
+	dead := &types.Proxy{URL: "http://" + closedAddr(t)}
+	c := New(testConfig(), []*types.Proxy{dead})
+
+	c.CheckAll(context.Background())
+	if !c.Healthy(dead) {
+		t.Fatal("proxy dead after one failure, want FailThreshold=2 failures")
+	}
+	c.CheckAll(context.Background())
+	if c.Healthy(dead) {
+		t.Fatal("proxy still healthy after reaching FailThreshold")
+	}
+	st := c.Statuses()[0]
+	if st.Alive || st.ConsecutiveFailures != 2 || st.LastError == "" {
+		t.Errorf("status = %+v, want dead with 2 failures and an error", st)
+	}
+
+	// One success brings it back
+	c.record(dead, probeResult{latency: 40 * time.Millisecond, egressIP: "198.51.100.1", reputation: ReputationClean})
+	if !c.Healthy(dead) {
+		t.Error("proxy not healthy after a successful check")
+	}
+	if st := c.Statuses()[0]; st.ConsecutiveFailures != 0 || st.LatencyMs != 40 || st.LastError != "" {
+		t.Errorf("status after recovery = %+v", st)
+	}
+
+	if !c.Healthy(&types.Proxy{URL: "http://unknown:1"}) {
+		t.Error("proxies the checker does not know should be healthy")
+	}
+}
+
+func TestWrongCredentialsFail(t *testing.T) {
+	proxy := newTestProxy(t)
+	p := &types.Proxy{URL: proxy.URL, Username: "user", Password: "wrong"}
+	cfg := testConfig()
+	cfg.FailThreshold = 1
+	c := New(cfg, []*types.Proxy{p})
+	c.CheckAll(context.Background())
+	if c.Healthy(p) {
+		t.Error("proxy rejecting the credentials should be dead")
+	}
+}
+
+func TestStartAndClose(t *testing.T) {
+	proxy := newTestProxy(t)
+	p := &types.Proxy{URL: proxy.URL, Username: "user", Password: "secret"}
+	c := New(testConfig(), []*types.Proxy{p})
+	c.Start()
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Statuses()[0].LastChecked == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Start() did not check the proxy")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Close()
+	c.Close()
+
+	// Closing a checker that never started must not block
+	New(testConfig(), []*types.Proxy{p}).Close()
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header http.Header
+		body   string
+		want   string
+	}{
+		{"clean", 200, http.Header{}, "<html>ok</html>", ReputationClean},
+		{"challenge header", 403, http.Header{"Cf-Mitigated": {"challenge"}}, "", ReputationChallenged},
+		{"challenge page", 503, http.Header{}, "<title>Just a moment...</title>", ReputationChallenged},
+		{"blocked", 403, http.Header{}, `<div id="cf-error-details">Sorry, you have been blocked</div>`, ReputationBlocked},
+		{"other error", 500, http.Header{}, "oops", ReputationUnknown},
+	}
+	for _, tt := range tests {
+		if got := classify(tt.status, tt.header, []byte(tt.body)); got != tt.want {
+			t.Errorf("%s: classify() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseIP(t *testing.T) {
+	for body, want := range map[string]string{
+		"203.0.113.7\n":          "203.0.113.7",
+		`{"ip":"2001:db8::1"}`:   "2001:db8::1",
+		"<html>not an ip</html>": "",
+	} {
+		if got := ParseIP([]byte(body)); got != want {
+			t.Errorf("ParseIP(%q) = %q, want %q", body, got, want)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+// Package quiethours enforces per-domain time windows during which a target
+// must not be contacted at all (published maintenance windows, scraping
+// agreements that restrict crawl times, etc.).
+package quiethours
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// maxChainedWindows bounds how many back-to-back windows are walked when
+// computing the next allowed time, so a misconfigured schedule that covers the
+// whole week cannot loop forever.
+const maxChainedWindows = 64
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Window is a recurring quiet period. Start and End are minutes after local
+// midnight in Location; a window with End <= Start runs past midnight into the
+// next day. Days holds the weekdays the window starts on.
+type Window struct {
+	Days     [7]bool
+	Start    int
+	End      int
+	Location *time.Location
+}
+
+// activeUntil reports whether t falls inside the window and, if so, when that
+// occurrence of the window ends.
+func (w Window) activeUntil(t time.Time) (time.Time, bool) {
+	local := t.In(w.Location)
+	// An overnight window may have started on the previous day
+	for offset := 0; offset >= -1; offset-- {
+		day := local.AddDate(0, 0, offset)
+		if !w.Days[day.Weekday()] {
+			continue
+		}
+		midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, w.Location)
+		start := midnight.Add(time.Duration(w.Start) * time.Minute)
+		end := midnight.Add(time.Duration(w.End) * time.Minute)
+		if w.End <= w.Start {
+			end = end.AddDate(0, 0, 1)
+		}
+		if !t.Before(start) && t.Before(end) {
+			return end, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Rule binds quiet windows to a domain. The domain also covers its subdomains.
+type Rule struct {
+	Domain  string
+	Windows []Window
+}
+
+// matches reports whether host is the rule's domain or a subdomain of it.
+func (r Rule) matches(host string) bool {
+	return host == r.Domain || strings.HasSuffix(host, "."+r.Domain)
+}
+
+// Schedule is a set of per-domain quiet hour rules. A nil Schedule allows everything.
+type Schedule struct {
+	rules []Rule
+}
+
+// Parse parses a QUIET_HOURS specification. Rules are separated by ';' or
+// newlines, each of the form
+//
+//	domain=window[,window...]
+//
+// where a window is "[days] HH:MM-HH:MM [timezone]". days is "*" (default),
+// a single day ("sat") or a range ("mon-fri"); timezone is an IANA name and
+// defaults to UTC. Example:
+//
+//	example.com=sat-sun 00:00-06:00 Europe/London,mon-fri 23:30-01:00
+//
+// An empty spec yields a nil Schedule.
+func Parse(spec string) (*Schedule, error) {
+	var rules []Rule
+	for _, line := range strings.FieldsFunc(spec, func(r rune) bool { return r == ';' || r == '\n' }) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		domain, windowsSpec, ok := strings.Cut(line, "=")
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if !ok || domain == "" {
+			return nil, fmt.Errorf("quiet hours rule %q: expected domain=windows", line)
+		}
+		rule := Rule{Domain: strings.TrimPrefix(domain, ".")}
+		for _, ws := range strings.Split(windowsSpec, ",") {
+			w, err := parseWindow(ws)
+			if err != nil {
+				return nil, fmt.Errorf("quiet hours rule for %s: %w", rule.Domain, err)
+			}
+			rule.Windows = append(rule.Windows, w)
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return &Schedule{rules: rules}, nil
+}
+
+// parseWindow parses "[days] HH:MM-HH:MM [timezone]".
+func parseWindow(spec string) (Window, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 || len(fields) > 3 {
+		return Window{}, fmt.Errorf("invalid window %q", strings.TrimSpace(spec))
+	}
+
+	w := Window{Location: time.UTC}
+	// The time range is the only field containing ':'
+	idx := -1
+	for i, f := range fields {
+		if strings.Contains(f, ":") {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 || idx > 1 {
+		return Window{}, fmt.Errorf("invalid window %q: missing HH:MM-HH:MM", strings.TrimSpace(spec))
+	}
+
+	days := "*"
+	if idx == 1 {
+		days = fields[0]
+	}
+	if err := w.parseDays(days); err != nil {
+		return Window{}, err
+	}
+
+	startStr, endStr, ok := strings.Cut(fields[idx], "-")
+	if !ok {
+		return Window{}, fmt.Errorf("invalid time range %q", fields[idx])
+	}
+	var err error
+	if w.Start, err = parseClock(startStr, false); err != nil {
+		return Window{}, err
+	}
+	if w.End, err = parseClock(endStr, true); err != nil {
+		return Window{}, err
+	}
+	if w.Start == w.End {
+		return Window{}, fmt.Errorf("invalid time range %q: start equals end", fields[idx])
+	}
+
+	if idx+1 < len(fields) {
+		loc, err := time.LoadLocation(fields[idx+1])
+		if err != nil {
+			return Window{}, fmt.Errorf("invalid timezone %q: %w", fields[idx+1], err)
+		}
+		w.Location = loc
+	}
+	return w, nil
+}
+
+// parseDays sets w.Days from "*", "mon" or "mon-fri" (ranges may wrap, e.g. "fri-mon").
+func (w *Window) parseDays(spec string) error {
+	spec = strings.ToLower(spec)
+	if spec == "*" {
+		for i := range w.Days {
+			w.Days[i] = true
+		}
+		return nil
+	}
+	from, to, isRange := strings.Cut(spec, "-")
+	if !isRange {
+		to = from
+	}
+	start, ok1 := weekdays[from]
+	end, ok2 := weekdays[to]
+	if !ok1 || !ok2 {
+		return fmt.Errorf("invalid days %q", spec)
+	}
+	for d := start; ; d = (d + 1) % 7 {
+		w.Days[d] = true
+		if d == end {
+			break
+		}
+	}
+	return nil
+}
+
+// parseClock parses HH:MM into minutes after midnight. "24:00" is accepted
+// only as an end time.
+func parseClock(s string, isEnd bool) (int, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	h, err1 := strconv.Atoi(hh)
+	m, err2 := strconv.Atoi(mm)
+	if err1 != nil || err2 != nil || m < 0 || m > 59 || h < 0 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	if h == 24 && m == 0 && isEnd {
+		return 24 * 60, nil
+	}
+	if h > 23 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return h*60 + m, nil
+}
+
+// Check returns a *types.QuietHoursError if host is inside a quiet window at
+// now, or nil if the request may proceed. Overlapping or back-to-back windows
+// are merged so NextAllowedAt is the first instant the domain is reachable.
+func (s *Schedule) Check(host string, now time.Time) error {
+	if s == nil || host == "" {
+		return nil
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	for _, rule := range s.rules {
+		if !rule.matches(host) {
+			continue
+		}
+		next, blocked := rule.nextAllowed(now)
+		if blocked {
+			return types.NewQuietHoursError(rule.Domain, next)
+		}
+	}
+	return nil
+}
+
+// nextAllowed returns the end of the quiet period covering t, following
+// chained windows. blocked is false if t is outside every window.
+func (r Rule) nextAllowed(t time.Time) (next time.Time, blocked bool) {
+	next = t
+	for i := 0; i < maxChainedWindows; i++ {
+		advanced := false
+		for _, w := range r.Windows {
+			if end, ok := w.activeUntil(next); ok {
+				next = end
+				advanced = true
+				blocked = true
+			}
+		}
+		if !advanced {
+			break
+		}
+	}
+	return next, blocked
+}
@@ -0,0 +1,109 @@
+package quiethours
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// 2026-03-07 is a Saturday
+func at(day, hour, minute int) time.Time {
+	return time.Date(2026, 3, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestParse_Empty(t *testing.T) {
+	s, err := Parse("  ")
+	if err != nil || s != nil {
+		t.Errorf("Expected nil schedule for empty spec, got %v, %v", s, err)
+	}
+	if err := s.Check("example.com", time.Now()); err != nil {
+		t.Errorf("nil schedule should allow everything, got %v", err)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"missing equals", "example.com 00:00-06:00"},
+		{"missing domain", "=00:00-06:00"},
+		{"bad time", "example.com=25:00-26:00"},
+		{"bad minutes", "example.com=01:60-02:00"},
+		{"start equals end", "example.com=02:00-02:00"},
+		{"24:00 as start", "example.com=24:00-02:00"},
+		{"bad day", "example.com=funday 00:00-06:00"},
+		{"bad timezone", "example.com=00:00-06:00 Mars/Olympus"},
+		{"no range", "example.com=sat"},
+		{"too many fields", "example.com=sat 00:00-06:00 UTC extra"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.spec); err == nil {
+				t.Errorf("Parse(%q) expected error", tt.spec)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	s, err := Parse("example.com=sat-sun 00:00-06:00, mon-fri 23:00-01:00; other.org=12:00-13:00 Europe/Paris")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		host     string
+		now      time.Time
+		wantNext time.Time // zero = allowed
+	}{
+		{"weekend window", "example.com", at(7, 3, 0), at(7, 6, 0)},
+		{"subdomain matches", "www.example.com", at(7, 3, 0), at(7, 6, 0)},
+		{"after weekend window", "example.com", at(7, 6, 0), time.Time{}},
+		{"overnight started friday", "example.com", at(7, 0, 30), at(7, 6, 0)},
+		{"overnight weekday", "example.com", at(9, 23, 30), at(10, 1, 0)},
+		{"overnight past midnight", "example.com", at(10, 0, 59), at(10, 1, 0)},
+		{"weekday daytime", "example.com", at(10, 12, 0), time.Time{}},
+		{"unrelated domain", "notexample.com", at(7, 3, 0), time.Time{}},
+		{"timezone window", "other.org", at(10, 11, 30), at(10, 12, 0)},
+		{"timezone outside", "other.org", at(10, 12, 30), time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Check(tt.host, tt.now)
+			if tt.wantNext.IsZero() {
+				if err != nil {
+					t.Errorf("Check() = %v, want allowed", err)
+				}
+				return
+			}
+			var qhErr *types.QuietHoursError
+			if !errors.As(err, &qhErr) {
+				t.Fatalf("Check() = %v, want *QuietHoursError", err)
+			}
+			if !errors.Is(err, types.ErrQuietHours) {
+				t.Error("Expected errors.Is(err, ErrQuietHours)")
+			}
+			if !qhErr.NextAllowedAt.Equal(tt.wantNext) {
+				t.Errorf("NextAllowedAt = %v, want %v", qhErr.NextAllowedAt, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestCheck_ChainedWindows(t *testing.T) {
+	s, err := Parse("example.com=00:00-06:00,06:00-08:00,07:00-09:30")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	var qhErr *types.QuietHoursError
+	if !errors.As(s.Check("example.com", at(10, 1, 0)), &qhErr) {
+		t.Fatal("Expected quiet hours error")
+	}
+	if want := at(10, 9, 30); !qhErr.NextAllowedAt.Equal(want) {
+		t.Errorf("NextAllowedAt = %v, want %v", qhErr.NextAllowedAt, want)
+	}
+}
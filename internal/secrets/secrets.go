@@ -0,0 +1,112 @@
+// Package secrets resolves configuration values that point at a secret
+// store instead of holding the secret, so credentials need not sit in plain
+// environment variables. A reference is "<scheme>:<ref>", e.g.
+// "vault:secret/data/flaresolverr#capsolver"; the scheme selects a provider
+// registered with Register.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Provider fetches secrets from one store.
+type Provider interface {
+	// Resolve returns the secret ref names; ref is the reference without
+	// its "<scheme>:" prefix.
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// ProviderFactory creates a Provider from the process environment.
+type ProviderFactory func(getenv func(string) string) (Provider, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]ProviderFactory)
+)
+
+// Register adds a provider factory under scheme. Call from init() in each
+// provider file. Panics on duplicate registration.
+func Register(scheme string, factory ProviderFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[scheme]; exists {
+		panic("secret provider already registered: " + scheme)
+	}
+	registry[scheme] = factory
+}
+
+// Schemes returns the registered schemes in sorted order.
+func Schemes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolver resolves references, creating each provider on first use.
+type Resolver struct {
+	getenv    func(string) string
+	providers map[string]Provider
+}
+
+// NewResolver returns a Resolver whose providers read their settings
+// (addresses, tokens) through getenv.
+func NewResolver(getenv func(string) string) *Resolver {
+	return &Resolver{getenv: getenv, providers: make(map[string]Provider)}
+}
+
+// IsReference reports whether value names a registered scheme.
+func IsReference(value string) bool {
+	scheme, _, ok := strings.Cut(value, ":")
+	if !ok {
+		return false
+	}
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	_, exists := registry[scheme]
+	return exists
+}
+
+// Resolve returns the secret value refers to, or value unchanged when it is
+// not a reference.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+	scheme, ref, _ := strings.Cut(value, ":")
+	p, ok := r.providers[scheme]
+	if !ok {
+		registryMu.RLock()
+		factory := registry[scheme]
+		registryMu.RUnlock()
+		var err error
+		if p, err = factory(r.getenv); err != nil {
+			return "", fmt.Errorf("%s secret provider: %w", scheme, err)
+		}
+		r.providers[scheme] = p
+	}
+	secret, err := p.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s secret %q: %w", scheme, ref, err)
+	}
+	return secret, nil
+}
+
+// ReadFile returns the contents of a secret file without the trailing
+// newline editors and `echo` add.
+func ReadFile(path string) (string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from operator configuration
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,130 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// vaultTimeout bounds each request to Vault.
+const vaultTimeout = 10 * time.Second
+
+func init() {
+	Register("vault", newVaultProvider)
+}
+
+// vaultProvider reads fields of HashiCorp Vault KV secrets (version 1 or 2).
+// References are "<path>#<field>", with the path as used in the HTTP API,
+// e.g. "secret/data/flaresolverr#capsolver" for a KV v2 mount "secret".
+type vaultProvider struct {
+	addr      string
+	token     string
+	namespace string
+	client    *http.Client
+
+	mu    sync.Mutex
+	cache map[string]map[string]any // Secret data by path, so fields share a read
+}
+
+// newVaultProvider configures the provider from VAULT_ADDR, VAULT_TOKEN (or
+// VAULT_TOKEN_FILE, e.g. written by Vault Agent) and VAULT_NAMESPACE.
+func newVaultProvider(getenv func(string) string) (Provider, error) {
+	addr := strings.TrimRight(getenv("VAULT_ADDR"), "/")
+	if addr == "" {
+		return nil, errors.New("VAULT_ADDR is not set")
+	}
+	token := getenv("VAULT_TOKEN")
+	if token == "" {
+		if path := getenv("VAULT_TOKEN_FILE"); path != "" {
+			var err error
+			if token, err = ReadFile(path); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if token == "" {
+		return nil, errors.New("VAULT_TOKEN or VAULT_TOKEN_FILE is required")
+	}
+	return &vaultProvider{
+		addr:      addr,
+		token:     token,
+		namespace: getenv("VAULT_NAMESPACE"),
+		client:    &http.Client{Timeout: vaultTimeout},
+		cache:     make(map[string]map[string]any),
+	}, nil
+}
+
+func (v *vaultProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	path = strings.Trim(path, "/")
+	if !ok || path == "" || field == "" {
+		return "", errors.New("want vault:<path>#<field>")
+	}
+	data, err := v.read(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("field %q not found", field)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("field %q is not a string", field)
+	}
+	return s, nil
+}
+
+// read fetches the secret at path, unwrapping the KV v2 envelope.
+func (v *vaultProvider) read(ctx context.Context, path string) (map[string]any, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if data, ok := v.cache[path]; ok {
+		return data, nil
+	}
+
+	u := v.addr + "/v1/" + (&url.URL{Path: path}).EscapedPath()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Vault response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned HTTP %d", resp.StatusCode)
+	}
+
+	var parsed struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid Vault response: %w", err)
+	}
+	data := parsed.Data
+	// KV v2 nests the fields under data.data next to data.metadata
+	if inner, ok := data["data"].(map[string]any); ok {
+		if _, hasMeta := data["metadata"]; hasMeta {
+			data = inner
+		}
+	}
+	v.cache[path] = data
+	return data, nil
+}
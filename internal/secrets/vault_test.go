@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVaultProvider(t *testing.T) {
+	reads := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root-token" || r.Header.Get("X-Vault-Namespace") != "team" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		reads++
+		switch r.URL.Path {
+		case "/v1/secret/data/flaresolverr":
+			_, _ = w.Write([]byte(`{"data":{"data":{"capsolver":"CAP-123","proxy_password":"pw"},"metadata":{"version":3}}}`))
+		case "/v1/kv/flaresolverr":
+			_, _ = w.Write([]byte(`{"data":{"api_key":"v1-key","retries":3}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	env := map[string]string{"VAULT_ADDR": srv.URL + "/", "VAULT_TOKEN": "root-token", "VAULT_NAMESPACE": "team"}
+	r := NewResolver(func(name string) string { return env[name] })
+	ctx := context.Background()
+
+	tests := map[string]string{
+		"vault:secret/data/flaresolverr#capsolver":      "CAP-123",
+		"vault:secret/data/flaresolverr#proxy_password": "pw",
+		"vault:kv/flaresolverr#api_key":                 "v1-key",
+	}
+	for ref, want := range tests {
+		if got, err := r.Resolve(ctx, ref); err != nil || got != want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", ref, got, err, want)
+		}
+	}
+	if reads != 2 {
+		t.Errorf("Vault read %d times, want once per path", reads)
+	}
+
+	for _, ref := range []string{
+		"vault:secret/data/flaresolverr#missing",
+		"vault:kv/flaresolverr#retries",
+		"vault:secret/data/other#field",
+		"vault:secret/data/flaresolverr",
+	} {
+		if _, err := r.Resolve(ctx, ref); err == nil {
+			t.Errorf("Resolve(%q): expected error", ref)
+		}
+	}
+}
+
+func TestVaultProviderRequiresConfiguration(t *testing.T) {
+	r := NewResolver(func(string) string { return "" })
+	_, err := r.Resolve(context.Background(), "vault:secret/data/x#y")
+	if err == nil || !strings.Contains(err.Error(), "VAULT_ADDR") {
+		t.Errorf("Resolve() without VAULT_ADDR = %v", err)
+	}
+}
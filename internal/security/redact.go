@@ -100,3 +100,18 @@ func RedactProxyURL(proxyURL string) string {
 
 	return parsed.String()
 }
+
+// RedactProxy returns a proxy URL whose credentials are held separately,
+// with the username shown and the password redacted, so entries sharing a
+// gateway stay distinguishable in logs and stats.
+func RedactProxy(proxyURL, username string) string {
+	if username == "" {
+		return RedactProxyURL(proxyURL)
+	}
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return "[invalid-proxy-url]"
+	}
+	parsed.User = url.UserPassword(username, "[REDACTED]")
+	return parsed.String()
+}
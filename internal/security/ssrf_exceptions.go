@@ -0,0 +1,145 @@
+package security
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+)
+
+// SSRFExceptions lists private targets the operator has vouched for, such as
+// QA environments on RFC 1918 addresses. They lift the private, loopback and
+// link-local checks on target URLs only; cloud metadata endpoints and
+// unspecified addresses stay blocked, and proxy URLs are governed by
+// ALLOW_LOCAL_PROXIES instead.
+type SSRFExceptions struct {
+	cidrs []*net.IPNet
+	hosts []string // "host" covers its subdomains, "*.host" only the subdomains
+}
+
+// ssrfExceptions holds the exceptions in effect; nil allows none.
+var ssrfExceptions atomic.Pointer[SSRFExceptions]
+
+// ParseSSRFExceptions parses SSRF_ALLOWED_CIDRS and SSRF_ALLOWED_HOSTS
+// entries. A CIDR entry may be a bare IP address. Returns nil when both are
+// empty.
+func ParseSSRFExceptions(cidrs, hosts []string) (*SSRFExceptions, error) {
+	e := &SSRFExceptions{}
+	for _, entry := range cidrs {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid SSRF_ALLOWED_CIDRS entry %q", entry)
+			}
+			bits := 128
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 32
+			}
+			e.cidrs = append(e.cidrs, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SSRF_ALLOWED_CIDRS entry %q: %w", entry, err)
+		}
+		e.cidrs = append(e.cidrs, ipNet)
+	}
+	for _, entry := range hosts {
+		host := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(entry), "."))
+		if host == "" {
+			continue
+		}
+		domain := strings.TrimPrefix(host, "*.")
+		if domain == "" || strings.ContainsAny(domain, "*/: ") || strings.HasPrefix(domain, ".") {
+			return nil, fmt.Errorf("invalid SSRF_ALLOWED_HOSTS entry %q: want host or *.domain", entry)
+		}
+		if isCloudMetadataHost(domain) {
+			return nil, fmt.Errorf("SSRF_ALLOWED_HOSTS entry %q is a cloud metadata host, which is always blocked", entry)
+		}
+		e.hosts = append(e.hosts, host)
+	}
+	if len(e.cidrs) == 0 && len(e.hosts) == 0 {
+		return nil, nil
+	}
+	return e, nil
+}
+
+// SetSSRFExceptions installs the exceptions applied by target URL validation.
+// nil removes them.
+func SetSSRFExceptions(e *SSRFExceptions) {
+	ssrfExceptions.Store(e)
+}
+
+// Len returns the number of CIDR and host exceptions.
+func (e *SSRFExceptions) Len() (cidrs, hosts int) {
+	if e == nil {
+		return 0, 0
+	}
+	return len(e.cidrs), len(e.hosts)
+}
+
+// allowsIP reports whether ip falls in an allowed CIDR.
+func (e *SSRFExceptions) allowsIP(ip net.IP) bool {
+	if e == nil {
+		return false
+	}
+	for _, ipNet := range e.cidrs {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsHost reports whether hostname matches an allowed host pattern.
+func (e *SSRFExceptions) allowsHost(hostname string) bool {
+	if e == nil || hostname == "" {
+		return false
+	}
+	for _, pattern := range e.hosts {
+		if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(hostname, "."+domain) {
+				return true
+			}
+		} else if hostname == pattern || strings.HasSuffix(hostname, "."+pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTargetHost applies the hostname blocklists to a target URL host and
+// reports whether the host is exempted from the private address checks.
+func checkTargetHost(hostname string) (exempt bool, err error) {
+	if isCloudMetadataHost(hostname) {
+		return false, ErrLocalhostBlocked
+	}
+	if ssrfExceptions.Load().allowsHost(hostname) {
+		return true, nil
+	}
+	if BlockedHosts[hostname] || isLocalhostHostname(hostname) {
+		return false, ErrLocalhostBlocked
+	}
+	return false, nil
+}
+
+// validateTargetIP is validateIP for target URLs: a private, loopback or
+// link-local address passes when its host or the address itself is exempted,
+// but metadata and unspecified addresses never do.
+func validateTargetIP(ip net.IP, hostExempt bool) error {
+	err := validateIP(ip)
+	if err == nil || ip.IsUnspecified() {
+		return err
+	}
+	if !hostExempt && !ssrfExceptions.Load().allowsIP(ip) {
+		return err
+	}
+	if isCloudMetadataIP(ip) {
+		return ErrMetadataBlocked
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package security
+
+import (
+	"errors"
+	"testing"
+)
+
+func withSSRFExceptions(t *testing.T, cidrs, hosts []string) {
+	t.Helper()
+	e, err := ParseSSRFExceptions(cidrs, hosts)
+	if err != nil {
+		t.Fatalf("ParseSSRFExceptions() error = %v", err)
+	}
+	SetSSRFExceptions(e)
+	t.Cleanup(func() { SetSSRFExceptions(nil) })
+}
+
+func TestParseSSRFExceptions(t *testing.T) {
+	if e, err := ParseSSRFExceptions(nil, []string{" "}); e != nil || err != nil {
+		t.Errorf("empty lists = %v, %v; want nil, nil", e, err)
+	}
+	e, err := ParseSSRFExceptions([]string{"10.20.0.0/16", "192.168.5.10", "fd12::/64"}, []string{"qa.corp", "*.staging.internal"})
+	if err != nil {
+		t.Fatalf("ParseSSRFExceptions() error = %v", err)
+	}
+	if cidrs, hosts := e.Len(); cidrs != 3 || hosts != 2 {
+		t.Errorf("Len() = %d, %d; want 3, 2", cidrs, hosts)
+	}
+
+	for _, bad := range [][2][]string{
+		{{"10.0.0.0/33"}, nil},
+		{{"not-an-ip"}, nil},
+		{nil, {"http://qa.corp"}},
+		{nil, {"qa.*.corp"}},
+		{nil, {"metadata.google.internal"}},
+	} {
+		if _, err := ParseSSRFExceptions(bad[0], bad[1]); err == nil {
+			t.Errorf("ParseSSRFExceptions(%v, %v): expected error", bad[0], bad[1])
+		}
+	}
+}
+
+func TestSSRFExceptionsAllowListedCIDRs(t *testing.T) {
+	withSSRFExceptions(t, []string{"10.20.0.0/16", "192.168.5.10", "169.254.0.0/16"}, nil)
+
+	for _, rawURL := range []string{"http://10.20.3.4/", "https://192.168.5.10:8443/app"} {
+		if _, _, err := ValidateAndResolveURL(rawURL); err != nil {
+			t.Errorf("ValidateAndResolveURL(%q) = %v, want allowed", rawURL, err)
+		}
+		if err := ValidateURL(rawURL); err != nil {
+			t.Errorf("ValidateURL(%q) = %v, want allowed", rawURL, err)
+		}
+	}
+
+	// Everything outside the exceptions stays blocked
+	blocked := map[string]error{
+		"http://10.21.0.1/":       ErrPrivateIPBlocked,
+		"http://192.168.5.11/":    ErrPrivateIPBlocked,
+		"http://127.0.0.1/":       ErrLocalhostBlocked,
+		"http://169.254.169.254/": ErrMetadataBlocked, // inside an allowed range
+		"http://0.0.0.0/":         ErrPrivateIPBlocked,
+	}
+	for rawURL, want := range blocked {
+		if _, _, err := ValidateAndResolveURL(rawURL); !errors.Is(err, want) {
+			t.Errorf("ValidateAndResolveURL(%q) = %v, want %v", rawURL, err, want)
+		}
+	}
+
+	// Proxy validation is unaffected
+	if err := ValidateProxyURL("http://10.20.3.4:3128", false); err == nil {
+		t.Error("ValidateProxyURL allowed a private proxy through the target exceptions")
+	}
+}
+
+func TestSSRFExceptionsAllowListedHosts(t *testing.T) {
+	withSSRFExceptions(t, nil, []string{"localhost", "*.kubernetes"})
+
+	if _, _, err := ValidateAndResolveURL("http://localhost:8080/"); errors.Is(err, ErrLocalhostBlocked) {
+		t.Errorf("exempted host was blocked: %v", err)
+	}
+	if _, _, err := ValidateAndResolveURL("http://kubernetes/"); !errors.Is(err, ErrLocalhostBlocked) {
+		t.Errorf("*.kubernetes should not cover kubernetes itself, got %v", err)
+	}
+	if _, _, err := ValidateAndResolveURL("http://metadata.google.internal/"); !errors.Is(err, ErrLocalhostBlocked) {
+		t.Errorf("metadata host = %v, want blocked", err)
+	}
+}
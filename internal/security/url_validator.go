@@ -171,15 +171,12 @@ func ValidateURLWithContext(ctx context.Context, rawURL string) error {
 		return ErrBlockedScheme
 	}
 
-	// Check for blocked hostnames
+	// Check for blocked hostnames and localhost variations, unless the
+	// operator exempted the host (SSRF_ALLOWED_HOSTS)
 	hostname := strings.ToLower(parsed.Hostname())
-	if BlockedHosts[hostname] {
-		return ErrLocalhostBlocked
-	}
-
-	// Check for localhost hostname variations
-	if isLocalhostHostname(hostname) {
-		return ErrLocalhostBlocked
+	hostExempt, err := checkTargetHost(hostname)
+	if err != nil {
+		return err
 	}
 
 	// Fix #18: Validate internationalized domain names (IDN)
@@ -193,7 +190,7 @@ func ValidateURLWithContext(ctx context.Context, rawURL string) error {
 	if ip != nil {
 		// Normalize IPv4-mapped IPv6 addresses to IPv4
 		ip = normalizeIPv4Mapped(ip)
-		if err := validateIP(ip); err != nil {
+		if err := validateTargetIP(ip, hostExempt); err != nil {
 			return fmt.Errorf("invalid parsed IP %s: %w", ip.String(), err)
 		}
 	} else {
@@ -206,7 +203,7 @@ func ValidateURLWithContext(ctx context.Context, rawURL string) error {
 		for _, resolvedIP := range ips {
 			// Normalize IPv4-mapped addresses
 			resolvedIP = normalizeIPv4Mapped(resolvedIP)
-			if err := validateIP(resolvedIP); err != nil {
+			if err := validateTargetIP(resolvedIP, hostExempt); err != nil {
 				return fmt.Errorf("invalid resolved IP for %s: %w", hostname, err)
 			}
 		}
@@ -514,19 +511,16 @@ func ValidateAndResolveURLWithContext(ctx context.Context, rawURL string) (strin
 	}
 
 	hostname := strings.ToLower(parsed.Hostname())
-	if BlockedHosts[hostname] {
-		return "", nil, ErrLocalhostBlocked
-	}
-
-	if isLocalhostHostname(hostname) {
-		return "", nil, ErrLocalhostBlocked
+	hostExempt, err := checkTargetHost(hostname)
+	if err != nil {
+		return "", nil, err
 	}
 
 	// Try to parse as IP address
 	ip := parseIPWithNormalization(hostname)
 	if ip != nil {
 		ip = normalizeIPv4Mapped(ip)
-		if err := validateIP(ip); err != nil {
+		if err := validateTargetIP(ip, hostExempt); err != nil {
 			return "", nil, err
 		}
 		return rawURL, ip, nil
@@ -543,7 +537,7 @@ func ValidateAndResolveURLWithContext(ctx context.Context, rawURL string) (strin
 
 	for _, resolvedIP := range ips {
 		resolvedIP = normalizeIPv4Mapped(resolvedIP)
-		if err := validateIP(resolvedIP); err != nil {
+		if err := validateTargetIP(resolvedIP, hostExempt); err != nil {
 			return "", nil, err
 		}
 	}
@@ -816,12 +810,13 @@ func ExtractAndValidateHostIPWithContext(ctx context.Context, rawURL string) (ne
 	if hostname == "" {
 		return nil, ErrEmptyHostname
 	}
+	hostExempt := ssrfExceptions.Load().allowsHost(hostname)
 
 	// Check if hostname is already an IP
 	ip := parseIPWithNormalization(hostname)
 	if ip != nil {
 		ip = normalizeIPv4Mapped(ip)
-		if err := validateIP(ip); err != nil {
+		if err := validateTargetIP(ip, hostExempt); err != nil {
 			return nil, fmt.Errorf("direct IP validation failed for %s: %w", hostname, err)
 		}
 		return ip, nil
@@ -835,7 +830,7 @@ func ExtractAndValidateHostIPWithContext(ctx context.Context, rawURL string) (ne
 
 	// Validate and return first IP
 	firstIP := normalizeIPv4Mapped(ips[0])
-	if err := validateIP(firstIP); err != nil {
+	if err := validateTargetIP(firstIP, hostExempt); err != nil {
 		return nil, fmt.Errorf("resolved IP validation failed for %s: %w", hostname, err)
 	}
 
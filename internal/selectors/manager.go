@@ -3,6 +3,9 @@ package selectors
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -27,6 +30,7 @@ type ReloadStats struct {
 	LastErrorStr       string    `json:"lastError,omitempty"`
 	RemoteSuccesses    int64     `json:"remoteSuccesses,omitempty"`
 	RemoteFailures     int64     `json:"remoteFailures,omitempty"`
+	RemoteNotModified  int64     `json:"remoteNotModified,omitempty"` // Fetches answered 304 for the cached ETag
 	LastRemoteFetch    time.Time `json:"lastRemoteFetch,omitempty"`
 	LastRemoteError    error     `json:"-"`
 	LastRemoteErrorStr string    `json:"lastRemoteError,omitempty"`
@@ -49,8 +53,28 @@ type Manager struct {
 	// Remote fetch fields
 	remoteURL       string
 	refreshInterval time.Duration
+	publicKey       ed25519.PublicKey // nil accepts unsigned documents
 	httpClient      *http.Client
 	refreshTicker   *time.Ticker
+	remote          *Selectors // Last accepted remote selectors, guarded by mu
+	remoteETag      string     // ETag of remote, guarded by mu
+}
+
+// RemoteOptions configures fetching selectors from a URL.
+type RemoteOptions struct {
+	URL             string
+	RefreshInterval time.Duration
+	// PublicKey, when set, makes the manager accept only documents signed
+	// with the matching Ed25519 private key (see SignedDocument).
+	PublicKey ed25519.PublicKey
+}
+
+// SignedDocument is a selectors document with an Ed25519 signature, as
+// served from a remote URL: Payload is the base64 of the YAML or JSON
+// selectors document and Signature the base64 of its signature.
+type SignedDocument struct {
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
 }
 
 // NewManager creates a new SelectorsManager.
@@ -64,12 +88,20 @@ func NewManager(externalPath string, hotReload bool) (*Manager, error) {
 // If remoteURL is set and refreshInterval > 0, selectors will be periodically fetched from the URL.
 // File selectors take priority over remote; remote supplements if file is not available.
 func NewManagerWithRemote(externalPath string, hotReload bool, remoteURL string, refreshInterval time.Duration) (*Manager, error) {
+	return NewManagerWithRemoteOptions(externalPath, hotReload, RemoteOptions{URL: remoteURL, RefreshInterval: refreshInterval})
+}
+
+// NewManagerWithRemoteOptions creates a new SelectorsManager that fetches
+// selectors as remote describes, optionally requiring them to be signed.
+func NewManagerWithRemoteOptions(externalPath string, hotReload bool, remote RemoteOptions) (*Manager, error) {
+	remoteURL, refreshInterval := remote.URL, remote.RefreshInterval
 	m := &Manager{
 		embedded:        Get(), // Use the singleton embedded selectors
 		externalPath:    externalPath,
 		stopCh:          make(chan struct{}),
 		remoteURL:       remoteURL,
 		refreshInterval: refreshInterval,
+		publicKey:       remote.PublicKey,
 	}
 
 	// Initialize HTTP client for remote fetch
@@ -178,6 +210,55 @@ func (m *Manager) Reload() error {
 	return m.loadExternalLocked()
 }
 
+// SetPath switches the external selectors file to path and loads it, or
+// returns to the remote or embedded selectors for an empty path. Passing the current
+// path re-reads the file. On failure the previous file and selectors remain
+// in use. A running hot-reload watcher follows the new file.
+func (m *Manager) SetPath(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prev := m.externalPath
+	if path == "" {
+		m.externalPath = ""
+		if m.remote != nil {
+			m.current.Store(m.mergeWithEmbedded(m.remote))
+		} else {
+			m.current.Store(m.embedded)
+		}
+		if m.watcher != nil && prev != "" {
+			_ = m.watcher.Remove(prev)
+		}
+		return nil
+	}
+
+	m.externalPath = path
+	if err := m.loadExternalLocked(); err != nil {
+		m.externalPath = prev
+		return err
+	}
+
+	if m.watcher != nil && path != prev {
+		if prev != "" {
+			_ = m.watcher.Remove(prev)
+		}
+		if err := m.watcher.Add(path); err != nil {
+			log.Warn().
+				Err(err).
+				Str("path", path).
+				Msg("Failed to watch new selectors file, hot-reload disabled for it")
+		}
+	}
+	return nil
+}
+
+// path returns the external selectors file, empty when none is set.
+func (m *Manager) path() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.externalPath
+}
+
 // Stats returns the current reload statistics.
 func (m *Manager) Stats() ReloadStats {
 	m.mu.Lock()
@@ -268,7 +349,9 @@ func parseAndValidate(data []byte) (*Selectors, error) {
 	return &s, nil
 }
 
-// loadRemote fetches selectors from the remote URL.
+// loadRemote fetches selectors from the remote URL. The ETag of the last
+// accepted document is sent along, and a 304 answer returns that document
+// again without downloading it.
 func (m *Manager) loadRemote(ctx context.Context) (*Selectors, error) {
 	if m.remoteURL == "" {
 		return nil, fmt.Errorf("no remote URL configured")
@@ -284,7 +367,13 @@ func (m *Manager) loadRemote(ctx context.Context) (*Selectors, error) {
 
 	// Add User-Agent header
 	req.Header.Set("User-Agent", "FlareSolverr-Go/1.0")
-	req.Header.Set("Accept", "application/yaml, application/x-yaml, text/yaml, text/x-yaml, */*")
+	req.Header.Set("Accept", "application/yaml, application/x-yaml, text/yaml, text/x-yaml, application/json, */*")
+	m.mu.Lock()
+	cached, etag := m.remote, m.remoteETag
+	m.mu.Unlock()
+	if cached != nil && etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
 
 	resp, err := m.httpClient.Do(req)
 	if err != nil {
@@ -292,6 +381,13 @@ func (m *Manager) loadRemote(ctx context.Context) (*Selectors, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		m.mu.Lock()
+		m.stats.RemoteNotModified++
+		m.mu.Unlock()
+		log.Debug().Str("url", m.remoteURL).Msg("Remote selectors not modified")
+		return cached, nil
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
@@ -302,14 +398,67 @@ func (m *Manager) loadRemote(ctx context.Context) (*Selectors, error) {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
+	body, err = m.verifyRemote(body)
+	if err != nil {
+		return nil, err
+	}
+
 	selectors, err := parseAndValidate(body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse remote selectors: %w", err)
 	}
 
+	m.mu.Lock()
+	m.remote = selectors
+	m.remoteETag = resp.Header.Get("ETag")
+	m.mu.Unlock()
+
 	return selectors, nil
 }
 
+// verifyRemote unwraps a SignedDocument and returns its payload. With a
+// public key configured the signature must verify and unsigned documents
+// are refused; without one a signed document's payload is used as is.
+func (m *Manager) verifyRemote(body []byte) ([]byte, error) {
+	var doc SignedDocument
+	signed := json.Unmarshal(body, &doc) == nil && doc.Payload != "" && doc.Signature != ""
+	if !signed {
+		if m.publicKey != nil {
+			return nil, fmt.Errorf("remote selectors are not signed")
+		}
+		return body, nil
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(doc.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signed selectors payload: %w", err)
+	}
+	if m.publicKey == nil {
+		return payload, nil
+	}
+	signature, err := base64.StdEncoding.DecodeString(doc.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signed selectors signature: %w", err)
+	}
+	if !ed25519.Verify(m.publicKey, payload, signature) {
+		return nil, fmt.Errorf("remote selectors signature verification failed")
+	}
+	return payload, nil
+}
+
+// ParsePublicKey parses an Ed25519 public key given as the base64 of its 32
+// bytes, for RemoteOptions.PublicKey.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key: want %d bytes, got %d", ed25519.PublicKeySize, len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
 // startRemoteRefresh starts the periodic remote selector refresh loop.
 func (m *Manager) startRemoteRefresh() {
 	if m.remoteURL == "" || m.refreshInterval <= 0 {
@@ -418,6 +567,48 @@ func (m *Manager) mergeWithEmbedded(external *Selectors) *Selectors {
 		merged.JavaScript = m.embedded.JavaScript
 	}
 
+	if len(external.Captcha) > 0 {
+		merged.Captcha = external.Captcha
+	} else {
+		merged.Captcha = m.embedded.Captcha
+	}
+
+	if len(external.AWSWAF) > 0 {
+		merged.AWSWAF = external.AWSWAF
+	} else {
+		merged.AWSWAF = m.embedded.AWSWAF
+	}
+
+	if len(external.DataDome) > 0 {
+		merged.DataDome = external.DataDome
+	} else {
+		merged.DataDome = m.embedded.DataDome
+	}
+
+	if len(external.Incapsula) > 0 {
+		merged.Incapsula = external.Incapsula
+	} else {
+		merged.Incapsula = m.embedded.Incapsula
+	}
+
+	if len(external.ManagedChallenge) > 0 {
+		merged.ManagedChallenge = external.ManagedChallenge
+	} else {
+		merged.ManagedChallenge = m.embedded.ManagedChallenge
+	}
+
+	if len(external.ManagedFrames) > 0 {
+		merged.ManagedFrames = external.ManagedFrames
+	} else {
+		merged.ManagedFrames = m.embedded.ManagedFrames
+	}
+
+	if len(external.ManagedCheckbox) > 0 {
+		merged.ManagedCheckbox = external.ManagedCheckbox
+	} else {
+		merged.ManagedCheckbox = m.embedded.ManagedCheckbox
+	}
+
 	if len(external.TurnstileSelectors) > 0 {
 		merged.TurnstileSelectors = external.TurnstileSelectors
 	} else {
@@ -507,7 +698,7 @@ func (m *Manager) watchFile() {
 					if err := m.Reload(); err != nil {
 						log.Warn().
 							Err(err).
-							Str("path", m.externalPath).
+							Str("path", m.path()).
 							Msg("Hot-reload failed, keeping previous selectors")
 					}
 					debouncing = false
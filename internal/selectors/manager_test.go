@@ -2,6 +2,9 @@ package selectors
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -232,6 +235,54 @@ func TestManager_Reload_NoExternalPath(t *testing.T) {
 	}
 }
 
+func TestManager_SetPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	first := filepath.Join(tmpDir, "first.yaml")
+	second := filepath.Join(tmpDir, "second.yaml")
+	if err := os.WriteFile(first, []byte("access_denied:\n  - \"first file\"\n"), 0600); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	if err := os.WriteFile(second, []byte("access_denied:\n  - \"second file\"\n"), 0600); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	m := GetManager()
+	defer m.Close()
+	embedded := m.Get()
+
+	if err := m.SetPath(first); err != nil {
+		t.Fatalf("SetPath(first) error = %v", err)
+	}
+	if got := m.Get().AccessDenied[0]; got != "first file" {
+		t.Errorf("AccessDenied[0] = %q, want %q", got, "first file")
+	}
+
+	if err := m.SetPath(second); err != nil {
+		t.Fatalf("SetPath(second) error = %v", err)
+	}
+	if got := m.Get().AccessDenied[0]; got != "second file" {
+		t.Errorf("AccessDenied[0] = %q, want %q", got, "second file")
+	}
+
+	// A missing file keeps the previous file and selectors
+	if err := m.SetPath(filepath.Join(tmpDir, "missing.yaml")); err == nil {
+		t.Error("SetPath(missing) should fail")
+	}
+	if got := m.Get().AccessDenied[0]; got != "second file" {
+		t.Errorf("AccessDenied[0] = %q after failed SetPath, want %q", got, "second file")
+	}
+	if err := m.Reload(); err != nil {
+		t.Errorf("Reload() after failed SetPath error = %v, want the previous file", err)
+	}
+
+	if err := m.SetPath(""); err != nil {
+		t.Fatalf("SetPath(\"\") error = %v", err)
+	}
+	if m.Get() != embedded {
+		t.Error("SetPath(\"\") should restore the embedded selectors")
+	}
+}
+
 func TestManager_HotReload(t *testing.T) {
 	// Skip if running in CI or short mode
 	if testing.Short() {
@@ -379,6 +430,21 @@ func TestManager_MergeWithEmbedded(t *testing.T) {
 	if len(merged.JavaScript) == 0 {
 		t.Error("Expected embedded javascript patterns to be used")
 	}
+	if len(merged.Captcha) == 0 {
+		t.Error("Expected embedded captcha patterns to be used")
+	}
+	if len(merged.AWSWAF) == 0 {
+		t.Error("Expected embedded aws_waf patterns to be used")
+	}
+	if len(merged.DataDome) == 0 {
+		t.Error("Expected embedded datadome patterns to be used")
+	}
+	if len(merged.Incapsula) == 0 {
+		t.Error("Expected embedded incapsula patterns to be used")
+	}
+	if len(merged.ManagedChallenge) == 0 || len(merged.ManagedFrames) == 0 || len(merged.ManagedCheckbox) == 0 {
+		t.Error("Expected embedded managed challenge selectors to be used")
+	}
 	if len(merged.TurnstileSelectors) == 0 {
 		t.Error("Expected embedded turnstile_selectors to be used")
 	}
@@ -692,3 +758,138 @@ func TestManager_RemoteStats(t *testing.T) {
 		t.Error("Expected LastRemoteFetch to be set")
 	}
 }
+
+func TestManager_RemoteETag(t *testing.T) {
+	var mu sync.Mutex
+	var conditional, fetches int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetches++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`access_denied: ["etag denied"]`))
+	}))
+	defer server.Close()
+
+	m, err := NewManagerWithRemote("", false, server.URL, time.Hour)
+	if err != nil {
+		t.Fatalf("NewManagerWithRemote() error = %v", err)
+	}
+	defer m.Close()
+
+	m.refreshFromRemote()
+	m.refreshFromRemote()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fetches != 3 || conditional != 2 {
+		t.Errorf("fetches = %d, conditional = %d; want 3 and 2", fetches, conditional)
+	}
+	if sel := m.Get(); len(sel.AccessDenied) != 1 || sel.AccessDenied[0] != "etag denied" {
+		t.Errorf("AccessDenied = %v, want the cached remote selectors", sel.AccessDenied)
+	}
+	if stats := m.Stats(); stats.RemoteNotModified != 2 || stats.RemoteSuccesses != 3 {
+		t.Errorf("stats = %+v, want 2 not modified of 3 successes", stats)
+	}
+}
+
+func TestManager_RemoteSigned(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte(`{"access_denied": ["signed denied"]}`)
+	signed, _ := json.Marshal(SignedDocument{
+		Payload:   base64.StdEncoding.EncodeToString(payload),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(priv, payload)),
+	})
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+	forged, _ := json.Marshal(SignedDocument{
+		Payload:   base64.StdEncoding.EncodeToString(payload),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(otherKey, payload)),
+	})
+
+	tests := []struct {
+		name string
+		body []byte
+		key  ed25519.PublicKey
+		want string // expected first access_denied pattern, empty for a rejected document
+	}{
+		{"signed", signed, pub, "signed denied"},
+		{"forged", forged, pub, ""},
+		{"unsigned with key", payload, pub, ""},
+		{"signed without key", signed, nil, "signed denied"},
+		{"unsigned without key", payload, nil, "signed denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(tt.body)
+			}))
+			defer server.Close()
+
+			m, err := NewManagerWithRemoteOptions("", false, RemoteOptions{URL: server.URL, RefreshInterval: time.Hour, PublicKey: tt.key})
+			if err != nil {
+				t.Fatalf("NewManagerWithRemoteOptions() error = %v", err)
+			}
+			defer m.Close()
+
+			got := m.Get().AccessDenied[0]
+			if tt.want == "" {
+				if got == "signed denied" {
+					t.Error("document should have been rejected")
+				}
+				if m.Stats().RemoteFailures != 1 {
+					t.Errorf("RemoteFailures = %d, want 1", m.Stats().RemoteFailures)
+				}
+			} else if got != tt.want {
+				t.Errorf("AccessDenied[0] = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	key, err := ParsePublicKey(base64.StdEncoding.EncodeToString(pub))
+	if err != nil || !key.Equal(pub) {
+		t.Errorf("ParsePublicKey() = %v, %v", key, err)
+	}
+	for _, bad := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := ParsePublicKey(bad); err == nil {
+			t.Errorf("ParsePublicKey(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestManager_SetPathRestoresRemote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`access_denied: ["remote denied"]`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "selectors.yaml")
+	if err := os.WriteFile(path, []byte(`access_denied: ["file denied"]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManagerWithRemote(path, false, server.URL, time.Hour)
+	if err != nil {
+		t.Fatalf("NewManagerWithRemote() error = %v", err)
+	}
+	defer m.Close()
+
+	if got := m.Get().AccessDenied[0]; got != "file denied" {
+		t.Fatalf("AccessDenied[0] = %q, want the file's", got)
+	}
+	if err := m.SetPath(""); err != nil {
+		t.Fatalf("SetPath(\"\") error = %v", err)
+	}
+	if got := m.Get().AccessDenied[0]; got != "remote denied" {
+		t.Errorf("AccessDenied[0] = %q after dropping the file, want the remote's", got)
+	}
+}
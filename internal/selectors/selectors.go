@@ -17,7 +17,13 @@ type Selectors struct {
 	AccessDenied          []string `yaml:"access_denied"`
 	Turnstile             []string `yaml:"turnstile"`
 	JavaScript            []string `yaml:"javascript"`
-	Captcha               []string `yaml:"captcha"` // hCaptcha/reCAPTCHA detection patterns
+	Captcha               []string `yaml:"captcha"`           // hCaptcha/reCAPTCHA detection patterns
+	AWSWAF                []string `yaml:"aws_waf"`           // AWS WAF challenge interstitial patterns
+	DataDome              []string `yaml:"datadome"`          // DataDome interstitial/captcha page patterns
+	Incapsula             []string `yaml:"incapsula"`         // Imperva Incapsula interstitial patterns
+	ManagedChallenge      []string `yaml:"managed_challenge"` // Cloudflare managed challenge interstitial patterns
+	ManagedFrames         []string `yaml:"managed_frames"`    // Challenge iframes to descend into, at every frame level
+	ManagedCheckbox       []string `yaml:"managed_checkbox"`  // Checkbox inside the innermost challenge frame
 	TurnstileSelectors    []string `yaml:"turnstile_selectors"`
 	TurnstileFramePattern string   `yaml:"turnstile_frame_pattern"`
 	ShadowHosts           []string `yaml:"shadow_hosts"`
@@ -90,6 +96,34 @@ func defaultSelectors() *Selectors {
 			"cf-challenge",
 			"cf_chl_prog",
 		},
+		AWSWAF: []string{
+			"gokuprops",
+			"awswafintegration.checkforcerefresh",
+			"awswafcaptcha.rendercaptcha",
+		},
+		DataDome: []string{
+			"captcha-delivery.com",
+			"var dd={",
+		},
+		Incapsula: []string{
+			"incapsula incident id",
+			"request unsuccessful. incapsula",
+		},
+		ManagedChallenge: []string{
+			"ctype: 'managed'",
+			"ctype:'managed'",
+			"/orchestrate/managed/",
+		},
+		ManagedFrames: []string{
+			"iframe[src*='challenges.cloudflare.com']",
+			"iframe[src*='/cdn-cgi/challenge-platform/']",
+			"iframe[id^='cf-chl-widget-']",
+		},
+		ManagedCheckbox: []string{
+			"input[type='checkbox']",
+			"[role='checkbox']",
+			"label.cb-lb",
+		},
 		TurnstileSelectors: []string{
 			"input[type='checkbox']",
 			".cf-turnstile-response",
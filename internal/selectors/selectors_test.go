@@ -98,6 +98,11 @@ func TestSelectorsContainExpectedPatterns(t *testing.T) {
 		"access_denied": {"access denied", "error 1020"},
 		"turnstile":     {"cf-turnstile"},
 		"javascript":    {"just a moment", "checking your browser"},
+		"aws_waf":       {"gokuprops"},
+		"datadome":      {"captcha-delivery.com"},
+		"incapsula":     {"incapsula incident id"},
+		"managed":       {"ctype: 'managed'"},
+		"managed_frame": {"iframe[src*='challenges.cloudflare.com']"},
 	}
 
 	for category, patterns := range expectedPatterns {
@@ -109,6 +114,16 @@ func TestSelectorsContainExpectedPatterns(t *testing.T) {
 			list = sel.Turnstile
 		case "javascript":
 			list = sel.JavaScript
+		case "aws_waf":
+			list = sel.AWSWAF
+		case "datadome":
+			list = sel.DataDome
+		case "incapsula":
+			list = sel.Incapsula
+		case "managed":
+			list = sel.ManagedChallenge
+		case "managed_frame":
+			list = sel.ManagedFrames
 		}
 
 		for _, expected := range patterns {
@@ -0,0 +1,280 @@
+// Package servertls provides the TLS configuration for the HTTP listeners:
+// either a certificate and key from disk, reloaded when the files change, or
+// certificates obtained and renewed from an ACME CA such as Let's Encrypt.
+// The API listener can also verify client certificates against a CA bundle.
+package servertls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+)
+
+// reloadDelay coalesces the several events a certificate rotation causes.
+const reloadDelay = 500 * time.Millisecond
+
+// Provider hands out the current server certificate.
+type Provider struct {
+	certFile string
+	keyFile  string
+	caFile   string // Client CA bundle, empty when client certificates are not checked
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+
+	acme          *autocert.Manager
+	challengeSrv  *http.Server
+	watcher       *fsnotify.Watcher
+	wg            sync.WaitGroup
+	closeOnce     sync.Once
+	reloadPending *time.Timer
+}
+
+// New builds the Provider for cfg. Returns nil when TLS is not configured.
+func New(cfg *config.Config) (*Provider, error) {
+	var p *Provider
+	switch {
+	case cfg.TLSCertFile != "":
+		p = &Provider{certFile: cfg.TLSCertFile, keyFile: cfg.TLSKeyFile}
+		log.Info().
+			Str("cert", cfg.TLSCertFile).
+			Str("key", cfg.TLSKeyFile).
+			Msg("TLS enabled with certificate from disk, reloaded on change")
+	case len(cfg.TLSACMEDomains) > 0:
+		p = newACMEProvider(cfg)
+	default:
+		return nil, nil
+	}
+	p.caFile = cfg.TLSClientCAFile
+
+	if err := p.reload(); err != nil {
+		p.Close()
+		return nil, err
+	}
+	if err := p.startWatcher(); err != nil {
+		p.Close()
+		return nil, err
+	}
+	if p.caFile != "" {
+		log.Info().Str("ca", p.caFile).Msg("Client certificates verified against CA bundle")
+	}
+	return p, nil
+}
+
+// startWatcher watches the directories of the files read from disk, which
+// also catches the symlink swap Kubernetes uses to update mounted secrets.
+func (p *Provider) startWatcher() error {
+	dirs := make(map[string]bool)
+	for _, f := range []string{p.certFile, p.keyFile, p.caFile} {
+		if f != "" {
+			dirs[filepath.Dir(f)] = true
+		}
+	}
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create certificate watcher: %w", err)
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+	p.watcher = watcher
+	p.wg.Add(1)
+	go p.watch()
+	return nil
+}
+
+// newACMEProvider obtains certificates for the configured domains on first
+// use and renews them before they expire.
+func newACMEProvider(cfg *config.Config) *Provider {
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(cfg.TLSACMECacheDir),
+		HostPolicy: autocert.HostWhitelist(cfg.TLSACMEDomains...),
+		Email:      cfg.TLSACMEEmail,
+	}
+	if cfg.TLSACMEDirectoryURL != "" {
+		m.Client = &acme.Client{DirectoryURL: cfg.TLSACMEDirectoryURL}
+	}
+	p := &Provider{acme: m}
+
+	// TLS-ALPN challenges are answered on the HTTPS listener itself;
+	// HTTP-01 needs a plain listener, usually on port 80
+	if cfg.TLSACMEHTTPAddr != "" {
+		p.challengeSrv = &http.Server{
+			Addr:              cfg.TLSACMEHTTPAddr,
+			Handler:           m.HTTPHandler(nil),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			if err := p.challengeSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error().Err(err).Str("addr", cfg.TLSACMEHTTPAddr).Msg("ACME HTTP challenge listener failed")
+			}
+		}()
+	}
+
+	log.Info().
+		Strs("domains", cfg.TLSACMEDomains).
+		Str("cache_dir", cfg.TLSACMECacheDir).
+		Str("http_challenge_addr", cfg.TLSACMEHTTPAddr).
+		Msg("TLS enabled with ACME certificates")
+	return p
+}
+
+// TLSConfig returns a server TLS configuration backed by the Provider.
+func (p *Provider) TLSConfig() *tls.Config {
+	if p.acme != nil {
+		tc := p.acme.TLSConfig()
+		tc.MinVersion = tls.VersionTLS12
+		return tc
+	}
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{"h2", "http/1.1"},
+		GetCertificate: p.getCertificate,
+	}
+}
+
+// APITLSConfig is TLSConfig for the API listener: with a client CA bundle,
+// client certificates are requested and verified against it. Whether one
+// is required is left to the ClientCert middleware, which exempts /health.
+func (p *Provider) APITLSConfig() *tls.Config {
+	tc := p.TLSConfig()
+	if p.caFile == "" {
+		return tc
+	}
+	tc.ClientAuth = tls.VerifyClientCertIfGiven
+	tc.ClientCAs = p.currentClientCAs()
+	// A fresh config per handshake picks up a reloaded bundle
+	base := tc.Clone()
+	tc.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		c := base.Clone()
+		c.ClientCAs = p.currentClientCAs()
+		return c, nil
+	}
+	return tc
+}
+
+func (p *Provider) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.cert, nil
+}
+
+func (p *Provider) currentClientCAs() *x509.CertPool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.clientCAs
+}
+
+// reload reads the certificate, key and client CA bundle from disk. On
+// failure the previous ones stay in use.
+func (p *Provider) reload() error {
+	var cert *tls.Certificate
+	if p.certFile != "" {
+		c, err := tls.LoadX509KeyPair(p.certFile, p.keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		cert = &c
+	}
+	var pool *x509.CertPool
+	if p.caFile != "" {
+		pem, err := os.ReadFile(p.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA bundle: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("client CA bundle %s contains no PEM certificates", p.caFile)
+		}
+	}
+	p.mu.Lock()
+	if cert != nil {
+		p.cert = cert
+	}
+	if pool != nil {
+		p.clientCAs = pool
+	}
+	p.mu.Unlock()
+	return nil
+}
+
+// watch reloads the certificate after changes in its directories settle.
+func (p *Provider) watch() {
+	defer p.wg.Done()
+	for {
+		select {
+		case event, ok := <-p.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			p.mu.Lock()
+			if p.reloadPending != nil {
+				p.reloadPending.Stop()
+			}
+			p.reloadPending = time.AfterFunc(reloadDelay, func() {
+				if err := p.reload(); err != nil {
+					log.Warn().Err(err).Msg("TLS certificate reload failed, keeping the previous certificate")
+					return
+				}
+				log.Info().Msg("TLS certificates reloaded")
+			})
+			p.mu.Unlock()
+		case err, ok := <-p.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Warn().Err(err).Msg("TLS certificate watcher error")
+		}
+	}
+}
+
+// Close stops the certificate watcher and the ACME challenge listener.
+func (p *Provider) Close() {
+	if p == nil {
+		return
+	}
+	p.closeOnce.Do(func() {
+		if p.watcher != nil {
+			_ = p.watcher.Close()
+		}
+		if p.challengeSrv != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			_ = p.challengeSrv.Shutdown(ctx)
+			cancel()
+		}
+		p.wg.Wait()
+		p.mu.Lock()
+		if p.reloadPending != nil {
+			p.reloadPending.Stop()
+		}
+		p.mu.Unlock()
+	})
+}
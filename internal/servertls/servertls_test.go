@@ -0,0 +1,248 @@
+package servertls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+)
+
+// writeCert writes a self-signed certificate for commonName and its key.
+func writeCert(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Key first, so the watcher never pairs the new certificate with the old key
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func commonName(t *testing.T, p *Provider) string {
+	t.Helper()
+	cert, err := p.TLSConfig().GetCertificate(nil)
+	if err != nil || cert == nil {
+		t.Fatalf("GetCertificate() = %v, %v", cert, err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestNewWithoutTLS(t *testing.T) {
+	p, err := New(&config.Config{})
+	if p != nil || err != nil {
+		t.Fatalf("New() without TLS settings = %v, %v; want nil, nil", p, err)
+	}
+	p.Close()
+}
+
+func TestCertificateReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, "first")
+
+	p, err := New(&config.Config{TLSCertFile: certFile, TLSKeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer p.Close()
+	if got := commonName(t, p); got != "first" {
+		t.Fatalf("certificate = %q, want first", got)
+	}
+
+	writeCert(t, certFile, keyFile, "second")
+	deadline := time.Now().Add(5 * time.Second)
+	for commonName(t, p) != "second" {
+		if time.Now().After(deadline) {
+			t.Fatal("certificate was not reloaded after the files changed")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// A broken update keeps the working certificate
+	if err := os.WriteFile(certFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * reloadDelay)
+	if got := commonName(t, p); got != "second" {
+		t.Errorf("certificate after a bad update = %q, want second", got)
+	}
+}
+
+func TestNewRejectsMissingCertificate(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := New(&config.Config{TLSCertFile: filepath.Join(dir, "a.crt"), TLSKeyFile: filepath.Join(dir, "a.key")}); err == nil {
+		t.Error("New() with missing files: expected error")
+	}
+}
+
+func TestACMEConfig(t *testing.T) {
+	p, err := New(&config.Config{TLSACMEDomains: []string{"solver.example.com"}, TLSACMECacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer p.Close()
+	tc := p.TLSConfig()
+	if tc.GetCertificate == nil || tc.MinVersion != tls.VersionTLS12 {
+		t.Errorf("ACME TLS config = %+v", tc)
+	}
+	hasALPN := false
+	for _, proto := range tc.NextProtos {
+		hasALPN = hasALPN || proto == "acme-tls/1"
+	}
+	if !hasALPN {
+		t.Errorf("NextProtos = %v, want acme-tls/1 for TLS-ALPN challenges", tc.NextProtos)
+	}
+}
+
+// newCA returns a CA certificate and key for signing client certificates.
+func newCA(t *testing.T, name string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ca, key
+}
+
+// clientCert issues a client certificate for commonName signed by ca.
+func clientCert(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, commonName string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestClientCertificateVerification(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "clients.pem")
+	writeCert(t, certFile, keyFile, "server")
+	ca, caKey := newCA(t, "workers")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := New(&config.Config{TLSCertFile: certFile, TLSKeyFile: keyFile, TLSClientCAFile: caFile})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer p.Close()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+			_, _ = io.WriteString(w, r.TLS.VerifiedChains[0][0].Subject.CommonName)
+		}
+	}))
+	srv.TLS = p.APITLSConfig()
+	srv.StartTLS()
+	defer srv.Close()
+
+	get := func(certs ...tls.Certificate) (string, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true, //nolint:gosec // self-signed test server
+			// Send the certificate even when the server asks for other CAs
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				if len(certs) == 0 {
+					return &tls.Certificate{}, nil
+				}
+				return &certs[0], nil
+			},
+		}}}
+		defer client.CloseIdleConnections()
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	if name, err := get(clientCert(t, ca, caKey, "worker-1")); err != nil || name != "worker-1" {
+		t.Errorf("trusted client certificate: %q, %v; want worker-1", name, err)
+	}
+	if name, err := get(); err != nil || name != "" {
+		t.Errorf("no client certificate: %q, %v; want an unverified connection", name, err)
+	}
+	other, otherKey := newCA(t, "elsewhere")
+	if _, err := get(clientCert(t, other, otherKey, "intruder")); err == nil {
+		t.Error("certificate from another CA: expected handshake failure")
+	}
+}
+
+func TestNewRejectsEmptyClientCA(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "clients.pem")
+	writeCert(t, certFile, keyFile, "server")
+	if err := os.WriteFile(caFile, []byte("no certificates here"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(&config.Config{TLSCertFile: certFile, TLSKeyFile: keyFile, TLSClientCAFile: caFile}); err == nil {
+		t.Error("New() with a CA bundle without certificates: expected error")
+	}
+}
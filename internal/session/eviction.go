@@ -0,0 +1,112 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+	"github.com/Rorqualx/flaresolverr-go/internal/events"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// evictionDrainTimeout bounds the wait for stray page references on an
+// evicted session. Only idle sessions are evicted, so this rarely applies.
+const evictionDrainTimeout = 2 * time.Second
+
+// MakeRoom ensures a new session fits under MaxSessions. When the manager is
+// full it returns ErrTooManySessions under the reject policy, or destroys the
+// least recently used idle session under the lru policy.
+//
+// Session creation calls it before taking a browser from the pool: every
+// session pins a browser, so a full manager would otherwise block there.
+func (m *Manager) MakeRoom() error {
+	m.mu.Lock()
+	victim, err := m.makeRoomLocked()
+	m.mu.Unlock()
+
+	if victim != nil {
+		m.evict(victim)
+	}
+	return err
+}
+
+// makeRoomLocked checks the session limit and, under the lru policy, detaches
+// the eviction victim from the map. The caller must hold m.mu and must pass
+// a returned victim to evict after unlocking.
+func (m *Manager) makeRoomLocked() (*Session, error) {
+	if len(m.sessions) < m.config.MaxSessions {
+		return nil, nil
+	}
+	if m.config.SessionEvictionPolicy != config.SessionEvictionLRU {
+		return nil, types.ErrTooManySessions
+	}
+
+	var victim *Session
+	for _, s := range m.sessions {
+		// Sessions in use are never evicted from under a request
+		if s.closing.Load() || s.solving.Load() > 0 || s.refCount.Load() > 0 {
+			continue
+		}
+		if victim == nil || s.LastUsedTime().Before(victim.LastUsedTime()) {
+			victim = s
+		}
+	}
+	if victim == nil {
+		return nil, types.ErrTooManySessions
+	}
+
+	// Mark closing BEFORE removing from the map so no new page reference is handed out
+	victim.closing.Store(true)
+	delete(m.sessions, victim.ID)
+	return victim, nil
+}
+
+// evict tears down a session detached by makeRoomLocked. Its snapshot is
+// written rather than removed, so a client re-creating the session with the
+// same ID gets its cookies back.
+func (m *Manager) evict(sess *Session) {
+	if !sess.waitForReferences(evictionDrainTimeout) {
+		log.Warn().
+			Str("session_id", sess.ID).
+			Int32("ref_count", sess.refCount.Load()).
+			Msg("Eviction: references still held, proceeding with cleanup anyway")
+	}
+
+	sess.mu.Lock()
+	page := sess.Page
+	sess.Page = nil
+	sess.mu.Unlock()
+
+	if page != nil && m.store != nil {
+		if err := m.writeSnapshot(sess, page); err != nil {
+			log.Warn().Err(err).Str("session_id", sess.ID).Msg("Failed to persist evicted session")
+		}
+	}
+	if page != nil {
+		if err := page.Close(); err != nil {
+			log.Warn().Err(err).Str("session_id", sess.ID).Msg("Error closing evicted session page")
+		}
+	}
+	// CleanupBrowser also removes the on-disk user-data dir; plain Close() leaks it.
+	if sess.Browser != nil {
+		switch {
+		case sess.OwnsBrowser && m.pool != nil:
+			m.pool.CleanupBrowser(sess.Browser)
+		case sess.OwnsBrowser:
+			if err := sess.Browser.Close(); err != nil {
+				log.Warn().Err(err).Str("session_id", sess.ID).Msg("Error closing evicted session's browser")
+			}
+		case m.pool != nil:
+			m.pool.Release(sess.Browser)
+		}
+	}
+
+	log.Info().
+		Str("session_id", sess.ID).
+		Dur("idle", time.Since(sess.LastUsedTime())).
+		Dur("lifetime", time.Since(sess.CreatedAt)).
+		Msg("Session evicted to stay within MAX_SESSIONS")
+	events.Publish(context.Background(), events.Event{Type: events.SessionDestroyed, Session: sess.ID, Reason: "evicted"})
+}
@@ -0,0 +1,412 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/browser"
+	"github.com/Rorqualx/flaresolverr-go/internal/config"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// snapshotTimeout bounds the CDP calls made while capturing or restoring a snapshot.
+const snapshotTimeout = 5 * time.Second
+
+// Snapshot is the on-disk representation of a session's restorable state.
+// It is written after each successful solve on the session and on shutdown,
+// and consumed by sessions.create with the same ID after a restart.
+//
+// Proxy credentials are never written: ProxyURL only records which proxy the
+// session exited through, and restoring it requires the client to send the
+// proxy, credentials included, again.
+type Snapshot struct {
+	ID           string                 `json:"id"`
+	Cookies      []*proto.NetworkCookie `json:"cookies,omitempty"`
+	Origin       string                 `json:"origin,omitempty"`       // Origin the localStorage items belong to
+	LocalStorage map[string]string      `json:"localStorage,omitempty"` // localStorage of the last visited origin
+	UserAgent    string                 `json:"userAgent,omitempty"`
+	ProxyURL     string                 `json:"proxyUrl,omitempty"`     // Without credentials
+	ProxySession string                 `json:"proxySession,omitempty"` // Sticky-session token of a residential proxy
+	Timezone     string                 `json:"timezone,omitempty"`
+	TTL          time.Duration          `json:"ttl,omitempty"`
+	SavedAt      time.Time              `json:"savedAt"`
+}
+
+// newStore builds the configured snapshot store: Redis when SESSION_REDIS_URL
+// is set, otherwise a directory when SESSION_PERSIST_DIR is set. Returns nil
+// (persistence disabled, with a warning) if neither is set or setup fails.
+func newStore(cfg *config.Config) Store {
+	switch {
+	case cfg.SessionRedisURL != "":
+		store, err := NewRedisStore(cfg.SessionRedisURL, cfg.SessionRedisPrefix)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to initialize Redis session store, persistence disabled")
+			return nil
+		}
+		log.Info().Str("prefix", cfg.SessionRedisPrefix).Msg("Redis session store enabled")
+		return store
+	case cfg.SessionPersistDir != "":
+		store, err := NewFileStore(cfg.SessionPersistDir)
+		if err != nil {
+			log.Warn().Err(err).Str("dir", cfg.SessionPersistDir).Msg("Failed to initialize session store, persistence disabled")
+			return nil
+		}
+		log.Info().Str("dir", cfg.SessionPersistDir).Msg("Session persistence enabled")
+		return store
+	}
+	return nil
+}
+
+// Persist writes the session's cookies, localStorage, user agent, proxy
+// (without credentials) and timezone to the store. It is a no-op when persistence is disabled.
+func (m *Manager) Persist(sess *Session) error {
+	if m.store == nil || sess == nil {
+		return nil
+	}
+	page, release := sess.AcquirePageWithRelease()
+	if page == nil {
+		return types.ErrSessionPageNil
+	}
+	defer release()
+	return m.writeSnapshot(sess, page)
+}
+
+// writeSnapshot captures state from page and saves it to the store.
+func (m *Manager) writeSnapshot(sess *Session, page *rod.Page) error {
+	snap, err := captureSnapshot(sess, page)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := m.store.Save(ctx, snap, sess.EffectiveTTL(m.config.SessionTTL)); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("session_id", sess.ID).
+		Int("cookies", len(snap.Cookies)).
+		Int("local_storage", len(snap.LocalStorage)).
+		Msg("Session snapshot saved")
+	return nil
+}
+
+// captureSnapshot reads the cookie jar and current-origin localStorage from
+// page along with the session's recorded metadata.
+func captureSnapshot(sess *Session, page *rod.Page) (*Snapshot, error) {
+	p := page.Timeout(snapshotTimeout)
+
+	cookies, err := p.Cookies(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session cookies: %w", err)
+	}
+
+	sess.mu.Lock()
+	snap := Snapshot{
+		ID:           sess.ID,
+		Cookies:      cookies,
+		UserAgent:    sess.UserAgent,
+		ProxyURL:     stripProxyCredentials(sess.ProxyURL),
+		ProxySession: sess.ProxySession,
+		Timezone:     sess.Timezone,
+		TTL:          sess.TTL,
+		SavedAt:      time.Now(),
+	}
+	sess.mu.Unlock()
+
+	// localStorage is best-effort: about:blank and opaque origins have none
+	res, err := p.Eval(`() => {
+		try {
+			const items = {};
+			for (let i = 0; i < localStorage.length; i++) {
+				const k = localStorage.key(i);
+				items[k] = localStorage.getItem(k);
+			}
+			return { origin: location.origin, items: items };
+		} catch (e) {
+			return { origin: "", items: {} };
+		}
+	}`)
+	if err != nil {
+		log.Debug().Err(err).Str("session_id", sess.ID).Msg("Could not capture localStorage for snapshot")
+	} else {
+		var ls struct {
+			Origin string            `json:"origin"`
+			Items  map[string]string `json:"items"`
+		}
+		if err := res.Value.Unmarshal(&ls); err == nil && ls.Origin != "" && ls.Origin != "null" && len(ls.Items) > 0 {
+			snap.Origin = ls.Origin
+			snap.LocalStorage = ls.Items
+		}
+	}
+
+	return &snap, nil
+}
+
+// LoadSnapshot returns the persisted snapshot for a session ID, or nil if
+// persistence is disabled, no snapshot exists, or the snapshot has outlived
+// its TTL (in which case it is removed).
+func (m *Manager) LoadSnapshot(id string) (*Snapshot, error) {
+	if m.store == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	snap, err := m.store.Load(ctx, id)
+	if err != nil || snap == nil {
+		return nil, err
+	}
+
+	ttl := m.config.SessionTTL
+	if snap.TTL > 0 {
+		ttl = snap.TTL
+	}
+	if time.Since(snap.SavedAt) > ttl {
+		log.Debug().Str("session_id", id).Msg("Discarding expired session snapshot")
+		m.removeSnapshot(id)
+		return nil, nil
+	}
+	// The store is shared and may have been written by anything; never hand
+	// on credentials Save would not have kept
+	snap.ProxyURL = stripProxyCredentials(snap.ProxyURL)
+
+	return snap, nil
+}
+
+// Restore applies a snapshot to a freshly created session: cookies and
+// localStorage are loaded into its page and the recorded user agent, sticky
+// proxy session and timezone are carried over. The proxy itself comes from
+// the restoring request. Failures are logged and skipped so a partial
+// restore still yields a usable session.
+func (m *Manager) Restore(sess *Session, snap *Snapshot) {
+	if sess == nil || snap == nil {
+		return
+	}
+	page, release := sess.AcquirePageWithRelease()
+	if page == nil {
+		return
+	}
+	defer release()
+	p := page.Timeout(snapshotTimeout)
+
+	if snap.UserAgent != "" {
+		if err := browser.SetUserAgent(p, snap.UserAgent); err != nil {
+			log.Warn().Err(err).Str("session_id", sess.ID).Msg("Failed to restore session user agent")
+		}
+	}
+
+	if len(snap.Cookies) > 0 {
+		params := make([]*proto.NetworkCookieParam, 0, len(snap.Cookies))
+		for _, c := range snap.Cookies {
+			param := &proto.NetworkCookieParam{
+				Name:         c.Name,
+				Value:        c.Value,
+				Domain:       c.Domain,
+				Path:         c.Path,
+				Secure:       c.Secure,
+				HTTPOnly:     c.HTTPOnly,
+				SameSite:     c.SameSite,
+				Priority:     c.Priority,
+				SourceScheme: c.SourceScheme,
+			}
+			if !c.Session {
+				param.Expires = c.Expires
+			}
+			params = append(params, param)
+		}
+		if err := p.SetCookies(params); err != nil {
+			log.Warn().Err(err).Str("session_id", sess.ID).Msg("Failed to restore session cookies")
+		}
+	}
+
+	if snap.Origin != "" && len(snap.LocalStorage) > 0 {
+		if err := (proto.DOMStorageEnable{}).Call(p); err != nil {
+			log.Debug().Err(err).Msg("DOMStorage.enable failed")
+		}
+		storageID := &proto.DOMStorageStorageID{SecurityOrigin: snap.Origin, IsLocalStorage: true}
+		for k, v := range snap.LocalStorage {
+			if err := (proto.DOMStorageSetDOMStorageItem{StorageID: storageID, Key: k, Value: v}).Call(p); err != nil {
+				log.Warn().Err(err).Str("session_id", sess.ID).Msg("Failed to restore localStorage item")
+				break
+			}
+		}
+	}
+
+	sess.mu.Lock()
+	if sess.UserAgent == "" {
+		sess.UserAgent = snap.UserAgent
+	}
+	if sess.ProxySession == "" {
+		sess.ProxySession = snap.ProxySession
+	}
+	if sess.Timezone == "" {
+		sess.Timezone = snap.Timezone
+	}
+	if sess.TTL == 0 {
+		sess.TTL = snap.TTL
+	}
+	sess.mu.Unlock()
+
+	log.Info().
+		Str("session_id", sess.ID).
+		Int("cookies", len(snap.Cookies)).
+		Int("local_storage", len(snap.LocalStorage)).
+		Time("saved_at", snap.SavedAt).
+		Msg("Session restored from snapshot")
+}
+
+// CheckProxy verifies proxy is the one the snapshot's session exited
+// through, since its cf_clearance is bound to that egress. It returns
+// ErrSessionProxyRequired when the session was saved behind a proxy and none
+// is given, and ErrSessionProxyMismatch when another one is.
+// Credentials are not compared; the snapshot holds none.
+func (s *Snapshot) CheckProxy(proxy *types.Proxy) error {
+	requested := ""
+	if proxy != nil {
+		requested = stripProxyCredentials(proxy.URL)
+	}
+	switch {
+	case requested == s.ProxyURL:
+		return nil
+	case requested == "":
+		return fmt.Errorf("%w (%s)", types.ErrSessionProxyRequired, s.ProxyURL)
+	case s.ProxyURL == "":
+		return fmt.Errorf("%w (saved without a proxy, request names %s)", types.ErrSessionProxyMismatch, requested)
+	default:
+		return fmt.Errorf("%w (saved behind %s, request names %s)", types.ErrSessionProxyMismatch, s.ProxyURL, requested)
+	}
+}
+
+// stripProxyCredentials returns proxyURL without its user info. A URL that
+// does not parse is dropped rather than risk storing a secret.
+func stripProxyCredentials(proxyURL string) string {
+	if proxyURL == "" {
+		return ""
+	}
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+	return u.String()
+}
+
+// removeSnapshot deletes a session's snapshot, if any.
+func (m *Manager) removeSnapshot(id string) {
+	if m.store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := m.store.Delete(ctx, id); err != nil {
+		log.Warn().Err(err).Str("session_id", id).Msg("Failed to remove session snapshot")
+	}
+}
+
+// pruneSnapshot removes a locally expired session's snapshot unless it was
+// refreshed more recently (e.g. by another instance sharing the store).
+func (m *Manager) pruneSnapshot(id string) {
+	if _, err := m.LoadSnapshot(id); err != nil {
+		log.Debug().Err(err).Str("session_id", id).Msg("Failed to check session snapshot during cleanup")
+	}
+}
+
+// closeStore releases the snapshot store after shutdown snapshots are written.
+func (m *Manager) closeStore() {
+	if m.store == nil {
+		return
+	}
+	if err := m.store.Close(); err != nil {
+		log.Warn().Err(err).Msg("Error closing session store")
+	}
+}
+
+// Export captures the session's cookie jar, localStorage and user agent in the
+// portable sessions.export format.
+func (m *Manager) Export(sess *Session) (*types.SessionState, error) {
+	page, release := sess.AcquirePageWithRelease()
+	if page == nil {
+		return nil, types.ErrSessionPageNil
+	}
+	defer release()
+
+	snap, err := captureSnapshot(sess, page)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &types.SessionState{
+		Cookies:      make([]types.Cookie, 0, len(snap.Cookies)),
+		Origin:       snap.Origin,
+		LocalStorage: snap.LocalStorage,
+		UserAgent:    snap.UserAgent,
+	}
+	for _, c := range snap.Cookies {
+		state.Cookies = append(state.Cookies, types.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Expires:  float64(c.Expires),
+			Size:     c.Size,
+			HTTPOnly: c.HTTPOnly,
+			Secure:   c.Secure,
+			Session:  c.Session,
+			SameSite: string(c.SameSite),
+		})
+	}
+	return state, nil
+}
+
+// Import loads a sessions.export state into the session's page. The imported
+// user agent replaces the session's, since cf_clearance is bound to it.
+// Individual cookie or localStorage failures are logged, as in Restore.
+func (m *Manager) Import(sess *Session, state *types.SessionState) error {
+	page, release := sess.AcquirePageWithRelease()
+	if page == nil {
+		return types.ErrSessionPageNil
+	}
+	defer release()
+
+	snap := &Snapshot{
+		ID:           sess.ID,
+		Cookies:      make([]*proto.NetworkCookie, 0, len(state.Cookies)),
+		Origin:       state.Origin,
+		LocalStorage: state.LocalStorage,
+		UserAgent:    state.UserAgent,
+		SavedAt:      time.Now(),
+	}
+	for _, c := range state.Cookies {
+		path := c.Path
+		if path == "" {
+			path = "/"
+		}
+		snap.Cookies = append(snap.Cookies, &proto.NetworkCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     path,
+			Expires:  proto.TimeSinceEpoch(c.Expires),
+			HTTPOnly: c.HTTPOnly,
+			Secure:   c.Secure,
+			Session:  c.Session || c.Expires <= 0,
+			SameSite: proto.NetworkCookieSameSite(c.SameSite),
+		})
+	}
+	m.Restore(sess, snap)
+
+	if state.UserAgent != "" {
+		sess.mu.Lock()
+		sess.UserAgent = state.UserAgent
+		sess.mu.Unlock()
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/browser"
+	"github.com/Rorqualx/flaresolverr-go/internal/security"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// rotationTimeout bounds the CDP calls made while moving a session to a new browser.
+const rotationTimeout = 10 * time.Second
+
+// errNoRotation is returned by RotateProxy when no rotation list is configured.
+var errNoRotation = errors.New("session proxy rotation is not configured")
+
+// SetProxyRotation enables per-session proxy rotation: once a session's solves
+// have been denied `after` times in a row, RecordSolveOutcome asks the caller to
+// move it to the next proxy in proxies.
+func (m *Manager) SetProxyRotation(proxies []*types.Proxy, after int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rotation = proxies
+	m.rotateAfter = after
+}
+
+// RecordSolveOutcome tracks consecutive access_denied failures for a session
+// and reports whether it has reached the rotation threshold.
+func (m *Manager) RecordSolveOutcome(sess *Session, accessDenied bool) (rotate bool) {
+	if !accessDenied {
+		sess.deniedStreak.Store(0)
+		return false
+	}
+	streak := sess.deniedStreak.Add(1)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.rotation) > 0 && m.rotateAfter > 0 && int(streak) >= m.rotateAfter
+}
+
+// nextRotationProxy returns the rotation entry after current, the launch URL
+// of the session's proxy, wrapping around. A session not on a rotation proxy
+// starts at the first entry.
+func (m *Manager) nextRotationProxy(current string) *types.Proxy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.rotation) == 0 {
+		return nil
+	}
+	for i, p := range m.rotation {
+		if rotationLaunchURL(p) == current {
+			return m.rotation[(i+1)%len(m.rotation)]
+		}
+	}
+	return m.rotation[0]
+}
+
+// rotationLaunchURL is the URL a session browser behind p is launched with,
+// the way sessions.create launches one: SOCKS5 credentials go into the URL
+// for the forwarder, and HTTP proxies are answered per solve from
+// Session.Proxy.
+func rotationLaunchURL(p *types.Proxy) string {
+	return browser.ProxyLaunchURL(p.URL, p.Username, p.Password)
+}
+
+// RotateProxy respawns the session's browser behind the next proxy in the
+// rotation list. Cookies are carried over except Cloudflare's, which are bound
+// to the old egress IP and would only get the new one challenged or banned.
+//
+// The caller must hold the session's operation lock and must have released
+// its own page reference.
+func (m *Manager) RotateProxy(ctx context.Context, sess *Session) error {
+	if m.pool == nil {
+		return errNoRotation
+	}
+	sess.mu.Lock()
+	currentProxy := sess.ProxyURL
+	sess.mu.Unlock()
+
+	next := m.nextRotationProxy(currentProxy)
+	if next == nil {
+		return errNoRotation
+	}
+
+	cookies := carryOverCookies(sess)
+
+	launchURL := rotationLaunchURL(next)
+	newBrowser, err := m.pool.SpawnWithOptions(ctx, browser.LaunchOptions{
+		ProxyURL: launchURL,
+		Timezone: sess.Timezone,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to spawn browser for proxy rotation: %w", err)
+	}
+	newPage, err := newBrowser.Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		m.pool.CleanupBrowser(newBrowser)
+		return fmt.Errorf("failed to open page for proxy rotation: %w", err)
+	}
+
+	p := newPage.Timeout(rotationTimeout)
+	if sess.Timezone != "" {
+		if err := browser.ApplyTimezoneOverride(p, sess.Timezone); err != nil {
+			log.Warn().Err(err).Str("session_id", sess.ID).Msg("Failed to apply timezone override after proxy rotation")
+		}
+	}
+	if len(cookies) > 0 {
+		if err := p.SetCookies(cookies); err != nil {
+			log.Warn().Err(err).Str("session_id", sess.ID).Msg("Failed to carry cookies over to rotated browser")
+		}
+	}
+
+	sess.mu.Lock()
+	if sess.closing.Load() {
+		sess.mu.Unlock()
+		m.pool.CleanupBrowser(newBrowser)
+		return types.ErrSessionNotFound
+	}
+	oldBrowser, oldPage, ownedOld := sess.Browser, sess.Page, sess.OwnsBrowser
+	sess.Browser = newBrowser
+	sess.Page = newPage
+	sess.OwnsBrowser = true
+	sess.ProxyURL = launchURL
+	sess.Proxy = next
+	sess.ProxySession = ""
+	sess.mu.Unlock()
+	sess.deniedStreak.Store(0)
+
+	// Readers that grabbed the old page just before the swap get a moment to finish
+	if !sess.waitForReferences(5 * time.Second) {
+		log.Warn().Str("session_id", sess.ID).Msg("Closing pre-rotation page with references still held")
+	}
+	m.closeReplacedBrowser(sess.ID, oldBrowser, oldPage, ownedOld)
+
+	log.Info().
+		Str("session_id", sess.ID).
+		Str("from_proxy", security.RedactProxyURL(currentProxy)).
+		Str("to_proxy", security.RedactProxyURL(launchURL)).
+		Int("cookies_kept", len(cookies)).
+		Msg("Session proxy rotated after repeated access denials")
+	return nil
+}
+
+// carryOverCookies reads the session's cookie jar, minus Cloudflare cookies,
+// as parameters ready to set on a fresh page.
+func carryOverCookies(sess *Session) []*proto.NetworkCookieParam {
+	page, release := sess.AcquirePageWithRelease()
+	if page == nil {
+		return nil
+	}
+	defer release()
+
+	res, err := proto.NetworkGetAllCookies{}.Call(page.Timeout(rotationTimeout))
+	if res == nil {
+		log.Warn().Err(err).Str("session_id", sess.ID).Msg("Failed to read cookies before proxy rotation")
+		return nil
+	}
+
+	params := make([]*proto.NetworkCookieParam, 0, len(res.Cookies))
+	for _, c := range res.Cookies {
+		if isCloudflareCookie(c.Name) {
+			continue
+		}
+		param := &proto.NetworkCookieParam{
+			Name:         c.Name,
+			Value:        c.Value,
+			Domain:       c.Domain,
+			Path:         c.Path,
+			Secure:       c.Secure,
+			HTTPOnly:     c.HTTPOnly,
+			SameSite:     c.SameSite,
+			Priority:     c.Priority,
+			SourceScheme: c.SourceScheme,
+		}
+		if !c.Session {
+			param.Expires = c.Expires
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// isCloudflareCookie reports whether a cookie is set by Cloudflare
+// (cf_clearance, __cf_bm, __cflb, __cfruid, _cfuvid, cf_chl_* ...).
+func isCloudflareCookie(name string) bool {
+	return strings.HasPrefix(name, "cf_") || strings.HasPrefix(name, "__cf") || name == "_cfuvid"
+}
+
+// closeReplacedBrowser tears down the page and browser a session used before
+// its proxy was rotated.
+func (m *Manager) closeReplacedBrowser(id string, brow *rod.Browser, page *rod.Page, owned bool) {
+	if page != nil {
+		if err := page.Close(); err != nil {
+			log.Debug().Err(err).Str("session_id", id).Msg("Error closing pre-rotation session page")
+		}
+	}
+	if brow == nil {
+		return
+	}
+	if owned {
+		m.pool.CleanupBrowser(brow)
+	} else {
+		m.pool.Release(brow)
+	}
+}
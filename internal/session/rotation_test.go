@@ -0,0 +1,96 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func TestIsCloudflareCookie(t *testing.T) {
+	tests := map[string]bool{
+		"cf_clearance":    true,
+		"__cf_bm":         true,
+		"__cflb":          true,
+		"__cfruid":        true,
+		"_cfuvid":         true,
+		"cf_chl_rc_ni":    true,
+		"session_id":      false,
+		"_ga":             false,
+		"csrftoken":       false,
+		"cfg_preferences": false,
+	}
+	for name, want := range tests {
+		if got := isCloudflareCookie(name); got != want {
+			t.Errorf("isCloudflareCookie(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestRecordSolveOutcome(t *testing.T) {
+	m := NewManager(testConfig(), nil)
+	defer m.Close()
+	sess := &Session{ID: "rotation-test-session"}
+
+	// Without a rotation list the streak is tracked but never triggers
+	for i := 0; i < 5; i++ {
+		if m.RecordSolveOutcome(sess, true) {
+			t.Fatal("rotation requested without a rotation list")
+		}
+	}
+
+	m.SetProxyRotation([]*types.Proxy{{URL: "http://a:8080"}, {URL: "http://b:8080"}}, 2)
+	m.RecordSolveOutcome(sess, false)
+	if m.RecordSolveOutcome(sess, true) {
+		t.Error("rotation requested after a single denial")
+	}
+	if !m.RecordSolveOutcome(sess, true) {
+		t.Error("rotation not requested after reaching the threshold")
+	}
+	if m.RecordSolveOutcome(sess, false) {
+		t.Error("rotation requested after a successful solve")
+	}
+}
+
+func TestNextRotationProxy(t *testing.T) {
+	m := NewManager(testConfig(), nil)
+	defer m.Close()
+
+	if p := m.nextRotationProxy(""); p != nil {
+		t.Errorf("expected nil without a rotation list, got %v", p)
+	}
+
+	m.SetProxyRotation([]*types.Proxy{{URL: "http://a:8080"}, {URL: "http://b:8080"}}, 3)
+	tests := []struct{ current, want string }{
+		{"", "http://a:8080"},
+		{"http://other:3128", "http://a:8080"},
+		{"http://a:8080", "http://b:8080"},
+		{"http://b:8080", "http://a:8080"},
+	}
+	for _, tt := range tests {
+		if got := m.nextRotationProxy(tt.current); got.URL != tt.want {
+			t.Errorf("nextRotationProxy(%q) = %q, want %q", tt.current, got.URL, tt.want)
+		}
+	}
+
+	// A SOCKS5 session is launched with its credentials in the URL
+	m.SetProxyRotation([]*types.Proxy{
+		{URL: "socks5://a:1080", Username: "user", Password: "pass"},
+		{URL: "http://b:8080", Username: "user", Password: "pass"},
+	}, 3)
+	if got := m.nextRotationProxy("socks5://user:pass@a:1080"); got.URL != "http://b:8080" {
+		t.Errorf("nextRotationProxy(socks5 launch URL) = %q, want http://b:8080", got.URL)
+	}
+	if got := rotationLaunchURL(m.rotation[0]); got != "socks5://user:pass@a:1080" {
+		t.Errorf("rotationLaunchURL(socks5) = %q, want credentials in the URL", got)
+	}
+	if got := rotationLaunchURL(m.rotation[1]); got != "http://b:8080" {
+		t.Errorf("rotationLaunchURL(http) = %q, want the bare URL", got)
+	}
+
+	// Rotation needs a pool to spawn browsers from
+	if err := m.RotateProxy(context.Background(), &Session{ID: "rotation-test-session"}); !errors.Is(err, errNoRotation) {
+		t.Errorf("RotateProxy without pool error = %v, want errNoRotation", err)
+	}
+}
@@ -3,6 +3,7 @@
 package session
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -14,6 +15,8 @@ import (
 
 	"github.com/Rorqualx/flaresolverr-go/internal/browser"
 	"github.com/Rorqualx/flaresolverr-go/internal/config"
+	"github.com/Rorqualx/flaresolverr-go/internal/events"
+	"github.com/Rorqualx/flaresolverr-go/internal/security"
 	"github.com/Rorqualx/flaresolverr-go/internal/types"
 )
 
@@ -21,6 +24,14 @@ import (
 // This prevents unbounded growth from bugs or malicious usage.
 const maxPageReferences = 100
 
+// detailsTimeout bounds all the CDP queries made for one sessions.info, so
+// busy or hung pages cannot stall the command; detailsConcurrency bounds how
+// many sessions are queried at once.
+const (
+	detailsTimeout     = 3 * time.Second
+	detailsConcurrency = 8
+)
+
 // Session represents a persistent browser session.
 // Sessions maintain their own page and cookies across multiple requests.
 //
@@ -55,6 +66,25 @@ type Session struct {
 	// Timezone is the IANA timezone applied to this session's page via CDP at creation.
 	// Empty means no per-session override; callers may apply a global default instead.
 	Timezone string
+
+	// UserAgent and ProxyURL are recorded so they can be persisted with the
+	// session snapshot and restored after a restart.
+	UserAgent string
+	ProxyURL  string
+
+	// Proxy is the session's proxy with credentials, answered to proxy auth
+	// challenges on every solve. ProxySession is the sticky-session token a
+	// residential provider keys the exit IP on; it is persisted so a restored
+	// session exits from the same IP.
+	Proxy        *types.Proxy
+	ProxySession string
+
+	// Operational counters reported by sessions.info
+	requests atomic.Int64
+	solving  atomic.Int32 // solves running now; they can overlap
+
+	// deniedStreak counts consecutive access_denied solves (proxy rotation)
+	deniedStreak atomic.Int32
 }
 
 // Manager handles session lifecycle and cleanup.
@@ -66,6 +96,13 @@ type Manager struct {
 	pool     *browser.Pool // Pool reference for returning browsers on cleanup
 	stopCh   chan struct{}
 	wg       sync.WaitGroup // Track background goroutines for clean shutdown
+
+	// store persists session snapshots (SESSION_PERSIST_DIR or SESSION_REDIS_URL); nil disables persistence.
+	store Store
+
+	// Proxy rotation for sessions that keep getting denied (SESSION_PROXY_ROTATION)
+	rotation    []*types.Proxy
+	rotateAfter int
 }
 
 // NewManager creates a new session manager.
@@ -78,6 +115,7 @@ func NewManager(cfg *config.Config, pool *browser.Pool) *Manager {
 		pool:     pool,
 		stopCh:   make(chan struct{}),
 	}
+	m.store = newStore(cfg)
 
 	// Start cleanup routine with WaitGroup tracking for clean shutdown
 	m.wg.Add(1)
@@ -90,6 +128,7 @@ func NewManager(cfg *config.Config, pool *browser.Pool) *Manager {
 		Dur("ttl", cfg.SessionTTL).
 		Dur("cleanup_interval", cfg.SessionCleanupInterval).
 		Int("max_sessions", cfg.MaxSessions).
+		Str("eviction_policy", cfg.SessionEvictionPolicy).
 		Msg("Session manager initialized")
 
 	return m
@@ -99,6 +138,15 @@ func NewManager(cfg *config.Config, pool *browser.Pool) *Manager {
 // Returns an error if the session already exists or max sessions is reached.
 // The browser is returned to the pool on any error.
 func (m *Manager) Create(id string, brow *rod.Browser, ttl time.Duration) (*Session, error) {
+	// Deferred first so a session evicted to make room is torn down after the
+	// manager lock is released
+	var evicted *Session
+	defer func() {
+		if evicted != nil {
+			m.evict(evicted)
+		}
+	}()
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -111,14 +159,16 @@ func (m *Manager) Create(id string, brow *rod.Browser, ttl time.Duration) (*Sess
 		return nil, types.ErrSessionAlreadyExists
 	}
 
-	// Check max sessions limit
-	if len(m.sessions) >= m.config.MaxSessions {
+	// Check max sessions limit, evicting an idle session if the policy allows
+	victim, err := m.makeRoomLocked()
+	if err != nil {
 		// Return browser since we can't create session
 		if m.pool != nil {
 			m.pool.Release(brow)
 		}
-		return nil, types.ErrTooManySessions
+		return nil, err
 	}
+	evicted = victim
 
 	// Create a new page for this session
 	page, err := brow.Page(proto.TargetCreateTarget{URL: "about:blank"})
@@ -146,6 +196,7 @@ func (m *Manager) Create(id string, brow *rod.Browser, ttl time.Duration) (*Sess
 		Str("session_id", id).
 		Int("total_sessions", len(m.sessions)).
 		Msg("Session created")
+	events.Publish(context.Background(), events.Event{Type: events.SessionCreated, Session: id})
 
 	return session, nil
 }
@@ -220,6 +271,9 @@ func (m *Manager) Destroy(id string) error {
 	delete(m.sessions, id)
 	m.mu.Unlock()
 
+	// An explicitly destroyed session must not be resurrected after a restart
+	m.removeSnapshot(id)
+
 	// Close the page - now safe since all references are released
 	session.mu.Lock()
 	page := session.Page
@@ -251,6 +305,7 @@ func (m *Manager) Destroy(id string) error {
 		Str("session_id", id).
 		Dur("lifetime", time.Since(session.CreatedAt)).
 		Msg("Session destroyed")
+	events.Publish(context.Background(), events.Event{Type: events.SessionDestroyed, Session: id, Reason: "destroyed"})
 
 	return nil
 }
@@ -267,6 +322,61 @@ func (m *Manager) List() []string {
 	return ids
 }
 
+// ListInfo returns the effective and remaining TTL of every active session.
+func (m *Manager) ListInfo() []types.SessionInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	infos := make([]types.SessionInfo, 0, len(m.sessions))
+	for id, s := range m.sessions {
+		infos = append(infos, types.SessionInfo{
+			ID:                  id,
+			TTLSeconds:          int64(s.EffectiveTTL(m.config.SessionTTL) / time.Second),
+			RemainingTTLSeconds: int64(s.RemainingTTL(m.config.SessionTTL) / time.Second),
+		})
+	}
+	return infos
+}
+
+// Details returns the operational state of the session with the given ID, or
+// of every active session if id is empty. Unlike Get, it does not refresh the
+// last-used timestamp, so inspecting a session does not keep it alive. Pages
+// are queried in parallel under one deadline; values a page does not report
+// in time are omitted.
+func (m *Manager) Details(ctx context.Context, id string) ([]types.SessionDetail, error) {
+	m.mu.RLock()
+	var sessions []*Session
+	if id != "" {
+		s, ok := m.sessions[id]
+		if !ok || s.closing.Load() {
+			m.mu.RUnlock()
+			return nil, types.ErrSessionNotFound
+		}
+		sessions = []*Session{s}
+	} else {
+		sessions = make([]*Session, 0, len(m.sessions))
+		for _, s := range m.sessions {
+			sessions = append(sessions, s)
+		}
+	}
+	m.mu.RUnlock()
+
+	// Page queries go over CDP, so they run without holding the manager lock
+	ctx, cancel := context.WithTimeout(ctx, detailsTimeout)
+	defer cancel()
+	details := make([]types.SessionDetail, len(sessions))
+	var eg errgroup.Group
+	eg.SetLimit(detailsConcurrency)
+	for i, s := range sessions {
+		eg.Go(func() error {
+			details[i] = s.detail(ctx, m.config.SessionTTL)
+			return nil
+		})
+	}
+	_ = eg.Wait()
+	return details, nil
+}
+
 // TouchAndExtend refreshes a session's last-used timestamp and optionally updates its TTL.
 // If newTTL is 0, only the timestamp is refreshed.
 func (m *Manager) TouchAndExtend(id string, newTTL time.Duration) error {
@@ -350,6 +460,8 @@ func (m *Manager) cleanupExpired() {
 					Msg("Cleanup: references still held, proceeding with cleanup anyway")
 			}
 
+			m.pruneSnapshot(sess.ID)
+
 			// Fix #13: Safely extract and clear page reference under lock to prevent double-free
 			sess.mu.Lock()
 			page := sess.Page
@@ -382,6 +494,7 @@ func (m *Manager) cleanupExpired() {
 				Str("session_id", sess.ID).
 				Dur("lifetime", now.Sub(sess.CreatedAt)).
 				Msg("Session expired and cleaned up")
+			events.Publish(context.Background(), events.Event{Type: events.SessionDestroyed, Session: sess.ID, Reason: "expired"})
 			return nil
 		})
 	}
@@ -431,6 +544,7 @@ func (m *Manager) Close() error {
 	m.wg.Wait()
 
 	if len(sessions) == 0 {
+		m.closeStore()
 		log.Info().Msg("Session manager closed")
 		return nil
 	}
@@ -457,6 +571,13 @@ func (m *Manager) Close() error {
 			sess.Page = nil
 			sess.mu.Unlock()
 
+			// Snapshot live sessions so they can be restored after restart
+			if page != nil && m.store != nil {
+				if err := m.writeSnapshot(sess, page); err != nil {
+					log.Warn().Err(err).Str("session_id", sess.ID).Msg("Failed to persist session during shutdown")
+				}
+			}
+
 			// Close page outside lock (slow I/O)
 			if page != nil {
 				if err := page.Close(); err != nil {
@@ -486,6 +607,7 @@ func (m *Manager) Close() error {
 		log.Error().Err(err).Msg("Session shutdown encountered errors")
 	}
 
+	m.closeStore()
 	log.Info().Msg("Session manager closed")
 	return nil
 }
@@ -516,6 +638,15 @@ func (s *Session) SetTTL(ttl time.Duration) {
 	s.TTL = ttl
 }
 
+// RecordUserAgent stores the UA the session's page uses, if none is recorded yet.
+func (s *Session) RecordUserAgent(ua string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.UserAgent == "" {
+		s.UserAgent = ua
+	}
+}
+
 // RemainingTTL returns the time remaining before the session expires.
 func (s *Session) RemainingTTL(defaultTTL time.Duration) time.Duration {
 	elapsed := time.Since(s.LastUsedTime())
@@ -527,6 +658,63 @@ func (s *Session) RemainingTTL(defaultTTL time.Duration) time.Duration {
 	return remaining
 }
 
+// BeginSolve marks a solve as running on the session and counts it. The
+// returned function must be called when the solve finishes.
+func (s *Session) BeginSolve() (end func()) {
+	s.requests.Add(1)
+	s.solving.Add(1)
+	var once sync.Once
+	return func() { once.Do(func() { s.solving.Add(-1) }) }
+}
+
+// detail snapshots the session for sessions.info. The current URL and JS heap
+// size are read from the page concurrently until ctx ends, and omitted if
+// unavailable.
+func (s *Session) detail(ctx context.Context, defaultTTL time.Duration) types.SessionDetail {
+	s.mu.Lock()
+	proxyURL := s.ProxyURL
+	s.mu.Unlock()
+
+	d := types.SessionDetail{
+		ID:                  s.ID,
+		CreatedAt:           s.CreatedAt.UnixMilli(),
+		LastUsedAt:          s.LastUsedTime().UnixMilli(),
+		TTLSeconds:          int64(s.EffectiveTTL(defaultTTL) / time.Second),
+		RemainingTTLSeconds: int64(s.RemainingTTL(defaultTTL) / time.Second),
+		RequestCount:        s.requests.Load(),
+		Proxy:               security.RedactProxyURL(proxyURL),
+		SolveInProgress:     s.solving.Load() > 0,
+	}
+
+	page, release := s.AcquirePageWithRelease()
+	if page == nil {
+		return d
+	}
+	defer release()
+
+	p := page.Context(ctx)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if info, err := p.Info(); err == nil {
+			d.CurrentURL = info.URL
+		} else {
+			log.Debug().Err(err).Str("session_id", s.ID).Msg("Failed to read session page URL")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if heap, err := (proto.RuntimeGetHeapUsage{}).Call(p); err == nil {
+			d.MemoryBytes = int64(heap.UsedSize)
+		} else {
+			log.Debug().Err(err).Str("session_id", s.ID).Msg("Failed to read session heap usage")
+		}
+	}()
+	wg.Wait()
+	return d
+}
+
 // SafeGetPage returns the session's page reference while holding the lock.
 // This prevents race conditions when accessing the page from multiple goroutines.
 // Returns nil if the page has been closed or is unavailable.
@@ -0,0 +1,199 @@
+package session
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// storeTimeout bounds a single store operation so a slow backend never
+// stalls request handling or shutdown.
+const storeTimeout = 5 * time.Second
+
+// Store persists session snapshots outside the process.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Save writes a snapshot. ttl is the session's effective TTL; backends
+	// that support expiry may use it to drop stale snapshots on their own.
+	Save(ctx context.Context, snap *Snapshot, ttl time.Duration) error
+	// Load returns the snapshot for id, or nil and no error if there is none.
+	Load(ctx context.Context, id string) (*Snapshot, error)
+	// Delete removes the snapshot for id. Deleting a missing snapshot is not an error.
+	Delete(ctx context.Context, id string) error
+	// Close releases any resources held by the store.
+	Close() error
+}
+
+// snapshotKey hashes a session ID so arbitrary IDs are safe as file names or
+// keys and are not exposed to anyone who can list the store.
+func snapshotKey(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// decodeSnapshot parses a stored snapshot and checks it belongs to id.
+func decodeSnapshot(data []byte, id string) (*Snapshot, error) {
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to decode session snapshot: %w", err)
+	}
+	if snap.ID != id {
+		return nil, nil
+	}
+	return &snap, nil
+}
+
+// FileStore keeps one JSON snapshot file per session in a directory.
+// Suitable for a single instance that needs sessions to survive restarts.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates the directory (mode 0700) if needed and returns a store backed by it.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create session persistence directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// path returns the snapshot file path for a session ID.
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, snapshotKey(id)+".json")
+}
+
+// Save atomically replaces the session's snapshot file.
+func (s *FileStore) Save(_ context.Context, snap *Snapshot, _ time.Duration) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("failed to encode session snapshot: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".snapshot-*")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+	if err := os.Rename(tmpName, s.path(snap.ID)); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("failed to replace snapshot file: %w", err)
+	}
+	return nil
+}
+
+// Load reads the session's snapshot file. A corrupt file is removed.
+func (s *FileStore) Load(_ context.Context, id string) (*Snapshot, error) {
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session snapshot: %w", err)
+	}
+	snap, err := decodeSnapshot(data, id)
+	if err != nil {
+		_ = os.Remove(s.path(id))
+		return nil, err
+	}
+	return snap, nil
+}
+
+// Delete removes the session's snapshot file, if any.
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove session snapshot: %w", err)
+	}
+	return nil
+}
+
+// Close is a no-op for FileStore.
+func (s *FileStore) Close() error {
+	return nil
+}
+
+// RedisStore keeps snapshots in Redis so several instances behind a load
+// balancer can share session state without sticky routing. Keys expire with
+// the session TTL.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore connects to the Redis server at rawURL
+// (redis://[user:pass@]host:port/db or rediss:// for TLS) and verifies it is reachable.
+// Keys are namespaced with prefix.
+func NewRedisStore(rawURL, prefix string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to session Redis: %w", err)
+	}
+
+	return &RedisStore{client: client, prefix: prefix}, nil
+}
+
+// key returns the Redis key for a session ID.
+func (s *RedisStore) key(id string) string {
+	return s.prefix + snapshotKey(id)
+}
+
+// Save writes the snapshot with the session TTL as the key expiry.
+func (s *RedisStore) Save(ctx context.Context, snap *Snapshot, ttl time.Duration) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("failed to encode session snapshot: %w", err)
+	}
+	if err := s.client.Set(ctx, s.key(snap.ID), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save session snapshot to Redis: %w", err)
+	}
+	return nil
+}
+
+// Load fetches the snapshot for id from Redis.
+func (s *RedisStore) Load(ctx context.Context, id string) (*Snapshot, error) {
+	data, err := s.client.Get(ctx, s.key(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session snapshot from Redis: %w", err)
+	}
+	return decodeSnapshot(data, id)
+}
+
+// Delete removes the snapshot for id from Redis.
+func (s *RedisStore) Delete(ctx context.Context, id string) error {
+	if err := s.client.Del(ctx, s.key(id)).Err(); err != nil {
+		return fmt.Errorf("failed to delete session snapshot from Redis: %w", err)
+	}
+	return nil
+}
+
+// Close closes the Redis connection pool.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,85 @@
+package session
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStore_PathStaysInDir(t *testing.T) {
+	dir := t.TempDir()
+	fs, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := fs.path("../../etc/passwd-xxxxxxxx")
+	if filepath.Dir(path) != dir {
+		t.Errorf("Snapshot path %q escaped store dir %q", path, dir)
+	}
+}
+
+func TestFileStore_SaveLoadDelete(t *testing.T) {
+	fs, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	id := "file-store-session-01"
+
+	if err := fs.Save(ctx, &Snapshot{ID: id, UserAgent: "UA", SavedAt: time.Now()}, time.Minute); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	snap, err := fs.Load(ctx, id)
+	if err != nil || snap == nil || snap.UserAgent != "UA" {
+		t.Fatalf("Load() = %+v, %v", snap, err)
+	}
+	if snap, _ := fs.Load(ctx, "other-session-id-0001"); snap != nil {
+		t.Error("Expected nil for unknown session")
+	}
+	if err := fs.Delete(ctx, id); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := fs.Delete(ctx, id); err != nil {
+		t.Errorf("Delete() of missing snapshot should not error, got %v", err)
+	}
+	if snap, _ := fs.Load(ctx, id); snap != nil {
+		t.Error("Expected snapshot to be gone after Delete")
+	}
+}
+
+func TestNewRedisStore_InvalidURL(t *testing.T) {
+	if _, err := NewRedisStore("not-a-redis-url", "prefix:"); err == nil {
+		t.Error("Expected error for invalid Redis URL")
+	}
+}
+
+func TestRedisStore_SaveLoadDelete(t *testing.T) {
+	url := os.Getenv("TEST_REDIS_URL")
+	if url == "" {
+		t.Skip("TEST_REDIS_URL not set")
+	}
+	rs, err := NewRedisStore(url, "flaresolverr-test:")
+	if err != nil {
+		t.Fatalf("NewRedisStore() error = %v", err)
+	}
+	defer rs.Close()
+	ctx := context.Background()
+	id := "redis-store-session-1"
+
+	if err := rs.Save(ctx, &Snapshot{ID: id, UserAgent: "UA", SavedAt: time.Now()}, time.Minute); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	snap, err := rs.Load(ctx, id)
+	if err != nil || snap == nil || snap.UserAgent != "UA" {
+		t.Fatalf("Load() = %+v, %v", snap, err)
+	}
+	if err := rs.Delete(ctx, id); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if snap, _ := rs.Load(ctx, id); snap != nil {
+		t.Error("Expected snapshot to be gone after Delete")
+	}
+}
@@ -0,0 +1,226 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/captcha"
+	"github.com/Rorqualx/flaresolverr-go/internal/humanize"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// actionElementTimeout bounds how long click, type, select and scroll
+// actions wait for their element. A wait action with a selector waits until
+// the solve times out instead.
+const actionElementTimeout = 10 * time.Second
+
+// actionSelectJS chooses the option whose value, or failing that visible
+// text, equals v and fires the events a user's choice would.
+const actionSelectJS = `function(v) {
+	var opts = Array.from(this.options || []);
+	var opt = opts.find(function(o) { return o.value === v; }) ||
+		opts.find(function(o) { return o.text.trim() === v; });
+	if (!opt) return false;
+	this.value = opt.value;
+	this.dispatchEvent(new Event('input', { bubbles: true }));
+	this.dispatchEvent(new Event('change', { bubbles: true }));
+	return true;
+}`
+
+// applyActions runs the request's actions on the cleared page with the
+// humanized mouse and keyboard, then rebuilds the result from wherever they
+// left the page. The first failing action fails the solve.
+func (s *Solver) applyActions(ctx context.Context, page *rod.Page, opts *SolveOptions, networkCapture *NetworkCapture, result *Result) error {
+	if len(opts.Actions) == 0 || opts.Method != "" || opts.ReturnRawHtml || opts.Download {
+		return nil
+	}
+
+	for i, action := range opts.Actions {
+		if err := s.runAction(ctx, page, action); err != nil {
+			return fmt.Errorf("action %d (%s) failed: %w", i, action.Type, err)
+		}
+		log.Ctx(ctx).Debug().Int("index", i).Str("type", action.Type).Str("selector", action.Selector).Msg("Action completed")
+	}
+
+	// A click may have submitted a form; let the new document load
+	if err := page.Context(ctx).Timeout(actionElementTimeout).WaitLoad(); err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("WaitLoad after actions failed, continuing anyway")
+	}
+
+	// The actions may have navigated anywhere, so validate the final URL
+	// without DNS pinning to the original host
+	refreshed, err := s.buildResult(ctx, page, opts.URL, opts.screenshot(), nil, opts.SkipResponseValidation, networkCapture, 0)
+	if err != nil {
+		return err
+	}
+	refreshed.UserAgent = result.UserAgent
+	refreshed.ClientRedirects = result.ClientRedirects
+	*result = *refreshed
+	log.Ctx(ctx).Info().Int("actions", len(opts.Actions)).Str("url", refreshed.URL).Msg("Post-solve actions completed")
+	return nil
+}
+
+// runAction performs one action, pausing afterwards as a user would.
+func (s *Solver) runAction(ctx context.Context, page *rod.Page, action types.Action) error {
+	if !sleepWithContext(ctx, humanize.NewTiming().PreActionDelay()) {
+		return ctx.Err()
+	}
+
+	switch action.Type {
+	case types.ActionClick:
+		el, err := actionElement(ctx, page, action.Selector)
+		if err != nil {
+			return err
+		}
+		if err := clickActionElement(ctx, page, el); err != nil {
+			return err
+		}
+	case types.ActionType:
+		el, err := actionElement(ctx, page, action.Selector)
+		if err != nil {
+			return err
+		}
+		if err := clickActionElement(ctx, page, el); err != nil {
+			return err
+		}
+		// The click may land beside a small input; make sure it has focus
+		if err := el.Focus(); err != nil {
+			return fmt.Errorf("failed to focus %q: %w", action.Selector, err)
+		}
+		if err := humanize.NewKeyboard(page).Type(ctx, action.Text); err != nil {
+			return fmt.Errorf("failed to type into %q: %w", action.Selector, err)
+		}
+	case types.ActionSelect:
+		el, err := actionElement(ctx, page, action.Selector)
+		if err != nil {
+			return err
+		}
+		res, err := el.Eval(actionSelectJS, action.Value)
+		if err != nil {
+			return fmt.Errorf("failed to select in %q: %w", action.Selector, err)
+		}
+		if !res.Value.Bool() {
+			return fmt.Errorf("%q has no option %q", action.Selector, action.Value)
+		}
+	case types.ActionScroll:
+		scroller := humanize.NewScroller(page)
+		if action.Selector == "" {
+			if err := scroller.ScrollBy(ctx, float64(action.Pixels)); err != nil {
+				return fmt.Errorf("failed to scroll: %w", err)
+			}
+			break
+		}
+		el, err := actionElement(ctx, page, action.Selector)
+		if err != nil {
+			return err
+		}
+		if err := scroller.ScrollToElement(ctx, el); err != nil {
+			return fmt.Errorf("failed to scroll to %q: %w", action.Selector, err)
+		}
+	case types.ActionWait:
+		if action.Selector != "" {
+			if _, err := page.Context(ctx).Element(action.Selector); err != nil {
+				return fmt.Errorf("timed out waiting for %q: %w", action.Selector, err)
+			}
+		}
+		if action.Ms > 0 && !sleepWithContext(ctx, time.Duration(action.Ms)*time.Millisecond) {
+			return ctx.Err()
+		}
+		return nil
+	case types.ActionImageCaptcha:
+		if err := s.solveImageCaptcha(ctx, page, action); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown action type %q", action.Type)
+	}
+
+	if !sleepWithContext(ctx, humanize.NewTiming().PostActionDelay()) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// solveImageCaptcha screenshots the captcha image matching the action's
+// selector, has the external providers read it, and types the answer into
+// the input field with the humanized keyboard.
+func (s *Solver) solveImageCaptcha(ctx context.Context, page *rod.Page, action types.Action) error {
+	if s.solverChain == nil || !s.solverChain.IsEnabled() {
+		return fmt.Errorf("imageCaptcha needs external CAPTCHA solving to be enabled")
+	}
+
+	img, err := actionElement(ctx, page, action.Selector)
+	if err != nil {
+		return err
+	}
+	if _, err := humanize.NewScroller(page).EnsureElementVisible(ctx, img); err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("Failed to scroll captcha image into view")
+	}
+	// An <img> may still be decoding; other elements return at once
+	if err := img.WaitLoad(); err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("Captcha image did not finish loading, capturing anyway")
+	}
+	data, err := captureElementScreenshot(page, action.Selector, &proto.PageCaptureScreenshot{Format: proto.PageCaptureScreenshotFormatPng})
+	if err != nil {
+		return fmt.Errorf("failed to capture captcha image: %w", err)
+	}
+
+	var pageURL string
+	if info, err := page.Info(); err == nil {
+		pageURL = info.URL
+	}
+	result, err := s.solverChain.SolveImage(s.withPreferredProvider(ctx, extractDomainFromURL(pageURL)), &captcha.ImageRequest{
+		Image:   data,
+		PageURL: pageURL,
+	})
+	if err != nil {
+		return fmt.Errorf("external image captcha solver failed: %w", err)
+	}
+	log.Ctx(ctx).Info().
+		Str("provider", result.Provider).
+		Dur("solve_time", result.SolveTime).
+		Float64("cost", result.Cost).
+		Msg("Image captcha solved via external provider")
+
+	input, err := actionElement(ctx, page, action.Input)
+	if err != nil {
+		return err
+	}
+	if err := clickActionElement(ctx, page, input); err != nil {
+		return err
+	}
+	if err := input.Focus(); err != nil {
+		return fmt.Errorf("failed to focus %q: %w", action.Input, err)
+	}
+	if err := humanize.NewKeyboard(page).Type(ctx, result.Token); err != nil {
+		return fmt.Errorf("failed to type into %q: %w", action.Input, err)
+	}
+	return nil
+}
+
+// actionElement waits up to actionElementTimeout for the selector's element.
+func actionElement(ctx context.Context, page *rod.Page, selector string) (*rod.Element, error) {
+	el, err := page.Context(ctx).Timeout(actionElementTimeout).Element(selector)
+	if err != nil {
+		return nil, fmt.Errorf("element %q not found: %w", selector, err)
+	}
+	// Drop the lookup timeout so later calls on the element use ctx alone
+	return el.Context(ctx), nil
+}
+
+// clickActionElement scrolls the element into view if needed and clicks it
+// with the humanized mouse.
+func clickActionElement(ctx context.Context, page *rod.Page, el *rod.Element) error {
+	if _, err := humanize.NewScroller(page).EnsureElementVisible(ctx, el); err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("Failed to scroll action element into view")
+	}
+	if err := humanize.NewMouse(page).ClickElement(ctx, el); err != nil {
+		return fmt.Errorf("failed to click: %w", err)
+	}
+	return nil
+}
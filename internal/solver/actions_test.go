@@ -0,0 +1,42 @@
+package solver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func TestApplyActionsSkipped(t *testing.T) {
+	s := &Solver{}
+	result := &Result{HTML: "<html></html>"}
+	click := []types.Action{{Type: types.ActionClick, Selector: "#go"}}
+	tests := []*SolveOptions{
+		{},
+		{Actions: click, Method: "PATCH"},
+		{Actions: click, ReturnRawHtml: true},
+		{Actions: click, Download: true},
+	}
+	for _, opts := range tests {
+		// A nil page would panic if the actions ran
+		if err := s.applyActions(context.Background(), nil, opts, nil, result); err != nil {
+			t.Errorf("applyActions(%+v) error = %v", opts, err)
+		}
+	}
+}
+
+func TestRunActionUnknownType(t *testing.T) {
+	s := &Solver{}
+	if err := s.runAction(context.Background(), nil, types.Action{Type: "hover"}); err == nil {
+		t.Error("runAction() should reject an unknown action type")
+	}
+}
+
+func TestRunActionImageCaptchaNeedsSolverChain(t *testing.T) {
+	s := &Solver{}
+	action := types.Action{Type: types.ActionImageCaptcha, Selector: "img.captcha", Input: "#answer"}
+	// A nil page would panic if the capture ran
+	if err := s.runAction(context.Background(), nil, action); err == nil {
+		t.Error("runAction() should fail without a solver chain")
+	}
+}
@@ -0,0 +1,75 @@
+package solver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/rs/zerolog/log"
+)
+
+// awsWAFTokenCookie is set by the AWS WAF challenge script once the browser
+// has passed the JavaScript challenge.
+const awsWAFTokenCookie = "aws-waf-token"
+
+// awsWAFReloadAfter is how many passes the interstitial may linger with the
+// token cookie already set before the page is reloaded by hand. The
+// challenge script reloads on its own; this covers a reload that was lost.
+const awsWAFReloadAfter = 2
+
+// isAWSWAFPage reports whether lowercased html is an AWS WAF challenge or
+// CAPTCHA interstitial.
+func isAWSWAFPage(htmlLower string, patterns []string) bool {
+	return containsAny(htmlLower, patterns)
+}
+
+// isAWSWAFCaptcha reports whether an AWS WAF interstitial is the CAPTCHA
+// variant rather than the JavaScript challenge.
+func isAWSWAFCaptcha(htmlLower string) bool {
+	return strings.Contains(htmlLower, "awswafcaptcha") ||
+		(strings.Contains(htmlLower, "awswaf.com") && strings.Contains(htmlLower, "/captcha.js"))
+}
+
+// hasAWSWAFToken checks if the aws-waf-token cookie has been set.
+func (s *Solver) hasAWSWAFToken(page *rod.Page) bool {
+	cookies, err := page.Cookies(nil)
+	if err != nil {
+		return false
+	}
+	for _, cookie := range cookies {
+		if cookie.Name == awsWAFTokenCookie && cookie.Value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// stepAWSWAF runs one solve loop pass on an AWS WAF interstitial. The
+// JavaScript challenge solves itself: the script computes a proof of work,
+// stores the token cookie and reloads. The loop only has to wait, and to
+// reload when the interstitial outlives the token.
+func stepAWSWAF(ctx context.Context, p *ChallengePass) error {
+	if isAWSWAFCaptcha(p.HTMLLower) {
+		if p.Counters["aws_waf_captcha_reported"] == 0 {
+			log.Ctx(ctx).Warn().Msg("AWS WAF CAPTCHA detected; only the JavaScript challenge can be solved")
+			p.Counters["aws_waf_captcha_reported"] = 1
+		}
+		return nil
+	}
+
+	if !p.Solver.hasAWSWAFToken(p.Page) {
+		log.Ctx(ctx).Debug().Msg("AWS WAF challenge running, waiting for token")
+		return nil
+	}
+
+	p.Counters["aws_waf_token_passes"]++
+	if p.Counters["aws_waf_token_passes"] < awsWAFReloadAfter {
+		return nil
+	}
+	p.Counters["aws_waf_token_passes"] = 0
+	log.Ctx(ctx).Info().Msg("AWS WAF token set but challenge page persists, reloading")
+	if err := p.Page.Context(ctx).Reload(); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to reload after AWS WAF challenge")
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package solver
+
+import "testing"
+
+func TestIsAWSWAFCaptcha(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want bool
+	}{
+		{"javascript challenge", `<script src="https://abc.token.awswaf.com/abc/def/challenge.js"></script><script>awswafintegration.checkforcerefresh()</script>`, false},
+		{"captcha render call", `<script>awswafcaptcha.rendercaptcha(container, {})</script>`, true},
+		{"captcha script", `<script src="https://abc.captcha.awswaf.com/abc/def/captcha.js"></script>`, true},
+		{"unrelated captcha script", `<script src="https://example.com/captcha.js"></script>`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isAWSWAFCaptcha(tt.html); got != tt.want {
+				t.Errorf("isAWSWAFCaptcha() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package solver
+
+import (
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// Limits on the XHR/fetch responses recorded for captureRequests.
+const (
+	maxCapturedRequests  = 50
+	maxCapturedBodySize  = 2 * 1024 * 1024  // per response; larger bodies are dropped
+	maxCapturedTotalSize = 10 * 1024 * 1024 // across all responses
+)
+
+// SetCapturePatterns starts recording XHR/fetch responses whose URL matches
+// any of patterns (see matchCapturePattern). Call before navigating.
+func (nc *NetworkCapture) SetCapturePatterns(patterns []string) {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	nc.capturePatterns = patterns
+	if len(patterns) > 0 && nc.pendingCaptures == nil {
+		nc.pendingCaptures = make(map[proto.NetworkRequestID]types.CapturedRequest)
+	}
+}
+
+// CapturedRequests returns the recorded responses in completion order. Safe
+// to call on a nil capture.
+func (nc *NetworkCapture) CapturedRequests() []types.CapturedRequest {
+	if nc == nil {
+		return nil
+	}
+	nc.mu.RLock()
+	defer nc.mu.RUnlock()
+	return append([]types.CapturedRequest(nil), nc.captured...)
+}
+
+// noteCaptureResponse remembers a matching XHR/fetch response until its body
+// has finished loading.
+func (nc *NetworkCapture) noteCaptureResponse(e *proto.NetworkResponseReceived) {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	if e.Response == nil || !matchAnyCapturePattern(nc.capturePatterns, e.Response.URL) {
+		return
+	}
+	if len(nc.captured)+len(nc.pendingCaptures) >= maxCapturedRequests {
+		return
+	}
+	nc.pendingCaptures[e.RequestID] = types.CapturedRequest{
+		URL:      e.Response.URL,
+		Status:   e.Response.Status,
+		MimeType: e.Response.MIMEType,
+	}
+}
+
+// finishCapture fetches the body of a noted response once it has loaded.
+func (nc *NetworkCapture) finishCapture(page *rod.Page, e *proto.NetworkLoadingFinished) {
+	nc.mu.Lock()
+	entry, ok := nc.pendingCaptures[e.RequestID]
+	delete(nc.pendingCaptures, e.RequestID)
+	nc.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	body, err := proto.NetworkGetResponseBody{RequestID: e.RequestID}.Call(page)
+	if err != nil {
+		log.Debug().Err(err).Str("url", stripQuery(entry.URL)).Msg("Failed to read captured response body")
+		return
+	}
+	if len(body.Body) > maxCapturedBodySize {
+		log.Debug().Str("url", stripQuery(entry.URL)).Int("size", len(body.Body)).Msg("Captured response body too large, dropping")
+		return
+	}
+	entry.Body = body.Body
+	entry.Base64Encoded = body.Base64Encoded
+
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	if nc.capturedSize+len(entry.Body) > maxCapturedTotalSize {
+		log.Debug().Str("url", stripQuery(entry.URL)).Msg("Captured responses reached the total size limit")
+		return
+	}
+	nc.capturedSize += len(entry.Body)
+	nc.captured = append(nc.captured, entry)
+}
+
+// matchAnyCapturePattern reports whether url matches any of patterns.
+func matchAnyCapturePattern(patterns []string, url string) bool {
+	for _, p := range patterns {
+		if matchCapturePattern(p, url) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchCapturePattern matches a URL against a captureRequests pattern. A
+// pattern with '*' is a glob over the whole URL, '*' matching any run of
+// characters; any other pattern matches URLs containing it.
+func matchCapturePattern(pattern, url string) bool {
+	if !strings.Contains(pattern, "*") {
+		return strings.Contains(url, pattern)
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(url, parts[0]) {
+		return false
+	}
+	rest := url[len(parts[0]):]
+	last := len(parts) - 1
+	for _, part := range parts[1:last] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	return strings.HasSuffix(rest, parts[last])
+}
@@ -0,0 +1,70 @@
+package solver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestMatchCapturePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		url     string
+		want    bool
+	}{
+		{"/api/", "https://example.com/api/items?page=2", true},
+		{"/api/", "https://example.com/static/app.js", false},
+		{"https://example.com/api/*", "https://example.com/api/items", true},
+		{"https://example.com/api/*", "https://cdn.example.com/api/items", false},
+		{"*/graphql*", "https://example.com/graphql?op=Search", true},
+		{"*.json", "https://example.com/data/list.json", true},
+		{"*.json", "https://example.com/data/list.json?v=1", false},
+		{"https://*.example.com/*/search*", "https://api.example.com/v2/search?q=go", true},
+		{"https://*.example.com/*/search*", "https://example.com/v2/search", false},
+	}
+	for _, tt := range tests {
+		if got := matchCapturePattern(tt.pattern, tt.url); got != tt.want {
+			t.Errorf("matchCapturePattern(%q, %q) = %v, want %v", tt.pattern, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestNetworkCaptureNotesMatchingResponses(t *testing.T) {
+	nc := newNetworkCapture()
+	response := func(id, url string) *proto.NetworkResponseReceived {
+		return &proto.NetworkResponseReceived{
+			RequestID: proto.NetworkRequestID(id),
+			Type:      proto.NetworkResourceTypeXHR,
+			Response:  &proto.NetworkResponse{URL: url, Status: 200, MIMEType: "application/json"},
+		}
+	}
+
+	// Nothing is noted without patterns
+	nc.noteCaptureResponse(response("0", "https://example.com/api/items"))
+	if len(nc.pendingCaptures) != 0 {
+		t.Fatalf("noted %d responses without patterns", len(nc.pendingCaptures))
+	}
+
+	nc.SetCapturePatterns([]string{"/api/"})
+	nc.noteCaptureResponse(response("1", "https://example.com/api/items"))
+	nc.noteCaptureResponse(response("2", "https://example.com/static/app.js"))
+	if len(nc.pendingCaptures) != 1 {
+		t.Fatalf("pending captures = %d, want 1", len(nc.pendingCaptures))
+	}
+	if got := nc.pendingCaptures["1"]; got.URL != "https://example.com/api/items" || got.MimeType != "application/json" {
+		t.Errorf("pending capture = %+v", got)
+	}
+
+	for i := 0; i < maxCapturedRequests+5; i++ {
+		nc.noteCaptureResponse(response(fmt.Sprint("n", i), "https://example.com/api/page"))
+	}
+	if len(nc.pendingCaptures) != maxCapturedRequests {
+		t.Errorf("pending captures = %d, want the %d cap", len(nc.pendingCaptures), maxCapturedRequests)
+	}
+
+	var nilCapture *NetworkCapture
+	if nilCapture.CapturedRequests() != nil {
+		t.Error("CapturedRequests() on nil capture should be nil")
+	}
+}
@@ -0,0 +1,79 @@
+package solver
+
+import (
+	"sort"
+	"sync"
+)
+
+// String returns the challenge type's name as used in logs and metrics,
+// taken from its registered detector.
+func (c ChallengeType) String() string {
+	if c == ChallengeNone {
+		return "none"
+	}
+	if d := detectorByType(c); d != nil {
+		return d.Name
+	}
+	return "unknown"
+}
+
+// ChallengeStat counts solves by the first challenge type seen on the page.
+type ChallengeStat struct {
+	Type     string
+	Detected int64 // solves that met this challenge
+	Solved   int64 // of those, solves that cleared it
+}
+
+// challengeCounter accumulates ChallengeStat per challenge type.
+type challengeCounter struct {
+	mu     sync.Mutex
+	counts map[ChallengeType]*ChallengeStat
+}
+
+func (c *challengeCounter) record(t ChallengeType, solved bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[ChallengeType]*ChallengeStat)
+	}
+	st := c.counts[t]
+	if st == nil {
+		st = &ChallengeStat{Type: t.String()}
+		c.counts[t] = st
+	}
+	if solved {
+		st.Solved++
+	} else {
+		st.Detected++
+	}
+}
+
+func (c *challengeCounter) snapshot() []ChallengeStat {
+	c.mu.Lock()
+	out := make([]ChallengeStat, 0, len(c.counts))
+	for _, st := range c.counts {
+		out = append(out, *st)
+	}
+	c.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Type < out[j].Type })
+	return out
+}
+
+// ChallengeRecorder is implemented by stats managers that count the
+// challenge types met per domain.
+type ChallengeRecorder interface {
+	RecordChallenge(domain, challenge string)
+}
+
+// recordDomainChallenge reports the first challenge type met on a domain to
+// the stats manager, if it counts them.
+func (s *Solver) recordDomainChallenge(domain string, t ChallengeType) {
+	if rec, ok := s.statsManager.(ChallengeRecorder); ok && domain != "" {
+		rec.RecordChallenge(domain, t.String())
+	}
+}
+
+// ChallengeStats returns per-challenge-type solve counts, sorted by type.
+func (s *Solver) ChallengeStats() []ChallengeStat {
+	return s.challenges.snapshot()
+}
@@ -0,0 +1,40 @@
+package solver
+
+import "testing"
+
+func TestChallengeStats(t *testing.T) {
+	s := &Solver{}
+	if stats := s.ChallengeStats(); len(stats) != 0 {
+		t.Fatalf("Expected no stats, got %v", stats)
+	}
+
+	s.challenges.record(ChallengeIncapsula, false)
+	s.challenges.record(ChallengeIncapsula, true)
+	s.challenges.record(ChallengeIncapsula, false)
+	s.challenges.record(ChallengeAWSWAF, false)
+
+	stats := s.ChallengeStats()
+	want := []ChallengeStat{
+		{Type: "aws_waf", Detected: 1},
+		{Type: "incapsula", Detected: 2, Solved: 1},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("ChallengeStats() = %v, want %v", stats, want)
+	}
+	for i := range want {
+		if stats[i] != want[i] {
+			t.Errorf("ChallengeStats()[%d] = %v, want %v", i, stats[i], want[i])
+		}
+	}
+}
+
+func TestChallengeTypeNames(t *testing.T) {
+	for c := ChallengeNone; c <= ChallengeManaged; c++ {
+		if c.String() == "unknown" {
+			t.Errorf("ChallengeType %d has no name", c)
+		}
+	}
+	if ChallengeType(99).String() != "unknown" {
+		t.Error("Expected unknown for an undefined challenge type")
+	}
+}
@@ -6,19 +6,22 @@ import (
 	"sync"
 	"time"
 
+	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
+	"github.com/rs/zerolog/log"
 	"golang.org/x/net/publicsuffix"
 
 	"github.com/Rorqualx/flaresolverr-go/internal/types"
 )
 
-// Layer-2 of the clean-egress path: cache a minted cf_clearance (plus the exact
-// User-Agent that minted it) keyed by registrable-domain + egress identity, so
-// the expensive challenge solve (and especially the ~minutes-long two-phase
-// bypass) becomes a once-per-(domain,egress) cost instead of every request.
+// Layer-2 of the clean-egress path: cache a minted cf_clearance keyed by
+// registrable-domain + egress identity + User-Agent, so the expensive challenge
+// solve (and especially the ~minutes-long two-phase bypass) becomes a
+// once-per-(domain,egress,UA) cost instead of every request.
 //
-// cf_clearance is bound to IP + User-Agent, so the cache key includes the egress
-// identity and the entry carries the UA — reuse only happens when both match.
+// cf_clearance is bound to IP + User-Agent, so both are part of the key — reuse
+// only happens when both match. Several UAs can hold clearance for the same
+// domain and egress side by side.
 
 const (
 	defaultClearanceTTL = 25 * time.Minute
@@ -26,9 +29,10 @@ const (
 	cfClearanceCookie   = "cf_clearance"
 )
 
-// ClearanceEntry holds reusable Cloudflare clearance state for one (domain, egress).
+// ClearanceEntry holds reusable Cloudflare clearance state for one (domain, egress, UA).
 type ClearanceEntry struct {
 	cookies   []types.RequestCookie // ready to inject before navigation
+	scope     string                // clearanceScope(domain, egress)
 	userAgent string
 	expiresAt time.Time
 }
@@ -37,6 +41,7 @@ type ClearanceEntry struct {
 type ClearanceCache struct {
 	mu         sync.RWMutex
 	entries    map[string]*ClearanceEntry
+	latest     map[string]string // scope -> UA of the newest entry
 	ttl        time.Duration
 	maxEntries int
 	now        func() time.Time // injectable for tests
@@ -52,20 +57,27 @@ func NewClearanceCache(ttl time.Duration, maxEntries int) *ClearanceCache {
 	}
 	return &ClearanceCache{
 		entries:    make(map[string]*ClearanceEntry),
+		latest:     make(map[string]string),
 		ttl:        ttl,
 		maxEntries: maxEntries,
 		now:        time.Now,
 	}
 }
 
-// Get returns a fresh cached clearance for (domain, egress), or nil on miss/expiry.
-func (c *ClearanceCache) Get(domain, egress string) *ClearanceEntry {
+// Get returns a fresh cached clearance for (domain, egress, userAgent), or nil
+// on miss/expiry. An empty userAgent matches the most recently stored UA for
+// the domain and egress; the caller must then navigate with entry's UA.
+func (c *ClearanceCache) Get(domain, egress, userAgent string) *ClearanceEntry {
 	if c == nil || domain == "" {
 		return nil
 	}
-	key := clearanceKey(domain, egress)
 
+	scope := clearanceScope(domain, egress)
 	c.mu.RLock()
+	if userAgent == "" {
+		userAgent = c.latest[scope]
+	}
+	key := clearanceKey(scope, userAgent)
 	e, ok := c.entries[key]
 	c.mu.RUnlock()
 	if !ok {
@@ -75,7 +87,7 @@ func (c *ClearanceCache) Get(domain, egress string) *ClearanceEntry {
 		// Expired — drop it.
 		c.mu.Lock()
 		if cur, still := c.entries[key]; still && cur == e {
-			delete(c.entries, key)
+			c.deleteLocked(key, e)
 		}
 		c.mu.Unlock()
 		return nil
@@ -83,6 +95,20 @@ func (c *ClearanceCache) Get(domain, egress string) *ClearanceEntry {
 	return e
 }
 
+// Invalidate drops the entry for (domain, egress, userAgent), e.g. after
+// Cloudflare challenged a request that carried it.
+func (c *ClearanceCache) Invalidate(domain, egress, userAgent string) {
+	if c == nil {
+		return
+	}
+	key := clearanceKey(clearanceScope(domain, egress), userAgent)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.deleteLocked(key, e)
+	}
+}
+
 // Put stores clearance for (domain, egress) IF the cookies contain a cf_clearance.
 // A no-op for non-Cloudflare solves, so it never pollutes the cache.
 func (c *ClearanceCache) Put(domain, egress, userAgent string, cookies []*proto.NetworkCookie) {
@@ -103,12 +129,14 @@ func (c *ClearanceCache) Put(domain, egress, userAgent string, cookies []*proto.
 		return // already expired; nothing worth caching
 	}
 
-	entry := &ClearanceEntry{cookies: reqCookies, userAgent: userAgent, expiresAt: expiresAt}
-	key := clearanceKey(domain, egress)
+	scope := clearanceScope(domain, egress)
+	entry := &ClearanceEntry{cookies: reqCookies, scope: scope, userAgent: userAgent, expiresAt: expiresAt}
+	key := clearanceKey(scope, userAgent)
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.entries[key] = entry
+	c.latest[scope] = userAgent
 	if len(c.entries) > c.maxEntries {
 		c.evictLocked(now)
 	}
@@ -119,27 +147,42 @@ func (c *ClearanceCache) Put(domain, egress, userAgent string, cookies []*proto.
 func (c *ClearanceCache) evictLocked(now time.Time) {
 	for k, e := range c.entries {
 		if !now.Before(e.expiresAt) {
-			delete(c.entries, k)
+			c.deleteLocked(k, e)
 		}
 	}
 	for len(c.entries) > c.maxEntries {
 		var oldestKey string
-		var oldest time.Time
+		var oldest *ClearanceEntry
 		for k, e := range c.entries {
-			if oldestKey == "" || e.expiresAt.Before(oldest) {
-				oldestKey, oldest = k, e.expiresAt
+			if oldest == nil || e.expiresAt.Before(oldest.expiresAt) {
+				oldestKey, oldest = k, e
 			}
 		}
-		delete(c.entries, oldestKey)
+		c.deleteLocked(oldestKey, oldest)
+	}
+}
+
+// deleteLocked removes an entry and, if it was the newest UA for its scope, the
+// scope's latest pointer. Caller must hold c.mu.
+func (c *ClearanceCache) deleteLocked(key string, e *ClearanceEntry) {
+	delete(c.entries, key)
+	if c.latest[e.scope] == e.userAgent {
+		delete(c.latest, e.scope)
 	}
 }
 
-// clearanceKey couples the registrable domain to the egress identity. cf_clearance
-// is IP-bound, so a different egress must never reuse another's clearance.
-func clearanceKey(domain, egress string) string {
+// clearanceScope couples the registrable domain to the egress identity.
+// cf_clearance is IP-bound, so a different egress must never reuse another's
+// clearance.
+func clearanceScope(domain, egress string) string {
 	return domain + "|" + egress
 }
 
+// clearanceKey adds the User-Agent to the scope; cf_clearance is UA-bound too.
+func clearanceKey(scope, userAgent string) string {
+	return scope + "|" + userAgent
+}
+
 // registrableDomain returns the eTLD+1 of a URL (e.g. "filecrypt.cc" for a
 // container page), so all paths/subdomains of a site share one cache entry.
 func registrableDomain(rawURL string) string {
@@ -203,3 +246,33 @@ func isClearanceCookie(name string) bool {
 		strings.HasPrefix(name, "cf_") ||
 		strings.HasPrefix(name, "__cf")
 }
+
+// acceptCachedClearance checks the freshly loaded page after a cached clearance
+// was injected. If no challenge is showing, the clearance was honoured and the
+// solve loop can be skipped. If Cloudflare challenged anyway, the entry is
+// dropped and the injected cf_clearance removed from the page, so the solve
+// loop's cookie shortcut does not mistake the stale cookie for a fresh solve.
+func (s *Solver) acceptCachedClearance(page *rod.Page, domain, egress string, e *ClearanceEntry) bool {
+	if !s.challengeShowing(page) {
+		log.Info().
+			Str("domain", domain).
+			Str("egress", egress).
+			Msg("Cached cf_clearance accepted, skipping challenge wait")
+		return true
+	}
+
+	s.clearanceCache.Invalidate(domain, egress, e.userAgent)
+	for _, ck := range e.cookies {
+		if ck.Name != cfClearanceCookie {
+			continue
+		}
+		if err := (proto.NetworkDeleteCookies{Name: ck.Name, Domain: ck.Domain, Path: ck.Path}).Call(page); err != nil {
+			log.Debug().Err(err).Msg("Failed to remove rejected cf_clearance cookie")
+		}
+	}
+	log.Info().
+		Str("domain", domain).
+		Str("egress", egress).
+		Msg("Cached cf_clearance rejected, solving challenge")
+	return false
+}
@@ -48,7 +48,7 @@ func TestClearanceCache_PutGet(t *testing.T) {
 	c := NewClearanceCache(25*time.Minute, 0)
 	c.Put("filecrypt.cc", "direct", "UA/1.0", clearanceCookies())
 
-	e := c.Get("filecrypt.cc", "direct")
+	e := c.Get("filecrypt.cc", "direct", "")
 	if e == nil {
 		t.Fatal("expected cache hit")
 	}
@@ -73,13 +73,13 @@ func TestClearanceCache_KeyIsolation(t *testing.T) {
 	c.Put("filecrypt.cc", "proxyA", "UA/1.0", clearanceCookies())
 
 	// Different egress must not reuse another egress's clearance (IP-bound).
-	if c.Get("filecrypt.cc", "proxyB") != nil {
+	if c.Get("filecrypt.cc", "proxyB", "") != nil {
 		t.Error("clearance leaked across egress identities")
 	}
-	if c.Get("other.com", "proxyA") != nil {
+	if c.Get("other.com", "proxyA", "") != nil {
 		t.Error("clearance leaked across domains")
 	}
-	if c.Get("filecrypt.cc", "proxyA") == nil {
+	if c.Get("filecrypt.cc", "proxyA", "") == nil {
 		t.Error("expected hit for matching domain+egress")
 	}
 }
@@ -90,7 +90,7 @@ func TestClearanceCache_NoClearanceCookie(t *testing.T) {
 	c.Put("example.com", "direct", "UA/1.0", []*proto.NetworkCookie{
 		{Name: "sessionid", Value: "x", Domain: "example.com", Path: "/"},
 	})
-	if c.Get("example.com", "direct") != nil {
+	if c.Get("example.com", "direct", "") != nil {
 		t.Error("cached an entry with no cf_clearance")
 	}
 }
@@ -101,12 +101,12 @@ func TestClearanceCache_Expiry(t *testing.T) {
 	c.now = func() time.Time { return base }
 	c.Put("filecrypt.cc", "direct", "UA/1.0", clearanceCookies())
 
-	if c.Get("filecrypt.cc", "direct") == nil {
+	if c.Get("filecrypt.cc", "direct", "") == nil {
 		t.Fatal("expected hit before expiry")
 	}
 	// Advance past the TTL ceiling.
 	c.now = func() time.Time { return base.Add(26 * time.Minute) }
-	if c.Get("filecrypt.cc", "direct") != nil {
+	if c.Get("filecrypt.cc", "direct", "") != nil {
 		t.Error("expected miss after TTL expiry")
 	}
 }
@@ -122,7 +122,7 @@ func TestClearanceCache_HonorsShorterCookieExpiry(t *testing.T) {
 	c.Put("x.com", "direct", "UA/1.0", cookies)
 
 	c.now = func() time.Time { return base.Add(6 * time.Minute) }
-	if c.Get("x.com", "direct") != nil {
+	if c.Get("x.com", "direct", "") != nil {
 		t.Error("entry should have expired with the cookie at 5 min, not the 25 min ceiling")
 	}
 }
@@ -140,3 +140,34 @@ func TestClearanceCache_Eviction(t *testing.T) {
 		t.Errorf("cache exceeded max: %d entries", n)
 	}
 }
+
+func TestClearanceCache_UserAgentKey(t *testing.T) {
+	c := NewClearanceCache(25*time.Minute, 0)
+	c.Put("filecrypt.cc", "direct", "UA/1.0", clearanceCookies())
+	c.Put("filecrypt.cc", "direct", "UA/2.0", clearanceCookies())
+
+	// Both UAs keep their own clearance for the same domain+egress.
+	for _, ua := range []string{"UA/1.0", "UA/2.0"} {
+		if e := c.Get("filecrypt.cc", "direct", ua); e == nil || e.userAgent != ua {
+			t.Errorf("Get(%q) = %+v, want entry for that UA", ua, e)
+		}
+	}
+	if c.Get("filecrypt.cc", "direct", "UA/3.0") != nil {
+		t.Error("clearance leaked across user agents")
+	}
+	// No UA requested: the newest entry wins.
+	if e := c.Get("filecrypt.cc", "direct", ""); e == nil || e.userAgent != "UA/2.0" {
+		t.Errorf("Get(\"\") = %+v, want newest UA/2.0 entry", e)
+	}
+
+	c.Invalidate("filecrypt.cc", "direct", "UA/2.0")
+	if c.Get("filecrypt.cc", "direct", "UA/2.0") != nil {
+		t.Error("expected miss after Invalidate")
+	}
+	if c.Get("filecrypt.cc", "direct", "") != nil {
+		t.Error("newest pointer should be cleared with its entry")
+	}
+	if c.Get("filecrypt.cc", "direct", "UA/1.0") == nil {
+		t.Error("Invalidate dropped another UA's entry")
+	}
+}
@@ -0,0 +1,39 @@
+package solver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func TestDataDomeVariantOf(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want dataDomeVariant
+	}{
+		{"slider", `<script>var dd={'rt':'c','cid':'x','hsh':'y','t':'fe','host':'geo.captcha-delivery.com'}</script>`, dataDomeCaptcha},
+		{"interstitial", `<script>var dd={'rt':'i','cid':'x','hsh':'y','host':'geo.captcha-delivery.com'}</script>`, dataDomeInterstitial},
+		{"interstitial iframe", `<iframe src="https://geo.captcha-delivery.com/interstitial/?initialcid=x"></iframe>`, dataDomeInterstitial},
+		{"banned", `<script>var dd={'rt':'c','cid':'x','t':'bv','host':'geo.captcha-delivery.com'}</script>`, dataDomeBlocked},
+		{"banned iframe", `<iframe src="https://geo.captcha-delivery.com/captcha/?initialcid=x&amp;t=bv"></iframe>`, dataDomeBlocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dataDomeVariantOf(tt.html); got != tt.want {
+				t.Errorf("dataDomeVariantOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSolveProxyContext(t *testing.T) {
+	if p := solveProxyFrom(context.Background()); p != nil {
+		t.Errorf("Expected no proxy, got %v", p)
+	}
+	proxy := &types.Proxy{URL: "http://10.0.0.1:8080"}
+	if p := solveProxyFrom(withSolveProxy(context.Background(), proxy)); p != proxy {
+		t.Errorf("Expected stored proxy, got %v", p)
+	}
+}
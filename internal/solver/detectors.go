@@ -0,0 +1,283 @@
+package solver
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/selectors"
+)
+
+// ChallengeDetector recognises one kind of challenge page and drives it on
+// each pass of the solve loop. Register vendors with RegisterChallengeDetector
+// at startup; the loop itself never names them.
+type ChallengeDetector struct {
+	// Type is reported by detectChallenge and keys the challenge metrics.
+	Type ChallengeType
+	// Name labels the type in logs and metrics, e.g. "datadome".
+	Name string
+	// Priority orders matching; lower runs first. The built-in detectors
+	// are spaced by 100 so vendors can slot in between.
+	Priority int
+	// Match reports whether lowercased page HTML is this challenge.
+	Match func(htmlLower string, sel *selectors.Selectors) bool
+	// Selectors are CSS selectors whose presence means the challenge is
+	// still up, for pages whose title gives nothing away. Optional.
+	Selectors []string
+	// Solve runs once per loop pass while the page matches. An error ends
+	// the solve with it. Optional: without it the loop just waits.
+	Solve func(ctx context.Context, p *ChallengePass) error
+	// SuppressTurnstile stops the loop clicking for a Turnstile checkbox on
+	// matching pages.
+	SuppressTurnstile bool
+	// TriggerTurnstile makes the loop click for a Turnstile checkbox on
+	// matching pages even when no Turnstile selector is visible.
+	TriggerTurnstile bool
+}
+
+// ChallengePass is what a detector's Solve sees on one loop pass.
+type ChallengePass struct {
+	Solver    *Solver
+	Page      *rod.Page
+	URL       string
+	HTMLLower string
+	Type      ChallengeType // the matched detector's type
+	Attempt   int           // zero-based loop pass
+	// Counters is per-solve scratch space shared by all detectors, e.g. the
+	// paid CAPTCHA budget.
+	Counters map[string]int
+}
+
+// Built-in detector priorities.
+const (
+	PriorityAccessDenied = 100
+	PriorityHCaptcha     = 200
+	PriorityAWSWAF       = 300
+	PriorityDataDome     = 400
+	PriorityIncapsula    = 500
+	// PriorityManagedChallenge runs before Turnstile: the managed challenge
+	// page carries Turnstile markup too.
+	PriorityManagedChallenge = 550
+	PriorityTurnstile        = 600
+	PriorityRecaptcha        = 700
+	PriorityJavaScript       = 800
+)
+
+var (
+	detectorsMu sync.RWMutex
+	detectors   []*ChallengeDetector // sorted by Priority
+)
+
+func init() {
+	for _, d := range builtinDetectors() {
+		RegisterChallengeDetector(d)
+	}
+}
+
+// RegisterChallengeDetector adds a detector to the global registry. Call at
+// startup, before requests are served. Panics on a missing Match or a
+// duplicate type.
+func RegisterChallengeDetector(d ChallengeDetector) {
+	if d.Match == nil || d.Type == ChallengeNone || d.Name == "" {
+		panic("challenge detector needs a type, a name and a match function")
+	}
+	detectorsMu.Lock()
+	defer detectorsMu.Unlock()
+	for _, existing := range detectors {
+		if existing.Type == d.Type {
+			panic("challenge detector already registered: " + d.Name)
+		}
+	}
+	detectors = append(detectors, &d)
+	sort.SliceStable(detectors, func(i, j int) bool { return detectors[i].Priority < detectors[j].Priority })
+}
+
+// ChallengeDetectors returns the registered detectors in match order.
+func ChallengeDetectors() []ChallengeDetector {
+	detectorsMu.RLock()
+	defer detectorsMu.RUnlock()
+	out := make([]ChallengeDetector, len(detectors))
+	for i, d := range detectors {
+		out[i] = *d
+	}
+	return out
+}
+
+// matchDetector returns the first registered detector matching htmlLower, or nil.
+func matchDetector(htmlLower string, sel *selectors.Selectors) *ChallengeDetector {
+	detectorsMu.RLock()
+	defer detectorsMu.RUnlock()
+	for _, d := range detectors {
+		if d.Match(htmlLower, sel) {
+			return d
+		}
+	}
+	return nil
+}
+
+// detectorByType returns the registered detector for t, or nil.
+func detectorByType(t ChallengeType) *ChallengeDetector {
+	detectorsMu.RLock()
+	defer detectorsMu.RUnlock()
+	for _, d := range detectors {
+		if d.Type == t {
+			return d
+		}
+	}
+	return nil
+}
+
+// allChallengeSelectors returns the Cloudflare challenge selectors followed
+// by those of the registered detectors.
+func allChallengeSelectors() []string {
+	detectorsMu.RLock()
+	defer detectorsMu.RUnlock()
+	out := append([]string(nil), challengeSelectors...)
+	for _, d := range detectors {
+		out = append(out, d.Selectors...)
+	}
+	return out
+}
+
+// containsAny reports whether htmlLower contains any of patterns.
+func containsAny(htmlLower string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(htmlLower, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// captchaSolvesCounter is the ChallengePass counter holding paid external
+// CAPTCHA submissions; it is capped at maxCaptchaSolves across vendors.
+const captchaSolvesCounter = "captcha_solves"
+
+// builtinDetectors returns the detectors shipped with the solver.
+func builtinDetectors() []ChallengeDetector {
+	return []ChallengeDetector{
+		{
+			Type:     ChallengeAccessDenied,
+			Name:     "access_denied",
+			Priority: PriorityAccessDenied,
+			Match: func(htmlLower string, sel *selectors.Selectors) bool {
+				return strings.Contains(htmlLower, "cloudflare") && containsAny(htmlLower, sel.AccessDenied)
+			},
+		},
+		{
+			// Cloudflare's hCaptcha fallback is served on the same
+			// interstitial, which can still carry Turnstile markup
+			Type:              ChallengeHCaptcha,
+			Name:              "hcaptcha",
+			Priority:          PriorityHCaptcha,
+			Match:             func(htmlLower string, sel *selectors.Selectors) bool { return isHCaptchaPage(htmlLower, sel.Captcha) },
+			Solve:             solveCaptchaPass,
+			SuppressTurnstile: true,
+		},
+		{
+			Type:     ChallengeAWSWAF,
+			Name:     "aws_waf",
+			Priority: PriorityAWSWAF,
+			Match:    func(htmlLower string, sel *selectors.Selectors) bool { return isAWSWAFPage(htmlLower, sel.AWSWAF) },
+			// AWS WAF interstitials have an empty title; the script alone also
+			// appears on pages using the WAF SDK, so require the container too
+			Selectors: []string{
+				"html:has(#challenge-container) script[src*='awswaf.com']",
+				"html:has(#captcha-container) script[src*='awswaf.com']",
+			},
+			Solve:             stepAWSWAF,
+			SuppressTurnstile: true,
+		},
+		{
+			Type:              ChallengeDataDome,
+			Name:              "datadome",
+			Priority:          PriorityDataDome,
+			Match:             func(htmlLower string, sel *selectors.Selectors) bool { return isDataDomePage(htmlLower, sel.DataDome) },
+			Selectors:         []string{"iframe[src*='captcha-delivery.com']"},
+			Solve:             stepDataDome,
+			SuppressTurnstile: true,
+		},
+		{
+			Type:     ChallengeIncapsula,
+			Name:     "incapsula",
+			Priority: PriorityIncapsula,
+			Match: func(htmlLower string, sel *selectors.Selectors) bool {
+				return isIncapsulaPage(htmlLower, sel.Incapsula)
+			},
+			Selectors:         []string{"iframe#main-iframe[src*='_Incapsula_Resource']"},
+			Solve:             stepIncapsula,
+			SuppressTurnstile: true,
+		},
+		{
+			// Cloudflare's managed challenge nests the checkbox two or three
+			// cross-origin iframes deep; the "frames" Turnstile method walks them
+			Type:     ChallengeManaged,
+			Name:     "managed",
+			Priority: PriorityManagedChallenge,
+			Match: func(htmlLower string, sel *selectors.Selectors) bool {
+				return containsAny(htmlLower, sel.ManagedChallenge)
+			},
+			Selectors:        []string{"[id^='cf-chl-widget-']"},
+			TriggerTurnstile: true,
+		},
+		{
+			Type:             ChallengeTurnstile,
+			Name:             "turnstile",
+			Priority:         PriorityTurnstile,
+			Match:            func(htmlLower string, sel *selectors.Selectors) bool { return containsAny(htmlLower, sel.Turnstile) },
+			TriggerTurnstile: true,
+		},
+		{
+			// After Turnstile, which is solved natively first
+			Type:              ChallengeRecaptcha,
+			Name:              "recaptcha",
+			Priority:          PriorityRecaptcha,
+			Match:             func(htmlLower string, sel *selectors.Selectors) bool { return isRecaptchaPage(htmlLower, sel.Captcha) },
+			Solve:             solveCaptchaPass,
+			SuppressTurnstile: true,
+		},
+		{
+			Type:     ChallengeJavaScript,
+			Name:     "javascript",
+			Priority: PriorityJavaScript,
+			Match:    func(htmlLower string, sel *selectors.Selectors) bool { return containsAny(htmlLower, sel.JavaScript) },
+		},
+	}
+}
+
+// solveCaptchaPass sends an hCaptcha or reCAPTCHA page to the external
+// solver chain. Each submission is a paid task, so stop after
+// maxCaptchaSolves and let the loop time out.
+func solveCaptchaPass(ctx context.Context, p *ChallengePass) error {
+	if p.Counters[captchaSolvesCounter] >= maxCaptchaSolves {
+		return nil
+	}
+	s := p.Solver
+	recaptcha := p.Type == ChallengeRecaptcha
+	kind := "hCaptcha"
+	if recaptcha {
+		kind = "reCAPTCHA"
+	}
+	if s.solverChain == nil || !s.solverChain.IsEnabled() {
+		log.Ctx(ctx).Warn().Str("captcha", kind).Msg("CAPTCHA detected but external CAPTCHA solving is not enabled")
+		p.Counters[captchaSolvesCounter] = maxCaptchaSolves // warn once
+		return nil
+	}
+
+	p.Counters[captchaSolvesCounter]++
+	log.Ctx(ctx).Info().Str("captcha", kind).Int("attempt", p.Counters[captchaSolvesCounter]).Msg("CAPTCHA detected, attempting external solver")
+	var err error
+	if recaptcha {
+		_, err = s.solveRecaptchaExternal(ctx, p.Page, p.URL, true)
+	} else {
+		err = s.solveHCaptchaExternal(ctx, p.Page, p.URL)
+	}
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("captcha", kind).Msg("External CAPTCHA solve failed")
+	}
+	return nil
+}
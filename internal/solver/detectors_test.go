@@ -0,0 +1,62 @@
+package solver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/selectors"
+)
+
+func TestBuiltinDetectorOrder(t *testing.T) {
+	var got []string
+	for _, d := range ChallengeDetectors() {
+		if d.Type <= ChallengeManaged { // skip detectors registered by other tests
+			got = append(got, d.Name)
+		}
+	}
+	want := "access_denied,hcaptcha,aws_waf,datadome,incapsula,managed,turnstile,recaptcha,javascript"
+	if strings.Join(got, ",") != want {
+		t.Errorf("detector order = %v, want %s", got, want)
+	}
+}
+
+func TestRegisterChallengeDetector(t *testing.T) {
+	const challengeExample ChallengeType = 100
+	example := ChallengeDetector{
+		Type:     challengeExample,
+		Name:     "example_vendor",
+		Priority: PriorityHCaptcha - 1,
+		Match: func(htmlLower string, _ *selectors.Selectors) bool {
+			return strings.Contains(htmlLower, "example-vendor-challenge")
+		},
+		Selectors: []string{"#example-vendor"},
+	}
+	if detectorByType(challengeExample) == nil { // the registry outlives -count runs
+		RegisterChallengeDetector(example)
+	}
+
+	s := &Solver{}
+	// Matches ahead of Turnstile by priority
+	if got := s.detectChallenge(`<div class="cf-turnstile"></div><div id="example-vendor-challenge"></div>`); got != challengeExample {
+		t.Errorf("detectChallenge() = %v, want %v", got, challengeExample)
+	}
+	if challengeExample.String() != "example_vendor" {
+		t.Errorf("String() = %q, want example_vendor", challengeExample.String())
+	}
+	found := false
+	for _, sel := range allChallengeSelectors() {
+		if sel == "#example-vendor" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected the detector's selectors in the challenge selector list")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic on duplicate registration")
+		}
+	}()
+	RegisterChallengeDetector(example)
+}
@@ -0,0 +1,215 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// Per-domain solve settings from DOMAIN_OVERRIDES_PATH, for sites that need
+// tuned behavior. The handler applies the settings of the matching entry
+// where the request leaves them unset, so clients need no changes.
+
+// defaultTurnstileMethods is the order Turnstile methods are tried in when a
+// domain has no history. "wait" is first because invisible Turnstile
+// auto-solves without interaction; "frames" next, as the managed
+// challenge's nested iframes defeat the others.
+var defaultTurnstileMethods = []string{"wait", "frames", "keyboard", "shadow", "widget", "iframe", "positional"}
+
+// DomainOverride is one entry of the DOMAIN_OVERRIDES_PATH file.
+type DomainOverride struct {
+	// Domains are patterns as in PROXY_ROUTES: "example.com" for the domain
+	// and its subdomains, "*.example.com" for subdomains only, or "*".
+	Domains          []string      `yaml:"domains"`
+	Timeout          time.Duration `yaml:"timeout"`          // Used when the request sets no maxTimeout
+	DisableMedia     bool          `yaml:"disableMedia"`     // Block images, CSS and fonts
+	TurnstileMethods []string      `yaml:"turnstileMethods"` // Tried first, before the learned order
+	TabsTillVerify   int           `yaml:"tabsTillVerify"`   // Used when the request sets none
+	Proxy            string        `yaml:"proxy"`            // Proxy URL or "pool:<name>", used when the request names no proxy
+	Profile          string        `yaml:"profile"`          // Browser profile, used when the request names none
+	UserAgent        string        `yaml:"userAgent"`        // Used when the request sets none
+
+	proxy *types.Proxy // Proxy, parsed
+}
+
+// ProxyConfig returns a copy of the entry's proxy, or nil when it sets none.
+func (o *DomainOverride) ProxyConfig() *types.Proxy {
+	if o.proxy == nil {
+		return nil
+	}
+	proxy := *o.proxy
+	return &proxy
+}
+
+// domainOverridePattern is a pattern of a DomainOverride entry.
+type domainOverridePattern struct {
+	pattern  string
+	override *DomainOverride
+}
+
+// DomainOverrides are the DOMAIN_OVERRIDES_PATH entries. A nil
+// DomainOverrides overrides nothing.
+type DomainOverrides []domainOverridePattern
+
+// LoadDomainOverrides reads the domain overrides file at path. An empty path
+// returns nil.
+func LoadDomainOverrides(path string) (DomainOverrides, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from operator configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read domain overrides: %w", err)
+	}
+	return ParseDomainOverrides(data)
+}
+
+// ParseDomainOverrides parses a domain overrides document, a YAML (or JSON)
+// list of entries:
+//
+//   - domains: ["example.com", "*.shop.example"]
+//     timeout: 90s
+//     disableMedia: true
+//     turnstileMethods: [keyboard, frames]
+//     tabsTillVerify: 14
+//     proxy: pool:residential
+//     profile: desktop-mac
+//     userAgent: "Mozilla/5.0 ..."
+func ParseDomainOverrides(data []byte) (DomainOverrides, error) {
+	var list []*DomainOverride
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse domain overrides: %w", err)
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("domain overrides file has no entries")
+	}
+
+	var overrides DomainOverrides
+	seen := make(map[string]bool)
+	for i, o := range list {
+		if o == nil || len(o.Domains) == 0 {
+			return nil, fmt.Errorf("domain override %d: domains is required", i)
+		}
+		if err := o.validate(); err != nil {
+			return nil, fmt.Errorf("domain override %d: %w", i, err)
+		}
+		for _, pattern := range o.Domains {
+			pattern = strings.ToLower(strings.TrimSpace(pattern))
+			if !validRoutePattern(pattern) {
+				return nil, fmt.Errorf("domain override %d: invalid pattern %q: want example.com, *.example.com or *", i, pattern)
+			}
+			if seen[pattern] {
+				return nil, fmt.Errorf("domain override %d: pattern %q overridden twice", i, pattern)
+			}
+			seen[pattern] = true
+			overrides = append(overrides, domainOverridePattern{pattern: pattern, override: o})
+		}
+	}
+	return overrides, nil
+}
+
+// ValidateTurnstileMethods returns an error naming the first entry of methods
+// that is not a Turnstile method.
+func ValidateTurnstileMethods(methods []string) error {
+	for _, method := range methods {
+		if !slices.Contains(defaultTurnstileMethods, method) {
+			return fmt.Errorf("unknown Turnstile method %q (must be one of %s)", method, strings.Join(defaultTurnstileMethods, ", "))
+		}
+	}
+	return nil
+}
+
+// validate checks an entry's settings and parses its proxy.
+func (o *DomainOverride) validate() error {
+	if o.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	if o.TabsTillVerify < 0 || o.TabsTillVerify > types.MaxTabsTillVerify {
+		return fmt.Errorf("tabsTillVerify must be between 0 and %d", types.MaxTabsTillVerify)
+	}
+	if err := ValidateTurnstileMethods(o.TurnstileMethods); err != nil {
+		return err
+	}
+	if o.Profile != "" && !types.ValidBrowserProfileName(o.Profile) {
+		return fmt.Errorf("invalid profile name %q", o.Profile)
+	}
+	if o.Proxy != "" {
+		if name, isPool := strings.CutPrefix(o.Proxy, proxyRoutePoolPrefix); isPool {
+			o.proxy = &types.Proxy{Pool: strings.TrimSpace(name)}
+		} else if o.proxy = parseProxyEntry(o.Proxy); o.proxy == nil {
+			return fmt.Errorf("invalid proxy URL")
+		}
+		if err := o.proxy.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Match returns the entry for host, or nil when none applies. The most
+// specific pattern wins, as for PROXY_ROUTES.
+func (d DomainOverrides) Match(host string) *DomainOverride {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" {
+		return nil
+	}
+	var best *DomainOverride
+	bestScore := -1
+	for _, p := range d {
+		if score := routeScore(p.pattern, host); score > bestScore {
+			best, bestScore = p.override, score
+		}
+	}
+	return best
+}
+
+// Pools returns the pool names the entries' proxies refer to.
+func (d DomainOverrides) Pools() []string {
+	var names []string
+	for _, p := range d {
+		if p.override.proxy != nil && p.override.proxy.Pool != "" && !slices.Contains(names, p.override.proxy.Pool) {
+			names = append(names, p.override.proxy.Pool)
+		}
+	}
+	return names
+}
+
+// turnstileMethodsKey carries SolveOptions.TurnstileMethods on the solve
+// context, down to the Turnstile solve.
+type turnstileMethodsKey struct{}
+
+// withTurnstileMethods returns ctx carrying the preferred Turnstile methods.
+func withTurnstileMethods(ctx context.Context, methods []string) context.Context {
+	if len(methods) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, turnstileMethodsKey{}, methods)
+}
+
+// preferTurnstileMethods moves the methods preferred on ctx to the front of
+// order, keeping the rest in order.
+func preferTurnstileMethods(ctx context.Context, order []string) []string {
+	preferred, _ := ctx.Value(turnstileMethodsKey{}).([]string)
+	if len(preferred) == 0 {
+		return order
+	}
+	methods := make([]string, 0, len(order)+len(preferred))
+	for _, m := range preferred {
+		if !slices.Contains(methods, m) {
+			methods = append(methods, m)
+		}
+	}
+	for _, m := range order {
+		if !slices.Contains(methods, m) {
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
@@ -0,0 +1,41 @@
+package solver
+
+import (
+	"context"
+	"slices"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/captcha"
+)
+
+// DomainSolverPreferences is implemented by stats managers that store the
+// per-domain solver preferences set with domains.setPrefs. Both look the
+// host up and, failing that, its nearest parent domain.
+type DomainSolverPreferences interface {
+	DisabledTurnstileMethods(host string) []string
+	PreferredProviderFor(host string) string
+}
+
+// withPreferredProvider returns ctx asking the solver chain to try the
+// external provider the preferences for host name first.
+func (s *Solver) withPreferredProvider(ctx context.Context, host string) context.Context {
+	if prefs, ok := s.statsManager.(DomainSolverPreferences); ok && host != "" {
+		return captcha.WithPreferredProvider(ctx, prefs.PreferredProviderFor(host))
+	}
+	return ctx
+}
+
+// dropDisabledTurnstileMethods removes the Turnstile methods the preferences
+// for domain disable.
+func (s *Solver) dropDisabledTurnstileMethods(domain string, methods []string) []string {
+	prefs, ok := s.statsManager.(DomainSolverPreferences)
+	if !ok || domain == "" {
+		return methods
+	}
+	disabled := prefs.DisabledTurnstileMethods(domain)
+	if len(disabled) == 0 {
+		return methods
+	}
+	return slices.DeleteFunc(methods, func(method string) bool {
+		return slices.Contains(disabled, method)
+	})
+}
@@ -0,0 +1,24 @@
+package solver
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/stats"
+)
+
+func TestEnabledTurnstileMethods_DomainPrefs(t *testing.T) {
+	m := stats.NewManager()
+	defer m.Close()
+	m.SetDomainSolverPrefs("example.com", &stats.SolverPreferences{NativeFirst: true, DisableMethods: []string{"keyboard", "positional"}})
+
+	s := &Solver{}
+	s.SetStatsManager(m)
+	order := []string{"wait", "keyboard", "shadow", "positional"}
+	if got := s.enabledTurnstileMethods("www.example.com", slices.Clone(order)); !slices.Equal(got, []string{"wait", "shadow"}) {
+		t.Errorf("www.example.com methods = %v, want keyboard and positional dropped", got)
+	}
+	if got := s.enabledTurnstileMethods("other.org", slices.Clone(order)); !slices.Equal(got, order) {
+		t.Errorf("other.org methods = %v, want all", got)
+	}
+}
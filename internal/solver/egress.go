@@ -42,6 +42,7 @@ func ParseEgressStrategy(s string) EgressStrategy {
 type EgressPool struct {
 	proxies  []*types.Proxy
 	strategy EgressStrategy
+	healthy  func(*types.Proxy) bool // nil: every proxy is usable
 	mu       sync.Mutex
 	rr       int // round-robin cursor
 }
@@ -61,27 +62,98 @@ func (p *EgressPool) Select(domain string) *types.Proxy {
 	if p == nil || len(p.proxies) == 0 {
 		return nil
 	}
+	return p.proxies[nextHealthy(p.proxies, p.index(domain), p.healthy)]
+}
+
+// SelectOther returns a proxy for which avoid returns false, for retrying a
+// request the target refused, or nil when every proxy is avoided. Healthy
+// proxies are preferred.
+func (p *EgressPool) SelectOther(domain string, avoid func(*types.Proxy) bool) *types.Proxy {
+	if p == nil || len(p.proxies) == 0 {
+		return nil
+	}
+	idx := nextUnavoided(p.proxies, p.index(domain), p.healthy, avoid)
+	if idx < 0 {
+		return nil
+	}
+	return p.proxies[idx]
+}
+
+// index returns the index the pool's strategy picks for domain.
+func (p *EgressPool) index(domain string) int {
+	var idx int
 	switch p.strategy {
 	case EgressRoundRobin:
 		p.mu.Lock()
-		proxy := p.proxies[p.rr%len(p.proxies)]
+		idx = p.rr % len(p.proxies)
 		p.rr++
 		p.mu.Unlock()
-		return proxy
 	case EgressPerRequest:
 		// Vary by domain + cursor so it spreads without needing a RNG (Math.random
 		// is intentionally avoided project-wide). Not cryptographic; just spread.
 		p.mu.Lock()
-		idx := (hashString(domain) + uint32(p.rr)) % uint32(len(p.proxies))
+		idx = int((hashString(domain) + uint32(p.rr)) % uint32(len(p.proxies)))
 		p.rr++
 		p.mu.Unlock()
-		return p.proxies[idx]
 	default: // EgressStickyDomain
-		if domain == "" {
-			return p.proxies[0]
+		if domain != "" {
+			idx = int(hashString(domain) % uint32(len(p.proxies)))
+		}
+	}
+	return idx
+}
+
+// SetHealthCheck makes Select skip proxies for which healthy returns false.
+// Must be called before the pool is used.
+func (p *EgressPool) SetHealthCheck(healthy func(*types.Proxy) bool) {
+	if p != nil {
+		p.healthy = healthy
+	}
+}
+
+// Proxies returns the pool's proxies.
+func (p *EgressPool) Proxies() []*types.Proxy {
+	if p == nil {
+		return nil
+	}
+	return p.proxies
+}
+
+// nextHealthy returns the first healthy index at or after start, wrapping
+// around. A dead proxy's domains thus move to its neighbour and return once
+// it recovers. When every proxy is dead, start is returned unchanged: a
+// request through a failing proxy beats no request at all.
+func nextHealthy(proxies []*types.Proxy, start int, healthy func(*types.Proxy) bool) int {
+	if healthy == nil {
+		return start
+	}
+	for i := range proxies {
+		idx := (start + i) % len(proxies)
+		if healthy(proxies[idx]) {
+			return idx
+		}
+	}
+	return start
+}
+
+// nextUnavoided returns the first index at or after start, wrapping around,
+// whose proxy is not avoided, preferring healthy ones. Returns -1 when every
+// proxy is avoided.
+func nextUnavoided(proxies []*types.Proxy, start int, healthy, avoid func(*types.Proxy) bool) int {
+	fallback := -1
+	for i := range proxies {
+		idx := (start + i) % len(proxies)
+		if avoid(proxies[idx]) {
+			continue
+		}
+		if healthy == nil || healthy(proxies[idx]) {
+			return idx
+		}
+		if fallback < 0 {
+			fallback = idx
 		}
-		return p.proxies[hashString(domain)%uint32(len(p.proxies))]
 	}
+	return fallback
 }
 
 // Size returns the number of proxies in the pool.
@@ -105,3 +105,37 @@ func TestEgressPool_RoundRobin(t *testing.T) {
 		}
 	}
 }
+
+func TestEgressPool_SkipsUnhealthy(t *testing.T) {
+	p := NewEgressPool(proxies3(), EgressStickyDomain)
+	before := p.Select("example.com").URL
+
+	p.SetHealthCheck(func(proxy *types.Proxy) bool { return proxy.URL != before })
+	moved := p.Select("example.com").URL
+	if moved == before {
+		t.Fatalf("sticky-domain kept dead egress %s", before)
+	}
+	if again := p.Select("example.com").URL; again != moved {
+		t.Errorf("sticky-domain not stable while %s is dead: %s then %s", before, moved, again)
+	}
+
+	// Recovered: the domain returns to its own egress
+	p.SetHealthCheck(func(*types.Proxy) bool { return true })
+	if got := p.Select("example.com").URL; got != before {
+		t.Errorf("after recovery Select = %s, want %s", got, before)
+	}
+}
+
+func TestEgressPool_SelectOther(t *testing.T) {
+	p := NewEgressPool(proxies3(), EgressStickyDomain)
+	refused := p.Select("example.com")
+	other := p.SelectOther("example.com", func(proxy *types.Proxy) bool { return proxy.URL == refused.URL })
+	if other == nil || other.URL == refused.URL {
+		t.Errorf("SelectOther() = %v, want an egress other than %s", other, refused.URL)
+	}
+
+	var empty *EgressPool
+	if got := empty.SelectOther("example.com", func(*types.Proxy) bool { return false }); got != nil {
+		t.Errorf("nil pool SelectOther() = %v, want nil", got)
+	}
+}
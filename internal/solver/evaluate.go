@@ -0,0 +1,45 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/rs/zerolog/log"
+)
+
+// evaluateJsTimeout bounds an evaluateJs script, including any promise it
+// awaits.
+const evaluateJsTimeout = 30 * time.Second
+
+// maxJSResultSize caps the serialized evaluateJs result returned to the client.
+const maxJSResultSize = 1 << 20
+
+// applyEvaluateJs runs the evaluateJs script as the body of an async function
+// in the solved page and stores its JSON-serialized return value. A script
+// that throws, times out or returns too much leaves the reason in JSError
+// rather than failing the solve.
+func (s *Solver) applyEvaluateJs(ctx context.Context, page *rod.Page, opts *SolveOptions, result *Result) {
+	if opts.EvaluateJs == "" || opts.Method != "" || opts.ReturnRawHtml {
+		return
+	}
+
+	log.Ctx(ctx).Debug().Int("js_length", len(opts.EvaluateJs)).Msg("Evaluating custom JavaScript")
+	script := fmt.Sprintf("async () => {\n%s\n}", opts.EvaluateJs)
+	res, err := page.Context(ctx).Timeout(evaluateJsTimeout).Evaluate(rod.Eval(script).ByPromise())
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("evaluateJs failed")
+		result.JSError = err.Error()
+		return
+	}
+	data := []byte("null") // undefined
+	if res != nil {
+		data = []byte(res.Value.JSON("", ""))
+	}
+	if len(data) > maxJSResultSize {
+		result.JSError = fmt.Sprintf("result exceeds %d bytes", maxJSResultSize)
+		return
+	}
+	result.JSResult = data
+}
@@ -0,0 +1,23 @@
+package solver
+
+import (
+	"context"
+	"testing"
+)
+
+func TestApplyEvaluateJsSkipped(t *testing.T) {
+	s := &Solver{}
+	tests := []*SolveOptions{
+		{},
+		{EvaluateJs: "return 1", Method: "DELETE"},
+		{EvaluateJs: "return 1", ReturnRawHtml: true},
+	}
+	for _, opts := range tests {
+		result := &Result{}
+		// A nil page would panic if the script ran
+		s.applyEvaluateJs(context.Background(), nil, opts, result)
+		if result.JSResult != nil || result.JSError != "" {
+			t.Errorf("applyEvaluateJs(%+v) set a result", opts)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package solver
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/security"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// Layer-3 of the clean-egress path: once the clearance cache holds a
+// cf_clearance for a domain, plain GETs are fetched over HTTP with that
+// cookie and the user agent that minted it, through the same egress, without
+// a browser. A response Cloudflare challenges falls back to the browser.
+//
+// The client presents the TLS ClientHello of the Chrome major version in the
+// minting user agent and sends that Chrome's navigation headers (see
+// fastpath_chrome.go), so the clearance is presented with the fingerprint it
+// was minted under.
+
+const (
+	// maxFastPathTransports bounds the transports kept for connection reuse,
+	// one per egress, pinned target and user agent. Past it they are all
+	// dropped.
+	maxFastPathTransports = 64
+	// maxFastPathRedirects matches Chrome's redirect limit.
+	maxFastPathRedirects = 20
+)
+
+// errFastPathChallenged reports a fast path response Cloudflare challenged.
+var errFastPathChallenged = errors.New("fast path response was challenged")
+
+// chromeNavigationHeaders are the headers Chrome sends on a top-level
+// navigation, less User-Agent and the client hints, which follow the
+// minting user agent.
+var chromeNavigationHeaders = map[string]string{
+	"Accept":                    "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7",
+	"Accept-Encoding":           "gzip, deflate, br, zstd",
+	"Accept-Language":           "en-US,en;q=0.9",
+	"Upgrade-Insecure-Requests": "1",
+	"Sec-Fetch-Dest":            "document",
+	"Sec-Fetch-Mode":            "navigate",
+	"Sec-Fetch-Site":            "none",
+	"Sec-Fetch-User":            "?1",
+}
+
+// FastPath fetches pages over HTTP with a cached cf_clearance instead of a
+// browser. It keeps one transport per egress and user agent so connections
+// are reused across requests.
+type FastPath struct {
+	mu         sync.Mutex
+	transports map[string]*chromeTransport
+	rootCAs    *x509.CertPool // nil for the system roots
+}
+
+// NewFastPath creates a fast path client.
+func NewFastPath() *FastPath {
+	return &FastPath{transports: make(map[string]*chromeTransport)}
+}
+
+// SetFastPath enables the clearance fast path (Layer-3 of the clean-egress
+// path). It only fires on clearance cache hits.
+func (s *Solver) SetFastPath(f *FastPath) {
+	s.fastPath = f
+}
+
+// fastPathEligible reports whether a solve only needs the response, so an
+// HTTP fetch can stand in for the browser. Anything that reads or drives the
+// rendered page needs the browser.
+func fastPathEligible(opts *SolveOptions) bool {
+	return !opts.IsPost && opts.Method == "" && !opts.Download &&
+		!opts.Screenshot && opts.WaitInSeconds == 0 &&
+		opts.ExecuteJs == "" && opts.EvaluateJs == "" &&
+		len(opts.CaptureRequests) == 0 && len(opts.Actions) == 0 &&
+		!opts.ReturnHar && !opts.ReturnPdf && opts.ReturnSnapshot == "" &&
+		!opts.RecordSolve && !opts.VerifyProxy && !opts.SolveRecaptcha &&
+		opts.WaitForSelector == "" && opts.WaitForText == ""
+}
+
+// Fetch GETs opts.URL with the clearance entry's user agent and opts.Cookies,
+// which already carry the entry's cookies. It returns errFastPathChallenged
+// when Cloudflare challenges the response, and any other error when the
+// fetch fails; either way the caller falls back to the browser.
+func (f *FastPath) Fetch(ctx context.Context, opts *SolveOptions, e *ClearanceEntry) (*Result, error) {
+	target, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	transport, err := f.transport(opts.Proxy, target.Hostname(), opts.ExpectedIP, !opts.SkipResponseValidation, e.userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+	sent := make([]*http.Cookie, 0, len(opts.Cookies))
+	for _, c := range opts.Cookies {
+		sent = append(sent, &http.Cookie{Name: c.Name, Value: c.Value, Domain: c.Domain, Path: c.Path, Secure: c.Secure, HttpOnly: c.HTTPOnly})
+	}
+	jar.SetCookies(target, sent)
+
+	// Set-Cookie headers on redirects are collected as they pass
+	var received []*http.Cookie
+	client := &http.Client{
+		Transport: &chromeNavigation{base: transport, userAgent: e.userAgent},
+		Jar:       jar,
+		Timeout:   opts.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.Response != nil {
+				received = append(received, req.Response.Cookies()...)
+			}
+			if opts.FollowRedirects != nil && !*opts.FollowRedirects {
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= maxFastPathRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxFastPathRedirects)
+			}
+			if opts.SkipResponseValidation {
+				return nil
+			}
+			return security.ValidateURLWithContext(req.Context(), req.URL.String())
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opts.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if opts.Locale != "" {
+		req.Header.Set("Accept-Language", opts.Locale)
+	}
+	for k, v := range opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fast path request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	maxSize := responseLimitsFrom(ctx).MaxResponseSize
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fast path response: %w", err)
+	}
+	truncated := len(body) > maxSize
+	if truncated {
+		body = body[:maxSize]
+	}
+
+	headers := make(map[string]string, min(len(resp.Header), maxResponseHeaders))
+	for k, v := range resp.Header {
+		if len(headers) == maxResponseHeaders {
+			break
+		}
+		headers[strings.ToLower(k)] = strings.Join(v, ", ")
+	}
+	html := string(body)
+	if isChallengedResponse(resp.StatusCode, headers) || hasChallengeTitle(html) {
+		return nil, errFastPathChallenged
+	}
+
+	received = append(received, resp.Cookies()...)
+	return &Result{
+		Success:         true,
+		StatusCode:      resp.StatusCode,
+		HTML:            html,
+		HTMLTruncated:   truncated,
+		Cookies:         fastPathCookies(opts.Cookies, received, resp.Request.URL.Hostname()),
+		UserAgent:       e.userAgent,
+		URL:             resp.Request.URL.String(),
+		ResponseHeaders: headers,
+	}, nil
+}
+
+// transport returns the pooled transport for an egress and user agent,
+// creating it on first use. Without a proxy, pinnedIP (the address the
+// handler validated) is dialed for host, and with validate any other host
+// is validated and dialed at the address checked, so DNS cannot be rebound
+// between validation and fetch.
+func (f *FastPath) transport(p *types.Proxy, host string, pinnedIP net.IP, validate bool, userAgent string) (*chromeTransport, error) {
+	var proxyURL *url.URL
+	if p != nil && p.URL != "" {
+		raw := p.URL
+		if !strings.Contains(raw, "://") {
+			raw = "http://" + raw
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		u.Scheme = strings.ToLower(u.Scheme)
+		if p.Username != "" {
+			u.User = url.UserPassword(p.Username, p.Password)
+		}
+		proxyURL = u
+		pinnedIP = nil // The proxy resolves the target
+	}
+
+	key := "direct"
+	if proxyURL != nil {
+		key = proxyURL.String()
+	}
+	if pinnedIP != nil {
+		key += "|" + host + "|" + pinnedIP.String()
+	}
+	if !validate {
+		key += "|unvalidated"
+	}
+	key += "|" + userAgent
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t, ok := f.transports[key]; ok {
+		return t, nil
+	}
+	if len(f.transports) >= maxFastPathTransports {
+		for k, t := range f.transports {
+			t.CloseIdleConnections()
+			delete(f.transports, k)
+		}
+	}
+
+	t := newChromeTransport(userAgent, proxyURL, host, pinnedIP, validate, f.rootCAs)
+	f.transports[key] = t
+	return t, nil
+}
+
+// hasChallengeTitle reports whether an HTML document's title is one the
+// solve loop treats as a challenge.
+func hasChallengeTitle(html string) bool {
+	lower := strings.ToLower(html)
+	start := strings.Index(lower, "<title")
+	if start < 0 {
+		return false
+	}
+	end := strings.Index(lower[start:], "</title>")
+	if end < 0 {
+		return false
+	}
+	title := lower[start : start+end]
+	for _, t := range challengeTitles {
+		if strings.Contains(title, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// fastPathCookies merges the cookies sent with those the responses set, the
+// latter winning, into the form browser solves return. Cookies set without a
+// Domain belong to host.
+func fastPathCookies(sent []types.RequestCookie, received []*http.Cookie, host string) []*proto.NetworkCookie {
+	cookies := make([]*proto.NetworkCookie, 0, len(sent)+len(received))
+	index := make(map[string]int, cap(cookies))
+	add := func(c *proto.NetworkCookie) {
+		if c.Domain == "" {
+			c.Domain = host
+		}
+		if c.Path == "" {
+			c.Path = "/"
+		}
+		key := c.Name + "|" + c.Domain + "|" + c.Path
+		if i, ok := index[key]; ok {
+			cookies[i] = c
+			return
+		}
+		index[key] = len(cookies)
+		cookies = append(cookies, c)
+	}
+	for _, c := range sent {
+		add(&proto.NetworkCookie{Name: c.Name, Value: c.Value, Domain: c.Domain, Path: c.Path, Secure: c.Secure, HTTPOnly: c.HTTPOnly, Session: true})
+	}
+	for _, c := range received {
+		nc := &proto.NetworkCookie{Name: c.Name, Value: c.Value, Domain: c.Domain, Path: c.Path, Secure: c.Secure, HTTPOnly: c.HttpOnly, Session: c.Expires.IsZero() && c.MaxAge == 0}
+		switch {
+		case c.MaxAge > 0:
+			nc.Expires = proto.TimeSinceEpoch(time.Now().Add(time.Duration(c.MaxAge) * time.Second).Unix())
+		case !c.Expires.IsZero():
+			nc.Expires = proto.TimeSinceEpoch(c.Expires.Unix())
+		}
+		add(nc)
+	}
+	return cookies
+}
+
+// serveFromFastPath tries the fast path for a solve that hit the clearance
+// cache, returning nil when the browser must handle it.
+func (s *Solver) serveFromFastPath(ctx context.Context, opts *SolveOptions, e *ClearanceEntry) *Result {
+	if s.fastPath == nil || e == nil || !fastPathEligible(opts) {
+		return nil
+	}
+	start := time.Now()
+	result, err := s.fastPath.Fetch(ctx, opts, e)
+	if err != nil {
+		log.Ctx(ctx).Info().Err(err).Str("url", opts.URL).Msg("Clearance fast path missed, using the browser")
+		return nil
+	}
+	log.Ctx(ctx).Info().
+		Str("url", result.URL).
+		Int("status", result.StatusCode).
+		Dur("duration", time.Since(start)).
+		Msg("Served via clearance fast path")
+	return result
+}
@@ -0,0 +1,604 @@
+package solver
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	utls "github.com/refraction-networking/utls"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+	"golang.org/x/net/proxy"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/security"
+)
+
+// The fast path speaks as the Chrome that minted the clearance: that
+// version's TLS ClientHello (via uTLS) and its navigation headers, client
+// hints included. HTTP/2 is x/net/http2's Transport over the uTLS
+// connection, so its SETTINGS and header order are Go's rather than
+// Chrome's; HTTP/1.1 requests are written here, in Chrome's header order.
+
+// chromeHellos maps the first Chrome major version sending each ClientHello
+// uTLS reproduces to it, newest first.
+var chromeHellos = []struct {
+	major int
+	id    utls.ClientHelloID
+}{
+	{133, utls.HelloChrome_133}, // new ALPS codepoint
+	{131, utls.HelloChrome_131}, // X25519MLKEM768
+	{124, utls.HelloChrome_120_PQ},
+	{120, utls.HelloChrome_120},
+	{106, utls.HelloChrome_106_Shuffle},
+	{102, utls.HelloChrome_102},
+	{0, utls.HelloChrome_100},
+}
+
+const (
+	// The header table and header list sizes Chrome announces over HTTP/2
+	chromeH2TableSize     = 65536
+	chromeH2MaxHeaderList = 262144
+
+	// fastPathIdleTimeout drops idle HTTP/2 connections a server has likely
+	// closed by now.
+	fastPathIdleTimeout = 90 * time.Second
+)
+
+// errNoH2 reports a TLS connection on which the server did not negotiate
+// HTTP/2; the request is sent over HTTP/1.1 instead.
+var errNoH2 = errors.New("server did not negotiate HTTP/2")
+
+// chromeHeaderOrder is the order Chrome sends a navigation's headers in over
+// HTTP/1.1, named as it names them there.
+var chromeHeaderOrder = []string{
+	"Cache-Control",
+	"sec-ch-ua",
+	"sec-ch-ua-mobile",
+	"sec-ch-ua-platform",
+	"Upgrade-Insecure-Requests",
+	"User-Agent",
+	"Accept",
+	"Sec-Fetch-Site",
+	"Sec-Fetch-Mode",
+	"Sec-Fetch-User",
+	"Sec-Fetch-Dest",
+	"Referer",
+	"Accept-Encoding",
+	"Accept-Language",
+	"Cookie",
+	"Priority",
+}
+
+// connectionHeaders are the headers the transport writes itself or never
+// sends.
+var connectionHeaders = map[string]bool{
+	"Host":              true,
+	"Connection":        true,
+	"Proxy-Connection":  true,
+	"Keep-Alive":        true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+	"Content-Length":    true,
+}
+
+var chromeVersionPattern = regexp.MustCompile(`Chrome/(\d+)\.`)
+
+// chromeMajor returns the Chrome major version a user agent names, or 0.
+func chromeMajor(ua string) int {
+	m := chromeVersionPattern.FindStringSubmatch(ua)
+	if m == nil {
+		return 0
+	}
+	major, _ := strconv.Atoi(m[1])
+	return major
+}
+
+// chromeHello returns the ClientHello Chrome major sends, or uTLS's current
+// Chrome when the user agent named no version.
+func chromeHello(major int) utls.ClientHelloID {
+	if major == 0 {
+		return utls.HelloChrome_Auto
+	}
+	for _, h := range chromeHellos {
+		if major >= h.major {
+			return h.id
+		}
+	}
+	return utls.HelloChrome_Auto
+}
+
+// chromeClientHints returns the low-entropy client hints Chrome sends with
+// ua. The brand list is greased as Chromium does it, seeded by the major
+// version, so it matches the browser that minted the clearance.
+func chromeClientHints(ua string, major int) map[string]string {
+	const greaseChars = " (:-./);=?_"
+	greaseVersions := []string{"8", "99", "24"}
+	orders := [][3]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}
+
+	v := strconv.Itoa(major)
+	grease := fmt.Sprintf(`"Not%cA%cBrand";v="%s"`, greaseChars[major%11], greaseChars[(major+1)%11], greaseVersions[major%3])
+	var brands [3]string
+	order := orders[major%6]
+	brands[order[0]] = grease
+	brands[order[1]] = `"Chromium";v="` + v + `"`
+	brands[order[2]] = `"Google Chrome";v="` + v + `"`
+
+	platform, mobile := "Linux", "?0"
+	switch {
+	case strings.Contains(ua, "Android"):
+		platform, mobile = "Android", "?1"
+	case strings.Contains(ua, "Windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "Macintosh"):
+		platform = "macOS"
+	case strings.Contains(ua, "CrOS"):
+		platform = "Chrome OS"
+	}
+	return map[string]string{
+		"sec-ch-ua":          strings.Join(brands[:], ", "),
+		"sec-ch-ua-mobile":   mobile,
+		"sec-ch-ua-platform": `"` + platform + `"`,
+	}
+}
+
+// chromeHeaders returns h's fields in the order Chrome sends them, named as
+// Chrome names them over HTTP/1.1. Fields Chrome does not send follow in name
+// order; connection headers are left out.
+func chromeHeaders(h http.Header) []hpack.HeaderField {
+	fields := make([]hpack.HeaderField, 0, len(h))
+	seen := make(map[string]bool, len(chromeHeaderOrder))
+	for _, name := range chromeHeaderOrder {
+		key := http.CanonicalHeaderKey(name)
+		seen[key] = true
+		for _, v := range h[key] {
+			fields = append(fields, hpack.HeaderField{Name: name, Value: v})
+		}
+	}
+	rest := make([]string, 0, len(h))
+	for k := range h {
+		if !seen[k] && !connectionHeaders[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	for _, k := range rest {
+		for _, v := range h[k] {
+			fields = append(fields, hpack.HeaderField{Name: k, Value: v})
+		}
+	}
+	return fields
+}
+
+// chromeNavigation is an http.RoundTripper sending each request, redirect
+// hops included, with the navigation headers of the Chrome userAgent names.
+// Headers the request already carries are kept; the user agent is always
+// userAgent's.
+type chromeNavigation struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (n *chromeNavigation) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	setDefault := func(k, v string) {
+		if req.Header.Get(k) == "" {
+			req.Header.Set(k, v)
+		}
+	}
+	for k, v := range chromeNavigationHeaders {
+		setDefault(k, v)
+	}
+	if major := chromeMajor(n.userAgent); major > 0 {
+		for k, v := range chromeClientHints(n.userAgent, major) {
+			setDefault(k, v)
+		}
+		if major >= 124 {
+			setDefault("Priority", "u=0, i")
+		}
+	}
+	req.Header.Set("User-Agent", n.userAgent)
+	return n.base.RoundTrip(req)
+}
+
+// chromeTransport is an http.RoundTripper fetching as one Chrome user agent
+// through one egress. HTTPS uses that version's ClientHello, and HTTP/2 when
+// the server offers it. Without a proxy every host dialed is validated and
+// dialed at the address validated, redirect hops included. Requests must
+// not have a body.
+type chromeTransport struct {
+	hello     utls.ClientHelloID
+	userAgent string   // Sent on proxy CONNECTs
+	proxy     *url.URL // nil for a direct egress
+	host      string   // dialed at pinnedIP when set
+	pinnedIP  net.IP
+	validate  bool           // Validate other hosts against SSRF rules before dialing
+	rootCAs   *x509.CertPool // nil for the system roots
+	dialer    *net.Dialer
+	h2        *http2.Transport
+
+	mu    sync.Mutex
+	http1 map[string]bool // host:port of servers that did not negotiate HTTP/2
+}
+
+// newChromeTransport returns a transport for a user agent's Chrome version.
+func newChromeTransport(userAgent string, proxyURL *url.URL, host string, pinnedIP net.IP, validate bool, rootCAs *x509.CertPool) *chromeTransport {
+	t := &chromeTransport{
+		hello:     chromeHello(chromeMajor(userAgent)),
+		userAgent: userAgent,
+		proxy:     proxyURL,
+		host:      host,
+		pinnedIP:  pinnedIP,
+		validate:  validate,
+		rootCAs:   rootCAs,
+		dialer:    &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+		http1:     make(map[string]bool),
+	}
+	t.h2 = &http2.Transport{
+		DialTLSContext: func(ctx context.Context, _, addr string, _ *tls.Config) (net.Conn, error) {
+			conn, err := t.dialTLS(ctx, addr)
+			if err != nil {
+				return nil, err
+			}
+			if conn.ConnectionState().NegotiatedProtocol != "h2" {
+				conn.Close()
+				return nil, errNoH2
+			}
+			return conn, nil
+		},
+		DisableCompression:        true, // Chrome's Accept-Encoding is sent and decoded here
+		MaxDecoderHeaderTableSize: chromeH2TableSize,
+		MaxHeaderListSize:         chromeH2MaxHeaderList,
+		IdleConnTimeout:           fastPathIdleTimeout,
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *chromeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody {
+		return nil, errors.New("fast path requests cannot have a body")
+	}
+	addr := canonicalAddr(req.URL)
+
+	var resp *http.Response
+	var err error
+	if req.URL.Scheme == "https" {
+		resp, err = t.roundTripTLS(req, addr)
+	} else {
+		resp, err = t.roundTripPlain(req, addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	decodeBody(resp)
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle HTTP/2 connections.
+func (t *chromeTransport) CloseIdleConnections() {
+	t.h2.CloseIdleConnections()
+}
+
+// roundTripTLS sends req over HTTP/2, or over HTTP/1.1 to a server that
+// did not negotiate it.
+func (t *chromeTransport) roundTripTLS(req *http.Request, addr string) (*http.Response, error) {
+	t.mu.Lock()
+	http1 := t.http1[addr]
+	t.mu.Unlock()
+	if !http1 {
+		resp, err := t.h2.RoundTrip(req)
+		if !errors.Is(err, errNoH2) {
+			return resp, err
+		}
+		t.mu.Lock()
+		t.http1[addr] = true
+		t.mu.Unlock()
+	}
+
+	conn, err := t.dialTLS(req.Context(), addr)
+	if err != nil {
+		return nil, err
+	}
+	return roundTripHTTP1(req, conn, req.URL.RequestURI(), nil)
+}
+
+// roundTripPlain sends a cleartext request: through an HTTP proxy in
+// absolute form, otherwise over a direct or tunnelled connection.
+func (t *chromeTransport) roundTripPlain(req *http.Request, addr string) (*http.Response, error) {
+	if t.proxy != nil && (t.proxy.Scheme == "http" || t.proxy.Scheme == "https") {
+		conn, err := t.dialProxy(req.Context())
+		if err != nil {
+			return nil, err
+		}
+		return roundTripHTTP1(req, conn, req.URL.String(), t.proxyAuthorization())
+	}
+	conn, err := t.dial(req.Context(), addr)
+	if err != nil {
+		return nil, err
+	}
+	return roundTripHTTP1(req, conn, req.URL.RequestURI(), nil)
+}
+
+// dialTLS connects to addr and completes the TLS handshake with the
+// transport's Chrome ClientHello.
+func (t *chromeTransport) dialTLS(ctx context.Context, addr string) (*utls.UConn, error) {
+	serverName, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := t.dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	conn := utls.UClient(raw, &utls.Config{ServerName: serverName, RootCAs: t.rootCAs}, t.hello)
+
+	hsCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := conn.HandshakeContext(hsCtx); err != nil {
+		raw.Close()
+		return nil, fmt.Errorf("TLS handshake failed: %w", err)
+	}
+	return conn, nil
+}
+
+// dial opens a connection to addr through the egress, or tunnelled through
+// the proxy, which resolves the target itself. Directly, the pinned host is
+// dialed at the pinned IP, and any other host at the address it was
+// validated at, so DNS cannot be rebound between the check and the connect.
+func (t *chromeTransport) dial(ctx context.Context, addr string) (net.Conn, error) {
+	if t.proxy == nil {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case t.pinnedIP != nil && strings.EqualFold(host, t.host):
+			addr = net.JoinHostPort(t.pinnedIP.String(), port)
+		case t.validate:
+			_, ip, err := security.ValidateAndResolveURLWithContext(ctx, "https://"+addr)
+			if err != nil {
+				return nil, fmt.Errorf("fast path target %s rejected: %w", host, err)
+			}
+			addr = net.JoinHostPort(ip.String(), port)
+		}
+		return t.dialer.DialContext(ctx, "tcp", addr)
+	}
+
+	switch t.proxy.Scheme {
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if u := t.proxy.User; u != nil {
+			password, _ := u.Password()
+			auth = &proxy.Auth{User: u.Username(), Password: password}
+		}
+		d, err := proxy.SOCKS5("tcp", t.proxy.Host, auth, t.dialer)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SOCKS5 proxy: %w", err)
+		}
+		return d.(proxy.ContextDialer).DialContext(ctx, "tcp", addr)
+	case "http", "https":
+		return t.dialConnect(ctx, addr)
+	}
+	return nil, fmt.Errorf("unsupported proxy scheme %q", t.proxy.Scheme)
+}
+
+// dialProxy connects to the HTTP proxy, over TLS for an https proxy.
+func (t *chromeTransport) dialProxy(ctx context.Context) (net.Conn, error) {
+	conn, err := t.dialer.DialContext(ctx, "tcp", canonicalAddr(t.proxy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach proxy: %w", err)
+	}
+	if t.proxy.Scheme != "https" {
+		return conn, nil
+	}
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: t.proxy.Hostname()})
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy TLS handshake failed: %w", err)
+	}
+	return tlsConn, nil
+}
+
+// dialConnect opens a CONNECT tunnel to addr through the HTTP proxy.
+func (t *chromeTransport) dialConnect(ctx context.Context, addr string) (net.Conn, error) {
+	conn, err := t.dialProxy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	header := http.Header{"User-Agent": {t.userAgent}}
+	if auth := t.proxyAuthorization(); auth != nil {
+		header.Set("Proxy-Authorization", auth.Get("Proxy-Authorization"))
+	}
+	connect := &http.Request{Method: http.MethodConnect, URL: &url.URL{Opaque: addr}, Host: addr, Header: header}
+	if err := connect.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy CONNECT failed: %w", err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), connect)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy CONNECT failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy refused CONNECT: %s", resp.Status)
+	}
+	return conn, nil
+}
+
+// proxyAuthorization returns the Proxy-Authorization header for the proxy's
+// credentials, or nil without any.
+func (t *chromeTransport) proxyAuthorization() http.Header {
+	u := t.proxy.User
+	if u == nil {
+		return nil
+	}
+	password, _ := u.Password()
+	return http.Header{"Proxy-Authorization": {"Basic " + base64.StdEncoding.EncodeToString([]byte(u.Username()+":"+password))}}
+}
+
+// roundTripHTTP1 writes req over conn as Chrome would and reads the
+// response. target is the request-target; extra headers (proxy
+// authorization) follow Chrome's. The connection closes with the body.
+func roundTripHTTP1(req *http.Request, conn net.Conn, target string, extra http.Header) (*http.Response, error) {
+	stop := context.AfterFunc(req.Context(), func() { conn.Close() })
+
+	if err := writeHTTP1(conn, req, target, extra); err != nil {
+		stop()
+		conn.Close()
+		return nil, fmt.Errorf("failed to write request: %w", err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		stop()
+		conn.Close()
+		return nil, err
+	}
+	resp.Body = &closeWithBody{ReadCloser: resp.Body, close: func() {
+		stop()
+		conn.Close()
+	}}
+	return resp, nil
+}
+
+// writeHTTP1 writes req's request line and headers in Chrome's order.
+func writeHTTP1(w io.Writer, req *http.Request, target string, extra http.Header) error {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s %s HTTP/1.1\r\nHost: %s\r\nConnection: keep-alive\r\n", req.Method, target, host)
+	for _, f := range chromeHeaders(req.Header) {
+		if f.Name == "Priority" { // Chrome sends it over HTTP/2 only
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\r\n", f.Name, f.Value)
+	}
+	for k, vv := range extra {
+		for _, v := range vv {
+			fmt.Fprintf(&b, "%s: %s\r\n", k, v)
+		}
+	}
+	b.WriteString("\r\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// closeWithBody runs close after closing the body.
+type closeWithBody struct {
+	io.ReadCloser
+	close func()
+}
+
+func (b *closeWithBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.close()
+	return err
+}
+
+// decodeBody decodes a response body in any encoding Chrome advertises.
+// Unknown encodings are left as they are.
+func decodeBody(resp *http.Response) {
+	var decode func(io.Reader) (io.Reader, error)
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip":
+		decode = func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }
+	case "deflate":
+		decode = func(r io.Reader) (io.Reader, error) {
+			// "deflate" is zlib-wrapped, but some servers send it raw
+			br := bufio.NewReader(r)
+			if h, err := br.Peek(2); err == nil && h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0 {
+				return zlib.NewReader(br)
+			}
+			return flate.NewReader(br), nil
+		}
+	case "br":
+		decode = func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil }
+	case "zstd":
+		decode = func(r io.Reader) (io.Reader, error) {
+			d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+			if err != nil {
+				return nil, err
+			}
+			return d.IOReadCloser(), nil
+		}
+	default:
+		return
+	}
+	resp.Body = &decodedBody{body: resp.Body, decode: decode}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// decodedBody decodes its body on first read, so an empty body (a 204 or a
+// redirect) is not an error.
+type decodedBody struct {
+	body   io.ReadCloser
+	decode func(io.Reader) (io.Reader, error)
+	r      io.Reader
+}
+
+func (d *decodedBody) Read(p []byte) (int, error) {
+	if d.r == nil {
+		r, err := d.decode(d.body)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return 0, io.EOF
+			}
+			return 0, fmt.Errorf("failed to decode response: %w", err)
+		}
+		d.r = r
+	}
+	return d.r.Read(p)
+}
+
+func (d *decodedBody) Close() error {
+	if c, ok := d.r.(io.Closer); ok {
+		c.Close()
+	}
+	return d.body.Close()
+}
+
+// canonicalAddr returns u's host:port, with the scheme's default port.
+func canonicalAddr(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return net.JoinHostPort(u.Hostname(), port)
+	}
+	port := "80"
+	switch u.Scheme {
+	case "https":
+		port = "443"
+	case "socks5", "socks5h":
+		port = "1080"
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
@@ -0,0 +1,240 @@
+package solver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	utls "github.com/refraction-networking/utls"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+const chrome131UA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36"
+
+func TestChromeHello(t *testing.T) {
+	tests := []struct {
+		ua   string
+		want utls.ClientHelloID
+	}{
+		{chrome131UA, utls.HelloChrome_131},
+		{strings.Replace(chrome131UA, "131", "136", 1), utls.HelloChrome_133},
+		{strings.Replace(chrome131UA, "131", "126", 1), utls.HelloChrome_120_PQ},
+		{strings.Replace(chrome131UA, "131", "110", 1), utls.HelloChrome_106_Shuffle},
+		{strings.Replace(chrome131UA, "131", "90", 1), utls.HelloChrome_100},
+		{"Mozilla/5.0 Test", utls.HelloChrome_Auto},
+	}
+	for _, tt := range tests {
+		if got := chromeHello(chromeMajor(tt.ua)); got != tt.want {
+			t.Errorf("chromeHello(%q) = %v, want %v", tt.ua, got, tt.want)
+		}
+	}
+}
+
+func TestChromeClientHints(t *testing.T) {
+	// Brand lists as the Chrome versions send them
+	tests := []struct {
+		major int
+		want  string
+	}{
+		{120, `"Not_A Brand";v="8", "Chromium";v="120", "Google Chrome";v="120"`},
+		{131, `"Google Chrome";v="131", "Chromium";v="131", "Not_A Brand";v="24"`},
+		{133, `"Not(A:Brand";v="99", "Google Chrome";v="133", "Chromium";v="133"`},
+	}
+	for _, tt := range tests {
+		hints := chromeClientHints(chrome131UA, tt.major)
+		if hints["sec-ch-ua"] != tt.want {
+			t.Errorf("sec-ch-ua for %d = %s, want %s", tt.major, hints["sec-ch-ua"], tt.want)
+		}
+	}
+	if hints := chromeClientHints(chrome131UA, 131); hints["sec-ch-ua-platform"] != `"Windows"` || hints["sec-ch-ua-mobile"] != "?0" {
+		t.Errorf("hints = %v, want a Windows desktop", hints)
+	}
+}
+
+func TestWriteHTTP1(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/a?b=1", nil)
+	req.Header.Set("Cookie", "cf_clearance=x")
+	req.Header.Set("X-Custom", "1")
+	req.Header.Set("Accept-Language", "en-US")
+	req.Header.Set("User-Agent", chrome131UA)
+	req.Header.Set("sec-ch-ua-mobile", "?0")
+	req.Header.Set("Priority", "u=0, i")
+
+	var b bytes.Buffer
+	if err := writeHTTP1(&b, req, req.URL.RequestURI(), nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "GET /a?b=1 HTTP/1.1\r\n" +
+		"Host: example.com\r\n" +
+		"Connection: keep-alive\r\n" +
+		"sec-ch-ua-mobile: ?0\r\n" +
+		"User-Agent: " + chrome131UA + "\r\n" +
+		"Accept-Language: en-US\r\n" +
+		"Cookie: cf_clearance=x\r\n" +
+		"X-Custom: 1\r\n" +
+		"\r\n"
+	if b.String() != want {
+		t.Errorf("writeHTTP1() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestFastPathFetchTLS(t *testing.T) {
+	for _, h2 := range []bool{true, false} {
+		name := "http1"
+		if h2 {
+			name = "h2"
+		}
+		t.Run(name, func(t *testing.T) {
+			var got *http.Request
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r
+				page := []byte("<html><head><title>Home</title></head><body>content</body></html>")
+				var body bytes.Buffer
+				if h2 {
+					w.Header().Set("Content-Encoding", "zstd")
+					zw, _ := zstd.NewWriter(&body)
+					zw.Write(page)
+					zw.Close()
+				} else {
+					w.Header().Set("Content-Encoding", "gzip")
+					gw := gzip.NewWriter(&body)
+					gw.Write(page)
+					gw.Close()
+				}
+				w.Write(body.Bytes())
+			}))
+			server.EnableHTTP2 = h2
+			server.StartTLS()
+			defer server.Close()
+
+			fp := NewFastPath()
+			fp.rootCAs = x509.NewCertPool()
+			fp.rootCAs.AddCert(server.Certificate())
+			opts := &SolveOptions{
+				URL:                    server.URL + "/",
+				Timeout:                5 * time.Second,
+				Cookies:                []types.RequestCookie{{Name: cfClearanceCookie, Value: "minted"}},
+				SkipResponseValidation: true,
+			}
+
+			// Twice, so the second h2 request reuses the connection
+			for i := 0; i < 2; i++ {
+				result, err := fp.Fetch(context.Background(), opts, &ClearanceEntry{userAgent: chrome131UA})
+				if err != nil {
+					t.Fatalf("Fetch() error = %v", err)
+				}
+				if !strings.Contains(result.HTML, "content") {
+					t.Errorf("HTML = %q, want the decoded page", result.HTML)
+				}
+				if _, ok := result.ResponseHeaders["content-encoding"]; ok {
+					t.Errorf("ResponseHeaders = %v, want content-encoding dropped after decoding", result.ResponseHeaders)
+				}
+			}
+
+			if got.ProtoMajor != map[bool]int{true: 2, false: 1}[h2] {
+				t.Errorf("Proto = %s, want h2 %v", got.Proto, h2)
+			}
+			if got.UserAgent() != chrome131UA || got.Header.Get("Accept-Encoding") != "gzip, deflate, br, zstd" {
+				t.Errorf("headers = %v, want the minting UA and Chrome's encodings", got.Header)
+			}
+			if got.Header.Get("Sec-Ch-Ua-Platform") != `"Windows"` {
+				t.Errorf("headers = %v, want client hints for the UA", got.Header)
+			}
+			if (got.Header.Get("Priority") != "") != h2 {
+				t.Errorf("Priority = %q, want it over h2 only", got.Header.Get("Priority"))
+			}
+			if c, err := got.Cookie(cfClearanceCookie); err != nil || c.Value != "minted" {
+				t.Errorf("cf_clearance = %v (%v), want minted", c, err)
+			}
+		})
+	}
+}
+
+func TestFastPathFetchThroughProxy(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>Home</title></head></html>"))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	var tunnelled string
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect || r.Header.Get("Proxy-Authorization") != "Basic dXNlcjpwYXNz" {
+			http.Error(w, "proxy auth required", http.StatusProxyAuthRequired)
+			return
+		}
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		tunnelled = r.Host
+		w.WriteHeader(http.StatusOK)
+		client, _, _ := w.(http.Hijacker).Hijack()
+		go func() {
+			io.Copy(target, client)
+			target.Close()
+		}()
+		io.Copy(client, target)
+		client.Close()
+	}))
+	defer proxyServer.Close()
+
+	fp := NewFastPath()
+	fp.rootCAs = x509.NewCertPool()
+	fp.rootCAs.AddCert(server.Certificate())
+	opts := &SolveOptions{
+		URL:                    server.URL + "/",
+		Timeout:                5 * time.Second,
+		Proxy:                  &types.Proxy{URL: proxyServer.URL, Username: "user", Password: "pass"},
+		SkipResponseValidation: true,
+	}
+	result, err := fp.Fetch(context.Background(), opts, &ClearanceEntry{userAgent: chrome131UA})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if result.StatusCode != http.StatusOK || tunnelled != strings.TrimPrefix(server.URL, "https://") {
+		t.Errorf("Fetch() = %d via %q, want a 200 tunnelled to %s", result.StatusCode, tunnelled, server.URL)
+	}
+}
+
+// TestFastPathRedirectToPrivateIP verifies a redirect hop is validated at
+// dial time, so a hop to an internal address is never connected to even
+// without the client's redirect check.
+func TestFastPathRedirectToPrivateIP(t *testing.T) {
+	var reached atomic.Bool
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached.Store(true)
+	}))
+	defer internal.Close()
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL+"/admin", http.StatusFound)
+	}))
+	defer public.Close()
+
+	// public.example is pinned to the test server, as the handler pins a
+	// validated target; the hop to the internal server is not
+	_, port, _ := net.SplitHostPort(public.Listener.Addr().String())
+	transport := newChromeTransport(chrome131UA, nil, "public.example", net.ParseIP("127.0.0.1"), true, nil)
+	client := &http.Client{Transport: transport, Timeout: 5 * time.Second}
+
+	_, err := client.Get("http://public.example:" + port + "/")
+	if err == nil || !strings.Contains(err.Error(), "rejected") {
+		t.Errorf("Get() error = %v, want the redirect hop rejected", err)
+	}
+	if reached.Load() {
+		t.Error("Redirect hop to a private IP was connected to")
+	}
+}
@@ -0,0 +1,101 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func TestFastPathEligible(t *testing.T) {
+	tests := []struct {
+		name string
+		opts SolveOptions
+		want bool
+	}{
+		{"plain get", SolveOptions{URL: "https://example.com"}, true},
+		{"custom headers", SolveOptions{Headers: map[string]string{"Referer": "https://example.com"}}, true},
+		{"post", SolveOptions{IsPost: true}, false},
+		{"put", SolveOptions{Method: "PUT"}, false},
+		{"screenshot", SolveOptions{Screenshot: true}, false},
+		{"executeJs", SolveOptions{ExecuteJs: "1"}, false},
+		{"actions", SolveOptions{Actions: []types.Action{{Type: types.ActionClick, Selector: "a"}}}, false},
+		{"waitForSelector", SolveOptions{WaitForSelector: "#app"}, false},
+		{"download", SolveOptions{Download: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fastPathEligible(&tt.opts); got != tt.want {
+				t.Errorf("fastPathEligible() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFastPathFetch(t *testing.T) {
+	const ua = "Mozilla/5.0 Test"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clearance, err := r.Cookie(cfClearanceCookie)
+		if err != nil || clearance.Value != "minted" || r.UserAgent() != ua {
+			w.Header().Set("Cf-Mitigated", "challenge")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("<html><head><title>Just a moment...</title></head></html>"))
+			return
+		}
+		if r.URL.Path == "/old" {
+			http.SetCookie(w, &http.Cookie{Name: "hop", Value: "1"})
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", MaxAge: 60})
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><head><title>Home</title></head><body>content</body></html>"))
+	}))
+	defer server.Close()
+
+	entry := &ClearanceEntry{userAgent: ua}
+	opts := &SolveOptions{
+		URL:                    server.URL + "/old",
+		Timeout:                5 * time.Second,
+		Cookies:                []types.RequestCookie{{Name: cfClearanceCookie, Value: "minted"}},
+		SkipResponseValidation: true,
+	}
+	fp := NewFastPath()
+
+	result, err := fp.Fetch(context.Background(), opts, entry)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if !result.Success || result.StatusCode != http.StatusOK || result.URL != server.URL+"/new" || result.UserAgent != ua {
+		t.Errorf("Fetch() = %+v, want a 200 from /new with the entry's user agent", result)
+	}
+	if result.ResponseHeaders["content-type"] != "text/html" {
+		t.Errorf("ResponseHeaders = %v, want lower-case content-type", result.ResponseHeaders)
+	}
+	names := map[string]string{}
+	for _, c := range result.Cookies {
+		names[c.Name] = c.Value
+	}
+	if names[cfClearanceCookie] != "minted" || names["session"] != "abc" || names["hop"] != "1" {
+		t.Errorf("Cookies = %v, want the clearance plus cookies set on the redirect and the page", names)
+	}
+
+	// Without the clearance Cloudflare challenges, and the browser takes over
+	opts.Cookies = nil
+	if _, err := fp.Fetch(context.Background(), opts, entry); !errors.Is(err, errFastPathChallenged) {
+		t.Errorf("Fetch() without clearance error = %v, want errFastPathChallenged", err)
+	}
+}
+
+func TestHasChallengeTitle(t *testing.T) {
+	if !hasChallengeTitle("<html><head><TITLE>Just a moment...</TITLE>") {
+		t.Error("hasChallengeTitle() should match the interstitial title")
+	}
+	if hasChallengeTitle("<title>Shop</title><p>please wait while we load</p>") {
+		t.Error("hasChallengeTitle() should only look at the title")
+	}
+}
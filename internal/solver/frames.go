@@ -0,0 +1,307 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/humanize"
+	"github.com/Rorqualx/flaresolverr-go/internal/selectors"
+)
+
+// maxFrameDepth bounds how many challenge iframes deep the frame walker
+// descends. The managed challenge nests two or three.
+const maxFrameDepth = 4
+
+// errFrameCheckboxNotFound is returned when no challenge frame holds a checkbox.
+var errFrameCheckboxNotFound = errors.New("no checkbox found in challenge frames")
+
+// frameClient sends CDP commands to one target session through the browser
+// connection, so the typed proto calls work on OOPIF sessions rod has no
+// Page for.
+type frameClient struct {
+	browser *rod.Browser
+	session proto.TargetSessionID
+	ctx     context.Context
+}
+
+func (c *frameClient) Call(ctx context.Context, sessionID, method string, params interface{}) ([]byte, error) {
+	return c.browser.Call(ctx, sessionID, method, params)
+}
+
+func (c *frameClient) GetSessionID() proto.TargetSessionID { return c.session }
+
+func (c *frameClient) GetContext() context.Context { return c.ctx }
+
+// frameWalker finds the managed challenge checkbox through nested
+// cross-origin iframes. Each out-of-process iframe is a separate CDP target
+// whose DOM the page cannot see, so the walker attaches to them one level at
+// a time: the iframe's frame ID is its target ID. Deeper frames are
+// auto-attached under our own sessions and reported by Target events; frames
+// swapped out by the challenge drop out on detach.
+//
+// The walker's sessions are its own, separate from the turnstile.render
+// interceptor's auto-attach on the page session.
+type frameWalker struct {
+	page    *rod.Page
+	browser *rod.Browser
+	sel     *selectors.Selectors
+
+	mu       sync.Mutex
+	sessions map[proto.TargetTargetID]proto.TargetSessionID // frame (target) ID -> our session
+	stop     func()
+}
+
+// newFrameWalker starts listening for Target events on the page's browser.
+// Call close when done to detach the walker's sessions.
+func newFrameWalker(page *rod.Page, sel *selectors.Selectors) *frameWalker {
+	w := &frameWalker{
+		page:     page,
+		browser:  page.Browser(),
+		sel:      sel,
+		sessions: make(map[proto.TargetTargetID]proto.TargetSessionID),
+	}
+	if w.browser == nil {
+		return w
+	}
+
+	b, cancel := w.browser.WithCancel()
+	w.stop = cancel
+	wait := b.EachEvent(
+		func(e *proto.TargetAttachedToTarget, envelope proto.TargetSessionID) {
+			if e.TargetInfo != nil && w.owns(envelope) {
+				w.track(e.TargetInfo.TargetID, e.SessionID)
+			}
+		},
+		func(e *proto.TargetDetachedFromTarget) {
+			w.untrack(e.SessionID)
+		},
+	)
+	go wait()
+	return w
+}
+
+// owns reports whether session is one the walker attached.
+func (w *frameWalker) owns(session proto.TargetSessionID) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, s := range w.sessions {
+		if s == session {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *frameWalker) track(target proto.TargetTargetID, session proto.TargetSessionID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.sessions[target]; !ok {
+		w.sessions[target] = session
+	}
+}
+
+func (w *frameWalker) untrack(session proto.TargetSessionID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for target, s := range w.sessions {
+		if s == session {
+			delete(w.sessions, target)
+		}
+	}
+}
+
+// close stops listening and detaches every session the walker holds.
+func (w *frameWalker) close() {
+	if w.stop != nil {
+		w.stop()
+	}
+	w.mu.Lock()
+	sessions := make([]proto.TargetSessionID, 0, len(w.sessions))
+	for _, s := range w.sessions {
+		sessions = append(sessions, s)
+	}
+	w.sessions = map[proto.TargetTargetID]proto.TargetSessionID{}
+	w.mu.Unlock()
+
+	for _, s := range sessions {
+		_ = proto.TargetDetachFromTarget{SessionID: s}.Call(w.browser)
+	}
+}
+
+// frameSession returns a session on the out-of-process iframe with frameID,
+// attaching to it unless a Target event already delivered one. Same-process
+// iframes have no target of their own and return an error; their documents
+// are part of the parent's pierced DOM.
+func (w *frameWalker) frameSession(ctx context.Context, frameID proto.PageFrameID) (*frameClient, error) {
+	target := proto.TargetTargetID(frameID)
+	w.mu.Lock()
+	session, ok := w.sessions[target]
+	w.mu.Unlock()
+
+	if !ok {
+		res, err := proto.TargetAttachToTarget{TargetID: target, Flatten: true}.Call(w.browser.Context(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("attach to frame: %w", err)
+		}
+		session = res.SessionID
+		w.track(target, session)
+	}
+
+	client := &frameClient{browser: w.browser, session: session, ctx: ctx}
+	_ = proto.DOMEnable{}.Call(client)
+	// Nested out-of-process frames attach under this session and arrive as
+	// Target events
+	_ = proto.TargetSetAutoAttach{AutoAttach: true, Flatten: true}.Call(client)
+	return client, nil
+}
+
+// findCheckbox walks the challenge frames from the page down and returns the
+// checkbox centre in page coordinates.
+func (w *frameWalker) findCheckbox(ctx context.Context) (x, y float64, err error) {
+	if w.browser == nil {
+		return 0, 0, fmt.Errorf("page has no browser")
+	}
+	return w.walk(ctx, w.page.Context(ctx), 0, 0, 0)
+}
+
+// walk searches one frame level. client is the frame's session and
+// (offsetX, offsetY) the page position of its viewport. The page level is
+// never searched for a checkbox, only for challenge frames, so an unrelated
+// form checkbox cannot be clicked.
+func (w *frameWalker) walk(ctx context.Context, client proto.Client, depth int, offsetX, offsetY float64) (float64, float64, error) {
+	if ctx.Err() != nil {
+		return 0, 0, ctx.Err()
+	}
+
+	roots, err := documentRoots(client)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if depth > 0 {
+		for _, selector := range w.sel.ManagedCheckbox {
+			for _, root := range roots {
+				res, err := proto.DOMQuerySelector{NodeID: root, Selector: selector}.Call(client)
+				if err != nil || res.NodeID == 0 {
+					continue
+				}
+				if cx, cy, ok := nodeCenter(client, res.NodeID); ok {
+					log.Ctx(ctx).Debug().Int("depth", depth).Str("selector", selector).Msg("Found checkbox in challenge frame")
+					return offsetX + cx, offsetY + cy, nil
+				}
+			}
+		}
+	}
+	if depth >= maxFrameDepth {
+		return 0, 0, errFrameCheckboxNotFound
+	}
+
+	for _, selector := range w.sel.ManagedFrames {
+		for _, root := range roots {
+			res, err := proto.DOMQuerySelectorAll{NodeID: root, Selector: selector}.Call(client)
+			if err != nil {
+				continue
+			}
+			for _, nodeID := range res.NodeIDs {
+				desc, err := proto.DOMDescribeNode{NodeID: nodeID}.Call(client)
+				if err != nil || desc.Node == nil || desc.Node.FrameID == "" {
+					continue
+				}
+				box, err := proto.DOMGetBoxModel{NodeID: nodeID}.Call(client)
+				if err != nil || len(box.Model.Content) < 2 {
+					continue
+				}
+				child, err := w.frameSession(ctx, desc.Node.FrameID)
+				if err != nil {
+					log.Ctx(ctx).Debug().Err(err).Int("depth", depth).Msg("Challenge frame is not a separate target")
+					continue
+				}
+				x, y, err := w.walk(ctx, child, depth+1, offsetX+box.Model.Content[0], offsetY+box.Model.Content[1])
+				if err == nil {
+					return x, y, nil
+				}
+			}
+		}
+	}
+	return 0, 0, errFrameCheckboxNotFound
+}
+
+// documentRoots returns the node IDs to query in a frame: the document, every
+// shadow root (closed ones included, via pierce) and same-process iframe
+// documents.
+func documentRoots(client proto.Client) ([]proto.DOMNodeID, error) {
+	depth := -1
+	doc, err := proto.DOMGetDocument{Depth: &depth, Pierce: true}.Call(client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get frame DOM: %w", err)
+	}
+	if doc == nil || doc.Root == nil {
+		return nil, fmt.Errorf("frame DOM is empty")
+	}
+
+	var roots []proto.DOMNodeID
+	var visit func(node *proto.DOMNode)
+	visit = func(node *proto.DOMNode) {
+		if node == nil {
+			return
+		}
+		for _, shadow := range node.ShadowRoots {
+			roots = append(roots, shadow.NodeID)
+			visit(shadow)
+		}
+		if node.ContentDocument != nil {
+			roots = append(roots, node.ContentDocument.NodeID)
+			visit(node.ContentDocument)
+		}
+		for _, child := range node.Children {
+			visit(child)
+		}
+	}
+	roots = append(roots, doc.Root.NodeID)
+	visit(doc.Root)
+	return roots, nil
+}
+
+// nodeCenter returns the centre of a node's content box in its frame's
+// viewport, skipping nodes that are not rendered.
+func nodeCenter(client proto.Client, nodeID proto.DOMNodeID) (float64, float64, bool) {
+	box, err := proto.DOMGetBoxModel{NodeID: nodeID}.Call(client)
+	if err != nil || box.Model == nil || len(box.Model.Content) < 8 || box.Model.Width == 0 || box.Model.Height == 0 {
+		return 0, 0, false
+	}
+	q := box.Model.Content
+	return (q[0] + q[2] + q[4] + q[6]) / 4, (q[1] + q[3] + q[5] + q[7]) / 4, true
+}
+
+// solveTurnstileFrames clicks the Turnstile checkbox found by walking nested
+// challenge iframes, the layout of Cloudflare's newer managed challenge that
+// the selector- and shadow-based methods cannot reach.
+//
+// Detection risk: LOW - CDP DOM reads, humanized click on the page
+func (s *Solver) solveTurnstileFrames(ctx context.Context, page *rod.Page) error {
+	log.Ctx(ctx).Debug().Msg("Trying frame tree walk for managed challenge checkbox")
+
+	walker := newFrameWalker(page, s.getSelectors())
+	defer walker.close()
+
+	x, y, err := walker.findCheckbox(ctx)
+	if err != nil {
+		return fmt.Errorf("frame walk failed: %w", err)
+	}
+
+	if err := humanize.NewMouse(page).Click(ctx, x, y); err != nil {
+		return fmt.Errorf("frame checkbox click failed: %w", err)
+	}
+	log.Ctx(ctx).Info().Float64("x", x).Float64("y", y).Msg("Clicked managed challenge checkbox via frame tree walk")
+
+	if !sleepWithContext(ctx, humanize.RandomDuration(250, 450)) {
+		return fmt.Errorf("context canceled after frame checkbox click")
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package solver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/selectors"
+)
+
+// fakeFrameClient answers DOM commands for one frame from canned results.
+type fakeFrameClient struct {
+	results map[string]interface{}
+}
+
+func (c *fakeFrameClient) Call(_ context.Context, _, method string, _ interface{}) ([]byte, error) {
+	res, ok := c.results[method]
+	if !ok {
+		return nil, errors.New("unexpected method " + method)
+	}
+	return json.Marshal(res)
+}
+
+func TestDocumentRoots(t *testing.T) {
+	client := &fakeFrameClient{results: map[string]interface{}{
+		"DOM.getDocument": map[string]interface{}{
+			"root": map[string]interface{}{
+				"nodeId": 1,
+				"children": []interface{}{
+					map[string]interface{}{
+						"nodeId":      2,
+						"shadowRoots": []interface{}{map[string]interface{}{"nodeId": 3}},
+					},
+					map[string]interface{}{
+						"nodeId":          4,
+						"contentDocument": map[string]interface{}{"nodeId": 5},
+					},
+				},
+			},
+		},
+	}}
+
+	roots, err := documentRoots(client)
+	if err != nil {
+		t.Fatalf("documentRoots() error = %v", err)
+	}
+	want := []int{1, 3, 5}
+	if len(roots) != len(want) {
+		t.Fatalf("documentRoots() = %v, want %v", roots, want)
+	}
+	for i, id := range want {
+		if int(roots[i]) != id {
+			t.Errorf("roots[%d] = %d, want %d", i, roots[i], id)
+		}
+	}
+}
+
+func TestFrameWalkerCheckboxOffset(t *testing.T) {
+	client := &fakeFrameClient{results: map[string]interface{}{
+		"DOM.getDocument":   map[string]interface{}{"root": map[string]interface{}{"nodeId": 1}},
+		"DOM.querySelector": map[string]interface{}{"nodeId": 7},
+		"DOM.getBoxModel": map[string]interface{}{"model": map[string]interface{}{
+			"content": []float64{10, 20, 30, 20, 30, 40, 10, 40},
+			"width":   20,
+			"height":  20,
+		}},
+	}}
+	w := &frameWalker{sel: selectors.Get()}
+
+	// A checkbox at (20, 30) in a frame whose viewport starts at (100, 200)
+	x, y, err := w.walk(context.Background(), client, 1, 100, 200)
+	if err != nil {
+		t.Fatalf("walk() error = %v", err)
+	}
+	if x != 120 || y != 230 {
+		t.Errorf("walk() = (%v, %v), want (120, 230)", x, y)
+	}
+}
+
+func TestFrameWalkerSkipsPageCheckbox(t *testing.T) {
+	client := &fakeFrameClient{results: map[string]interface{}{
+		"DOM.getDocument":      map[string]interface{}{"root": map[string]interface{}{"nodeId": 1}},
+		"DOM.querySelectorAll": map[string]interface{}{"nodeIds": []int{}},
+	}}
+	w := &frameWalker{sel: selectors.Get()}
+
+	// DOM.querySelector is not answered: the page level must not look for a
+	// checkbox, and with no challenge frames the walk finds nothing
+	if _, _, err := w.walk(context.Background(), client, 0, 0, 0); !errors.Is(err, errFrameCheckboxNotFound) {
+		t.Errorf("walk() error = %v, want errFrameCheckboxNotFound", err)
+	}
+}
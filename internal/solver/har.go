@@ -0,0 +1,344 @@
+package solver
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+
+	"github.com/Rorqualx/flaresolverr-go/pkg/version"
+)
+
+// maxHAREntries caps the requests recorded in a HAR archive; later requests
+// are dropped and noted in the log comment.
+const maxHAREntries = 1000
+
+// HAR 1.2 archive (http://www.softwareishard.com/blog/har-12-spec/). Only
+// the fields the network events provide are filled; bodies are not kept.
+type harLog struct {
+	Log harContent `json:"log"`
+}
+
+type harContent struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+	Comment string     `json:"comment,omitempty"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harBodyContent `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harBodyContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// harRecorder builds HAR entries from Network events. Each request ID
+// becomes one entry per leg: a redirect closes the current entry with the
+// redirect response and opens a new one.
+type harRecorder struct {
+	mu      sync.Mutex
+	entries []*harEntry
+	open    map[proto.NetworkRequestID]*harPending
+	dropped int
+}
+
+// harPending is an entry whose response or completion has not arrived yet.
+type harPending struct {
+	entry     *harEntry
+	startedAt proto.MonotonicTime
+	timing    *proto.NetworkResourceTiming
+}
+
+// EnableHAR starts recording every request the page makes for HAR. Call
+// before navigating.
+func (nc *NetworkCapture) EnableHAR() {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	if nc.har == nil {
+		nc.har = newHARRecorder()
+	}
+}
+
+// HAR returns the recorded traffic as a HAR 1.2 archive, or nil when HAR
+// recording is off. Safe to call on a nil capture.
+func (nc *NetworkCapture) HAR() ([]byte, error) {
+	if nc == nil {
+		return nil, nil
+	}
+	nc.mu.RLock()
+	har := nc.har
+	nc.mu.RUnlock()
+	if har == nil {
+		return nil, nil
+	}
+	return har.archive()
+}
+
+// activeHAR returns the HAR recorder, or nil when recording is off.
+func (nc *NetworkCapture) activeHAR() *harRecorder {
+	nc.mu.RLock()
+	defer nc.mu.RUnlock()
+	return nc.har
+}
+
+func newHARRecorder() *harRecorder {
+	return &harRecorder{open: make(map[proto.NetworkRequestID]*harPending)}
+}
+
+// requestWillBeSent opens an entry, closing the previous leg on a redirect.
+func (h *harRecorder) requestWillBeSent(e *proto.NetworkRequestWillBeSent) {
+	if e.Request == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if prev, ok := h.open[e.RequestID]; ok && e.RedirectResponse != nil {
+		prev.setResponse(e.RedirectResponse)
+		prev.finish(e.Timestamp, 0)
+		delete(h.open, e.RequestID)
+	}
+	if len(h.entries) >= maxHAREntries {
+		h.dropped++
+		return
+	}
+
+	req := harRequest{
+		Method:      e.Request.Method,
+		URL:         e.Request.URL + e.Request.URLFragment,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(e.Request.Headers),
+		QueryString: harQuery(e.Request.URL),
+		HeadersSize: -1,
+		BodySize:    0,
+	}
+	if e.Request.PostData != "" {
+		req.PostData = &harPostData{MimeType: e.Request.Headers["Content-Type"].Str(), Text: e.Request.PostData}
+		req.BodySize = len(e.Request.PostData)
+	}
+	entry := &harEntry{
+		StartedDateTime: e.WallTime.Time().UTC().Format(time.RFC3339Nano),
+		Request:         req,
+		Response: harResponse{
+			Cookies:     []harNameValue{},
+			Headers:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: harTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Receive: 0},
+	}
+	h.entries = append(h.entries, entry)
+	h.open[e.RequestID] = &harPending{entry: entry, startedAt: e.Timestamp}
+}
+
+// responseReceived fills in the response of an open entry.
+func (h *harRecorder) responseReceived(e *proto.NetworkResponseReceived) {
+	if e.Response == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if p, ok := h.open[e.RequestID]; ok {
+		p.setResponse(e.Response)
+	}
+}
+
+// loadingFinished closes an entry with its transfer size.
+func (h *harRecorder) loadingFinished(e *proto.NetworkLoadingFinished) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if p, ok := h.open[e.RequestID]; ok {
+		p.finish(e.Timestamp, int(e.EncodedDataLength))
+		delete(h.open, e.RequestID)
+	}
+}
+
+// loadingFailed closes an entry that got no (complete) response.
+func (h *harRecorder) loadingFailed(e *proto.NetworkLoadingFailed) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if p, ok := h.open[e.RequestID]; ok {
+		p.entry.Comment = e.ErrorText
+		if e.BlockedReason != "" {
+			p.entry.Comment += " (blocked: " + string(e.BlockedReason) + ")"
+		}
+		p.finish(e.Timestamp, 0)
+		delete(h.open, e.RequestID)
+	}
+}
+
+func (p *harPending) setResponse(r *proto.NetworkResponse) {
+	e := p.entry
+	e.Request.HTTPVersion = harHTTPVersion(r.Protocol)
+	e.Response.Status = r.Status
+	e.Response.StatusText = r.StatusText
+	e.Response.HTTPVersion = harHTTPVersion(r.Protocol)
+	e.Response.Headers = harHeaders(r.Headers)
+	e.Response.Content.MimeType = r.MIMEType
+	e.Response.RedirectURL = r.Headers["Location"].Str()
+	if e.Response.RedirectURL == "" {
+		e.Response.RedirectURL = r.Headers["location"].Str()
+	}
+	if r.RemoteIPAddress != "" {
+		e.ServerIPAddress = r.RemoteIPAddress
+	}
+	p.timing = r.Timing
+}
+
+// finish sets the entry's total time and phase timings once it completes.
+func (p *harPending) finish(at proto.MonotonicTime, size int) {
+	e := p.entry
+	e.Time = msBetween(p.startedAt, at)
+	e.Response.BodySize = size
+	e.Response.Content.Size = size
+
+	t := p.timing
+	if t == nil {
+		e.Timings.Wait = e.Time
+		return
+	}
+	e.Timings.DNS = harPhase(t.DNSStart, t.DNSEnd)
+	e.Timings.Connect = harPhase(t.ConnectStart, t.ConnectEnd)
+	e.Timings.SSL = harPhase(t.SslStart, t.SslEnd)
+	e.Timings.Send = max(t.SendEnd-t.SendStart, 0)
+	e.Timings.Wait = max(t.ReceiveHeadersEnd-t.SendEnd, 0)
+	// Timing offsets are relative to RequestTime, which can trail the
+	// requestWillBeSent timestamp
+	headersAt := (t.RequestTime-float64(p.startedAt))*1000 + t.ReceiveHeadersEnd
+	e.Timings.Receive = max(e.Time-headersAt, 0)
+}
+
+// archive returns the HAR log as JSON. Requests still in flight are included
+// without a response.
+func (h *harRecorder) archive() ([]byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	content := harContent{
+		Version: "1.2",
+		Creator: harCreator{Name: "flaresolverr-go", Version: version.Version},
+		Entries: make([]harEntry, 0, len(h.entries)),
+	}
+	for _, e := range h.entries {
+		content.Entries = append(content.Entries, *e)
+	}
+	if h.dropped > 0 {
+		content.Comment = "entry limit reached; later requests were not recorded"
+	}
+	return json.Marshal(harLog{Log: content})
+}
+
+func msBetween(from, to proto.MonotonicTime) float64 {
+	if to < from {
+		return 0
+	}
+	return float64(to-from) * 1000
+}
+
+// harPhase returns the length of a timing phase, or -1 when it did not apply.
+func harPhase(start, end float64) float64 {
+	if start < 0 || end < start {
+		return -1
+	}
+	return end - start
+}
+
+func harHTTPVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "h2":
+		return "HTTP/2.0"
+	case "h3", "h3-29":
+		return "HTTP/3.0"
+	case "":
+		return ""
+	}
+	return strings.ToUpper(protocol)
+}
+
+// harHeaders converts CDP headers to sorted HAR name/value pairs.
+func harHeaders(headers proto.NetworkHeaders) []harNameValue {
+	out := make([]harNameValue, 0, len(headers))
+	for name, value := range headers {
+		out = append(out, harNameValue{Name: name, Value: value.Str()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// harQuery returns a URL's query parameters in order.
+func harQuery(raw string) []harNameValue {
+	out := []harNameValue{}
+	u, err := url.Parse(raw)
+	if err != nil || u.RawQuery == "" {
+		return out
+	}
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		name, value, _ := strings.Cut(pair, "=")
+		name, _ = url.QueryUnescape(name)
+		value, _ = url.QueryUnescape(value)
+		out = append(out, harNameValue{Name: name, Value: value})
+	}
+	return out
+}
@@ -0,0 +1,116 @@
+package solver
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/ysmood/gson"
+)
+
+func TestHARRecorder(t *testing.T) {
+	h := newHARRecorder()
+	headers := func(kv ...string) proto.NetworkHeaders {
+		out := proto.NetworkHeaders{}
+		for i := 0; i+1 < len(kv); i += 2 {
+			out[kv[i]] = gson.New(kv[i+1])
+		}
+		return out
+	}
+
+	// A redirected document: one request ID, two legs
+	h.requestWillBeSent(&proto.NetworkRequestWillBeSent{
+		RequestID: "1",
+		Request:   &proto.NetworkRequest{Method: "GET", URL: "http://example.com/?a=1&b=x%20y", Headers: headers("Accept", "*/*")},
+		Timestamp: 10,
+	})
+	h.requestWillBeSent(&proto.NetworkRequestWillBeSent{
+		RequestID:        "1",
+		Request:          &proto.NetworkRequest{Method: "GET", URL: "https://example.com/"},
+		Timestamp:        10.25,
+		RedirectResponse: &proto.NetworkResponse{Status: 301, StatusText: "Moved Permanently", Protocol: "http/1.1", Headers: headers("Location", "https://example.com/")},
+	})
+	h.responseReceived(&proto.NetworkResponseReceived{
+		RequestID: "1",
+		Response:  &proto.NetworkResponse{Status: 403, Protocol: "h2", MIMEType: "text/html", RemoteIPAddress: "192.0.2.1"},
+	})
+	h.loadingFinished(&proto.NetworkLoadingFinished{RequestID: "1", Timestamp: 11, EncodedDataLength: 512})
+
+	// A blocked subresource
+	h.requestWillBeSent(&proto.NetworkRequestWillBeSent{
+		RequestID: "2",
+		Request:   &proto.NetworkRequest{Method: "POST", URL: "https://example.com/api", PostData: "q=1", Headers: headers("Content-Type", "application/x-www-form-urlencoded")},
+		Timestamp: 10.5,
+	})
+	h.loadingFailed(&proto.NetworkLoadingFailed{RequestID: "2", Timestamp: 10.6, ErrorText: "net::ERR_BLOCKED_BY_CLIENT"})
+
+	raw, err := h.archive()
+	if err != nil {
+		t.Fatalf("archive() error = %v", err)
+	}
+	var har harLog
+	if err := json.Unmarshal(raw, &har); err != nil {
+		t.Fatalf("archive() is not valid JSON: %v", err)
+	}
+	if har.Log.Version != "1.2" || har.Log.Creator.Name != "flaresolverr-go" {
+		t.Errorf("log header = %q / %+v", har.Log.Version, har.Log.Creator)
+	}
+	if len(har.Log.Entries) != 3 {
+		t.Fatalf("entries = %d, want 3", len(har.Log.Entries))
+	}
+
+	redirect := har.Log.Entries[0]
+	if redirect.Response.Status != 301 || redirect.Response.RedirectURL != "https://example.com/" {
+		t.Errorf("redirect response = %+v", redirect.Response)
+	}
+	if redirect.Response.HTTPVersion != "HTTP/1.1" || redirect.Time != 250 {
+		t.Errorf("redirect httpVersion = %q, time = %v", redirect.Response.HTTPVersion, redirect.Time)
+	}
+	if q := redirect.Request.QueryString; len(q) != 2 || q[1].Name != "b" || q[1].Value != "x y" {
+		t.Errorf("queryString = %+v", q)
+	}
+
+	final := har.Log.Entries[1]
+	if final.Response.Status != 403 || final.Response.HTTPVersion != "HTTP/2.0" || final.ServerIPAddress != "192.0.2.1" {
+		t.Errorf("final entry = %+v", final)
+	}
+	if final.Response.BodySize != 512 || final.Time != 750 {
+		t.Errorf("final bodySize = %d, time = %v", final.Response.BodySize, final.Time)
+	}
+
+	failed := har.Log.Entries[2]
+	if failed.Comment != "net::ERR_BLOCKED_BY_CLIENT" || failed.Response.Status != 0 {
+		t.Errorf("failed entry = %+v", failed)
+	}
+	if failed.Request.PostData == nil || failed.Request.PostData.MimeType != "application/x-www-form-urlencoded" {
+		t.Errorf("failed postData = %+v", failed.Request.PostData)
+	}
+}
+
+func TestHARRecorderEntryLimit(t *testing.T) {
+	h := newHARRecorder()
+	for i := 0; i < maxHAREntries+1; i++ {
+		h.requestWillBeSent(&proto.NetworkRequestWillBeSent{
+			RequestID: proto.NetworkRequestID(string(rune('a' + i%26))),
+			Request:   &proto.NetworkRequest{Method: "GET", URL: "https://example.com/"},
+		})
+	}
+	if len(h.entries) != maxHAREntries || h.dropped != 1 {
+		t.Errorf("entries = %d, dropped = %d", len(h.entries), h.dropped)
+	}
+}
+
+func TestNetworkCaptureHARDisabled(t *testing.T) {
+	var nilCapture *NetworkCapture
+	if har, err := nilCapture.HAR(); har != nil || err != nil {
+		t.Errorf("nil capture HAR() = %s, %v", har, err)
+	}
+	nc := newNetworkCapture()
+	if har, _ := nc.HAR(); har != nil {
+		t.Errorf("HAR() without EnableHAR = %s", har)
+	}
+	nc.EnableHAR()
+	if har, _ := nc.HAR(); har == nil {
+		t.Error("HAR() after EnableHAR = nil")
+	}
+}
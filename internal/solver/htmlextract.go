@@ -0,0 +1,89 @@
+package solver
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-rod/rod"
+	"github.com/rs/zerolog/log"
+)
+
+// htmlChunkSize is how many UTF-16 code units of page HTML are pulled per CDP
+// call. Large pages are transferred in pieces so the Go side never holds the
+// whole CDP message and the decoded string at the same time.
+const htmlChunkSize = 1 << 20
+
+// htmlHolderJS captures the serialized document once; chunks are then read
+// from the returned object so the page is not re-serialized per chunk.
+const htmlHolderJS = `() => ({ html: document.documentElement ? document.documentElement.outerHTML : '' })`
+
+// htmlChunkJS returns the next chunk from the holder object. A chunk never
+// ends between the halves of a surrogate pair, which would otherwise be
+// replaced with U+FFFD when decoded.
+const htmlChunkJS = `function(start, size) {
+	const h = this.html;
+	let end = Math.min(start + size, h.length);
+	if (end < h.length) {
+		const c = h.charCodeAt(end - 1);
+		if (c >= 0xD800 && c <= 0xDBFF) end--;
+	}
+	return { chunk: h.substring(start, end), next: end, total: h.length };
+}`
+
+// extractHTML reads the page HTML in chunks, stopping once limit bytes have
+// been collected. truncated reports whether the page was larger than limit.
+// Peak Go-side memory is roughly the returned string plus one chunk, versus
+// about twice the page size for page.HTML().
+func extractHTML(page *rod.Page, limit int) (html string, truncated bool, err error) {
+	holder, err := page.Evaluate(rod.Eval(htmlHolderJS).ByObject())
+	if err != nil {
+		return "", false, fmt.Errorf("failed to capture page HTML: %w", err)
+	}
+	defer func() {
+		if releaseErr := page.Release(holder); releaseErr != nil {
+			log.Debug().Err(releaseErr).Msg("Failed to release HTML holder object")
+		}
+	}()
+
+	var sb strings.Builder
+	start, total := 0, -1
+	for total < 0 || start < total {
+		res, err := page.Evaluate(rod.Eval(htmlChunkJS, start, htmlChunkSize).This(holder))
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read page HTML at offset %d: %w", start, err)
+		}
+		next := res.Value.Get("next").Int()
+		if total < 0 {
+			total = res.Value.Get("total").Int()
+			// UTF-16 length is a lower bound on the UTF-8 size for most pages
+			sb.Grow(min(total, limit))
+		}
+		if next <= start && start < total {
+			return "", false, fmt.Errorf("page HTML extraction made no progress at offset %d", start)
+		}
+		sb.WriteString(res.Value.Get("chunk").Str())
+		start = next
+
+		if sb.Len() > limit {
+			log.Warn().
+				Int("length", total).
+				Int("max", limit).
+				Msg("Response truncated due to size limit")
+			return truncateUTF8(sb.String(), limit), true, nil
+		}
+	}
+	return sb.String(), false, nil
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a UTF-8 sequence.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	// Back up to the start of the rune straddling the cut (at most UTFMax-1 bytes)
+	for i := 0; i < utf8.UTFMax-1 && n > 0 && !utf8.RuneStart(s[n]); i++ {
+		n--
+	}
+	return s[:n]
+}
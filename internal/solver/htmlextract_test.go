@@ -0,0 +1,24 @@
+package solver
+
+import "testing"
+
+func TestTruncateUTF8(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"héllo", 2, "h"}, // é is two bytes; don't split it
+		{"héllo", 3, "hé"},
+		{"a🎉", 4, "a"},
+		{"a🎉", 5, "a🎉"},
+		{"", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := truncateUTF8(tt.in, tt.n); got != tt.want {
+			t.Errorf("truncateUTF8(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package solver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/rs/zerolog/log"
+)
+
+// incapsulaReloadAfter is how many passes the interstitial may linger with a
+// session cookie already set before the page is reloaded by hand.
+const incapsulaReloadAfter = 3
+
+// isIncapsulaPage reports whether lowercased html is an Imperva Incapsula
+// interstitial. Protected pages load /_Incapsula_Resource scripts on every
+// visit, so only the interstitial's own markers count.
+func isIncapsulaPage(htmlLower string, patterns []string) bool {
+	return containsAny(htmlLower, patterns)
+}
+
+// incapsulaSessionCookies returns the names of the Incapsula session cookies
+// (incap_ses_*) set on the page. visid_incap_* is issued before the challenge
+// runs, so only incap_ses_* shows it has passed.
+func incapsulaSessionCookies(page *rod.Page) []string {
+	cookies, err := page.Cookies(nil)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, cookie := range cookies {
+		if strings.HasPrefix(cookie.Name, "incap_ses_") && cookie.Value != "" {
+			names = append(names, cookie.Name)
+		}
+	}
+	return names
+}
+
+// stepIncapsula runs one solve loop pass on an Incapsula interstitial. The
+// challenge script sets the incap_ses_* cookies and reloads by itself, so
+// the loop waits, and reloads once the cookies outlive the interstitial.
+func stepIncapsula(ctx context.Context, p *ChallengePass) error {
+	sessions := incapsulaSessionCookies(p.Page)
+	if len(sessions) == 0 {
+		log.Ctx(ctx).Debug().Msg("Incapsula challenge running, waiting for session cookie")
+		return nil
+	}
+
+	p.Counters["incapsula_token_passes"]++
+	if p.Counters["incapsula_token_passes"] < incapsulaReloadAfter {
+		return nil
+	}
+	p.Counters["incapsula_token_passes"] = 0
+	log.Ctx(ctx).Info().Strs("cookies", sessions).Msg("Incapsula session cookie set but challenge page persists, reloading")
+	if err := p.Page.Context(ctx).Reload(); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to reload after Incapsula challenge")
+	}
+	return nil
+}
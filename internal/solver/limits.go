@@ -0,0 +1,106 @@
+package solver
+
+import (
+	"context"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// Default limits on what one solve returns, used where the server sets none.
+const (
+	defaultMaxResponseSize   = 10 * 1024 * 1024 // 10MB of HTML
+	defaultMaxScreenshotSize = 5 * 1024 * 1024  // 5MB screenshot
+	defaultMaxCookies        = 100
+	defaultMaxStorageItems   = 100
+	defaultMaxStorageSize    = 1 * 1024 * 1024 // 1MB of localStorage/sessionStorage
+)
+
+// ResponseLimits bound what one solve returns, so a huge page cannot exhaust
+// memory. Sizes are in bytes.
+type ResponseLimits struct {
+	MaxResponseSize   int // page HTML; longer pages are truncated and flagged
+	MaxScreenshotSize int // screenshots larger than this are dropped
+	MaxCookies        int // cookies returned
+	MaxStorageItems   int // localStorage and sessionStorage items returned, each
+	MaxStorageSize    int // serialized localStorage and sessionStorage, each; larger is dropped
+}
+
+// DefaultResponseLimits returns the built-in limits.
+func DefaultResponseLimits() ResponseLimits {
+	return ResponseLimits{
+		MaxResponseSize:   defaultMaxResponseSize,
+		MaxScreenshotSize: defaultMaxScreenshotSize,
+		MaxCookies:        defaultMaxCookies,
+		MaxStorageItems:   defaultMaxStorageItems,
+		MaxStorageSize:    defaultMaxStorageSize,
+	}
+}
+
+// withDefaults fills unset limits with the built-in ones.
+func (l ResponseLimits) withDefaults() ResponseLimits {
+	d := DefaultResponseLimits()
+	if l.MaxResponseSize <= 0 {
+		l.MaxResponseSize = d.MaxResponseSize
+	}
+	if l.MaxScreenshotSize <= 0 {
+		l.MaxScreenshotSize = d.MaxScreenshotSize
+	}
+	if l.MaxCookies <= 0 {
+		l.MaxCookies = d.MaxCookies
+	}
+	if l.MaxStorageItems <= 0 {
+		l.MaxStorageItems = d.MaxStorageItems
+	}
+	if l.MaxStorageSize <= 0 {
+		l.MaxStorageSize = d.MaxStorageSize
+	}
+	return l
+}
+
+// withOptions applies a request's overrides, already validated against the
+// hard ceilings.
+func (l ResponseLimits) withOptions(o *types.ResponseLimits) ResponseLimits {
+	if o == nil {
+		return l
+	}
+	if o.MaxResponseSize > 0 {
+		l.MaxResponseSize = o.MaxResponseSize
+	}
+	if o.MaxScreenshotSize > 0 {
+		l.MaxScreenshotSize = o.MaxScreenshotSize
+	}
+	if o.MaxCookies > 0 {
+		l.MaxCookies = o.MaxCookies
+	}
+	if o.MaxStorageItems > 0 {
+		l.MaxStorageItems = o.MaxStorageItems
+	}
+	if o.MaxStorageSize > 0 {
+		l.MaxStorageSize = o.MaxStorageSize
+	}
+	return l
+}
+
+// SetResponseLimits sets the server's limits. Unset fields keep the
+// defaults.
+func (s *Solver) SetResponseLimits(l ResponseLimits) {
+	s.responseLimits = l
+}
+
+// responseLimitsKey carries a solve's resolved ResponseLimits on its context,
+// down to where the result is built.
+type responseLimitsKey struct{}
+
+// withResponseLimits returns ctx carrying the limits for a solve: the
+// server's, with the request's overrides applied.
+func (s *Solver) withResponseLimits(ctx context.Context, opts *SolveOptions) context.Context {
+	return context.WithValue(ctx, responseLimitsKey{}, s.responseLimits.withDefaults().withOptions(opts.Limits))
+}
+
+// responseLimitsFrom returns the limits on ctx, or the defaults.
+func responseLimitsFrom(ctx context.Context) ResponseLimits {
+	if l, ok := ctx.Value(responseLimitsKey{}).(ResponseLimits); ok {
+		return l
+	}
+	return DefaultResponseLimits()
+}
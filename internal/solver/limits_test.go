@@ -0,0 +1,32 @@
+package solver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func TestResponseLimits(t *testing.T) {
+	if got := responseLimitsFrom(context.Background()); got != DefaultResponseLimits() {
+		t.Errorf("limits without a solve = %+v, want defaults", got)
+	}
+
+	s := &Solver{}
+	if got := responseLimitsFrom(s.withResponseLimits(context.Background(), &SolveOptions{})); got != DefaultResponseLimits() {
+		t.Errorf("unset limits = %+v, want defaults", got)
+	}
+
+	s.SetResponseLimits(ResponseLimits{MaxResponseSize: 20 << 20, MaxCookies: 300})
+	got := responseLimitsFrom(s.withResponseLimits(context.Background(), &SolveOptions{}))
+	if got.MaxResponseSize != 20<<20 || got.MaxCookies != 300 || got.MaxScreenshotSize != defaultMaxScreenshotSize {
+		t.Errorf("server limits = %+v", got)
+	}
+
+	// Request overrides win field by field, lower or higher
+	opts := &SolveOptions{Limits: &types.ResponseLimits{MaxResponseSize: 1024, MaxStorageItems: 5000}}
+	got = responseLimitsFrom(s.withResponseLimits(context.Background(), opts))
+	if got.MaxResponseSize != 1024 || got.MaxStorageItems != 5000 || got.MaxCookies != 300 || got.MaxStorageSize != defaultMaxStorageSize {
+		t.Errorf("request limits = %+v", got)
+	}
+}
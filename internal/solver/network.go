@@ -9,6 +9,8 @@ import (
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
 )
 
 // Maximum number of headers to capture per response to prevent memory exhaustion
@@ -22,6 +24,19 @@ type NetworkCapture struct {
 	statusCode int
 	headers    map[string]string
 	url        string
+
+	// Last XHR/fetch response that carried a Cloudflare challenge
+	challengedXHR   string
+	challengedXHRAt time.Time
+
+	// XHR/fetch responses recorded for captureRequests
+	capturePatterns []string
+	pendingCaptures map[proto.NetworkRequestID]types.CapturedRequest // awaiting loadingFinished
+	captured        []types.CapturedRequest
+	capturedSize    int
+
+	// All traffic, for returnHar (nil when off)
+	har *harRecorder
 }
 
 // newNetworkCapture creates a new NetworkCapture instance.
@@ -77,6 +92,28 @@ func (nc *NetworkCapture) URL() string {
 	return nc.url
 }
 
+// recordChallengedXHR notes an XHR/fetch response that was challenged.
+func (nc *NetworkCapture) recordChallengedXHR(url string) {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	nc.challengedXHR = url
+	nc.challengedXHRAt = time.Now()
+}
+
+// ChallengedXHRSince returns the URL of the last challenged XHR/fetch response
+// seen at or after since, or "". Safe to call on a nil capture.
+func (nc *NetworkCapture) ChallengedXHRSince(since time.Time) string {
+	if nc == nil {
+		return ""
+	}
+	nc.mu.RLock()
+	defer nc.mu.RUnlock()
+	if nc.challengedXHR == "" || nc.challengedXHRAt.Before(since) {
+		return ""
+	}
+	return nc.challengedXHR
+}
+
 // setupNetworkCapture enables the Network domain and sets up event listeners
 // to capture HTTP response data from the main document.
 //
@@ -93,7 +130,7 @@ func setupNetworkCapture(ctx context.Context, page *rod.Page) (*NetworkCapture,
 	// Enable Network domain to receive network events
 	err := proto.NetworkEnable{}.Call(page)
 	if err != nil {
-		log.Debug().Err(err).Msg("Failed to enable Network domain for response capture")
+		log.Ctx(ctx).Debug().Err(err).Msg("Failed to enable Network domain for response capture")
 		// Return capture with defaults - graceful degradation
 		return capture, func() {}, nil
 	}
@@ -118,14 +155,14 @@ func setupNetworkCapture(ctx context.Context, page *rod.Page) (*NetworkCapture,
 			}()
 			select {
 			case <-done:
-				log.Debug().Msg("Network capture listeners cleaned up")
+				log.Ctx(ctx).Debug().Msg("Network capture listeners cleaned up")
 			case <-time.After(5 * time.Second):
-				log.Warn().Msg("Timeout waiting for network capture listeners to cleanup")
+				log.Ctx(ctx).Warn().Msg("Timeout waiting for network capture listeners to cleanup")
 			}
 			// Disable Network domain to stop receiving events
 			// This prevents resource leak from lingering event subscriptions
 			if err := (proto.NetworkDisable{}).Call(page); err != nil {
-				log.Debug().Err(err).Msg("Failed to disable Network domain during cleanup")
+				log.Ctx(ctx).Debug().Err(err).Msg("Failed to disable Network domain during cleanup")
 			}
 		})
 	}
@@ -142,7 +179,7 @@ func setupNetworkCapture(ctx context.Context, page *rod.Page) (*NetworkCapture,
 		// Add panic recovery to prevent goroutine panic from crashing the process
 		defer func() {
 			if r := recover(); r != nil {
-				log.Error().Interface("panic", r).Msg("Recovered from panic in network capture listener")
+				log.Ctx(ctx).Error().Interface("panic", r).Msg("Recovered from panic in network capture listener")
 			}
 		}()
 
@@ -161,6 +198,20 @@ func setupNetworkCapture(ctx context.Context, page *rod.Page) (*NetworkCapture,
 			default:
 			}
 
+			if har := capture.activeHAR(); har != nil {
+				har.responseReceived(e)
+			}
+
+			// XHR/fetch responses are only checked for challenge markers and
+			// noted for captureRequests
+			if e.Type == proto.NetworkResourceTypeXHR || e.Type == proto.NetworkResourceTypeFetch {
+				if e.Response != nil && isChallengedXHR(e.Response) {
+					capture.recordChallengedXHR(e.Response.URL)
+				}
+				capture.noteCaptureResponse(e)
+				return false
+			}
+
 			// Only capture Document responses (main page, not subresources)
 			if e.Type != proto.NetworkResourceTypeDocument {
 				return false // Continue listening
@@ -173,7 +224,7 @@ func setupNetworkCapture(ctx context.Context, page *rod.Page) (*NetworkCapture,
 				for key, value := range e.Response.Headers {
 					// Enforce header count limit to prevent memory exhaustion
 					if headerCount >= maxNetworkCaptureHeaders {
-						log.Debug().
+						log.Ctx(ctx).Debug().
 							Int("captured", headerCount).
 							Int("max", maxNetworkCaptureHeaders).
 							Msg("Network capture header limit reached, truncating")
@@ -188,7 +239,7 @@ func setupNetworkCapture(ctx context.Context, page *rod.Page) (*NetworkCapture,
 				statusCode := e.Response.Status
 				url := e.Response.URL
 
-				log.Debug().
+				log.Ctx(ctx).Debug().
 					Int("status_code", statusCode).
 					Str("url", url).
 					Int("header_count", len(headers)).
@@ -198,6 +249,27 @@ func setupNetworkCapture(ctx context.Context, page *rod.Page) (*NetworkCapture,
 			}
 
 			return false // Continue listening (handle redirects)
+		}, func(e *proto.NetworkLoadingFinished) bool {
+			select {
+			case <-listenerCtx.Done():
+				return true
+			default:
+			}
+			if har := capture.activeHAR(); har != nil {
+				har.loadingFinished(e)
+			}
+			capture.finishCapture(pageWithCtx, e)
+			return false
+		}, func(e *proto.NetworkRequestWillBeSent) bool {
+			if har := capture.activeHAR(); har != nil {
+				har.requestWillBeSent(e)
+			}
+			return listenerCtx.Err() != nil
+		}, func(e *proto.NetworkLoadingFailed) bool {
+			if har := capture.activeHAR(); har != nil {
+				har.loadingFailed(e)
+			}
+			return listenerCtx.Err() != nil
 		})
 
 		// Start listening - this blocks until context is canceled or handler returns true
@@ -216,11 +288,11 @@ func setupNetworkCapture(ctx context.Context, page *rod.Page) (*NetworkCapture,
 	select {
 	case <-initTimer.C:
 		// Subscription should be active by now
-		log.Debug().Msg("Network capture subscription initialized")
+		log.Ctx(ctx).Debug().Msg("Network capture subscription initialized")
 	case <-ctx.Done():
 		return capture, cleanupFunc, ctx.Err()
 	}
 
-	log.Debug().Msg("Network capture enabled")
+	log.Ctx(ctx).Debug().Msg("Network capture enabled")
 	return capture, cleanupFunc, nil
 }
@@ -0,0 +1,83 @@
+package solver
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// pdfTimeout bounds rendering and reading a returnPdf document.
+const pdfTimeout = 60 * time.Second
+
+// maxPDFSize caps a returnPdf document before base64 encoding.
+const maxPDFSize = 20 * 1024 * 1024
+
+// applyPdf renders the final page to PDF for result.Pdf. Like screenshots, a
+// failed rendering is logged and leaves the field empty rather than failing
+// the solve.
+func (s *Solver) applyPdf(ctx context.Context, page *rod.Page, opts *SolveOptions, result *Result) {
+	if !opts.ReturnPdf || opts.Method != "" || opts.Download {
+		return
+	}
+
+	data, err := renderPDF(page.Context(ctx).Timeout(pdfTimeout), opts.PdfOptions)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to render PDF")
+		return
+	}
+	result.Pdf = base64.StdEncoding.EncodeToString(data)
+	log.Ctx(ctx).Debug().Int("size", len(data)).Msg("PDF rendered")
+}
+
+// renderPDF prints the page with the requested paper settings.
+func renderPDF(page *rod.Page, opts *types.PdfOptions) ([]byte, error) {
+	stream, err := page.PDF(pdfRequest(opts))
+	if err != nil {
+		return nil, fmt.Errorf("PDF rendering failed: %w", err)
+	}
+	defer func() { _ = stream.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(stream, maxPDFSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	if len(data) > maxPDFSize {
+		return nil, fmt.Errorf("PDF exceeds maximum limit of %d bytes", maxPDFSize)
+	}
+	return data, nil
+}
+
+// pdfRequest translates pdfOptions into a Page.printToPDF call. Paper sizes
+// are passed portrait; Chrome rotates them for landscape.
+func pdfRequest(opts *types.PdfOptions) *proto.PagePrintToPDF {
+	req := &proto.PagePrintToPDF{}
+	if opts == nil {
+		return req
+	}
+	req.Landscape = opts.Landscape
+	req.PrintBackground = opts.PrintBackground
+
+	width, height := opts.Width, opts.Height
+	if width == 0 && opts.Format != "" {
+		size := types.PdfPaperSizes[strings.ToLower(opts.Format)]
+		width, height = size[0], size[1]
+	}
+	if width > 0 && height > 0 {
+		req.PaperWidth = &width
+		req.PaperHeight = &height
+	}
+	if opts.Scale != 0 {
+		scale := opts.Scale
+		req.Scale = &scale
+	}
+	return req
+}
@@ -0,0 +1,44 @@
+package solver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func TestPdfRequest(t *testing.T) {
+	if req := pdfRequest(nil); req.PaperWidth != nil || req.Scale != nil || req.Landscape {
+		t.Errorf("pdfRequest(nil) = %+v, want Chrome defaults", req)
+	}
+
+	req := pdfRequest(&types.PdfOptions{Format: "A4", Landscape: true, PrintBackground: true, Scale: 0.5})
+	if req.PaperWidth == nil || *req.PaperWidth != 8.27 || *req.PaperHeight != 11.69 {
+		t.Errorf("A4 paper = %v x %v", req.PaperWidth, req.PaperHeight)
+	}
+	if !req.Landscape || !req.PrintBackground || req.Scale == nil || *req.Scale != 0.5 {
+		t.Errorf("pdfRequest() = %+v", req)
+	}
+
+	// A custom size wins over the format
+	req = pdfRequest(&types.PdfOptions{Format: "letter", Width: 4, Height: 6})
+	if *req.PaperWidth != 4 || *req.PaperHeight != 6 {
+		t.Errorf("custom paper = %v x %v, want 4 x 6", *req.PaperWidth, *req.PaperHeight)
+	}
+}
+
+func TestApplyPdfSkipped(t *testing.T) {
+	s := &Solver{}
+	for _, opts := range []*SolveOptions{
+		{},
+		{ReturnPdf: true, Download: true},
+		{ReturnPdf: true, Method: "PUT"},
+	} {
+		result := &Result{}
+		// A nil page would panic if rendering were attempted
+		s.applyPdf(context.Background(), nil, opts, result)
+		if result.Pdf != "" {
+			t.Errorf("applyPdf(%+v) rendered a PDF", opts)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/humanize"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// Built-in poll strategy names.
+const (
+	PollStrategyRandom = "random" // 0.8-1.5s randomized polling (default)
+	PollStrategyFixed  = "fixed"  // constant 1s polling
+	PollStrategyEvent  = "event"  // wake on page load / cf_clearance events
+)
+
+const (
+	// defaultMaxPollAttempts applies when the solve context has no deadline.
+	defaultMaxPollAttempts = 300
+
+	// fixedPollInterval is the interval of the "fixed" strategy.
+	fixedPollInterval = time.Second
+
+	// eventPollMinGap and eventPollMaxWait bound the "event" strategy: passes
+	// never run closer than the gap, and a pass runs after the max wait even
+	// without events so Turnstile widgets still get interacted with.
+	eventPollMinGap  = 250 * time.Millisecond
+	eventPollMaxWait = 3 * time.Second
+)
+
+// PollLimits are the early-exit thresholds solveLoop applies. Attempt counts
+// are detection passes, starting at 0.
+type PollLimits struct {
+	// AccessDeniedAfter is the pass from which an access-denied page is final.
+	// Earlier passes give the JS interstitial time to redirect away.
+	AccessDeniedAfter int
+	// TurnstileEscalateAfter is the pass from which a page stuck on a JS
+	// challenge title is treated as hiding a Turnstile widget.
+	TurnstileEscalateAfter int
+	// EarlyBypassAfter is the number of native Turnstile attempts after which
+	// the loop bails out to the CDP disconnect/reconnect bypass. It is raised
+	// automatically when an external solver chain is configured.
+	EarlyBypassAfter int
+	// ClearanceCookieExit ends the loop as soon as a cf_clearance cookie is
+	// present, even if the challenge widget is still showing.
+	ClearanceCookieExit bool
+}
+
+// DefaultPollLimits returns the thresholds used by the built-in strategies.
+func DefaultPollLimits() PollLimits {
+	return PollLimits{
+		AccessDeniedAfter:      3,
+		TurnstileEscalateAfter: 5,
+		EarlyBypassAfter:       2,
+		ClearanceCookieExit:    true,
+	}
+}
+
+// PollSettings tune solveLoop's polling. SetPollSettings sets the server's;
+// a request's types.PollOptions override them field by field.
+type PollSettings struct {
+	// IntervalMin and IntervalMax bound the pause of the "random" strategy.
+	IntervalMin time.Duration
+	IntervalMax time.Duration
+	// MaxAttempts caps the detection passes of a solve; 0 sizes them to the
+	// solve timeout.
+	MaxAttempts int
+	// SelectorBudget is the time one pass may spend checking the challenge
+	// selectors, shared among them.
+	SelectorBudget time.Duration
+}
+
+// DefaultPollSettings returns the built-in polling settings.
+func DefaultPollSettings() PollSettings {
+	return PollSettings{
+		IntervalMin:    800 * time.Millisecond,
+		IntervalMax:    1500 * time.Millisecond,
+		SelectorBudget: 5 * time.Second,
+	}
+}
+
+// withDefaults fills unset fields from DefaultPollSettings.
+func (p PollSettings) withDefaults() PollSettings {
+	d := DefaultPollSettings()
+	if p.IntervalMin <= 0 {
+		p.IntervalMin = d.IntervalMin
+	}
+	if p.IntervalMax <= 0 {
+		p.IntervalMax = max(d.IntervalMax, p.IntervalMin)
+	}
+	if p.SelectorBudget <= 0 {
+		p.SelectorBudget = d.SelectorBudget
+	}
+	return p
+}
+
+// withOptions applies a request's overrides. An interval the request sets
+// moves the other bound with it if needed, keeping min <= max.
+func (p PollSettings) withOptions(o *types.PollOptions) PollSettings {
+	if o == nil {
+		return p
+	}
+	if o.IntervalMinMs > 0 {
+		p.IntervalMin = time.Duration(o.IntervalMinMs) * time.Millisecond
+		p.IntervalMax = max(p.IntervalMax, p.IntervalMin)
+	}
+	if o.IntervalMaxMs > 0 {
+		p.IntervalMax = time.Duration(o.IntervalMaxMs) * time.Millisecond
+		p.IntervalMin = min(p.IntervalMin, p.IntervalMax)
+	}
+	if o.MaxAttempts > 0 {
+		p.MaxAttempts = o.MaxAttempts
+	}
+	if o.SelectorBudgetMs > 0 {
+		p.SelectorBudget = time.Duration(o.SelectorBudgetMs) * time.Millisecond
+	}
+	return p
+}
+
+// strategy adapts a strategy to the settings: the "random" strategy polls
+// within the configured interval range.
+func (p PollSettings) strategy(strategy PollStrategy) PollStrategy {
+	if is, ok := strategy.(*IntervalStrategy); ok && is.StrategyName == PollStrategyRandom {
+		return newRandomPollStrategy(p.IntervalMin, p.IntervalMax, is.PollLimits)
+	}
+	return strategy
+}
+
+// newRandomPollStrategy returns the "random" strategy polling between
+// minInterval and maxInterval.
+func newRandomPollStrategy(minInterval, maxInterval time.Duration, limits PollLimits) *IntervalStrategy {
+	minMs, maxMs := int(minInterval.Milliseconds()), int(maxInterval.Milliseconds())
+	return &IntervalStrategy{
+		StrategyName: PollStrategyRandom,
+		Interval:     func() time.Duration { return humanize.RandomDuration(minMs, maxMs) },
+		AvgInterval:  (minInterval + maxInterval) / 2,
+		PollLimits:   limits,
+	}
+}
+
+// pollOptionsKey carries SolveOptions.Poll on the solve context, down to
+// solveLoop.
+type pollOptionsKey struct{}
+
+// withPollOptions returns ctx carrying a request's polling overrides.
+func withPollOptions(ctx context.Context, o *types.PollOptions) context.Context {
+	if o == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, pollOptionsKey{}, o)
+}
+
+// SetPollSettings sets the server's polling settings. Unset fields keep the
+// defaults.
+func (s *Solver) SetPollSettings(p PollSettings) {
+	s.pollSettings = p
+}
+
+// pollSettingsFor returns the polling settings for a solve: the server's,
+// with the overrides of the request on ctx applied.
+func (s *Solver) pollSettingsFor(ctx context.Context) PollSettings {
+	o, _ := ctx.Value(pollOptionsKey{}).(*types.PollOptions)
+	return s.pollSettings.withDefaults().withOptions(o)
+}
+
+// PollStrategy paces solveLoop's challenge detection passes.
+type PollStrategy interface {
+	// Name identifies the strategy in configuration and logs.
+	Name() string
+	// MaxAttempts bounds the number of detection passes that fit in the
+	// remaining solve time.
+	MaxAttempts(remaining time.Duration) int
+	// Limits returns the early-exit thresholds for the loop.
+	Limits() PollLimits
+	// Watch starts pacing a loop on page. wait blocks until the next pass is
+	// due and returns false once ctx is done; stop releases any resources.
+	Watch(ctx context.Context, page *rod.Page) (wait func() bool, stop func())
+}
+
+// IntervalStrategy polls after a delay drawn from Interval.
+type IntervalStrategy struct {
+	StrategyName string
+	Interval     func() time.Duration
+	// AvgInterval sizes MaxAttempts against the remaining time.
+	AvgInterval time.Duration
+	PollLimits  PollLimits
+}
+
+// Name implements PollStrategy.
+func (s *IntervalStrategy) Name() string { return s.StrategyName }
+
+// MaxAttempts implements PollStrategy.
+func (s *IntervalStrategy) MaxAttempts(remaining time.Duration) int {
+	return attemptsFor(remaining, s.AvgInterval)
+}
+
+// Limits implements PollStrategy.
+func (s *IntervalStrategy) Limits() PollLimits { return s.PollLimits }
+
+// Watch implements PollStrategy.
+func (s *IntervalStrategy) Watch(ctx context.Context, _ *rod.Page) (func() bool, func()) {
+	return func() bool { return sleepWithContext(ctx, s.Interval()) }, func() {}
+}
+
+// EventStrategy runs a detection pass when the page finishes loading a
+// document or receives a cf_clearance cookie, instead of polling blindly.
+// A pass still runs every MaxWait without events.
+type EventStrategy struct {
+	MinGap     time.Duration
+	MaxWait    time.Duration
+	PollLimits PollLimits
+}
+
+// Name implements PollStrategy.
+func (s *EventStrategy) Name() string { return PollStrategyEvent }
+
+// MaxAttempts implements PollStrategy. Passes can be as close as MinGap.
+func (s *EventStrategy) MaxAttempts(remaining time.Duration) int {
+	return attemptsFor(remaining, s.MinGap)
+}
+
+// Limits implements PollStrategy.
+func (s *EventStrategy) Limits() PollLimits { return s.PollLimits }
+
+// Watch implements PollStrategy. Events arriving while a pass runs are kept,
+// so the next wait returns after MinGap.
+func (s *EventStrategy) Watch(ctx context.Context, page *rod.Page) (func() bool, func()) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	signal := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case signal <- struct{}{}:
+		default:
+		}
+	}
+
+	listen := page.Context(watchCtx).EachEvent(
+		func(*proto.PageLoadEventFired) bool {
+			notify()
+			return false
+		},
+		func(e *proto.NetworkResponseReceivedExtraInfo) bool {
+			for name, value := range e.Headers {
+				if strings.EqualFold(name, "set-cookie") && strings.Contains(value.Str(), cfClearanceCookie+"=") {
+					notify()
+					break
+				}
+			}
+			return false
+		},
+	)
+	go listen()
+
+	wait := func() bool {
+		if !sleepWithContext(ctx, s.MinGap) {
+			return false
+		}
+		timer := time.NewTimer(s.MaxWait - s.MinGap)
+		defer timer.Stop()
+		select {
+		case <-signal:
+			log.Ctx(ctx).Debug().Msg("Poll woken by page event")
+			return true
+		case <-timer.C:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return wait, cancel
+}
+
+// attemptsFor sizes a pass budget: remaining/interval + 1, at least 1.
+func attemptsFor(remaining, interval time.Duration) int {
+	if remaining <= 0 || interval <= 0 {
+		return defaultMaxPollAttempts
+	}
+	return max(int(remaining/interval)+1, 1)
+}
+
+// NewPollStrategy returns the built-in strategy with the given name and the
+// default limits.
+func NewPollStrategy(name string) (PollStrategy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", PollStrategyRandom:
+		return &IntervalStrategy{
+			StrategyName: PollStrategyRandom,
+			Interval:     humanize.RandomPollInterval,
+			AvgInterval:  1150 * time.Millisecond, // Average of 800-1500ms
+			PollLimits:   DefaultPollLimits(),
+		}, nil
+	case PollStrategyFixed:
+		return &IntervalStrategy{
+			StrategyName: PollStrategyFixed,
+			Interval:     func() time.Duration { return fixedPollInterval },
+			AvgInterval:  fixedPollInterval,
+			PollLimits:   DefaultPollLimits(),
+		}, nil
+	case PollStrategyEvent:
+		return &EventStrategy{
+			MinGap:     eventPollMinGap,
+			MaxWait:    eventPollMaxWait,
+			PollLimits: DefaultPollLimits(),
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown poll strategy %q (want %s, %s or %s)", name, PollStrategyRandom, PollStrategyFixed, PollStrategyEvent)
+}
+
+// ParsePollStrategyDomains parses a POLL_STRATEGY_DOMAINS value: comma- and/or
+// newline-separated "domain=strategy" entries. A domain's entry also applies
+// to its subdomains. Blank entries and #-comments are skipped.
+func ParsePollStrategyDomains(raw string) (map[string]string, error) {
+	entries := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	})
+	out := make(map[string]string, len(entries))
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" || strings.HasPrefix(e, "#") {
+			continue
+		}
+		domain, name, ok := strings.Cut(e, "=")
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if !ok || domain == "" || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid entry %q: want domain=strategy", e)
+		}
+		p, err := NewPollStrategy(name)
+		if err != nil {
+			return nil, fmt.Errorf("domain %s: %w", domain, err)
+		}
+		out[domain] = p.Name()
+	}
+	return out, nil
+}
+
+// PollStrategyPreferences is implemented by stats managers that store a
+// per-domain poll strategy (SolverPreferences.PollStrategy).
+type PollStrategyPreferences interface {
+	PollStrategyPreference(domain string) string
+}
+
+// SetPollStrategy sets the strategy used when a domain has no preference.
+func (s *Solver) SetPollStrategy(p PollStrategy) {
+	s.pollStrategy = p
+}
+
+// pollStrategyFor picks the strategy for a host: the preference stored for the
+// host or its nearest parent domain, else the solver default.
+func (s *Solver) pollStrategyFor(host string) PollStrategy {
+	if prefs, ok := s.statsManager.(PollStrategyPreferences); ok && host != "" {
+		for d := host; d != ""; {
+			if name := prefs.PollStrategyPreference(d); name != "" {
+				p, err := NewPollStrategy(name)
+				if err == nil {
+					return p
+				}
+				log.Warn().Err(err).Str("domain", d).Msg("Ignoring invalid per-domain poll strategy")
+				break
+			}
+			_, parent, found := strings.Cut(d, ".")
+			if !found || !strings.Contains(parent, ".") {
+				break
+			}
+			d = parent
+		}
+	}
+	if s.pollStrategy != nil {
+		return s.pollStrategy
+	}
+	p, _ := NewPollStrategy(PollStrategyRandom)
+	return p
+}
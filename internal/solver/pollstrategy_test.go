@@ -0,0 +1,152 @@
+package solver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func TestNewPollStrategy(t *testing.T) {
+	for _, name := range []string{"", "random", "Fixed", " event "} {
+		p, err := NewPollStrategy(name)
+		if err != nil {
+			t.Errorf("NewPollStrategy(%q) error = %v", name, err)
+			continue
+		}
+		if p.Limits() != DefaultPollLimits() {
+			t.Errorf("NewPollStrategy(%q) limits = %+v, want defaults", name, p.Limits())
+		}
+	}
+	if _, err := NewPollStrategy("busy-loop"); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
+
+func TestPollStrategyMaxAttempts(t *testing.T) {
+	random, _ := NewPollStrategy(PollStrategyRandom)
+	fixed, _ := NewPollStrategy(PollStrategyFixed)
+	event, _ := NewPollStrategy(PollStrategyEvent)
+
+	tests := []struct {
+		strategy  PollStrategy
+		remaining time.Duration
+		want      int
+	}{
+		{random, 60 * time.Second, 53},
+		{fixed, 60 * time.Second, 61},
+		{event, 60 * time.Second, 241},
+		{fixed, 0, defaultMaxPollAttempts},
+		{fixed, 100 * time.Millisecond, 1},
+	}
+	for _, tt := range tests {
+		if got := tt.strategy.MaxAttempts(tt.remaining); got != tt.want {
+			t.Errorf("%s.MaxAttempts(%v) = %d, want %d", tt.strategy.Name(), tt.remaining, got, tt.want)
+		}
+	}
+}
+
+func TestIntervalStrategyWait(t *testing.T) {
+	p := &IntervalStrategy{
+		StrategyName: "test",
+		Interval:     func() time.Duration { return time.Millisecond },
+		AvgInterval:  time.Millisecond,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	wait, stop := p.Watch(ctx, nil)
+	defer stop()
+
+	if !wait() {
+		t.Error("wait() = false before cancellation")
+	}
+	cancel()
+	p.Interval = func() time.Duration { return time.Hour }
+	if wait() {
+		t.Error("wait() = true after cancellation")
+	}
+}
+
+func TestParsePollStrategyDomains(t *testing.T) {
+	got, err := ParsePollStrategyDomains("Example.com=event,\n# slow site\nslow.org = fixed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["example.com"] != PollStrategyEvent || got["slow.org"] != PollStrategyFixed {
+		t.Errorf("ParsePollStrategyDomains() = %v", got)
+	}
+
+	for _, bad := range []string{"example.com", "=event", "example.com=", "example.com=turbo"} {
+		if _, err := ParsePollStrategyDomains(bad); err == nil {
+			t.Errorf("ParsePollStrategyDomains(%q) expected error", bad)
+		}
+	}
+}
+
+// pollPrefsStats is a StatsManager that also stores poll strategy preferences.
+type pollPrefsStats map[string]string
+
+func (pollPrefsStats) RecordTurnstileMethod(string, string, bool)    {}
+func (pollPrefsStats) GetTurnstileMethodOrder(string) []string       { return nil }
+func (p pollPrefsStats) PollStrategyPreference(domain string) string { return p[domain] }
+
+func TestPollStrategyFor(t *testing.T) {
+	s := &Solver{}
+	if got := s.pollStrategyFor("example.com").Name(); got != PollStrategyRandom {
+		t.Errorf("default strategy = %q, want random", got)
+	}
+
+	fixed, _ := NewPollStrategy(PollStrategyFixed)
+	s.SetPollStrategy(fixed)
+	s.SetStatsManager(pollPrefsStats{"example.com": PollStrategyEvent, "bad.org": "turbo"})
+
+	tests := map[string]string{
+		"example.com":       PollStrategyEvent,
+		"www.example.com":   PollStrategyEvent, // inherits from the parent domain
+		"a.b.example.com":   PollStrategyEvent,
+		"other.com":         PollStrategyFixed, // server default
+		"bad.org":           PollStrategyFixed, // invalid preference ignored
+		"":                  PollStrategyFixed,
+		"notexample.com":    PollStrategyFixed,
+		"example.com.other": PollStrategyFixed,
+	}
+	for host, want := range tests {
+		if got := s.pollStrategyFor(host).Name(); got != want {
+			t.Errorf("pollStrategyFor(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestPollSettings(t *testing.T) {
+	s := &Solver{}
+	if got := s.pollSettingsFor(context.Background()); got != DefaultPollSettings() {
+		t.Errorf("unset settings = %+v, want defaults", got)
+	}
+
+	s.SetPollSettings(PollSettings{IntervalMin: 2 * time.Second, MaxAttempts: 30})
+	got := s.pollSettingsFor(context.Background())
+	if got.IntervalMin != 2*time.Second || got.IntervalMax != 2*time.Second || got.MaxAttempts != 30 {
+		t.Errorf("server settings = %+v, want max raised to min", got)
+	}
+
+	// Request overrides win field by field and keep min <= max
+	ctx := withPollOptions(context.Background(), &types.PollOptions{IntervalMaxMs: 500, SelectorBudgetMs: 1000})
+	got = s.pollSettingsFor(ctx)
+	if got.IntervalMin != 500*time.Millisecond || got.IntervalMax != 500*time.Millisecond || got.SelectorBudget != time.Second || got.MaxAttempts != 30 {
+		t.Errorf("request settings = %+v", got)
+	}
+
+	// Only the random strategy takes the interval range
+	random, _ := NewPollStrategy(PollStrategyRandom)
+	adapted := got.strategy(random)
+	if d := adapted.(*IntervalStrategy).Interval(); d != 500*time.Millisecond {
+		t.Errorf("random interval = %v, want 500ms", d)
+	}
+	if adapted.MaxAttempts(10*time.Second) != 21 {
+		t.Errorf("random MaxAttempts = %d, want 21", adapted.MaxAttempts(10*time.Second))
+	}
+	fixed, _ := NewPollStrategy(PollStrategyFixed)
+	if got.strategy(fixed) != fixed {
+		t.Error("fixed strategy should be kept")
+	}
+}
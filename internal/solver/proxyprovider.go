@@ -0,0 +1,139 @@
+package solver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// Residential proxy gateways hand out a new exit IP per connection unless the
+// username carries a session parameter. Each provider spells that parameter
+// differently; StickyProxy adds it so every solve on a FlareSolverr session
+// keeps the exit IP its cf_clearance was issued to.
+
+// ProxyProvider names a residential proxy provider's username syntax.
+type ProxyProvider string
+
+const (
+	// ProviderBrightData: brd-customer-<id>-zone-<zone>-session-<token>
+	ProviderBrightData ProxyProvider = "brightdata"
+	// ProviderOxylabs: customer-<user>-sessid-<token>-sesstime-<minutes>
+	ProviderOxylabs ProxyProvider = "oxylabs"
+	// ProviderSmartproxy (Decodo): user-<user>-session-<token>-sessionduration-<minutes>
+	ProviderSmartproxy ProxyProvider = "smartproxy"
+	// ProviderNone leaves the username alone.
+	ProviderNone ProxyProvider = "none"
+)
+
+// maxStickyMinutes is the longest session Oxylabs and Smartproxy keep an IP.
+const maxStickyMinutes = 1440
+
+// DetectProxyProvider returns the provider whose gateway host proxyURL
+// points at, or "" for any other proxy.
+func DetectProxyProvider(proxyURL string) ProxyProvider {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	hasDomain := func(domain string) bool {
+		return host == domain || strings.HasSuffix(host, "."+domain)
+	}
+	switch {
+	case hasDomain("superproxy.io") || hasDomain("lum-superproxy.io"):
+		return ProviderBrightData
+	case hasDomain("pr.oxylabs.io") || strings.HasSuffix(host, "-pr.oxylabs.io"):
+		return ProviderOxylabs
+	case hasDomain("smartproxy.com") || hasDomain("decodo.com"):
+		return ProviderSmartproxy
+	}
+	return ""
+}
+
+// NewProxySessionToken returns a random session token valid in the username
+// syntax of every provider.
+func NewProxySessionToken() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// StickyProxy returns a copy of p whose username pins the exit IP to token
+// for lifetime, or p itself when the provider is unknown or the username
+// already names a session. provider "" means detect it from the host.
+// Credentials embedded in the URL are rewritten there.
+func StickyProxy(p *types.Proxy, provider ProxyProvider, token string, lifetime time.Duration) *types.Proxy {
+	if p == nil || p.URL == "" || token == "" {
+		return p
+	}
+	if provider == "" {
+		provider = DetectProxyProvider(p.URL)
+	}
+
+	username, inURL := p.Username, false
+	u, err := url.Parse(p.URL)
+	if username == "" && err == nil && u.User != nil {
+		username, inURL = u.User.Username(), true
+	}
+	sticky := stickyUsername(username, provider, token, lifetime)
+	if sticky == username {
+		return p
+	}
+
+	out := *p
+	if inURL {
+		if password, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(sticky, password)
+		} else {
+			u.User = url.User(sticky)
+		}
+		out.URL = u.String()
+	} else {
+		out.Username = sticky
+	}
+	return &out
+}
+
+// stickyUsername adds provider's session parameters to username.
+func stickyUsername(username string, provider ProxyProvider, token string, lifetime time.Duration) string {
+	if username == "" {
+		return username
+	}
+	minutes := int(lifetime / time.Minute)
+	if minutes < 1 {
+		minutes = 1
+	}
+	if minutes > maxStickyMinutes {
+		minutes = maxStickyMinutes
+	}
+
+	switch provider {
+	case ProviderBrightData:
+		if strings.Contains(username, "-session-") {
+			return username
+		}
+		return username + "-session-" + token
+	case ProviderOxylabs:
+		if strings.Contains(username, "-sessid-") {
+			return username
+		}
+		if !strings.HasPrefix(username, "customer-") {
+			username = "customer-" + username
+		}
+		return username + "-sessid-" + token + "-sesstime-" + strconv.Itoa(minutes)
+	case ProviderSmartproxy:
+		if strings.Contains(username, "-session-") {
+			return username
+		}
+		if !strings.HasPrefix(username, "user-") {
+			username = "user-" + username
+		}
+		return username + "-session-" + token + "-sessionduration-" + strconv.Itoa(minutes)
+	}
+	return username
+}
@@ -0,0 +1,130 @@
+package solver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// proxyRoutePoolPrefix marks a PROXY_ROUTES target naming a proxy pool.
+const proxyRoutePoolPrefix = "pool:"
+
+// ProxyRoute sends requests for a domain pattern through a proxy or a named
+// proxy pool.
+type ProxyRoute struct {
+	// Pattern is "example.com" for the domain and its subdomains,
+	// "*.example.com" for subdomains only, or "*" for every domain.
+	Pattern string
+	Proxy   *types.Proxy // URL and credentials, or Pool
+}
+
+// ProxyRoutes are the PROXY_ROUTES rules. A nil ProxyRoutes routes nothing.
+type ProxyRoutes []ProxyRoute
+
+// ParseProxyRoutes parses a PROXY_ROUTES value: comma- and/or
+// newline-separated "pattern=target" entries, where target is a proxy URL
+// (embedded user:pass@ ok) or "pool:<name>". Blank entries and #-comments
+// are skipped.
+func ParseProxyRoutes(raw string) (ProxyRoutes, error) {
+	entries := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	})
+	var routes ProxyRoutes
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" || strings.HasPrefix(e, "#") {
+			continue
+		}
+		pattern, target, ok := strings.Cut(e, "=")
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		target = strings.TrimSpace(target)
+		if !ok || pattern == "" || target == "" {
+			return nil, fmt.Errorf("invalid entry %q: want pattern=proxy", e)
+		}
+		if !validRoutePattern(pattern) {
+			return nil, fmt.Errorf("invalid pattern %q: want example.com, *.example.com or *", pattern)
+		}
+		if seen[pattern] {
+			return nil, fmt.Errorf("pattern %q routed twice", pattern)
+		}
+		seen[pattern] = true
+
+		var proxy *types.Proxy
+		if name, isPool := strings.CutPrefix(target, proxyRoutePoolPrefix); isPool {
+			proxy = &types.Proxy{Pool: strings.TrimSpace(name)}
+		} else if proxy = parseProxyEntry(target); proxy == nil {
+			return nil, fmt.Errorf("pattern %s: invalid proxy URL", pattern)
+		}
+		if err := proxy.Validate(); err != nil {
+			return nil, fmt.Errorf("pattern %s: %w", pattern, err)
+		}
+		routes = append(routes, ProxyRoute{Pattern: pattern, Proxy: proxy})
+	}
+	return routes, nil
+}
+
+// validRoutePattern accepts "*", a domain, or "*." followed by a domain.
+func validRoutePattern(pattern string) bool {
+	if pattern == "*" {
+		return true
+	}
+	domain := strings.TrimPrefix(pattern, "*.")
+	return domain != "" && !strings.ContainsAny(domain, "*/:= ") && !strings.HasPrefix(domain, ".") && !strings.HasSuffix(domain, ".")
+}
+
+// Match returns a copy of the proxy for host, or nil when no route applies.
+// The most specific pattern wins: an exact host match, else the longest
+// domain; ties go to the earlier entry.
+func (r ProxyRoutes) Match(host string) *types.Proxy {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" {
+		return nil
+	}
+	var best *ProxyRoute
+	bestScore := -1
+	for i := range r {
+		score := routeScore(r[i].Pattern, host)
+		if score > bestScore {
+			best, bestScore = &r[i], score
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	proxy := *best.Proxy
+	return &proxy
+}
+
+// routeScore ranks how specifically pattern matches host, or -1 when it does
+// not match.
+func routeScore(pattern, host string) int {
+	if pattern == "*" {
+		return 0
+	}
+	if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+		if strings.HasSuffix(host, "."+domain) {
+			return 2 * len(domain)
+		}
+		return -1
+	}
+	switch {
+	case host == pattern:
+		return 2*len(pattern) + 1
+	case strings.HasSuffix(host, "."+pattern):
+		return 2 * len(pattern)
+	}
+	return -1
+}
+
+// Pools returns the pool names the routes refer to.
+func (r ProxyRoutes) Pools() []string {
+	var names []string
+	for _, route := range r {
+		if route.Proxy.Pool != "" {
+			names = append(names, route.Proxy.Pool)
+		}
+	}
+	return names
+}
@@ -0,0 +1,145 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/captcha"
+)
+
+// raceMinNativeAttempts is how many native Turnstile passes a domain needs
+// before its success rate is trusted enough to start racing.
+const raceMinNativeAttempts = 5
+
+// SolveOutcomeRecorder is implemented by stats managers that keep per-domain
+// native and external solve outcomes.
+type SolveOutcomeRecorder interface {
+	RecordSolveOutcome(domain, method string, success bool, durationMs int64)
+}
+
+// NativeSolveHistory is implemented by stats managers that can report the
+// native solve outcomes recorded for a domain.
+type NativeSolveHistory interface {
+	NativeSolveHistory(domain string) (attempts, successes int64)
+}
+
+// recordSolveOutcome reports a native ("native") or external (the provider
+// name) solve outcome to the stats manager, if it keeps them.
+func (s *Solver) recordSolveOutcome(domain, method string, success bool, d time.Duration) {
+	if rec, ok := s.statsManager.(SolveOutcomeRecorder); ok && domain != "" {
+		rec.RecordSolveOutcome(domain, method, success, d.Milliseconds())
+	}
+}
+
+// shouldRaceExternal reports whether domain's native Turnstile success rate
+// is below the chain's race threshold, so the external solve should start
+// alongside the native methods rather than after them.
+func (s *Solver) shouldRaceExternal(domain string) bool {
+	if s.solverChain == nil || domain == "" {
+		return false
+	}
+	threshold := s.solverChain.RaceThreshold()
+	if threshold <= 0 {
+		return false
+	}
+	history, ok := s.statsManager.(NativeSolveHistory)
+	if !ok {
+		return false
+	}
+	attempts, successes := history.NativeSolveHistory(domain)
+	if attempts < raceMinNativeAttempts {
+		return false
+	}
+	return float64(successes)/float64(attempts) < threshold
+}
+
+// externalRace is an external Turnstile solve running in the background.
+// result and err are set once done is closed.
+type externalRace struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+	result *captcha.SolveResult
+	err    error
+}
+
+// startExternalRace submits the Turnstile widget on page to the external
+// providers in the background. It returns nil when the widget can't be
+// described yet, leaving the native methods on their own.
+func (s *Solver) startExternalRace(ctx context.Context, page *rod.Page, pageURL string) *externalRace {
+	req, err := captcha.TurnstileRequestFromPage(ctx, page, pageURL, s.userAgent, solveProxyFrom(ctx))
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("Cannot race external solver, solving natively")
+		return nil
+	}
+
+	raceCtx, cancel := context.WithCancel(ctx)
+	race := &externalRace{done: make(chan struct{}), cancel: cancel}
+	go func() {
+		defer close(race.done)
+		race.result, race.err = s.solverChain.SolveTurnstileToken(raceCtx, req)
+	}()
+	return race
+}
+
+// raceTurnstile runs the native Turnstile methods while an external solve of
+// the same widget is in flight. Whichever produces a token first wins: a
+// native solve abandons the external task, and an external token stops the
+// native methods and is injected. When the native methods give up first,
+// the external solve gets the time that is left.
+func (s *Solver) raceTurnstile(ctx context.Context, page *rod.Page, pageURL string, tabsTillVerify int) error {
+	race := s.startExternalRace(ctx, page, pageURL)
+	if race == nil {
+		return s.solveTurnstile(ctx, page, tabsTillVerify)
+	}
+	defer race.cancel()
+
+	log.Ctx(ctx).Info().Msg("Racing external solver against native Turnstile solving")
+
+	nativeCtx, stopNative := context.WithCancel(ctx)
+	defer stopNative()
+	go func() {
+		select {
+		case <-race.done:
+			if race.err == nil {
+				stopNative()
+			}
+		case <-nativeCtx.Done():
+		}
+	}()
+
+	if err := s.solveTurnstile(nativeCtx, page, tabsTillVerify); err != nil && ctx.Err() != nil {
+		return err
+	}
+	if s.isTurnstileSolved(page) {
+		log.Ctx(ctx).Info().Msg("Native solving won the race, abandoning external solve")
+		return nil
+	}
+
+	select {
+	case <-race.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if race.err != nil {
+		return fmt.Errorf("external solver failed: %w", race.err)
+	}
+	s.recordSolveOutcome(extractDomainFromURL(pageURL), race.result.Provider, true, race.result.SolveTime)
+
+	injected := captcha.InjectSolvedTurnstile(ctx, page, race.result.Token)
+	log.Ctx(ctx).Info().
+		Str("provider", race.result.Provider).
+		Dur("solve_time", race.result.SolveTime).
+		Float64("cost", race.result.Cost).
+		Bool("injected", injected).
+		Msg("External solver won the race")
+	if injected {
+		if err := captcha.VerifyInjectedToken(ctx, page, captcha.TokenTurnstile, race.result.Token, tokenVerifyTimeout); err != nil {
+			return fmt.Errorf("external Turnstile token not accepted: %w", err)
+		}
+	}
+	return nil
+}
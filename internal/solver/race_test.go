@@ -0,0 +1,45 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/captcha"
+	"github.com/Rorqualx/flaresolverr-go/internal/stats"
+)
+
+func TestShouldRaceExternal(t *testing.T) {
+	m := stats.NewManager()
+	defer m.Close()
+	for i := 0; i < 10; i++ {
+		m.RecordSolveOutcome("hard.example", "native", i == 0, 1000)
+		m.RecordSolveOutcome("easy.example", "native", i > 0, 1000)
+	}
+	for i := 0; i < raceMinNativeAttempts-1; i++ {
+		m.RecordSolveOutcome("new.example", "native", false, 1000)
+	}
+
+	s := New(nil, "TestAgent/1.0")
+	s.SetStatsManager(m)
+	if s.shouldRaceExternal("hard.example") {
+		t.Error("raced without a solver chain")
+	}
+
+	s.SetSolverChain(captcha.NewSolverChain(captcha.SolverChainConfig{FallbackEnabled: true, RaceThreshold: 0.3}))
+	tests := map[string]bool{
+		"hard.example":  true,  // 10% native success
+		"easy.example":  false, // 90% native success
+		"new.example":   false, // too few attempts to judge
+		"other.example": false,
+		"":              false,
+	}
+	for domain, want := range tests {
+		if got := s.shouldRaceExternal(domain); got != want {
+			t.Errorf("shouldRaceExternal(%q) = %v, want %v", domain, got, want)
+		}
+	}
+
+	s.SetSolverChain(captcha.NewSolverChain(captcha.SolverChainConfig{FallbackEnabled: true}))
+	if s.shouldRaceExternal("hard.example") {
+		t.Error("raced with racing disabled")
+	}
+}
@@ -0,0 +1,222 @@
+package solver
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rs/zerolog/log"
+)
+
+// Screencast limits for recordSolve. Chrome only sends a frame when the page
+// changes, so a long solve over a static interstitial stays small.
+const (
+	recordingFrameWidth  = 960
+	recordingFrameHeight = 540
+	recordingJPEGQuality = 60
+	maxRecordingFrames   = 300
+	maxRecordingSize     = 20 * 1024 * 1024
+)
+
+// recordingFrame is one screencast JPEG and when Chrome painted it.
+type recordingFrame struct {
+	data []byte
+	at   float64 // seconds since the epoch
+}
+
+// solveRecorder collects Page.startScreencast frames for the solve.
+type solveRecorder struct {
+	page   *rod.Page
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu      sync.Mutex
+	frames  []recordingFrame
+	size    int
+	dropped int
+}
+
+// SetRecordingDir makes recordSolve write recordings to dir instead of
+// returning them, which also keeps the recordings of failed solves.
+func (s *Solver) SetRecordingDir(dir string) {
+	s.recordingDir = dir
+}
+
+// startRecording starts screencasting the page when the request asked for
+// it. Returns nil when recording is off or Chrome refuses the screencast.
+func (s *Solver) startRecording(ctx context.Context, page *rod.Page, opts *SolveOptions) *solveRecorder {
+	if !opts.RecordSolve {
+		return nil
+	}
+
+	listenCtx, cancel := context.WithCancel(ctx)
+	rec := &solveRecorder{page: page, cancel: cancel, done: make(chan struct{})}
+	listenPage := page.Context(listenCtx)
+	wait := listenPage.EachEvent(func(e *proto.PageScreencastFrame) {
+		rec.add(e)
+		// Chrome sends the next frame only after this one is acknowledged
+		_ = proto.PageScreencastFrameAck{SessionID: e.SessionID}.Call(listenPage)
+	})
+	go func() {
+		defer close(rec.done)
+		wait()
+	}()
+
+	width, height := recordingFrameWidth, recordingFrameHeight
+	quality := recordingJPEGQuality
+	err := proto.PageStartScreencast{
+		Format:    proto.PageStartScreencastFormatJpeg,
+		Quality:   &quality,
+		MaxWidth:  &width,
+		MaxHeight: &height,
+	}.Call(page)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to start solve recording")
+		cancel()
+		<-rec.done
+		return nil
+	}
+	log.Ctx(ctx).Debug().Str("url", opts.URL).Msg("Recording solve")
+	return rec
+}
+
+// add keeps a frame until the frame or size limit is reached.
+func (r *solveRecorder) add(e *proto.PageScreencastFrame) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.frames) >= maxRecordingFrames || r.size+len(e.Data) > maxRecordingSize {
+		r.dropped++
+		return
+	}
+	at := float64(time.Now().UnixNano()) / 1e9
+	if e.Metadata != nil && e.Metadata.Timestamp != 0 {
+		at = float64(e.Metadata.Timestamp)
+	}
+	r.frames = append(r.frames, recordingFrame{data: e.Data, at: at})
+	r.size += len(e.Data)
+}
+
+// stop ends the screencast and returns the frames collected.
+func (r *solveRecorder) stop() []recordingFrame {
+	if err := (proto.PageStopScreencast{}).Call(r.page); err != nil {
+		log.Debug().Err(err).Msg("Failed to stop solve recording")
+	}
+	r.cancel()
+	<-r.done
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.dropped > 0 {
+		log.Debug().Int("dropped", r.dropped).Msg("Solve recording hit its frame limit")
+	}
+	return r.frames
+}
+
+// finishRecording stops the recorder and hands the animation to the result,
+// or writes it to the recording directory when one is configured. Without
+// a directory the recording of a failed solve is discarded.
+func (s *Solver) finishRecording(rec *solveRecorder, opts *SolveOptions, result *Result, solveErr error) {
+	if rec == nil {
+		return
+	}
+	frames := rec.stop()
+	if len(frames) == 0 {
+		log.Warn().Msg("Solve recording captured no frames")
+		return
+	}
+	data, err := encodeRecording(frames)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to encode solve recording")
+		return
+	}
+
+	if s.recordingDir == "" {
+		if result == nil {
+			log.Info().Msg("Discarding recording of failed solve; set RECORD_SOLVE_DIR to keep it")
+			return
+		}
+		result.Recording = base64.StdEncoding.EncodeToString(data)
+		return
+	}
+
+	path := filepath.Join(s.recordingDir, recordingFileName(opts.URL, solveErr == nil, time.Now()))
+	if err := os.MkdirAll(s.recordingDir, 0o750); err != nil {
+		log.Warn().Err(err).Msg("Failed to create recording directory")
+		return
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		log.Warn().Err(err).Msg("Failed to write solve recording")
+		return
+	}
+	log.Info().Str("path", path).Int("frames", len(frames)).Bool("success", solveErr == nil).Msg("Solve recording saved")
+	if result != nil {
+		result.RecordingPath = path
+	}
+}
+
+// encodeRecording assembles the frames into an animated GIF, each shown
+// until the next one was painted.
+func encodeRecording(frames []recordingFrame) ([]byte, error) {
+	anim := &gif.GIF{}
+	for i, f := range frames {
+		img, err := jpeg.Decode(bytes.NewReader(f.data))
+		if err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i, err)
+		}
+		b := img.Bounds()
+		paletted := image.NewPaletted(b, palette.Plan9)
+		draw.Draw(paletted, b, img, b.Min, draw.Src)
+		anim.Image = append(anim.Image, paletted)
+
+		delay := 100 // hold the last frame for a second
+		if i+1 < len(frames) {
+			// GIF delays are in hundredths; viewers clamp anything below 2
+			delay = max(int((frames[i+1].at-f.at)*100), 2)
+		}
+		anim.Delay = append(anim.Delay, delay)
+		anim.Config.Width = max(anim.Config.Width, b.Dx())
+		anim.Config.Height = max(anim.Config.Height, b.Dy())
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return nil, err
+	}
+	if buf.Len() > maxRecordingSize {
+		return nil, fmt.Errorf("recording size %d exceeds maximum limit of %d bytes", buf.Len(), maxRecordingSize)
+	}
+	return buf.Bytes(), nil
+}
+
+// recordingFileName names a recording after the target host, the outcome
+// and the time, e.g. 20260102-150405.000-example.com-failed.gif.
+func recordingFileName(rawURL string, success bool, at time.Time) string {
+	host := "unknown"
+	if u, err := url.Parse(rawURL); err == nil && u.Hostname() != "" {
+		host = strings.Map(func(r rune) rune {
+			if r == '.' || r == '-' || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+				return r
+			}
+			return '_'
+		}, strings.ToLower(u.Hostname()))
+	}
+	outcome := "ok"
+	if !success {
+		outcome = "failed"
+	}
+	return fmt.Sprintf("%s-%s-%s.gif", at.Format("20060102-150405.000"), host, outcome)
+}
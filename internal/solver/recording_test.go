@@ -0,0 +1,78 @@
+package solver
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"testing"
+	"time"
+)
+
+func testJPEG(t *testing.T, c color.Color) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 32, 18))
+	for y := 0; y < 18; y++ {
+		for x := 0; x < 32; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestEncodeRecording(t *testing.T) {
+	frames := []recordingFrame{
+		{data: testJPEG(t, color.White), at: 100},
+		{data: testJPEG(t, color.Black), at: 100.5},
+		{data: testJPEG(t, color.White), at: 100.501},
+	}
+	data, err := encodeRecording(frames)
+	if err != nil {
+		t.Fatalf("encodeRecording() error = %v", err)
+	}
+	anim, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("recording is not a GIF: %v", err)
+	}
+	if len(anim.Image) != 3 || anim.Config.Width != 32 || anim.Config.Height != 18 {
+		t.Fatalf("GIF has %d frames of %dx%d", len(anim.Image), anim.Config.Width, anim.Config.Height)
+	}
+	// Half a second, the 2/100 floor, then the held last frame
+	want := []int{50, 2, 100}
+	for i, d := range want {
+		if anim.Delay[i] != d {
+			t.Errorf("delay[%d] = %d, want %d", i, anim.Delay[i], d)
+		}
+	}
+}
+
+func TestEncodeRecordingBadFrame(t *testing.T) {
+	if _, err := encodeRecording([]recordingFrame{{data: []byte("not a jpeg")}}); err == nil {
+		t.Error("encodeRecording() accepted a corrupt frame")
+	}
+}
+
+func TestRecordingFileName(t *testing.T) {
+	at := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	if got := recordingFileName("https://WWW.Example.com:8443/path", true, at); got != "20260102-150405.000-www.example.com-ok.gif" {
+		t.Errorf("recordingFileName() = %q", got)
+	}
+	if got := recordingFileName("::bad", false, at); got != "20260102-150405.000-unknown-failed.gif" {
+		t.Errorf("recordingFileName(bad URL) = %q", got)
+	}
+}
+
+func TestRecordingOff(t *testing.T) {
+	s := &Solver{}
+	// A nil page would panic if recording were attempted
+	if rec := s.startRecording(context.Background(), nil, &SolveOptions{}); rec != nil {
+		t.Error("startRecording() without RecordSolve returned a recorder")
+	}
+	s.finishRecording(nil, &SolveOptions{}, &Result{}, nil)
+}
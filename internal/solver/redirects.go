@@ -0,0 +1,183 @@
+package solver
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/security"
+)
+
+// maxClientRedirectHops caps how many meta-refresh/JS redirects are followed
+// after clearance, so a redirect loop cannot hold the browser indefinitely.
+const maxClientRedirectHops = 5
+
+// redirectPollInterval is how often the page URL is checked during the settle window.
+const redirectPollInterval = 100 * time.Millisecond
+
+// metaRefreshJS returns the content attribute of the first meta refresh tag, or "".
+const metaRefreshJS = `() => {
+	const m = document.querySelector('meta[http-equiv="refresh" i]');
+	return m ? (m.getAttribute('content') || '') : '';
+}`
+
+// parseMetaRefresh parses a meta refresh content attribute such as
+// `0; url=https://example.com/next` or `3;URL='/next'`.
+// Returns ok=false if there is no redirect target.
+func parseMetaRefresh(content string) (delay time.Duration, target string, ok bool) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return 0, "", false
+	}
+
+	delayPart, rest, _ := strings.Cut(content, ";")
+	if i := strings.IndexByte(delayPart, ','); i >= 0 {
+		// Some pages use a comma instead of a semicolon
+		rest = delayPart[i+1:] + rest
+		delayPart = delayPart[:i]
+	}
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(delayPart), 64)
+	if err != nil || seconds < 0 {
+		return 0, "", false
+	}
+
+	rest = strings.TrimSpace(rest)
+	if len(rest) >= 3 && strings.EqualFold(rest[:3], "url") {
+		rest = strings.TrimSpace(rest[3:])
+		if !strings.HasPrefix(rest, "=") {
+			return 0, "", false
+		}
+		rest = strings.TrimSpace(rest[1:])
+	}
+	rest = strings.Trim(rest, `'"`)
+	if rest == "" {
+		return 0, "", false
+	}
+
+	return time.Duration(seconds * float64(time.Second)), rest, true
+}
+
+// resolveRedirectTarget resolves a possibly relative redirect target against
+// the current page URL. Only http(s) targets are accepted.
+func resolveRedirectTarget(current, target string) (string, bool) {
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", false
+	}
+	ref, err := url.Parse(target)
+	if err != nil {
+		return "", false
+	}
+	resolved := base.ResolveReference(ref)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return "", false
+	}
+	resolved.Fragment = ""
+	return resolved.String(), true
+}
+
+// currentPageURL returns the page's committed URL, or "" if unavailable.
+func currentPageURL(page *rod.Page) string {
+	info, err := page.Info()
+	if err != nil || info == nil {
+		return ""
+	}
+	return info.URL
+}
+
+// followClientRedirects waits up to settle for a meta-refresh or JavaScript
+// location redirect fired by the cleared page, and follows it (up to
+// maxClientRedirectHops, restarting the window after each hop). Meta refreshes
+// whose delay fits in the window are followed immediately instead of waiting
+// them out. Returns the URLs landed on, in order; empty if the page stayed put.
+//
+// Every destination is re-validated for SSRF; following stops at the first
+// one that fails.
+func (s *Solver) followClientRedirects(ctx context.Context, page *rod.Page, settle time.Duration, skipValidation bool) []string {
+	var hops []string
+	current := currentPageURL(page)
+	if current == "" || current == "about:blank" {
+		return nil
+	}
+
+	for len(hops) < maxClientRedirectHops {
+		deadline := time.Now().Add(settle)
+		moved := false
+
+		// Follow a meta refresh that would fire within the window right away
+		if res, err := page.Timeout(2 * time.Second).Eval(metaRefreshJS); err == nil {
+			if delay, target, ok := parseMetaRefresh(res.Value.Str()); ok && delay <= settle {
+				if next, ok := resolveRedirectTarget(current, target); ok && next != current {
+					if !skipValidation {
+						if err := security.ValidateURLWithContext(ctx, next); err != nil {
+							log.Ctx(ctx).Warn().Err(err).Str("url", next).Msg("Meta refresh target failed validation, not following")
+							return hops
+						}
+					}
+					log.Ctx(ctx).Debug().Str("from", current).Str("to", next).Msg("Following meta refresh after clearance")
+					if err := page.Context(ctx).Navigate(next); err != nil {
+						log.Ctx(ctx).Warn().Err(err).Str("url", next).Msg("Failed to follow meta refresh")
+						return hops
+					}
+					moved = true
+				}
+			}
+		}
+
+		// Otherwise watch for a JS location change (location.replace, href assignment, ...)
+		for !moved && time.Now().Before(deadline) {
+			if !sleepWithContext(ctx, redirectPollInterval) {
+				return hops
+			}
+			if u := currentPageURL(page); u != "" && u != current {
+				moved = true
+			}
+		}
+		if !moved {
+			return hops
+		}
+
+		if err := page.Context(ctx).WaitLoad(); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("WaitLoad after client redirect failed, continuing")
+		}
+		if err := s.validateResponseURL(page, nil, skipValidation); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("Client redirect destination failed validation, stopping")
+			return hops
+		}
+
+		current = currentPageURL(page)
+		hops = append(hops, current)
+		log.Ctx(ctx).Info().Str("url", current).Int("hop", len(hops)).Msg("Followed post-clearance client redirect")
+	}
+
+	return hops
+}
+
+// applyClientRedirects follows post-clearance client redirects when enabled and
+// refreshes the result (HTML, URL, cookies, headers) from the final page.
+func (s *Solver) applyClientRedirects(ctx context.Context, page *rod.Page, opts *SolveOptions, result *Result) {
+	if opts.RedirectSettle <= 0 || opts.Method != "" || opts.ReturnRawHtml {
+		return
+	}
+
+	hops := s.followClientRedirects(ctx, page, opts.RedirectSettle, opts.SkipResponseValidation)
+	if len(hops) == 0 {
+		return
+	}
+
+	// Destinations were validated hop by hop above
+	refreshed, err := s.buildResult(ctx, page, opts.URL, opts.screenshot(), nil, true, nil, 0)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to rebuild result after client redirect, returning pre-redirect page")
+		return
+	}
+	ua := result.UserAgent
+	*result = *refreshed
+	result.UserAgent = ua
+	result.ClientRedirects = hops
+}
@@ -0,0 +1,67 @@
+package solver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMetaRefresh(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantDelay  time.Duration
+		wantTarget string
+		wantOK     bool
+	}{
+		{"zero delay", "0; url=https://example.com/next", 0, "https://example.com/next", true},
+		{"uppercase quoted", "3;URL='/next'", 3 * time.Second, "/next", true},
+		{"double quoted", `1; url="/a?b=c"`, time.Second, "/a?b=c", true},
+		{"no url keyword", "0; /direct", 0, "/direct", true},
+		{"comma separator", "2,url=/comma", 2 * time.Second, "/comma", true},
+		{"fractional delay", "0.5; url=/half", 500 * time.Millisecond, "/half", true},
+		{"reload only", "5", 0, "", false},
+		{"empty", "", 0, "", false},
+		{"bad delay", "soon; url=/x", 0, "", false},
+		{"negative delay", "-1; url=/x", 0, "", false},
+		{"missing equals", "0; url /x", 0, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, target, ok := parseMetaRefresh(tt.content)
+			if ok != tt.wantOK {
+				t.Fatalf("parseMetaRefresh(%q) ok = %v, want %v", tt.content, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if delay != tt.wantDelay || target != tt.wantTarget {
+				t.Errorf("parseMetaRefresh(%q) = (%v, %q), want (%v, %q)", tt.content, delay, target, tt.wantDelay, tt.wantTarget)
+			}
+		})
+	}
+}
+
+func TestResolveRedirectTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		target  string
+		want    string
+		wantOK  bool
+	}{
+		{"absolute", "https://example.com/a", "https://other.com/b", "https://other.com/b", true},
+		{"relative path", "https://example.com/dir/page", "next", "https://example.com/dir/next", true},
+		{"root relative", "https://example.com/dir/page", "/home", "https://example.com/home", true},
+		{"fragment stripped", "https://example.com/", "/x#frag", "https://example.com/x", true},
+		{"javascript scheme", "https://example.com/", "javascript:alert(1)", "", false},
+		{"file scheme", "https://example.com/", "file:///etc/passwd", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := resolveRedirectTarget(tt.current, tt.target)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("resolveRedirectTarget(%q, %q) = (%q, %v), want (%q, %v)", tt.current, tt.target, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestScreenshotRequest(t *testing.T) {
+	req := screenshotRequest(&ScreenshotOptions{})
+	if req.Format != proto.PageCaptureScreenshotFormatPng || req.Quality != nil {
+		t.Errorf("default request = %+v, want PNG without quality", req)
+	}
+
+	req = screenshotRequest(&ScreenshotOptions{Format: "jpeg", Quality: 60})
+	if req.Format != proto.PageCaptureScreenshotFormatJpeg || req.Quality == nil || *req.Quality != 60 {
+		t.Errorf("jpeg request = %+v", req)
+	}
+
+	req = screenshotRequest(&ScreenshotOptions{Format: "webp"})
+	if req.Format != proto.PageCaptureScreenshotFormatWebp || req.Quality != nil {
+		t.Errorf("webp request = %+v", req)
+	}
+
+	// Quality never reaches a PNG capture
+	if req = screenshotRequest(&ScreenshotOptions{Quality: 60}); req.Quality != nil {
+		t.Errorf("png request has quality %d", *req.Quality)
+	}
+}
+
+func TestSolveOptionsScreenshot(t *testing.T) {
+	opts := &SolveOptions{ScreenshotOptions: ScreenshotOptions{Format: "jpeg"}}
+	if opts.screenshot() != nil {
+		t.Error("screenshot() without Screenshot should be nil")
+	}
+	opts.Screenshot = true
+	if got := opts.screenshot(); got == nil || got.Format != "jpeg" {
+		t.Errorf("screenshot() = %+v", got)
+	}
+}
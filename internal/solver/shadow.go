@@ -69,12 +69,12 @@ func (t *ShadowRootTraverser) FindTurnstileCheckbox(ctx context.Context) (*rod.E
 
 		element, err := t.findCheckboxViaHost(ctx, hostSelector)
 		if err == nil && element != nil {
-			log.Debug().
+			log.Ctx(ctx).Debug().
 				Str("host_selector", hostSelector).
 				Msg("Found checkbox via shadow host")
 			return element, nil
 		}
-		log.Debug().
+		log.Ctx(ctx).Debug().
 			Str("host_selector", hostSelector).
 			Err(err).
 			Msg("Shadow host selector did not yield checkbox")
@@ -89,7 +89,7 @@ func (t *ShadowRootTraverser) FindTurnstileCheckbox(ctx context.Context) (*rod.E
 
 	element, err := t.findCheckboxViaLandmark(ctx)
 	if err == nil && element != nil {
-		log.Debug().Msg("Found checkbox via cf-turnstile-response landmark")
+		log.Ctx(ctx).Debug().Msg("Found checkbox via cf-turnstile-response landmark")
 		return element, nil
 	}
 
@@ -103,7 +103,7 @@ func (t *ShadowRootTraverser) FindTurnstileCheckbox(ctx context.Context) (*rod.E
 	// Try finding Turnstile iframes and checking their shadow roots
 	element, err = t.findCheckboxInTurnstileIframes(ctx)
 	if err == nil && element != nil {
-		log.Debug().Msg("Found checkbox in Turnstile iframe shadow root")
+		log.Ctx(ctx).Debug().Msg("Found checkbox in Turnstile iframe shadow root")
 		return element, nil
 	}
 
@@ -116,7 +116,7 @@ func (t *ShadowRootTraverser) FindTurnstileCheckbox(ctx context.Context) (*rod.E
 
 	element, err = t.findCheckboxViaFullTree(ctx)
 	if err == nil && element != nil {
-		log.Debug().Msg("Found checkbox via full DOM tree scan")
+		log.Ctx(ctx).Debug().Msg("Found checkbox via full DOM tree scan")
 		return element, nil
 	}
 
@@ -138,7 +138,7 @@ func (t *ShadowRootTraverser) findCheckboxViaHost(ctx context.Context, hostSelec
 	}
 	defer func() {
 		if err := host.Release(); err != nil {
-			log.Debug().Err(err).Msg("Failed to release shadow host element")
+			log.Ctx(ctx).Debug().Err(err).Msg("Failed to release shadow host element")
 		}
 	}()
 
@@ -146,7 +146,7 @@ func (t *ShadowRootTraverser) findCheckboxViaHost(ctx context.Context, hostSelec
 	// Rod's ShadowRoot() uses DOM.describeNode which can access closed shadow roots
 	shadowRoot, err := host.ShadowRoot()
 	if err != nil {
-		log.Debug().
+		log.Ctx(ctx).Debug().
 			Str("host_selector", hostSelector).
 			Err(err).
 			Msg("Failed to access shadow root (may not exist or be closed)")
@@ -162,7 +162,7 @@ func (t *ShadowRootTraverser) findCheckboxViaHost(ctx context.Context, hostSelec
 	for _, checkboxSelector := range sel.ShadowInnerSelectors {
 		checkbox, err := shadowRoot.Element(checkboxSelector)
 		if err == nil && checkbox != nil {
-			log.Debug().
+			log.Ctx(ctx).Debug().
 				Str("checkbox_selector", checkboxSelector).
 				Msg("Found checkbox in shadow root")
 			return checkbox, nil
@@ -174,7 +174,7 @@ func (t *ShadowRootTraverser) findCheckboxViaHost(ctx context.Context, hostSelec
 	if err == nil && iframe != nil {
 		defer func() {
 			if err := iframe.Release(); err != nil {
-				log.Debug().Err(err).Msg("Failed to release iframe element")
+				log.Ctx(ctx).Debug().Err(err).Msg("Failed to release iframe element")
 			}
 		}()
 
@@ -184,7 +184,7 @@ func (t *ShadowRootTraverser) findCheckboxViaHost(ctx context.Context, hostSelec
 			for _, checkboxSelector := range sel.ShadowInnerSelectors {
 				checkbox, err := frame.Timeout(t.timeout).Element(checkboxSelector)
 				if err == nil && checkbox != nil {
-					log.Debug().
+					log.Ctx(ctx).Debug().
 						Str("checkbox_selector", checkboxSelector).
 						Msg("Found checkbox in iframe within shadow root")
 					return checkbox, nil
@@ -221,7 +221,7 @@ func (t *ShadowRootTraverser) findCheckboxViaLandmark(ctx context.Context) (*rod
 	}
 	defer func() {
 		if err := input.Release(); err != nil {
-			log.Debug().Err(err).Msg("Failed to release landmark input element")
+			log.Ctx(ctx).Debug().Err(err).Msg("Failed to release landmark input element")
 		}
 	}()
 
@@ -233,7 +233,7 @@ func (t *ShadowRootTraverser) findCheckboxViaLandmark(ctx context.Context) (*rod
 	}
 	defer func() {
 		if err := parent.Release(); err != nil {
-			log.Debug().Err(err).Msg("Failed to release landmark parent element")
+			log.Ctx(ctx).Debug().Err(err).Msg("Failed to release landmark parent element")
 		}
 	}()
 
@@ -247,7 +247,7 @@ func (t *ShadowRootTraverser) findCheckboxViaLandmark(ctx context.Context) (*rod
 		}
 		defer func() {
 			if err := grandparent.Release(); err != nil {
-				log.Debug().Err(err).Msg("Failed to release landmark grandparent element")
+				log.Ctx(ctx).Debug().Err(err).Msg("Failed to release landmark grandparent element")
 			}
 		}()
 
@@ -262,7 +262,7 @@ func (t *ShadowRootTraverser) findCheckboxViaLandmark(ctx context.Context) (*rod
 	for _, checkboxSelector := range sel.ShadowInnerSelectors {
 		checkbox, err := shadowRoot.Element(checkboxSelector)
 		if err == nil && checkbox != nil {
-			log.Debug().
+			log.Ctx(ctx).Debug().
 				Str("checkbox_selector", checkboxSelector).
 				Msg("Found checkbox via landmark parent shadow root")
 			return checkbox, nil
@@ -274,7 +274,7 @@ func (t *ShadowRootTraverser) findCheckboxViaLandmark(ctx context.Context) (*rod
 	if err == nil && iframe != nil {
 		defer func() {
 			if err := iframe.Release(); err != nil {
-				log.Debug().Err(err).Msg("Failed to release landmark iframe element")
+				log.Ctx(ctx).Debug().Err(err).Msg("Failed to release landmark iframe element")
 			}
 		}()
 
@@ -283,7 +283,7 @@ func (t *ShadowRootTraverser) findCheckboxViaLandmark(ctx context.Context) (*rod
 			for _, checkboxSelector := range sel.ShadowInnerSelectors {
 				checkbox, err := frame.Timeout(t.timeout).Element(checkboxSelector)
 				if err == nil && checkbox != nil {
-					log.Debug().
+					log.Ctx(ctx).Debug().
 						Str("checkbox_selector", checkboxSelector).
 						Msg("Found checkbox in iframe via landmark")
 					return checkbox, nil
@@ -329,7 +329,7 @@ func (t *ShadowRootTraverser) findCheckboxInNestedShadow(ctx context.Context, fr
 		shadowRoot, err := func() (*rod.Element, error) {
 			defer func() {
 				if releaseErr := host.Release(); releaseErr != nil {
-					log.Debug().Err(releaseErr).Msg("Failed to release nested shadow host element")
+					log.Ctx(ctx).Debug().Err(releaseErr).Msg("Failed to release nested shadow host element")
 				}
 			}()
 			return host.ShadowRoot()
@@ -341,7 +341,7 @@ func (t *ShadowRootTraverser) findCheckboxInNestedShadow(ctx context.Context, fr
 		for _, checkboxSelector := range sel.ShadowInnerSelectors {
 			checkbox, err := shadowRoot.Element(checkboxSelector)
 			if err == nil && checkbox != nil {
-				log.Debug().
+				log.Ctx(ctx).Debug().
 					Str("checkbox_selector", checkboxSelector).
 					Msg("Found checkbox in nested shadow root")
 				return checkbox, nil
@@ -366,7 +366,7 @@ func (t *ShadowRootTraverser) findCheckboxInTurnstileIframes(ctx context.Context
 	defer func() {
 		for _, iframe := range iframes {
 			if err := iframe.Release(); err != nil {
-				log.Debug().Err(err).Msg("Failed to release iframe element in cleanup")
+				log.Ctx(ctx).Debug().Err(err).Msg("Failed to release iframe element in cleanup")
 			}
 		}
 	}()
@@ -374,7 +374,7 @@ func (t *ShadowRootTraverser) findCheckboxInTurnstileIframes(ctx context.Context
 	iframesToCheck := iframes
 	if len(iframesToCheck) > maxIframesToCheck {
 		iframesToCheck = iframesToCheck[:maxIframesToCheck]
-		log.Debug().Int("total", len(iframes)).Int("checking", maxIframesToCheck).Msg("Limiting iframe check count")
+		log.Ctx(ctx).Debug().Int("total", len(iframes)).Int("checking", maxIframesToCheck).Msg("Limiting iframe check count")
 	}
 
 	for _, iframe := range iframesToCheck {
@@ -416,7 +416,7 @@ func (t *ShadowRootTraverser) findCheckboxInTurnstileIframes(ctx context.Context
 			shadowRoot, err := func() (*rod.Element, error) {
 				defer func() {
 					if releaseErr := host.Release(); releaseErr != nil {
-						log.Debug().Err(releaseErr).Msg("Failed to release Turnstile iframe shadow host element")
+						log.Ctx(ctx).Debug().Err(releaseErr).Msg("Failed to release Turnstile iframe shadow host element")
 					}
 				}()
 				return host.ShadowRoot()
@@ -428,7 +428,7 @@ func (t *ShadowRootTraverser) findCheckboxInTurnstileIframes(ctx context.Context
 			for _, checkboxSelector := range sel.ShadowInnerSelectors {
 				checkbox, err := shadowRoot.Element(checkboxSelector)
 				if err == nil && checkbox != nil {
-					log.Debug().
+					log.Ctx(ctx).Debug().
 						Str("frame_src", *src).
 						Str("checkbox_selector", checkboxSelector).
 						Msg("Found checkbox in Turnstile iframe shadow root")
@@ -441,7 +441,7 @@ func (t *ShadowRootTraverser) findCheckboxInTurnstileIframes(ctx context.Context
 		for _, checkboxSelector := range sel.ShadowInnerSelectors {
 			checkbox, err := frame.Timeout(t.timeout).Element(checkboxSelector)
 			if err == nil && checkbox != nil {
-				log.Debug().
+				log.Ctx(ctx).Debug().
 					Str("frame_src", *src).
 					Str("checkbox_selector", checkboxSelector).
 					Msg("Found checkbox directly in Turnstile iframe")
@@ -562,7 +562,7 @@ func (t *ShadowRootTraverser) findCheckboxViaFullTree(ctx context.Context) (*rod
 		return nil, fmt.Errorf("failed to create element from resolved node: %w", err)
 	}
 
-	log.Info().
+	log.Ctx(ctx).Info().
 		Int("nodes_visited", nodesVisited).
 		Msg("Found Turnstile checkbox via full DOM tree scan")
 	return element, nil
@@ -671,7 +671,7 @@ func (t *ShadowRootTraverser) GetCheckboxPosition(ctx context.Context) (x, y flo
 	}
 	defer func() {
 		if err := checkbox.Release(); err != nil {
-			log.Debug().Err(err).Msg("Failed to release checkbox element")
+			log.Ctx(ctx).Debug().Err(err).Msg("Failed to release checkbox element")
 		}
 	}()
 
@@ -704,7 +704,7 @@ func (t *ShadowRootTraverser) ClickCheckbox(ctx context.Context) (err error) {
 	// Add panic recovery for DOM traversal operations
 	defer func() {
 		if r := recover(); r != nil {
-			log.Error().Interface("panic", r).Msg("Recovered from panic in shadow DOM checkbox click")
+			log.Ctx(ctx).Error().Interface("panic", r).Msg("Recovered from panic in shadow DOM checkbox click")
 			err = fmt.Errorf("panic during shadow DOM click: %v", r)
 		}
 	}()
@@ -715,7 +715,7 @@ func (t *ShadowRootTraverser) ClickCheckbox(ctx context.Context) (err error) {
 	}
 	defer func() {
 		if err := checkbox.Release(); err != nil {
-			log.Debug().Err(err).Msg("Failed to release checkbox element after click")
+			log.Ctx(ctx).Debug().Err(err).Msg("Failed to release checkbox element after click")
 		}
 	}()
 
@@ -724,7 +724,7 @@ func (t *ShadowRootTraverser) ClickCheckbox(ctx context.Context) (err error) {
 		return fmt.Errorf("failed to click checkbox via shadow DOM: %w", err)
 	}
 
-	log.Info().Msg("Successfully clicked Turnstile checkbox via shadow DOM traversal")
+	log.Ctx(ctx).Info().Msg("Successfully clicked Turnstile checkbox via shadow DOM traversal")
 	return nil
 }
 
@@ -749,7 +749,7 @@ func (t *ShadowRootTraverser) GetTurnstileContainerBounds(ctx context.Context) (
 		box, err := func() (*proto.DOMGetContentQuadsResult, error) {
 			defer func() {
 				if releaseErr := element.Release(); releaseErr != nil {
-					log.Debug().Err(releaseErr).Msg("Failed to release container bounds element")
+					log.Ctx(ctx).Debug().Err(releaseErr).Msg("Failed to release container bounds element")
 				}
 			}()
 			return element.Shape()
@@ -792,7 +792,7 @@ func (t *ShadowRootTraverser) GetTurnstileContainerBounds(ctx context.Context) (
 		box, err := func() (*proto.DOMGetContentQuadsResult, error) {
 			defer func() {
 				if releaseErr := element.Release(); releaseErr != nil {
-					log.Debug().Err(releaseErr).Msg("Failed to release turnstile selector element")
+					log.Ctx(ctx).Debug().Err(releaseErr).Msg("Failed to release turnstile selector element")
 				}
 			}()
 			return element.Shape()
@@ -0,0 +1,53 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// snapshotTimeout bounds capturing a returnSnapshot archive, which fetches
+// every resource of the page from the cache or network.
+const snapshotTimeout = 60 * time.Second
+
+// maxSnapshotSize caps a returnSnapshot archive.
+const maxSnapshotSize = 20 * 1024 * 1024
+
+// applySnapshot captures the final page as a self-contained archive for
+// result.Snapshot, with its stylesheets, images and frames inlined so the
+// client needs no second request to assets the site may block. A failed
+// capture is logged and leaves the field empty.
+func (s *Solver) applySnapshot(ctx context.Context, page *rod.Page, opts *SolveOptions, result *Result) {
+	if opts.ReturnSnapshot == "" || opts.Method != "" || opts.Download {
+		return
+	}
+
+	data, err := captureSnapshot(page.Context(ctx).Timeout(snapshotTimeout), opts.ReturnSnapshot)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("format", opts.ReturnSnapshot).Msg("Failed to capture page snapshot")
+		return
+	}
+	result.Snapshot = data
+	log.Ctx(ctx).Debug().Int("size", len(data)).Str("format", opts.ReturnSnapshot).Msg("Page snapshot captured")
+}
+
+// captureSnapshot returns the page in the given snapshot format.
+func captureSnapshot(page *rod.Page, format string) (string, error) {
+	if format != types.SnapshotMHTML {
+		return "", fmt.Errorf("unsupported snapshot format %q", format)
+	}
+	res, err := proto.PageCaptureSnapshot{Format: proto.PageCaptureSnapshotFormatMhtml}.Call(page)
+	if err != nil {
+		return "", fmt.Errorf("snapshot capture failed: %w", err)
+	}
+	if len(res.Data) > maxSnapshotSize {
+		return "", fmt.Errorf("snapshot size %d exceeds maximum limit of %d bytes", len(res.Data), maxSnapshotSize)
+	}
+	return res.Data, nil
+}
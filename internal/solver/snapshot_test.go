@@ -0,0 +1,28 @@
+package solver
+
+import (
+	"context"
+	"testing"
+)
+
+func TestApplySnapshotSkipped(t *testing.T) {
+	s := &Solver{}
+	for _, opts := range []*SolveOptions{
+		{},
+		{ReturnSnapshot: "mhtml", Download: true},
+		{ReturnSnapshot: "mhtml", Method: "DELETE"},
+	} {
+		result := &Result{}
+		// A nil page would panic if a capture were attempted
+		s.applySnapshot(context.Background(), nil, opts, result)
+		if result.Snapshot != "" {
+			t.Errorf("applySnapshot(%+v) captured a snapshot", opts)
+		}
+	}
+}
+
+func TestCaptureSnapshotFormat(t *testing.T) {
+	if _, err := captureSnapshot(nil, "warc"); err == nil {
+		t.Error("captureSnapshot() accepted an unsupported format")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"net"
@@ -13,6 +14,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -23,12 +25,17 @@ import (
 	"github.com/go-rod/stealth"
 	"github.com/rs/zerolog/log"
 	"github.com/ysmood/gson"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/Rorqualx/flaresolverr-go/internal/browser"
 	"github.com/Rorqualx/flaresolverr-go/internal/captcha"
+	"github.com/Rorqualx/flaresolverr-go/internal/events"
+	"github.com/Rorqualx/flaresolverr-go/internal/features"
 	"github.com/Rorqualx/flaresolverr-go/internal/humanize"
 	"github.com/Rorqualx/flaresolverr-go/internal/security"
 	"github.com/Rorqualx/flaresolverr-go/internal/selectors"
+	"github.com/Rorqualx/flaresolverr-go/internal/tracing"
 	"github.com/Rorqualx/flaresolverr-go/internal/types"
 )
 
@@ -42,6 +49,11 @@ const (
 	ChallengeTurnstile
 	ChallengeHCaptcha
 	ChallengeAccessDenied
+	ChallengeRecaptcha
+	ChallengeAWSWAF
+	ChallengeDataDome
+	ChallengeIncapsula
+	ChallengeManaged
 )
 
 // Result contains the outcome of a solve attempt.
@@ -54,15 +66,26 @@ type Result struct {
 	CookieError    string // Non-empty if cookies could not be retrieved
 	UserAgent      string
 	URL            string
-	Screenshot     string // Base64 encoded PNG screenshot
+	Screenshot     string // Base64 encoded screenshot in ScreenshotOptions.Format
 	TurnstileToken string // cf-turnstile-response token if present
+	RecaptchaToken string // g-recaptcha-response token solved for SolveRecaptcha
 
 	// Extended extraction for debugging/advanced use
-	LocalStorage     map[string]string // All localStorage key-value pairs
-	SessionStorage   map[string]string // All sessionStorage key-value pairs
-	ResponseHeaders  map[string]string // Headers from the final navigation response
-	ResponseEncoding string            // "base64" when download mode, empty for HTML
-	ExecuteJsResult  string            // Result of custom JS execution
+	LocalStorage     map[string]string       // All localStorage key-value pairs
+	SessionStorage   map[string]string       // All sessionStorage key-value pairs
+	ResponseHeaders  map[string]string       // Headers from the final navigation response
+	ResponseEncoding string                  // "base64" when download mode, empty for HTML
+	ExecuteJsResult  string                  // Result of custom JS execution
+	JSResult         []byte                  // JSON return value of evaluateJs (nil if not run or failed)
+	JSError          string                  // Why evaluateJs produced no result
+	CapturedRequests []types.CapturedRequest // XHR/fetch responses matching CaptureRequests
+	HAR              []byte                  // HAR archive of the solve's traffic (nil unless ReturnHar)
+	Pdf              string                  // Base64 PDF of the final page (empty unless ReturnPdf)
+	Snapshot         string                  // Archive of the final page in the ReturnSnapshot format
+	Recording        string                  // Base64 animated GIF of the solve (RecordSolve without a recording dir)
+	RecordingPath    string                  // Where the RecordSolve recording was written
+	ClientRedirects  []string                // URLs reached via post-clearance meta-refresh/JS redirects
+	EgressIP         string                  // IP the browser exited from (empty unless VerifyProxy)
 }
 
 // SolveOptions contains options for a solve request.
@@ -75,12 +98,18 @@ type SolveOptions struct {
 	ContentType    string            // Content type for POST: "application/json" or "application/x-www-form-urlencoded"
 	Headers        map[string]string // Custom HTTP headers to send with the request
 	IsPost         bool
-	Screenshot     bool   // Capture screenshot after solve
+	Screenshot     bool   // Capture screenshot after solve, as ScreenshotOptions says
 	DisableMedia   bool   // Disable loading of media (images, CSS, fonts)
 	WaitInSeconds  int    // Wait N seconds before returning the response
 	ExpectedIP     net.IP // Expected IP from DNS resolution for pinning (nil to skip)
 	TabsTillVerify int    // Number of Tab presses to reach Turnstile checkbox (default: 10)
+	// TurnstileMethods are Turnstile methods to try first, before the order
+	// learned for the domain.
+	TurnstileMethods []string
 
+	// Method is the HTTP method for requests issued via the in-page Fetch API
+	// (PUT, PATCH, DELETE). Empty means GET or POST navigation, per IsPost.
+	Method string
 	// Download returns URL content as base64 instead of page HTML.
 	Download bool
 	// FollowRedirects controls whether to follow HTTP redirects (default: true).
@@ -93,15 +122,85 @@ type SolveOptions struct {
 	ReturnRawHtml bool //nolint:revive,stylecheck // JSON API compatibility
 	// ExecuteJs is custom JavaScript to execute on the page after solving.
 	ExecuteJs string
+	// EvaluateJs is a script run after ExecuteJs whose JSON-serializable
+	// return value is kept in Result.JSResult. The handler only passes it
+	// when EVALUATE_JS_ENABLED is set.
+	EvaluateJs string
+	// CaptureRequests are URL patterns of XHR/fetch responses to record,
+	// body included, for Result.CapturedRequests.
+	CaptureRequests []string
+	// BlockURLs are URL patterns of page requests to fail; AllowURLs are
+	// never failed, whether by BlockURLs or DisableMedia. Both use the
+	// captureRequests pattern syntax.
+	BlockURLs []string
+	AllowURLs []string
+	// ScreenshotOptions shapes the Screenshot capture; the zero value is a
+	// full-page PNG.
+	ScreenshotOptions ScreenshotOptions
+	// ReturnHar records every request the page makes for Result.HAR.
+	ReturnHar bool
+	// ReturnPdf renders the final page to PDF for Result.Pdf, sized by
+	// PdfOptions (nil for Chrome's defaults).
+	ReturnPdf  bool
+	PdfOptions *types.PdfOptions
+	// ReturnSnapshot captures the final page with its resources inlined for
+	// Result.Snapshot; types.SnapshotMHTML is the only format.
+	ReturnSnapshot string
+	// RecordSolve screencasts the page from navigation to the end of the
+	// solve, for Result.Recording or the solver's recording directory.
+	RecordSolve bool
+	// VerifyProxy loads the IP-echo endpoint before the target and fails
+	// when the browser exits from the server's own IP.
+	VerifyProxy bool
+	// SolveRecaptcha solves a reCAPTCHA embedded in the final page (e.g. a
+	// login form) through the external solver chain, before ExecuteJs runs.
+	SolveRecaptcha bool
+	// WaitForSelector and WaitForText hold the result back until the cleared
+	// page has an element matching the selector and contains the text, for
+	// SPAs whose content renders after the load event.
+	WaitForSelector string
+	WaitForText     string
+	// Actions run on the cleared page after the waits above, before
+	// ExecuteJs; the result is rebuilt from where they leave the page.
+	Actions []types.Action
 	// CookieExtractDelay is the number of seconds to wait before extracting cookies.
 	// This allows late-set JS cookies to be captured.
 	CookieExtractDelay int
 	// Fingerprint specifies per-request browser fingerprint customization.
 	Fingerprint *types.FingerprintConfig
+	// RedirectSettle is how long to watch for meta-refresh/JS location redirects
+	// after clearance; they are followed so the real destination is returned.
+	// Zero disables following.
+	RedirectSettle time.Duration
+	// XHRWatch is how long to watch the cleared page's XHR/fetch responses for
+	// a second-order challenge. Zero disables the check.
+	XHRWatch time.Duration
+	// XHRChallengeReport fails the solve with XHR_CHALLENGED instead of
+	// solving the challenged request's URL and reloading the page.
+	XHRChallengeReport bool
 	// DefaultTimezone is the global timezone fallback (from TZ env var). Applied
 	// per page via CDP Emulation.setTimezoneOverride when no per-request
 	// Fingerprint.Overrides["timezone"] takes priority.
 	DefaultTimezone string
+	// Poll overrides the server's polling settings for this solve.
+	Poll *types.PollOptions
+	// Limits overrides the server's limits on the returned page, screenshot,
+	// cookies and storage for this solve.
+	Limits *types.ResponseLimits
+	// Locale, Timezone and Viewport emulate the request's language tag,
+	// IANA timezone and viewport size on the page. Timezone wins over the
+	// fingerprint override and DefaultTimezone; an unset Viewport is
+	// 1920x1080 on fresh pages and left alone on session pages.
+	Locale   string
+	Timezone string
+	Viewport *types.Viewport
+	// Profile is the named browser profile selected with the request's
+	// "profile" field. Its browser is dedicated rather than pooled, and its
+	// user agent, fingerprint and timezone apply where the request sets none.
+	Profile *browser.BrowserProfile
+	// Priority orders this solve against others waiting on a saturated pool
+	// (browser.PriorityLow, PriorityNormal or PriorityHigh).
+	Priority int
 
 	// SkipResponseValidation disables response URL validation (for testing only).
 	// WARNING: Do not enable in production - this disables SSRF protection.
@@ -116,7 +215,16 @@ type Solver struct {
 	selectorsManager *selectors.Manager   // Hot-reload capable selectors manager
 	statsManager     StatsManager         // Domain stats for method tracking (optional)
 	clearanceCache   *ClearanceCache      // cf_clearance reuse cache (optional)
+	fastPath         *FastPath            // browserless fetch on clearance cache hits (optional)
 	egressPool       *EgressPool          // sticky clean-egress proxy pool (optional)
+	pollStrategy     PollStrategy         // default challenge poll strategy (nil = random)
+	pollSettings     PollSettings         // polling interval range, attempt cap and selector budget (zero = defaults)
+	responseLimits   ResponseLimits       // size limits on returned pages, screenshots, cookies and storage (zero = defaults)
+	features         *features.Flags      // experimental behavior switches (nil = defaults)
+	challenges       challengeCounter     // per-challenge-type solve counts
+	recordingDir     string               // where recordSolve writes recordings (empty = return them)
+	proxyVerifyURL   string               // IP-echo endpoint for verifyProxy
+	directIP         directIP             // server's own IP, for verifyProxy
 }
 
 // StatsManager interface for domain statistics tracking.
@@ -143,12 +251,34 @@ func New(pool *browser.Pool, userAgent string) *Solver {
 	}
 }
 
+// applyBrowserIdentity fills in the user agent and fingerprint profile that the
+// pool's user agent rotation assigned to this browser. Explicit per-request
+// values (and a clearance-cache UA) take priority.
+func (s *Solver) applyBrowserIdentity(b *rod.Browser, opts *SolveOptions) {
+	if s.pool == nil || b == nil {
+		return
+	}
+	identity, ok := s.pool.IdentityFor(b)
+	if !ok {
+		return
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = identity.UserAgent
+	}
+	if opts.Fingerprint == nil && identity.Profile != "" {
+		opts.Fingerprint = &types.FingerprintConfig{Profile: identity.Profile}
+	}
+}
+
 // resolveTimezone picks the per-page timezone in precedence order:
 // Fingerprint.Overrides["timezone"] > DefaultTimezone. Returns "" when neither is set.
 func resolveTimezone(opts *SolveOptions) string {
 	if opts == nil {
 		return ""
 	}
+	if opts.Timezone != "" {
+		return opts.Timezone
+	}
 	if opts.Fingerprint != nil && opts.Fingerprint.Overrides != nil {
 		if v, ok := opts.Fingerprint.Overrides["timezone"].(string); ok && v != "" {
 			return v
@@ -157,6 +287,71 @@ func resolveTimezone(opts *SolveOptions) string {
 	return opts.DefaultTimezone
 }
 
+// Viewport used when a request sets none, matching the --window-size the
+// browsers launch with.
+const (
+	defaultViewportWidth  = 1920
+	defaultViewportHeight = 1080
+)
+
+// applyLocaleAndViewport applies opts.Locale, re-sending ua with its
+// Accept-Language, and sets the viewport to opts.Viewport or the default.
+func applyLocaleAndViewport(ctx context.Context, page *rod.Page, opts *SolveOptions, ua string) {
+	if opts.Locale != "" {
+		if err := browser.ApplyLocaleOverride(page, opts.Locale, ua); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("locale", opts.Locale).Msg("Failed to apply locale override")
+		}
+	}
+	width, height := defaultViewportWidth, defaultViewportHeight
+	if opts.Viewport != nil {
+		width, height = opts.Viewport.Width, opts.Viewport.Height
+	}
+	if err := browser.SetViewport(page, width, height); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to set viewport")
+	}
+}
+
+// pageReusable reports whether a solve may take and park a reused tab
+// (PAGE_REUSE_ENABLED). Only pooled browsers keep tabs. The solve must set a
+// user agent, replacing the previous request's, and no locale, whose
+// navigator script cannot be taken off the tab again.
+func (s *Solver) pageReusable(opts *SolveOptions, pooled bool) bool {
+	return pooled && s.pool != nil && s.pool.PageReuse() &&
+		opts.Locale == "" && (s.userAgent != "" || opts.UserAgent != "")
+}
+
+// stealthPage returns the browser's parked tab when reuse is set and one is
+// ready, or a new go-rod/stealth page. reused tells the caller the tab's
+// new-document scripts are already registered. A tab that will be parked is
+// watched for the origins it visits, whose storage parking clears.
+func (s *Solver) stealthPage(ctx context.Context, b *rod.Browser, reuse bool) (page *rod.Page, reused bool, err error) {
+	if reuse {
+		if page = s.pool.TakePage(b); page != nil {
+			log.Ctx(ctx).Debug().Msg("Reusing parked tab")
+			reused = true
+		}
+	}
+	if page == nil {
+		if page, err = stealth.Page(b); err != nil {
+			return nil, false, err
+		}
+	}
+	if reuse {
+		s.pool.WatchPage(page)
+	}
+	return page, reused, nil
+}
+
+// releasePage parks the solve's tab for the browser's next request when
+// reuse is set, and closes it otherwise.
+func (s *Solver) releasePage(b *rod.Browser, page *rod.Page, reuse bool) {
+	if reuse {
+		s.pool.ParkPage(b, page)
+		return
+	}
+	_ = page.Close()
+}
+
 // NewWithSelectors creates a new Solver with a SelectorsManager.
 func NewWithSelectors(pool *browser.Pool, userAgent string, selectorsManager *selectors.Manager) *Solver {
 	return &Solver{
@@ -214,6 +409,25 @@ func (s *Solver) GetSolverChainMetrics() map[string]interface{} {
 	return s.solverChain.GetMetrics()
 }
 
+// CaptchaProviderStats returns the external solver stats per provider, nil
+// without a solver chain.
+func (s *Solver) CaptchaProviderStats() map[string]captcha.ProviderStats {
+	return s.solverChain.ProviderStats()
+}
+
+// CaptchaStatus queries the balance of every configured external captcha
+// provider, flagging balances below lowBalance. Returns nil without a solver
+// chain.
+func (s *Solver) CaptchaStatus(ctx context.Context, lowBalance float64) []types.CaptchaProviderStatus {
+	return s.solverChain.Status(ctx, lowBalance)
+}
+
+// CaptchaBudgetStatus returns the external solver spend budgets, nil without
+// a solver chain or budget.
+func (s *Solver) CaptchaBudgetStatus() []captcha.BudgetStatus {
+	return s.solverChain.BudgetStatus()
+}
+
 // sleepWithContext sleeps for the specified duration or until context is canceled.
 // Returns true if the sleep completed normally, false if interrupted by context cancellation.
 //
@@ -265,27 +479,61 @@ func setupProxyAuth(ctx context.Context, page *rod.Page, proxy *types.Proxy) (fu
 		Password: proxy.Password,
 	})
 	if err != nil {
-		log.Warn().Err(err).Msg("Failed to set up proxy")
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to set up proxy")
 		return func() {}, fmt.Errorf("failed to set up proxy authentication: %w", err)
 	}
 	return cleanup, nil
 }
 
-// setupMediaBlocking enables request interception to block media resources.
-// This reduces bandwidth and speeds up page loads by blocking images, stylesheets, fonts, and media.
+// blocksRequests reports whether the solve filters the page's requests, for
+// disableMedia or blockUrls. allowUrls alone has nothing to exempt.
+func blocksRequests(opts *SolveOptions) bool {
+	return opts.DisableMedia || len(opts.BlockURLs) > 0
+}
+
+// blockRequest reports whether a page request is failed: it matches
+// blockUrls, or disableMedia is set and it loads an image, stylesheet, font
+// or media, and it matches no allowUrls pattern. Cloudflare's challenge
+// platform is never blocked by pattern, since a broad pattern would
+// otherwise keep the challenge from ever clearing.
+func blockRequest(opts *SolveOptions, url string, resourceType proto.NetworkResourceType) bool {
+	if matchAnyCapturePattern(opts.AllowURLs, url) {
+		return false
+	}
+	if matchAnyCapturePattern(opts.BlockURLs, url) && !isChallengePlatformURL(url) {
+		return true
+	}
+	if !opts.DisableMedia {
+		return false
+	}
+	switch resourceType {
+	case proto.NetworkResourceTypeImage,
+		proto.NetworkResourceTypeStylesheet,
+		proto.NetworkResourceTypeFont,
+		proto.NetworkResourceTypeMedia:
+		return true
+	}
+	return false
+}
+
+// isChallengePlatformURL reports whether url is part of Cloudflare's
+// challenge or Turnstile machinery.
+func isChallengePlatformURL(url string) bool {
+	return strings.Contains(url, "/cdn-cgi/challenge-platform/") ||
+		strings.Contains(url, "://challenges.cloudflare.com/")
+}
+
+// setupRequestBlocking enables request interception to fail the requests
+// blockRequest picks: media for disableMedia, which reduces bandwidth and
+// speeds up page loads, and the blockUrls patterns, which keep trackers, ads
+// and analytics off the page.
 // Returns a cleanup function that should be deferred.
 // The cleanup function ensures the router goroutine exits cleanly with a timeout.
-func setupMediaBlocking(page *rod.Page) func() {
+func setupRequestBlocking(page *rod.Page, opts *SolveOptions) func() {
 	router := page.HijackRequests()
 
 	router.MustAdd("*", func(ctx *rod.Hijack) {
-		resourceType := ctx.Request.Type()
-		// Block images, stylesheets, fonts, and media
-		switch resourceType {
-		case proto.NetworkResourceTypeImage,
-			proto.NetworkResourceTypeStylesheet,
-			proto.NetworkResourceTypeFont,
-			proto.NetworkResourceTypeMedia:
+		if blockRequest(opts, ctx.Request.URL().String(), ctx.Request.Type()) {
 			ctx.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
 			return
 		}
@@ -299,7 +547,7 @@ func setupMediaBlocking(page *rod.Page) func() {
 		// Add panic recovery to prevent goroutine panic from crashing the process
 		defer func() {
 			if r := recover(); r != nil {
-				log.Error().Interface("panic", r).Msg("Recovered from panic in media blocking router")
+				log.Error().Interface("panic", r).Msg("Recovered from panic in request blocking router")
 			}
 		}()
 		router.Run()
@@ -308,7 +556,7 @@ func setupMediaBlocking(page *rod.Page) func() {
 	return func() {
 		// Stop the router - this signals the goroutine to exit
 		if err := router.Stop(); err != nil {
-			log.Debug().Err(err).Msg("Error stopping media blocking router")
+			log.Debug().Err(err).Msg("Error stopping request blocking router")
 		}
 
 		// Wait for the goroutine to exit with a timeout
@@ -320,7 +568,7 @@ func setupMediaBlocking(page *rod.Page) func() {
 		case <-done:
 			// Clean exit
 		case <-timer.C:
-			log.Warn().Msg("Media blocking goroutine did not exit cleanly within timeout")
+			log.Warn().Msg("Request blocking goroutine did not exit cleanly within timeout")
 		}
 	}
 }
@@ -337,10 +585,14 @@ func setupMediaBlocking(page *rod.Page) func() {
 //
 // Fix #24: Includes panic recovery to prevent crashes from browser-level panics.
 func (s *Solver) Solve(ctx context.Context, opts *SolveOptions) (result *Result, err error) {
+	ctx, span := tracing.Start(ctx, "solver.solve", attribute.String("flaresolverr.domain", extractDomainFromURL(opts.URL)))
+	defer func() { tracing.End(span, err) }()
+	ctx = s.withResponseLimits(ctx, opts)
+
 	// Fix #24: Panic recovery to catch browser-level panics
 	defer func() {
 		if r := recover(); r != nil {
-			log.Error().
+			log.Ctx(ctx).Error().
 				Interface("panic", r).
 				Str("url", opts.URL).
 				Msg("Panic recovered in Solve")
@@ -355,11 +607,23 @@ func (s *Solver) Solve(ctx context.Context, opts *SolveOptions) (result *Result,
 	// Ensure minimum timeout of 1 second for realistic operation
 	timeout := opts.Timeout
 	if timeout < time.Second {
-		log.Warn().Dur("requested", timeout).Msg("Timeout too short, using 1 second minimum")
+		log.Ctx(ctx).Warn().Dur("requested", timeout).Msg("Timeout too short, using 1 second minimum")
 		timeout = time.Second
 	}
 
-	log.Info().
+	if bp := opts.Profile; bp != nil {
+		if opts.UserAgent == "" {
+			opts.UserAgent = bp.UserAgent
+		}
+		if opts.Fingerprint == nil && bp.Fingerprint != "" {
+			opts.Fingerprint = &types.FingerprintConfig{Profile: bp.Fingerprint}
+		}
+		if bp.Timezone != "" {
+			opts.DefaultTimezone = bp.Timezone
+		}
+	}
+
+	log.Ctx(ctx).Info().
 		Str("url", opts.URL).
 		Dur("timeout", timeout).
 		Bool("is_post", opts.IsPost).
@@ -381,7 +645,7 @@ func (s *Solver) Solve(ctx context.Context, opts *SolveOptions) (result *Result,
 	if opts.Proxy == nil && s.egressPool != nil {
 		if p := s.egressPool.Select(cacheDomain); p != nil {
 			opts.Proxy = p
-			log.Info().
+			log.Ctx(ctx).Info().
 				Str("domain", cacheDomain).
 				Str("egress", proxyID(p)).
 				Msg("Selected sticky clean egress")
@@ -389,14 +653,15 @@ func (s *Solver) Solve(ctx context.Context, opts *SolveOptions) (result *Result,
 	}
 
 	cacheEgress := proxyID(opts.Proxy)
-	cacheEligible := s.clearanceCache != nil && !opts.IsPost && cacheDomain != ""
+	cacheEligible := s.clearanceCache != nil && !opts.IsPost && opts.Method == "" && cacheDomain != ""
+	var cachedClearance *ClearanceEntry
 	if cacheEligible {
-		if e := s.clearanceCache.Get(cacheDomain, cacheEgress); e != nil &&
-			(opts.UserAgent == "" || opts.UserAgent == e.userAgent) {
+		if e := s.clearanceCache.Get(cacheDomain, cacheEgress, opts.UserAgent); e != nil {
 			// cf_clearance is IP+UA bound — reuse the exact UA that minted it.
+			cachedClearance = e
 			opts.UserAgent = e.userAgent
 			opts.Cookies = append(append([]types.RequestCookie{}, e.cookies...), opts.Cookies...)
-			log.Info().
+			log.Ctx(ctx).Info().
 				Str("domain", cacheDomain).
 				Str("egress", cacheEgress).
 				Msg("Injected cached cf_clearance (clearance-cache fast path)")
@@ -409,101 +674,138 @@ func (s *Solver) Solve(ctx context.Context, opts *SolveOptions) (result *Result,
 		}()
 	}
 
-	// Acquire browser - use dedicated browser for per-request proxy, pooled otherwise
+	// Layer-3: with clearance in hand, a plain GET may not need a browser
+	if fast := s.serveFromFastPath(ctx, opts, cachedClearance); fast != nil {
+		return fast, nil
+	}
+
+	// Acquire browser - use dedicated browser for per-request proxy or
+	// profile, pooled otherwise
 	var browserInstance *rod.Browser
 	var usePooledBrowser bool
 
-	if opts.Proxy != nil && opts.Proxy.URL != "" {
-		// Per-request proxy: spawn dedicated browser with this proxy
-		// This browser is NOT pooled and will be closed after use
+	var proxyURL string
+	if opts.Proxy != nil {
+		// SOCKS5 credentials travel in the URL to the pool's forwarder
+		proxyURL = browser.ProxyLaunchURL(opts.Proxy.URL, opts.Proxy.Username, opts.Proxy.Password)
+	}
+	_, acquireSpan := tracing.Start(ctx, "browser.acquire", attribute.Bool("browser.dedicated", proxyURL != "" || opts.Profile != nil))
+	if proxyURL != "" || opts.Profile != nil {
+		// Use a dedicated browser with this proxy and profile, kept warm in
+		// a matching sub-pool between requests (PROXY_POOL_SIZE)
 		// Use redacted proxy URL in logs to prevent credential exposure
 		// Note: Intentionally not logging auth presence to prevent information disclosure
-		log.Info().
-			Str("proxy_url", security.RedactProxyURL(opts.Proxy.URL)).
-			Msg("Spawning dedicated browser with per-request proxy")
+		profileName := ""
+		if opts.Profile != nil {
+			profileName = opts.Profile.Name
+		}
+		log.Ctx(ctx).Info().
+			Str("proxy_url", security.RedactProxyURL(proxyURL)).
+			Str("profile", profileName).
+			Msg("Using dedicated browser with per-request proxy or profile")
 		// Fix HIGH: Use separate variable name to avoid shadowing the outer 'err'
 		// which is used by panic recovery
 		var spawnErr error
-		browserInstance, spawnErr = s.pool.SpawnWithProxy(ctx, opts.Proxy.URL)
+		browserInstance, spawnErr = s.pool.AcquireDedicated(ctx, opts.Profile, proxyURL)
+		tracing.End(acquireSpan, spawnErr)
 		if spawnErr != nil {
-			return nil, fmt.Errorf("failed to spawn browser with proxy: %w", spawnErr)
+			return nil, fmt.Errorf("failed to spawn dedicated browser: %w", spawnErr)
 		}
-		defer s.pool.CleanupBrowser(browserInstance)
+		defer s.pool.ReleaseDedicated(browserInstance)
 		usePooledBrowser = false
 	} else {
 		// No per-request proxy: use pooled browser (may have default proxy from config)
-		log.Debug().Msg("Using pooled browser (no per-request proxy specified)")
+		log.Ctx(ctx).Debug().Msg("Using pooled browser (no per-request proxy specified)")
 		// Fix HIGH: Use separate variable name to avoid shadowing the outer 'err'
 		// which is used by panic recovery
 		var acquireErr error
-		browserInstance, acquireErr = s.pool.Acquire(ctx)
+		browserInstance, acquireErr = s.pool.AcquirePriority(ctx, opts.Priority)
+		tracing.End(acquireSpan, acquireErr)
 		if acquireErr != nil {
 			return nil, types.NewPoolAcquireError("failed to acquire browser", acquireErr)
 		}
 		defer s.pool.Release(browserInstance)
 		usePooledBrowser = true
+		s.pool.RotateRequestIdentity(browserInstance)
+		s.applyBrowserIdentity(browserInstance, opts)
 	}
 
-	_ = usePooledBrowser // Used for logging/debugging if needed
+	// Pooled browsers may hand over the tab the previous request left parked
+	reusePage := s.pageReusable(opts, usePooledBrowser)
 
 	// Create timeout context for the solve operation
-	solveCtx, cancel := context.WithTimeout(ctx, timeout)
+	solveCtx, cancel := context.WithTimeout(withPollOptions(withTurnstileMethods(withSolveProxy(s.withPreferredProvider(ctx, extractDomainFromURL(opts.URL)), opts.Proxy), opts.TurnstileMethods), opts.Poll), timeout)
 	defer cancel()
 
 	var page *rod.Page
 
+	// recordSolve: the paths below start the recorder once their page exists;
+	// it is finished here with the solve's outcome. The page may already be
+	// closed by then, but the frames are kept as they arrive.
+	var recording *solveRecorder
+	defer func() { s.finishRecording(recording, opts, result, err) }()
+
 	// For POST requests, we need a special approach because stealth scripts
 	// conflict with form creation JavaScript. We use a regular page and
-	// apply stealth manually after the POST navigation.
-	if opts.IsPost && opts.PostData != "" {
+	// apply stealth manually after the POST navigation. PUT/PATCH/DELETE share
+	// this path since they are issued via the in-page Fetch API.
+	if opts.Method != "" || (opts.IsPost && opts.PostData != "") {
 		// Fix 2.10: Use stealth.Page for POST requests too - apply stealth before navigation
 		// The previous concern about conflicts was resolved by proper ordering
-		page, err = stealth.Page(browserInstance)
+		var reused bool
+		page, reused, err = s.stealthPage(ctx, browserInstance, reusePage)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create stealth page for POST: %w", err)
 		}
-		defer page.Close()
+		defer s.releasePage(browserInstance, page, reusePage)
 
 		// Layer our custom stealth over go-rod/stealth. go-rod/stealth alone
 		// reports a macOS WebGL renderer on Linux and leaves screen at the
 		// headless 800x600 default — both bot tells. ApplyStealthToPage fixes the
 		// WebGL/OS consistency and screen geometry (registered after go-rod/stealth
-		// so it wins). See docs/INVESTIGATION-fingerprint-gate2.md.
-		if err := browser.ApplyGate2Corrections(page); err != nil {
-			log.Warn().Err(err).Msg("Failed to apply gate-2 fingerprint corrections (POST)")
+		// so it wins). See docs/INVESTIGATION-fingerprint-gate2.md. A reused tab
+		// has them already.
+		if !reused {
+			if err := browser.ApplyGate2Corrections(page); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("Failed to apply gate-2 fingerprint corrections (POST)")
+			}
 		}
 
 		// Install the turnstile.render interceptor before navigation so managed
 		// challenges expose their sitekey/action/cData/chlPageData to the external
 		// solver. Only when an external solver chain can consume them.
-		if s.solverChain != nil {
+		if s.solverChain != nil && s.features.Enabled(features.TurnstileInterceptor, extractDomainFromURL(opts.URL)) {
 			captcha.InstallTurnstileInterceptor(page)
 			defer captcha.RemoveTurnstileInterceptor(page)
 		}
 
 		if tz := resolveTimezone(opts); tz != "" {
 			if err := browser.ApplyTimezoneOverride(page, tz); err != nil {
-				log.Warn().Err(err).Str("timezone", tz).Msg("Failed to apply timezone override")
+				log.Ctx(ctx).Warn().Err(err).Str("timezone", tz).Msg("Failed to apply timezone override")
 			}
 		}
 
-		// Set user agent
-		if s.userAgent != "" {
-			if err := browser.SetUserAgent(page, s.userAgent); err != nil {
-				log.Warn().Err(err).Msg("Failed to set user agent")
+		// Set user agent — per-request override (or rotated browser identity) takes priority
+		ua := s.userAgent
+		if opts.UserAgent != "" {
+			ua = opts.UserAgent
+		}
+		if ua != "" {
+			if err := browser.SetUserAgent(page, ua); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("Failed to set user agent")
 			}
 		}
 
-		// Set viewport
-		if err := browser.SetViewport(page, 1920, 1080); err != nil {
-			log.Warn().Err(err).Msg("Failed to set viewport")
-		}
+		applyLocaleAndViewport(ctx, page, opts, ua)
 
-		// Set up media blocking if requested
-		if opts.DisableMedia {
-			mediaCleanup := setupMediaBlocking(page)
-			defer mediaCleanup()
-			log.Debug().Msg("Media blocking enabled")
+		// Block media and blockUrls requests if asked to
+		if blocksRequests(opts) {
+			blockCleanup := setupRequestBlocking(page, opts)
+			defer blockCleanup()
+			log.Ctx(ctx).Debug().
+				Bool("disable_media", opts.DisableMedia).
+				Int("block_patterns", len(opts.BlockURLs)).
+				Msg("Request blocking enabled")
 		}
 
 		// Fix #13: Use helper for proxy setup to reduce duplication
@@ -516,67 +818,82 @@ func (s *Solver) Solve(ctx context.Context, opts *SolveOptions) (result *Result,
 		// Set cookies before navigation
 		if len(opts.Cookies) > 0 {
 			if err := s.setCookies(page, opts.Cookies, opts.URL); err != nil {
-				log.Warn().Err(err).Msg("Failed to set cookies")
+				log.Ctx(ctx).Warn().Err(err).Msg("Failed to set cookies")
+			}
+		}
+
+		if opts.VerifyProxy {
+			egressIP, err := s.verifyEgress(solveCtx, page)
+			if err != nil {
+				return nil, err
 			}
+			defer func() {
+				if result != nil {
+					result.EgressIP = egressIP
+				}
+			}()
 		}
 
 		// Set up network capture BEFORE navigation to capture response events
 		networkCapture, networkCleanup, err := setupNetworkCapture(solveCtx, page)
 		if err != nil {
-			log.Warn().Err(err).Msg("Failed to setup network capture, using defaults")
+			log.Ctx(ctx).Warn().Err(err).Msg("Failed to setup network capture, using defaults")
 		}
 		defer networkCleanup()
+		networkCapture.SetCapturePatterns(opts.CaptureRequests)
+		if opts.ReturnHar {
+			networkCapture.EnableHAR()
+		}
+		recording = s.startRecording(solveCtx, page, opts)
 
-		// Dispatch POST based on content type
-		if opts.ContentType == types.ContentTypeJSON {
-			// JSON POST via Fetch API
-			if err := s.navigatePostJSON(solveCtx, page.Context(solveCtx), opts.URL, opts.PostData, opts.Headers); err != nil {
-				return nil, fmt.Errorf("JSON POST navigation to %s failed: %w", opts.URL, err)
-			}
-		} else {
-			// Form POST (default, backward compatible)
-			if err := s.navigatePost(solveCtx, page.Context(solveCtx), opts.URL, opts.PostData); err != nil {
-				return nil, fmt.Errorf("form POST navigation to %s failed: %w", opts.URL, err)
-			}
+		if err := s.dispatchBodyRequest(solveCtx, page.Context(solveCtx), opts, networkCapture); err != nil {
+			return nil, err
 		}
 
 		// Wait for initial load
 		if err := page.Context(solveCtx).WaitLoad(); err != nil {
-			log.Warn().Err(err).Msg("WaitLoad failed, continuing anyway")
+			log.Ctx(ctx).Warn().Err(err).Msg("WaitLoad failed, continuing anyway")
 		}
 
 		// Main solve loop with DNS pinning
-		return s.solveLoop(solveCtx, page, opts.URL, opts.Screenshot, opts.ExpectedIP, opts.TabsTillVerify, opts.SkipResponseValidation, networkCapture, opts.CookieExtractDelay)
+		result, err = s.solveLoop(solveCtx, page, opts.URL, opts.screenshot(), opts.ExpectedIP, opts.TabsTillVerify, opts.SkipResponseValidation, networkCapture, opts.CookieExtractDelay)
+		if err != nil {
+			return nil, err
+		}
+		result.UserAgent = ua
+		return result, nil
 	}
 
 	// GET request path
 	// For GET requests, use stealth page
-	page, err = stealth.Page(browserInstance)
+	page, reused, err := s.stealthPage(ctx, browserInstance, reusePage)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create stealth page: %w", err)
 	}
-	defer page.Close()
+	defer s.releasePage(browserInstance, page, reusePage)
 
 	// Layer our custom stealth over go-rod/stealth. go-rod/stealth alone reports a
 	// macOS WebGL renderer on Linux and leaves screen at the headless 800x600
 	// default — both bot tells. ApplyStealthToPage fixes WebGL/OS consistency and
 	// screen geometry (registered after go-rod/stealth so it wins).
-	// See docs/INVESTIGATION-fingerprint-gate2.md.
-	if err := browser.ApplyGate2Corrections(page); err != nil {
-		log.Warn().Err(err).Msg("Failed to apply gate-2 fingerprint corrections (GET)")
+	// See docs/INVESTIGATION-fingerprint-gate2.md. A reused tab has them already.
+	if !reused {
+		if err := browser.ApplyGate2Corrections(page); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("Failed to apply gate-2 fingerprint corrections (GET)")
+		}
 	}
 
 	// Install the turnstile.render interceptor before navigation so managed
 	// challenges expose their sitekey/action/cData/chlPageData to the external
 	// solver. Only when an external solver chain can consume them.
-	if s.solverChain != nil {
+	if s.solverChain != nil && s.features.Enabled(features.TurnstileInterceptor, extractDomainFromURL(opts.URL)) {
 		captcha.InstallTurnstileInterceptor(page)
 		defer captcha.RemoveTurnstileInterceptor(page)
 	}
 
 	if tz := resolveTimezone(opts); tz != "" {
 		if err := browser.ApplyTimezoneOverride(page, tz); err != nil {
-			log.Warn().Err(err).Str("timezone", tz).Msg("Failed to apply timezone override")
+			log.Ctx(ctx).Warn().Err(err).Str("timezone", tz).Msg("Failed to apply timezone override")
 		}
 	}
 
@@ -584,24 +901,24 @@ func (s *Solver) Solve(ctx context.Context, opts *SolveOptions) (result *Result,
 	ua := s.userAgent
 	if opts.UserAgent != "" {
 		ua = opts.UserAgent
-		log.Debug().Str("user_agent", ua).Msg("Using per-request User-Agent override")
+		log.Ctx(ctx).Debug().Str("user_agent", ua).Msg("Using per-request User-Agent override")
 	}
 	if ua != "" {
 		if err := browser.SetUserAgent(page, ua); err != nil {
-			log.Warn().Err(err).Msg("Failed to set user agent")
+			log.Ctx(ctx).Warn().Err(err).Msg("Failed to set user agent")
 		}
 	}
 
-	// Set viewport
-	if err := browser.SetViewport(page, 1920, 1080); err != nil {
-		log.Warn().Err(err).Msg("Failed to set viewport")
-	}
+	applyLocaleAndViewport(ctx, page, opts, ua)
 
-	// Set up media blocking if requested
-	if opts.DisableMedia {
-		mediaCleanup := setupMediaBlocking(page)
-		defer mediaCleanup()
-		log.Debug().Msg("Media blocking enabled")
+	// Block media and blockUrls requests if asked to
+	if blocksRequests(opts) {
+		blockCleanup := setupRequestBlocking(page, opts)
+		defer blockCleanup()
+		log.Ctx(ctx).Debug().
+			Bool("disable_media", opts.DisableMedia).
+			Int("block_patterns", len(opts.BlockURLs)).
+			Msg("Request blocking enabled")
 	}
 
 	// Fix #13: Use helper for proxy setup to reduce duplication
@@ -614,27 +931,45 @@ func (s *Solver) Solve(ctx context.Context, opts *SolveOptions) (result *Result,
 	// Set cookies before navigation
 	if len(opts.Cookies) > 0 {
 		if err := s.setCookies(page, opts.Cookies, opts.URL); err != nil {
-			log.Warn().Err(err).Msg("Failed to set cookies")
+			log.Ctx(ctx).Warn().Err(err).Msg("Failed to set cookies")
+		}
+	}
+
+	// Confirm the proxy is in use before the target sees the browser
+	if opts.VerifyProxy {
+		egressIP, err := s.verifyEgress(solveCtx, page)
+		if err != nil {
+			return nil, err
 		}
+		defer func() {
+			if result != nil {
+				result.EgressIP = egressIP
+			}
+		}()
 	}
 
 	// Set up network capture BEFORE navigation to capture response events
 	networkCapture, networkCleanup, err := setupNetworkCapture(solveCtx, page)
 	if err != nil {
-		log.Warn().Err(err).Msg("Failed to setup network capture, using defaults")
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to setup network capture, using defaults")
 	}
 	defer networkCleanup()
+	networkCapture.SetCapturePatterns(opts.CaptureRequests)
+	if opts.ReturnHar {
+		networkCapture.EnableHAR()
+	}
+	recording = s.startRecording(solveCtx, page, opts)
 
 	// Set custom headers before navigation (for GET requests)
 	if len(opts.Headers) > 0 {
 		if err := s.setCustomHeaders(page, opts.Headers); err != nil {
-			log.Warn().Err(err).Msg("Failed to set custom headers")
+			log.Ctx(ctx).Warn().Err(err).Msg("Failed to set custom headers")
 		}
 	}
 
 	// Handle followRedirects=false — capture first response via JS fetch with redirect:manual
 	if opts.FollowRedirects != nil && !*opts.FollowRedirects {
-		log.Debug().Str("url", opts.URL).Msg("followRedirects=false: using fetch with redirect:manual")
+		log.Ctx(ctx).Debug().Str("url", opts.URL).Msg("followRedirects=false: using fetch with redirect:manual")
 
 		escapedURL := strings.ReplaceAll(opts.URL, "'", "\\'")
 		noRedirectJS := `async function() {
@@ -701,7 +1036,7 @@ func (s *Solver) Solve(ctx context.Context, opts *SolveOptions) (result *Result,
 	if solveCtx.Err() != nil {
 		return nil, fmt.Errorf("context canceled before navigation: %w", solveCtx.Err())
 	}
-	if err := page.Context(solveCtx).Navigate(opts.URL); err != nil {
+	if err := navigate(solveCtx, page, opts.URL); err != nil {
 		// Fix 2.6: Check if context was canceled to provide better error message
 		if solveCtx.Err() != nil {
 			return nil, fmt.Errorf("navigation timed out for %s: %w", opts.URL, solveCtx.Err())
@@ -711,16 +1046,16 @@ func (s *Solver) Solve(ctx context.Context, opts *SolveOptions) (result *Result,
 
 	// Wait for initial load
 	if err := page.Context(solveCtx).WaitLoad(); err != nil {
-		log.Warn().Err(err).Msg("WaitLoad failed, continuing anyway")
+		log.Ctx(ctx).Warn().Err(err).Msg("WaitLoad failed, continuing anyway")
 	}
 
 	// Return raw HTML before JS rendering if requested
 	if opts.ReturnRawHtml {
 		rawHTML, htmlErr := page.HTML()
 		if htmlErr != nil {
-			log.Warn().Err(htmlErr).Msg("Failed to get raw HTML")
+			log.Ctx(ctx).Warn().Err(htmlErr).Msg("Failed to get raw HTML")
 		} else {
-			log.Debug().Int("length", len(rawHTML)).Msg("Returning raw HTML (before JS rendering)")
+			log.Ctx(ctx).Debug().Int("length", len(rawHTML)).Msg("Returning raw HTML (before JS rendering)")
 			return &Result{
 				Success:    true,
 				StatusCode: 200,
@@ -731,15 +1066,25 @@ func (s *Solver) Solve(ctx context.Context, opts *SolveOptions) (result *Result,
 		}
 	}
 
-	// Main solve loop with DNS pinning
-	result, err = s.solveLoop(solveCtx, page, opts.URL, opts.Screenshot, opts.ExpectedIP, opts.TabsTillVerify, opts.SkipResponseValidation, networkCapture, opts.CookieExtractDelay)
+	// A still-valid cached clearance lands straight on the content; skip the
+	// challenge wait entirely. Otherwise fall through to the main solve loop.
+	if cachedClearance != nil && s.acceptCachedClearance(page, cacheDomain, cacheEgress, cachedClearance) {
+		result, err = s.buildResult(ctx, page, opts.URL, opts.screenshot(), opts.ExpectedIP, opts.SkipResponseValidation, networkCapture, opts.CookieExtractDelay)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Main solve loop with DNS pinning
+		result, err = s.solveLoop(solveCtx, page, opts.URL, opts.screenshot(), opts.ExpectedIP, opts.TabsTillVerify, opts.SkipResponseValidation, networkCapture, opts.CookieExtractDelay)
+	}
 	if err != nil {
-		// If the challenge timed out (or native Turnstile solving was exhausted early)
+		// If the challenge timed out (or native Turnstile solving was exhausted early,
+		// or the captcha spend budget stopped the external fallback)
 		// and we still have time in the parent context, try the disconnect/reconnect
 		// approach. This launches a clean Chrome without CDP so Cloudflare can't detect
 		// it, lets Chrome handle the challenge naturally, then reconnects to extract results.
-		if (strings.Contains(err.Error(), "timed out") || strings.Contains(err.Error(), "turnstile_early_bypass")) && ctx.Err() == nil {
-			log.Info().Msg("Normal solve timed out, attempting CDP disconnect/reconnect bypass")
+		if (strings.Contains(err.Error(), "timed out") || strings.Contains(err.Error(), "turnstile_early_bypass") || errors.Is(err, types.ErrCaptchaBudgetExceeded)) && ctx.Err() == nil {
+			log.Ctx(ctx).Info().Msg("Normal solve timed out, attempting CDP disconnect/reconnect bypass")
 			reconnResult, reconnErr := s.solveWithReconnect(ctx, browserInstance, opts)
 			if reconnErr == nil {
 				// Force-recycle the pool browser since it was held for a long
@@ -747,29 +1092,58 @@ func (s *Solver) Solve(ctx context.Context, opts *SolveOptions) (result *Result,
 				s.pool.RecycleBrowser(browserInstance)
 				return reconnResult, nil
 			}
-			log.Warn().Err(reconnErr).Msg("Reconnect bypass also failed")
+			log.Ctx(ctx).Warn().Err(reconnErr).Msg("Reconnect bypass also failed")
 		}
 		return nil, fmt.Errorf("solve loop failed for %s: %w", opts.URL, err)
 	}
+	result.UserAgent = ua
 
 	// Post-solve processing: download re-fetch, custom JS, waitInSeconds.
-	s.applyPostSolveProcessing(solveCtx, page, opts, result)
+	if err := s.applyPostSolveProcessing(solveCtx, page, opts, networkCapture, result); err != nil {
+		return nil, err
+	}
 
 	return result, nil
 }
 
 // applyPostSolveProcessing runs the post-solve steps shared by Solve and
 // SolveWithPage so the session and non-session paths stay in sync:
-// download-mode re-fetch, custom JS execution (executeJs), and the optional
-// waitInSeconds delay with a cookie re-fetch afterward.
-func (s *Solver) applyPostSolveProcessing(ctx context.Context, page *rod.Page, opts *SolveOptions, result *Result) {
+// post-clearance client redirects, the second-order XHR challenge check,
+// waitForSelector/waitForText, post-solve actions, download-mode re-fetch,
+// custom JS execution (executeJs, evaluateJs), the optional waitInSeconds
+// delay with a cookie re-fetch afterward, and the captured XHR/fetch
+// responses.
+func (s *Solver) applyPostSolveProcessing(ctx context.Context, page *rod.Page, opts *SolveOptions, networkCapture *NetworkCapture, result *Result) error {
 	if result == nil {
-		return
+		return nil
+	}
+
+	// Follow meta-refresh/JS bounce pages first so the steps below see the real destination
+	s.applyClientRedirects(ctx, page, opts, result)
+
+	// The document may be cleared while its XHR/fetch calls are still challenged
+	if err := s.applyXHRWatch(ctx, page, opts, networkCapture, result); err != nil {
+		return err
+	}
+
+	// Let a client-rendered page fill in before anything reads it
+	if err := s.applyWaitFor(ctx, page, opts, result); err != nil {
+		return err
+	}
+
+	// Solve an embedded reCAPTCHA so executeJs can submit the form it guards
+	if opts.SolveRecaptcha {
+		s.applyRecaptcha(ctx, page, result)
+	}
+
+	// Drive the page (search forms, filters) before anything else reads it
+	if err := s.applyActions(ctx, page, opts, networkCapture, result); err != nil {
+		return err
 	}
 
 	// Download mode: re-fetch the URL via Fetch API and return base64
 	if opts.Download {
-		log.Info().Str("url", opts.URL).Msg("Download mode: fetching URL as binary via Fetch API")
+		log.Ctx(ctx).Info().Str("url", opts.URL).Msg("Download mode: fetching URL as binary via Fetch API")
 		escapedURL := strings.ReplaceAll(opts.URL, "'", "\\'")
 		escapedURL = strings.ReplaceAll(escapedURL, "\\", "\\\\")
 		downloadJS := `async function() {
@@ -789,37 +1163,40 @@ func (s *Solver) applyPostSolveProcessing(ctx context.Context, page *rod.Page, o
 
 		downloadResult, evalErr := page.Timeout(30 * time.Second).Evaluate(rod.Eval(downloadJS).ByPromise())
 		if evalErr != nil {
-			log.Warn().Err(evalErr).Msg("Download fetch failed")
+			log.Ctx(ctx).Warn().Err(evalErr).Msg("Download fetch failed")
 		} else {
 			b64 := downloadResult.Value.Str()
 			if strings.HasPrefix(b64, "ERROR:") {
-				log.Warn().Str("error", b64).Msg("Download fetch returned error")
+				log.Ctx(ctx).Warn().Str("error", b64).Msg("Download fetch returned error")
 			} else {
 				result.HTML = b64
 				result.ResponseEncoding = "base64"
-				log.Info().Int("base64_length", len(b64)).Msg("Download complete")
+				log.Ctx(ctx).Info().Int("base64_length", len(b64)).Msg("Download complete")
 			}
 		}
 	}
 
 	// Execute custom JavaScript if provided
 	if opts.ExecuteJs != "" {
-		log.Debug().Int("js_length", len(opts.ExecuteJs)).Msg("Executing custom JavaScript")
+		log.Ctx(ctx).Debug().Int("js_length", len(opts.ExecuteJs)).Msg("Executing custom JavaScript")
 		jsResult, evalErr := page.Timeout(10 * time.Second).Eval(fmt.Sprintf(`() => { %s }`, opts.ExecuteJs))
 		if evalErr != nil {
-			log.Warn().Err(evalErr).Msg("Custom JS execution failed")
+			log.Ctx(ctx).Warn().Err(evalErr).Msg("Custom JS execution failed")
 			result.ExecuteJsResult = "ERROR: " + evalErr.Error()
 		} else if jsResult != nil {
 			result.ExecuteJsResult = jsResult.Value.String()
 		}
 	}
 
+	// Evaluate the script whose value is returned as jsResult
+	s.applyEvaluateJs(ctx, page, opts, result)
+
 	// Wait additional time if requested (waitInSeconds)
 	if opts.WaitInSeconds > 0 {
 		waitDuration := time.Duration(opts.WaitInSeconds) * time.Second
-		log.Debug().Int("seconds", opts.WaitInSeconds).Msg("Waiting additional time before returning")
+		log.Ctx(ctx).Debug().Int("seconds", opts.WaitInSeconds).Msg("Waiting additional time before returning")
 		if !sleepWithContext(ctx, waitDuration) {
-			log.Warn().Msg("Wait interrupted by context cancellation")
+			log.Ctx(ctx).Warn().Msg("Wait interrupted by context cancellation")
 		}
 
 		// Re-fetch cookies after wait — JavaScript may set cookies during the delay
@@ -827,77 +1204,113 @@ func (s *Solver) applyPostSolveProcessing(ctx context.Context, page *rod.Page, o
 		freshCookies, err := proto.NetworkGetAllCookies{}.Call(page)
 		if err == nil && freshCookies != nil {
 			result.Cookies = freshCookies.Cookies
-			log.Debug().Int("cookies", len(freshCookies.Cookies)).Msg("Re-fetched cookies after waitInSeconds")
+			log.Ctx(ctx).Debug().Int("cookies", len(freshCookies.Cookies)).Msg("Re-fetched cookies after waitInSeconds")
 		}
 	}
+
+	// Render last so the PDF and snapshot show the page as returned
+	s.applyPdf(ctx, page, opts, result)
+	s.applySnapshot(ctx, page, opts, result)
+
+	// Everything the page fetched up to now, including during the steps above
+	result.CapturedRequests = networkCapture.CapturedRequests()
+	if har, err := networkCapture.HAR(); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to build HAR archive")
+	} else {
+		result.HAR = har
+	}
+	return nil
 }
 
-// solveHCaptchaExternal uses external CAPTCHA solvers to solve an hCaptcha challenge.
-func (s *Solver) solveHCaptchaExternal(ctx context.Context, page *rod.Page, pageURL string) error {
+// tokenVerifyTimeout is how long the page gets to react to an injected
+// external token, before and again after it is re-delivered via callbacks.
+const tokenVerifyTimeout = 5 * time.Second
+
+// solveHCaptchaExternal submits an hCaptcha challenge to the external solver
+// chain, which extracts the sitekey and injects the token into
+// h-captcha-response. There is no native hCaptcha solving.
+func (s *Solver) solveHCaptchaExternal(ctx context.Context, page *rod.Page, pageURL string) (err error) {
+	ctx, span := tracing.Start(ctx, "solver.hcaptcha.external")
+	defer func() { tracing.End(span, err) }()
+
 	if s.solverChain == nil {
-		return fmt.Errorf("no solver chain configured")
-	}
-
-	// Extract hCaptcha sitekey from the page
-	sitekey, err := page.Timeout(5 * time.Second).Eval(`() => {
-		// Try data-sitekey attribute
-		const el = document.querySelector('[data-sitekey]');
-		if (el) return el.getAttribute('data-sitekey');
-		// Try hcaptcha iframe src
-		const iframe = document.querySelector('iframe[src*="hcaptcha.com"]');
-		if (iframe) {
-			const url = new URL(iframe.src);
-			return url.searchParams.get('sitekey') || '';
-		}
-		return '';
-	}`)
-	if err != nil || sitekey.Value.Str() == "" {
-		return fmt.Errorf("could not extract hCaptcha sitekey")
-	}
-
-	log.Info().Str("sitekey", sitekey.Value.Str()[:10]+"...").Msg("Extracted hCaptcha sitekey")
-
-	// Use the first configured provider that supports hCaptcha
-	// All 3 providers (2captcha, capsolver, anticaptcha) support HCaptchaTaskProxyless
-	for _, provider := range s.solverChain.GetProviders() {
-		// Type-assert to check if provider has SolveHCaptcha
-		type hcaptchaSolver interface {
-			SolveHCaptcha(ctx context.Context, req *captcha.HCaptchaRequest) (*captcha.CaptchaResult, error)
-		}
-		if hs, ok := provider.(hcaptchaSolver); ok {
-			result, err := hs.SolveHCaptcha(ctx, &captcha.HCaptchaRequest{
-				SiteKey:   sitekey.Value.Str(),
-				PageURL:   pageURL,
-				UserAgent: s.userAgent,
-			})
-			if err != nil {
-				log.Warn().Err(err).Str("provider", provider.Name()).Msg("hCaptcha solve failed")
-				continue
-			}
+		return fmt.Errorf("solver chain not configured")
+	}
 
-			// Inject the token
-			_, injectErr := page.Eval(fmt.Sprintf(`() => {
-				// Set h-captcha-response textarea
-				const textarea = document.querySelector('[name="h-captcha-response"], [id="h-captcha-response"]');
-				if (textarea) { textarea.value = '%s'; textarea.dispatchEvent(new Event('input')); }
-				// Set g-recaptcha-response (hCaptcha uses this too)
-				const grecaptcha = document.querySelector('[name="g-recaptcha-response"]');
-				if (grecaptcha) { grecaptcha.value = '%s'; }
-				// Try to invoke callback
-				if (window.hcaptcha && window.hcaptcha.execute) {
-					try { window.hcaptcha.execute(); } catch(e) {}
-				}
-			}`, result.Token, result.Token))
-			if injectErr != nil {
-				log.Warn().Err(injectErr).Msg("Failed to inject hCaptcha token")
-			} else {
-				log.Info().Str("provider", result.Provider).Dur("solve_time", result.SolveTime).Msg("hCaptcha solved via external provider")
-				return nil
-			}
+	result, err := s.solverChain.SolveHCaptcha(ctx, page, pageURL, s.userAgent)
+	if err != nil {
+		return fmt.Errorf("external hCaptcha solver failed: %w", err)
+	}
+
+	log.Ctx(ctx).Info().
+		Str("provider", result.Provider).
+		Dur("solve_time", result.SolveTime).
+		Float64("cost", result.Cost).
+		Bool("injected", result.Injected).
+		Msg("hCaptcha solved via external provider")
+
+	// Make sure the page took the token, re-delivering it if not
+	if result.Injected {
+		if err := captcha.VerifyInjectedToken(ctx, page, captcha.TokenHCaptcha, result.Token, tokenVerifyTimeout); err != nil {
+			return fmt.Errorf("external hCaptcha token not accepted: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// solveRecaptchaExternal submits a reCAPTCHA v2 or v3 challenge to the
+// external solver chain, which injects the token into g-recaptcha-response
+// and fires the widget callback. Returns the token. With verify, a token the
+// page does not react to is re-delivered and then fails the solve; a
+// reCAPTCHA embedded in a cleared page only proceeds when the caller submits
+// it, so applyRecaptcha skips the check.
+func (s *Solver) solveRecaptchaExternal(ctx context.Context, page *rod.Page, pageURL string, verify bool) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "solver.recaptcha.external")
+	defer func() { tracing.End(span, err) }()
+
+	if s.solverChain == nil {
+		return "", fmt.Errorf("solver chain not configured")
+	}
+
+	result, err := s.solverChain.SolveRecaptcha(ctx, page, pageURL, s.userAgent)
+	if err != nil {
+		return "", fmt.Errorf("external reCAPTCHA solver failed: %w", err)
+	}
+
+	log.Ctx(ctx).Info().
+		Str("provider", result.Provider).
+		Dur("solve_time", result.SolveTime).
+		Float64("cost", result.Cost).
+		Bool("injected", result.Injected).
+		Msg("reCAPTCHA solved via external provider")
+
+	if result.Injected && verify {
+		if err := captcha.VerifyInjectedToken(ctx, page, captcha.TokenRecaptcha, result.Token, tokenVerifyTimeout); err != nil {
+			return "", fmt.Errorf("external reCAPTCHA token not accepted: %w", err)
 		}
 	}
 
-	return fmt.Errorf("no provider could solve hCaptcha")
+	return result.Token, nil
+}
+
+// applyRecaptcha solves a reCAPTCHA embedded in the cleared page when the
+// request asked for it (solveRecaptcha) and records the token.
+func (s *Solver) applyRecaptcha(ctx context.Context, page *rod.Page, result *Result) {
+	if s.solverChain == nil || !s.solverChain.IsEnabled() {
+		log.Ctx(ctx).Warn().Msg("solveRecaptcha requested but external CAPTCHA solving is not enabled")
+		return
+	}
+	if !isRecaptchaPage(strings.ToLower(result.HTML), s.getSelectors().Captcha) {
+		log.Ctx(ctx).Debug().Msg("solveRecaptcha requested but the page has no reCAPTCHA")
+		return
+	}
+	token, err := s.solveRecaptchaExternal(ctx, page, result.URL, false)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Embedded reCAPTCHA solve failed")
+		return
+	}
+	result.RecaptchaToken = token
 }
 
 // findBrowserBinary resolves the actual browser ELF/Mach-O binary, following
@@ -1013,7 +1426,7 @@ func (s *Solver) solveWithReconnect(ctx context.Context, _ *rod.Browser, opts *S
 	}
 	defer stealthExt.Cleanup()
 
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("url", opts.URL).
 		Str("user_data_dir", userDataDir).
 		Str("stealth_ext", stealthExt.Dir()).
@@ -1022,7 +1435,7 @@ func (s *Solver) solveWithReconnect(ctx context.Context, _ *rod.Browser, opts *S
 	// ========================================
 	// Phase 1: Clean Chrome — no CDP, stealth via extension
 	// ========================================
-	log.Info().Dur("wait", phase1Wait).Msg("Phase 1: Launching Chrome with stealth extension...")
+	log.Ctx(ctx).Info().Dur("wait", phase1Wait).Msg("Phase 1: Launching Chrome with stealth extension...")
 
 	phase1Args := []string{
 		"--no-first-run",
@@ -1034,7 +1447,7 @@ func (s *Solver) solveWithReconnect(ctx context.Context, _ *rod.Browser, opts *S
 		"--load-extension=" + stealthExt.Dir(), // Stealth patches without CDP
 		opts.URL,
 	}
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("browser", browserPath).
 		Strs("args", phase1Args).
 		Msg("Phase 1 command")
@@ -1081,12 +1494,12 @@ func (s *Solver) solveWithReconnect(ctx context.Context, _ *rod.Browser, opts *S
 		return nil, ctx.Err()
 	}
 
-	log.Info().Msg("Phase 1 complete, cookies should be saved")
+	log.Ctx(ctx).Info().Msg("Phase 1 complete, cookies should be saved")
 
 	// ========================================
 	// Phase 2: Relaunch with CDP to extract results
 	// ========================================
-	log.Info().Int("port", debugPort).Msg("Phase 2: Relaunching with CDP for extraction...")
+	log.Ctx(ctx).Info().Int("port", debugPort).Msg("Phase 2: Relaunching with CDP for extraction...")
 
 	cmd2 := exec.Command(browserPath, //nolint:gosec // browserPath is validated by findBrowserBinary
 		"--no-first-run",
@@ -1155,7 +1568,7 @@ func (s *Solver) solveWithReconnect(ctx context.Context, _ *rod.Browser, opts *S
 			!strings.Contains(titleLower, "please wait") &&
 			!strings.Contains(titleLower, "performing security") {
 			targetPage = p
-			log.Info().Str("title", pInfo.Title).Str("url", pInfo.URL).Msg("Challenge resolved via two-phase bypass!")
+			log.Ctx(ctx).Info().Str("title", pInfo.Title).Str("url", pInfo.URL).Msg("Challenge resolved via two-phase bypass!")
 			break
 		}
 	}
@@ -1163,7 +1576,7 @@ func (s *Solver) solveWithReconnect(ctx context.Context, _ *rod.Browser, opts *S
 	if targetPage == nil {
 		for _, p := range pages {
 			if pInfo, err := p.Info(); err == nil {
-				log.Debug().Str("title", pInfo.Title).Str("url", pInfo.URL).Msg("Page found after Phase 2")
+				log.Ctx(ctx).Debug().Str("title", pInfo.Title).Str("url", pInfo.URL).Msg("Page found after Phase 2")
 			}
 		}
 		return nil, fmt.Errorf("challenge not resolved after two-phase bypass (found %d pages)", len(pages))
@@ -1175,11 +1588,11 @@ func (s *Solver) solveWithReconnect(ctx context.Context, _ *rod.Browser, opts *S
 
 	networkCapture, networkCleanup, ncErr := setupNetworkCapture(solveCtx, targetPage)
 	if ncErr != nil {
-		log.Warn().Err(ncErr).Msg("Failed to setup network capture")
+		log.Ctx(ctx).Warn().Err(ncErr).Msg("Failed to setup network capture")
 	}
 	defer networkCleanup()
 
-	return s.buildResult(targetPage, opts.URL, opts.Screenshot, opts.ExpectedIP, opts.SkipResponseValidation, networkCapture, opts.CookieExtractDelay)
+	return s.buildResult(ctx, targetPage, opts.URL, opts.screenshot(), opts.ExpectedIP, opts.SkipResponseValidation, networkCapture, opts.CookieExtractDelay)
 }
 
 // setCookies sets cookies on the page before navigation.
@@ -1223,11 +1636,19 @@ func (s *Solver) setCookies(page *rod.Page, cookies []types.RequestCookie, targe
 	return page.SetCookies(cdpCookies)
 }
 
+// navigate loads targetURL in page, traced as a navigation span.
+func navigate(ctx context.Context, page *rod.Page, targetURL string) error {
+	_, span := tracing.Start(ctx, "solver.navigate", attribute.String("http.request.method", "GET"))
+	err := page.Context(ctx).Navigate(targetURL)
+	tracing.End(span, err)
+	return err
+}
+
 // navigatePost performs a POST request by injecting and submitting a form.
 // This function is called with a regular (non-stealth) page to avoid JS conflicts.
 // Fix: Accept explicit context parameter for proper timeout/cancellation propagation.
 func (s *Solver) navigatePost(ctx context.Context, page *rod.Page, targetURL string, postData string) error {
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("url", targetURL).
 		Int("post_data_len", len(postData)).
 		Msg("Performing POST request")
@@ -1247,7 +1668,7 @@ func (s *Solver) navigatePost(ctx context.Context, page *rod.Page, targetURL str
 
 	// Wait for page to be ready
 	if err := page.WaitLoad(); err != nil {
-		log.Debug().Err(err).Msg("WaitLoad on base URL failed")
+		log.Ctx(ctx).Debug().Err(err).Msg("WaitLoad on base URL failed")
 	}
 
 	// Give the page time to fully initialize, but respect context cancellation
@@ -1297,7 +1718,7 @@ func (s *Solver) navigatePost(ctx context.Context, page *rod.Page, targetURL str
 
 	// Wait for navigation to complete
 	if err := page.WaitLoad(); err != nil {
-		log.Warn().Err(err).Msg("WaitLoad after POST failed, continuing anyway")
+		log.Ctx(ctx).Warn().Err(err).Msg("WaitLoad after POST failed, continuing anyway")
 	}
 
 	return nil
@@ -1355,70 +1776,140 @@ func (s *Solver) buildFormFieldsJS(postData string) (string, error) {
 	return builder.String(), nil
 }
 
+// dispatchBodyRequest issues a request that carries a body: PUT/PATCH/DELETE
+// through the in-page Fetch API, or POST as JSON fetch or form submission
+// depending on the content type. For fetch methods the response status and
+// headers are recorded in networkCapture, since document.write produces no
+// Document network event for the capture listener to observe.
+func (s *Solver) dispatchBodyRequest(ctx context.Context, page *rod.Page, opts *SolveOptions, networkCapture *NetworkCapture) (err error) {
+	method := opts.Method
+	if method == "" {
+		method = "POST"
+	}
+	ctx, span := tracing.Start(ctx, "solver.navigate", attribute.String("http.request.method", method))
+	defer func() { tracing.End(span, err) }()
+
+	switch {
+	case opts.Method != "":
+		contentType := opts.ContentType
+		if contentType == "" {
+			contentType = types.ContentTypeFormURLEncoded
+		}
+		resp, err := s.navigateFetch(ctx, page, opts.Method, opts.URL, opts.PostData, contentType, opts.Headers)
+		if err != nil {
+			return fmt.Errorf("%s request to %s failed: %w", opts.Method, opts.URL, err)
+		}
+		if networkCapture != nil {
+			networkCapture.SetResponse(resp.Status, resp.Headers, opts.URL)
+		}
+	case opts.ContentType == types.ContentTypeJSON:
+		// JSON POST via Fetch API
+		if err := s.navigatePostJSON(ctx, page, opts.URL, opts.PostData, opts.Headers); err != nil {
+			return fmt.Errorf("JSON POST navigation to %s failed: %w", opts.URL, err)
+		}
+	default:
+		// Form POST (default, backward compatible)
+		if err := s.navigatePost(ctx, page, opts.URL, opts.PostData); err != nil {
+			return fmt.Errorf("form POST navigation to %s failed: %w", opts.URL, err)
+		}
+	}
+	return nil
+}
+
 // navigatePostJSON performs a POST request with JSON body using the Fetch API.
 // This is used when contentType is "application/json".
 // Fix: Accept explicit context parameter for proper timeout/cancellation propagation.
 func (s *Solver) navigatePostJSON(ctx context.Context, page *rod.Page, targetURL string, jsonData string, headers map[string]string) error {
-	log.Debug().
+	_, err := s.navigateFetch(ctx, page, "POST", targetURL, jsonData, types.ContentTypeJSON, headers)
+	return err
+}
+
+// fetchResponse is the outcome of an in-page Fetch API request.
+type fetchResponse struct {
+	Status      int               `json:"status"`
+	ContentType string            `json:"contentType"`
+	Headers     map[string]string `json:"headers"`
+}
+
+// navigateFetch performs an arbitrary-method request using the in-page Fetch API
+// and writes the response body into the document so the solve loop and result
+// extraction operate on it. The target origin is loaded first so the request
+// carries the origin's cookies (including cf_clearance).
+// The Content-Type header is only sent when body is non-empty.
+func (s *Solver) navigateFetch(ctx context.Context, page *rod.Page, method, targetURL, body, contentType string, headers map[string]string) (*fetchResponse, error) {
+	log.Ctx(ctx).Debug().
+		Str("method", method).
 		Str("url", targetURL).
-		Int("json_data_len", len(jsonData)).
+		Int("body_len", len(body)).
 		Int("headers_count", len(headers)).
-		Msg("Performing JSON POST request via Fetch API")
+		Msg("Performing request via Fetch API")
 
 	// Parse the URL to get the base domain
 	parsedURL, err := neturl.Parse(targetURL)
 	if err != nil {
-		return fmt.Errorf("invalid URL: %w", err)
+		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Navigate to the target domain first to establish proper page context
 	baseURL := fmt.Sprintf("%s://%s/", parsedURL.Scheme, parsedURL.Host)
 	if err := page.Navigate(baseURL); err != nil {
-		return fmt.Errorf("failed to navigate to base URL: %w", err)
+		return nil, fmt.Errorf("failed to navigate to base URL: %w", err)
 	}
 
 	// Wait for page to be ready
 	if err := page.WaitLoad(); err != nil {
-		log.Debug().Err(err).Msg("WaitLoad on base URL failed")
+		log.Ctx(ctx).Debug().Err(err).Msg("WaitLoad on base URL failed")
 	}
 
 	// Give the page time to fully initialize
 	if !sleepWithContext(ctx, 500*time.Millisecond) {
-		return fmt.Errorf("context canceled during JSON POST navigation: %w", ctx.Err())
+		return nil, fmt.Errorf("context canceled during %s fetch navigation: %w", method, ctx.Err())
 	}
 
 	// Build headers object JavaScript
 	headersJS := s.buildHeadersJS(headers)
 
-	// Safely encode the target URL and JSON data
-	targetURLJSON, err := json.Marshal(targetURL)
+	// Safely encode the method, target URL, body and content type for embedding in JS
+	methodJSON, err := json.Marshal(method)
 	if err != nil {
-		return fmt.Errorf("failed to encode target URL: %w", err)
+		return nil, fmt.Errorf("failed to encode method: %w", err)
 	}
-
-	// The jsonData is already a JSON string, but we need to escape it for embedding in JS
-	jsonDataJS, err := json.Marshal(jsonData)
+	targetURLJSON, err := json.Marshal(targetURL)
 	if err != nil {
-		return fmt.Errorf("failed to encode JSON data: %w", err)
+		return nil, fmt.Errorf("failed to encode target URL: %w", err)
+	}
+	bodyJS := "undefined"
+	contentTypeJS := ""
+	if body != "" {
+		bodyJSON, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+		bodyJS = string(bodyJSON)
+		contentTypeJSON, err := json.Marshal(contentType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode content type: %w", err)
+		}
+		contentTypeJS = fmt.Sprintf("headers.set('Content-Type', %s);", contentTypeJSON)
 	}
 
-	// Use Fetch API to perform the JSON POST request
 	evalResult, err := proto.RuntimeEvaluate{
 		Expression: fmt.Sprintf(`
 			(async function() {
 				try {
-					var headers = new Headers({
-						'Content-Type': 'application/json'
-					});
+					var headers = new Headers();
+					%s
 					%s
 
 					var response = await fetch(%s, {
-						method: 'POST',
+						method: %s,
 						headers: headers,
 						body: %s,
 						credentials: 'include'
 					});
 
+					var respHeaders = {};
+					response.headers.forEach(function(v, k) { respHeaders[k] = v; });
 					var contentType = response.headers.get('content-type') || '';
 					var text = await response.text();
 
@@ -1430,6 +1921,7 @@ func (s *Solver) navigatePostJSON(ctx context.Context, page *rod.Page, targetURL
 					return {
 						status: response.status,
 						contentType: contentType,
+						headers: respHeaders,
 						success: true
 					};
 				} catch(e) {
@@ -1439,42 +1931,45 @@ func (s *Solver) navigatePostJSON(ctx context.Context, page *rod.Page, targetURL
 					};
 				}
 			})()
-		`, headersJS, targetURLJSON, jsonDataJS),
+		`, contentTypeJS, headersJS, targetURLJSON, methodJSON, bodyJS),
 		AwaitPromise:  true,
 		ReturnByValue: true,
 	}.Call(page)
 
 	if err != nil {
-		return fmt.Errorf("failed to execute JSON POST fetch: %w", err)
+		return nil, fmt.Errorf("failed to execute %s fetch: %w", method, err)
 	}
 
 	if evalResult.ExceptionDetails != nil {
-		return fmt.Errorf("fetch exception: %s", evalResult.ExceptionDetails.Text)
+		return nil, fmt.Errorf("fetch exception: %s", evalResult.ExceptionDetails.Text)
 	}
 
 	// Parse the result to check for errors
+	resp := &fetchResponse{}
 	if evalResult.Result.Type == proto.RuntimeRemoteObjectTypeObject {
-		jsonStr := evalResult.Result.Value.String()
-		var result map[string]interface{}
-		if err := json.Unmarshal([]byte(jsonStr), &result); err == nil {
-			if success, ok := result["success"].(bool); ok && !success {
-				if errMsg, ok := result["error"].(string); ok {
-					return fmt.Errorf("fetch failed: %s", errMsg)
+		var result struct {
+			fetchResponse
+			Success bool   `json:"success"`
+			Error   string `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(evalResult.Result.Value.String()), &result); err == nil {
+			if !result.Success {
+				if result.Error != "" {
+					return nil, fmt.Errorf("fetch failed: %s", result.Error)
 				}
-				return fmt.Errorf("fetch failed with unknown error")
-			}
-			if status, ok := result["status"].(float64); ok {
-				log.Debug().Int("status", int(status)).Msg("JSON POST completed")
+				return nil, fmt.Errorf("fetch failed with unknown error")
 			}
+			*resp = result.fetchResponse
+			log.Ctx(ctx).Debug().Str("method", method).Int("status", resp.Status).Msg("Fetch request completed")
 		}
 	}
 
 	// Wait for the document to stabilize
 	if err := page.WaitLoad(); err != nil {
-		log.Warn().Err(err).Msg("WaitLoad after JSON POST failed, continuing anyway")
+		log.Ctx(ctx).Warn().Err(err).Str("method", method).Msg("WaitLoad after fetch failed, continuing anyway")
 	}
 
-	return nil
+	return resp, nil
 }
 
 // buildHeadersJS generates JavaScript code to add custom headers to a Headers object.
@@ -1538,7 +2033,12 @@ var challengeTitles = []string{
 	"attention required",
 }
 
-// Challenge selectors that indicate a challenge is in progress
+// maxCaptchaSolves bounds paid external CAPTCHA submissions per solve;
+// the second covers a token the site rejected.
+const maxCaptchaSolves = 2
+
+// Challenge selectors that indicate a Cloudflare challenge is in progress.
+// Other vendors' selectors come from their registered ChallengeDetector.
 var challengeSelectors = []string{
 	"#cf-challenge-running",
 	".ray_id",
@@ -1565,33 +2065,53 @@ var turnstileTriggerSelectors = map[string]bool{
 
 // solveLoop repeatedly checks for and attempts to solve challenges.
 // Uses the same approach as Python FlareSolverr: check title and selectors.
+// Passes are paced by the domain's PollStrategy (see pollstrategy.go).
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout
+//   - ctx: The solve context, carrying the request's response limits
 //   - page: The browser page
 //   - url: The original request URL
-//   - captureScreenshot: Whether to capture a screenshot
+//   - screenshot: How to capture a screenshot (nil for none)
 //   - expectedIP: The IP resolved during initial validation for DNS pinning (nil to skip)
 //   - tabsTillVerify: Number of Tab presses to reach Turnstile checkbox (0 uses default of 10)
 //   - skipValidation: If true, skip response URL validation (for testing only)
 //   - networkCapture: Optional network capture for real HTTP status codes and headers (may be nil)
-func (s *Solver) solveLoop(ctx context.Context, page *rod.Page, url string, captureScreenshot bool, expectedIP net.IP, tabsTillVerify int, skipValidation bool, networkCapture *NetworkCapture, cookieExtractDelay int) (*Result, error) {
-	// Phase 2: Use randomized poll interval (0.8-1.5s) instead of fixed 1s
-	// This makes polling patterns appear more human-like
-	avgPollInterval := 1150 * time.Millisecond // Average of 800-1500ms for calculation
+func (s *Solver) solveLoop(ctx context.Context, page *rod.Page, url string, screenshot *ScreenshotOptions, expectedIP net.IP, tabsTillVerify int, skipValidation bool, networkCapture *NetworkCapture, cookieExtractDelay int) (result *Result, err error) {
+	// The poll strategy (per-domain preference or server default) paces the
+	// detection passes and supplies the early-exit thresholds
+	settings := s.pollSettingsFor(ctx)
+	strategy := settings.strategy(s.pollStrategyFor(extractDomainFromURL(url)))
+	limits := strategy.Limits()
+	wait, stopWatch := strategy.Watch(ctx, page)
+	defer stopWatch()
 
 	// Calculate max attempts from context deadline (Bug 3: poll attempts vs timeout mismatch)
-	maxAttempts := 300 // Fallback: 5 minutes at ~1.15s intervals
+	maxAttempts := defaultMaxPollAttempts
 	if deadline, ok := ctx.Deadline(); ok {
-		remaining := time.Until(deadline)
-		maxAttempts = int(remaining/avgPollInterval) + 1
-		if maxAttempts < 1 {
-			maxAttempts = 1
-		}
+		maxAttempts = strategy.MaxAttempts(time.Until(deadline))
 	}
+	if settings.MaxAttempts > 0 {
+		maxAttempts = min(maxAttempts, settings.MaxAttempts)
+	}
+	log.Ctx(ctx).Debug().Str("strategy", strategy.Name()).Int("max_attempts", maxAttempts).Msg("Challenge poll strategy")
 
 	// Track Turnstile solve attempts for external solver fallback
 	turnstileAttempts := 0
+	// Per-solve scratch space for the challenge detectors
+	counters := make(map[string]int)
+
+	// Count the solve under the first challenge type seen on the page
+	metChallenge := ChallengeNone
+	defer func() {
+		if metChallenge != ChallengeNone && err == nil {
+			s.challenges.record(metChallenge, true)
+		}
+	}()
+
+	// Each detection pass is traced up to the decision on what to do next
+	detectSpan := trace.SpanFromContext(context.Background())
+	defer func() { detectSpan.End() }()
 
 	for attempt := 0; attempt < maxAttempts; attempt++ {
 		// Check context at the start of each iteration to fail fast
@@ -1601,14 +2121,15 @@ func (s *Solver) solveLoop(ctx context.Context, page *rod.Page, url string, capt
 			return nil, types.NewChallengeTimeoutError(url)
 		default:
 		}
+		_, detectSpan = tracing.Start(ctx, "solver.detect_challenge", attribute.Int("solver.attempt", attempt+1))
 
 		// Get page title
 		title, err := s.getPageTitle(page)
 		if err != nil {
-			log.Debug().Err(err).Msg("Failed to get page title")
-			// Use context-aware sleep with randomized interval (Bug 2: time.Sleep ignores context)
-			// Phase 2: Random interval 0.8-1.5s for human-like behavior
-			if !sleepWithContext(ctx, humanize.RandomPollInterval()) {
+			log.Ctx(ctx).Debug().Err(err).Msg("Failed to get page title")
+			detectSpan.End()
+			// Context-aware wait (Bug 2: time.Sleep ignores context)
+			if !wait() {
 				return nil, types.NewChallengeTimeoutError(url)
 			}
 			continue
@@ -1625,9 +2146,13 @@ func (s *Solver) solveLoop(ctx context.Context, page *rod.Page, url string, capt
 		}
 
 		// Check if any challenge selector is present
-		challengeSelector := s.findChallengeSelector(page)
+		challengeSelector := s.findChallengeSelector(page, settings.SelectorBudget)
+		detectSpan.SetAttributes(
+			attribute.String("solver.page_title", title),
+			attribute.String("solver.challenge_selector", challengeSelector),
+		)
 
-		log.Debug().
+		log.Ctx(ctx).Debug().
 			Int("attempt", attempt+1).
 			Int("max_attempts", maxAttempts).
 			Str("title", title).
@@ -1637,15 +2162,15 @@ func (s *Solver) solveLoop(ctx context.Context, page *rod.Page, url string, capt
 
 		// If no challenge indicators, we're done
 		if !challengeInTitle && challengeSelector == "" {
-			log.Info().Str("title", title).Msg("Challenge solved or no challenge present")
-			return s.buildResult(page, url, captureScreenshot, expectedIP, skipValidation, networkCapture, cookieExtractDelay)
+			log.Ctx(ctx).Info().Str("title", title).Msg("Challenge solved or no challenge present")
+			return s.buildResult(ctx, page, url, screenshot, expectedIP, skipValidation, networkCapture, cookieExtractDelay)
 		}
 
 		// For invisible Turnstile: if cf_clearance cookie is present, challenge is solved
 		// even if the widget is still visible on the page
-		if s.hasCfClearanceCookie(page) {
-			log.Info().Msg("cf_clearance cookie present - challenge solved (invisible Turnstile)")
-			return s.buildResult(page, url, captureScreenshot, expectedIP, skipValidation, networkCapture, cookieExtractDelay)
+		if limits.ClearanceCookieExit && s.hasCfClearanceCookie(page) {
+			log.Ctx(ctx).Info().Msg("cf_clearance cookie present - challenge solved (invisible Turnstile)")
+			return s.buildResult(ctx, page, url, screenshot, expectedIP, skipValidation, networkCapture, cookieExtractDelay)
 		}
 
 		// Check for access denied — but only after giving the JS challenge
@@ -1657,61 +2182,102 @@ func (s *Solver) solveLoop(ctx context.Context, page *rod.Page, url string, capt
 			// (e.g., Turnstile click triggered a redirect after solving).
 			// Wait for the new page to load and retry.
 			if strings.Contains(err.Error(), "Cannot find context") {
-				log.Info().Msg("Page context changed (likely post-challenge redirect), waiting for new page")
+				log.Ctx(ctx).Info().Msg("Page context changed (likely post-challenge redirect), waiting for new page")
 				if !sleepWithContext(ctx, 2*time.Second) {
 					return nil, types.NewChallengeTimeoutError(url)
 				}
 				// Try to get result from the new page
-				return s.buildResult(page, url, captureScreenshot, expectedIP, skipValidation, networkCapture, cookieExtractDelay)
+				return s.buildResult(ctx, page, url, screenshot, expectedIP, skipValidation, networkCapture, cookieExtractDelay)
 			}
-			log.Debug().Err(err).Msg("Failed to get page HTML for challenge detection")
+			log.Ctx(ctx).Debug().Err(err).Msg("Failed to get page HTML for challenge detection")
 			return nil, fmt.Errorf("failed to get page HTML: %w", err)
 		}
-		if html != "" && s.detectChallenge(html) == ChallengeAccessDenied {
-			if attempt >= 3 {
+		htmlChallenge := ChallengeNone
+		var detector *ChallengeDetector
+		htmlLower := strings.ToLower(html)
+		if html != "" {
+			detector = matchDetector(htmlLower, s.getSelectors())
+		}
+		if detector != nil {
+			htmlChallenge = detector.Type
+		}
+		detectSpan.SetAttributes(attribute.String("solver.challenge", htmlChallenge.String()))
+		detectSpan.End()
+		if metChallenge == ChallengeNone && htmlChallenge != ChallengeNone {
+			metChallenge = htmlChallenge
+			s.challenges.record(htmlChallenge, false)
+			s.recordDomainChallenge(extractDomainFromURL(url), htmlChallenge)
+			events.Publish(ctx, events.Event{
+				Type:      events.ChallengeDetected,
+				Domain:    extractDomainFromURL(url),
+				Challenge: htmlChallenge.String(),
+			})
+		}
+		if htmlChallenge == ChallengeAccessDenied {
+			if attempt >= limits.AccessDeniedAfter {
 				return nil, types.NewAccessDeniedError(url)
 			}
-			log.Debug().
+			log.Ctx(ctx).Debug().
 				Int("attempt", attempt+1).
 				Msg("Possible access denied, but waiting for JS challenge to resolve first")
 		}
 
 		// If Turnstile is present, try to solve it.
-		// Trigger on known Turnstile selectors OR when HTML analysis detects Turnstile
-		// (e.g., embedded in CF interstitial iframe where .cf-turnstile isn't on the main page).
+		// Trigger on known Turnstile selectors OR when HTML analysis detects a
+		// checkbox challenge (e.g., embedded in CF interstitial iframe where
+		// .cf-turnstile isn't on the main page, or the managed challenge).
 		shouldSolveTurnstile := turnstileTriggerSelectors[challengeSelector]
-		if !shouldSolveTurnstile && html != "" {
-			htmlChallenge := s.detectChallenge(html)
-			shouldSolveTurnstile = htmlChallenge == ChallengeTurnstile
+		if !shouldSolveTurnstile {
+			shouldSolveTurnstile = detector != nil && detector.TriggerTurnstile
 		}
 		// Also trigger Turnstile solving when stuck on JS challenge for multiple attempts
 		// — the interstitial may have an embedded Turnstile that needs interaction
-		if !shouldSolveTurnstile && challengeInTitle && attempt >= 5 {
+		if !shouldSolveTurnstile && challengeInTitle && attempt >= limits.TurnstileEscalateAfter {
 			shouldSolveTurnstile = true
 		}
+		// The hCaptcha and reCAPTCHA fallbacks reuse the interstitial
+		// (#challenge-stage), so clicking for a Turnstile checkbox there only
+		// wastes the attempt. The other vendors have no checkbox at all.
+		if detector != nil && detector.SuppressTurnstile {
+			shouldSolveTurnstile = false
+		}
 		if shouldSolveTurnstile {
 			turnstileAttempts++
-			log.Debug().
+			log.Ctx(ctx).Debug().
 				Str("selector", challengeSelector).
 				Int("attempt", turnstileAttempts).
 				Msg("Turnstile detected, attempting to solve...")
 
-			// Try native solving methods first (Methods 1-5)
-			if err := s.solveTurnstile(ctx, page, tabsTillVerify); err != nil {
+			// Try native solving methods first (Methods 1-5). On domains
+			// where they rarely work, the first pass races an external solve.
+			var err error
+			if turnstileAttempts == 1 && s.shouldRaceExternal(extractDomainFromURL(url)) {
+				err = s.raceTurnstile(ctx, page, url, tabsTillVerify)
+				if errors.Is(err, types.ErrCaptchaBudgetExceeded) {
+					return nil, err
+				}
+			} else {
+				err = s.solveTurnstile(ctx, page, tabsTillVerify)
+			}
+			if err != nil {
 				// Fix: Log but continue - Turnstile solve is best-effort, the loop will
 				// check again and return error if challenge persists past timeout
-				log.Warn().Err(err).Msg("Turnstile solve attempt failed, will retry")
+				log.Ctx(ctx).Warn().Err(err).Msg("Turnstile solve attempt failed, will retry")
 			}
 
 			// Try external solver fallback before the early bypass so that
 			// configured providers (2Captcha, CapSolver, etc.) get a chance.
 			if s.solverChain != nil && s.solverChain.ShouldFallback(turnstileAttempts) {
-				log.Info().
+				log.Ctx(ctx).Info().
 					Int("native_attempts", turnstileAttempts).
 					Msg("Native Turnstile solving exhausted, trying external solver")
 
-				if err := s.solveTurnstileExternal(ctx, page, url); err != nil {
-					log.Warn().Err(err).Msg("External solver fallback failed")
+				if err := s.solveTurnstileExternal(ctx, page, url); errors.Is(err, types.ErrCaptchaBudgetExceeded) {
+					// Paid solving is off until the budget resets; report that
+					// rather than a timeout
+					return nil, err
+				} else if err != nil {
+					log.Ctx(ctx).Warn().Err(err).Msg("External solver fallback failed")
 				} else {
 					continue
 				}
@@ -1720,29 +2286,36 @@ func (s *Solver) solveLoop(ctx context.Context, page *rod.Page, url string, capt
 			// Early two-phase bypass: bail to CDP disconnect/reconnect approach.
 			// When an external solver chain is configured, raise the threshold
 			// so the external provider has at least one shot before we bail.
-			earlyBypassThreshold := 2
+			earlyBypassThreshold := limits.EarlyBypassAfter
 			if s.solverChain != nil && s.solverChain.IsEnabled() {
-				earlyBypassThreshold = s.solverChain.NativeAttempts() + 1
+				earlyBypassThreshold = max(earlyBypassThreshold, s.solverChain.NativeAttempts()+1)
 			}
 			if turnstileAttempts >= earlyBypassThreshold && ctx.Err() == nil {
-				log.Info().
+				log.Ctx(ctx).Info().
 					Int("native_attempts", turnstileAttempts).
 					Msg("Turnstile native solving struggling, attempting early two-phase bypass")
 				return nil, fmt.Errorf("turnstile_early_bypass: native solving exhausted after %d attempts", turnstileAttempts)
 			}
 		}
 
-		// If hCaptcha is detected, try external solving
-		if html != "" && s.detectChallenge(html) == ChallengeHCaptcha && s.solverChain != nil {
-			log.Info().Msg("hCaptcha detected, attempting external solver")
-			if err := s.solveHCaptchaExternal(ctx, page, url); err != nil {
-				log.Warn().Err(err).Msg("hCaptcha external solve failed")
+		// Let the matched vendor advance its challenge
+		if detector != nil && detector.Solve != nil {
+			pass := &ChallengePass{
+				Solver:    s,
+				Page:      page,
+				URL:       url,
+				HTMLLower: htmlLower,
+				Type:      detector.Type,
+				Attempt:   attempt,
+				Counters:  counters,
+			}
+			if err := detector.Solve(ctx, pass); err != nil {
+				return nil, err
 			}
 		}
 
-		// Wait and retry with context-aware sleep (Bug 2)
-		// Phase 2: Random interval 0.8-1.5s for human-like behavior
-		if !sleepWithContext(ctx, humanize.RandomPollInterval()) {
+		// Wait for the next pass as paced by the strategy (Bug 2: context-aware)
+		if !wait() {
 			return nil, types.NewChallengeTimeoutError(url)
 		}
 	}
@@ -1762,10 +2335,10 @@ func (s *Solver) getPageTitle(page *rod.Page) (string, error) {
 // findChallengeSelector checks if any challenge selector is present on the page.
 // Uses shared timeout budget across all selector checks to prevent stacked timeouts.
 // Fix: Share timeout budget across selectors instead of giving each one a full 2 seconds.
-func (s *Solver) findChallengeSelector(page *rod.Page) string {
-	// Calculate timeout budget: use page's context deadline if available, otherwise default
+func (s *Solver) findChallengeSelector(page *rod.Page, budget time.Duration) string {
+	// Calculate timeout budget: use page's context deadline if available, otherwise the budget
 	ctx := page.GetContext()
-	totalTimeout := 5 * time.Second // Default total budget for all selectors
+	totalTimeout := budget // Total budget for all selectors
 	if deadline, ok := ctx.Deadline(); ok {
 		remaining := time.Until(deadline)
 		if remaining < totalTimeout {
@@ -1774,12 +2347,13 @@ func (s *Solver) findChallengeSelector(page *rod.Page) string {
 	}
 
 	// Distribute timeout budget across selectors (minimum 100ms each)
-	perSelectorTimeout := totalTimeout / time.Duration(len(challengeSelectors)+1)
+	selectorList := allChallengeSelectors()
+	perSelectorTimeout := totalTimeout / time.Duration(len(selectorList)+1)
 	if perSelectorTimeout < 100*time.Millisecond {
 		perSelectorTimeout = 100 * time.Millisecond
 	}
 
-	for _, selector := range challengeSelectors {
+	for _, selector := range selectorList {
 		// Check context cancellation
 		select {
 		case <-ctx.Done():
@@ -1795,43 +2369,43 @@ func (s *Solver) findChallengeSelector(page *rod.Page) string {
 	return ""
 }
 
-// detectChallenge analyzes HTML to determine the challenge type.
+// detectChallenge analyzes HTML to determine the challenge type, using the
+// first matching registered ChallengeDetector.
 func (s *Solver) detectChallenge(html string) ChallengeType {
-	htmlLower := strings.ToLower(html)
-	sel := s.getSelectors()
-
-	// Check for access denied
-	for _, pattern := range sel.AccessDenied {
-		if strings.Contains(htmlLower, pattern) && strings.Contains(htmlLower, "cloudflare") {
-			return ChallengeAccessDenied
-		}
+	if d := matchDetector(strings.ToLower(html), s.getSelectors()); d != nil {
+		return d.Type
 	}
+	return ChallengeNone
+}
 
-	// Check for Turnstile challenge
-	for _, pattern := range sel.Turnstile {
-		if strings.Contains(htmlLower, pattern) {
-			return ChallengeTurnstile
-		}
+// isHCaptchaPage reports whether lowercased html embeds an hCaptcha widget:
+// a captcha pattern matches and the page references hCaptcha rather than
+// reCAPTCHA.
+func isHCaptchaPage(htmlLower string, captchaPatterns []string) bool {
+	if !strings.Contains(htmlLower, "hcaptcha") && !strings.Contains(htmlLower, "h-captcha") {
+		return false
 	}
-
-	// Check for hCaptcha
-	for _, pattern := range sel.Captcha {
+	for _, pattern := range captchaPatterns {
 		if strings.Contains(htmlLower, strings.ToLower(pattern)) {
-			// Distinguish hCaptcha from reCAPTCHA
-			if strings.Contains(htmlLower, "hcaptcha") || strings.Contains(htmlLower, "h-captcha") {
-				return ChallengeHCaptcha
-			}
+			return true
 		}
 	}
+	return false
+}
 
-	// Check for JavaScript challenge
-	for _, pattern := range sel.JavaScript {
-		if strings.Contains(htmlLower, pattern) {
-			return ChallengeJavaScript
+// isRecaptchaPage reports whether lowercased html embeds a reCAPTCHA widget
+// or script. Call after isHCaptchaPage, whose widget mirrors its token into
+// a g-recaptcha-response field.
+func isRecaptchaPage(htmlLower string, captchaPatterns []string) bool {
+	if !strings.Contains(htmlLower, "recaptcha") {
+		return false
+	}
+	for _, pattern := range captchaPatterns {
+		if strings.Contains(htmlLower, strings.ToLower(pattern)) {
+			return true
 		}
 	}
-
-	return ChallengeNone
+	return false
 }
 
 // solveTurnstile attempts to solve the Turnstile challenge.
@@ -1853,7 +2427,7 @@ func (s *Solver) detectChallenge(html string) ChallengeType {
 // Parameters:
 //   - tabsTillVerify: Number of Tab presses to reach the Turnstile checkbox (0 uses default)
 func (s *Solver) solveTurnstile(ctx context.Context, page *rod.Page, tabsTillVerify int) error {
-	log.Debug().Msg("Attempting to solve Turnstile challenge with humanized timing")
+	log.Ctx(ctx).Debug().Msg("Attempting to solve Turnstile challenge with humanized timing")
 
 	// Phase 2: Randomized wait for Turnstile to fully initialize (400-700ms)
 	if !sleepWithContext(ctx, humanize.RandomDuration(400, 700)) {
@@ -1866,40 +2440,49 @@ func (s *Solver) solveTurnstile(ctx context.Context, page *rod.Page, tabsTillVer
 		domain = extractDomainFromURL(info.URL)
 	}
 
-	// Get method order based on past success for this domain
-	methods := s.getTurnstileMethodOrder(domain)
+	// Get method order based on past success for this domain, after the
+	// methods a domain override prefers
+	methods := s.enabledTurnstileMethods(domain, preferTurnstileMethods(ctx, s.getTurnstileMethodOrder(domain)))
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Strs("method_order", methods).
 		Str("domain", domain).
 		Msg("Turnstile method order")
 
 	// Try each method in order
+	start := time.Now()
 	for _, method := range methods {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
 
+		methodCtx, span := tracing.Start(ctx, "solver.turnstile."+method, attribute.String("flaresolverr.domain", domain))
 		var err error
 		switch method {
 		case "wait":
-			err = s.solveTurnstileWait(ctx, page)
+			err = s.solveTurnstileWait(methodCtx, page)
 		case "shadow":
-			err = s.solveTurnstileShadow(ctx, page)
+			err = s.solveTurnstileShadow(methodCtx, page)
 		case "keyboard":
-			err = s.solveTurnstileKeyboard(ctx, page, tabsTillVerify)
+			err = s.solveTurnstileKeyboard(methodCtx, page, tabsTillVerify)
 		case "widget":
-			err = s.solveTurnstileWidget(ctx, page)
+			err = s.solveTurnstileWidget(methodCtx, page)
 		case "iframe":
-			err = s.solveTurnstileClick(ctx, page)
+			err = s.solveTurnstileClick(methodCtx, page)
 		case "positional":
-			err = s.solveTurnstilePositional(ctx, page)
+			err = s.solveTurnstilePositional(methodCtx, page)
+		case "frames":
+			err = s.solveTurnstileFrames(methodCtx, page)
 		default:
+			span.End()
 			continue
 		}
+		solved := err == nil && s.isTurnstileSolved(page)
+		span.SetAttributes(attribute.Bool("solver.turnstile.solved", solved))
+		tracing.End(span, err)
 
 		// Record attempt regardless of error (for method learning)
-		log.Debug().Str("method", method).Err(err).Msg("Turnstile method attempted")
+		log.Ctx(ctx).Debug().Str("method", method).Err(err).Msg("Turnstile method attempted")
 
 		if err != nil {
 			// Method returned error - record failure and continue to next method
@@ -1908,17 +2491,21 @@ func (s *Solver) solveTurnstile(ctx context.Context, page *rod.Page, tabsTillVer
 		}
 
 		// Check for success (method completed without error)
-		if s.isTurnstileSolved(page) {
-			log.Info().Str("method", method).Msg("Turnstile solved!")
+		if solved {
+			log.Ctx(ctx).Info().Str("method", method).Msg("Turnstile solved!")
 
 			// Record successful method for future reference
 			s.recordTurnstileMethod(domain, method, true)
+			s.recordSolveOutcome(domain, "native", true, time.Since(start))
 			return nil
 		}
 
 		// Method didn't work - record failure
 		s.recordTurnstileMethod(domain, method, false)
 	}
+	if ctx.Err() == nil {
+		s.recordSolveOutcome(domain, "native", false, time.Since(start))
+	}
 
 	// Don't return error - the solveLoop will check if challenge is still present
 	return nil
@@ -1935,7 +2522,7 @@ func (s *Solver) solveTurnstile(ctx context.Context, page *rod.Page, tabsTillVer
 // The wait is done in multiple shorter intervals with success checks in between.
 // For invisible Turnstile, the cf_clearance cookie may appear while the widget is still visible.
 func (s *Solver) solveTurnstileWait(ctx context.Context, page *rod.Page) error {
-	log.Debug().Msg("Trying passive wait for invisible Turnstile auto-solve")
+	log.Ctx(ctx).Debug().Msg("Trying passive wait for invisible Turnstile auto-solve")
 
 	// Phase 2: Randomized wait intervals (4-6 seconds) instead of fixed 5 seconds
 	// Total wait time: up to ~30 seconds per attempt (invisible Turnstile can take 20-30s)
@@ -1954,7 +2541,7 @@ func (s *Solver) solveTurnstileWait(ctx context.Context, page *rod.Page) error {
 		// Check if cf_clearance cookie appeared (invisible Turnstile success indicator)
 		// This is the most reliable indicator for invisible mode
 		if s.hasCfClearanceCookie(page) {
-			log.Info().
+			log.Ctx(ctx).Info().
 				Int("iteration", i+1).
 				Msg("cf_clearance cookie appeared - Turnstile auto-solved during passive wait")
 			return nil
@@ -1962,7 +2549,7 @@ func (s *Solver) solveTurnstileWait(ctx context.Context, page *rod.Page) error {
 
 		// Check if Turnstile auto-solved via token or success state
 		if s.isTurnstileSolved(page) {
-			log.Info().
+			log.Ctx(ctx).Info().
 				Int("iteration", i+1).
 				Msg("Turnstile success indicators detected during passive wait")
 			return nil
@@ -1974,21 +2561,21 @@ func (s *Solver) solveTurnstileWait(ctx context.Context, page *rod.Page) error {
 			// Also check for #turnstile-wrapper
 			has2, _, _ := page.Has("#turnstile-wrapper")
 			if !has2 {
-				log.Debug().
+				log.Ctx(ctx).Debug().
 					Int("iteration", i+1).
 					Msg("Turnstile widget disappeared during wait")
 				return nil
 			}
 		}
 
-		log.Debug().
+		log.Ctx(ctx).Debug().
 			Int("iteration", i+1).
 			Int("max", maxWaitIterations).
 			Msg("Turnstile still present, continuing wait")
 	}
 
 	// Wait method completed but didn't solve - not an error, will try next method
-	log.Debug().Msg("Passive wait completed without solving Turnstile")
+	log.Ctx(ctx).Debug().Msg("Passive wait completed without solving Turnstile")
 	return nil
 }
 
@@ -2011,22 +2598,31 @@ func (s *Solver) hasCfClearanceCookie(page *rod.Page) bool {
 
 // getTurnstileMethodOrder returns the order of methods to try based on domain history.
 func (s *Solver) getTurnstileMethodOrder(domain string) []string {
-	// Default order (if no stats or no history)
-	// "wait" is first because invisible Turnstile auto-solves without interaction
-	defaultOrder := []string{"wait", "keyboard", "shadow", "widget", "iframe", "positional"}
-
 	if s.statsManager == nil || domain == "" {
-		return defaultOrder
+		return slices.Clone(defaultTurnstileMethods)
 	}
 
 	order := s.statsManager.GetTurnstileMethodOrder(domain)
 	if len(order) == 0 {
-		return defaultOrder
+		return slices.Clone(defaultTurnstileMethods)
 	}
 
 	return order
 }
 
+// enabledTurnstileMethods drops the methods whose feature flag is off for
+// domain or that its solver preferences disable.
+func (s *Solver) enabledTurnstileMethods(domain string, methods []string) []string {
+	return s.dropDisabledTurnstileMethods(domain, slices.DeleteFunc(methods, func(method string) bool {
+		return !s.features.Enabled("turnstile."+method, domain)
+	}))
+}
+
+// SetFeatures sets the feature flags gating experimental behaviors.
+func (s *Solver) SetFeatures(f *features.Flags) {
+	s.features = f
+}
+
 // recordTurnstileMethod records the outcome of a Turnstile method attempt.
 func (s *Solver) recordTurnstileMethod(domain, method string, success bool) {
 	if s.statsManager == nil || domain == "" {
@@ -2131,18 +2727,18 @@ func (s *Solver) isTurnstileSolved(page *rod.Page) bool {
 // Detection risk: LOW - uses debugger API, no JavaScript modification
 // Phase 2: Uses randomized post-click dwell time
 func (s *Solver) solveTurnstileShadow(ctx context.Context, page *rod.Page) error {
-	log.Debug().Msg("Trying CDP-native shadow DOM traversal for Turnstile")
+	log.Ctx(ctx).Debug().Msg("Trying CDP-native shadow DOM traversal for Turnstile")
 
 	// Use shorter timeout for shadow traverser
 	traverser := NewShadowRootTraverser(page).WithTimeout(2 * time.Second)
 
 	// Try to find and click the checkbox via shadow DOM
 	if err := traverser.ClickCheckbox(ctx); err != nil {
-		log.Debug().Err(err).Msg("Shadow DOM checkbox click failed")
+		log.Ctx(ctx).Debug().Err(err).Msg("Shadow DOM checkbox click failed")
 		return fmt.Errorf("shadow DOM click failed: %w", err)
 	}
 
-	log.Info().Msg("Clicked Turnstile checkbox via shadow DOM traversal")
+	log.Ctx(ctx).Info().Msg("Clicked Turnstile checkbox via shadow DOM traversal")
 
 	// Phase 2: Randomized post-click dwell time (250-450ms)
 	if !sleepWithContext(ctx, humanize.RandomDuration(250, 450)) {
@@ -2159,7 +2755,7 @@ func (s *Solver) solveTurnstileShadow(ctx context.Context, page *rod.Page) error
 // Detection risk: MEDIUM - coordinates may reveal automation patterns
 // Phase 2: Now uses Bezier curve mouse movement for human-like behavior
 func (s *Solver) solveTurnstilePositional(ctx context.Context, page *rod.Page) error {
-	log.Debug().Msg("Trying positional click for Turnstile with humanized movement")
+	log.Ctx(ctx).Debug().Msg("Trying positional click for Turnstile with humanized movement")
 
 	// OPTIMIZATION: Use shorter timeout for traverser
 	traverser := NewShadowRootTraverser(page).WithTimeout(1 * time.Second)
@@ -2167,11 +2763,11 @@ func (s *Solver) solveTurnstilePositional(ctx context.Context, page *rod.Page) e
 	// Get the Turnstile container bounds
 	bounds, err := traverser.GetTurnstileContainerBounds(ctx)
 	if err != nil {
-		log.Debug().Err(err).Msg("Failed to get Turnstile container bounds")
+		log.Ctx(ctx).Debug().Err(err).Msg("Failed to get Turnstile container bounds")
 		return fmt.Errorf("failed to get container bounds: %w", err)
 	}
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Float64("container_x", bounds.X).
 		Float64("container_y", bounds.Y).
 		Float64("container_width", bounds.Width).
@@ -2199,11 +2795,11 @@ func (s *Solver) solveTurnstilePositional(ctx context.Context, page *rod.Page) e
 
 		// Phase 2: Use humanized click with Bezier movement, hover, and dwell
 		if err := mouse.Click(ctx, clickX, clickY); err != nil {
-			log.Debug().Err(err).Int("attempt", i).Msg("Humanized click failed")
+			log.Ctx(ctx).Debug().Err(err).Int("attempt", i).Msg("Humanized click failed")
 			continue
 		}
 
-		log.Info().
+		log.Ctx(ctx).Info().
 			Float64("x", clickX).
 			Float64("y", clickY).
 			Int("attempt", i).
@@ -2222,29 +2818,33 @@ func (s *Solver) solveTurnstilePositional(ctx context.Context, page *rod.Page) e
 // This method is called after native solving methods have been exhausted.
 //
 // Detection risk: LOW - uses legitimate CAPTCHA solving service
-func (s *Solver) solveTurnstileExternal(ctx context.Context, page *rod.Page, pageURL string) error {
+func (s *Solver) solveTurnstileExternal(ctx context.Context, page *rod.Page, pageURL string) (err error) {
+	ctx, span := tracing.Start(ctx, "solver.turnstile.external")
+	defer func() { tracing.End(span, err) }()
+
 	if s.solverChain == nil {
 		return fmt.Errorf("solver chain not configured")
 	}
 
-	log.Debug().Msg("Trying external CAPTCHA solver for Turnstile")
+	log.Ctx(ctx).Debug().Msg("Trying external CAPTCHA solver for Turnstile")
 
-	result, err := s.solverChain.Solve(ctx, page, pageURL, s.userAgent)
+	result, err := s.solverChain.Solve(ctx, page, pageURL, s.userAgent, solveProxyFrom(ctx))
 	if err != nil {
 		return fmt.Errorf("external solver failed: %w", err)
 	}
+	s.recordSolveOutcome(extractDomainFromURL(pageURL), result.Provider, true, result.SolveTime)
 
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("provider", result.Provider).
 		Dur("solve_time", result.SolveTime).
 		Float64("cost", result.Cost).
 		Bool("injected", result.Injected).
 		Msg("External CAPTCHA solver succeeded")
 
-	// Wait for the page to process the injected token
+	// Make sure the page took the token, re-delivering it if not
 	if result.Injected {
-		if err := captcha.WaitForTokenInjectionEffect(ctx, page, 5*time.Second); err != nil {
-			log.Debug().Err(err).Msg("Error waiting for token injection effect")
+		if err := captcha.VerifyInjectedToken(ctx, page, captcha.TokenTurnstile, result.Token, tokenVerifyTimeout); err != nil {
+			return fmt.Errorf("external Turnstile token not accepted: %w", err)
 		}
 	}
 
@@ -2258,7 +2858,7 @@ func (s *Solver) solveTurnstileWidget(ctx context.Context, page *rod.Page) error
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
-	log.Debug().Msg("Trying direct widget click for Turnstile with humanized movement")
+	log.Ctx(ctx).Debug().Msg("Trying direct widget click for Turnstile with humanized movement")
 
 	// Phase 2: Create humanized mouse and scroller
 	mouse := humanize.NewMouse(page)
@@ -2294,17 +2894,17 @@ func (s *Solver) solveTurnstileWidget(ctx context.Context, page *rod.Page) error
 		// Phase 2: Scroll element into view if needed
 		scrolled, _ := scroller.EnsureElementVisible(ctx, element)
 		if scrolled {
-			log.Debug().Str("selector", selector).Msg("Scrolled to Turnstile widget")
+			log.Ctx(ctx).Debug().Str("selector", selector).Msg("Scrolled to Turnstile widget")
 		}
 
 		// Phase 2: Use humanized click on element
 		if err := mouse.ClickElement(ctx, element); err != nil {
-			log.Debug().Err(err).Str("selector", selector).Msg("Humanized widget click failed")
+			log.Ctx(ctx).Debug().Err(err).Str("selector", selector).Msg("Humanized widget click failed")
 			_ = element.Release()
 			continue
 		}
 
-		log.Info().Str("selector", selector).Msg("Performed humanized click on Turnstile widget")
+		log.Ctx(ctx).Info().Str("selector", selector).Msg("Performed humanized click on Turnstile widget")
 		_ = element.Release()
 
 		// Check for success after click
@@ -2332,7 +2932,7 @@ func (s *Solver) solveTurnstileKeyboard(ctx context.Context, page *rod.Page, tab
 		tabCount = 6
 	}
 
-	log.Debug().Int("tab_count", tabCount).Msg("Trying keyboard navigation for Turnstile")
+	log.Ctx(ctx).Debug().Int("tab_count", tabCount).Msg("Trying keyboard navigation for Turnstile")
 
 	keyboard := page.Keyboard
 
@@ -2340,7 +2940,7 @@ func (s *Solver) solveTurnstileKeyboard(ctx context.Context, page *rod.Page, tab
 	// Phase 2: Randomized delay between tabs (60-120ms) for human-like behavior
 	for i := 0; i < tabCount; i++ {
 		if err := keyboard.Press(input.Tab); err != nil {
-			log.Debug().Err(err).Int("tab", i).Msg("Tab press failed")
+			log.Ctx(ctx).Debug().Err(err).Int("tab", i).Msg("Tab press failed")
 			continue
 		}
 		// Phase 2: Randomized delay for natural keyboard timing
@@ -2356,11 +2956,11 @@ func (s *Solver) solveTurnstileKeyboard(ctx context.Context, page *rod.Page, tab
 
 	// Press Enter to activate the checkbox (matches Python v3.3.22)
 	if err := keyboard.Press(input.Enter); err != nil {
-		log.Debug().Err(err).Msg("Enter press failed")
+		log.Ctx(ctx).Debug().Err(err).Msg("Enter press failed")
 		return fmt.Errorf("failed to press Enter key: %w", err)
 	}
 
-	log.Info().Msg("Sent keyboard Tab+Enter for Turnstile")
+	log.Ctx(ctx).Info().Msg("Sent keyboard Tab+Enter for Turnstile")
 
 	// Phase 2: Post-action dwell time (400-700ms)
 	if !sleepWithContext(ctx, humanize.RandomDuration(400, 700)) {
@@ -2373,14 +2973,14 @@ func (s *Solver) solveTurnstileKeyboard(ctx context.Context, page *rod.Page, tab
 		// Always defer element release to prevent memory leaks
 		defer func() {
 			if releaseErr := btn.Release(); releaseErr != nil {
-				log.Debug().Err(releaseErr).Msg("Error releasing Verify button element")
+				log.Ctx(ctx).Debug().Err(releaseErr).Msg("Error releasing Verify button element")
 			}
 		}()
 		if focusErr := btn.Focus(); focusErr == nil {
 			// Phase 2: Small delay before pressing Enter on Verify button
 			sleepWithContext(ctx, humanize.RandomDuration(80, 200))
 			if enterErr := keyboard.Press(input.Enter); enterErr == nil {
-				log.Info().Msg("Pressed Enter on Verify button")
+				log.Ctx(ctx).Info().Msg("Pressed Enter on Verify button")
 			}
 		}
 	}
@@ -2395,7 +2995,7 @@ func (s *Solver) solveTurnstileClick(ctx context.Context, page *rod.Page) error
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
-	log.Debug().Msg("Trying direct iframe click for Turnstile")
+	log.Ctx(ctx).Debug().Msg("Trying direct iframe click for Turnstile")
 
 	sel := s.getSelectors()
 
@@ -2409,7 +3009,7 @@ func (s *Solver) solveTurnstileClick(ctx context.Context, page *rod.Page) error
 	defer func() {
 		for _, iframe := range iframes {
 			if err := iframe.Release(); err != nil {
-				log.Debug().Err(err).Msg("Failed to release iframe element")
+				log.Ctx(ctx).Debug().Err(err).Msg("Failed to release iframe element")
 			}
 		}
 	}()
@@ -2422,12 +3022,12 @@ func (s *Solver) solveTurnstileClick(ctx context.Context, page *rod.Page) error
 		}
 
 		if strings.Contains(*src, sel.TurnstileFramePattern) {
-			log.Debug().Str("frame_src", *src).Msg("Found Turnstile frame")
+			log.Ctx(ctx).Debug().Str("frame_src", *src).Msg("Found Turnstile frame")
 
 			// Get the frame's page object
 			frame, err := iframe.Frame()
 			if err != nil {
-				log.Debug().Err(err).Msg("Failed to get frame")
+				log.Ctx(ctx).Debug().Err(err).Msg("Failed to get frame")
 				continue
 			}
 
@@ -2441,15 +3041,15 @@ func (s *Solver) solveTurnstileClick(ctx context.Context, page *rod.Page) error
 				// Try to click the element, then release it immediately
 				clickErr := element.Click(proto.InputMouseButtonLeft, 1)
 				if err := element.Release(); err != nil {
-					log.Debug().Err(err).Str("selector", selector).Msg("Error releasing Turnstile iframe element")
+					log.Ctx(ctx).Debug().Err(err).Str("selector", selector).Msg("Error releasing Turnstile iframe element")
 				}
 
 				if clickErr != nil {
-					log.Debug().Err(clickErr).Str("selector", selector).Msg("Click failed")
+					log.Ctx(ctx).Debug().Err(clickErr).Str("selector", selector).Msg("Click failed")
 					continue
 				}
 
-				log.Info().Str("selector", selector).Msg("Clicked Turnstile checkbox")
+				log.Ctx(ctx).Info().Str("selector", selector).Msg("Clicked Turnstile checkbox")
 				return nil
 			}
 		}
@@ -2458,21 +3058,6 @@ func (s *Solver) solveTurnstileClick(ctx context.Context, page *rod.Page) error
 	return types.ErrTurnstileFailed
 }
 
-// Maximum response size to prevent memory exhaustion (10MB)
-const maxResponseSize = 10 * 1024 * 1024
-
-// Maximum number of cookies to extract to prevent resource exhaustion
-const maxExtractedCookies = 100
-
-// Maximum screenshot size to prevent memory exhaustion (5MB)
-const maxScreenshotSize = 5 * 1024 * 1024
-
-// Maximum number of localStorage/sessionStorage items to extract
-const maxStorageItems = 100
-
-// Maximum total size of localStorage/sessionStorage data (1MB)
-const maxStorageSize = 1 * 1024 * 1024
-
 // Maximum number of response headers to capture
 const maxResponseHeaders = 100
 
@@ -2544,21 +3129,34 @@ func (s *Solver) validateResponseURL(page *rod.Page, expectedIP net.IP, skipVali
 // Parameters:
 //   - page: The browser page
 //   - url: The original request URL
-//   - captureScreenshot: Whether to capture a screenshot
+//   - screenshot: How to capture a screenshot (nil for none)
 //   - expectedIP: The IP resolved during initial validation for DNS pinning (nil to skip)
 //   - skipValidation: If true, skip response URL validation (for testing only)
 //   - networkCapture: Optional network capture for real HTTP status codes and headers (may be nil)
-func (s *Solver) buildResult(page *rod.Page, url string, captureScreenshot bool, expectedIP net.IP, skipValidation bool, networkCapture *NetworkCapture, cookieExtractDelay int) (*Result, error) {
+func (s *Solver) buildResult(ctx context.Context, page *rod.Page, url string, screenshot *ScreenshotOptions, expectedIP net.IP, skipValidation bool, networkCapture *NetworkCapture, cookieExtractDelay int) (*Result, error) {
 	// Validate response URL to detect DNS rebinding attacks
 	if err := s.validateResponseURL(page, expectedIP, skipValidation); err != nil {
 		return nil, err
 	}
 
-	html, err := page.HTML()
+	// Chunked extraction keeps peak memory near the page size and stops at
+	// the response size limit instead of transferring the whole document first
+	limits := responseLimitsFrom(ctx)
+	html, truncated, err := extractHTML(page, limits.MaxResponseSize)
+	if err != nil {
+		log.Debug().Err(err).Msg("Chunked HTML extraction failed, falling back to page.HTML")
+		if html, err = page.HTML(); err != nil {
+			return nil, fmt.Errorf("failed to extract page HTML: %w", err)
+		}
+	}
+	result, err := s.buildResultWithHTML(page, url, html, limits, screenshot, networkCapture, cookieExtractDelay)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract page HTML: %w", err)
+		return nil, err
 	}
-	return s.buildResultWithHTML(page, url, html, captureScreenshot, networkCapture, cookieExtractDelay)
+	if truncated {
+		result.HTMLTruncated = true
+	}
+	return result, nil
 }
 
 // buildResultWithHTML constructs the result using pre-fetched HTML.
@@ -2568,19 +3166,20 @@ func (s *Solver) buildResult(page *rod.Page, url string, captureScreenshot bool,
 //   - page: The browser page
 //   - url: The original request URL
 //   - html: Pre-fetched HTML content
-//   - captureScreenshot: Whether to capture a screenshot
+//   - limits: Size limits on the returned HTML, cookies, storage and screenshot
+//   - screenshot: How to capture a screenshot (nil for none)
 //   - networkCapture: Optional network capture for real HTTP status codes and headers (may be nil)
-func (s *Solver) buildResultWithHTML(page *rod.Page, url string, html string, captureScreenshot bool, networkCapture *NetworkCapture, cookieExtractDelay int) (*Result, error) {
+func (s *Solver) buildResultWithHTML(page *rod.Page, url string, html string, limits ResponseLimits, screenshot *ScreenshotOptions, networkCapture *NetworkCapture, cookieExtractDelay int) (*Result, error) {
 	// Fix #15: Track if HTML was truncated
 	htmlTruncated := false
 
 	// Limit response size to prevent memory exhaustion
-	if len(html) > maxResponseSize {
+	if len(html) > limits.MaxResponseSize {
 		log.Warn().
 			Int("size", len(html)).
-			Int("max", maxResponseSize).
+			Int("max", limits.MaxResponseSize).
 			Msg("Response truncated due to size limit")
-		html = html[:maxResponseSize]
+		html = html[:limits.MaxResponseSize]
 		htmlTruncated = true
 	}
 
@@ -2615,12 +3214,12 @@ func (s *Solver) buildResultWithHTML(page *rod.Page, url string, html string, ca
 	}
 
 	// Enforce cookie count limit to prevent resource exhaustion
-	if len(cookies) > maxExtractedCookies {
+	if len(cookies) > limits.MaxCookies {
 		log.Warn().
 			Int("count", len(cookies)).
-			Int("max", maxExtractedCookies).
+			Int("max", limits.MaxCookies).
 			Msg("Cookie count exceeds limit, truncating")
-		cookies = cookies[:maxExtractedCookies]
+		cookies = cookies[:limits.MaxCookies]
 	}
 
 	// Fix: Enforce per-cookie value size limit to prevent memory exhaustion
@@ -2653,8 +3252,8 @@ func (s *Solver) buildResultWithHTML(page *rod.Page, url string, html string, ca
 	}
 
 	// Extract localStorage and sessionStorage for debugging
-	localStorage := s.extractLocalStorage(page)
-	sessionStorage := s.extractSessionStorage(page)
+	localStorage := s.extractLocalStorage(page, limits)
+	sessionStorage := s.extractSessionStorage(page, limits)
 
 	// Get status code and headers from network capture, or use DOM extraction as fallback
 	statusCode := 200 // Default fallback
@@ -2682,8 +3281,8 @@ func (s *Solver) buildResultWithHTML(page *rod.Page, url string, html string, ca
 
 	// Capture screenshot if requested
 	var screenshotBase64 string
-	if captureScreenshot {
-		screenshotData, err := s.captureScreenshot(page)
+	if screenshot != nil {
+		screenshotData, err := s.captureScreenshot(page, screenshot, limits.MaxScreenshotSize)
 		if err != nil {
 			log.Warn().Err(err).Msg("Failed to capture screenshot")
 		} else {
@@ -2760,7 +3359,7 @@ func (s *Solver) extractTurnstileToken(page *rod.Page) string {
 
 // extractLocalStorage extracts all localStorage key-value pairs from the page.
 // Enforces limits on item count and total size to prevent resource exhaustion.
-func (s *Solver) extractLocalStorage(page *rod.Page) map[string]string {
+func (s *Solver) extractLocalStorage(page *rod.Page, limits ResponseLimits) map[string]string {
 	result, err := proto.RuntimeEvaluate{
 		Expression: `(function() {
 			var data = {};
@@ -2789,10 +3388,10 @@ func (s *Solver) extractLocalStorage(page *rod.Page) map[string]string {
 	}
 
 	// Check total size limit before parsing
-	if len(jsonStr) > maxStorageSize {
+	if len(jsonStr) > limits.MaxStorageSize {
 		log.Warn().
 			Int("size", len(jsonStr)).
-			Int("max", maxStorageSize).
+			Int("max", limits.MaxStorageSize).
 			Msg("localStorage data exceeds size limit, truncating")
 		return nil
 	}
@@ -2804,15 +3403,15 @@ func (s *Solver) extractLocalStorage(page *rod.Page) map[string]string {
 	}
 
 	// Enforce item count limit
-	if len(data) > maxStorageItems {
+	if len(data) > limits.MaxStorageItems {
 		log.Warn().
 			Int("count", len(data)).
-			Int("max", maxStorageItems).
+			Int("max", limits.MaxStorageItems).
 			Msg("localStorage item count exceeds limit, truncating")
-		truncated := make(map[string]string, maxStorageItems)
+		truncated := make(map[string]string, limits.MaxStorageItems)
 		count := 0
 		for k, v := range data {
-			if count >= maxStorageItems {
+			if count >= limits.MaxStorageItems {
 				break
 			}
 			truncated[k] = v
@@ -2829,7 +3428,7 @@ func (s *Solver) extractLocalStorage(page *rod.Page) map[string]string {
 
 // extractSessionStorage extracts all sessionStorage key-value pairs from the page.
 // Enforces limits on item count and total size to prevent resource exhaustion.
-func (s *Solver) extractSessionStorage(page *rod.Page) map[string]string {
+func (s *Solver) extractSessionStorage(page *rod.Page, limits ResponseLimits) map[string]string {
 	result, err := proto.RuntimeEvaluate{
 		Expression: `(function() {
 			var data = {};
@@ -2858,10 +3457,10 @@ func (s *Solver) extractSessionStorage(page *rod.Page) map[string]string {
 	}
 
 	// Check total size limit before parsing
-	if len(jsonStr) > maxStorageSize {
+	if len(jsonStr) > limits.MaxStorageSize {
 		log.Warn().
 			Int("size", len(jsonStr)).
-			Int("max", maxStorageSize).
+			Int("max", limits.MaxStorageSize).
 			Msg("sessionStorage data exceeds size limit, truncating")
 		return nil
 	}
@@ -2873,15 +3472,15 @@ func (s *Solver) extractSessionStorage(page *rod.Page) map[string]string {
 	}
 
 	// Enforce item count limit
-	if len(data) > maxStorageItems {
+	if len(data) > limits.MaxStorageItems {
 		log.Warn().
 			Int("count", len(data)).
-			Int("max", maxStorageItems).
+			Int("max", limits.MaxStorageItems).
 			Msg("sessionStorage item count exceeds limit, truncating")
-		truncated := make(map[string]string, maxStorageItems)
+		truncated := make(map[string]string, limits.MaxStorageItems)
 		count := 0
 		for k, v := range data {
-			if count >= maxStorageItems {
+			if count >= limits.MaxStorageItems {
 				break
 			}
 			truncated[k] = v
@@ -2984,134 +3583,274 @@ func (s *Solver) extractResponseHeaders(page *rod.Page) map[string]string {
 	return data
 }
 
-// captureScreenshot captures a PNG screenshot of the page.
-// Returns an error if the screenshot exceeds the maximum size limit.
-func (s *Solver) captureScreenshot(page *rod.Page) ([]byte, error) {
-	// Use full page screenshot
-	screenshot, err := page.Screenshot(true, &proto.PageCaptureScreenshot{
-		Format:  proto.PageCaptureScreenshotFormatPng,
-		Quality: nil, // PNG doesn't use quality
-	})
+// ScreenshotOptions selects the format and area of a screenshot.
+type ScreenshotOptions struct {
+	Format   string // types.ScreenshotPNG (default), ScreenshotJPEG or ScreenshotWebP
+	Quality  int    // JPEG/WebP quality 1-100 (0 = Chrome's default)
+	Viewport bool   // Capture only the visible viewport instead of the full page
+	Selector string // Capture only the first element matching this selector
+}
+
+// screenshot returns how to capture the result's screenshot, or nil when
+// none was requested.
+func (o *SolveOptions) screenshot() *ScreenshotOptions {
+	if !o.Screenshot {
+		return nil
+	}
+	return &o.ScreenshotOptions
+}
+
+// captureScreenshot captures a screenshot of the page, or of one element.
+// Returns an error if the screenshot exceeds maxSize bytes.
+func (s *Solver) captureScreenshot(page *rod.Page, opts *ScreenshotOptions, maxSize int) ([]byte, error) {
+	req := screenshotRequest(opts)
+
+	var screenshot []byte
+	var err error
+	if opts.Selector != "" {
+		screenshot, err = captureElementScreenshot(page, opts.Selector, req)
+	} else {
+		screenshot, err = page.Screenshot(!opts.Viewport, req)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("screenshot capture failed: %w", err)
 	}
 
 	// Enforce size limit to prevent memory exhaustion
-	if len(screenshot) > maxScreenshotSize {
+	if len(screenshot) > maxSize {
 		log.Warn().
 			Int("size", len(screenshot)).
-			Int("max", maxScreenshotSize).
+			Int("max", maxSize).
 			Msg("Screenshot exceeds maximum size limit, returning error")
-		return nil, fmt.Errorf("screenshot size %d exceeds maximum limit of %d bytes", len(screenshot), maxScreenshotSize)
+		return nil, fmt.Errorf("screenshot size %d exceeds maximum limit of %d bytes", len(screenshot), maxSize)
 	}
 
 	return screenshot, nil
 }
 
+// screenshotRequest builds the capture call for the format and quality.
+func screenshotRequest(opts *ScreenshotOptions) *proto.PageCaptureScreenshot {
+	req := &proto.PageCaptureScreenshot{Format: proto.PageCaptureScreenshotFormatPng}
+	switch opts.Format {
+	case types.ScreenshotJPEG:
+		req.Format = proto.PageCaptureScreenshotFormatJpeg
+	case types.ScreenshotWebP:
+		req.Format = proto.PageCaptureScreenshotFormatWebp
+	}
+	// PNG is lossless and has no quality
+	if opts.Quality > 0 && req.Format != proto.PageCaptureScreenshotFormatPng {
+		quality := opts.Quality
+		req.Quality = &quality
+	}
+	return req
+}
+
+// captureElementScreenshot clips the capture to the element's box. The
+// element must already be on the page; result building does not wait for it.
+func captureElementScreenshot(page *rod.Page, selector string, req *proto.PageCaptureScreenshot) ([]byte, error) {
+	el, err := page.Sleeper(rod.NotFoundSleeper).Element(selector)
+	if err != nil {
+		return nil, fmt.Errorf("screenshot element %q not found: %w", selector, err)
+	}
+	if err := el.ScrollIntoView(); err != nil {
+		return nil, fmt.Errorf("failed to scroll to %q: %w", selector, err)
+	}
+	shape, err := el.Shape()
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure %q: %w", selector, err)
+	}
+	box := shape.Box()
+	if box == nil || box.Width == 0 || box.Height == 0 {
+		return nil, fmt.Errorf("screenshot element %q has no visible box", selector)
+	}
+	metrics, err := proto.PageGetLayoutMetrics{}.Call(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get layout metrics: %w", err)
+	}
+
+	// The box is relative to the viewport, the clip to the document
+	var scrollX, scrollY float64
+	if vp := metrics.CSSLayoutViewport; vp != nil {
+		scrollX, scrollY = float64(vp.PageX), float64(vp.PageY)
+	}
+	req.Clip = &proto.PageViewport{
+		X:      box.X + scrollX,
+		Y:      box.Y + scrollY,
+		Width:  box.Width,
+		Height: box.Height,
+		Scale:  1,
+	}
+	req.CaptureBeyondViewport = true
+	res, err := req.Call(page)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
 // SolveWithPage solves a challenge using an existing page (for session support).
-func (s *Solver) SolveWithPage(ctx context.Context, page *rod.Page, opts *SolveOptions) (*Result, error) {
-	log.Info().
+func (s *Solver) SolveWithPage(ctx context.Context, page *rod.Page, opts *SolveOptions) (result *Result, err error) {
+	ctx, span := tracing.Start(ctx, "solver.solve",
+		attribute.String("flaresolverr.domain", extractDomainFromURL(opts.URL)),
+		attribute.Bool("solver.session_page", true))
+	defer func() { tracing.End(span, err) }()
+	ctx = s.withResponseLimits(ctx, opts)
+
+	log.Ctx(ctx).Info().
 		Str("url", opts.URL).
 		Bool("disable_media", opts.DisableMedia).
 		Int("wait_seconds", opts.WaitInSeconds).
 		Msg("Starting solve with existing page")
 
-	// Apply stealth patches only to fresh/blank pages
-	// On session reuse, the page already has content and stealth was already applied
-	// Trying to re-apply stealth to a loaded page causes errors due to stale JS context
+	s.applyBrowserIdentity(page.Browser(), opts)
+
+	// Register stealth patches once, while the session's page is still blank.
+	// The scripts run in every document the page loads from then on, so a
+	// reused session page already has them; registering again would stack a
+	// second copy on each navigation.
 	pageInfo, _ := page.Info()
 	if pageInfo == nil || pageInfo.URL == "" || pageInfo.URL == "about:blank" {
 		if opts.Fingerprint != nil {
 			profile := browser.ResolveProfile(opts.Fingerprint.Profile, opts.Fingerprint.Overrides, opts.Fingerprint.DisablePatches)
 			if err := browser.ApplyStealthToPageWithProfile(page, profile); err != nil {
-				log.Warn().Err(err).Msg("Failed to apply stealth patches with fingerprint profile")
+				log.Ctx(ctx).Warn().Err(err).Msg("Failed to apply stealth patches with fingerprint profile")
 			}
 		} else {
 			if err := browser.ApplyStealthToPage(page); err != nil {
-				log.Warn().Err(err).Msg("Failed to apply stealth patches")
+				log.Ctx(ctx).Warn().Err(err).Msg("Failed to apply stealth patches")
 			}
 		}
 		// Screen/window geometry coherence (the full stealthScript already sets a
 		// Linux-correct WebGL renderer, but not screen geometry).
 		if err := browser.ApplyGate2Corrections(page); err != nil {
-			log.Warn().Err(err).Msg("Failed to apply gate-2 fingerprint corrections (session)")
+			log.Ctx(ctx).Warn().Err(err).Msg("Failed to apply gate-2 fingerprint corrections (session)")
 		}
 		if tz := resolveTimezone(opts); tz != "" {
 			if err := browser.ApplyTimezoneOverride(page, tz); err != nil {
-				log.Warn().Err(err).Str("timezone", tz).Msg("Failed to apply timezone override")
+				log.Ctx(ctx).Warn().Err(err).Str("timezone", tz).Msg("Failed to apply timezone override")
+			}
+		}
+		if opts.UserAgent != "" {
+			if err := browser.SetUserAgent(page, opts.UserAgent); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("Failed to set user agent")
 			}
 		}
 	} else {
-		log.Debug().Str("url", pageInfo.URL).Msg("Skipping stealth on reused session page")
+		log.Ctx(ctx).Debug().Str("url", pageInfo.URL).Msg("Skipping stealth on reused session page")
+		// A timezone the request names explicitly still applies
+		if opts.Timezone != "" {
+			if err := browser.ApplyTimezoneOverride(page, opts.Timezone); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Str("timezone", opts.Timezone).Msg("Failed to apply timezone override")
+			}
+		}
+	}
+	if err := browser.ApplyLocaleOverride(page, opts.Locale, opts.UserAgent); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("locale", opts.Locale).Msg("Failed to apply locale override")
+	}
+	if opts.Viewport != nil {
+		if err := browser.SetViewport(page, opts.Viewport.Width, opts.Viewport.Height); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("Failed to set viewport")
+		}
 	}
 
-	// Set up media blocking if requested
-	if opts.DisableMedia {
-		mediaCleanup := setupMediaBlocking(page)
-		defer mediaCleanup()
-		log.Debug().Msg("Media blocking enabled")
+	// Block media and blockUrls requests if asked to
+	if blocksRequests(opts) {
+		blockCleanup := setupRequestBlocking(page, opts)
+		defer blockCleanup()
+		log.Ctx(ctx).Debug().
+			Bool("disable_media", opts.DisableMedia).
+			Int("block_patterns", len(opts.BlockURLs)).
+			Msg("Request blocking enabled")
 	}
 
 	// Set cookies if provided
 	if len(opts.Cookies) > 0 {
 		if err := s.setCookies(page, opts.Cookies, opts.URL); err != nil {
-			log.Warn().Err(err).Msg("Failed to set cookies")
+			log.Ctx(ctx).Warn().Err(err).Msg("Failed to set cookies")
 		}
 	}
 
 	// Create timeout context
-	solveCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	solveCtx, cancel := context.WithTimeout(withPollOptions(withTurnstileMethods(withSolveProxy(s.withPreferredProvider(ctx, extractDomainFromURL(opts.URL)), opts.Proxy), opts.TurnstileMethods), opts.Poll), opts.Timeout)
 	defer cancel()
 
+	// Session browsers are launched behind the session's proxy; its
+	// credentials are answered per solve. The page outlives the solve, so
+	// interception is switched off again afterwards.
+	if opts.Proxy != nil && opts.Proxy.Username != "" {
+		proxyCleanup, err := setupProxyAuth(solveCtx, page, opts.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("proxy authentication setup failed: %w", err)
+		}
+		defer func() {
+			proxyCleanup()
+			_ = proto.FetchDisable{}.Call(page)
+		}()
+	}
+
+	if opts.VerifyProxy {
+		egressIP, err := s.verifyEgress(solveCtx, page)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if result != nil {
+				result.EgressIP = egressIP
+			}
+		}()
+	}
+
 	// Set up network capture BEFORE navigation to capture response events
 	networkCapture, networkCleanup, err := setupNetworkCapture(solveCtx, page)
 	if err != nil {
-		log.Warn().Err(err).Msg("Failed to setup network capture, using defaults")
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to setup network capture, using defaults")
 	}
 	defer networkCleanup()
+	networkCapture.SetCapturePatterns(opts.CaptureRequests)
+	if opts.ReturnHar {
+		networkCapture.EnableHAR()
+	}
+	recording := s.startRecording(solveCtx, page, opts)
+	defer func() { s.finishRecording(recording, opts, result, err) }()
 
 	// Navigate (GET or POST)
 	// Use page.Context() inline to avoid reassigning the page variable
-	if opts.IsPost && opts.PostData != "" {
-		// Dispatch POST based on content type
-		if opts.ContentType == types.ContentTypeJSON {
-			// JSON POST via Fetch API
-			if err := s.navigatePostJSON(solveCtx, page.Context(solveCtx), opts.URL, opts.PostData, opts.Headers); err != nil {
-				return nil, fmt.Errorf("JSON POST navigation to %s failed: %w", opts.URL, err)
-			}
-		} else {
-			// Form POST (default, backward compatible)
-			if err := s.navigatePost(solveCtx, page.Context(solveCtx), opts.URL, opts.PostData); err != nil {
-				return nil, fmt.Errorf("form POST navigation to %s failed: %w", opts.URL, err)
-			}
+	if opts.Method != "" || (opts.IsPost && opts.PostData != "") {
+		if err := s.dispatchBodyRequest(solveCtx, page.Context(solveCtx), opts, networkCapture); err != nil {
+			return nil, err
 		}
 	} else {
 		// Set custom headers before navigation (for GET requests)
 		if len(opts.Headers) > 0 {
 			if err := s.setCustomHeaders(page, opts.Headers); err != nil {
-				log.Warn().Err(err).Msg("Failed to set custom headers")
+				log.Ctx(ctx).Warn().Err(err).Msg("Failed to set custom headers")
 			}
 		}
-		if err := page.Context(solveCtx).Navigate(opts.URL); err != nil {
+		if err := navigate(solveCtx, page, opts.URL); err != nil {
 			return nil, fmt.Errorf("failed to navigate to %s: %w", opts.URL, err)
 		}
 	}
 
 	// Wait for load
 	if err := page.Context(solveCtx).WaitLoad(); err != nil {
-		log.Warn().Err(err).Msg("WaitLoad failed, continuing anyway")
+		log.Ctx(ctx).Warn().Err(err).Msg("WaitLoad failed, continuing anyway")
 	}
 
 	// Solve with DNS pinning
-	result, err := s.solveLoop(solveCtx, page, opts.URL, opts.Screenshot, opts.ExpectedIP, opts.TabsTillVerify, opts.SkipResponseValidation, networkCapture, opts.CookieExtractDelay)
+	result, err = s.solveLoop(solveCtx, page, opts.URL, opts.screenshot(), opts.ExpectedIP, opts.TabsTillVerify, opts.SkipResponseValidation, networkCapture, opts.CookieExtractDelay)
 	if err != nil {
 		return nil, fmt.Errorf("solve loop failed for %s: %w", opts.URL, err)
 	}
+	if opts.UserAgent != "" {
+		result.UserAgent = opts.UserAgent
+	}
 
 	// Post-solve processing: download re-fetch, custom JS, waitInSeconds.
 	// Shared with the non-session Solve path so executeJs/download/cookie
 	// re-fetch behave identically whether or not a session is active.
-	s.applyPostSolveProcessing(solveCtx, page, opts, result)
+	if err := s.applyPostSolveProcessing(solveCtx, page, opts, networkCapture, result); err != nil {
+		return nil, err
+	}
 
 	return result, nil
 }
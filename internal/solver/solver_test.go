@@ -1,7 +1,13 @@
 package solver
 
 import (
+	"slices"
 	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/features"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
 )
 
 func TestDetectChallenge(t *testing.T) {
@@ -102,6 +108,76 @@ func TestDetectChallenge(t *testing.T) {
 			html:     "<html><body>Just a moment <div class=\"cf-turnstile\"></div></body></html>",
 			expected: ChallengeTurnstile,
 		},
+		{
+			name:     "hcaptcha widget",
+			html:     "<html><body><div class=\"h-captcha\" data-sitekey=\"10000000-ffff-ffff-ffff-000000000001\"></div></body></html>",
+			expected: ChallengeHCaptcha,
+		},
+		{
+			name:     "hcaptcha iframe",
+			html:     "<html><body><iframe src=\"https://newassets.hcaptcha.com/captcha/v1/static/hcaptcha.html#sitekey=abc\"></iframe></body></html>",
+			expected: ChallengeHCaptcha,
+		},
+		{
+			name:     "hcaptcha fallback takes precedence over turnstile",
+			html:     "<html><body>Just a moment <div id=\"turnstile-wrapper\"><div class=\"h-captcha\"></div></div></body></html>",
+			expected: ChallengeHCaptcha,
+		},
+		{
+			name:     "recaptcha is not hcaptcha",
+			html:     "<html><body><div class=\"g-recaptcha\" data-sitekey=\"6Le\"></div></body></html>",
+			expected: ChallengeRecaptcha,
+		},
+		{
+			name:     "recaptcha v3 script",
+			html:     "<html><head><script src=\"https://www.google.com/recaptcha/api.js?render=6Le\"></script></head><body></body></html>",
+			expected: ChallengeRecaptcha,
+		},
+		{
+			name:     "aws waf challenge",
+			html:     "<html><head><script>window.gokuProps = {\"key\":\"AQID\"};</script><script src=\"https://abc.token.awswaf.com/abc/challenge.js\"></script></head><body><div id=\"challenge-container\"></div></body></html>",
+			expected: ChallengeAWSWAF,
+		},
+		{
+			name:     "aws waf captcha",
+			html:     "<html><body><div id=\"captcha-container\"></div><script>AwsWafCaptcha.renderCaptcha(document.getElementById('captcha-container'), {});</script></body></html>",
+			expected: ChallengeAWSWAF,
+		},
+		{
+			name:     "aws waf sdk on a normal page",
+			html:     "<html><head><script src=\"https://abc.token.awswaf.com/abc/challenge.js\"></script></head><body>Shop</body></html>",
+			expected: ChallengeNone,
+		},
+		{
+			name:     "datadome captcha",
+			html:     "<html><body><script>var dd={'rt':'c','cid':'AHrlqAAA','t':'fe','host':'geo.captcha-delivery.com'}</script><iframe src=\"https://geo.captcha-delivery.com/captcha/?initialCid=AHrlqAAA\"></iframe></body></html>",
+			expected: ChallengeDataDome,
+		},
+		{
+			name:     "datadome tag on a normal page",
+			html:     "<html><head><script src=\"https://js.datadome.co/tags.js\"></script></head><body>Shop</body></html>",
+			expected: ChallengeNone,
+		},
+		{
+			name:     "incapsula interstitial",
+			html:     "<html><head><meta name=\"robots\" content=\"noindex,nofollow\"><script src=\"/_Incapsula_Resource?SWJIYLWA=719d34d31c8e3a6e6fffd425f7e032f3\"></script></head><body><iframe id=\"main-iframe\" src=\"/_Incapsula_Resource?CWUDNSAI=9\">Request unsuccessful. Incapsula incident ID: 1234-5678</iframe></body></html>",
+			expected: ChallengeIncapsula,
+		},
+		{
+			name:     "incapsula script on a normal page",
+			html:     "<html><body>Shop<script src=\"/_Incapsula_Resource?SWJIYLWA=719d34d31c8e3a6e6fffd425f7e032f3\"></script></body></html>",
+			expected: ChallengeNone,
+		},
+		{
+			name:     "managed challenge",
+			html:     "<html><body><div id=\"cf-chl-widget-x1y2z\"></div><script>window._cf_chl_opt={cvId: '3',cType: 'managed',cRay: '8a1b'};</script><script>var a={ctype: 'managed'};</script><div class=\"cf-turnstile\"></div></body></html>",
+			expected: ChallengeManaged,
+		},
+		{
+			name:     "turnstile takes precedence over recaptcha",
+			html:     "<html><body><div class=\"cf-turnstile\"></div><div class=\"g-recaptcha\"></div></body></html>",
+			expected: ChallengeTurnstile,
+		},
 	}
 
 	for _, tt := range tests {
@@ -131,6 +207,21 @@ func TestChallengeTypeString(t *testing.T) {
 	if ChallengeAccessDenied != 4 {
 		t.Errorf("ChallengeAccessDenied should be 4, got %d", ChallengeAccessDenied)
 	}
+	if ChallengeRecaptcha != 5 {
+		t.Errorf("ChallengeRecaptcha should be 5, got %d", ChallengeRecaptcha)
+	}
+	if ChallengeAWSWAF != 6 {
+		t.Errorf("ChallengeAWSWAF should be 6, got %d", ChallengeAWSWAF)
+	}
+	if ChallengeDataDome != 7 {
+		t.Errorf("ChallengeDataDome should be 7, got %d", ChallengeDataDome)
+	}
+	if ChallengeIncapsula != 8 {
+		t.Errorf("ChallengeIncapsula should be 8, got %d", ChallengeIncapsula)
+	}
+	if ChallengeManaged != 9 {
+		t.Errorf("ChallengeManaged should be 9, got %d", ChallengeManaged)
+	}
 }
 
 func TestNewSolver(t *testing.T) {
@@ -306,3 +397,80 @@ func containsString(s, substr string) bool {
 	}
 	return false
 }
+
+// TestResolveTimezone verifies the request timezone wins over the
+// fingerprint override, which wins over the default
+func TestResolveTimezone(t *testing.T) {
+	opts := &SolveOptions{
+		DefaultTimezone: "America/New_York",
+		Fingerprint:     &types.FingerprintConfig{Overrides: map[string]any{"timezone": "Asia/Tokyo"}},
+	}
+	if got := resolveTimezone(opts); got != "Asia/Tokyo" {
+		t.Errorf("fingerprint override: got %q", got)
+	}
+	opts.Timezone = "Europe/Paris"
+	if got := resolveTimezone(opts); got != "Europe/Paris" {
+		t.Errorf("request timezone: got %q", got)
+	}
+	if got := resolveTimezone(&SolveOptions{DefaultTimezone: "UTC"}); got != "UTC" {
+		t.Errorf("default: got %q", got)
+	}
+}
+
+// TestEnabledTurnstileMethods verifies feature flags remove Turnstile methods
+// per domain, and that every method has a flag
+func TestEnabledTurnstileMethods(t *testing.T) {
+	for _, method := range defaultTurnstileMethods {
+		if !(*features.Flags)(nil).Enabled("turnstile."+method, "") {
+			t.Errorf("Turnstile method %q has no feature flag", method)
+		}
+	}
+
+	flags, err := features.Parse("turnstile.positional=off,turnstile.shadow@example.com=off")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Solver{}
+	s.SetFeatures(flags)
+	order := []string{"wait", "shadow", "positional"}
+	if got := s.enabledTurnstileMethods("example.com", slices.Clone(order)); !slices.Equal(got, []string{"wait"}) {
+		t.Errorf("example.com methods = %v", got)
+	}
+	if got := s.enabledTurnstileMethods("other.org", slices.Clone(order)); !slices.Equal(got, []string{"wait", "shadow"}) {
+		t.Errorf("other.org methods = %v", got)
+	}
+}
+
+func TestBlockRequest(t *testing.T) {
+	opts := &SolveOptions{
+		BlockURLs: []string{"google-analytics.com", "https://ads.*/pixel*", "*"},
+		AllowURLs: []string{"https://example.com/"},
+	}
+	tests := []struct {
+		name         string
+		opts         *SolveOptions
+		url          string
+		resourceType proto.NetworkResourceType
+		want         bool
+	}{
+		{"blocked by substring", opts, "https://www.google-analytics.com/collect", proto.NetworkResourceTypeXHR, true},
+		{"allowed wins over block", opts, "https://example.com/app.js", proto.NetworkResourceTypeScript, false},
+		{"challenge platform kept", opts, "https://example.com.evil/cdn-cgi/challenge-platform/h/b/orchestrate", proto.NetworkResourceTypeScript, false},
+		{"turnstile kept", opts, "https://challenges.cloudflare.com/turnstile/v0/api.js", proto.NetworkResourceTypeScript, false},
+		{"no lists", &SolveOptions{}, "https://cdn.example.com/logo.png", proto.NetworkResourceTypeImage, false},
+		{"disableMedia image", &SolveOptions{DisableMedia: true}, "https://cdn.example.com/logo.png", proto.NetworkResourceTypeImage, true},
+		{"disableMedia script", &SolveOptions{DisableMedia: true}, "https://cdn.example.com/app.js", proto.NetworkResourceTypeScript, false},
+		{"allowUrls exempts media", &SolveOptions{DisableMedia: true, AllowURLs: []string{"/captcha/"}}, "https://example.com/captcha/img.png", proto.NetworkResourceTypeImage, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blockRequest(tt.opts, tt.url, tt.resourceType); got != tt.want {
+				t.Errorf("blockRequest(%q) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+
+	if blocksRequests(&SolveOptions{AllowURLs: []string{"x"}}) {
+		t.Error("blocksRequests() with only allowUrls should not intercept requests")
+	}
+}
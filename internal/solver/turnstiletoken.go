@@ -0,0 +1,314 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/stealth"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/browser"
+	"github.com/Rorqualx/flaresolverr-go/internal/captcha"
+	"github.com/Rorqualx/flaresolverr-go/internal/security"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// Turnstile token polling. The token is checked every turnstileTokenPoll so
+// the solve returns the moment it exists; invisible and managed widgets
+// usually pass on their own within a couple of seconds.
+const (
+	turnstileTokenPoll = 250 * time.Millisecond
+	// turnstileRenderAfter is how long to wait for the page to render a widget
+	// with the requested sitekey before rendering one ourselves.
+	turnstileRenderAfter = 2 * time.Second
+	// turnstileInteractAfter is how long a widget may sit unsolved before the
+	// native click methods are tried.
+	turnstileInteractAfter = 4 * time.Second
+)
+
+// TurnstileToken is the outcome of SolveTurnstileToken.
+type TurnstileToken struct {
+	Token     string
+	URL       string // Page the widget was solved on
+	UserAgent string
+	// Method is "auto" when the widget passed unaided, the native method that
+	// passed it, or the external provider that solved it.
+	Method string
+}
+
+// turnstileTokenJS returns the cf-turnstile-response token of the widget with
+// the given sitekey, or of the widget rendered by turnstileRenderJS. A lone
+// script-rendered widget (no data-sitekey in the DOM) is assumed to match.
+const turnstileTokenJS = `(k) => {
+	if (window.__flaresolverrTurnstileToken) return window.__flaresolverrTurnstileToken;
+	var widgets = document.querySelectorAll('[data-sitekey]');
+	for (var i = 0; i < widgets.length; i++) {
+		if (widgets[i].getAttribute('data-sitekey') !== k) continue;
+		var input = widgets[i].querySelector('[name="cf-turnstile-response"]');
+		if (input && input.value) return input.value;
+	}
+	var inputs = document.querySelectorAll('[name="cf-turnstile-response"]');
+	if (widgets.length === 0 && inputs.length === 1 && inputs[0].value) return inputs[0].value;
+	return '';
+}`
+
+// turnstileWidgetJS reports whether the page has a widget for the sitekey.
+const turnstileWidgetJS = `(k) => {
+	if (document.getElementById('flaresolverr-turnstile')) return true;
+	var widgets = document.querySelectorAll('[data-sitekey]');
+	for (var i = 0; i < widgets.length; i++) {
+		if (widgets[i].getAttribute('data-sitekey') === k) return true;
+	}
+	return widgets.length === 0 && document.querySelectorAll('[name="cf-turnstile-response"]').length === 1;
+}`
+
+// turnstileRenderJS renders a widget for the sitekey, loading the Turnstile
+// API first when the page has not. The token lands in a window global.
+const turnstileRenderJS = `(k) => {
+	var id = 'flaresolverr-turnstile';
+	if (document.getElementById(id)) return;
+	var box = document.createElement('div');
+	box.id = id;
+	document.body.appendChild(box);
+	var render = function() {
+		window.turnstile.render('#' + id, {
+			sitekey: k,
+			callback: function(token) { window.__flaresolverrTurnstileToken = token; }
+		});
+	};
+	if (window.turnstile && typeof window.turnstile.render === 'function') {
+		render();
+		return;
+	}
+	window.__flaresolverrTurnstileOnload = render;
+	var script = document.createElement('script');
+	script.src = 'https://challenges.cloudflare.com/turnstile/v0/api.js?onload=__flaresolverrTurnstileOnload&render=explicit';
+	script.async = true;
+	document.head.appendChild(script);
+}`
+
+// SolveTurnstileToken loads opts.URL and returns the token of the Turnstile
+// widget with siteKey, without building a page result. The widget is left to
+// pass on its own first, then clicked with the native methods, then sent to
+// the external solver chain; whichever yields a token first wins. A page
+// without the widget gets one rendered for the sitekey.
+//
+// Only URL, Timeout, Proxy, Cookies, UserAgent, Fingerprint, DefaultTimezone,
+// ExpectedIP, Priority and TabsTillVerify are used from opts.
+func (s *Solver) SolveTurnstileToken(ctx context.Context, opts *SolveOptions, siteKey string) (token *TurnstileToken, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Ctx(ctx).Error().
+				Interface("panic", r).
+				Str("url", opts.URL).
+				Msg("Panic recovered in SolveTurnstileToken")
+			err = fmt.Errorf("unexpected error during solve: %v", r)
+		}
+	}()
+	if siteKey == "" {
+		return nil, fmt.Errorf("sitekey is required")
+	}
+	if opts.Timeout <= 0 {
+		return nil, fmt.Errorf("timeout must be positive, got %v", opts.Timeout)
+	}
+	timeout := max(opts.Timeout, time.Second)
+
+	var proxyURL string
+	if opts.Proxy != nil {
+		// SOCKS5 credentials travel in the URL to the pool's forwarder
+		proxyURL = browser.ProxyLaunchURL(opts.Proxy.URL, opts.Proxy.Username, opts.Proxy.Password)
+	}
+	var browserInstance *rod.Browser
+	if proxyURL != "" {
+		log.Ctx(ctx).Info().
+			Str("proxy_url", security.RedactProxyURL(proxyURL)).
+			Msg("Using dedicated browser with per-request proxy")
+		var spawnErr error
+		browserInstance, spawnErr = s.pool.AcquireDedicated(ctx, nil, proxyURL)
+		if spawnErr != nil {
+			return nil, fmt.Errorf("failed to spawn dedicated browser: %w", spawnErr)
+		}
+		defer s.pool.ReleaseDedicated(browserInstance)
+	} else {
+		var acquireErr error
+		browserInstance, acquireErr = s.pool.AcquirePriority(ctx, opts.Priority)
+		if acquireErr != nil {
+			return nil, types.NewPoolAcquireError("failed to acquire browser", acquireErr)
+		}
+		defer s.pool.Release(browserInstance)
+		s.pool.RotateRequestIdentity(browserInstance)
+		s.applyBrowserIdentity(browserInstance, opts)
+	}
+
+	solveCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	page, err := stealth.Page(browserInstance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stealth page: %w", err)
+	}
+	defer page.Close()
+
+	if err := browser.ApplyGate2Corrections(page); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to apply gate-2 fingerprint corrections (turnstile)")
+	}
+	if tz := resolveTimezone(opts); tz != "" {
+		if err := browser.ApplyTimezoneOverride(page, tz); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("timezone", tz).Msg("Failed to apply timezone override")
+		}
+	}
+	ua := s.userAgent
+	if opts.UserAgent != "" {
+		ua = opts.UserAgent
+	}
+	if ua != "" {
+		if err := browser.SetUserAgent(page, ua); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("Failed to set user agent")
+		}
+	}
+	applyLocaleAndViewport(ctx, page, opts, ua)
+
+	proxyCleanup, err := setupProxyAuth(solveCtx, page, opts.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("proxy authentication setup failed: %w", err)
+	}
+	defer proxyCleanup()
+
+	if len(opts.Cookies) > 0 {
+		if err := s.setCookies(page, opts.Cookies, opts.URL); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("Failed to set cookies")
+		}
+	}
+
+	if err := page.Context(solveCtx).Navigate(opts.URL); err != nil {
+		if solveCtx.Err() != nil {
+			return nil, fmt.Errorf("navigation timed out for %s: %w", opts.URL, solveCtx.Err())
+		}
+		return nil, fmt.Errorf("failed to navigate to %s: %w", opts.URL, err)
+	}
+	if err := page.Context(solveCtx).WaitLoad(); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("WaitLoad failed, continuing anyway")
+	}
+	if err := s.validateResponseURL(page, opts.ExpectedIP, opts.SkipResponseValidation); err != nil {
+		return nil, err
+	}
+
+	token, err = s.waitTurnstileToken(solveCtx, page, opts, siteKey, ua)
+	if err != nil {
+		return nil, err
+	}
+	token.UserAgent = ua
+	return token, nil
+}
+
+// waitTurnstileToken polls the page for the sitekey's token, escalating from
+// waiting to rendering, native clicks and finally the external solver chain.
+func (s *Solver) waitTurnstileToken(ctx context.Context, page *rod.Page, opts *SolveOptions, siteKey, ua string) (*TurnstileToken, error) {
+	pageURL := opts.URL
+	if info, err := page.Info(); err == nil && info.URL != "" {
+		pageURL = info.URL
+	}
+	domain := extractDomainFromURL(pageURL)
+
+	var methods []string
+	for _, method := range s.enabledTurnstileMethods(domain, s.getTurnstileMethodOrder(domain)) {
+		if method != "wait" { // polling is the wait
+			methods = append(methods, method)
+		}
+	}
+
+	start := time.Now()
+	checkedWidget := false
+	externalTried := false
+	lastMethod := "auto"
+	next := 0
+
+	for {
+		if tok := s.readTurnstileToken(page, siteKey); tok != "" {
+			if lastMethod != "auto" {
+				s.recordTurnstileMethod(domain, lastMethod, true)
+			}
+			log.Ctx(ctx).Info().Str("method", lastMethod).Dur("elapsed", time.Since(start)).Msg("Turnstile token obtained")
+			return &TurnstileToken{Token: tok, URL: pageURL, Method: lastMethod}, nil
+		}
+
+		elapsed := time.Since(start)
+		if !checkedWidget && elapsed >= turnstileRenderAfter {
+			checkedWidget = true
+			if !s.hasTurnstileWidget(page, siteKey) {
+				log.Ctx(ctx).Debug().Msg("No Turnstile widget for sitekey, rendering one")
+				if _, err := page.Context(ctx).Eval(turnstileRenderJS, siteKey); err != nil {
+					log.Ctx(ctx).Warn().Err(err).Msg("Failed to render Turnstile widget")
+				}
+			}
+		}
+
+		switch {
+		case elapsed >= turnstileInteractAfter && next < len(methods):
+			if lastMethod != "auto" {
+				s.recordTurnstileMethod(domain, lastMethod, false)
+			}
+			lastMethod = methods[next]
+			next++
+			if err := s.runTurnstileMethod(ctx, page, lastMethod, opts.TabsTillVerify); err != nil {
+				log.Ctx(ctx).Debug().Err(err).Str("method", lastMethod).Msg("Turnstile method failed")
+			}
+		case elapsed >= turnstileInteractAfter && !externalTried && s.solverChain != nil && s.solverChain.IsEnabled():
+			externalTried = true
+			result, err := s.solverChain.SolveTurnstileToken(s.withPreferredProvider(ctx, domain), &captcha.TurnstileRequest{
+				SiteKey:   siteKey,
+				PageURL:   pageURL,
+				UserAgent: ua,
+				Proxy:     opts.Proxy,
+			})
+			if err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("External Turnstile token solve failed")
+				break
+			}
+			return &TurnstileToken{Token: result.Token, URL: pageURL, Method: result.Provider}, nil
+		}
+
+		if !sleepWithContext(ctx, turnstileTokenPoll) {
+			return nil, types.NewChallengeTimeoutError(opts.URL)
+		}
+	}
+}
+
+// runTurnstileMethod runs one native Turnstile method by name.
+func (s *Solver) runTurnstileMethod(ctx context.Context, page *rod.Page, method string, tabsTillVerify int) error {
+	switch method {
+	case "shadow":
+		return s.solveTurnstileShadow(ctx, page)
+	case "keyboard":
+		return s.solveTurnstileKeyboard(ctx, page, tabsTillVerify)
+	case "widget":
+		return s.solveTurnstileWidget(ctx, page)
+	case "iframe":
+		return s.solveTurnstileClick(ctx, page)
+	case "positional":
+		return s.solveTurnstilePositional(ctx, page)
+	case "frames":
+		return s.solveTurnstileFrames(ctx, page)
+	}
+	return nil
+}
+
+// readTurnstileToken returns the sitekey's token, or "" while unsolved.
+func (s *Solver) readTurnstileToken(page *rod.Page, siteKey string) string {
+	result, err := page.Timeout(2*time.Second).Eval(turnstileTokenJS, siteKey)
+	if err != nil || result == nil {
+		return ""
+	}
+	return result.Value.Str()
+}
+
+// hasTurnstileWidget reports whether the page shows a widget for the sitekey.
+func (s *Solver) hasTurnstileWidget(page *rod.Page, siteKey string) bool {
+	result, err := page.Timeout(2*time.Second).Eval(turnstileWidgetJS, siteKey)
+	if err != nil || result == nil {
+		return false
+	}
+	return result.Value.Bool()
+}
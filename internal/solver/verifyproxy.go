@@ -0,0 +1,116 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/proxyhealth"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// verifyProxy checks, before the target is contacted, that the browser
+// really exits through a proxy: the IP-echo endpoint is loaded in the page
+// and the IP it reports is compared with the server's own.
+const (
+	// proxyVerifyTimeout bounds loading the IP-echo endpoint in the browser.
+	proxyVerifyTimeout = 20 * time.Second
+
+	// directIPTTL is how long the server's own IP is remembered.
+	directIPTTL = 10 * time.Minute
+)
+
+// directIP caches the server's own egress IP, fetched without a proxy.
+type directIP struct {
+	mu        sync.Mutex
+	ip        string
+	fetchedAt time.Time
+}
+
+// SetProxyVerifyURL sets the IP-echo endpoint used by verifyProxy. It must
+// return the caller's IP as plain text or {"ip": "..."}.
+func (s *Solver) SetProxyVerifyURL(u string) {
+	s.proxyVerifyURL = u
+}
+
+// verifyEgress loads the IP-echo endpoint in page and returns the IP the
+// browser exits from. It fails with types.ErrProxyVerification when the
+// endpoint cannot be reached or reports the server's own IP.
+func (s *Solver) verifyEgress(ctx context.Context, page *rod.Page) (string, error) {
+	if s.proxyVerifyURL == "" {
+		return "", fmt.Errorf("%w: no IP-echo endpoint configured (PROXY_VERIFY_URL)", types.ErrProxyVerification)
+	}
+	ctx, cancel := context.WithTimeout(ctx, proxyVerifyTimeout)
+	defer cancel()
+
+	p := page.Context(ctx)
+	if err := p.Navigate(s.proxyVerifyURL); err != nil {
+		return "", fmt.Errorf("%w: IP-echo endpoint unreachable through the proxy: %w", types.ErrProxyVerification, err)
+	}
+	if err := p.WaitLoad(); err != nil {
+		return "", fmt.Errorf("%w: IP-echo endpoint did not load through the proxy: %w", types.ErrProxyVerification, err)
+	}
+	text, err := p.Eval(`() => document.body ? document.body.innerText : ""`)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to read the IP-echo response: %w", types.ErrProxyVerification, err)
+	}
+	observed := proxyhealth.ParseIP([]byte(text.Value.Str()))
+	if observed == "" {
+		return "", fmt.Errorf("%w: IP-echo endpoint returned no IP; the proxy may have answered with an error page", types.ErrProxyVerification)
+	}
+
+	direct, err := s.directIP.get(ctx, s.proxyVerifyURL)
+	if err != nil {
+		// The browser reached the endpoint, so the proxy works; it just
+		// cannot be told apart from a direct connection
+		log.Ctx(ctx).Warn().Err(err).Msg("Could not look up the server's own IP, verifyProxy only checked connectivity")
+		return observed, nil
+	}
+	if observed == direct {
+		return "", fmt.Errorf("%w: browser exits from %s, the server's own IP; the proxy is not in use", types.ErrProxyVerification, observed)
+	}
+	log.Ctx(ctx).Debug().Str("egress_ip", observed).Msg("Proxy verified")
+	return observed, nil
+}
+
+// get returns the server's own IP, fetching it from endpoint without any
+// proxy when the cached value is missing or stale.
+func (d *directIP) get(ctx context.Context, endpoint string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.ip != "" && time.Since(d.fetchedAt) < directIPTTL {
+		return d.ip, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	// Explicitly no proxy, not even HTTP_PROXY from the environment
+	client := &http.Client{Transport: &http.Transport{Proxy: nil}, Timeout: proxyVerifyTimeout}
+	defer client.CloseIdleConnections()
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("IP-echo endpoint returned HTTP %d", resp.StatusCode)
+	}
+	ip := proxyhealth.ParseIP(body)
+	if ip == "" {
+		return "", fmt.Errorf("IP-echo endpoint returned no IP")
+	}
+	d.ip, d.fetchedAt = ip, time.Now()
+	return ip, nil
+}
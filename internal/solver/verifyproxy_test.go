@@ -0,0 +1,48 @@
+package solver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDirectIPCachesLookup(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write([]byte("198.51.100.4\n"))
+	}))
+	defer srv.Close()
+
+	var d directIP
+	for range 3 {
+		ip, err := d.get(context.Background(), srv.URL)
+		if err != nil || ip != "198.51.100.4" {
+			t.Fatalf("get() = %q, %v; want 198.51.100.4", ip, err)
+		}
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("endpoint hit %d times, want the IP cached after 1", n)
+	}
+
+	// Stale entries are fetched again
+	d.fetchedAt = time.Now().Add(-2 * directIPTTL)
+	if _, err := d.get(context.Background(), srv.URL); err != nil || hits.Load() != 2 {
+		t.Errorf("stale get() error = %v, hits = %d; want a refetch", err, hits.Load())
+	}
+}
+
+func TestDirectIPRejectsNonIP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html>rate limited</html>"))
+	}))
+	defer srv.Close()
+
+	var d directIP
+	if ip, err := d.get(context.Background(), srv.URL); err == nil {
+		t.Errorf("get() = %q, want an error for a body without an IP", ip)
+	}
+}
@@ -0,0 +1,80 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/rs/zerolog/log"
+)
+
+// waitForPollInterval is how often the cleared page is checked for the
+// waitForSelector element and waitForText text.
+const waitForPollInterval = 250 * time.Millisecond
+
+// waitForJS reports whether the page has an element matching the selector
+// and contains the text; an empty argument is always satisfied. An invalid
+// selector throws, failing the eval.
+const waitForJS = `(selector, text) => {
+	if (selector && !document.querySelector(selector)) return false;
+	if (text && !(document.body && document.body.innerText.includes(text))) return false;
+	return true;
+}`
+
+// applyWaitFor holds the result back until the page has the waitForSelector
+// element and the waitForText text, polling until the solve times out. Pages
+// that render their content after the load event otherwise come back as an
+// empty shell. The result is rebuilt from the page once they appear.
+func (s *Solver) applyWaitFor(ctx context.Context, page *rod.Page, opts *SolveOptions, result *Result) error {
+	if opts.WaitForSelector == "" && opts.WaitForText == "" {
+		return nil
+	}
+	if opts.Method != "" || opts.ReturnRawHtml || opts.Download {
+		return nil
+	}
+
+	start := time.Now()
+	for {
+		res, err := page.Context(ctx).Eval(waitForJS, opts.WaitForSelector, opts.WaitForText)
+		if err != nil {
+			if ctx.Err() != nil {
+				return waitForTimeoutError(opts, ctx.Err())
+			}
+			return fmt.Errorf("waitForSelector %q could not be evaluated: %w", opts.WaitForSelector, err)
+		}
+		if res != nil && res.Value.Bool() {
+			break
+		}
+		if !sleepWithContext(ctx, waitForPollInterval) {
+			return waitForTimeoutError(opts, ctx.Err())
+		}
+	}
+	log.Ctx(ctx).Debug().
+		Str("selector", opts.WaitForSelector).
+		Bool("text", opts.WaitForText != "").
+		Dur("elapsed", time.Since(start)).
+		Msg("Waited for page content")
+
+	refreshed, err := s.buildResult(ctx, page, opts.URL, opts.screenshot(), opts.ExpectedIP, opts.SkipResponseValidation, nil, 0)
+	if err != nil {
+		return err
+	}
+	refreshed.StatusCode = result.StatusCode
+	refreshed.ResponseHeaders = result.ResponseHeaders
+	refreshed.UserAgent = result.UserAgent
+	refreshed.ClientRedirects = result.ClientRedirects
+	*result = *refreshed
+	return nil
+}
+
+// waitForTimeoutError names what never appeared on the page.
+func waitForTimeoutError(opts *SolveOptions, err error) error {
+	switch {
+	case opts.WaitForSelector != "" && opts.WaitForText != "":
+		return fmt.Errorf("timed out waiting for selector %q and text %q: %w", opts.WaitForSelector, opts.WaitForText, err)
+	case opts.WaitForSelector != "":
+		return fmt.Errorf("timed out waiting for selector %q: %w", opts.WaitForSelector, err)
+	}
+	return fmt.Errorf("timed out waiting for text %q: %w", opts.WaitForText, err)
+}
@@ -0,0 +1,45 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestApplyWaitForSkipped(t *testing.T) {
+	s := &Solver{}
+	result := &Result{HTML: "<html></html>"}
+	tests := []*SolveOptions{
+		{},
+		{WaitForSelector: "#app", Method: "PUT"},
+		{WaitForText: "Results", ReturnRawHtml: true},
+		{WaitForSelector: "#app", Download: true},
+	}
+	for _, opts := range tests {
+		// A nil page would panic if the options were not skipped
+		if err := s.applyWaitFor(context.Background(), nil, opts, result); err != nil {
+			t.Errorf("applyWaitFor(%+v) error = %v", opts, err)
+		}
+	}
+}
+
+func TestWaitForTimeoutError(t *testing.T) {
+	tests := []struct {
+		opts *SolveOptions
+		want string
+	}{
+		{&SolveOptions{WaitForSelector: "#app"}, `selector "#app"`},
+		{&SolveOptions{WaitForText: "Results"}, `text "Results"`},
+		{&SolveOptions{WaitForSelector: "#app", WaitForText: "Results"}, `selector "#app" and text "Results"`},
+	}
+	for _, tt := range tests {
+		err := waitForTimeoutError(tt.opts, context.DeadlineExceeded)
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("waitForTimeoutError() = %q, want it to mention %s", err, tt.want)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("waitForTimeoutError() should wrap the context error")
+		}
+	}
+}
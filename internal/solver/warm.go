@@ -0,0 +1,74 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rs/zerolog/log"
+)
+
+// WarmResult reports where a warm-up navigation landed and the state of the
+// page's Cloudflare clearance.
+type WarmResult struct {
+	URL              string
+	Challenged       bool      // A challenge was showing after the load
+	HasClearance     bool      // A cf_clearance cookie applies to URL
+	ClearanceExpires time.Time // Zero for a session cookie or no clearance
+}
+
+// WarmPage navigates page to url and waits for the load, without running the
+// solve loop. Visiting the site with the session's cookies keeps cf_clearance
+// (and the site's own session) warm; if Cloudflare challenges instead,
+// Challenged tells the caller a full solve is due.
+func (s *Solver) WarmPage(ctx context.Context, page *rod.Page, url string) (*WarmResult, error) {
+	p := page.Context(ctx)
+	if err := p.Navigate(url); err != nil {
+		return nil, fmt.Errorf("failed to navigate to %s: %w", url, err)
+	}
+	if err := p.WaitLoad(); err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("WaitLoad during session warm-up failed, continuing")
+	}
+
+	result := &WarmResult{URL: url}
+	if info, err := p.Info(); err == nil && info.URL != "" {
+		result.URL = info.URL
+	}
+	result.Challenged = s.challengeShowing(p)
+
+	cookies, err := proto.NetworkGetCookies{Urls: []string{result.URL}}.Call(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cookies after warm-up: %w", err)
+	}
+	for _, c := range cookies.Cookies {
+		if c.Name != cfClearanceCookie {
+			continue
+		}
+		result.HasClearance = true
+		if !c.Session && c.Expires > 0 {
+			result.ClearanceExpires = c.Expires.Time()
+		}
+		break
+	}
+	return result, nil
+}
+
+// challengeShowing reports whether the page shows a challenge, judged by the
+// same title and selector checks as the solve loop. A page whose title cannot
+// be read counts as challenged.
+func (s *Solver) challengeShowing(page *rod.Page) bool {
+	title, err := s.getPageTitle(page)
+	if err != nil {
+		return true
+	}
+	titleLower := strings.ToLower(title)
+	for _, t := range challengeTitles {
+		if strings.Contains(titleLower, t) {
+			return true
+		}
+	}
+	return s.findChallengeSelector(page, s.pollSettingsFor(page.GetContext()).SelectorBudget) != ""
+}
@@ -0,0 +1,144 @@
+package solver
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/security"
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// isChallengedResponse reports whether a response carries a Cloudflare
+// challenge. headers must have lower-case keys. Cloudflare marks challenged
+// responses with "cf-mitigated: challenge"; older setups only show up as a
+// Cloudflare-served HTML error page in place of the expected payload.
+func isChallengedResponse(status int, headers map[string]string) bool {
+	if strings.EqualFold(headers["cf-mitigated"], "challenge") {
+		return true
+	}
+	switch status {
+	case 403, 429, 503:
+		return strings.Contains(strings.ToLower(headers["server"]), "cloudflare") &&
+			strings.Contains(strings.ToLower(headers["content-type"]), "text/html")
+	}
+	return false
+}
+
+// isChallengedXHR applies isChallengedResponse to a CDP response. Cloudflare's
+// own challenge-platform calls under /cdn-cgi/ are not the site's traffic and
+// are ignored.
+func isChallengedXHR(resp *proto.NetworkResponse) bool {
+	if strings.Contains(resp.URL, "/cdn-cgi/") {
+		return false
+	}
+	headers := make(map[string]string, 3)
+	for k, v := range resp.Headers {
+		switch key := strings.ToLower(k); key {
+		case "cf-mitigated", "server", "content-type":
+			headers[key] = v.Str()
+		}
+	}
+	return isChallengedResponse(resp.Status, headers)
+}
+
+// waitForXHRChallenge waits up to window for a challenged XHR/fetch response
+// recorded since the given time and returns its URL, or "" if none arrived.
+// Responses from the initial page load are already in the capture, so calls
+// fired before the window starts are not missed.
+func waitForXHRChallenge(ctx context.Context, capture *NetworkCapture, since time.Time, window time.Duration) string {
+	deadline := time.Now().Add(window)
+	for {
+		if u := capture.ChallengedXHRSince(since); u != "" {
+			return u
+		}
+		if !time.Now().Before(deadline) || !sleepWithContext(ctx, redirectPollInterval) {
+			return ""
+		}
+	}
+}
+
+// stripQuery drops the query string and fragment, which may carry tokens,
+// before a URL is logged or returned to the client.
+func stripQuery(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// applyXHRWatch watches post-clearance XHR/fetch traffic when enabled. If a
+// call is challenged it either fails with XHR_CHALLENGED or, by default, earns
+// clearance for the call's URL with a top-level solve, reloads the page and
+// checks again, rebuilding the result from the reloaded page.
+func (s *Solver) applyXHRWatch(ctx context.Context, page *rod.Page, opts *SolveOptions, capture *NetworkCapture, result *Result) error {
+	if opts.XHRWatch <= 0 || capture == nil || opts.Method != "" || opts.ReturnRawHtml || opts.Download {
+		return nil
+	}
+
+	xhrURL := waitForXHRChallenge(ctx, capture, time.Time{}, opts.XHRWatch)
+	if xhrURL == "" {
+		return nil
+	}
+	log.Ctx(ctx).Warn().
+		Str("url", opts.URL).
+		Str("xhr_url", stripQuery(xhrURL)).
+		Msg("In-page request challenged after clearance")
+
+	if opts.XHRChallengeReport {
+		return types.NewXHRChallengedError(opts.URL, stripQuery(xhrURL))
+	}
+
+	reloadedAt := time.Now()
+	refreshed, err := s.resolveXHRChallenge(ctx, page, opts, capture, xhrURL)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("xhr_url", stripQuery(xhrURL)).Msg("Failed to clear challenged in-page request")
+		return types.NewXHRChallengedError(opts.URL, stripQuery(xhrURL))
+	}
+	if again := waitForXHRChallenge(ctx, capture, reloadedAt, opts.XHRWatch); again != "" {
+		return types.NewXHRChallengedError(opts.URL, stripQuery(again))
+	}
+
+	refreshed.UserAgent = result.UserAgent
+	refreshed.ClientRedirects = result.ClientRedirects
+	*result = *refreshed
+	log.Ctx(ctx).Info().Str("url", opts.URL).Msg("Cleared challenged in-page request and reloaded page")
+	return nil
+}
+
+// resolveXHRChallenge navigates to the challenged XHR URL so the challenge is
+// served (and solved) as a top-level document, then returns to the requested
+// page and runs the solve loop there again.
+func (s *Solver) resolveXHRChallenge(ctx context.Context, page *rod.Page, opts *SolveOptions, capture *NetworkCapture, xhrURL string) (*Result, error) {
+	if !opts.SkipResponseValidation {
+		if err := security.ValidateURLWithContext(ctx, xhrURL); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := page.Context(ctx).Navigate(xhrURL); err != nil {
+		return nil, err
+	}
+	if err := page.Context(ctx).WaitLoad(); err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("WaitLoad on challenged XHR URL failed, continuing")
+	}
+	if _, err := s.solveLoop(ctx, page, xhrURL, nil, nil, opts.TabsTillVerify, opts.SkipResponseValidation, nil, 0); err != nil {
+		return nil, err
+	}
+
+	if err := page.Context(ctx).Navigate(opts.URL); err != nil {
+		return nil, err
+	}
+	if err := page.Context(ctx).WaitLoad(); err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("WaitLoad after XHR clearance failed, continuing")
+	}
+	return s.solveLoop(ctx, page, opts.URL, opts.screenshot(), opts.ExpectedIP, opts.TabsTillVerify, opts.SkipResponseValidation, capture, opts.CookieExtractDelay)
+}
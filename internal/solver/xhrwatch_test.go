@@ -0,0 +1,59 @@
+package solver
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestIsChallengedResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		headers map[string]string
+		want    bool
+	}{
+		{"cf-mitigated header", 403, map[string]string{"cf-mitigated": "challenge"}, true},
+		{"cf-mitigated on 200", 200, map[string]string{"cf-mitigated": "Challenge"}, true},
+		{"cloudflare html 403", 403, map[string]string{"server": "cloudflare", "content-type": "text/html; charset=UTF-8"}, true},
+		{"cloudflare html 503", 503, map[string]string{"server": "cloudflare", "content-type": "text/html"}, true},
+		{"cloudflare json 403", 403, map[string]string{"server": "cloudflare", "content-type": "application/json"}, false},
+		{"origin html 403", 403, map[string]string{"server": "nginx", "content-type": "text/html"}, false},
+		{"cloudflare html 200", 200, map[string]string{"server": "cloudflare", "content-type": "text/html"}, false},
+		{"no headers", 403, map[string]string{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isChallengedResponse(tt.status, tt.headers); got != tt.want {
+				t.Errorf("isChallengedResponse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaitForXHRChallenge(t *testing.T) {
+	capture := newNetworkCapture()
+	before := time.Now()
+	capture.recordChallengedXHR("https://example.com/api/data")
+
+	if got := waitForXHRChallenge(context.Background(), capture, time.Time{}, 10*time.Millisecond); got != "https://example.com/api/data" {
+		t.Errorf("expected recorded XHR, got %q", got)
+	}
+	if got := waitForXHRChallenge(context.Background(), capture, time.Now().Add(time.Second), 10*time.Millisecond); got != "" {
+		t.Errorf("expected nothing recorded after the cutoff, got %q", got)
+	}
+	if got := capture.ChallengedXHRSince(before); got == "" {
+		t.Error("expected XHR recorded after start time")
+	}
+
+	var nilCapture *NetworkCapture
+	if got := nilCapture.ChallengedXHRSince(time.Time{}); got != "" {
+		t.Errorf("nil capture returned %q", got)
+	}
+}
+
+func TestStripQuery(t *testing.T) {
+	if got := stripQuery("https://example.com/api?token=secret#frag"); got != "https://example.com/api" {
+		t.Errorf("stripQuery() = %q", got)
+	}
+}
@@ -0,0 +1,204 @@
+package stats
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DomainOverflow labels the metrics of domains outside the top N.
+const DomainOverflow = "other"
+
+// SolveDurationBuckets are the upper bounds, in seconds, of the per-domain
+// solve duration histogram.
+var SolveDurationBuckets = []float64{1, 2.5, 5, 10, 20, 30, 45, 60, 120}
+
+// rankWindow is how often the tracked domains are re-ranked by the solves
+// they received since the last ranking.
+const rankWindow = 10 * time.Minute
+
+// maxCandidates bounds the untracked domains counted towards the next ranking.
+const maxCandidates = 10000
+
+// DomainSeries holds the metric counters of one domain, or of all domains
+// folded into DomainOverflow.
+type DomainSeries struct {
+	Domain      string
+	Solves      int64
+	Successes   int64
+	RateLimits  int64
+	Challenges  map[string]int64 // challenge type -> solves that met it
+	Buckets     []int64          // solves per SolveDurationBuckets bound, not cumulative; the last entry is +Inf
+	DurationSum float64          // seconds
+
+	recent int64 // solves since the last ranking
+}
+
+// DomainMetrics keeps Prometheus series for the busiest target domains.
+// At most topN domains have series of their own; everything else is counted
+// under DomainOverflow. Every rankWindow the domains with the most solves
+// in the window take the slots, so a domain that stops being busy gives its
+// slot up and its series disappears.
+type DomainMetrics struct {
+	mu         sync.Mutex
+	topN       int
+	series     map[string]*DomainSeries
+	other      *DomainSeries
+	candidates map[string]int64 // solves this window of domains under DomainOverflow
+	rankedAt   time.Time
+	now        func() time.Time
+}
+
+// NewDomainMetrics tracks up to topN domains. It returns nil when topN is
+// not positive; a nil *DomainMetrics ignores all calls.
+func NewDomainMetrics(topN int) *DomainMetrics {
+	if topN <= 0 {
+		return nil
+	}
+	return &DomainMetrics{
+		topN:       topN,
+		series:     make(map[string]*DomainSeries),
+		other:      newDomainSeries(DomainOverflow),
+		candidates: make(map[string]int64),
+		rankedAt:   time.Now(),
+		now:        time.Now,
+	}
+}
+
+func newDomainSeries(domain string) *DomainSeries {
+	return &DomainSeries{
+		Domain:     domain,
+		Challenges: make(map[string]int64),
+		Buckets:    make([]int64, len(SolveDurationBuckets)+1),
+	}
+}
+
+// RecordSolve counts a finished solve for domain and its duration.
+func (d *DomainMetrics) RecordSolve(domain string, success bool, duration time.Duration) {
+	if d == nil || domain == "" {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := d.seriesFor(domain, true)
+	s.Solves++
+	if success {
+		s.Successes++
+	}
+	secs := duration.Seconds()
+	i := sort.SearchFloat64s(SolveDurationBuckets, secs)
+	s.Buckets[i]++
+	s.DurationSum += secs
+}
+
+// RecordRateLimit counts a rate-limited or access-denied response from domain.
+func (d *DomainMetrics) RecordRateLimit(domain string) {
+	if d == nil || domain == "" {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.seriesFor(domain, false).RateLimits++
+}
+
+// RecordChallenge counts a solve on domain that met the given challenge type.
+func (d *DomainMetrics) RecordChallenge(domain, challenge string) {
+	if d == nil || domain == "" {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.seriesFor(domain, false).Challenges[challenge]++
+}
+
+// seriesFor returns the series counting domain, re-ranking first when the
+// window has passed. solve marks the event as a solve for the ranking.
+func (d *DomainMetrics) seriesFor(domain string, solve bool) *DomainSeries {
+	if now := d.now(); now.Sub(d.rankedAt) >= rankWindow {
+		d.rerank(now)
+	}
+	s := d.series[domain]
+	if s == nil && len(d.series) < d.topN {
+		s = newDomainSeries(domain)
+		d.series[domain] = s
+	}
+	if s == nil {
+		if solve && (d.candidates[domain] > 0 || len(d.candidates) < maxCandidates) {
+			d.candidates[domain]++
+		}
+		return d.other
+	}
+	if solve {
+		s.recent++
+	}
+	return s
+}
+
+// rerank gives the slots to the domains with the most solves in the window
+// that just ended. Tracked domains win ties, so idle slots are not churned.
+func (d *DomainMetrics) rerank(now time.Time) {
+	type contender struct {
+		domain  string
+		solves  int64
+		tracked bool
+	}
+	contenders := make([]contender, 0, len(d.series)+len(d.candidates))
+	for domain, s := range d.series {
+		contenders = append(contenders, contender{domain, s.recent, true})
+	}
+	for domain, n := range d.candidates {
+		contenders = append(contenders, contender{domain, n, false})
+	}
+	sort.Slice(contenders, func(i, j int) bool {
+		a, b := contenders[i], contenders[j]
+		if a.solves != b.solves {
+			return a.solves > b.solves
+		}
+		if a.tracked != b.tracked {
+			return a.tracked
+		}
+		return a.domain < b.domain
+	})
+
+	series := make(map[string]*DomainSeries, d.topN)
+	for _, c := range contenders[:min(d.topN, len(contenders))] {
+		s := d.series[c.domain]
+		if s == nil {
+			s = newDomainSeries(c.domain)
+		}
+		s.recent = 0
+		series[c.domain] = s
+	}
+	d.series = series
+	d.candidates = make(map[string]int64)
+	d.rankedAt = now
+}
+
+// Snapshot returns a copy of all series sorted by domain, with the
+// DomainOverflow series last.
+func (d *DomainMetrics) Snapshot() []DomainSeries {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	out := make([]DomainSeries, 0, len(d.series)+1)
+	for _, s := range d.series {
+		out = append(out, s.clone())
+	}
+	other := d.other.clone()
+	d.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Domain < out[j].Domain })
+	return append(out, other)
+}
+
+func (s *DomainSeries) clone() DomainSeries {
+	c := *s
+	c.Challenges = make(map[string]int64, len(s.Challenges))
+	for k, v := range s.Challenges {
+		c.Challenges[k] = v
+	}
+	c.Buckets = append([]int64(nil), s.Buckets...)
+	return c
+}
@@ -0,0 +1,96 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func seriesByDomain(d *DomainMetrics) map[string]DomainSeries {
+	out := make(map[string]DomainSeries)
+	for _, s := range d.Snapshot() {
+		out[s.Domain] = s
+	}
+	return out
+}
+
+func TestDomainMetricsOverflow(t *testing.T) {
+	d := NewDomainMetrics(2)
+	d.RecordSolve("a.example", true, 3*time.Second)
+	d.RecordSolve("b.example", false, 200*time.Second)
+	d.RecordSolve("c.example", true, time.Second)
+	d.RecordChallenge("c.example", "turnstile")
+	d.RecordRateLimit("a.example")
+
+	got := seriesByDomain(d)
+	if len(got) != 3 {
+		t.Fatalf("series = %v, want a.example, b.example and %s", got, DomainOverflow)
+	}
+	a, b, other := got["a.example"], got["b.example"], got[DomainOverflow]
+	if a.Solves != 1 || a.Successes != 1 || a.RateLimits != 1 || a.Buckets[2] != 1 || a.DurationSum != 3 {
+		t.Errorf("a.example = %+v", a)
+	}
+	if b.Successes != 0 || b.Buckets[len(SolveDurationBuckets)] != 1 {
+		t.Errorf("b.example = %+v, want one failure in the +Inf bucket", b)
+	}
+	if other.Solves != 1 || other.Challenges["turnstile"] != 1 || other.Buckets[0] != 1 {
+		t.Errorf("%s = %+v", DomainOverflow, other)
+	}
+	if snap := d.Snapshot(); snap[len(snap)-1].Domain != DomainOverflow {
+		t.Errorf("overflow series is not last: %v", snap)
+	}
+}
+
+func TestDomainMetricsRerank(t *testing.T) {
+	d := NewDomainMetrics(1)
+	now := time.Now()
+	d.now = func() time.Time { return now }
+
+	d.RecordSolve("early.example", true, time.Second)
+	for i := 0; i < 3; i++ {
+		d.RecordSolve("busy.example", true, time.Second)
+	}
+	if _, ok := seriesByDomain(d)["busy.example"]; ok {
+		t.Fatal("busy.example has a series before the ranking window ended")
+	}
+
+	// The busier domain takes the slot at the next ranking
+	now = now.Add(rankWindow)
+	d.RecordSolve("busy.example", true, time.Second)
+	got := seriesByDomain(d)
+	if _, ok := got["early.example"]; ok {
+		t.Error("early.example kept its slot after an idle window")
+	}
+	if got["busy.example"].Solves != 1 || got[DomainOverflow].Solves != 3 {
+		t.Errorf("series after ranking = %+v", got)
+	}
+
+	// An idle window keeps the slot when nothing else competes
+	now = now.Add(rankWindow)
+	d.RecordRateLimit("busy.example")
+	if got := seriesByDomain(d)["busy.example"]; got.RateLimits != 1 {
+		t.Errorf("busy.example = %+v, want it tracked after a quiet window", got)
+	}
+}
+
+func TestDomainMetricsDisabled(t *testing.T) {
+	d := NewDomainMetrics(0)
+	if d != nil {
+		t.Fatal("NewDomainMetrics(0) should return nil")
+	}
+	d.RecordSolve("a.example", true, time.Second)
+	d.RecordChallenge("a.example", "turnstile")
+	if d.Snapshot() != nil {
+		t.Error("nil DomainMetrics should have no series")
+	}
+
+	m := NewManager()
+	defer m.Close()
+	m.RecordChallenge("a.example", "turnstile") // must not panic while disabled
+	m.EnableDomainMetrics(5)
+	m.RecordRequest("a.example", 100, false, true)
+	m.RecordChallenge("a.example", "turnstile")
+	got := seriesByDomain(m.DomainMetrics())["a.example"]
+	if got.RateLimits != 1 || got.Challenges["turnstile"] != 1 {
+		t.Errorf("a.example = %+v, want the rate limit and challenge from the Manager", got)
+	}
+}
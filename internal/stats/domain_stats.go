@@ -4,6 +4,8 @@ package stats
 import (
 	"math"
 	"net/url"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,7 +19,7 @@ const maxDomains = 10000
 const evictionBatchSize = 100
 
 // TurnstileMethodStats tracks which Turnstile solving method works best for a domain.
-// Methods: "wait", "shadow", "keyboard", "widget", "iframe", "positional"
+// Methods: "wait", "frames", "shadow", "keyboard", "widget", "iframe", "positional"
 type TurnstileMethodStats struct {
 	MethodAttempts  map[string]int64 `json:"methodAttempts,omitempty"`  // Attempts per method
 	MethodSuccesses map[string]int64 `json:"methodSuccesses,omitempty"` // Successes per method
@@ -87,6 +89,7 @@ type SolverPreferences struct {
 	PreferredProvider string   `json:"preferredProvider,omitempty"` // Preferred external provider
 	TimeoutOverrideMs *int     `json:"timeoutOverrideMs,omitempty"` // Domain-specific timeout
 	DisableMethods    []string `json:"disableMethods,omitempty"`    // Methods to skip for this domain
+	PollStrategy      string   `json:"pollStrategy,omitempty"`      // Challenge poll strategy: "random", "fixed" or "event"
 }
 
 // DomainStats tracks request statistics for a single domain.
@@ -118,6 +121,9 @@ type DomainStats struct {
 	// Domain-specific solver preferences
 	SolverPrefs *SolverPreferences `json:"solverPrefs,omitempty"`
 
+	// Proxies the domain refused, by redacted proxy URL
+	bannedProxies map[string]*proxyBan
+
 	// Cached calculation
 	// Audit Issue 8: Use -1 as invalid marker since 0 is a valid delay value
 	cachedDelay int // -1 means cache is invalid
@@ -153,6 +159,7 @@ type DomainStatsJSON struct {
 	CrawlDelay       *int                  `json:"crawlDelay,omitempty"`
 	SolveStats       *SolveMethodStatsJSON `json:"solveStats,omitempty"`
 	SolverPrefs      *SolverPreferences    `json:"solverPrefs,omitempty"`
+	BannedProxies    []ProxyBanJSON        `json:"bannedProxies,omitempty"`
 }
 
 // ToJSON converts DomainStats to its JSON-serializable form.
@@ -177,6 +184,7 @@ func (s *DomainStats) ToJSON(minDelay, maxDelay int) DomainStatsJSON {
 		SuggestedDelayMs: s.suggestedDelayMs(minDelay, maxDelay),
 		CrawlDelay:       s.CrawlDelay,
 		SolverPrefs:      s.SolverPrefs,
+		BannedProxies:    s.bannedProxiesJSON(),
 	}
 
 	// Include solve stats if there are any attempts
@@ -310,6 +318,14 @@ type Manager struct {
 	DefaultMinDelayMs int
 	DefaultMaxDelayMs int
 
+	// Bounded per-domain Prometheus series, nil when disabled
+	metrics *DomainMetrics
+
+	// Turnstile method outcomes across all domains; unlike the per-domain
+	// stats they survive eviction, so they can be exported as counters
+	methodMu     sync.Mutex
+	methodTotals map[string]*TurnstileMethodTotal
+
 	// Fix #14: Background cleanup
 	stopCh chan struct{}
 	wg     sync.WaitGroup
@@ -460,7 +476,12 @@ func (m *Manager) evictOldestBatchLocked(count int) {
 		// The slight race is acceptable - we're just doing approximate LRU
 		stats.mu.RLock()
 		lastAccess := stats.LastAccess
+		configured := stats.SolverPrefs != nil
 		stats.mu.RUnlock()
+		// Preferences are operator configuration, not history; keep them
+		if configured {
+			continue
+		}
 		candidates = append(candidates, domainTime{domain, lastAccess})
 	}
 
@@ -538,12 +559,32 @@ func (m *Manager) RecordRequest(domain string, latencyMs int64, success bool, ra
 	if rateLimited {
 		stats.RateLimitCount++
 		stats.LastRateLimited = time.Now()
+		m.metrics.RecordRateLimit(domain)
 	}
 
 	// Invalidate cache (use -1 as invalid marker since 0 is a valid delay)
 	stats.cachedDelay = -1
 }
 
+// EnableDomainMetrics keeps Prometheus series for the topN busiest domains;
+// a topN of zero disables them. Call before the Manager is in use.
+func (m *Manager) EnableDomainMetrics(topN int) {
+	m.metrics = NewDomainMetrics(topN)
+}
+
+// DomainMetrics returns the per-domain Prometheus series, nil when disabled.
+func (m *Manager) DomainMetrics() *DomainMetrics {
+	if m == nil {
+		return nil
+	}
+	return m.metrics
+}
+
+// RecordChallenge counts a solve on domain that met the given challenge type.
+func (m *Manager) RecordChallenge(domain, challenge string) {
+	m.metrics.RecordChallenge(domain, challenge)
+}
+
 // SuggestedDelay returns the suggested delay for a domain.
 func (m *Manager) SuggestedDelay(domain string) int {
 	stats := m.Get(domain)
@@ -802,6 +843,78 @@ func (m *Manager) GetDomainSolverPrefs(domain string) *SolverPreferences {
 	return stats.SolverPrefs
 }
 
+// SolverPrefsFor returns the solver preferences stored for host or, failing
+// that, its nearest parent domain. Returns nil if none are set.
+func (m *Manager) SolverPrefsFor(host string) *SolverPreferences {
+	for d := host; d != ""; {
+		if prefs := m.GetDomainSolverPrefs(d); prefs != nil {
+			return prefs
+		}
+		_, parent, found := strings.Cut(d, ".")
+		if !found || !strings.Contains(parent, ".") {
+			break
+		}
+		d = parent
+	}
+	return nil
+}
+
+// DisabledTurnstileMethods returns the Turnstile methods the solver
+// preferences for host disable.
+func (m *Manager) DisabledTurnstileMethods(host string) []string {
+	if prefs := m.SolverPrefsFor(host); prefs != nil {
+		return prefs.DisableMethods
+	}
+	return nil
+}
+
+// PreferredProviderFor returns the external provider the solver preferences
+// for host put first, or "" if none is set.
+func (m *Manager) PreferredProviderFor(host string) string {
+	if prefs := m.SolverPrefsFor(host); prefs != nil {
+		return prefs.PreferredProvider
+	}
+	return ""
+}
+
+// SetDomainPollStrategy sets the challenge poll strategy for a domain, keeping
+// any other solver preferences.
+func (m *Manager) SetDomainPollStrategy(domain, strategy string) {
+	if domain == "" {
+		return
+	}
+
+	stats := m.getOrCreate(domain)
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	if stats.SolverPrefs == nil {
+		stats.SolverPrefs = &SolverPreferences{NativeFirst: true}
+	} else {
+		prefs := *stats.SolverPrefs
+		stats.SolverPrefs = &prefs
+	}
+	stats.SolverPrefs.PollStrategy = strategy
+}
+
+// PollStrategyPreference returns the poll strategy configured for a domain,
+// or "" if none is set.
+func (m *Manager) PollStrategyPreference(domain string) string {
+	stats := m.Get(domain)
+	if stats == nil {
+		return ""
+	}
+
+	stats.mu.RLock()
+	defer stats.mu.RUnlock()
+
+	if stats.SolverPrefs == nil {
+		return ""
+	}
+	return stats.SolverPrefs.PollStrategy
+}
+
 // NativeSuccessRate returns the native solve success rate for a domain (0.0 to 1.0).
 // Returns -1 if no native attempts have been made.
 func (m *Manager) NativeSuccessRate(domain string) float64 {
@@ -820,9 +933,26 @@ func (m *Manager) NativeSuccessRate(domain string) float64 {
 	return float64(stats.SolveStats.NativeSuccesses) / float64(stats.SolveStats.NativeAttempts)
 }
 
+// NativeSolveHistory returns the native solve attempts and successes
+// recorded for a domain.
+func (m *Manager) NativeSolveHistory(domain string) (attempts, successes int64) {
+	stats := m.Get(domain)
+	if stats == nil {
+		return 0, 0
+	}
+
+	stats.mu.RLock()
+	defer stats.mu.RUnlock()
+
+	return stats.SolveStats.NativeAttempts, stats.SolveStats.NativeSuccesses
+}
+
 // RecordTurnstileMethod records a Turnstile method attempt and its outcome.
-// method should be one of: "wait", "shadow", "keyboard", "widget", "iframe", "positional"
+// method should be one of: "wait", "frames", "shadow", "keyboard", "widget", "iframe", "positional"
 func (m *Manager) RecordTurnstileMethod(domain, method string, success bool) {
+	if method != "" {
+		m.recordMethodTotal(method, success)
+	}
 	if domain == "" || method == "" {
 		return
 	}
@@ -861,6 +991,47 @@ func (m *Manager) RecordTurnstileMethod(domain, method string, success bool) {
 	}
 }
 
+// TurnstileMethodTotal counts the attempts of one Turnstile method across
+// all domains.
+type TurnstileMethodTotal struct {
+	Method    string
+	Attempts  int64
+	Successes int64
+}
+
+func (m *Manager) recordMethodTotal(method string, success bool) {
+	m.methodMu.Lock()
+	defer m.methodMu.Unlock()
+	if m.methodTotals == nil {
+		m.methodTotals = make(map[string]*TurnstileMethodTotal)
+	}
+	t := m.methodTotals[method]
+	if t == nil {
+		t = &TurnstileMethodTotal{Method: method}
+		m.methodTotals[method] = t
+	}
+	t.Attempts++
+	if success {
+		t.Successes++
+	}
+}
+
+// TurnstileMethodTotals returns the Turnstile method outcomes across all
+// domains, sorted by method.
+func (m *Manager) TurnstileMethodTotals() []TurnstileMethodTotal {
+	if m == nil {
+		return nil
+	}
+	m.methodMu.Lock()
+	out := make([]TurnstileMethodTotal, 0, len(m.methodTotals))
+	for _, t := range m.methodTotals {
+		out = append(out, *t)
+	}
+	m.methodMu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Method < out[j].Method })
+	return out
+}
+
 // GetBestTurnstileMethod returns the best Turnstile method for a domain based on history.
 // Returns empty string if no history exists.
 func (m *Manager) GetBestTurnstileMethod(domain string) string {
@@ -884,7 +1055,7 @@ func (m *Manager) GetBestTurnstileMethod(domain string) string {
 // failing methods (negative learning). Methods that always fail are deprioritized.
 func (m *Manager) GetTurnstileMethodOrder(domain string) []string {
 	// Default order - "wait" first because invisible Turnstile auto-solves without interaction
-	defaultOrder := []string{"wait", "shadow", "keyboard", "widget", "iframe", "positional"}
+	defaultOrder := []string{"wait", "frames", "shadow", "keyboard", "widget", "iframe", "positional"}
 
 	stats := m.Get(domain)
 	if stats == nil {
@@ -1,6 +1,7 @@
 package stats
 
 import (
+	"strconv"
 	"testing"
 	"time"
 )
@@ -322,6 +323,23 @@ func TestManager_RecordSolveOutcome_Native(t *testing.T) {
 	}
 }
 
+func TestManager_NativeSolveHistory(t *testing.T) {
+	m := NewManager()
+	defer m.Close()
+
+	if attempts, successes := m.NativeSolveHistory("unseen.com"); attempts != 0 || successes != 0 {
+		t.Errorf("NativeSolveHistory(unseen) = %d, %d, want 0, 0", attempts, successes)
+	}
+
+	m.RecordSolveOutcome("history.com", "native", false, 800)
+	m.RecordSolveOutcome("history.com", "native", true, 900)
+	m.RecordSolveOutcome("history.com", "2captcha", true, 9000)
+
+	if attempts, successes := m.NativeSolveHistory("history.com"); attempts != 2 || successes != 1 {
+		t.Errorf("NativeSolveHistory = %d, %d, want 2, 1", attempts, successes)
+	}
+}
+
 func TestManager_RecordSolveOutcome_External(t *testing.T) {
 	m := NewManager()
 	defer m.Close()
@@ -465,6 +483,63 @@ func TestManager_SetDomainSolverPrefs(t *testing.T) {
 	}
 }
 
+func TestManager_SetDomainPollStrategy(t *testing.T) {
+	m := NewManager()
+	defer m.Close()
+
+	if got := m.PollStrategyPreference("poll.com"); got != "" {
+		t.Errorf("PollStrategyPreference() = %q before any preference", got)
+	}
+
+	m.SetDomainSolverPrefs("poll.com", &SolverPreferences{PreferredProvider: "capsolver"})
+	m.SetDomainPollStrategy("poll.com", "event")
+
+	if got := m.PollStrategyPreference("poll.com"); got != "event" {
+		t.Errorf("PollStrategyPreference() = %q, want event", got)
+	}
+	// Other preferences are kept
+	if got := m.GetDomainSolverPrefs("poll.com"); got == nil || got.PreferredProvider != "capsolver" {
+		t.Errorf("PreferredProvider lost: %+v", got)
+	}
+
+	m.SetDomainPollStrategy("fresh.com", "fixed")
+	if got := m.GetDomainSolverPrefs("fresh.com"); got == nil || !got.NativeFirst || got.PollStrategy != "fixed" {
+		t.Errorf("new domain prefs = %+v, want NativeFirst with fixed polling", got)
+	}
+}
+
+func TestManager_SolverPrefsFor(t *testing.T) {
+	m := NewManager()
+	defer m.Close()
+
+	m.SetDomainSolverPrefs("example.com", &SolverPreferences{
+		NativeFirst:       true,
+		PreferredProvider: "capsolver",
+		DisableMethods:    []string{"positional"},
+	})
+	m.SetDomainSolverPrefs("shop.example.com", &SolverPreferences{NativeFirst: true, PreferredProvider: "2captcha"})
+
+	tests := map[string]string{
+		"example.com":          "capsolver",
+		"www.example.com":      "capsolver", // inherits from the parent domain
+		"shop.example.com":     "2captcha",  // its own preferences win
+		"cdn.shop.example.com": "2captcha",
+		"other.com":            "",
+		"":                     "",
+	}
+	for host, want := range tests {
+		if got := m.PreferredProviderFor(host); got != want {
+			t.Errorf("PreferredProviderFor(%q) = %q, want %q", host, got, want)
+		}
+	}
+	if got := m.DisabledTurnstileMethods("www.example.com"); len(got) != 1 || got[0] != "positional" {
+		t.Errorf("DisabledTurnstileMethods() = %v, want [positional]", got)
+	}
+	if got := m.DisabledTurnstileMethods("shop.example.com"); len(got) != 0 {
+		t.Errorf("DisabledTurnstileMethods(shop) = %v, want none", got)
+	}
+}
+
 func TestManager_GetDomainSolverPrefs_NotSet(t *testing.T) {
 	m := NewManager()
 	defer m.Close()
@@ -650,6 +725,36 @@ func TestManager_RecordTurnstileMethod(t *testing.T) {
 	}
 }
 
+func TestManager_TurnstileMethodTotals(t *testing.T) {
+	m := NewManager()
+	defer m.Close()
+
+	m.RecordTurnstileMethod("a.example.com", "wait", true)
+	m.RecordTurnstileMethod("b.example.com", "wait", false)
+	m.RecordTurnstileMethod("b.example.com", "shadow", false)
+	m.RecordTurnstileMethod("", "shadow", true) // counted without a domain
+
+	want := []TurnstileMethodTotal{
+		{Method: "shadow", Attempts: 2, Successes: 1},
+		{Method: "wait", Attempts: 2, Successes: 1},
+	}
+	got := m.TurnstileMethodTotals()
+	if len(got) != len(want) {
+		t.Fatalf("TurnstileMethodTotals() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("TurnstileMethodTotals()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// The totals outlive the per-domain stats
+	m.ResetAll()
+	if got := m.TurnstileMethodTotals(); len(got) != 2 {
+		t.Errorf("totals after ResetAll = %+v", got)
+	}
+}
+
 func TestManager_GetTurnstileMethodOrder(t *testing.T) {
 	m := NewManager()
 	defer m.Close()
@@ -658,7 +763,7 @@ func TestManager_GetTurnstileMethodOrder(t *testing.T) {
 
 	// Default order when no history - "wait" should be first for invisible Turnstile
 	order := m.GetTurnstileMethodOrder(domain)
-	expected := []string{"wait", "shadow", "keyboard", "widget", "iframe", "positional"}
+	expected := []string{"wait", "frames", "shadow", "keyboard", "widget", "iframe", "positional"}
 	if len(order) != len(expected) {
 		t.Errorf("GetTurnstileMethodOrder() length = %d, want %d", len(order), len(expected))
 	}
@@ -686,22 +791,26 @@ func TestManager_GetTurnstileMethodOrder(t *testing.T) {
 		t.Errorf("Second method should be 'widget', got %q", order[1])
 	}
 
-	// Untried methods (wait, iframe, positional) should be before failing methods
+	// Untried methods (wait, frames, iframe, positional) should be before failing methods
 	// They have score = 0.5 (neutral), which ties with widget but comes after in sort stability
 	// Shadow has 0% success with 2 failures = -0.2 score, so it should be last
-	if order[5] != "shadow" {
-		t.Errorf("Last method should be 'shadow' (0%% success, deprioritized), got %q", order[5])
+	if order[6] != "shadow" {
+		t.Errorf("Last method should be 'shadow' (0%% success, deprioritized), got %q", order[6])
 	}
 
 	// Verify untried methods are in the middle (before shadow)
-	untriedMethods := []string{order[2], order[3], order[4]}
+	untriedMethods := []string{order[2], order[3], order[4], order[5]}
 	hasWait := false
+	hasFrames := false
 	hasIframe := false
 	hasPositional := false
 	for _, m := range untriedMethods {
 		if m == "wait" {
 			hasWait = true
 		}
+		if m == "frames" {
+			hasFrames = true
+		}
 		if m == "iframe" {
 			hasIframe = true
 		}
@@ -709,8 +818,8 @@ func TestManager_GetTurnstileMethodOrder(t *testing.T) {
 			hasPositional = true
 		}
 	}
-	if !hasWait || !hasIframe || !hasPositional {
-		t.Errorf("Untried methods (wait, iframe, positional) should be in positions 3-5, got %v", untriedMethods)
+	if !hasWait || !hasFrames || !hasIframe || !hasPositional {
+		t.Errorf("Untried methods (wait, frames, iframe, positional) should be in positions 3-6, got %v", untriedMethods)
 	}
 }
 
@@ -769,3 +878,39 @@ func TestTurnstileMethodStats_GetBestMethod(t *testing.T) {
 		t.Errorf("Old success should use rate, expect 'shadow', got %q", oldStats.GetBestMethod())
 	}
 }
+
+func TestManager_ProxyBans(t *testing.T) {
+	m := NewManager()
+	defer m.Close()
+
+	m.RecordProxyBan("example.com", "http://10.0.0.1:8080")
+	m.RecordProxyBan("example.com", "http://10.0.0.1:8080")
+	m.RecordProxyBan("example.com", "http://10.0.0.2:8080")
+
+	if !m.ProxyBannedWithin("example.com", "http://10.0.0.1:8080", time.Hour) {
+		t.Error("ProxyBannedWithin() = false for a proxy just banned")
+	}
+	if m.ProxyBannedWithin("example.com", "http://10.0.0.3:8080", time.Hour) || m.ProxyBannedWithin("other.com", "http://10.0.0.1:8080", time.Hour) {
+		t.Error("ProxyBannedWithin() = true for a proxy the domain never refused")
+	}
+	if m.ProxyBannedWithin("example.com", "http://10.0.0.1:8080", 0) {
+		t.Error("ProxyBannedWithin() ignores the window")
+	}
+
+	bans := m.AllStats()["example.com"].BannedProxies
+	if len(bans) != 2 || bans[0].Proxy != "http://10.0.0.2:8080" || bans[1].Count != 2 {
+		t.Errorf("BannedProxies = %+v, want 2 entries, most recent first", bans)
+	}
+}
+
+func TestManager_ProxyBansCapped(t *testing.T) {
+	m := NewManager()
+	defer m.Close()
+
+	for i := range maxBannedProxies + 10 {
+		m.RecordProxyBan("example.com", "http://proxy"+strconv.Itoa(i)+":8080")
+	}
+	if n := len(m.AllStats()["example.com"].BannedProxies); n != maxBannedProxies {
+		t.Errorf("kept %d bans, want %d", n, maxBannedProxies)
+	}
+}
@@ -0,0 +1,95 @@
+package stats
+
+import (
+	"sort"
+	"time"
+)
+
+// maxBannedProxies caps the refused proxies remembered per domain.
+const maxBannedProxies = 100
+
+// proxyBan counts how often a domain refused a proxy.
+type proxyBan struct {
+	count      int64
+	lastBanned time.Time
+}
+
+// ProxyBanJSON is a proxy a domain refused (access denied or a 1020 block).
+type ProxyBanJSON struct {
+	Proxy      string    `json:"proxy"` // Password redacted
+	Count      int64     `json:"count"`
+	LastBanned time.Time `json:"lastBanned"`
+}
+
+// RecordProxyBan records that domain refused the proxy. proxy must not
+// carry a password. The domain keeps its most recent maxBannedProxies bans.
+func (m *Manager) RecordProxyBan(domain, proxy string) {
+	if domain == "" || proxy == "" {
+		return
+	}
+
+	stats := m.getOrCreate(domain)
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	if stats.bannedProxies == nil {
+		stats.bannedProxies = make(map[string]*proxyBan)
+	}
+	ban := stats.bannedProxies[proxy]
+	if ban == nil {
+		if len(stats.bannedProxies) >= maxBannedProxies {
+			stats.evictOldestBanLocked()
+		}
+		ban = &proxyBan{}
+		stats.bannedProxies[proxy] = ban
+	}
+	if ban.count < maxCounterValue {
+		ban.count++
+	}
+	ban.lastBanned = time.Now()
+}
+
+// ProxyBannedWithin reports whether domain refused the proxy within the
+// last d.
+func (m *Manager) ProxyBannedWithin(domain, proxy string, d time.Duration) bool {
+	stats := m.Get(domain)
+	if stats == nil {
+		return false
+	}
+
+	stats.mu.RLock()
+	defer stats.mu.RUnlock()
+
+	ban := stats.bannedProxies[proxy]
+	return ban != nil && time.Since(ban.lastBanned) < d
+}
+
+// evictOldestBanLocked drops the least recently banned proxy (must hold
+// write lock).
+func (s *DomainStats) evictOldestBanLocked() {
+	var oldest string
+	var oldestTime time.Time
+	for proxy, ban := range s.bannedProxies {
+		if oldest == "" || ban.lastBanned.Before(oldestTime) {
+			oldest, oldestTime = proxy, ban.lastBanned
+		}
+	}
+	delete(s.bannedProxies, oldest)
+}
+
+// bannedProxiesJSON returns the bans, most recent first (must hold read
+// lock).
+func (s *DomainStats) bannedProxiesJSON() []ProxyBanJSON {
+	if len(s.bannedProxies) == 0 {
+		return nil
+	}
+	out := make([]ProxyBanJSON, 0, len(s.bannedProxies))
+	for proxy, ban := range s.bannedProxies {
+		out = append(out, ProxyBanJSON{Proxy: proxy, Count: ban.count, LastBanned: ban.lastBanned})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].LastBanned.After(out[j].LastBanned)
+	})
+	return out
+}
@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"sort"
+	"sync"
+)
+
+// TagOverflowValue replaces tag values seen after a key has reached its
+// distinct-value limit, keeping metric cardinality bounded.
+const TagOverflowValue = "_other"
+
+// defaultMaxTagValues is the per-key distinct value limit when none is given.
+const defaultMaxTagValues = 50
+
+// TagSeries holds request counters for one tag key/value pair.
+type TagSeries struct {
+	Key            string `json:"key"`
+	Value          string `json:"value"`
+	RequestCount   int64  `json:"requestCount"`
+	ErrorCount     int64  `json:"errorCount"`
+	TotalLatencyMs int64  `json:"totalLatencyMs"`
+}
+
+// TagStats attributes request load and failures to caller-supplied tags.
+// Only allowlisted keys are tracked, and each key keeps at most maxValues
+// distinct values; later values are folded into TagOverflowValue.
+type TagStats struct {
+	mu        sync.Mutex
+	keys      map[string]bool
+	maxValues int
+	series    map[string]map[string]*TagSeries // key -> value -> counters
+}
+
+// NewTagStats creates a tracker for the given tag keys. It returns nil when
+// keys is empty; a nil *TagStats ignores all calls.
+func NewTagStats(keys []string, maxValues int) *TagStats {
+	if len(keys) == 0 {
+		return nil
+	}
+	if maxValues <= 0 {
+		maxValues = defaultMaxTagValues
+	}
+	t := &TagStats{
+		keys:      make(map[string]bool, len(keys)),
+		maxValues: maxValues,
+		series:    make(map[string]map[string]*TagSeries, len(keys)),
+	}
+	for _, k := range keys {
+		t.keys[k] = true
+	}
+	return t
+}
+
+// Record counts one finished request against each of its tracked tags.
+func (t *TagStats) Record(tags map[string]string, success bool, latencyMs int64) {
+	if t == nil || len(tags) == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, value := range tags {
+		if !t.keys[key] {
+			continue
+		}
+		values := t.series[key]
+		if values == nil {
+			values = make(map[string]*TagSeries)
+			t.series[key] = values
+		}
+		s := values[value]
+		if s == nil && distinctValues(values) >= t.maxValues {
+			value = TagOverflowValue
+			s = values[value]
+		}
+		if s == nil {
+			s = &TagSeries{Key: key, Value: value}
+			values[value] = s
+		}
+		s.RequestCount++
+		if !success {
+			s.ErrorCount++
+		}
+		s.TotalLatencyMs += latencyMs
+	}
+}
+
+// distinctValues counts the real values tracked for a key; the overflow bucket
+// does not count towards the limit.
+func distinctValues(values map[string]*TagSeries) int {
+	if values[TagOverflowValue] != nil {
+		return len(values) - 1
+	}
+	return len(values)
+}
+
+// Snapshot returns a copy of all series, sorted by key then value.
+func (t *TagStats) Snapshot() []TagSeries {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	out := make([]TagSeries, 0, len(t.series))
+	for _, values := range t.series {
+		for _, s := range values {
+			out = append(out, *s)
+		}
+	}
+	t.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Key != out[j].Key {
+			return out[i].Key < out[j].Key
+		}
+		return out[i].Value < out[j].Value
+	})
+	return out
+}
@@ -0,0 +1,57 @@
+package stats
+
+import "testing"
+
+func TestTagStatsRecord(t *testing.T) {
+	ts := NewTagStats([]string{"app", "team"}, 10)
+
+	ts.Record(map[string]string{"app": "prowlarr", "team": "media", "user": "alice"}, true, 100)
+	ts.Record(map[string]string{"app": "prowlarr"}, false, 300)
+	ts.Record(map[string]string{"app": "sonarr"}, true, 50)
+
+	got := ts.Snapshot()
+	want := []TagSeries{
+		{Key: "app", Value: "prowlarr", RequestCount: 2, ErrorCount: 1, TotalLatencyMs: 400},
+		{Key: "app", Value: "sonarr", RequestCount: 1, TotalLatencyMs: 50},
+		{Key: "team", Value: "media", RequestCount: 1, TotalLatencyMs: 100},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Snapshot() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("series[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTagStatsBoundedCardinality(t *testing.T) {
+	ts := NewTagStats([]string{"app"}, 2)
+
+	for _, app := range []string{"a", "b", "c", "d", "a"} {
+		ts.Record(map[string]string{"app": app}, true, 1)
+	}
+
+	counts := make(map[string]int64)
+	for _, s := range ts.Snapshot() {
+		counts[s.Value] = s.RequestCount
+	}
+	if len(counts) != 3 {
+		t.Fatalf("expected 2 values plus overflow, got %v", counts)
+	}
+	if counts["a"] != 2 || counts["b"] != 1 || counts[TagOverflowValue] != 2 {
+		t.Errorf("unexpected counts %v", counts)
+	}
+}
+
+func TestTagStatsDisabled(t *testing.T) {
+	ts := NewTagStats(nil, 0)
+	if ts != nil {
+		t.Fatal("expected nil tracker without keys")
+	}
+	// A nil tracker must be safe to use
+	ts.Record(map[string]string{"app": "x"}, true, 1)
+	if got := ts.Snapshot(); got != nil {
+		t.Errorf("Snapshot() on nil = %v", got)
+	}
+}
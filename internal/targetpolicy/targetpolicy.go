@@ -0,0 +1,134 @@
+// Package targetpolicy restricts which target domains the solver may be
+// pointed at, so an instance reachable by others cannot be used as an open
+// relay to arbitrary sites.
+package targetpolicy
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+// maxListFileSize bounds an allowlist or denylist file read from disk.
+const maxListFileSize = 1 << 20
+
+// Policy holds the allowed and blocked domain patterns. A pattern is
+// "example.com" for the domain and its subdomains, "*.example.com" for
+// subdomains only, or "*" for every domain. A nil Policy allows everything.
+type Policy struct {
+	allow []string
+	deny  []string
+}
+
+// Load builds the policy from the TARGET_ALLOWLIST and TARGET_DENYLIST
+// values and the files named by TARGET_ALLOWLIST_FILE and
+// TARGET_DENYLIST_FILE; entries from a variable and its file are combined.
+// Returns nil when nothing is configured.
+func Load(allow, deny, allowFile, denyFile string) (*Policy, error) {
+	var p Policy
+	var err error
+	if p.allow, err = patterns("TARGET_ALLOWLIST", allow, allowFile); err != nil {
+		return nil, err
+	}
+	if p.deny, err = patterns("TARGET_DENYLIST", deny, denyFile); err != nil {
+		return nil, err
+	}
+	if len(p.allow) == 0 && len(p.deny) == 0 {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+// DenyAll returns a policy that refuses every target. It stands in for a
+// policy that failed to load, so a broken list never opens the instance up.
+func DenyAll() *Policy {
+	return &Policy{deny: []string{"*"}}
+}
+
+// patterns parses the entries of a list variable and its file.
+func patterns(name, raw, file string) ([]string, error) {
+	if file != "" {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s_FILE: %w", name, err)
+		}
+		if info.Size() > maxListFileSize {
+			return nil, fmt.Errorf("%s_FILE %s is larger than %d bytes", name, file, maxListFileSize)
+		}
+		data, err := os.ReadFile(file) //nolint:gosec // path comes from operator configuration
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s_FILE: %w", name, err)
+		}
+		raw += "\n" + string(data)
+	}
+
+	var out []string
+	for _, line := range strings.Split(raw, "\n") {
+		// Everything after # is a comment
+		line, _, _ = strings.Cut(line, "#")
+		for _, entry := range strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r'
+		}) {
+			pattern := strings.ToLower(strings.TrimSuffix(entry, "."))
+			if !validPattern(pattern) {
+				return nil, fmt.Errorf("%s: invalid pattern %q: want example.com, *.example.com or *", name, entry)
+			}
+			out = append(out, pattern)
+		}
+	}
+	return out, nil
+}
+
+// validPattern reports whether pattern is "*", "*.domain" or "domain".
+func validPattern(pattern string) bool {
+	if pattern == "*" {
+		return true
+	}
+	domain := strings.TrimPrefix(pattern, "*.")
+	return domain != "" && !strings.ContainsAny(domain, "*/:=") && !strings.HasPrefix(domain, ".")
+}
+
+// matches reports whether host is covered by pattern.
+func matches(pattern, host string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+domain)
+	}
+	return host == pattern || strings.HasSuffix(host, "."+pattern)
+}
+
+// Check returns an error wrapping types.ErrTargetNotAllowed when host is on
+// the denylist or, with an allowlist configured, not on it. The denylist
+// wins over the allowlist.
+func (p *Policy) Check(host string) error {
+	if p == nil {
+		return nil
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range p.deny {
+		if matches(pattern, host) {
+			return fmt.Errorf("%w: %s", types.ErrTargetNotAllowed, host)
+		}
+	}
+	if len(p.allow) == 0 {
+		return nil
+	}
+	for _, pattern := range p.allow {
+		if matches(pattern, host) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", types.ErrTargetNotAllowed, host)
+}
+
+// Summary returns the number of allow and deny patterns, for the startup log.
+func (p *Policy) Summary() (allowed, blocked int) {
+	if p == nil {
+		return 0, 0
+	}
+	return len(p.allow), len(p.deny)
+}
@@ -0,0 +1,102 @@
+package targetpolicy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Rorqualx/flaresolverr-go/internal/types"
+)
+
+func TestLoadEmpty(t *testing.T) {
+	p, err := Load("", " , ", "", "")
+	if p != nil || err != nil {
+		t.Fatalf("Load() with nothing configured = %v, %v; want nil, nil", p, err)
+	}
+	if err := p.Check("anything.example"); err != nil {
+		t.Errorf("nil policy Check() = %v, want nil", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p, err := Load("example.com, *.example.org", "admin.example.com", "", "")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	tests := map[string]bool{
+		"example.com":         true,
+		"www.example.com":     true,
+		"WWW.Example.COM.":    true,
+		"admin.example.com":   false, // denylist wins
+		"x.admin.example.com": false,
+		"example.org":         false, // *.example.org is subdomains only
+		"shop.example.org":    true,
+		"notexample.com":      false,
+		"other.net":           false,
+		"":                    false,
+	}
+	for host, allowed := range tests {
+		err := p.Check(host)
+		if allowed && err != nil {
+			t.Errorf("Check(%q) = %v, want allowed", host, err)
+		}
+		if !allowed && !errors.Is(err, types.ErrTargetNotAllowed) {
+			t.Errorf("Check(%q) = %v, want ErrTargetNotAllowed", host, err)
+		}
+	}
+}
+
+func TestDenylistOnly(t *testing.T) {
+	p, err := Load("", "internal.example, 169.254.169.254", "", "")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := p.Check("www.example.com"); err != nil {
+		t.Errorf("unlisted host refused: %v", err)
+	}
+	for _, host := range []string{"internal.example", "db.internal.example", "169.254.169.254"} {
+		if err := p.Check(host); err == nil {
+			t.Errorf("Check(%q) allowed a denied host", host)
+		}
+	}
+}
+
+func TestLoadFiles(t *testing.T) {
+	dir := t.TempDir()
+	allowFile := filepath.Join(dir, "allow.txt")
+	content := "# customer sites\nexample.com\n*.example.net  # shops\n\n"
+	if err := os.WriteFile(allowFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Load("example.org", "", allowFile, "")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if allowed, blocked := p.Summary(); allowed != 3 || blocked != 0 {
+		t.Errorf("Summary() = %d, %d; want 3, 0", allowed, blocked)
+	}
+	for _, host := range []string{"example.com", "shop.example.net", "example.org"} {
+		if err := p.Check(host); err != nil {
+			t.Errorf("Check(%q) = %v, want allowed", host, err)
+		}
+	}
+
+	if _, err := Load("", "", filepath.Join(dir, "missing.txt"), ""); err == nil {
+		t.Error("missing allowlist file: expected error")
+	}
+}
+
+func TestLoadRejectsInvalidPatterns(t *testing.T) {
+	for _, raw := range []string{"*example.com", "https://example.com", "example.com:443", "a.*.example.com", ".example.com"} {
+		if _, err := Load(raw, "", "", ""); err == nil {
+			t.Errorf("Load(%q): expected error", raw)
+		}
+	}
+}
+
+func TestDenyAll(t *testing.T) {
+	if err := DenyAll().Check("example.com"); !errors.Is(err, types.ErrTargetNotAllowed) {
+		t.Errorf("DenyAll().Check() = %v, want ErrTargetNotAllowed", err)
+	}
+}
@@ -0,0 +1,106 @@
+// Package tracing exports OpenTelemetry spans for the solve pipeline over
+// OTLP/HTTP. Spans are started with Start, which costs next to nothing while
+// tracing is disabled since the global tracer provider is then a no-op.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans of this service.
+const instrumentationName = "github.com/Rorqualx/flaresolverr-go"
+
+// Config controls span export.
+type Config struct {
+	Enabled bool
+	// Endpoint is the full OTLP/HTTP traces URL, e.g.
+	// http://collector:4318/v1/traces. When empty the exporter follows the
+	// standard OTEL_EXPORTER_OTLP_* environment variables.
+	Endpoint string
+	// SampleRatio is the fraction of new traces recorded. Requests carrying
+	// a traceparent header follow the caller's sampling decision.
+	SampleRatio    float64
+	ServiceName    string
+	ServiceVersion string
+}
+
+// Setup installs the global tracer provider and W3C trace context
+// propagation. The returned function flushes pending spans and must be
+// called before exit; it is a no-op when tracing is disabled.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var opts []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			attribute.String("service.name", cfg.ServiceName),
+			attribute.String("service.version", cfg.ServiceVersion),
+		),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		_ = exporter.Shutdown(ctx)
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tp.Shutdown, nil
+}
+
+// Start starts a span named name as a child of the span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartServer starts the span for an API request, continuing the trace of
+// the caller when the request carries a traceparent header.
+func StartServer(r *http.Request, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	return otel.Tracer(instrumentationName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...))
+}
+
+// End marks span as failed when err is non-nil and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		Fail(span, err.Error())
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// Fail marks span as failed with msg without ending it.
+func Fail(span trace.Span, msg string) {
+	span.SetStatus(codes.Error, msg)
+}
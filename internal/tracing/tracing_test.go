@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetupDisabled(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+}
+
+func TestSpans(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	})
+
+	r := httptest.NewRequest("POST", "/v1", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, server := StartServer(r, "request.get")
+	_, child := Start(ctx, "solver.navigate")
+	End(child, errors.New("navigation timeout"))
+	server.End()
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(spans))
+	}
+	nav, req := spans[0], spans[1]
+	if got := req.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("server span trace ID = %s, want the caller's", got)
+	}
+	if nav.Parent().SpanID() != req.SpanContext().SpanID() {
+		t.Error("child span is not parented to the server span")
+	}
+	if nav.Status().Code != codes.Error || nav.Status().Description != "navigation timeout" {
+		t.Errorf("child status = %+v, want the error", nav.Status())
+	}
+	if req.Status().Code != codes.Unset {
+		t.Errorf("server status = %+v, want unset", req.Status())
+	}
+}
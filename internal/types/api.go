@@ -1,9 +1,12 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"regexp"
 	"strings"
+	"time"
 )
 
 // Request validation limits.
@@ -27,6 +30,44 @@ const (
 	MaxTabsTillVerify      = 50
 	MaxSessionTTLMinutes   = 1440 // 24 hours
 	MaxCookieExtractDelay  = 30   // 30 seconds
+	MaxRedirectSettleMs    = 10000
+	MaxXHRWatchMs          = 30000
+	MaxStateCookies        = 1000 // sessions.import cookie jar size
+	MaxStateStorageItems   = 1000 // sessions.import localStorage entries
+	MaxTags                = 8
+	MaxTagKeyLength        = 32
+	MaxTagValueLength      = 64
+	MaxProfileNameLength   = 64
+	MaxSiteKeyLength       = 128
+	MaxDomainLength        = 253
+	MaxDisableMethods      = 16
+	MaxWaitForLength       = 1024
+	MaxActions             = 20
+	MaxActionTextLength    = 4096
+	MaxActionScrollPixels  = 100000
+	MaxEvaluateJsLength    = 64 * 1024
+	MaxCapturePatterns     = 10
+	MaxCapturePatternLen   = 512
+	MaxURLFilterPatterns   = 100
+	MaxPdfPaperInches      = 100
+	MaxLocaleLength        = 35
+	MinViewportSize        = 200
+	MaxViewportSize        = 8192
+	MinPollIntervalMs      = 50
+	MaxPollIntervalMs      = 10000
+	MaxPollAttempts        = 1000
+	MinSelectorBudgetMs    = 100
+	MaxSelectorBudgetMs    = 60000
+)
+
+// Hard ceilings on what one solve returns. The server's limits and a
+// request's limits may be set anywhere up to these.
+const (
+	MaxResponseSizeCeiling   = 100 * 1024 * 1024 // page HTML, bytes
+	MaxScreenshotSizeCeiling = 50 * 1024 * 1024  // decoded screenshot, bytes
+	MaxCookiesCeiling        = 1000              // cookies returned
+	MaxStorageItemsCeiling   = 10000             // localStorage/sessionStorage items each
+	MaxStorageSizeCeiling    = 16 * 1024 * 1024  // serialized localStorage/sessionStorage each, bytes
 )
 
 // Request represents an incoming API request.
@@ -40,10 +81,14 @@ type Request struct {
 	Cookies            []RequestCookie    `json:"cookies,omitempty"`
 	ReturnOnlyCookies  bool               `json:"returnOnlyCookies,omitempty"`
 	Proxy              *Proxy             `json:"proxy,omitempty"`
-	PostData           string             `json:"postData,omitempty"`
-	ContentType        string             `json:"contentType,omitempty"`        // Content type for POST: "application/json" or "application/x-www-form-urlencoded" (default)
+	PostData           string             `json:"postData,omitempty"`           // Request body for request.post/put/patch/delete
+	ContentType        string             `json:"contentType,omitempty"`        // Body content type: "application/json" or "application/x-www-form-urlencoded" (default)
 	Headers            map[string]string  `json:"headers,omitempty"`            // Custom HTTP headers to send with the request
 	ReturnScreenshot   bool               `json:"returnScreenshot,omitempty"`   // Capture screenshot and return as base64
+	ScreenshotFormat   string             `json:"screenshotFormat,omitempty"`   // Screenshot image format: "png" (default), "jpeg" or "webp"
+	ScreenshotQuality  int                `json:"screenshotQuality,omitempty"`  // JPEG/WebP quality 1-100
+	ScreenshotFullPage *bool              `json:"screenshotFullPage,omitempty"` // Capture the whole page; false captures only the viewport (default: true)
+	ScreenshotSelector string             `json:"screenshotSelector,omitempty"` // Capture only the first element matching this CSS selector
 	DisableMedia       bool               `json:"disableMedia,omitempty"`       // Disable loading of media (images, CSS, fonts)
 	WaitInSeconds      int                `json:"waitInSeconds,omitempty"`      // Wait N seconds before returning the response
 	TabsTillVerify     int                `json:"tabsTillVerify,omitempty"`     // Number of Tab presses to reach Turnstile checkbox (default: 10)
@@ -54,12 +99,65 @@ type Request struct {
 	UserAgent          string             `json:"userAgent,omitempty"`          // Override User-Agent for this request
 	ReturnRawHtml      bool               `json:"returnRawHtml,omitempty"`      //nolint:revive,stylecheck // JSON API compatibility
 	ExecuteJs          string             `json:"executeJs,omitempty"`          // Custom JavaScript to execute after solve
+	SolveRecaptcha     bool               `json:"solveRecaptcha,omitempty"`     // Solve a reCAPTCHA embedded in the final page via the external solver chain
 	KeepaliveTTL       int                `json:"keepaliveTtl,omitempty"`       // New TTL in minutes for sessions.keepalive (0 = just touch)
 	CookieExtractDelay int                `json:"cookieExtractDelay,omitempty"` // Seconds to wait before extracting cookies (0-30)
 	BrowserFlags       *BrowserFlags      `json:"browserFlags,omitempty"`       // Per-session Chrome flag overrides (sessions.create only)
 	Fingerprint        *FingerprintConfig `json:"fingerprint,omitempty"`        // Per-request browser fingerprint customization
+	RedirectSettleMs   *int               `json:"redirectSettleMs,omitempty"`   // Window to follow meta-refresh/JS redirects after clearance (0 = off, default: server)
+	SessionState       *SessionState      `json:"sessionState,omitempty"`       // Cookie jar and localStorage to load (sessions.import only)
+	XHRWatchMs         *int               `json:"xhrWatchMs,omitempty"`         // Window to watch post-load XHR/fetch responses for challenges (0 = off, default: server)
+	XHRChallengeAction string             `json:"xhrChallengeAction,omitempty"` // On a challenged XHR: "resolve" (default) re-solves, "report" fails with XHR_CHALLENGED
+	Tags               map[string]string  `json:"tags,omitempty"`               // Caller attribution (e.g. {"app":"prowlarr"}) carried into logs and metrics
+	AllowCacheFallback bool               `json:"allowCacheFallback,omitempty"` // On persistent access denied, return an archived copy marked stale (request.get only)
+	Profile            string             `json:"profile,omitempty"`            // Named browser profile from BROWSER_PROFILES_PATH (request.* and sessions.create)
+	Priority           string             `json:"priority,omitempty"`           // Queueing priority on a saturated pool: "low", "normal" or "high"
+	PoolSize           int                `json:"poolSize,omitempty"`           // New number of pooled browsers (pool.resize only)
+	Domain             string             `json:"domain,omitempty"`             // Domain whose solver preferences to read or set (domains.getPrefs/setPrefs)
+	DomainPrefs        *DomainPrefs       `json:"domainPrefs,omitempty"`        // Solver preferences to set; omitted clears them (domains.setPrefs only)
+	SiteKey            string             `json:"siteKey,omitempty"`            // Turnstile sitekey to solve (turnstile.solve only)
+	WaitForSelector    string             `json:"waitForSelector,omitempty"`    // CSS selector to wait for after the challenge clears (request.get/post)
+	WaitForText        string             `json:"waitForText,omitempty"`        // Text to wait for in the page after the challenge clears (request.get/post)
+	Actions            []Action           `json:"actions,omitempty"`            // Steps run on the page after the challenge clears (request.get/post)
+	EvaluateJs         string             `json:"evaluateJs,omitempty"`         // Script whose JSON return value is returned as solution.jsResult (EVALUATE_JS_ENABLED)
+	CaptureRequests    []string           `json:"captureRequests,omitempty"`    // URL patterns of XHR/fetch responses to return in solution.capturedRequests (request.get/post)
+	BlockURLs          []string           `json:"blockUrls,omitempty"`          // URL patterns of page requests to fail, e.g. trackers and ads (request.*)
+	AllowURLs          []string           `json:"allowUrls,omitempty"`          // URL patterns never blocked, by blockUrls or disableMedia (request.*)
+	ReturnHar          bool               `json:"returnHar,omitempty"`          // Return the solve's network traffic as a HAR archive in solution.har (request.get/post)
+	ReturnPdf          bool               `json:"returnPdf,omitempty"`          // Render the final page to PDF, base64-encoded in solution.pdf (request.get/post)
+	PdfOptions         *PdfOptions        `json:"pdfOptions,omitempty"`         // Page size and layout for returnPdf
+	ReturnSnapshot     string             `json:"returnSnapshot,omitempty"`     // Archive the final page with its resources in solution.snapshot: "mhtml" (request.get/post)
+	RecordSolve        bool               `json:"recordSolve,omitempty"`        // Screencast the solve as an animated GIF in solution.recording or RECORD_SOLVE_DIR (request.get/post)
+	VerifyProxy        bool               `json:"verifyProxy,omitempty"`        // Check the browser's egress IP through PROXY_VERIFY_URL before navigating; fail if it is the server's own (request.*)
+	Locale             string             `json:"locale,omitempty"`             // BCP 47 language tag for Accept-Language, navigator.languages and Intl (e.g. "fr-FR")
+	Timezone           string             `json:"timezone,omitempty"`           // IANA timezone for this request (e.g. "Europe/Paris"); wins over fingerprint and session timezones
+	Viewport           *Viewport          `json:"viewport,omitempty"`           // Page viewport size (default: 1920x1080)
+	Poll               *PollOptions       `json:"poll,omitempty"`               // Solve-loop polling overrides (request.get/post)
+	StreamResponse     bool               `json:"streamResponse,omitempty"`     // Stream the solution as NDJSON, the page HTML in chunks after the rest (request.*)
+	Limits             *ResponseLimits    `json:"limits,omitempty"`             // Size limits on the returned page, screenshot, cookies and storage
+}
+
+// Viewport is a page viewport size in CSS pixels.
+type Viewport struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
 }
 
+// Validate checks the viewport dimensions.
+func (v *Viewport) Validate() error {
+	if v.Width < MinViewportSize || v.Width > MaxViewportSize {
+		return fmt.Errorf("width must be between %d and %d", MinViewportSize, MaxViewportSize)
+	}
+	if v.Height < MinViewportSize || v.Height > MaxViewportSize {
+		return fmt.Errorf("height must be between %d and %d", MinViewportSize, MaxViewportSize)
+	}
+	return nil
+}
+
+// localePattern matches a BCP 47 language tag such as "en", "fr-FR" or
+// "zh-Hant-TW".
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
 // Validate validates the request and returns an error if invalid.
 // Fix HIGH: Add comprehensive input validation to prevent resource exhaustion and injection.
 func (r *Request) Validate() error {
@@ -73,7 +171,11 @@ func (r *Request) Validate() error {
 
 	// Validate cmd is a known command
 	switch r.Cmd {
-	case CmdRequestGet, CmdRequestPost, CmdSessionsCreate, CmdSessionsList, CmdSessionsDestroy, CmdSessionsKeepalive:
+	case CmdRequestGet, CmdRequestPost, CmdRequestPut, CmdRequestPatch, CmdRequestDelete,
+		CmdSessionsCreate, CmdSessionsList, CmdSessionsDestroy, CmdSessionsKeepalive,
+		CmdSessionsExport, CmdSessionsImport, CmdSessionsInfo, CmdSessionsTouch,
+		CmdPoolDrain, CmdPoolResize, CmdPoolRecycleAll, CmdTurnstileSolve,
+		CmdProxiesStatus, CmdCaptchaStatus, CmdDomainsGetPrefs, CmdDomainsSetPrefs:
 		// Valid command
 	default:
 		// Use %q format for security (prevents log injection) - matches test expectations
@@ -223,9 +325,358 @@ func (r *Request) Validate() error {
 		return fmt.Errorf("cookieExtractDelay exceeds maximum of %d seconds", MaxCookieExtractDelay)
 	}
 
+	// Validate imported session state
+	if r.SessionState != nil {
+		if err := r.SessionState.Validate(); err != nil {
+			return fmt.Errorf("sessionState: %w", err)
+		}
+	}
+
+	// Validate redirectSettleMs bounds
+	if r.RedirectSettleMs != nil {
+		if *r.RedirectSettleMs < 0 {
+			return fmt.Errorf("redirectSettleMs cannot be negative")
+		}
+		if *r.RedirectSettleMs > MaxRedirectSettleMs {
+			return fmt.Errorf("redirectSettleMs exceeds maximum of %d", MaxRedirectSettleMs)
+		}
+	}
+
+	// Validate xhrWatchMs bounds and the challenged-XHR action
+	if r.XHRWatchMs != nil {
+		if *r.XHRWatchMs < 0 {
+			return fmt.Errorf("xhrWatchMs cannot be negative")
+		}
+		if *r.XHRWatchMs > MaxXHRWatchMs {
+			return fmt.Errorf("xhrWatchMs exceeds maximum of %d", MaxXHRWatchMs)
+		}
+	}
+	switch r.XHRChallengeAction {
+	case "", XHRChallengeResolve, XHRChallengeReport:
+	default:
+		return fmt.Errorf("xhrChallengeAction must be %q or %q", XHRChallengeResolve, XHRChallengeReport)
+	}
+
+	// Validate tags
+	if len(r.Tags) > MaxTags {
+		return fmt.Errorf("too many tags (maximum %d)", MaxTags)
+	}
+	for key, value := range r.Tags {
+		if !isValidTagKey(key) {
+			return fmt.Errorf("tag key %q must be 1-%d letters, digits or underscores", key, MaxTagKeyLength)
+		}
+		if value == "" || len(value) > MaxTagValueLength {
+			return fmt.Errorf("tag %q: value must be 1-%d characters", key, MaxTagValueLength)
+		}
+		for _, c := range value {
+			if c < 0x20 || c == 0x7f {
+				return fmt.Errorf("tag %q: value contains control characters", key)
+			}
+		}
+	}
+
+	// Validate the page emulation settings
+	if r.Locale != "" && (len(r.Locale) > MaxLocaleLength || !localePattern.MatchString(r.Locale)) {
+		return fmt.Errorf("locale must be a language tag such as 'en-US'")
+	}
+	if r.Timezone != "" {
+		if _, err := time.LoadLocation(r.Timezone); err != nil || r.Timezone == "Local" {
+			return fmt.Errorf("timezone %q is not an IANA timezone name", r.Timezone)
+		}
+	}
+	if r.Viewport != nil {
+		if err := r.Viewport.Validate(); err != nil {
+			return fmt.Errorf("viewport: %w", err)
+		}
+	}
+
+	if r.Poll != nil {
+		if err := r.Poll.Validate(); err != nil {
+			return fmt.Errorf("poll: %w", err)
+		}
+	}
+	if r.Limits != nil {
+		if err := r.Limits.Validate(); err != nil {
+			return fmt.Errorf("limits: %w", err)
+		}
+	}
+
+	// The cache fallback returns a page copy, which only makes sense for reads
+	if r.AllowCacheFallback && r.Cmd != CmdRequestGet {
+		return fmt.Errorf("allowCacheFallback is only supported for %s", CmdRequestGet)
+	}
+
+	// pool.resize needs the new size; the upper bound is checked by the pool
+	if r.Cmd == CmdPoolResize && r.PoolSize < 1 {
+		return fmt.Errorf("poolSize is required for %s and must be at least 1 (use %s to empty the pool)", CmdPoolResize, CmdPoolDrain)
+	}
+	if r.PoolSize != 0 && r.Cmd != CmdPoolResize {
+		return fmt.Errorf("poolSize is only supported for %s", CmdPoolResize)
+	}
+
+	// domains.getPrefs and domains.setPrefs name the domain they act on
+	isDomainPrefsCmd := r.Cmd == CmdDomainsGetPrefs || r.Cmd == CmdDomainsSetPrefs
+	if isDomainPrefsCmd && strings.TrimSpace(r.Domain) == "" {
+		return fmt.Errorf("domain is required for %s", r.Cmd)
+	}
+	if r.Domain != "" && !isDomainPrefsCmd {
+		return fmt.Errorf("domain is only supported for %s and %s", CmdDomainsGetPrefs, CmdDomainsSetPrefs)
+	}
+	if len(r.Domain) > MaxDomainLength {
+		return fmt.Errorf("domain exceeds maximum length of %d", MaxDomainLength)
+	}
+	if r.DomainPrefs != nil {
+		if r.Cmd != CmdDomainsSetPrefs {
+			return fmt.Errorf("domainPrefs is only supported for %s", CmdDomainsSetPrefs)
+		}
+		if err := r.DomainPrefs.Validate(); err != nil {
+			return fmt.Errorf("domainPrefs: %w", err)
+		}
+	}
+
+	// turnstile.solve needs the page and the widget's sitekey, and runs on a
+	// fresh browser page
+	if r.Cmd == CmdTurnstileSolve {
+		if r.URL == "" || r.SiteKey == "" {
+			return fmt.Errorf("url and siteKey are required for %s", CmdTurnstileSolve)
+		}
+		if r.Session != "" {
+			return fmt.Errorf("session is not supported for %s", CmdTurnstileSolve)
+		}
+	}
+	if r.SiteKey != "" {
+		if r.Cmd != CmdTurnstileSolve {
+			return fmt.Errorf("siteKey is only supported for %s", CmdTurnstileSolve)
+		}
+		if len(r.SiteKey) > MaxSiteKeyLength {
+			return fmt.Errorf("siteKey exceeds maximum length of %d", MaxSiteKeyLength)
+		}
+		for _, c := range r.SiteKey {
+			if c <= 0x20 || c == 0x7f {
+				return fmt.Errorf("siteKey contains whitespace or control characters")
+			}
+		}
+	}
+
+	// waitForSelector/waitForText poll the rendered page, which only the
+	// navigating commands have
+	if r.WaitForSelector != "" || r.WaitForText != "" {
+		if r.Cmd != CmdRequestGet && r.Cmd != CmdRequestPost {
+			return fmt.Errorf("waitForSelector and waitForText are only supported for %s and %s", CmdRequestGet, CmdRequestPost)
+		}
+		if len(r.WaitForSelector) > MaxWaitForLength {
+			return fmt.Errorf("waitForSelector exceeds maximum length of %d", MaxWaitForLength)
+		}
+		if len(r.WaitForText) > MaxWaitForLength {
+			return fmt.Errorf("waitForText exceeds maximum length of %d", MaxWaitForLength)
+		}
+	}
+
+	// Actions drive the rendered page, like waitForSelector
+	if len(r.Actions) > 0 {
+		if r.Cmd != CmdRequestGet && r.Cmd != CmdRequestPost {
+			return fmt.Errorf("actions are only supported for %s and %s", CmdRequestGet, CmdRequestPost)
+		}
+		if len(r.Actions) > MaxActions {
+			return fmt.Errorf("too many actions (maximum %d)", MaxActions)
+		}
+		for i := range r.Actions {
+			if err := r.Actions[i].Validate(); err != nil {
+				return fmt.Errorf("actions[%d]: %w", i, err)
+			}
+		}
+	}
+
+	// evaluateJs runs in the rendered page; whether it is allowed at all is
+	// the handler's call (EVALUATE_JS_ENABLED)
+	if r.EvaluateJs != "" {
+		if r.Cmd != CmdRequestGet && r.Cmd != CmdRequestPost {
+			return fmt.Errorf("evaluateJs is only supported for %s and %s", CmdRequestGet, CmdRequestPost)
+		}
+		if len(r.EvaluateJs) > MaxEvaluateJsLength {
+			return fmt.Errorf("evaluateJs exceeds maximum length of %d", MaxEvaluateJsLength)
+		}
+	}
+
+	// captureRequests records the page's own XHR/fetch traffic
+	if len(r.CaptureRequests) > 0 {
+		if r.Cmd != CmdRequestGet && r.Cmd != CmdRequestPost {
+			return fmt.Errorf("captureRequests is only supported for %s and %s", CmdRequestGet, CmdRequestPost)
+		}
+		if len(r.CaptureRequests) > MaxCapturePatterns {
+			return fmt.Errorf("too many captureRequests patterns (maximum %d)", MaxCapturePatterns)
+		}
+		for i, p := range r.CaptureRequests {
+			if p == "" || len(p) > MaxCapturePatternLen {
+				return fmt.Errorf("captureRequests[%d] must be 1-%d characters", i, MaxCapturePatternLen)
+			}
+		}
+	}
+
+	// blockUrls/allowUrls filter the page's own requests
+	if len(r.BlockURLs) > 0 || len(r.AllowURLs) > 0 {
+		switch r.Cmd {
+		case CmdRequestGet, CmdRequestPost, CmdRequestPut, CmdRequestPatch, CmdRequestDelete:
+		default:
+			return fmt.Errorf("blockUrls and allowUrls are only supported for request.* commands")
+		}
+		lists := []struct {
+			name     string
+			patterns []string
+		}{{"blockUrls", r.BlockURLs}, {"allowUrls", r.AllowURLs}}
+		for _, list := range lists {
+			if len(list.patterns) > MaxURLFilterPatterns {
+				return fmt.Errorf("too many %s patterns (maximum %d)", list.name, MaxURLFilterPatterns)
+			}
+			for i, p := range list.patterns {
+				if p == "" || len(p) > MaxCapturePatternLen {
+					return fmt.Errorf("%s[%d] must be 1-%d characters", list.name, i, MaxCapturePatternLen)
+				}
+			}
+		}
+	}
+
+	if r.ReturnHar && r.Cmd != CmdRequestGet && r.Cmd != CmdRequestPost {
+		return fmt.Errorf("returnHar is only supported for %s and %s", CmdRequestGet, CmdRequestPost)
+	}
+
+	if r.ReturnPdf && r.Cmd != CmdRequestGet && r.Cmd != CmdRequestPost {
+		return fmt.Errorf("returnPdf is only supported for %s and %s", CmdRequestGet, CmdRequestPost)
+	}
+	if r.PdfOptions != nil {
+		if !r.ReturnPdf {
+			return fmt.Errorf("pdfOptions requires returnPdf")
+		}
+		if err := r.PdfOptions.Validate(); err != nil {
+			return fmt.Errorf("invalid pdfOptions: %w", err)
+		}
+	}
+
+	if r.VerifyProxy {
+		switch r.Cmd {
+		case CmdRequestGet, CmdRequestPost, CmdRequestPut, CmdRequestPatch, CmdRequestDelete:
+		default:
+			return fmt.Errorf("verifyProxy is only supported for request.* commands")
+		}
+	}
+
+	if r.StreamResponse {
+		switch r.Cmd {
+		case CmdRequestGet, CmdRequestPost, CmdRequestPut, CmdRequestPatch, CmdRequestDelete:
+		default:
+			return fmt.Errorf("streamResponse is only supported for request.* commands")
+		}
+		if r.ReturnOnlyCookies {
+			return fmt.Errorf("streamResponse cannot be combined with returnOnlyCookies")
+		}
+	}
+
+	if r.RecordSolve && r.Cmd != CmdRequestGet && r.Cmd != CmdRequestPost {
+		return fmt.Errorf("recordSolve is only supported for %s and %s", CmdRequestGet, CmdRequestPost)
+	}
+
+	if r.ReturnSnapshot != "" {
+		if r.Cmd != CmdRequestGet && r.Cmd != CmdRequestPost {
+			return fmt.Errorf("returnSnapshot is only supported for %s and %s", CmdRequestGet, CmdRequestPost)
+		}
+		if r.ReturnSnapshot != SnapshotMHTML {
+			return fmt.Errorf("returnSnapshot must be %q", SnapshotMHTML)
+		}
+	}
+
+	// Screenshot options shape returnScreenshot's image
+	if r.ScreenshotFormat != "" || r.ScreenshotQuality != 0 || r.ScreenshotFullPage != nil || r.ScreenshotSelector != "" {
+		if !r.ReturnScreenshot {
+			return fmt.Errorf("screenshot options require returnScreenshot")
+		}
+		switch r.ScreenshotFormat {
+		case "", ScreenshotPNG, ScreenshotJPEG, ScreenshotWebP:
+		default:
+			return fmt.Errorf("screenshotFormat must be %q, %q or %q", ScreenshotPNG, ScreenshotJPEG, ScreenshotWebP)
+		}
+		if r.ScreenshotQuality != 0 {
+			if r.ScreenshotFormat != ScreenshotJPEG && r.ScreenshotFormat != ScreenshotWebP {
+				return fmt.Errorf("screenshotQuality only applies to %q and %q", ScreenshotJPEG, ScreenshotWebP)
+			}
+			if r.ScreenshotQuality < 1 || r.ScreenshotQuality > 100 {
+				return fmt.Errorf("screenshotQuality must be between 1 and 100")
+			}
+		}
+		if len(r.ScreenshotSelector) > MaxWaitForLength {
+			return fmt.Errorf("screenshotSelector exceeds maximum length of %d", MaxWaitForLength)
+		}
+	}
+
+	// Validate the acquisition priority
+	switch r.Priority {
+	case "", PriorityLow, PriorityNormal, PriorityHigh:
+	default:
+		return fmt.Errorf("priority must be %q, %q or %q", PriorityLow, PriorityNormal, PriorityHigh)
+	}
+
+	// Validate the browser profile name; whether it exists is checked against
+	// the loaded profiles by the handler
+	if r.Profile != "" {
+		if !ValidBrowserProfileName(r.Profile) {
+			return fmt.Errorf("profile must be 1-%d letters, digits, '.', '_' or '-'", MaxProfileNameLength)
+		}
+		switch r.Cmd {
+		case CmdRequestGet, CmdRequestPost, CmdRequestPut, CmdRequestPatch, CmdRequestDelete:
+			// A session's browser was launched with its own profile
+			if r.Session != "" {
+				return fmt.Errorf("profile cannot be combined with session; set it on %s instead", CmdSessionsCreate)
+			}
+		case CmdSessionsCreate:
+		default:
+			return fmt.Errorf("profile is not supported for %s", r.Cmd)
+		}
+	}
+
 	return nil
 }
 
+// ValidBrowserProfileName reports whether name is usable as a browser profile
+// name: letters, digits, '.', '_' and '-', not starting with '.' (names
+// double as directory names), at most MaxProfileNameLength.
+func ValidBrowserProfileName(name string) bool {
+	if name == "" || len(name) > MaxProfileNameLength || name[0] == '.' {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case c == '.', c == '_', c == '-':
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ValidProxyPoolName reports whether name is usable as a proxy pool name,
+// under the same rules as browser profile names.
+func ValidProxyPoolName(name string) bool {
+	return ValidBrowserProfileName(name)
+}
+
+// isValidTagKey reports whether key is usable as a tag name: letters, digits
+// and underscores, starting with a letter or underscore, so it is safe in log
+// fields and metric labels alike.
+func isValidTagKey(key string) bool {
+	if key == "" || len(key) > MaxTagKeyLength {
+		return false
+	}
+	for i, c := range key {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // RequestCookie represents a cookie to be set before navigation.
 type RequestCookie struct {
 	Name     string `json:"name"`
@@ -241,10 +692,26 @@ type Proxy struct {
 	URL      string `json:"url"`
 	Username string `json:"username,omitempty"`
 	Password string `json:"password,omitempty"`
+	Pool     string `json:"pool,omitempty"`     // Named pool from PROXY_POOLS_PATH, instead of url
+	Provider string `json:"provider,omitempty"` // Sticky-session username syntax: brightdata|oxylabs|smartproxy|none; detected from the host when empty
 }
 
 // Validate validates the proxy configuration.
 func (p *Proxy) Validate() error {
+	switch p.Provider {
+	case "", "brightdata", "oxylabs", "smartproxy", "none":
+	default:
+		return fmt.Errorf("provider must be brightdata, oxylabs, smartproxy or none")
+	}
+	if p.Pool != "" {
+		if p.URL != "" || p.Username != "" || p.Password != "" {
+			return fmt.Errorf("pool cannot be combined with url, username or password")
+		}
+		if !ValidProxyPoolName(p.Pool) {
+			return fmt.Errorf("pool name must be 1-%d letters, digits, '.', '_' or '-'", MaxProfileNameLength)
+		}
+		return nil
+	}
 	if p.URL == "" {
 		return nil // Empty proxy is valid (means no proxy)
 	}
@@ -290,6 +757,341 @@ type Response struct {
 	Version   string    `json:"version"`
 	Solution  *Solution `json:"solution,omitempty"`
 	Sessions  []string  `json:"sessions,omitempty"`
+
+	// RequestID is the X-Request-ID of the call, for correlating logs
+	RequestID string `json:"requestId,omitempty"`
+
+	// SessionsInfo carries per-session TTL details for sessions.list
+	SessionsInfo []SessionInfo `json:"sessionsInfo,omitempty"`
+
+	// SessionState is the exported cookie jar and localStorage (sessions.export)
+	SessionState *SessionState `json:"sessionState,omitempty"`
+
+	// SessionDetails carries per-session operational state (sessions.info)
+	SessionDetails []SessionDetail `json:"sessionDetails,omitempty"`
+
+	// SessionTouch reports the outcome of a sessions.touch warm-up navigation
+	SessionTouch *SessionTouch `json:"sessionTouch,omitempty"`
+
+	// Proxies carries the proxy health checks (proxies.status)
+	Proxies []ProxyStatus `json:"proxies,omitempty"`
+
+	// CaptchaProviders carries the external captcha provider accounts (captcha.status)
+	CaptchaProviders []CaptchaProviderStatus `json:"captchaProviders,omitempty"`
+
+	// DomainPrefs carries a domain's solver preferences (domains.getPrefs, domains.setPrefs)
+	DomainPrefs *DomainPrefs `json:"domainPrefs,omitempty"`
+}
+
+// CapturedRequest is an XHR/fetch response recorded for captureRequests.
+type CapturedRequest struct {
+	URL           string `json:"url"`
+	Status        int    `json:"status"`
+	MimeType      string `json:"mimeType,omitempty"`
+	Body          string `json:"body"`
+	Base64Encoded bool   `json:"base64Encoded,omitempty"` // Body is base64 (binary responses)
+}
+
+// Post-solve action types.
+const (
+	ActionClick  = "click"  // Click the element matching Selector
+	ActionType   = "type"   // Click the element matching Selector, then type Text
+	ActionSelect = "select" // Choose the option with Value in the <select> matching Selector
+	ActionScroll = "scroll" // Scroll the element matching Selector into view, or the page by Pixels
+	ActionWait   = "wait"   // Wait for the element matching Selector to appear, or for Ms milliseconds
+
+	ActionImageCaptcha = "imageCaptcha" // Read the captcha image matching Selector via a provider, then type the answer into Input
+)
+
+// Action is one step of a request's actions list, run in order on the page
+// after the challenge clears.
+type Action struct {
+	Type     string `json:"type"`
+	Selector string `json:"selector,omitempty"` // CSS selector of the target element
+	Text     string `json:"text,omitempty"`     // Text to type (type)
+	Value    string `json:"value,omitempty"`    // Option value to choose (select)
+	Pixels   int    `json:"pixels,omitempty"`   // Distance to scroll, negative for up (scroll without selector)
+	Ms       int    `json:"ms,omitempty"`       // Pause in milliseconds (wait without selector)
+	Input    string `json:"input,omitempty"`    // CSS selector of the answer field (imageCaptcha)
+}
+
+// Validate checks an action's type and the fields that type needs.
+func (a *Action) Validate() error {
+	if len(a.Selector) > MaxWaitForLength || len(a.Input) > MaxWaitForLength {
+		return fmt.Errorf("selector exceeds maximum length of %d", MaxWaitForLength)
+	}
+	if len(a.Text) > MaxActionTextLength || len(a.Value) > MaxActionTextLength {
+		return fmt.Errorf("text and value must not exceed %d bytes", MaxActionTextLength)
+	}
+	switch a.Type {
+	case ActionClick:
+		if a.Selector == "" {
+			return fmt.Errorf("click needs a selector")
+		}
+	case ActionType:
+		if a.Selector == "" || a.Text == "" {
+			return fmt.Errorf("type needs a selector and text")
+		}
+	case ActionSelect:
+		if a.Selector == "" || a.Value == "" {
+			return fmt.Errorf("select needs a selector and value")
+		}
+	case ActionScroll:
+		if a.Selector == "" && a.Pixels == 0 {
+			return fmt.Errorf("scroll needs a selector or pixels")
+		}
+		if a.Pixels < -MaxActionScrollPixels || a.Pixels > MaxActionScrollPixels {
+			return fmt.Errorf("pixels must be within ±%d", MaxActionScrollPixels)
+		}
+	case ActionWait:
+		if a.Selector == "" && a.Ms <= 0 {
+			return fmt.Errorf("wait needs a selector or a positive ms")
+		}
+		if a.Ms < 0 || a.Ms > MaxWaitSeconds*1000 {
+			return fmt.Errorf("ms must be between 0 and %d", MaxWaitSeconds*1000)
+		}
+	case ActionImageCaptcha:
+		if a.Selector == "" || a.Input == "" {
+			return fmt.Errorf("imageCaptcha needs a selector and input")
+		}
+	default:
+		return fmt.Errorf("unknown action type %q", a.Type)
+	}
+	return nil
+}
+
+// Screenshot image formats for screenshotFormat.
+const (
+	ScreenshotPNG  = "png"
+	ScreenshotJPEG = "jpeg"
+	ScreenshotWebP = "webp"
+)
+
+// SnapshotMHTML is the returnSnapshot format for an MHTML web archive.
+const SnapshotMHTML = "mhtml"
+
+// PdfPaperSizes maps the pdfOptions formats to their width and height in
+// inches, portrait.
+var PdfPaperSizes = map[string][2]float64{
+	"letter":  {8.5, 11},
+	"legal":   {8.5, 14},
+	"tabloid": {11, 17},
+	"a3":      {11.69, 16.54},
+	"a4":      {8.27, 11.69},
+	"a5":      {5.83, 8.27},
+}
+
+// PdfOptions sets the page size and layout of a returnPdf rendering. The
+// zero value prints Letter portrait at scale 1 without backgrounds.
+type PdfOptions struct {
+	Format          string  `json:"format,omitempty"`          // Paper size from PdfPaperSizes, case-insensitive
+	Width           float64 `json:"width,omitempty"`           // Custom paper width in inches (with height; overrides format)
+	Height          float64 `json:"height,omitempty"`          // Custom paper height in inches
+	Landscape       bool    `json:"landscape,omitempty"`       // Swap width and height
+	PrintBackground bool    `json:"printBackground,omitempty"` // Include background colors and images
+	Scale           float64 `json:"scale,omitempty"`           // Rendering scale, 0.1-2 (default 1)
+}
+
+// Validate checks the paper size and scale.
+func (o *PdfOptions) Validate() error {
+	if o.Format != "" {
+		if _, ok := PdfPaperSizes[strings.ToLower(o.Format)]; !ok {
+			return fmt.Errorf("unknown format %q (Letter, Legal, Tabloid, A3, A4 or A5)", o.Format)
+		}
+	}
+	if (o.Width != 0) != (o.Height != 0) {
+		return fmt.Errorf("width and height must be set together")
+	}
+	if o.Width < 0 || o.Width > MaxPdfPaperInches || o.Height < 0 || o.Height > MaxPdfPaperInches {
+		return fmt.Errorf("width and height must be between 0 and %d inches", MaxPdfPaperInches)
+	}
+	if o.Scale != 0 && (o.Scale < 0.1 || o.Scale > 2) {
+		return fmt.Errorf("scale must be between 0.1 and 2")
+	}
+	return nil
+}
+
+// PollOptions overrides the server's solve-loop polling settings for one
+// request. Zero fields keep the server's values.
+type PollOptions struct {
+	IntervalMinMs    int `json:"intervalMinMs,omitempty"`    // Shortest pause between detection passes of the random strategy
+	IntervalMaxMs    int `json:"intervalMaxMs,omitempty"`    // Longest pause between detection passes of the random strategy
+	MaxAttempts      int `json:"maxAttempts,omitempty"`      // Cap on detection passes
+	SelectorBudgetMs int `json:"selectorBudgetMs,omitempty"` // Time one pass may spend checking challenge selectors
+}
+
+// Validate checks the polling bounds.
+func (o *PollOptions) Validate() error {
+	for _, v := range []int{o.IntervalMinMs, o.IntervalMaxMs} {
+		if v != 0 && (v < MinPollIntervalMs || v > MaxPollIntervalMs) {
+			return fmt.Errorf("intervalMinMs and intervalMaxMs must be between %d and %d", MinPollIntervalMs, MaxPollIntervalMs)
+		}
+	}
+	if o.IntervalMinMs != 0 && o.IntervalMaxMs != 0 && o.IntervalMaxMs < o.IntervalMinMs {
+		return fmt.Errorf("intervalMaxMs cannot be less than intervalMinMs")
+	}
+	if o.MaxAttempts < 0 || o.MaxAttempts > MaxPollAttempts {
+		return fmt.Errorf("maxAttempts must be between 0 and %d", MaxPollAttempts)
+	}
+	if o.SelectorBudgetMs != 0 && (o.SelectorBudgetMs < MinSelectorBudgetMs || o.SelectorBudgetMs > MaxSelectorBudgetMs) {
+		return fmt.Errorf("selectorBudgetMs must be between %d and %d", MinSelectorBudgetMs, MaxSelectorBudgetMs)
+	}
+	return nil
+}
+
+// ResponseLimits overrides the server's limits on what one solve returns,
+// up or down to the hard ceilings. Zero fields keep the server's values.
+type ResponseLimits struct {
+	MaxResponseSize   int `json:"maxResponseSize,omitempty"`   // Page HTML in bytes; longer pages are truncated and flagged responseTruncated
+	MaxScreenshotSize int `json:"maxScreenshotSize,omitempty"` // Screenshot in bytes; larger ones are dropped
+	MaxCookies        int `json:"maxCookies,omitempty"`        // Cookies returned
+	MaxStorageItems   int `json:"maxStorageItems,omitempty"`   // localStorage and sessionStorage items returned, each
+	MaxStorageSize    int `json:"maxStorageSize,omitempty"`    // Serialized localStorage and sessionStorage in bytes, each; larger ones are dropped
+}
+
+// Validate checks the limits against the hard ceilings.
+func (l *ResponseLimits) Validate() error {
+	for _, f := range []struct {
+		name    string
+		value   int
+		ceiling int
+	}{
+		{"maxResponseSize", l.MaxResponseSize, MaxResponseSizeCeiling},
+		{"maxScreenshotSize", l.MaxScreenshotSize, MaxScreenshotSizeCeiling},
+		{"maxCookies", l.MaxCookies, MaxCookiesCeiling},
+		{"maxStorageItems", l.MaxStorageItems, MaxStorageItemsCeiling},
+		{"maxStorageSize", l.MaxStorageSize, MaxStorageSizeCeiling},
+	} {
+		if f.value < 0 || f.value > f.ceiling {
+			return fmt.Errorf("%s must be between 0 and %d", f.name, f.ceiling)
+		}
+	}
+	return nil
+}
+
+// SessionState is the portable clearance state of a session, produced by
+// sessions.export and accepted by sessions.import on any instance.
+type SessionState struct {
+	Cookies      []Cookie          `json:"cookies"`
+	Origin       string            `json:"origin,omitempty"`       // Origin the localStorage items belong to
+	LocalStorage map[string]string `json:"localStorage,omitempty"` // localStorage of the session's current origin
+	UserAgent    string            `json:"userAgent,omitempty"`    // UA the cookies were minted with (cf_clearance is UA-bound)
+}
+
+// Validate checks an imported session state against size and format limits.
+func (s *SessionState) Validate() error {
+	if len(s.Cookies) > MaxStateCookies {
+		return fmt.Errorf("too many cookies (maximum %d)", MaxStateCookies)
+	}
+	for i, c := range s.Cookies {
+		if c.Name == "" {
+			return fmt.Errorf("cookie[%d]: name is required", i)
+		}
+		if c.Domain == "" {
+			return fmt.Errorf("cookie[%d]: domain is required", i)
+		}
+		if len(c.Name) > MaxCookieNameLength || len(c.Value) > MaxCookieValueLength ||
+			len(c.Domain) > MaxCookieDomainLength || len(c.Path) > MaxCookiePathLength {
+			return fmt.Errorf("cookie[%d]: field exceeds maximum length", i)
+		}
+		switch c.SameSite {
+		case "", "Strict", "Lax", "None":
+		default:
+			return fmt.Errorf("cookie[%d]: invalid sameSite %q", i, c.SameSite)
+		}
+	}
+	if len(s.LocalStorage) > MaxStateStorageItems {
+		return fmt.Errorf("too many localStorage items (maximum %d)", MaxStateStorageItems)
+	}
+	if len(s.LocalStorage) > 0 {
+		u, err := url.Parse(s.Origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("origin must be an http(s) origin when localStorage is set")
+		}
+	}
+	if strings.ContainsAny(s.UserAgent, "\r\n") {
+		return fmt.Errorf("userAgent must not contain line breaks")
+	}
+	return nil
+}
+
+// SessionInfo describes an active session's lifetime in sessions.list.
+type SessionInfo struct {
+	ID                  string `json:"id"`
+	TTLSeconds          int64  `json:"ttlSeconds"`          // Effective TTL (per-session override or server default)
+	RemainingTTLSeconds int64  `json:"remainingTtlSeconds"` // Time left before expiry if the session stays idle
+}
+
+// SessionDetail describes a session's operational state in sessions.info.
+type SessionDetail struct {
+	ID                  string `json:"id"`
+	CreatedAt           int64  `json:"createdAt"`  // Unix milliseconds
+	LastUsedAt          int64  `json:"lastUsedAt"` // Unix milliseconds
+	TTLSeconds          int64  `json:"ttlSeconds"`
+	RemainingTTLSeconds int64  `json:"remainingTtlSeconds"`
+	RequestCount        int64  `json:"requestCount"`          // Solves run through the session
+	CurrentURL          string `json:"currentUrl,omitempty"`  // URL the session's page is on
+	Proxy               string `json:"proxy,omitempty"`       // Proxy URL with the password redacted
+	MemoryBytes         int64  `json:"memoryBytes,omitempty"` // JS heap in use by the page (estimate)
+	SolveInProgress     bool   `json:"solveInProgress"`
+}
+
+// ProxyStatus is the latest health check of a configured proxy (proxies.status).
+type ProxyStatus struct {
+	Proxy               string `json:"proxy"`                 // Proxy URL with the password redacted
+	Alive               bool   `json:"alive"`                 // False once checks keep failing; out of rotation
+	LastChecked         int64  `json:"lastChecked,omitempty"` // Unix milliseconds; omitted before the first check
+	LatencyMs           int64  `json:"latencyMs,omitempty"`   // Time to fetch the IP check through the proxy
+	EgressIP            string `json:"egressIp,omitempty"`    // IP the proxy exits from
+	Reputation          string `json:"reputation"`            // Cloudflare verdict on the egress IP: clean, challenged, blocked or unknown
+	ConsecutiveFailures int    `json:"consecutiveFailures"`
+	LastError           string `json:"lastError,omitempty"`
+}
+
+// CaptchaProviderStatus is the account state of a configured external captcha
+// provider (captcha.status).
+type CaptchaProviderStatus struct {
+	Provider    string   `json:"provider"`
+	Balance     *float64 `json:"balance,omitempty"`    // Account balance in USD (9kw: credits); omitted when it could not be read
+	LowBalance  bool     `json:"lowBalance"`           // Balance below CAPTCHA_LOW_BALANCE
+	LatencyMs   int64    `json:"latencyMs"`            // Round trip of the balance query
+	AvgSolveMs  int64    `json:"avgSolveMs,omitempty"` // Average time solves have waited on the provider's queue so far
+	Attempts    int64    `json:"attempts"`             // Solve attempts since startup
+	SuccessRate float64  `json:"successRate"`          // Percent of attempts that returned a token
+	Error       string   `json:"error,omitempty"`      // Why the balance query failed
+	LastError   string   `json:"lastError,omitempty"`  // Last solve error
+	Breaker     string   `json:"breaker,omitempty"`    // Circuit breaker state: "closed", "open" or "half-open"
+}
+
+// DomainPrefs are operator-set solver preferences for a domain and its
+// subdomains, applied to every request to them (domains.setPrefs,
+// domains.getPrefs).
+type DomainPrefs struct {
+	DisableMethods    []string `json:"disableMethods,omitempty"`    // Turnstile methods never tried
+	PreferredProvider string   `json:"preferredProvider,omitempty"` // External CAPTCHA provider tried first
+	TimeoutOverrideMs int      `json:"timeoutOverrideMs,omitempty"` // maxTimeout for requests that set none
+	PollStrategy      string   `json:"pollStrategy,omitempty"`      // Challenge poll strategy: "random", "fixed" or "event"
+}
+
+// Validate checks the bounds of the preferences. Method, provider and
+// strategy names are checked by the handler, which knows them.
+func (p *DomainPrefs) Validate() error {
+	if len(p.DisableMethods) > MaxDisableMethods {
+		return fmt.Errorf("disableMethods exceeds maximum of %d entries", MaxDisableMethods)
+	}
+	if p.TimeoutOverrideMs < 0 {
+		return fmt.Errorf("timeoutOverrideMs cannot be negative")
+	}
+	return nil
+}
+
+// SessionTouch describes where a sessions.touch navigation landed and whether
+// the session's clearance is still good.
+type SessionTouch struct {
+	URL                string `json:"url"`                          // Final URL after the navigation
+	Challenged         bool   `json:"challenged"`                   // A challenge was showing; a full solve is needed
+	HasClearance       bool   `json:"hasClearance"`                 // A cf_clearance cookie applies to the URL
+	ClearanceExpiresAt int64  `json:"clearanceExpiresAt,omitempty"` // Unix milliseconds (omitted for session cookies)
 }
 
 // Solution contains the result of a successful solve.
@@ -301,8 +1103,9 @@ type Solution struct {
 	Cookies        []Cookie          `json:"cookies"`
 	UserAgent      string            `json:"userAgent"`
 	BrowserVersion string            `json:"browserVersion,omitempty"`  // Chrome major version (e.g., "124") for tls-client profile matching
-	Screenshot     string            `json:"screenshot,omitempty"`      // Base64 encoded PNG screenshot
+	Screenshot     string            `json:"screenshot,omitempty"`      // Base64 encoded screenshot, PNG unless screenshotFormat says otherwise
 	TurnstileToken string            `json:"turnstile_token,omitempty"` // cf-turnstile-response token if present
+	RecaptchaToken string            `json:"recaptcha_token,omitempty"` // g-recaptcha-response token solved for solveRecaptcha
 
 	// Extended extraction for debugging (omitted when empty)
 	LocalStorage    map[string]string `json:"localStorage,omitempty"`    // All localStorage key-value pairs
@@ -312,6 +1115,33 @@ type Solution struct {
 	// Custom JS result
 	ExecuteJsResult *string `json:"executeJsResult,omitempty"` // Result of executeJs if provided
 
+	// evaluateJs outcome: the script's return value as JSON, or why there is none
+	JSResult json.RawMessage `json:"jsResult,omitempty"`
+	JSError  string          `json:"jsError,omitempty"`
+
+	// XHR/fetch responses matching captureRequests, in completion order
+	CapturedRequests []CapturedRequest `json:"capturedRequests,omitempty"`
+
+	// HAR 1.2 archive of the solve's network traffic when returnHar is set
+	Har json.RawMessage `json:"har,omitempty"`
+
+	// Base64 PDF rendering of the final page when returnPdf is set
+	Pdf string `json:"pdf,omitempty"`
+
+	// Self-contained archive of the final page in the returnSnapshot format
+	Snapshot string `json:"snapshot,omitempty"`
+
+	// recordSolve screencast: a base64 animated GIF, or where it was written
+	// when RECORD_SOLVE_DIR is set
+	Recording     string `json:"recording,omitempty"`
+	RecordingPath string `json:"recordingPath,omitempty"`
+
+	// IP the browser exited from, checked before navigating (verifyProxy only)
+	EgressIP string `json:"egressIp,omitempty"`
+
+	// Post-clearance client redirects followed (omitted when none)
+	ClientRedirects []string `json:"clientRedirects,omitempty"` // URLs reached via meta-refresh/JS location redirects
+
 	// Response metadata (omitted when not applicable)
 	ResponseEncoding  string  `json:"responseEncoding,omitempty"`  // "base64" when download=true, empty for HTML
 	ResponseTruncated *bool   `json:"responseTruncated,omitempty"` // true if HTML response was truncated due to size limit
@@ -321,7 +1151,13 @@ type Solution struct {
 	RateLimited      *bool   `json:"rateLimited,omitempty"`      // true if rate limiting detected
 	SuggestedDelayMs *int    `json:"suggestedDelayMs,omitempty"` // recommended delay before retry in ms
 	ErrorCode        *string `json:"errorCode,omitempty"`        // specific error identifier (e.g., CF_1015)
-	ErrorCategory    *string `json:"errorCategory,omitempty"`    // broad category: rate_limit, access_denied, captcha, geo_blocked
+	ErrorCategory    *string `json:"errorCategory,omitempty"`    // broad category: rate_limit, access_denied, captcha, geo_blocked, quiet_hours
+	NextAllowedAt    *string `json:"nextAllowedAt,omitempty"`    // RFC 3339 time the domain may be contacted again (QUIET_HOURS only)
+
+	// Cache fallback fields (allowCacheFallback only)
+	Stale    *bool   `json:"stale,omitempty"`    // true when Response is an archived copy, not a live fetch
+	CachedAt *string `json:"cachedAt,omitempty"` // RFC 3339 time the archived copy was captured
+	CacheURL *string `json:"cacheUrl,omitempty"` // Where the archived copy was fetched from
 }
 
 // Cookie represents a browser cookie.
@@ -342,10 +1178,25 @@ type Cookie struct {
 const (
 	CmdRequestGet        = "request.get"
 	CmdRequestPost       = "request.post"
+	CmdRequestPut        = "request.put"
+	CmdRequestPatch      = "request.patch"
+	CmdRequestDelete     = "request.delete"
 	CmdSessionsCreate    = "sessions.create"
 	CmdSessionsList      = "sessions.list"
 	CmdSessionsDestroy   = "sessions.destroy"
 	CmdSessionsKeepalive = "sessions.keepalive"
+	CmdSessionsExport    = "sessions.export"
+	CmdSessionsImport    = "sessions.import"
+	CmdSessionsInfo      = "sessions.info"
+	CmdSessionsTouch     = "sessions.touch"
+	CmdPoolDrain         = "pool.drain"
+	CmdPoolResize        = "pool.resize"
+	CmdPoolRecycleAll    = "pool.recycleAll"
+	CmdTurnstileSolve    = "turnstile.solve"
+	CmdProxiesStatus     = "proxies.status"
+	CmdCaptchaStatus     = "captcha.status"
+	CmdDomainsGetPrefs   = "domains.getPrefs"
+	CmdDomainsSetPrefs   = "domains.setPrefs"
 )
 
 // Status values for API responses.
@@ -360,6 +1211,19 @@ const (
 	ContentTypeJSON           = "application/json"
 )
 
+// Actions for an XHR/fetch response challenged after the page was cleared.
+const (
+	XHRChallengeResolve = "resolve" // Solve a challenge on the XHR URL, then reload the page
+	XHRChallengeReport  = "report"  // Fail the request with XHR_CHALLENGED
+)
+
+// Priorities for taking a browser from a saturated pool.
+const (
+	PriorityLow    = "low"    // Bulk jobs; served after everything else
+	PriorityNormal = "normal" // Default for request.*
+	PriorityHigh   = "high"   // Interactive traffic; default for sessions.create
+)
+
 // BrowserFlags contains per-session Chrome flag overrides.
 // Only a curated subset of flags is supported for security.
 type BrowserFlags struct {
@@ -436,7 +1300,7 @@ func isValidCaptchaSolver(name string) bool {
 	}
 	// Fallback: hardcoded list for when registry isn't initialized
 	switch name {
-	case "2captcha", "capsolver", "anticaptcha":
+	case "2captcha", "capsolver", "anticaptcha", "capmonster", "custom":
 		return true
 	}
 	return false
@@ -2,6 +2,7 @@ package types
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -286,6 +287,514 @@ func TestRequestValidateSessionTTL(t *testing.T) {
 	}
 }
 
+// TestRequestValidateRedirectSettle verifies redirectSettleMs validation bounds
+func TestRequestValidateRedirectSettle(t *testing.T) {
+	tests := []struct {
+		name    string
+		settle  int
+		wantErr bool
+	}{
+		{name: "zero disables", settle: 0, wantErr: false},
+		{name: "valid 2000", settle: 2000, wantErr: false},
+		{name: "valid max", settle: MaxRedirectSettleMs, wantErr: false},
+		{name: "negative", settle: -1, wantErr: true},
+		{name: "exceeds max", settle: MaxRedirectSettleMs + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settle := tt.settle
+			req := Request{
+				Cmd:              "request.get",
+				URL:              "https://example.com",
+				RedirectSettleMs: &settle,
+			}
+			err := req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequestValidateXHRWatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		watch   int
+		action  string
+		wantErr bool
+	}{
+		{name: "zero disables", watch: 0, wantErr: false},
+		{name: "valid with resolve", watch: 5000, action: XHRChallengeResolve, wantErr: false},
+		{name: "valid with report", watch: MaxXHRWatchMs, action: XHRChallengeReport, wantErr: false},
+		{name: "negative", watch: -1, wantErr: true},
+		{name: "exceeds max", watch: MaxXHRWatchMs + 1, wantErr: true},
+		{name: "unknown action", watch: 1000, action: "ignore", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watch := tt.watch
+			req := Request{
+				Cmd:                "request.get",
+				URL:                "https://example.com",
+				XHRWatchMs:         &watch,
+				XHRChallengeAction: tt.action,
+			}
+			err := req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestRequestValidateTags verifies tag count, key and value validation
+func TestRequestValidateTags(t *testing.T) {
+	tooMany := make(map[string]string, MaxTags+1)
+	for i := 0; i <= MaxTags; i++ {
+		tooMany[fmt.Sprintf("k%d", i)] = "v"
+	}
+	tests := []struct {
+		name    string
+		tags    map[string]string
+		wantErr bool
+	}{
+		{name: "none", tags: nil, wantErr: false},
+		{name: "valid", tags: map[string]string{"app": "prowlarr", "team_2": "media ops"}, wantErr: false},
+		{name: "too many", tags: tooMany, wantErr: true},
+		{name: "empty key", tags: map[string]string{"": "v"}, wantErr: true},
+		{name: "key with dash", tags: map[string]string{"my-app": "v"}, wantErr: true},
+		{name: "key starts with digit", tags: map[string]string{"1app": "v"}, wantErr: true},
+		{name: "key too long", tags: map[string]string{strings.Repeat("k", MaxTagKeyLength+1): "v"}, wantErr: true},
+		{name: "empty value", tags: map[string]string{"app": ""}, wantErr: true},
+		{name: "value too long", tags: map[string]string{"app": strings.Repeat("v", MaxTagValueLength+1)}, wantErr: true},
+		{name: "control characters", tags: map[string]string{"app": "a\nb"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Request{Cmd: "sessions.list", Tags: tt.tags}
+			err := req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestRequestValidatePriority verifies accepted priority values
+func TestRequestValidatePriority(t *testing.T) {
+	for _, priority := range []string{"", PriorityLow, PriorityNormal, PriorityHigh} {
+		req := Request{Cmd: CmdRequestGet, URL: "https://example.com", Priority: priority}
+		if err := req.Validate(); err != nil {
+			t.Errorf("priority %q: Validate() = %v", priority, err)
+		}
+	}
+	req := Request{Cmd: CmdRequestGet, URL: "https://example.com", Priority: "urgent"}
+	if err := req.Validate(); err == nil {
+		t.Error("Expected error for unknown priority")
+	}
+}
+
+// TestRequestValidatePoolSize verifies poolSize is required for pool.resize only
+func TestRequestValidatePoolSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{name: "resize", req: Request{Cmd: CmdPoolResize, PoolSize: 4}},
+		{name: "resize without size", req: Request{Cmd: CmdPoolResize}, wantErr: true},
+		{name: "resize negative", req: Request{Cmd: CmdPoolResize, PoolSize: -1}, wantErr: true},
+		{name: "drain", req: Request{Cmd: CmdPoolDrain}},
+		{name: "drain with size", req: Request{Cmd: CmdPoolDrain, PoolSize: 2}, wantErr: true},
+		{name: "recycleAll", req: Request{Cmd: CmdPoolRecycleAll}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestRequestValidateDomainPrefs verifies domain is required for the
+// domains.* commands and domainPrefs is accepted by domains.setPrefs only
+func TestRequestValidateDomainPrefs(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{name: "get", req: Request{Cmd: CmdDomainsGetPrefs, Domain: "example.com"}},
+		{name: "get without domain", req: Request{Cmd: CmdDomainsGetPrefs}, wantErr: true},
+		{name: "set", req: Request{Cmd: CmdDomainsSetPrefs, Domain: "example.com", DomainPrefs: &DomainPrefs{TimeoutOverrideMs: 90000}}},
+		{name: "clear", req: Request{Cmd: CmdDomainsSetPrefs, Domain: "example.com"}},
+		{name: "set without domain", req: Request{Cmd: CmdDomainsSetPrefs, DomainPrefs: &DomainPrefs{}}, wantErr: true},
+		{name: "negative timeout", req: Request{Cmd: CmdDomainsSetPrefs, Domain: "example.com", DomainPrefs: &DomainPrefs{TimeoutOverrideMs: -1}}, wantErr: true},
+		{name: "prefs on get", req: Request{Cmd: CmdDomainsGetPrefs, Domain: "example.com", DomainPrefs: &DomainPrefs{}}, wantErr: true},
+		{name: "domain on request.get", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", Domain: "example.com"}, wantErr: true},
+		{name: "long domain", req: Request{Cmd: CmdDomainsGetPrefs, Domain: strings.Repeat("a", MaxDomainLength+1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestRequestValidateSiteKey verifies turnstile.solve requires a URL and a
+// sitekey, and that siteKey is rejected on other commands
+func TestRequestValidateSiteKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{name: "turnstile", req: Request{Cmd: CmdTurnstileSolve, URL: "https://example.com/", SiteKey: "0x4AAAAAAAtest"}},
+		{name: "turnstile without sitekey", req: Request{Cmd: CmdTurnstileSolve, URL: "https://example.com/"}, wantErr: true},
+		{name: "turnstile without url", req: Request{Cmd: CmdTurnstileSolve, SiteKey: "0x4AAAAAAAtest"}, wantErr: true},
+		{name: "turnstile with session", req: Request{Cmd: CmdTurnstileSolve, URL: "https://example.com/", SiteKey: "k", Session: "s"}, wantErr: true},
+		{name: "sitekey with spaces", req: Request{Cmd: CmdTurnstileSolve, URL: "https://example.com/", SiteKey: "0x4 AAA"}, wantErr: true},
+		{name: "sitekey too long", req: Request{Cmd: CmdTurnstileSolve, URL: "https://example.com/", SiteKey: strings.Repeat("a", MaxSiteKeyLength+1)}, wantErr: true},
+		{name: "sitekey on request.get", req: Request{Cmd: CmdRequestGet, URL: "https://example.com/", SiteKey: "k"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequestValidateWaitFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{name: "selector on request.get", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", WaitForSelector: "#app .item"}},
+		{name: "text on request.post", req: Request{Cmd: CmdRequestPost, URL: "https://example.com", PostData: "a=b", WaitForText: "Results"}},
+		{name: "both", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", WaitForSelector: "#app", WaitForText: "Results"}},
+		{name: "on request.put", req: Request{Cmd: CmdRequestPut, URL: "https://example.com", WaitForSelector: "#app"}, wantErr: true},
+		{name: "on sessions.create", req: Request{Cmd: CmdSessionsCreate, WaitForText: "Results"}, wantErr: true},
+		{name: "selector too long", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", WaitForSelector: strings.Repeat("a", MaxWaitForLength+1)}, wantErr: true},
+		{name: "text too long", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", WaitForText: strings.Repeat("a", MaxWaitForLength+1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequestValidateActions(t *testing.T) {
+	const u = "https://example.com"
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{name: "search", req: Request{Cmd: CmdRequestGet, URL: u, Actions: []Action{
+			{Type: ActionType, Selector: "#q", Text: "golang"},
+			{Type: ActionClick, Selector: "button[type=submit]"},
+			{Type: ActionWait, Selector: ".results"},
+		}}},
+		{name: "select and scroll", req: Request{Cmd: CmdRequestPost, URL: u, PostData: "a=b", Actions: []Action{
+			{Type: ActionSelect, Selector: "#sort", Value: "date"},
+			{Type: ActionScroll, Pixels: -400},
+			{Type: ActionWait, Ms: 500},
+		}}},
+		{name: "image captcha", req: Request{Cmd: CmdRequestGet, URL: u, Actions: []Action{
+			{Type: ActionImageCaptcha, Selector: "img.captcha", Input: "#answer"},
+		}}},
+		{name: "image captcha without input", req: Request{Cmd: CmdRequestGet, URL: u, Actions: []Action{{Type: ActionImageCaptcha, Selector: "img.captcha"}}}, wantErr: true},
+		{name: "on request.delete", req: Request{Cmd: CmdRequestDelete, URL: u, Actions: []Action{{Type: ActionWait, Ms: 1}}}, wantErr: true},
+		{name: "unknown type", req: Request{Cmd: CmdRequestGet, URL: u, Actions: []Action{{Type: "hover", Selector: "a"}}}, wantErr: true},
+		{name: "click without selector", req: Request{Cmd: CmdRequestGet, URL: u, Actions: []Action{{Type: ActionClick}}}, wantErr: true},
+		{name: "type without text", req: Request{Cmd: CmdRequestGet, URL: u, Actions: []Action{{Type: ActionType, Selector: "#q"}}}, wantErr: true},
+		{name: "select without value", req: Request{Cmd: CmdRequestGet, URL: u, Actions: []Action{{Type: ActionSelect, Selector: "#s"}}}, wantErr: true},
+		{name: "scroll without target", req: Request{Cmd: CmdRequestGet, URL: u, Actions: []Action{{Type: ActionScroll}}}, wantErr: true},
+		{name: "wait too long", req: Request{Cmd: CmdRequestGet, URL: u, Actions: []Action{{Type: ActionWait, Ms: MaxWaitSeconds*1000 + 1}}}, wantErr: true},
+		{name: "too many", req: Request{Cmd: CmdRequestGet, URL: u, Actions: make([]Action, MaxActions+1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequestValidateEvaluateJs(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{name: "request.get", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", EvaluateJs: "return document.title"}},
+		{name: "request.post", req: Request{Cmd: CmdRequestPost, URL: "https://example.com", PostData: "a=b", EvaluateJs: "return 1"}},
+		{name: "on turnstile.solve", req: Request{Cmd: CmdTurnstileSolve, URL: "https://example.com", SiteKey: "k", EvaluateJs: "return 1"}, wantErr: true},
+		{name: "too long", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", EvaluateJs: strings.Repeat("x", MaxEvaluateJsLength+1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequestValidateCaptureRequests(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{name: "request.get", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", CaptureRequests: []string{"/api/", "*/graphql*"}}},
+		{name: "on sessions.create", req: Request{Cmd: CmdSessionsCreate, CaptureRequests: []string{"/api/"}}, wantErr: true},
+		{name: "empty pattern", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", CaptureRequests: []string{""}}, wantErr: true},
+		{name: "pattern too long", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", CaptureRequests: []string{strings.Repeat("a", MaxCapturePatternLen+1)}}, wantErr: true},
+		{name: "too many", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", CaptureRequests: make([]string, MaxCapturePatterns+1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequestValidateReturnHar(t *testing.T) {
+	if err := (&Request{Cmd: CmdRequestPost, URL: "https://example.com", PostData: "a=b", ReturnHar: true}).Validate(); err != nil {
+		t.Errorf("Validate() on request.post error = %v", err)
+	}
+	if err := (&Request{Cmd: CmdSessionsCreate, ReturnHar: true}).Validate(); err == nil {
+		t.Error("Validate() accepted returnHar on sessions.create")
+	}
+}
+
+func TestRequestValidatePdfOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{name: "defaults", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", ReturnPdf: true}},
+		{name: "format", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", ReturnPdf: true, PdfOptions: &PdfOptions{Format: "A4", Landscape: true, Scale: 0.8}}},
+		{name: "custom size", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", ReturnPdf: true, PdfOptions: &PdfOptions{Width: 4, Height: 6}}},
+		{name: "on sessions.create", req: Request{Cmd: CmdSessionsCreate, ReturnPdf: true}, wantErr: true},
+		{name: "options without returnPdf", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", PdfOptions: &PdfOptions{Format: "A4"}}, wantErr: true},
+		{name: "unknown format", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", ReturnPdf: true, PdfOptions: &PdfOptions{Format: "B5"}}, wantErr: true},
+		{name: "width only", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", ReturnPdf: true, PdfOptions: &PdfOptions{Width: 4}}, wantErr: true},
+		{name: "too large", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", ReturnPdf: true, PdfOptions: &PdfOptions{Width: 500, Height: 6}}, wantErr: true},
+		{name: "scale", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", ReturnPdf: true, PdfOptions: &PdfOptions{Scale: 3}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequestValidateURLFilters(t *testing.T) {
+	valid := Request{Cmd: CmdRequestGet, URL: "https://example.com", BlockURLs: []string{"doubleclick.net", "*/analytics/*"}, AllowURLs: []string{"example.com"}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	invalid := []Request{
+		{Cmd: CmdSessionsCreate, BlockURLs: []string{"ads"}},
+		{Cmd: CmdRequestGet, URL: "https://example.com", BlockURLs: make([]string, MaxURLFilterPatterns+1)},
+		{Cmd: CmdRequestGet, URL: "https://example.com", AllowURLs: []string{""}},
+	}
+	for i, r := range invalid {
+		if err := r.Validate(); err == nil {
+			t.Errorf("Validate() accepted invalid request %d", i)
+		}
+	}
+}
+
+func TestRequestValidateStreamResponse(t *testing.T) {
+	if err := (&Request{Cmd: CmdRequestPost, URL: "https://example.com", StreamResponse: true}).Validate(); err != nil {
+		t.Errorf("streamResponse on request.post: %v", err)
+	}
+	if err := (&Request{Cmd: CmdSessionsCreate, StreamResponse: true}).Validate(); err == nil {
+		t.Error("streamResponse should be rejected for sessions.create")
+	}
+	if err := (&Request{Cmd: CmdRequestGet, URL: "https://example.com", StreamResponse: true, ReturnOnlyCookies: true}).Validate(); err == nil {
+		t.Error("streamResponse should be rejected with returnOnlyCookies")
+	}
+}
+
+func TestRequestValidateRecordSolve(t *testing.T) {
+	if err := (&Request{Cmd: CmdRequestGet, URL: "https://example.com", RecordSolve: true}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := (&Request{Cmd: CmdSessionsCreate, RecordSolve: true}).Validate(); err == nil {
+		t.Error("Validate() accepted recordSolve on sessions.create")
+	}
+}
+
+func TestRequestValidateVerifyProxy(t *testing.T) {
+	if err := (&Request{Cmd: CmdRequestPut, URL: "https://example.com", VerifyProxy: true}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := (&Request{Cmd: CmdTurnstileSolve, URL: "https://example.com", SiteKey: "0x4AAAAAAA", VerifyProxy: true}).Validate(); err == nil {
+		t.Error("Validate() accepted verifyProxy on turnstile.solve")
+	}
+}
+
+func TestRequestValidateReturnSnapshot(t *testing.T) {
+	if err := (&Request{Cmd: CmdRequestGet, URL: "https://example.com", ReturnSnapshot: "mhtml"}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := (&Request{Cmd: CmdRequestGet, URL: "https://example.com", ReturnSnapshot: "warc"}).Validate(); err == nil {
+		t.Error("Validate() accepted an unknown snapshot format")
+	}
+	if err := (&Request{Cmd: CmdSessionsCreate, ReturnSnapshot: "mhtml"}).Validate(); err == nil {
+		t.Error("Validate() accepted returnSnapshot on sessions.create")
+	}
+}
+
+func TestRequestValidateScreenshotOptions(t *testing.T) {
+	viewport := false
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{name: "jpeg with quality", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", ReturnScreenshot: true, ScreenshotFormat: "jpeg", ScreenshotQuality: 70}},
+		{name: "viewport element", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", ReturnScreenshot: true, ScreenshotFullPage: &viewport, ScreenshotSelector: "#main"}},
+		{name: "without returnScreenshot", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", ScreenshotFormat: "webp"}, wantErr: true},
+		{name: "unknown format", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", ReturnScreenshot: true, ScreenshotFormat: "gif"}, wantErr: true},
+		{name: "png quality", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", ReturnScreenshot: true, ScreenshotQuality: 50}, wantErr: true},
+		{name: "quality out of range", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", ReturnScreenshot: true, ScreenshotFormat: "webp", ScreenshotQuality: 101}, wantErr: true},
+		{name: "selector too long", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", ReturnScreenshot: true, ScreenshotSelector: strings.Repeat("a", MaxWaitForLength+1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestRequestValidateProfile verifies the profile name format and the
+// commands it may be combined with
+func TestRequestValidateProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{name: "request.get", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", Profile: "mobile-eu"}},
+		{name: "sessions.create", req: Request{Cmd: CmdSessionsCreate, Session: "abc", Profile: "desktop.us_1"}},
+		{name: "with session", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", Session: "abc", Profile: "mobile-eu"}, wantErr: true},
+		{name: "unsupported command", req: Request{Cmd: CmdSessionsList, Profile: "mobile-eu"}, wantErr: true},
+		{name: "bad characters", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", Profile: "mobile eu"}, wantErr: true},
+		{name: "leading dot", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", Profile: ".."}, wantErr: true},
+		{name: "too long", req: Request{Cmd: CmdRequestGet, URL: "https://example.com", Profile: strings.Repeat("p", MaxProfileNameLength+1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProxyValidatePool(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxy   Proxy
+		wantErr bool
+	}{
+		{name: "pool", proxy: Proxy{Pool: "residential"}},
+		{name: "pool with url", proxy: Proxy{Pool: "residential", URL: "http://10.0.0.1:8080"}, wantErr: true},
+		{name: "pool with credentials", proxy: Proxy{Pool: "residential", Username: "u"}, wantErr: true},
+		{name: "bad pool name", proxy: Proxy{Pool: "res idential"}, wantErr: true},
+		{name: "pool with provider", proxy: Proxy{Pool: "residential", Provider: "oxylabs"}},
+		{name: "url with provider", proxy: Proxy{URL: "http://gw.example.net:7777", Provider: "brightdata"}},
+		{name: "unknown provider", proxy: Proxy{URL: "http://gw.example.net:7777", Provider: "acme"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.proxy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestSessionStateValidate verifies sessions.import state validation
+func TestRequestValidateCacheFallback(t *testing.T) {
+	get := Request{Cmd: CmdRequestGet, URL: "https://example.com", AllowCacheFallback: true}
+	if err := get.Validate(); err != nil {
+		t.Errorf("request.get with allowCacheFallback: Validate() = %v", err)
+	}
+	post := Request{Cmd: CmdRequestPost, URL: "https://example.com", AllowCacheFallback: true}
+	if err := post.Validate(); err == nil {
+		t.Error("request.post with allowCacheFallback: expected error")
+	}
+}
+
+func TestSessionStateValidate(t *testing.T) {
+	valid := Cookie{Name: "cf_clearance", Value: "v", Domain: ".example.com", Path: "/", SameSite: "None"}
+	tests := []struct {
+		name    string
+		state   SessionState
+		wantErr bool
+	}{
+		{name: "valid", state: SessionState{
+			Cookies:      []Cookie{valid},
+			Origin:       "https://example.com",
+			LocalStorage: map[string]string{"k": "v"},
+		}},
+		{name: "empty", state: SessionState{}},
+		{name: "missing name", state: SessionState{Cookies: []Cookie{{Value: "v", Domain: ".example.com"}}}, wantErr: true},
+		{name: "missing domain", state: SessionState{Cookies: []Cookie{{Name: "a", Value: "v"}}}, wantErr: true},
+		{name: "bad sameSite", state: SessionState{Cookies: []Cookie{{Name: "a", Domain: "x.com", SameSite: "sometimes"}}}, wantErr: true},
+		{name: "storage without origin", state: SessionState{LocalStorage: map[string]string{"k": "v"}}, wantErr: true},
+		{name: "non-http origin", state: SessionState{Origin: "file:///", LocalStorage: map[string]string{"k": "v"}}, wantErr: true},
+		{name: "user agent newline", state: SessionState{UserAgent: "UA\r\nX: y"}, wantErr: true},
+		{name: "too many cookies", state: SessionState{Cookies: make([]Cookie, MaxStateCookies+1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.state.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 // TestCookieJSONFieldNames verifies cookie JSON field names match original FlareSolverr API
 func TestCookieJSONFieldNames(t *testing.T) {
 	cookie := Cookie{
@@ -328,3 +837,86 @@ func TestCookieJSONFieldNames(t *testing.T) {
 		}
 	}
 }
+
+// TestRequestValidateEmulation verifies the locale, timezone and viewport checks
+func TestRequestValidateEmulation(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{name: "all set", req: Request{Locale: "fr-FR", Timezone: "Europe/Paris", Viewport: &Viewport{Width: 1366, Height: 768}}},
+		{name: "bare language", req: Request{Locale: "de"}},
+		{name: "script subtag", req: Request{Locale: "zh-Hant-TW"}},
+		{name: "utc", req: Request{Timezone: "UTC"}},
+		{name: "underscore locale", req: Request{Locale: "en_US"}, wantErr: true},
+		{name: "locale with quote", req: Request{Locale: "en-US'"}, wantErr: true},
+		{name: "unknown timezone", req: Request{Timezone: "Mars/Olympus"}, wantErr: true},
+		{name: "local timezone", req: Request{Timezone: "Local"}, wantErr: true},
+		{name: "viewport too small", req: Request{Viewport: &Viewport{Width: 100, Height: 768}}, wantErr: true},
+		{name: "viewport too large", req: Request{Viewport: &Viewport{Width: 1366, Height: MaxViewportSize + 1}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Cmd = CmdRequestGet
+			tt.req.URL = "https://example.com"
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestRequestValidateLimits verifies the response limit overrides stay
+// within the hard ceilings
+func TestRequestValidateLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  ResponseLimits
+		wantErr bool
+	}{
+		{name: "empty", limits: ResponseLimits{}},
+		{name: "lowered", limits: ResponseLimits{MaxResponseSize: 1024, MaxCookies: 10}},
+		{name: "raised to ceilings", limits: ResponseLimits{MaxResponseSize: MaxResponseSizeCeiling, MaxStorageItems: MaxStorageItemsCeiling}},
+		{name: "response over ceiling", limits: ResponseLimits{MaxResponseSize: MaxResponseSizeCeiling + 1}, wantErr: true},
+		{name: "screenshot over ceiling", limits: ResponseLimits{MaxScreenshotSize: MaxScreenshotSizeCeiling + 1}, wantErr: true},
+		{name: "negative cookies", limits: ResponseLimits{MaxCookies: -1}, wantErr: true},
+		{name: "storage over ceiling", limits: ResponseLimits{MaxStorageSize: MaxStorageSizeCeiling + 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Request{Cmd: CmdRequestGet, URL: "https://example.com", Limits: &tt.limits}
+			err := req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestRequestValidatePoll verifies the polling override bounds
+func TestRequestValidatePoll(t *testing.T) {
+	tests := []struct {
+		name    string
+		poll    PollOptions
+		wantErr bool
+	}{
+		{name: "empty", poll: PollOptions{}},
+		{name: "all set", poll: PollOptions{IntervalMinMs: 300, IntervalMaxMs: 600, MaxAttempts: 50, SelectorBudgetMs: 2000}},
+		{name: "interval too short", poll: PollOptions{IntervalMinMs: 10}, wantErr: true},
+		{name: "interval too long", poll: PollOptions{IntervalMaxMs: MaxPollIntervalMs + 1}, wantErr: true},
+		{name: "max below min", poll: PollOptions{IntervalMinMs: 900, IntervalMaxMs: 600}, wantErr: true},
+		{name: "negative attempts", poll: PollOptions{MaxAttempts: -1}, wantErr: true},
+		{name: "budget too short", poll: PollOptions{SelectorBudgetMs: 50}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Request{Cmd: CmdRequestGet, URL: "https://example.com", Poll: &tt.poll}
+			err := req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
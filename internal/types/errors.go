@@ -1,7 +1,10 @@
 // Package types provides shared types, interfaces, and errors for the application.
 package types
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 // Sentinel errors for consistent error handling across the application.
 // These errors can be checked with errors.Is() for type-safe error handling.
@@ -9,6 +12,7 @@ var (
 	// Browser pool errors
 	ErrBrowserPoolExhausted = errors.New("browser pool exhausted: no browsers available")
 	ErrBrowserPoolClosed    = errors.New("browser pool is closed")
+	ErrBrowserPoolDrained   = errors.New("browser pool is drained")
 	ErrBrowserPoolTimeout   = errors.New("timeout waiting for browser from pool")
 	ErrBrowserUnhealthy     = errors.New("browser is unhealthy")
 	ErrBrowserCrashed       = errors.New("browser process crashed")
@@ -20,12 +24,15 @@ var (
 	ErrTooManySessions      = errors.New("maximum number of sessions reached")
 	ErrSessionPageNil       = errors.New("session page is nil or has been closed")
 	ErrSessionInUse         = errors.New("session is currently in use")
+	ErrSessionProxyRequired = errors.New("session was saved behind a proxy; resend the proxy to restore it")
+	ErrSessionProxyMismatch = errors.New("session was saved behind a different proxy; resend that proxy to restore it")
 
 	// Challenge errors
 	ErrAccessDenied        = errors.New("access denied by target site")
 	ErrChallengeTimeout    = errors.New("challenge resolution timed out")
 	ErrChallengeUnsolvable = errors.New("challenge could not be solved")
 	ErrTurnstileFailed     = errors.New("turnstile verification failed")
+	ErrXHRChallenged       = errors.New("in-page request was challenged after clearance")
 
 	// Request errors
 	ErrInvalidRequest   = errors.New("invalid request")
@@ -33,6 +40,11 @@ var (
 	ErrInvalidCommand   = errors.New("invalid command")
 	ErrURLRequired      = errors.New("url is required")
 	ErrPostDataRequired = errors.New("postData is required for POST requests")
+	ErrQuietHours       = errors.New("domain is in quiet hours")
+	ErrTargetNotAllowed = errors.New("target domain is not allowed on this server")
+
+	// Proxy errors
+	ErrProxyVerification = errors.New("proxy verification failed")
 
 	// Context errors
 	ErrContextCanceled = errors.New("operation canceled")
@@ -43,7 +55,10 @@ var (
 	ErrCaptchaSolverBalance   = errors.New("insufficient solver balance")
 	ErrCaptchaSitekeyNotFound = errors.New("turnstile sitekey not found")
 	ErrCaptchaTokenInjection  = errors.New("failed to inject captcha token")
+	ErrCaptchaTokenRejected   = errors.New("page did not accept the captcha token")
 	ErrCaptchaNoProviders     = errors.New("no captcha solver providers configured")
+	ErrCaptchaBudgetExceeded  = errors.New("captcha spend budget exceeded")
+	ErrCaptchaCircuitOpen     = errors.New("captcha provider circuit breaker is open")
 )
 
 // ChallengeError provides detailed information about challenge failures.
@@ -95,6 +110,17 @@ func NewUnsolvableChallengeError(url string, reason string) *ChallengeError {
 	}
 }
 
+// NewXHRChallengedError creates an error for a page whose document was
+// cleared but whose follow-up XHR/fetch calls (to xhrURL) are still challenged.
+func NewXHRChallengedError(url string, xhrURL string) *ChallengeError {
+	return &ChallengeError{
+		Type:    "xhr_challenged",
+		URL:     url,
+		Message: "Page was cleared but an in-page request to " + xhrURL + " was challenged.",
+		Err:     ErrXHRChallenged,
+	}
+}
+
 // PoolError provides detailed information about browser pool failures.
 type PoolError struct {
 	Operation string // The operation that failed
@@ -171,3 +197,58 @@ func NewCaptchaBalanceError(provider string) *CaptchaError {
 		Err:      ErrCaptchaSolverBalance,
 	}
 }
+
+// NewCaptchaBudgetError creates an error for a spend budget that is used up.
+// An empty provider means the global budget; period is "daily" or "monthly".
+func NewCaptchaBudgetError(provider, period string) *CaptchaError {
+	scope := "global"
+	if provider != "" {
+		scope = provider
+	}
+	return &CaptchaError{
+		Provider: provider,
+		Code:     "budget_exceeded",
+		Message:  "CAPTCHA " + period + " spend budget exceeded (" + scope + ")",
+		Err:      ErrCaptchaBudgetExceeded,
+	}
+}
+
+// NewCaptchaCircuitOpenError creates an error for a provider skipped because
+// its circuit breaker is open.
+func NewCaptchaCircuitOpenError(provider string) *CaptchaError {
+	return &CaptchaError{
+		Provider: provider,
+		Code:     "circuit_open",
+		Message:  "CAPTCHA provider " + provider + " skipped: circuit breaker is open",
+		Err:      ErrCaptchaCircuitOpen,
+	}
+}
+
+// QuietHoursError reports a request refused because the target domain is
+// inside a configured quiet window.
+type QuietHoursError struct {
+	Domain        string    // The quiet hours rule domain that matched
+	NextAllowedAt time.Time // First instant the domain may be contacted again
+	Message       string    // Human-readable error message
+	Err           error     // Underlying error (for unwrapping)
+}
+
+// Error implements the error interface.
+func (e *QuietHoursError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the underlying error for errors.Is/As support.
+func (e *QuietHoursError) Unwrap() error {
+	return e.Err
+}
+
+// NewQuietHoursError creates an error for a request made during quiet hours.
+func NewQuietHoursError(domain string, nextAllowedAt time.Time) *QuietHoursError {
+	return &QuietHoursError{
+		Domain:        domain,
+		NextAllowedAt: nextAllowedAt,
+		Message:       "Requests to " + domain + " are paused for quiet hours until " + nextAllowedAt.UTC().Format(time.RFC3339),
+		Err:           ErrQuietHours,
+	}
+}
@@ -0,0 +1,158 @@
+// Package webcache fetches archived copies of pages, used as a stale
+// fallback when an origin hard-blocks a request.
+//
+// Google retired its public page cache in 2024 and Bing's cache links cannot
+// be derived from a URL, so copies come from the Internet Archive's Wayback
+// Machine, which indexes snapshots by URL and capture time.
+package webcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// SourceWayback identifies copies served from the Wayback Machine.
+	SourceWayback = "wayback"
+
+	defaultAvailabilityURL = "https://archive.org/wayback/available"
+	defaultSnapshotBaseURL = "https://web.archive.org/web/"
+
+	// timestampLayout is the Wayback capture timestamp format (UTC).
+	timestampLayout = "20060102150405"
+
+	// maxCopySize matches the solver's HTML response limit.
+	maxCopySize = 10 * 1024 * 1024
+)
+
+// ErrNoCopy is returned when no archived copy of the URL exists.
+var ErrNoCopy = errors.New("no cached copy available")
+
+// Copy is an archived copy of a page.
+type Copy struct {
+	Source     string    // SourceWayback
+	URL        string    // Where the copy was fetched from
+	Status     int       // HTTP status the origin returned when captured
+	HTML       string    // Page body as originally served
+	CapturedAt time.Time // When the copy was captured (UTC)
+	Truncated  bool      // HTML was cut at the size limit
+}
+
+// Fetcher looks up and downloads archived copies.
+type Fetcher struct {
+	httpClient      *http.Client
+	availabilityURL string
+	snapshotBaseURL string
+}
+
+// Config contains Fetcher settings.
+type Config struct {
+	Timeout         time.Duration
+	AvailabilityURL string // Override for testing
+	SnapshotBaseURL string // Override for testing
+}
+
+// New creates a Fetcher.
+func New(cfg Config) *Fetcher {
+	f := &Fetcher{
+		httpClient:      &http.Client{Timeout: cfg.Timeout},
+		availabilityURL: cfg.AvailabilityURL,
+		snapshotBaseURL: cfg.SnapshotBaseURL,
+	}
+	if f.availabilityURL == "" {
+		f.availabilityURL = defaultAvailabilityURL
+	}
+	if f.snapshotBaseURL == "" {
+		f.snapshotBaseURL = defaultSnapshotBaseURL
+	}
+	return f
+}
+
+// availabilityResponse is the Wayback availability API's reply.
+type availabilityResponse struct {
+	ArchivedSnapshots struct {
+		Closest *struct {
+			Available bool   `json:"available"`
+			Status    string `json:"status"`
+			Timestamp string `json:"timestamp"`
+		} `json:"closest"`
+	} `json:"archived_snapshots"`
+}
+
+// Fetch returns the most recent archived copy of pageURL, or ErrNoCopy.
+func (f *Fetcher) Fetch(ctx context.Context, pageURL string) (*Copy, error) {
+	query := url.Values{"url": {pageURL}}
+	body, _, err := f.get(ctx, f.availabilityURL+"?"+query.Encode(), 1<<20)
+	if err != nil {
+		return nil, fmt.Errorf("wayback availability lookup failed: %w", err)
+	}
+	var avail availabilityResponse
+	if err := json.Unmarshal(body, &avail); err != nil {
+		return nil, fmt.Errorf("failed to parse wayback availability response: %w", err)
+	}
+	closest := avail.ArchivedSnapshots.Closest
+	if closest == nil || !closest.Available || closest.Timestamp == "" {
+		return nil, ErrNoCopy
+	}
+	capturedAt, err := time.Parse(timestampLayout, closest.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wayback timestamp %q: %w", closest.Timestamp, err)
+	}
+
+	// The id_ flag serves the capture as originally received, without the
+	// archive's toolbar or rewritten links
+	snapshotURL := f.snapshotBaseURL + closest.Timestamp + "id_/" + pageURL
+	html, truncated, err := f.get(ctx, snapshotURL, maxCopySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch wayback snapshot: %w", err)
+	}
+
+	// Status is the capture's original status code; the API omits it at times
+	status, err := strconv.Atoi(closest.Status)
+	if err != nil {
+		status = http.StatusOK
+	}
+	return &Copy{
+		Source:     SourceWayback,
+		URL:        snapshotURL,
+		Status:     status,
+		HTML:       string(html),
+		CapturedAt: capturedAt.UTC(),
+		Truncated:  truncated,
+	}, nil
+}
+
+// get fetches rawURL and returns at most limit bytes of its body.
+func (f *Fetcher) get(ctx context.Context, rawURL string, limit int64) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, ErrNoCopy
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(body)) > limit {
+		return body[:limit], true, nil
+	}
+	return body, false, nil
+}
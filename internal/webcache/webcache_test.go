@@ -0,0 +1,70 @@
+package webcache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestFetcher(t *testing.T, availability string) *Fetcher {
+	t.Helper()
+	// A bare handler: ServeMux would clean the "//" in the embedded URL
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/available":
+			if got := r.URL.Query().Get("url"); got != "https://example.com/page" {
+				t.Errorf("availability url = %q", got)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(availability))
+		case "/web/20240102030405id_/https://example.com/page":
+			_, _ = w.Write([]byte("<html>archived</html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return New(Config{
+		Timeout:         5 * time.Second,
+		AvailabilityURL: srv.URL + "/available",
+		SnapshotBaseURL: srv.URL + "/web/",
+	})
+}
+
+func TestFetch(t *testing.T) {
+	f := newTestFetcher(t, `{"archived_snapshots":{"closest":{"available":true,"status":"200","timestamp":"20240102030405","url":"http://web.archive.org/web/20240102030405/https://example.com/page"}}}`)
+
+	c, err := f.Fetch(context.Background(), "https://example.com/page")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if c.HTML != "<html>archived</html>" {
+		t.Errorf("HTML = %q", c.HTML)
+	}
+	if c.Status != http.StatusOK || c.Source != SourceWayback || c.Truncated {
+		t.Errorf("unexpected copy %+v", c)
+	}
+	if want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC); !c.CapturedAt.Equal(want) {
+		t.Errorf("CapturedAt = %v, want %v", c.CapturedAt, want)
+	}
+	if !strings.HasSuffix(c.URL, "/web/20240102030405id_/https://example.com/page") {
+		t.Errorf("URL = %q", c.URL)
+	}
+}
+
+func TestFetch_NoCopy(t *testing.T) {
+	for _, body := range []string{
+		`{"archived_snapshots":{}}`,
+		`{"archived_snapshots":{"closest":{"available":false,"timestamp":"20240102030405"}}}`,
+	} {
+		f := newTestFetcher(t, body)
+		if _, err := f.Fetch(context.Background(), "https://example.com/page"); !errors.Is(err, ErrNoCopy) {
+			t.Errorf("Fetch() with %s = %v, want ErrNoCopy", body, err)
+		}
+	}
+}